@@ -0,0 +1,162 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/enescakir/emoji"
+	"github.com/rabbitstack/fibratus/internal/bootstrap"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	"github.com/rabbitstack/fibratus/pkg/kcap"
+	kcapexport "github.com/rabbitstack/fibratus/pkg/kcap/export"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/util/spinner"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var Command = &cobra.Command{
+	Use:   "export [filter]",
+	Short: "Export events from the kcap (capture) file to JSON Lines or Parquet",
+	RunE:  exportCapture,
+}
+
+var (
+	// export command config
+	exportCfg = config.NewWithOpts(config.WithExport())
+
+	exportOutput string
+	exportFormat string
+)
+
+func init() {
+	exportCfg.MustViperize(Command)
+	Command.PersistentFlags().StringVarP(&exportOutput, "output", "o", "-", "The path of the export file. Events are written to the standard output if the path is -")
+	Command.PersistentFlags().StringVar(&exportFormat, "format", "", "The export format. Possible values are jsonl and parquet. Inferred from the output file extension if omitted")
+}
+
+func exportCapture(cmd *cobra.Command, args []string) error {
+	if err := bootstrap.InitConfigAndLogger(exportCfg); err != nil {
+		return err
+	}
+
+	format := kcapexport.Format(exportFormat)
+	if format == "" {
+		format = kcapexport.FormatFromPath(exportOutput)
+	}
+	if format == kcapexport.Parquet && exportOutput == "-" {
+		return fmt.Errorf("parquet export requires the output file")
+	}
+
+	reader, err := kcap.NewReader(exportCfg.KcapFile, exportCfg)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	if _, _, err := reader.RecoverSnapshotters(); err != nil {
+		return err
+	}
+	reader.SetRange(exportCfg.KcapRange)
+	f, err := filter.NewFromCLIWithAllAccessors(args)
+	if err != nil {
+		return err
+	}
+	if f != nil {
+		reader.SetFilter(f)
+	}
+
+	var w io.Writer = os.Stdout
+	if exportOutput != "-" {
+		out, err := os.Create(exportOutput)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		w = out
+	}
+
+	exp, err := kcapexport.New(format, w)
+	if err != nil {
+		return err
+	}
+	if exportOutput == "-" {
+		if _, err := exportEvents(reader, exp); err != nil {
+			return err
+		}
+		return exp.Close()
+	}
+
+	spin := spinner.Show("Exporting")
+	n, err := exportEvents(reader, exp)
+	spin.Stop()
+	if err != nil {
+		return err
+	}
+	if err := exp.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("%v %d event(s) exported to %s\n", emoji.CheckMarkButton, n, exportOutput)
+	return nil
+}
+
+// exportEvents pulls events from the capture until the reader is exhausted.
+// Errors produced by the reader are logged, while export errors abort the
+// process. Returns the number of exported events.
+func exportEvents(reader kcap.Reader, exp kcapexport.Exporter) (int, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	evts, errs := reader.Read(ctx)
+
+	var n int
+	write := func(kevt *kevent.Kevent) error {
+		if err := exp.Export(kevt); err != nil {
+			return err
+		}
+		n++
+		return nil
+	}
+
+	for {
+		select {
+		case kevt := <-evts:
+			if err := write(kevt); err != nil {
+				return n, err
+			}
+		case err := <-errs:
+			log.Warnf("fail to read event from capture: %v", err)
+		case <-reader.Done():
+			// drain events buffered before the reader stopped
+			for {
+				select {
+				case kevt := <-evts:
+					if err := write(kevt); err != nil {
+						return n, err
+					}
+				default:
+					return n, nil
+				}
+			}
+		}
+	}
+}
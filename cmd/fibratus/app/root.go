@@ -19,16 +19,11 @@
 package app
 
 import (
-	"errors"
-	"github.com/rabbitstack/fibratus/cmd/fibratus/app/capture"
-	"github.com/rabbitstack/fibratus/cmd/fibratus/app/config"
+	"github.com/rabbitstack/fibratus/cmd/fibratus/app/export"
 	"github.com/rabbitstack/fibratus/cmd/fibratus/app/list"
 	"github.com/rabbitstack/fibratus/cmd/fibratus/app/replay"
 	"github.com/rabbitstack/fibratus/cmd/fibratus/app/rules"
-	"github.com/rabbitstack/fibratus/cmd/fibratus/app/service"
-	"github.com/rabbitstack/fibratus/cmd/fibratus/app/stats"
 	"github.com/spf13/cobra"
-	"runtime"
 )
 
 // RootCmd is the entrance to Fibratus CLI
@@ -43,26 +38,16 @@ var RootCmd = &cobra.Command{
 	leverage the power of the Python ecosystem.
 	`,
 	SilenceUsage: true,
-	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		if runtime.GOOS != "windows" {
-			return errors.New("fibratus can only be run on Windows operating systems")
-		}
-		if runtime.GOARCH == "386" {
-			return errors.New("fibratus can't be run on 32-bits Windows operating systems")
-		}
-		return nil
-	},
 }
 
+// init registers commands that are available on all platforms. Event
+// acquisition, service management and commands that talk to the running
+// instance are registered in root_windows.go. Other platforms can only
+// replay and export captures, and work with rules.
 func init() {
-	RootCmd.AddCommand(capture.Command)
 	RootCmd.AddCommand(replay.Command)
-	RootCmd.AddCommand(service.Command)
-	RootCmd.AddCommand(stats.Command)
-	RootCmd.AddCommand(config.Command)
+	RootCmd.AddCommand(export.Command)
 	RootCmd.AddCommand(list.Command)
 	RootCmd.AddCommand(rules.Command)
-	RootCmd.AddCommand(runCmd)
-	RootCmd.AddCommand(docsCmd)
 	RootCmd.AddCommand(versionCmd)
 }
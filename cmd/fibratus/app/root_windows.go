@@ -0,0 +1,44 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package app
+
+import (
+	"errors"
+	"github.com/rabbitstack/fibratus/cmd/fibratus/app/capture"
+	"github.com/rabbitstack/fibratus/cmd/fibratus/app/config"
+	"github.com/rabbitstack/fibratus/cmd/fibratus/app/service"
+	"github.com/rabbitstack/fibratus/cmd/fibratus/app/stats"
+	"github.com/spf13/cobra"
+	"runtime"
+)
+
+func init() {
+	RootCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		if runtime.GOARCH == "386" {
+			return errors.New("fibratus can't be run on 32-bits Windows operating systems")
+		}
+		return nil
+	}
+	RootCmd.AddCommand(capture.Command)
+	RootCmd.AddCommand(service.Command)
+	RootCmd.AddCommand(stats.Command)
+	RootCmd.AddCommand(config.Command)
+	RootCmd.AddCommand(runCmd)
+	RootCmd.AddCommand(docsCmd)
+}
@@ -0,0 +1,133 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"fmt"
+	"github.com/enescakir/emoji"
+	"github.com/rabbitstack/fibratus/pkg/rules/sigma"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var invalidFilenameChars = regexp.MustCompile(`[^a-z0-9_]+`)
+
+func convertRules(paths []string) error {
+	if from != "sigma" {
+		return fmt.Errorf("unsupported rule format: %s. Only sigma rules can be converted", from)
+	}
+	if err := os.MkdirAll(outputDir, os.ModePerm); err != nil {
+		return err
+	}
+
+	files, err := expandRulePaths(paths)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("%v no rules found in %s", emoji.DisappointedFace, strings.Join(paths, ","))
+	}
+
+	var converted int
+	for _, file := range files {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		c, err := sigma.Convert(b)
+		if err != nil {
+			emo("%v %s: %v\n", emoji.CrossMark, file, err)
+			continue
+		}
+		if !c.IsConverted() {
+			emo("%v %s (%s): %d unsupported construct(s):\n", emoji.CrossMark, c.Title, file, len(c.Unsupported))
+			for _, u := range c.Unsupported {
+				fmt.Printf("  %v %s\n", emoji.Prohibited, u)
+			}
+			continue
+		}
+		out, err := c.Rule.Marshal()
+		if err != nil {
+			return err
+		}
+		n := filepath.Join(outputDir, ruleFilename(c.Rule))
+		if err := os.WriteFile(n, out, 0644); err != nil {
+			return err
+		}
+		converted++
+		emo("%v %s converted to %s\n", emoji.CheckMarkButton, c.Title, n)
+		for _, w := range c.Warnings {
+			fmt.Printf("  %v %s\n", emoji.Warning, w)
+		}
+	}
+
+	emo("%v Converted %d of %d rule(s)", emoji.Rocket, converted, len(files))
+	return nil
+}
+
+// expandRulePaths resolves rule files from paths that
+// can designate files, directories, or glob patterns.
+func expandRulePaths(paths []string) ([]string, error) {
+	isValidExt := func(path string) bool {
+		return filepath.Ext(path) == ".yml" || filepath.Ext(path) == ".yaml"
+	}
+	files := make([]string, 0)
+	for _, p := range paths {
+		matches, err := filepath.Glob(p)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range matches {
+			fi, err := os.Stat(m)
+			if err != nil {
+				return nil, err
+			}
+			if !fi.IsDir() {
+				if isValidExt(m) {
+					files = append(files, m)
+				}
+				continue
+			}
+			err = filepath.WalkDir(m, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if !d.IsDir() && isValidExt(path) {
+					files = append(files, path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return files, nil
+}
+
+// ruleFilename derives the rule file name from the tactic and the rule name.
+func ruleFilename(r *sigma.Rule) string {
+	name := invalidFilenameChars.ReplaceAllString(strings.ToLower(r.Name), "_")
+	if tactic := r.Labels["tactic.name"]; tactic != "" {
+		name = strings.Replace(strings.ToLower(tactic), " ", "_", -1) + "_" + name
+	}
+	return strings.Trim(name, "_") + ".yml"
+}
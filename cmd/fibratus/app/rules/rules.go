@@ -28,7 +28,7 @@ import (
 
 var Command = &cobra.Command{
 	Use:   "rules",
-	Short: "Validate, list, or search detection rules",
+	Short: "Validate, list, test, convert, or search detection rules",
 }
 
 var validateCmd = &cobra.Command{
@@ -43,6 +43,18 @@ var listCmd = &cobra.Command{
 	RunE:  list,
 }
 
+var testCmd = &cobra.Command{
+	Use:   "test",
+	Short: "Run test cases embedded in rules",
+	RunE:  test,
+}
+
+var convertCmd = &cobra.Command{
+	Use:   "convert [paths...]",
+	Short: "Convert rules from other formats, such as Sigma",
+	RunE:  convertFrom,
+}
+
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Create a new rule template",
@@ -54,6 +66,8 @@ var cfg = config.NewWithOpts(config.WithValidate(), config.WithList())
 var (
 	summarized bool
 	tacticID   string
+	from       string
+	outputDir  string
 )
 
 func init() {
@@ -64,6 +78,12 @@ func init() {
 	listCmd.PersistentFlags().BoolVarP(&summarized, "summary", "s", false, "Show rules summary by MITRE tactics and techniques")
 	Command.AddCommand(listCmd)
 
+	Command.AddCommand(testCmd)
+
+	convertCmd.PersistentFlags().StringVar(&from, "from", "sigma", "Specifies the format of the rules to convert. Only sigma is supported")
+	convertCmd.PersistentFlags().StringVarP(&outputDir, "output", "o", ".", "Specifies the directory where converted rules are written")
+	Command.AddCommand(convertCmd)
+
 	createCmd.PersistentFlags().StringVarP(&tacticID, "tactic-id", "t", "", "Specifies the MITRE tactic identifier for the rule (e.g. TA0001)")
 	Command.AddCommand(createCmd)
 }
@@ -76,6 +96,17 @@ func list(cmd *cobra.Command, args []string) error {
 	return listRules()
 }
 
+func test(cmd *cobra.Command, args []string) error {
+	return testRules()
+}
+
+func convertFrom(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("at least one rule path is required")
+	}
+	return convertRules(args)
+}
+
 func create(cmd *cobra.Command, args []string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("rule name is required")
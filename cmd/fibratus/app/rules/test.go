@@ -0,0 +1,77 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"fmt"
+	"github.com/enescakir/emoji"
+	"github.com/rabbitstack/fibratus/internal/bootstrap"
+	"github.com/rabbitstack/fibratus/pkg/rules"
+	"strings"
+)
+
+func testRules() error {
+	if err := bootstrap.InitConfigAndLogger(cfg); err != nil {
+		return err
+	}
+	if err := cfg.Filters.LoadMacros(); err != nil {
+		return fmt.Errorf("%v %v", emoji.DisappointedFace, err)
+	}
+	if err := cfg.Filters.LoadFilters(); err != nil {
+		return fmt.Errorf("%v %v", emoji.DisappointedFace, err)
+	}
+	if len(cfg.GetFilters()) == 0 {
+		return fmt.Errorf("%v no rules found in %s", emoji.DisappointedFace, strings.Join(cfg.Filters.Rules.FromPaths, ","))
+	}
+
+	results := rules.RunTests(cfg)
+	if len(results) == 0 {
+		emo("%v No rule tests found\n", emoji.ThinkingFace)
+		return nil
+	}
+
+	var failed int
+	var rule string
+	for _, res := range results {
+		if res.Rule != rule {
+			emo("%v %s\n", emoji.Package, res.Rule)
+			rule = res.Rule
+		}
+		switch {
+		case res.Err != nil:
+			failed++
+			fmt.Printf("  %v %s: %v\n", emoji.CrossMark, res.Test, res.Err)
+		case !res.Passed:
+			failed++
+			if res.Matched {
+				fmt.Printf("  %v %s: rule matched but no match was expected\n", emoji.CrossMark, res.Test)
+			} else {
+				fmt.Printf("  %v %s: rule didn't match but a match was expected\n", emoji.CrossMark, res.Test)
+			}
+		default:
+			fmt.Printf("  %v %s\n", emoji.CheckMarkButton, res.Test)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%v %d of %d rule test(s) failed", emoji.DisappointedFace, failed, len(results))
+	}
+	emo("%v All %d rule test(s) passed\n", emoji.Rocket, len(results))
+	return nil
+}
@@ -0,0 +1,179 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package bootstrap
+
+import (
+	"context"
+	"errors"
+	"github.com/rabbitstack/fibratus/pkg/aggregator"
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/api"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	"github.com/rabbitstack/fibratus/pkg/kcap"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	"github.com/rabbitstack/fibratus/pkg/util/multierror"
+	"github.com/rabbitstack/fibratus/pkg/util/signals"
+)
+
+// ErrCaptureReplayOnly signals the application can only replay
+// captures on this platform. Event acquisition requires the ETW
+// event sources which are only available on Windows.
+var ErrCaptureReplayOnly = errors.New("only capture replay is supported on this platform")
+
+// errFilamentUnsupported signals filaments can't be run on this platform.
+var errFilamentUnsupported = errors.New("filaments are only supported on Windows")
+
+// App replays the event stream from the capture file and
+// routes events to the output sinks. The process state is
+// reconstructed from the capture, so filters can resolve
+// process fields without touching the live system.
+type App struct {
+	config  *config.Config
+	psnap   ps.Snapshotter
+	agg     *aggregator.BufferedAggregator
+	reader  kcap.Reader
+	signals chan struct{}
+}
+
+// Option enables changing the behaviour of the bootstrap application.
+type Option func(*opts)
+
+type opts struct {
+	installSignals  bool
+	isCaptureReplay bool
+}
+
+// WithSignals installs signal handlers.
+func WithSignals() Option {
+	return func(o *opts) {
+		o.installSignals = true
+	}
+}
+
+// WithCaptureReplay denotes the capture file is being replayed.
+func WithCaptureReplay() Option {
+	return func(o *opts) {
+		o.isCaptureReplay = true
+	}
+}
+
+// NewApp constructs a new bootstrap application with the specified configuration
+// and a list of options. Only capture replay is supported on this platform.
+func NewApp(cfg *config.Config, options ...Option) (*App, error) {
+	if err := InitConfigAndLogger(cfg); err != nil {
+		return nil, err
+	}
+	var opts opts
+	for _, opt := range options {
+		opt(&opts)
+	}
+	if !opts.isCaptureReplay {
+		return nil, ErrCaptureReplayOnly
+	}
+	reader, err := kcap.NewReader(cfg.KcapFile, cfg)
+	if err != nil {
+		return nil, err
+	}
+	app := &App{
+		config: cfg,
+		reader: reader,
+	}
+	if opts.installSignals {
+		app.signals = signals.Install()
+	}
+	return app, nil
+}
+
+// ReadCapture reconstructs the event stream from the capture file.
+func (f *App) ReadCapture(ctx context.Context, args []string) error {
+	if f.reader == nil {
+		panic("reader is nil")
+	}
+	if f.config.Filament.Name != "" {
+		return errFilamentUnsupported
+	}
+	kfilter, err := filter.NewFromCLIWithAllAccessors(args)
+	if err != nil {
+		return err
+	}
+	_, f.psnap, err = f.reader.RecoverSnapshotters()
+	if err != nil {
+		return err
+	}
+	f.reader.SetRange(f.config.KcapRange)
+	if kfilter != nil {
+		f.reader.SetFilter(kfilter)
+	}
+	// use the channels where events are read
+	// from the capture as aggregator source
+	evts, errs := f.reader.Read(ctx)
+	f.agg, err = aggregator.NewBuffered(
+		evts,
+		errs,
+		f.config.Aggregator,
+		f.config.Outputs,
+		f.config.Transformers,
+		f.config.Alertsenders,
+		f.config.AlertRoutes,
+		aggregator.WithFilterCompiler(func(expr string) (aggregator.Predicate, error) {
+			return filter.NewFromCLIWithAllAccessors([]string{expr})
+		}),
+	)
+	if err != nil {
+		return err
+	}
+	return api.StartServer(f.config)
+}
+
+// Wait waits for the app to receive the termination signal.
+func (f *App) Wait() {
+	if f.signals != nil {
+		<-f.signals
+	}
+}
+
+// Shutdown is responsible for tearing down everything gracefully.
+func (f *App) Shutdown() error {
+	errs := make([]error, 0)
+	if f.psnap != nil {
+		if err := f.psnap.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if f.reader != nil {
+		if err := f.reader.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if f.agg != nil {
+		if err := f.agg.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := api.CloseServer(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := alertsender.ShutdownAll(); err != nil {
+		errs = append(errs, err)
+	}
+	return multierror.Wrap(errs...)
+}
@@ -60,6 +60,7 @@ type App struct {
 	agg        *aggregator.BufferedAggregator
 	writer     kcap.Writer
 	reader     kcap.Reader
+	recorder   kcap.Recorder
 	signals    chan struct{}
 }
 
@@ -217,7 +218,7 @@ func (f *App) Run(args []string) error {
 			return multierror.Wrap(err, f.evs.Close())
 		}
 		// load alert senders so emitting alerts is possible from filaments
-		err = alertsender.LoadAll(cfg.Alertsenders)
+		err = alertsender.LoadAll(cfg.Alertsenders, cfg.AlertRoutes)
 		if err != nil {
 			log.Warnf("couldn't load alertsenders: %v", err)
 		}
@@ -234,9 +235,24 @@ func (f *App) Run(args []string) error {
 			f.symbolizer = symbolize.NewSymbolizer(symbolize.NewDebugHelpResolver(cfg), f.psnap, cfg, false)
 			f.evs.RegisterEventListener(f.symbolizer)
 		}
-		// register rule engine
+		// register rule engine. The flight recorder must
+		// see the event before the engine evaluates rules,
+		// so the triggering event ends up in the capture
 		if f.engine != nil {
+			if cfg.Recorder.Enabled {
+				f.recorder, err = kcap.NewRecorder(cfg.Recorder, f.psnap, f.hsnap)
+				if err != nil {
+					return err
+				}
+				f.evs.RegisterEventListener(f.recorder)
+				f.engine.SetRecorder(f.recorder)
+			}
 			f.evs.RegisterEventListener(f.engine)
+			if cfg.Filters.Rules.Watch {
+				if err := f.engine.Watch(); err != nil {
+					log.Warnf("unable to watch rule files: %v", err)
+				}
+			}
 		}
 		// register YARA scanner
 		if cfg.Yara.Enabled {
@@ -255,15 +271,26 @@ func (f *App) Run(args []string) error {
 			f.evs.Events(),
 			f.evs.Errors(),
 			cfg.Aggregator,
-			cfg.Output,
+			cfg.Outputs,
 			cfg.Transformers,
 			cfg.Alertsenders,
+			cfg.AlertRoutes,
+			aggregator.WithFilterCompiler(func(expr string) (aggregator.Predicate, error) {
+				fltr := filter.New(expr, cfg, filter.WithPSnapshotter(f.psnap))
+				if err := fltr.Compile(); err != nil {
+					return nil, err
+				}
+				return fltr, nil
+			}),
 		)
 		if err != nil {
 			return err
 		}
 	}
 	// start the HTTP server
+	if f.engine != nil {
+		return api.StartServer(cfg, api.WithRulesReloader(f.engine))
+	}
 	return api.StartServer(cfg)
 }
 
@@ -314,6 +341,7 @@ func (f *App) ReadCapture(ctx context.Context, args []string) error {
 	if err != nil {
 		return err
 	}
+	f.reader.SetRange(f.config.KcapRange)
 	filamentName := f.config.Filament.Name
 	if filamentName != "" {
 		f.filament, err = filament.New(filamentName, f.psnap, f.hsnap, f.config)
@@ -347,9 +375,13 @@ func (f *App) ReadCapture(ctx context.Context, args []string) error {
 			evts,
 			errs,
 			f.config.Aggregator,
-			f.config.Output,
+			f.config.Outputs,
 			f.config.Transformers,
 			f.config.Alertsenders,
+			f.config.AlertRoutes,
+			aggregator.WithFilterCompiler(func(expr string) (aggregator.Predicate, error) {
+				return filter.NewFromCLIWithAllAccessors([]string{expr})
+			}),
 		)
 		if err != nil {
 			return err
@@ -376,6 +408,14 @@ func (f *App) Shutdown() error {
 			errs = append(errs, err)
 		}
 	}
+	if f.engine != nil {
+		f.engine.Close()
+	}
+	if f.recorder != nil {
+		if err := f.recorder.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
 	if f.hsnap != nil {
 		if err := f.hsnap.Close(); err != nil {
 			errs = append(errs, err)
@@ -34,18 +34,30 @@ import (
 	_ "github.com/rabbitstack/fibratus/pkg/outputs/console"
 	_ "github.com/rabbitstack/fibratus/pkg/outputs/elasticsearch"
 	_ "github.com/rabbitstack/fibratus/pkg/outputs/eventlog"
+	_ "github.com/rabbitstack/fibratus/pkg/outputs/file"
 	_ "github.com/rabbitstack/fibratus/pkg/outputs/http"
+	_ "github.com/rabbitstack/fibratus/pkg/outputs/kafka"
 	_ "github.com/rabbitstack/fibratus/pkg/outputs/null"
+	_ "github.com/rabbitstack/fibratus/pkg/outputs/otlp"
+	_ "github.com/rabbitstack/fibratus/pkg/outputs/splunk"
+	_ "github.com/rabbitstack/fibratus/pkg/outputs/syslog"
 
 	// initialize alert senders
 	_ "github.com/rabbitstack/fibratus/pkg/alertsender/mail"
+	_ "github.com/rabbitstack/fibratus/pkg/alertsender/pagerduty"
 	_ "github.com/rabbitstack/fibratus/pkg/alertsender/slack"
 	_ "github.com/rabbitstack/fibratus/pkg/alertsender/systray"
+	_ "github.com/rabbitstack/fibratus/pkg/alertsender/teams"
+	_ "github.com/rabbitstack/fibratus/pkg/alertsender/webhook"
 
 	// initialize transformers
+	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/drop"
+	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/enrich"
+	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/redact"
 	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/remove"
 	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/rename"
 	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/replace"
+	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/sample"
 	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/tags"
 	_ "github.com/rabbitstack/fibratus/pkg/aggregator/transformers/trim"
 )
@@ -61,21 +73,22 @@ var (
 	transformerErrors = expvar.NewMap("aggregator.transformer.errors")
 	// keventErrors is the number of event errors
 	keventErrors = expvar.NewInt("aggregator.kevent.errors")
+	// droppedEvents is the number of events discarded by transformers
+	droppedEvents = expvar.NewInt("aggregator.transformer.dropped.events")
 )
 
 // BufferedAggregator collects events from the inbound channel and produces batches on regular intervals. The batches
-// are pushed to the work queue from which load-balanced configured workers consume the batches and publish to the outputs.
+// are fanned out to the work queue of each output from which load-balanced workers consume the batches and publish to
+// the output.
 type BufferedAggregator struct {
 	kevtsc  <-chan *kevent.Kevent
 	errsc   <-chan error
 	stop    chan struct{}
 	flusher *time.Ticker
 	// queue of inbound kernel events
-	kevts []*kevent.Kevent
-	// work queue that forwarder passes to outputs
-	wq         queue
+	kevts      []*kevent.Kevent
 	submitter  *submitter
-	transforms []transformers.Transformer
+	transforms []transform
 	c          Config
 }
 
@@ -84,10 +97,16 @@ func NewBuffered(
 	evts <-chan *kevent.Kevent,
 	errs <-chan error,
 	aggConfig Config,
-	outputConfig outputs.Config,
+	outputConfigs []outputs.Config,
 	transformerConfigs []transformers.Config,
 	alertsenderConfigs []alertsender.Config,
+	alertRoutes alertsender.RouteConfig,
+	options ...Option,
 ) (*BufferedAggregator, error) {
+	var opts opts
+	for _, opt := range options {
+		opt(&opts)
+	}
 	flushInterval := aggConfig.FlushPeriod
 	if flushInterval < time.Millisecond*250 {
 		flushInterval = time.Millisecond * 250
@@ -98,21 +117,20 @@ func NewBuffered(
 		errsc:   errs,
 		stop:    make(chan struct{}, 1),
 		flusher: time.NewTicker(flushInterval),
-		wq:      make(chan *kevent.Batch),
 		c:       aggConfig,
 	}
 
 	var err error
-	agg.submitter, err = newSubmitter(agg.wq, outputConfig)
+	agg.submitter, err = newSubmitter(outputConfigs, opts.compiler, aggConfig.Spool)
 	if err != nil {
 		return nil, err
 	}
-	agg.transforms, err = transformers.LoadAll(transformerConfigs)
+	agg.transforms, err = loadTransforms(transformerConfigs, opts.compiler)
 	if err != nil {
 		return nil, err
 	}
 
-	err = alertsender.LoadAll(alertsenderConfigs)
+	err = alertsender.LoadAll(alertsenderConfigs, alertRoutes)
 	if err != nil {
 		return nil, err
 	}
@@ -126,32 +144,22 @@ func NewBuffered(
 func (agg *BufferedAggregator) Stop() error {
 	agg.stop <- struct{}{}
 
-	// flush enqueued events
-	b := kevent.NewBatch(agg.kevts...)
-	if b.Len() > 0 {
-		done := make(chan struct{}, 1)
-		go func() {
-			agg.wq <- b
-			done <- struct{}{}
-		}()
-
-		select {
-		case <-done:
-			close(agg.wq)
-		case <-time.After(agg.c.FlushTimeout):
-			return errors.New("fail to flush events after stop timed out")
+	for _, t := range agg.transforms {
+		if err := t.close(); err != nil {
+			log.Warnf("unable to close %q transformer: %v", t.typ, err)
 		}
 	}
 
-	// sleep a bit before closing the clients
-	time.Sleep(time.Millisecond * 150)
+	// producers must not stall on full spools while stopping
+	agg.submitter.unblock()
 
-	err := agg.submitter.shutdown()
-	if err != nil {
-		return err
+	// flush enqueued events
+	b := kevent.NewBatch(agg.kevts...)
+	if b.Len() > 0 {
+		agg.submitter.submit(b)
 	}
 
-	return nil
+	return agg.submitter.shutdown(agg.c.FlushTimeout)
 }
 
 // run starts the aggregator loop. The aggregator receives event stream from the upstream channel, buffers
@@ -169,26 +177,41 @@ func (agg *BufferedAggregator) run() {
 			b := kevent.NewBatch(agg.kevts...)
 			l := b.Len()
 			batchEvents.Add(l)
-			// push the batch to the work queue
+			// push the batch to the outputs
 			if l > 0 {
-				agg.wq <- b
+				agg.submitter.submit(b)
 			}
 			flushesCount.Add(1)
 			// clear the queue
 			agg.kevts = nil
 		case evt := <-agg.kevtsc:
-			for _, transform := range agg.transforms {
-				err := transform.Transform(evt)
-				if err != nil {
-					transformerErrors.Add(err.Error(), 1)
-				}
+			keventsDequeued.Add(1)
+			if !agg.transform(evt) {
+				continue
 			}
 			// push the event to the queue
 			agg.kevts = append(agg.kevts, evt)
-			keventsDequeued.Add(1)
 		case err := <-agg.errsc:
 			keventErrors.Add(1)
 			log.Errorf("event processing failure: %v", err)
 		}
 	}
 }
+
+// transform applies transformers on the event. It
+// returns false if any of the transformers dropped
+// the event.
+func (agg *BufferedAggregator) transform(evt *kevent.Kevent) bool {
+	for _, t := range agg.transforms {
+		err := t.apply(evt)
+		if err == nil {
+			continue
+		}
+		if errors.Is(err, transformers.ErrDropped) {
+			droppedEvents.Add(1)
+			return false
+		}
+		transformerErrors.Add(err.Error(), 1)
+	}
+	return true
+}
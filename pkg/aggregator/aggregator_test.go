@@ -19,6 +19,7 @@
 package aggregator
 
 import (
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
 	"github.com/rabbitstack/fibratus/pkg/kevent"
 	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
 	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
@@ -38,9 +39,10 @@ func TestNewBufferedAggregator(t *testing.T) {
 		keventsc,
 		errsc,
 		Config{FlushPeriod: time.Millisecond * 200},
-		outputs.Config{Type: outputs.Console, Output: console.Config{Format: "pretty"}},
+		[]outputs.Config{{Type: outputs.Console, Output: console.Config{Format: "pretty"}}},
 		nil,
 		nil,
+		alertsender.RouteConfig{},
 	)
 	require.NoError(t, err)
 	require.NotNil(t, agg)
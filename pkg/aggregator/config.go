@@ -19,14 +19,25 @@
 package aggregator
 
 import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/spool"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
-	"time"
 )
 
 const (
 	flushPeriod  = "aggregator.flush-period"
 	flushTimeout = "aggregator.flush-timeout"
+
+	spoolEnabled     = "aggregator.spool.enabled"
+	spoolDir         = "aggregator.spool.dir"
+	spoolMaxSize     = "aggregator.spool.max-size"
+	spoolSegmentSize = "aggregator.spool.segment-size"
+	spoolPolicy      = "aggregator.spool.policy"
+	spoolMaxBackoff  = "aggregator.spool.max-backoff"
 )
 
 // Config contains aggregator-specific configuration tweaks.
@@ -35,16 +46,50 @@ type Config struct {
 	FlushPeriod time.Duration `json:"aggregator.flush-period" yaml:"aggregator.flush-period"`
 	// FlushTimeout represents the max time to wait before announcing failed flushing of enqueued events
 	FlushTimeout time.Duration `json:"aggregator.flush-timeout" yaml:"aggregator.flush-timeout"`
+	// Spool contains the durable spool settings.
+	Spool SpoolConfig `json:"aggregator.spool" yaml:"aggregator.spool"`
+}
+
+// SpoolConfig contains the settings of the durable spool. When enabled, batches
+// are persisted to the write-ahead log of each output before they are published,
+// and failed publishes are retried until the output accepts the batch.
+type SpoolConfig struct {
+	// Enabled indicates if the spool is enabled.
+	Enabled bool `json:"aggregator.spool.enabled" yaml:"aggregator.spool.enabled"`
+	// Dir is the directory where spool segments are stored. Each output has its own subdirectory.
+	Dir string `json:"aggregator.spool.dir" yaml:"aggregator.spool.dir"`
+	// MaxSize is the maximum size in megabytes of the spool of every output.
+	MaxSize int `json:"aggregator.spool.max-size" yaml:"aggregator.spool.max-size"`
+	// SegmentSize is the size in megabytes of each segment file.
+	SegmentSize int `json:"aggregator.spool.segment-size" yaml:"aggregator.spool.segment-size"`
+	// Policy is the eviction policy that applies when the spool is full.
+	Policy spool.Policy `json:"aggregator.spool.policy" yaml:"aggregator.spool.policy"`
+	// MaxBackoff is the maximum interval between publish retries.
+	MaxBackoff time.Duration `json:"aggregator.spool.max-backoff" yaml:"aggregator.spool.max-backoff"`
 }
 
 // AddFlags registers persistent aggregator flags.
 func AddFlags(flags *pflag.FlagSet) {
 	flags.Duration(flushPeriod, time.Millisecond*200, "Determines the period for flushing batches to outputs")
 	flags.Duration(flushTimeout, time.Second*4, "Represents the max time to wait before announcing failed flushing of enqueued events on aggregator shutdown")
+	flags.Bool(spoolEnabled, false, "Indicates if batches are persisted to the durable spool before they are published to outputs")
+	flags.String(spoolDir, filepath.Join(os.Getenv("PROGRAMFILES"), "fibratus", "spool"), "Specifies the directory where spool segments are stored")
+	flags.Int(spoolMaxSize, 1024, "Specifies the maximum size in megabytes of the spool of every output")
+	flags.Int(spoolSegmentSize, 16, "Specifies the size in megabytes of each spool segment file")
+	flags.String(spoolPolicy, string(spool.DropOldest), "Specifies the eviction policy when the spool is full. Possible values are drop-oldest and block")
+	flags.Duration(spoolMaxBackoff, time.Minute, "Specifies the maximum interval between publish retries of spooled batches")
 }
 
 // InitFromViper initializes aggregator flags from viper.
 func (c *Config) InitFromViper(v *viper.Viper) {
 	c.FlushPeriod = v.GetDuration(flushPeriod)
 	c.FlushTimeout = v.GetDuration(flushTimeout)
+	c.Spool = SpoolConfig{
+		Enabled:     v.GetBool(spoolEnabled),
+		Dir:         v.GetString(spoolDir),
+		MaxSize:     v.GetInt(spoolMaxSize),
+		SegmentSize: v.GetInt(spoolSegmentSize),
+		Policy:      spool.Policy(v.GetString(spoolPolicy)),
+		MaxBackoff:  v.GetDuration(spoolMaxBackoff),
+	}
 }
@@ -0,0 +1,50 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+)
+
+// Predicate decides whether the event is eligible for
+// further processing. Compiled filters satisfy this interface.
+type Predicate interface {
+	Run(*kevent.Kevent) bool
+}
+
+// FilterCompiler compiles the filter expression into a predicate.
+// The aggregator can't reference the filter package directly because
+// the filter package depends on the configuration which in turn depends
+// on the aggregator, so the compiler is supplied by the caller.
+type FilterCompiler func(expr string) (Predicate, error)
+
+type opts struct {
+	compiler FilterCompiler
+}
+
+// Option represents the option for the aggregator.
+type Option func(o *opts)
+
+// WithFilterCompiler sets the function for compiling the filter
+// expressions declared in the output and transformer configurations.
+func WithFilterCompiler(compiler FilterCompiler) Option {
+	return func(o *opts) {
+		o.compiler = compiler
+	}
+}
@@ -0,0 +1,640 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package spool implements the durable store-and-forward queue that sits between
+// the aggregator and the output clients. Records are appended to the write-ahead
+// log made of segment files. Each record is framed as follows:
+//
+//	+-------------+-------------+------------------+
+//	| length (u32)| crc32c (u32)| payload (length) |
+//	+-------------+-------------+------------------+
+//
+// The consumer position is tracked in the cursor file that is atomically replaced
+// every time the record is acknowledged. Segments that were entirely consumed are
+// removed. When the spool is reopened, torn records at the tail of segments are
+// truncated and the consumption resumes from the last acknowledged record, so
+// records are delivered at least once across crashes.
+package spool
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Policy determines how the spool behaves when the size cap is reached.
+type Policy string
+
+const (
+	// DropOldest evicts the oldest segment to make room for new records.
+	DropOldest Policy = "drop-oldest"
+	// Block blocks the producer until the consumer frees up space.
+	Block Policy = "block"
+)
+
+const (
+	// DefaultMaxSize is the default size cap of the spool
+	DefaultMaxSize = 1024 * 1024 * 1024
+	// DefaultSegmentSize is the default size of the segment file
+	DefaultSegmentSize = 16 * 1024 * 1024
+
+	segmentExt  = ".seg"
+	cursorName  = "cursor"
+	headerSize  = 8
+	cursorSize  = 20
+	segmentPerm = 0o600
+)
+
+var (
+	// ErrClosed is returned when the operation is attempted on the closed spool
+	ErrClosed = errors.New("spool is closed")
+	// ErrEmpty is returned by Peek when there are no pending records
+	ErrEmpty = errors.New("spool is empty")
+	// ErrTooLarge is returned when the record can't fit in the spool
+	ErrTooLarge = errors.New("record exceeds the spool size cap")
+	// ErrCorrupt is returned when the record fails the checksum validation
+	ErrCorrupt = errors.New("corrupt spool record")
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Options contains the spool tunables.
+type Options struct {
+	// MaxSize is the maximum size in bytes of all segments.
+	MaxSize int64
+	// SegmentSize is the size in bytes at which the new segment is started.
+	SegmentSize int64
+	// Policy is the eviction policy that applies when the spool is full.
+	Policy Policy
+}
+
+// Stats contains spool counters.
+type Stats struct {
+	// Size is the size in bytes of all segments.
+	Size int64 `json:"size"`
+	// Segments is the number of segment files.
+	Segments int `json:"segments"`
+	// Pending is the number of records that weren't acknowledged.
+	Pending int `json:"pending"`
+	// Evicted is the number of unacknowledged records dropped due to the size cap.
+	Evicted uint64 `json:"evicted"`
+}
+
+// segment describes a single segment file.
+type segment struct {
+	id      uint64
+	size    int64
+	records int
+}
+
+// position identifies the record offset within the segment.
+type position struct {
+	seg uint64
+	off int64
+}
+
+// Spool is the segmented write-ahead log with the single consumer.
+type Spool struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	dir  string
+	opts Options
+
+	segments []*segment
+	w        *os.File
+	r        *os.File
+	rseg     uint64
+
+	// cursor is the position of the next unacknowledged record
+	cursor position
+	// consumed is the number of acknowledged records in the cursor segment
+	consumed int
+	// peeked is the position of the record returned by the last Peek call
+	peeked position
+	// peekEnd is the offset past the peeked record. Zero if there is no peeked record
+	peekEnd int64
+
+	size    int64
+	pending int
+	evicted uint64
+
+	notify chan struct{}
+	closed bool
+}
+
+// Open opens the spool in the given directory and recovers the state of existing
+// segments. The directory is created if it doesn't exist.
+func Open(dir string, opts Options) (*Spool, error) {
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultMaxSize
+	}
+	if opts.SegmentSize <= 0 {
+		opts.SegmentSize = DefaultSegmentSize
+	}
+	if opts.SegmentSize > opts.MaxSize {
+		opts.SegmentSize = opts.MaxSize
+	}
+	switch opts.Policy {
+	case DropOldest, Block:
+	case "":
+		opts.Policy = DropOldest
+	default:
+		return nil, fmt.Errorf("unknown spool eviction policy: %s", opts.Policy)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	s := &Spool{dir: dir, opts: opts, notify: make(chan struct{}, 1)}
+	s.cond = sync.NewCond(&s.mu)
+	if err := s.recover(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// recover rebuilds the spool state from segment files and the cursor.
+func (s *Spool) recover() error {
+	ids, err := s.listSegments()
+	if err != nil {
+		return err
+	}
+	cursor, ok := s.readCursor()
+	if !ok || len(ids) == 0 || cursor.seg < ids[0] || cursor.seg > ids[len(ids)-1] {
+		cursor = position{}
+		if len(ids) > 0 {
+			cursor.seg = ids[0]
+		}
+	}
+
+	for _, id := range ids {
+		if id < cursor.seg {
+			// leftover of the consumed segment that wasn't removed
+			if err := os.Remove(s.segmentPath(id)); err != nil {
+				return err
+			}
+			continue
+		}
+		seg, consumed, off, err := s.scan(id, cursor)
+		if err != nil {
+			return err
+		}
+		if id == cursor.seg {
+			s.consumed, cursor.off = consumed, off
+		}
+		s.segments = append(s.segments, seg)
+		s.size += seg.size
+		s.pending += seg.records
+	}
+	s.pending -= s.consumed
+
+	if len(s.segments) == 0 {
+		if err := s.roll(); err != nil {
+			return err
+		}
+		cursor = position{seg: s.segments[0].id}
+	} else {
+		active := s.segments[len(s.segments)-1]
+		s.w, err = os.OpenFile(s.segmentPath(active.id), os.O_WRONLY|os.O_APPEND, segmentPerm)
+		if err != nil {
+			return err
+		}
+	}
+	if s.segment(cursor.seg) == nil {
+		// the cursor segment is gone, so resume from the oldest segment
+		cursor = position{seg: s.segments[0].id}
+	}
+	s.cursor = cursor
+
+	return nil
+}
+
+// scan validates records in the segment and truncates the segment at the
+// first torn or corrupt record. It also counts the records preceding the
+// cursor and returns the offset of the nearest record boundary.
+func (s *Spool) scan(id uint64, cursor position) (*segment, int, int64, error) {
+	path := s.segmentPath(id)
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	seg := &segment{id: id}
+	var (
+		consumed int
+		off      int64
+	)
+	for {
+		n, ok := validRecord(b[seg.size:])
+		if !ok {
+			break
+		}
+		seg.size += n
+		seg.records++
+		if id == cursor.seg && seg.size <= cursor.off {
+			consumed, off = seg.records, seg.size
+		}
+	}
+	if seg.size < int64(len(b)) {
+		if err := os.Truncate(path, seg.size); err != nil {
+			return nil, 0, 0, err
+		}
+	}
+	return seg, consumed, off, nil
+}
+
+// validRecord returns the size of the framed record at the
+// beginning of the buffer if the record is complete and valid.
+func validRecord(b []byte) (int64, bool) {
+	if len(b) < headerSize {
+		return 0, false
+	}
+	l := int(binary.LittleEndian.Uint32(b))
+	if l > len(b)-headerSize {
+		return 0, false
+	}
+	if crc32.Checksum(b[headerSize:headerSize+l], crcTable) != binary.LittleEndian.Uint32(b[4:]) {
+		return 0, false
+	}
+	return int64(headerSize + l), true
+}
+
+// Append writes the record to the active segment. If the size cap is reached, the
+// oldest segment is evicted or the call blocks until the consumer frees up space,
+// depending on the eviction policy. The record is flushed to stable storage before
+// this method returns.
+func (s *Spool) Append(rec []byte) error {
+	n := int64(headerSize + len(rec))
+	if n > s.opts.MaxSize {
+		return ErrTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for {
+		if s.closed {
+			return ErrClosed
+		}
+		if err := s.reclaim(); err != nil {
+			return err
+		}
+		if s.size+n <= s.opts.MaxSize {
+			break
+		}
+		active := s.active()
+		if s.cursor.seg == active.id && s.cursor.off == active.size && active.size > 0 {
+			// the active segment was entirely consumed, so
+			// start a new segment and remove the old one
+			if err := s.roll(); err != nil {
+				return err
+			}
+			continue
+		}
+		if s.opts.Policy == Block {
+			s.cond.Wait()
+			continue
+		}
+		if err := s.evict(); err != nil {
+			return err
+		}
+	}
+
+	active := s.active()
+	if active.size > 0 && active.size+n > s.opts.SegmentSize {
+		if err := s.roll(); err != nil {
+			return err
+		}
+		active = s.active()
+	}
+
+	b := make([]byte, n)
+	binary.LittleEndian.PutUint32(b, uint32(len(rec)))
+	binary.LittleEndian.PutUint32(b[4:], crc32.Checksum(rec, crcTable))
+	copy(b[headerSize:], rec)
+	if _, err := s.w.Write(b); err != nil {
+		// discard the partially written record
+		_ = s.w.Truncate(active.size)
+		return err
+	}
+	if err := s.w.Sync(); err != nil {
+		return err
+	}
+	active.size += n
+	active.records++
+	s.size += n
+	s.pending++
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// Peek returns the oldest unacknowledged record. The same record is returned
+// until it is acknowledged. If there are no pending records, ErrEmpty is returned.
+func (s *Spool) Peek() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, ErrClosed
+	}
+	if err := s.reclaim(); err != nil {
+		return nil, err
+	}
+	seg := s.segment(s.cursor.seg)
+	if seg == nil || s.cursor.off >= seg.size {
+		return nil, ErrEmpty
+	}
+
+	if s.r == nil || s.rseg != seg.id {
+		if err := s.closeReader(); err != nil {
+			return nil, err
+		}
+		f, err := os.Open(s.segmentPath(seg.id))
+		if err != nil {
+			return nil, err
+		}
+		s.r, s.rseg = f, seg.id
+	}
+
+	var hdr [headerSize]byte
+	if _, err := s.r.ReadAt(hdr[:], s.cursor.off); err != nil {
+		return nil, err
+	}
+	l := int64(binary.LittleEndian.Uint32(hdr[:]))
+	if s.cursor.off+headerSize+l > seg.size {
+		s.skip(seg)
+		return nil, ErrCorrupt
+	}
+	rec := make([]byte, l)
+	if _, err := s.r.ReadAt(rec, s.cursor.off+headerSize); err != nil {
+		return nil, err
+	}
+	if crc32.Checksum(rec, crcTable) != binary.LittleEndian.Uint32(hdr[4:]) {
+		s.skip(seg)
+		return nil, ErrCorrupt
+	}
+
+	s.peeked = s.cursor
+	s.peekEnd = s.cursor.off + headerSize + l
+
+	return rec, nil
+}
+
+// Ack acknowledges the record returned by the last Peek call and persists the
+// cursor. If the record was evicted in the meantime, Ack is a no-op.
+func (s *Spool) Ack() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	if s.peekEnd == 0 || s.peeked != s.cursor {
+		s.peekEnd = 0
+		return nil
+	}
+	s.cursor.off = s.peekEnd
+	s.peekEnd = 0
+	s.consumed++
+	s.pending--
+	defer s.cond.Broadcast()
+	if err := s.reclaim(); err != nil {
+		return err
+	}
+	return s.writeCursor()
+}
+
+// Unblock switches the spool to the drop-oldest eviction policy and
+// releases blocked producers. It is used to prevent producers from
+// stalling on shutdown when the consumer can't make progress.
+func (s *Spool) Unblock() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.opts.Policy = DropOldest
+	s.cond.Broadcast()
+}
+
+// Notify returns the channel that is signaled when the new record is appended.
+// The channel is closed when the spool is closed.
+func (s *Spool) Notify() <-chan struct{} { return s.notify }
+
+// Stats returns spool counters.
+func (s *Spool) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Stats{Size: s.size, Segments: len(s.segments), Pending: s.pending, Evicted: s.evicted}
+}
+
+// Close persists the cursor and closes segment files. Blocked
+// producers are released with the ErrClosed error.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	s.cond.Broadcast()
+	close(s.notify)
+
+	err := s.writeCursor()
+	if cerr := s.closeReader(); cerr != nil && err == nil {
+		err = cerr
+	}
+	if s.w != nil {
+		if cerr := s.w.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// reclaim removes segments preceding the active segment that were entirely consumed.
+func (s *Spool) reclaim() error {
+	for len(s.segments) > 1 {
+		first := s.segments[0]
+		if s.cursor.seg != first.id || s.cursor.off < first.size {
+			break
+		}
+		if err := s.remove(); err != nil {
+			return err
+		}
+		s.cursor = position{seg: s.segments[0].id}
+		s.consumed = 0
+	}
+	return nil
+}
+
+// evict removes the oldest segment along with its unacknowledged records.
+func (s *Spool) evict() error {
+	if len(s.segments) == 1 {
+		if err := s.roll(); err != nil {
+			return err
+		}
+	}
+	first := s.segments[0]
+	if s.cursor.seg == first.id {
+		unacked := first.records - s.consumed
+		s.evicted += uint64(unacked)
+		s.pending -= unacked
+		if err := s.remove(); err != nil {
+			return err
+		}
+		s.cursor = position{seg: s.segments[0].id}
+		s.consumed = 0
+		return nil
+	}
+	return s.remove()
+}
+
+// remove deletes the oldest segment file.
+func (s *Spool) remove() error {
+	first := s.segments[0]
+	if s.rseg == first.id {
+		if err := s.closeReader(); err != nil {
+			return err
+		}
+	}
+	if err := os.Remove(s.segmentPath(first.id)); err != nil {
+		return err
+	}
+	s.size -= first.size
+	s.segments = s.segments[1:]
+	return nil
+}
+
+// skip marks the rest of the segment as consumed after detecting the corrupt record.
+func (s *Spool) skip(seg *segment) {
+	s.pending -= seg.records - s.consumed
+	s.consumed = seg.records
+	s.cursor.off = seg.size
+}
+
+// roll starts the new active segment.
+func (s *Spool) roll() error {
+	var id uint64 = 1
+	if len(s.segments) > 0 {
+		id = s.active().id + 1
+	}
+	if s.w != nil {
+		if err := s.w.Close(); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(s.segmentPath(id), os.O_CREATE|os.O_EXCL|os.O_WRONLY|os.O_APPEND, segmentPerm)
+	if err != nil {
+		return err
+	}
+	s.w = f
+	s.segments = append(s.segments, &segment{id: id})
+	return nil
+}
+
+func (s *Spool) active() *segment { return s.segments[len(s.segments)-1] }
+
+func (s *Spool) segment(id uint64) *segment {
+	for _, seg := range s.segments {
+		if seg.id == id {
+			return seg
+		}
+	}
+	return nil
+}
+
+func (s *Spool) closeReader() error {
+	if s.r == nil {
+		return nil
+	}
+	err := s.r.Close()
+	s.r, s.rseg = nil, 0
+	return err
+}
+
+func (s *Spool) segmentPath(id uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", id, segmentExt))
+}
+
+func (s *Spool) listSegments() ([]uint64, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), segmentExt) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), segmentExt), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// writeCursor atomically replaces the cursor file.
+func (s *Spool) writeCursor() error {
+	var b [cursorSize]byte
+	binary.LittleEndian.PutUint64(b[:], s.cursor.seg)
+	binary.LittleEndian.PutUint64(b[8:], uint64(s.cursor.off))
+	binary.LittleEndian.PutUint32(b[16:], crc32.Checksum(b[:16], crcTable))
+
+	path := filepath.Join(s.dir, cursorName)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, segmentPerm)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(b[:]); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (s *Spool) readCursor() (position, bool) {
+	b, err := os.ReadFile(filepath.Join(s.dir, cursorName))
+	if err != nil || len(b) != cursorSize {
+		return position{}, false
+	}
+	if crc32.Checksum(b[:16], crcTable) != binary.LittleEndian.Uint32(b[16:]) {
+		return position{}, false
+	}
+	return position{
+		seg: binary.LittleEndian.Uint64(b),
+		off: int64(binary.LittleEndian.Uint64(b[8:])),
+	}, true
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package spool
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendPeekAck(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{SegmentSize: 64})
+	require.NoError(t, err)
+	defer s.Close()
+
+	_, err = s.Peek()
+	require.Equal(t, ErrEmpty, err)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s.Append([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	select {
+	case <-s.Notify():
+	default:
+		t.Fatal("expected append notification")
+	}
+	stats := s.Stats()
+	assert.Equal(t, 10, stats.Pending)
+	assert.True(t, stats.Segments > 1)
+
+	for i := 0; i < 10; i++ {
+		rec, err := s.Peek()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("record-%d", i), string(rec))
+		// the record is returned until acknowledged
+		rec, err = s.Peek()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("record-%d", i), string(rec))
+		require.NoError(t, s.Ack())
+	}
+
+	_, err = s.Peek()
+	require.Equal(t, ErrEmpty, err)
+	stats = s.Stats()
+	assert.Equal(t, 0, stats.Pending)
+	assert.Equal(t, 1, stats.Segments)
+}
+
+func TestReplay(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{SegmentSize: 64})
+	require.NoError(t, err)
+
+	for i := 0; i < 6; i++ {
+		require.NoError(t, s.Append([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	for i := 0; i < 4; i++ {
+		_, err := s.Peek()
+		require.NoError(t, err)
+		require.NoError(t, s.Ack())
+	}
+	// peeked but not acknowledged record is redelivered
+	_, err = s.Peek()
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	s, err = Open(dir, Options{SegmentSize: 64})
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, 2, s.Stats().Pending)
+	for i := 4; i < 6; i++ {
+		rec, err := s.Peek()
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("record-%d", i), string(rec))
+		require.NoError(t, s.Ack())
+	}
+	_, err = s.Peek()
+	require.Equal(t, ErrEmpty, err)
+}
+
+func TestReplayTornRecord(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{})
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Append([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	_, err = s.Peek()
+	require.NoError(t, err)
+	require.NoError(t, s.Ack())
+	// simulate the crash without closing the spool
+	path := s.segmentPath(s.active().id)
+	require.NoError(t, s.w.Close())
+	require.NoError(t, s.closeReader())
+
+	// append the partially written record
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{0xff, 0x00, 0x00, 0x00, 0x01, 0x02})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s, err = Open(dir, Options{})
+	require.NoError(t, err)
+	defer s.Close()
+
+	assert.Equal(t, 2, s.Stats().Pending)
+	rec, err := s.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "record-1", string(rec))
+	require.NoError(t, s.Ack())
+	require.NoError(t, s.Append([]byte("record-3")))
+	rec, err = s.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "record-2", string(rec))
+	require.NoError(t, s.Ack())
+	rec, err = s.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "record-3", string(rec))
+}
+
+func TestReplayCorruptCursor(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir, Options{})
+	require.NoError(t, err)
+	require.NoError(t, s.Append([]byte("record-0")))
+	require.NoError(t, s.Append([]byte("record-1")))
+	_, err = s.Peek()
+	require.NoError(t, err)
+	require.NoError(t, s.Ack())
+	require.NoError(t, s.Close())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, cursorName), []byte("garbage"), 0o600))
+
+	s, err = Open(dir, Options{})
+	require.NoError(t, err)
+	defer s.Close()
+	// records are delivered at least once
+	rec, err := s.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "record-0", string(rec))
+	assert.Equal(t, 2, s.Stats().Pending)
+}
+
+func TestDropOldest(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{MaxSize: 64, SegmentSize: 32, Policy: DropOldest})
+	require.NoError(t, err)
+	defer s.Close()
+
+	// each framed record takes 16 bytes
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s.Append([]byte(fmt.Sprintf("record-%d", i))))
+	}
+	stats := s.Stats()
+	assert.True(t, stats.Size <= 64)
+	assert.Equal(t, uint64(6), stats.Evicted)
+	assert.Equal(t, 4, stats.Pending)
+
+	rec, err := s.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "record-6", string(rec))
+
+	// the peeked record is evicted before it is acknowledged
+	require.NoError(t, s.Append([]byte("record-a")))
+	require.NoError(t, s.Append([]byte("record-b")))
+	require.NoError(t, s.Ack())
+	rec, err = s.Peek()
+	require.NoError(t, err)
+	assert.Equal(t, "record-8", string(rec))
+
+	assert.Equal(t, ErrTooLarge, s.Append(make([]byte, 64)))
+}
+
+func TestBlock(t *testing.T) {
+	s, err := Open(t.TempDir(), Options{MaxSize: 32, SegmentSize: 16, Policy: Block})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.Append([]byte("record-0")))
+	require.NoError(t, s.Append([]byte("record-1")))
+
+	done := make(chan error, 1)
+	go func() { done <- s.Append([]byte("record-2")) }()
+
+	select {
+	case <-done:
+		t.Fatal("append should block when the spool is full")
+	case <-time.After(time.Millisecond * 100):
+	}
+
+	_, err = s.Peek()
+	require.NoError(t, err)
+	require.NoError(t, s.Ack())
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("append should unblock after the record is acknowledged")
+	}
+	assert.Equal(t, uint64(0), s.Stats().Evicted)
+
+	go func() { done <- s.Append([]byte("record-3")) }()
+	time.Sleep(time.Millisecond * 50)
+	s.Unblock()
+	require.NoError(t, <-done)
+	assert.Equal(t, uint64(1), s.Stats().Evicted)
+
+	require.NoError(t, s.Close())
+	assert.Equal(t, ErrClosed, s.Append([]byte("record-4")))
+}
@@ -0,0 +1,240 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"expvar"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/rabbitstack/fibratus/pkg/aggregator/spool"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	log "github.com/sirupsen/logrus"
+)
+
+const mb = 1024 * 1024
+
+// spoolInitialBackoff is the wait time before the first publish retry
+var spoolInitialBackoff = time.Second
+
+var (
+	// spoolStats exposes spool counters and the current retry backoff of every spooled output
+	spoolStats = expvar.NewMap("aggregator.spool")
+	// spoolAppendErrors counts the batches that couldn't be written to the spool
+	spoolAppendErrors = expvar.NewMap("aggregator.spool.append.errors")
+	// spoolPublishRetries counts the publish retries of spooled batches
+	spoolPublishRetries = expvar.NewMap("aggregator.spool.publish.retries")
+)
+
+// spooler persists batches to the durable spool and forwards them to output
+// clients. The batch is acknowledged and removed from the spool only after the
+// client accepts it. Failed publishes are retried with the exponential backoff,
+// rotating the clients of the output group, so batches are published in order
+// and survive outages and restarts.
+type spooler struct {
+	typ       outputs.Type
+	spool     *spool.Spool
+	clients   []outputs.Client
+	connected []bool
+	next      int
+
+	backoff *backoff.ExponentialBackOff
+	delay   atomic.Int64
+
+	drainc chan struct{}
+	quit   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+func newSpooler(typ outputs.Type, config SpoolConfig, clients []outputs.Client) (*spooler, error) {
+	sp, err := spool.Open(filepath.Join(config.Dir, typ.String()), spool.Options{
+		MaxSize:     int64(config.MaxSize) * mb,
+		SegmentSize: int64(config.SegmentSize) * mb,
+		Policy:      config.Policy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	maxBackoff := config.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = time.Minute
+	}
+	s := &spooler{
+		typ:       typ,
+		spool:     sp,
+		clients:   clients,
+		connected: make([]bool, len(clients)),
+		backoff: &backoff.ExponentialBackOff{
+			InitialInterval:     spoolInitialBackoff,
+			RandomizationFactor: backoff.DefaultRandomizationFactor,
+			Multiplier:          backoff.DefaultMultiplier,
+			MaxInterval:         maxBackoff,
+			Stop:                backoff.Stop,
+			Clock:               backoff.SystemClock,
+		},
+		drainc: make(chan struct{}),
+		quit:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	s.backoff.Reset()
+
+	if stats := sp.Stats(); stats.Pending > 0 {
+		log.Infof("replaying %d spooled batches for %q output", stats.Pending, typ)
+	}
+	spoolStats.Set(typ.String(), expvar.Func(s.stats))
+
+	go s.run()
+
+	return s, nil
+}
+
+// stats returns spool counters along with the current retry backoff.
+func (s *spooler) stats() any {
+	stats := s.spool.Stats()
+	return map[string]any{
+		"size":     stats.Size,
+		"segments": stats.Segments,
+		"pending":  stats.Pending,
+		"evicted":  stats.Evicted,
+		"backoff":  time.Duration(s.delay.Load()).String(),
+	}
+}
+
+// append writes the batch to the spool. Depending on the eviction
+// policy, the call may block until the spool has room for the batch.
+func (s *spooler) append(b *kevent.Batch) {
+	if err := s.spool.Append(b.MarshalRaw()); err != nil {
+		spoolAppendErrors.Add(s.typ.String(), 1)
+		log.Warnf("couldn't write batch of %d events to %q output spool: %v", b.Len(), s.typ, err)
+	}
+}
+
+func (s *spooler) run() {
+	defer close(s.done)
+	for {
+		rec, err := s.spool.Peek()
+		switch err {
+		case nil:
+		case spool.ErrEmpty:
+			select {
+			case _, ok := <-s.spool.Notify():
+				if !ok {
+					return
+				}
+			case <-s.drainc:
+				return
+			case <-s.quit:
+				return
+			}
+			continue
+		case spool.ErrClosed:
+			return
+		case spool.ErrCorrupt:
+			log.Warnf("skipping corrupt segment in %q output spool", s.typ)
+			continue
+		default:
+			if !s.wait(err) {
+				return
+			}
+			continue
+		}
+
+		batch, err := kevent.NewBatchFromRaw(rec)
+		if err != nil {
+			log.Warnf("discarding undecodable batch from %q output spool: %v", s.typ, err)
+		} else if !s.publish(batch) {
+			return
+		}
+		if err := s.spool.Ack(); err != nil && err != spool.ErrClosed {
+			log.Warnf("couldn't acknowledge batch in %q output spool: %v", s.typ, err)
+		}
+	}
+}
+
+// publish publishes the batch to output clients until one of them
+// accepts the batch. It returns false if the spooler is stopped in
+// the meantime.
+func (s *spooler) publish(b *kevent.Batch) bool {
+	for {
+		err := s.connect(s.next)
+		if err == nil {
+			err = s.clients[s.next].Publish(b)
+		}
+		if err == nil {
+			s.backoff.Reset()
+			s.delay.Store(0)
+			return true
+		}
+		clientPublishErrors.Add(1)
+		spoolPublishRetries.Add(s.typ.String(), 1)
+		s.next = (s.next + 1) % len(s.clients)
+		if !s.wait(err) {
+			return false
+		}
+	}
+}
+
+func (s *spooler) connect(i int) error {
+	if s.connected[i] {
+		return nil
+	}
+	if err := s.clients[i].Connect(); err != nil {
+		return err
+	}
+	s.connected[i] = true
+	return nil
+}
+
+// wait sleeps for the next backoff interval. It returns false if the spooler is stopped.
+func (s *spooler) wait(err error) bool {
+	d := s.backoff.NextBackOff()
+	s.delay.Store(int64(d))
+	log.Warnf("couldn't publish spooled batch to %q output: %v. Retrying in %v...", s.typ, err, d)
+	select {
+	case <-time.After(d):
+		return true
+	case <-s.quit:
+		return false
+	}
+}
+
+// unblock releases producers blocked on the full spool.
+func (s *spooler) unblock() { s.spool.Unblock() }
+
+// drain instructs the spooler to stop once all pending batches are published.
+func (s *spooler) drain() { close(s.drainc) }
+
+// close stops the spooler and closes the spool and output clients. Batches
+// that weren't published remain in the spool and are replayed on restart.
+func (s *spooler) close() error {
+	s.once.Do(func() { close(s.quit) })
+	err := s.spool.Close()
+	for _, client := range s.clients {
+		if cerr := client.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyClient fails to publish while the outage is in progress.
+type flakyClient struct {
+	mu     sync.Mutex
+	outage bool
+	fails  int
+	seqs   []uint64
+}
+
+func (c *flakyClient) Connect() error { return nil }
+func (c *flakyClient) Close() error   { return nil }
+
+func (c *flakyClient) Publish(b *kevent.Batch) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.outage {
+		c.fails++
+		return errors.New("connection refused")
+	}
+	for _, e := range b.Events {
+		c.seqs = append(c.seqs, e.Seq)
+	}
+	return nil
+}
+
+func (c *flakyClient) setOutage(outage bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.outage = outage
+}
+
+func (c *flakyClient) published() []uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]uint64(nil), c.seqs...)
+}
+
+func (c *flakyClient) failures() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fails
+}
+
+func newSpoolBatch(seqs ...uint64) *kevent.Batch {
+	evts := make([]*kevent.Kevent, len(seqs))
+	for i, seq := range seqs {
+		evts[i] = &kevent.Kevent{
+			Seq:       seq,
+			Type:      ktypes.CreateFile,
+			Name:      "CreateFile",
+			Category:  ktypes.File,
+			Timestamp: time.Now(),
+			Kparams:   kevent.Kparams{},
+			Metadata:  map[kevent.MetadataKey]any{},
+		}
+	}
+	return kevent.NewBatch(evts...)
+}
+
+func TestSpoolerOutage(t *testing.T) {
+	spoolInitialBackoff = time.Millisecond * 10
+	config := SpoolConfig{Enabled: true, Dir: t.TempDir(), MaxSize: 16, SegmentSize: 1, MaxBackoff: time.Millisecond * 50}
+
+	client := &flakyClient{outage: true}
+	s, err := newSpooler(outputs.HTTP, config, []outputs.Client{client})
+	require.NoError(t, err)
+
+	s.append(newSpoolBatch(1, 2))
+	s.append(newSpoolBatch(3))
+
+	require.Eventually(t, func() bool { return client.failures() > 2 }, time.Second*5, time.Millisecond*10)
+	assert.Empty(t, client.published())
+
+	client.setOutage(false)
+	s.append(newSpoolBatch(4, 5))
+
+	require.Eventually(t, func() bool { return len(client.published()) == 5 }, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, client.published())
+	assert.Equal(t, 0, s.spool.Stats().Pending)
+
+	s.drain()
+	<-s.done
+	require.NoError(t, s.close())
+}
+
+func TestSpoolerReplay(t *testing.T) {
+	spoolInitialBackoff = time.Millisecond * 10
+	config := SpoolConfig{Enabled: true, Dir: t.TempDir(), MaxSize: 16, SegmentSize: 1, MaxBackoff: time.Millisecond * 50}
+
+	client := &flakyClient{outage: true}
+	s, err := newSpooler(outputs.Elasticsearch, config, []outputs.Client{client})
+	require.NoError(t, err)
+	s.append(newSpoolBatch(1))
+	s.append(newSpoolBatch(2, 3))
+	require.Eventually(t, func() bool { return client.failures() > 0 }, time.Second*5, time.Millisecond*10)
+	require.NoError(t, s.close())
+
+	// batches are replayed after restart
+	client = &flakyClient{}
+	s, err = newSpooler(outputs.Elasticsearch, config, []outputs.Client{client})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return len(client.published()) == 3 }, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, []uint64{1, 2, 3}, client.published())
+	require.NoError(t, s.close())
+}
+
+func TestSpoolerRotateClients(t *testing.T) {
+	spoolInitialBackoff = time.Millisecond * 10
+	config := SpoolConfig{Enabled: true, Dir: t.TempDir(), MaxSize: 16, SegmentSize: 1, MaxBackoff: time.Millisecond * 50}
+
+	down := &flakyClient{outage: true}
+	up := &flakyClient{}
+	s, err := newSpooler(outputs.HTTP, config, []outputs.Client{down, up})
+	require.NoError(t, err)
+	defer s.close()
+
+	s.append(newSpoolBatch(1))
+	require.Eventually(t, func() bool { return len(up.published()) == 1 }, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, 1, down.failures())
+}
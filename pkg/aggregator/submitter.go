@@ -19,39 +19,176 @@
 package aggregator
 
 import (
+	"errors"
+	"expvar"
+	"fmt"
+	"time"
+
 	"github.com/rabbitstack/fibratus/pkg/kevent"
 	"github.com/rabbitstack/fibratus/pkg/outputs"
+	log "github.com/sirupsen/logrus"
+)
+
+// outputQueueSize determines the maximum number of batches
+// that can be buffered in the work queue of every output
+const outputQueueSize = 100
+
+var (
+	// outputDroppedBatches counts the number of batches dropped because the output work queue is full
+	outputDroppedBatches = expvar.NewMap("aggregator.output.dropped.batches")
+	// outputFilteredEvents counts the number of events discarded by output filters
+	outputFilteredEvents = expvar.NewMap("aggregator.output.filtered.events")
 )
 
 // queue defines the type alias for the batch worker queue
 type queue chan *kevent.Batch
 
-// submitter initializes a group of load balanced output producers.
-type submitter struct {
+// output is an individual output with its own work queue
+// from which load balanced workers consume the batches. If
+// the spool is enabled, batches are routed through the spooler
+// instead of the work queue.
+type output struct {
+	typ     outputs.Type
 	wq      queue
 	workers []*worker
+	spooler *spooler
+	filter  Predicate
 }
 
-func newSubmitter(wq queue, outputConfig outputs.Config) (*submitter, error) {
-	output, err := outputs.Load(outputConfig.Type, outputConfig)
-	if err != nil {
-		return nil, err
+// batch returns the batch with the events that satisfy the output
+// filter. If the output has no filter, the original batch is returned.
+func (o *output) batch(b *kevent.Batch) *kevent.Batch {
+	if o.filter == nil {
+		return b
+	}
+	evts := make([]*kevent.Kevent, 0, len(b.Events))
+	for _, evt := range b.Events {
+		if o.filter.Run(evt) {
+			evts = append(evts, evt)
+		}
+	}
+	if n := len(b.Events) - len(evts); n > 0 {
+		outputFilteredEvents.Add(o.typ.String(), int64(n))
+	}
+	return kevent.NewBatch(evts...)
+}
+
+// submitter fans out the batches to all configured outputs. Every
+// output gets a dedicated work queue and a group of load balanced
+// producers, so a failing or slow output doesn't stall the others.
+type submitter struct {
+	outputs []*output
+}
+
+func newSubmitter(outputConfigs []outputs.Config, compiler FilterCompiler, spoolConfig SpoolConfig) (*submitter, error) {
+	s := &submitter{outputs: make([]*output, 0, len(outputConfigs))}
+	for _, config := range outputConfigs {
+		o := &output{typ: config.Type}
+		if config.Filter != "" {
+			if compiler == nil {
+				return nil, fmt.Errorf("%q output declares the filter but no filter compiler is available", config.Type)
+			}
+			var err error
+			o.filter, err = compiler(config.Filter)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter for %q output: %v", config.Type, err)
+			}
+		}
+		group, err := outputs.Load(config.Type, config)
+		if err != nil {
+			return nil, err
+		}
+		// the null output discards events, so there is nothing to spool
+		if spoolConfig.Enabled && config.Type != outputs.Null {
+			o.spooler, err = newSpooler(config.Type, spoolConfig, group.Clients)
+			if err != nil {
+				return nil, fmt.Errorf("unable to open spool for %q output: %v", config.Type, err)
+			}
+			s.outputs = append(s.outputs, o)
+			continue
+		}
+		o.wq = make(queue, outputQueueSize)
+		o.workers = make([]*worker, len(group.Clients))
+		for i, client := range group.Clients {
+			o.workers[i] = initWorker(o.wq, client)
+		}
+		s.outputs = append(s.outputs, o)
 	}
-	clients := output.Clients
-	workers := make([]*worker, len(clients))
+	return s, nil
+}
 
-	for i, client := range clients {
-		workers[i] = initWorker(wq, client)
+// submit pushes the batch to the work queue of each output. If
+// the work queue of the output is full, the batch is dropped.
+// Spooled outputs persist the batch to the spool instead.
+func (s *submitter) submit(b *kevent.Batch) {
+	for _, o := range s.outputs {
+		batch := o.batch(b)
+		if batch.Len() == 0 {
+			continue
+		}
+		if o.spooler != nil {
+			o.spooler.append(batch)
+			continue
+		}
+		select {
+		case o.wq <- batch:
+		default:
+			outputDroppedBatches.Add(o.typ.String(), 1)
+			log.Warnf("%q output work queue is full. Dropping batch of %d events", o.typ, batch.Len())
+		}
 	}
+}
 
-	return &submitter{wq: wq, workers: workers}, nil
+// unblock releases the producers blocked on full spools.
+func (s *submitter) unblock() {
+	for _, o := range s.outputs {
+		if o.spooler != nil {
+			o.spooler.unblock()
+		}
+	}
 }
 
-func (s *submitter) shutdown() error {
-	for _, w := range s.workers {
-		if err := w.close(); err != nil {
-			return err
+// shutdown closes the work queues and waits for the
+// workers to drain the pending batches before closing
+// the output clients. Spooled batches that couldn't be
+// published before the timeout are kept in the spool.
+func (s *submitter) shutdown(timeout time.Duration) error {
+	for _, o := range s.outputs {
+		if o.spooler != nil {
+			o.spooler.drain()
+			continue
+		}
+		close(o.wq)
+	}
+	done := make(chan struct{})
+	go func() {
+		for _, o := range s.outputs {
+			if o.spooler != nil {
+				<-o.spooler.done
+			}
+			for _, w := range o.workers {
+				<-w.done
+			}
+		}
+		close(done)
+	}()
+	var err error
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		err = errors.New("fail to flush events after stop timed out")
+	}
+	for _, o := range s.outputs {
+		if o.spooler != nil {
+			if err := o.spooler.close(); err != nil {
+				return err
+			}
+		}
+		for _, w := range o.workers {
+			if err := w.close(); err != nil {
+				return err
+			}
 		}
 	}
-	return nil
+	return err
 }
@@ -0,0 +1,122 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type predicate func(*kevent.Kevent) bool
+
+func (p predicate) Run(e *kevent.Kevent) bool { return p(e) }
+
+type recordingClient struct {
+	mu   sync.Mutex
+	evts []*kevent.Kevent
+}
+
+func (c *recordingClient) Connect() error { return nil }
+func (c *recordingClient) Close() error   { return nil }
+
+func (c *recordingClient) Publish(b *kevent.Batch) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evts = append(c.evts, b.Events...)
+	return nil
+}
+
+func (c *recordingClient) published() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.evts)
+}
+
+func newOutput(typ outputs.Type, size int, filter Predicate, clients ...outputs.Client) *output {
+	o := &output{typ: typ, wq: make(queue, size), filter: filter}
+	for _, client := range clients {
+		o.workers = append(o.workers, initWorker(o.wq, client))
+	}
+	return o
+}
+
+func TestSubmitterFanout(t *testing.T) {
+	all := &recordingClient{}
+	net := &recordingClient{}
+
+	s := &submitter{
+		outputs: []*output{
+			newOutput(outputs.Console, outputQueueSize, nil, all),
+			newOutput(outputs.Elasticsearch, outputQueueSize, predicate(func(e *kevent.Kevent) bool { return e.Category == ktypes.Net }), net),
+		},
+	}
+
+	s.submit(kevent.NewBatch(
+		&kevent.Kevent{Type: ktypes.SendTCPv4, Category: ktypes.Net},
+		&kevent.Kevent{Type: ktypes.CreateFile, Category: ktypes.File},
+		&kevent.Kevent{Type: ktypes.RecvTCPv4, Category: ktypes.Net},
+	))
+	s.submit(kevent.NewBatch(&kevent.Kevent{Type: ktypes.CreateFile, Category: ktypes.File}))
+
+	require.NoError(t, s.shutdown(time.Second))
+
+	assert.Equal(t, 4, all.published())
+	assert.Equal(t, 2, net.published())
+	for _, e := range net.evts {
+		assert.Equal(t, ktypes.Net, e.Category)
+	}
+}
+
+func TestSubmitterSaturatedOutput(t *testing.T) {
+	client := &recordingClient{}
+
+	s := &submitter{
+		outputs: []*output{
+			// output without workers never drains its queue
+			newOutput(outputs.HTTP, 1, nil),
+			newOutput(outputs.Console, outputQueueSize, nil, client),
+		},
+	}
+
+	for i := 0; i < 3; i++ {
+		s.submit(kevent.NewBatch(&kevent.Kevent{Type: ktypes.CreateFile, Category: ktypes.File}))
+	}
+
+	require.NoError(t, s.shutdown(time.Second))
+
+	assert.Equal(t, 3, client.published())
+	assert.Equal(t, "2", outputDroppedBatches.Get(outputs.HTTP.String()).String())
+}
+
+func TestNewSubmitterFilterErrors(t *testing.T) {
+	_, err := newSubmitter([]outputs.Config{{Type: outputs.Null, Filter: "kevt.category = 'net'"}}, nil, SpoolConfig{})
+	require.Error(t, err)
+
+	compiler := func(expr string) (Predicate, error) { return nil, errors.New("bad filter") }
+	_, err = newSubmitter([]outputs.Config{{Type: outputs.Null, Filter: "kevt.category ="}}, compiler, SpoolConfig{})
+	require.EqualError(t, err, `invalid filter for "null" output: bad filter`)
+}
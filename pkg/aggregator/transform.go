@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+)
+
+// transform wraps the transformer that is only
+// applied to events matching the optional predicate.
+type transform struct {
+	transformers.Transformer
+	typ  transformers.Type
+	when Predicate
+}
+
+// loadTransforms instantiates transformers and compiles their
+// when filters. The order of transformer configs is retained.
+func loadTransforms(configs []transformers.Config, compiler FilterCompiler) ([]transform, error) {
+	transforms := make([]transform, 0, len(configs))
+	for _, config := range configs {
+		t := transform{typ: config.Type}
+		if config.When != "" {
+			if compiler == nil {
+				return nil, fmt.Errorf("%q transformer declares the when filter but no filter compiler is available", config.Type)
+			}
+			var err error
+			t.when, err = compiler(config.When)
+			if err != nil {
+				return nil, fmt.Errorf("invalid when filter for %q transformer: %v", config.Type, err)
+			}
+		}
+		if compiler != nil {
+			config.Compiler = func(expr string) (transformers.Predicate, error) { return compiler(expr) }
+		}
+		transformer, err := transformers.Load(config)
+		if err != nil {
+			return nil, err
+		}
+		t.Transformer = transformer
+		transforms = append(transforms, t)
+	}
+	return transforms, nil
+}
+
+// apply runs the transformer if the event satisfies the when filter.
+func (t transform) apply(e *kevent.Kevent) error {
+	if t.when != nil && !t.when.Run(e) {
+		return nil
+	}
+	return t.Transform(e)
+}
+
+// close releases resources held by the transformer.
+func (t transform) close() error {
+	if c, ok := t.Transformer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package aggregator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/drop"
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/tags"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+func TestTransformWhen(t *testing.T) {
+	compiler := func(expr string) (Predicate, error) {
+		assert.Equal(t, "ps.name = 'svchost.exe'", expr)
+		return predicate(func(e *kevent.Kevent) bool { return e.PS != nil && e.PS.Name == "svchost.exe" }), nil
+	}
+	transforms, err := loadTransforms([]transformers.Config{
+		{
+			Type:        transformers.Tags,
+			Transformer: tags.Config{Tags: []tags.Tag{{Key: "service", Value: "yes"}}},
+			When:        "ps.name = 'svchost.exe'",
+		},
+	}, compiler)
+	require.NoError(t, err)
+	require.Len(t, transforms, 1)
+
+	svchost := &kevent.Kevent{PS: &pstypes.PS{Name: "svchost.exe"}, Metadata: make(kevent.Metadata)}
+	cmd := &kevent.Kevent{PS: &pstypes.PS{Name: "cmd.exe"}, Metadata: make(kevent.Metadata)}
+	for _, e := range []*kevent.Kevent{svchost, cmd} {
+		require.NoError(t, transforms[0].apply(e))
+	}
+
+	assert.Equal(t, "yes", svchost.Metadata["service"])
+	assert.NotContains(t, cmd.Metadata, kevent.MetadataKey("service"))
+}
+
+func TestTransformUnconditional(t *testing.T) {
+	transforms, err := loadTransforms([]transformers.Config{
+		{Type: transformers.Tags, Transformer: tags.Config{Tags: []tags.Tag{{Key: "env", Value: "prod"}}}},
+	}, nil)
+	require.NoError(t, err)
+
+	e := &kevent.Kevent{Metadata: make(kevent.Metadata)}
+	require.NoError(t, transforms[0].apply(e))
+	assert.Equal(t, "prod", e.Metadata["env"])
+}
+
+func TestLoadTransformsWhenErrors(t *testing.T) {
+	configs := []transformers.Config{{Type: transformers.Tags, Transformer: tags.Config{}, When: "kevt.name ="}}
+
+	_, err := loadTransforms(configs, nil)
+	require.Error(t, err)
+
+	compiler := func(expr string) (Predicate, error) { return nil, errors.New("bad filter") }
+	_, err = loadTransforms(configs, compiler)
+	require.EqualError(t, err, `invalid when filter for "tags" transformer: bad filter`)
+}
+
+func TestTransformDropped(t *testing.T) {
+	compiler := func(expr string) (Predicate, error) {
+		return predicate(func(e *kevent.Kevent) bool { return e.Type == ktypes.ReadFile }), nil
+	}
+	transforms, err := loadTransforms([]transformers.Config{
+		{Type: transformers.Drop, Transformer: drop.Config{Rules: []drop.Rule{{Filter: "kevt.name = 'ReadFile'"}}}},
+		{Type: transformers.Tags, Transformer: tags.Config{Tags: []tags.Tag{{Key: "env", Value: "prod"}}}},
+	}, compiler)
+	require.NoError(t, err)
+
+	agg := &BufferedAggregator{transforms: transforms}
+
+	read := &kevent.Kevent{Type: ktypes.ReadFile, Metadata: make(kevent.Metadata)}
+	assert.False(t, agg.transform(read))
+	// remaining transformers are skipped for dropped events
+	assert.Empty(t, read.Metadata)
+
+	create := &kevent.Kevent{Type: ktypes.CreateFile, Metadata: make(kevent.Metadata)}
+	assert.True(t, agg.transform(create))
+	assert.Equal(t, "prod", create.Metadata["env"])
+}
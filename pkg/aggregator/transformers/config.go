@@ -18,7 +18,10 @@
 
 package transformers
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+)
 
 // ErrInvalidConfig signals an invalid configuration input
 var ErrInvalidConfig = func(name Type) error { return fmt.Errorf("invalid config for %q transformer", name) }
@@ -27,4 +30,18 @@ var ErrInvalidConfig = func(name Type) error { return fmt.Errorf("invalid config
 type Config struct {
 	Type        Type
 	Transformer interface{}
+	// When is the optional filter expression. If specified,
+	// the transformer only applies to events matching the filter.
+	When string
+	// Compiler compiles the filter expressions declared
+	// in the transformer configuration.
+	Compiler FilterCompiler
 }
+
+// Predicate decides whether the event matches the filter expression.
+type Predicate interface {
+	Run(*kevent.Kevent) bool
+}
+
+// FilterCompiler compiles the filter expression into a predicate.
+type FilterCompiler func(expr string) (Predicate, error)
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drop
+
+import "github.com/spf13/pflag"
+
+const (
+	enabled = "transformers.drop.enabled"
+)
+
+// Rule describes the events that are discarded.
+type Rule struct {
+	// Name identifies the rule in metrics. The filter expression is used if the name is empty.
+	Name string `mapstructure:"name"`
+	// Filter is the filter expression that matches discarded events.
+	Filter string `mapstructure:"filter"`
+}
+
+// Config stores the configuration for the drop transformer.
+type Config struct {
+	// Rules is the list of rules evaluated for every event.
+	Rules []Rule `mapstructure:"rules"`
+	// Enabled indicates whether this transformer is enabled
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// AddFlags registers persistent flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Indicates if the drop transformer is enabled")
+}
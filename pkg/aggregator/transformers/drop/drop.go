@@ -0,0 +1,83 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drop
+
+import (
+	"errors"
+	"expvar"
+	"fmt"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+)
+
+// droppedEvents counts the number of discarded events per rule
+var droppedEvents = expvar.NewMap("transformers.drop.events")
+
+type rule struct {
+	name   string
+	filter transformers.Predicate
+}
+
+// drop transformer discards events matching any of the rules.
+type drop struct {
+	rules []rule
+}
+
+func init() {
+	transformers.Register(transformers.Drop, initDropTransformer)
+}
+
+func initDropTransformer(config transformers.Config) (transformers.Transformer, error) {
+	cfg, ok := config.Transformer.(Config)
+	if !ok {
+		return nil, transformers.ErrInvalidConfig(transformers.Drop)
+	}
+	if config.Compiler == nil {
+		return nil, errors.New("drop transformer requires the filter compiler")
+	}
+
+	rules := make([]rule, 0, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		name := r.Name
+		if name == "" {
+			name = r.Filter
+		}
+		if r.Filter == "" {
+			return nil, fmt.Errorf("%q drop rule has no filter", name)
+		}
+		filter, err := config.Compiler(r.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter in %q drop rule: %v", name, err)
+		}
+		rules = append(rules, rule{name: name, filter: filter})
+	}
+
+	return &drop{rules: rules}, nil
+}
+
+func (d drop) Transform(kevt *kevent.Kevent) error {
+	for _, r := range d.rules {
+		if r.filter.Run(kevt) {
+			droppedEvents.Add(r.name, 1)
+			return transformers.ErrDropped
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package drop
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+)
+
+type predicate func(*kevent.Kevent) bool
+
+func (p predicate) Run(e *kevent.Kevent) bool { return p(e) }
+
+// compiler resolves filter expressions from the fixed set of predicates
+func compiler(filters map[string]predicate) transformers.FilterCompiler {
+	return func(expr string) (transformers.Predicate, error) {
+		p, ok := filters[expr]
+		if !ok {
+			return nil, errors.New("unknown field")
+		}
+		return p, nil
+	}
+}
+
+func TestTransform(t *testing.T) {
+	filters := map[string]predicate{
+		"kevt.name = 'ReadFile'":   func(e *kevent.Kevent) bool { return e.Type == ktypes.ReadFile },
+		"kevt.name = 'CloseFile'":  func(e *kevent.Kevent) bool { return e.Type == ktypes.CloseFile },
+		"kevt.name = 'CreateFile'": func(e *kevent.Kevent) bool { return e.Type == ktypes.CreateFile },
+	}
+	transf, err := transformers.Load(transformers.Config{
+		Type: transformers.Drop,
+		Transformer: Config{Rules: []Rule{
+			{Name: "reads", Filter: "kevt.name = 'ReadFile'"},
+			{Filter: "kevt.name = 'CloseFile'"},
+		}},
+		Compiler: compiler(filters),
+	})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, transf.Transform(&kevent.Kevent{Type: ktypes.ReadFile}), transformers.ErrDropped)
+	require.ErrorIs(t, transf.Transform(&kevent.Kevent{Type: ktypes.ReadFile}), transformers.ErrDropped)
+	require.ErrorIs(t, transf.Transform(&kevent.Kevent{Type: ktypes.CloseFile}), transformers.ErrDropped)
+	require.NoError(t, transf.Transform(&kevent.Kevent{Type: ktypes.CreateFile}))
+
+	assert.Equal(t, "2", droppedEvents.Get("reads").String())
+	assert.Equal(t, "1", droppedEvents.Get("kevt.name = 'CloseFile'").String())
+}
+
+func TestInvalidConfig(t *testing.T) {
+	filters := map[string]predicate{"kevt.name = 'ReadFile'": func(e *kevent.Kevent) bool { return true }}
+
+	_, err := transformers.Load(transformers.Config{
+		Type:        transformers.Drop,
+		Transformer: Config{Rules: []Rule{{Name: "reads", Filter: "kevt.name = 'ReadFile'"}}},
+	})
+	require.Error(t, err)
+
+	_, err = transformers.Load(transformers.Config{
+		Type:        transformers.Drop,
+		Transformer: Config{Rules: []Rule{{Name: "reads"}}},
+		Compiler:    compiler(filters),
+	})
+	require.EqualError(t, err, `"reads" drop rule has no filter`)
+
+	_, err = transformers.Load(transformers.Config{
+		Type:        transformers.Drop,
+		Transformer: Config{Rules: []Rule{{Name: "reads", Filter: "kevt.nam = 'ReadFile'"}}},
+		Compiler:    compiler(filters),
+	})
+	require.EqualError(t, err, `invalid filter in "reads" drop rule: unknown field`)
+}
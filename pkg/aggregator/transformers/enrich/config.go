@@ -0,0 +1,102 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package enrich
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	enabled        = "transformers.enrich.enabled"
+	reloadDebounce = "transformers.enrich.reload-debounce"
+)
+
+// Format is the lookup table file format.
+type Format string
+
+const (
+	// CSV denotes the comma-separated values file with the header row.
+	CSV Format = "csv"
+	// JSON denotes the JSON file with an array of objects or an object of objects.
+	JSON Format = "json"
+)
+
+// Match determines how the event field value is matched against table keys.
+type Match string
+
+const (
+	// Exact matches keys that are equal to the field value.
+	Exact Match = "exact"
+	// Glob matches keys with the * and ? wildcards.
+	Glob Match = "glob"
+	// CIDR matches IP addresses against network prefixes.
+	CIDR Match = "cidr"
+)
+
+// Target is the destination of appended columns.
+type Target string
+
+const (
+	// Metadata appends columns to event metadata.
+	Metadata Target = "metadata"
+	// Params appends columns as event parameters.
+	Params Target = "params"
+)
+
+// Table describes the lookup table and the way it is joined with events.
+type Table struct {
+	// Name identifies the table in metrics and logs. Defaults to the file name.
+	Name string `mapstructure:"name"`
+	// Path is the location of the lookup table file.
+	Path string `mapstructure:"path"`
+	// Format is the table file format. It is inferred from the file extension if empty.
+	Format Format `mapstructure:"format"`
+	// Field is the event field whose value is looked up in the table.
+	Field string `mapstructure:"field"`
+	// Key is the name of the column that contains table keys. Defaults to the first CSV column.
+	Key string `mapstructure:"key"`
+	// Match determines how the field value is matched against table keys.
+	Match Match `mapstructure:"match"`
+	// Columns is the list of columns appended to the event. Defaults to all but the key column.
+	Columns []string `mapstructure:"columns"`
+	// Target determines whether columns are appended to metadata or parameters.
+	Target Target `mapstructure:"target"`
+	// Prefix is prepended to the names of appended columns.
+	Prefix string `mapstructure:"prefix"`
+	// CaseSensitive indicates if exact and glob keys are matched case-sensitively.
+	CaseSensitive bool `mapstructure:"case-sensitive"`
+}
+
+// Config stores the configuration for the enrich transformer.
+type Config struct {
+	// Tables is the list of lookup tables. Tables are looked up in the declaration order.
+	Tables []Table `mapstructure:"tables"`
+	// ReloadDebounce is the quiet period after the last table file change before the table is reloaded.
+	ReloadDebounce time.Duration `mapstructure:"reload-debounce"`
+	// Enabled indicates whether this transformer is enabled
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// AddFlags registers persistent flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Indicates if the enrich transformer is enabled")
+	flags.Duration(reloadDebounce, time.Second, "The quiet period after the last table file change before the table is reloaded")
+}
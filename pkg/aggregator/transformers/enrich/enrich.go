@@ -0,0 +1,246 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package enrich
+
+import (
+	"expvar"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+var (
+	// enrichMatches counts the number of enriched events per table
+	enrichMatches = expvar.NewMap("transformers.enrich.matches")
+	// enrichReloads counts the number of successful table reloads
+	enrichReloads = expvar.NewInt("transformers.enrich.reloads")
+	// enrichReloadErrors counts the number of failed table reloads
+	enrichReloadErrors = expvar.NewInt("transformers.enrich.reload.errors")
+)
+
+// lookup holds the active lookup table. The table is atomically
+// replaced when the file changes, so lookups never block.
+type lookup struct {
+	config Table
+	table  atomic.Pointer[table]
+	timer  *time.Timer
+}
+
+// enrich transformer joins events with lookup tables. The value of the event
+// field is looked up in the table, and the columns of the matching row are
+// appended to event metadata or parameters.
+type enrich struct {
+	lookups  []*lookup
+	debounce time.Duration
+	watcher  *fsnotify.Watcher
+}
+
+func init() {
+	transformers.Register(transformers.Enrich, initEnrichTransformer)
+}
+
+func initEnrichTransformer(config transformers.Config) (transformers.Transformer, error) {
+	cfg, ok := config.Transformer.(Config)
+	if !ok {
+		return nil, transformers.ErrInvalidConfig(transformers.Enrich)
+	}
+
+	e := &enrich{
+		lookups:  make([]*lookup, 0, len(cfg.Tables)),
+		debounce: cfg.ReloadDebounce,
+	}
+	if e.debounce == 0 {
+		e.debounce = time.Second
+	}
+	for _, t := range cfg.Tables {
+		t, err := normalize(t)
+		if err != nil {
+			return nil, err
+		}
+		tbl, err := loadTable(t)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load %q lookup table: %v", t.Name, err)
+		}
+		log.Infof("loaded %q lookup table with %d row(s)", t.Name, tbl.rows)
+		l := &lookup{config: t}
+		l.table.Store(tbl)
+		e.lookups = append(e.lookups, l)
+	}
+
+	if err := e.watch(); err != nil {
+		log.Warnf("lookup tables won't be reloaded on change: %v", err)
+	}
+
+	return e, nil
+}
+
+// normalize validates the table config and applies defaults.
+func normalize(t Table) (Table, error) {
+	if t.Path == "" {
+		return t, fmt.Errorf("lookup table %q has no path", t.Name)
+	}
+	t.Path = filepath.Clean(t.Path)
+	if t.Name == "" {
+		t.Name = filepath.Base(t.Path)
+	}
+	if t.Field == "" {
+		return t, fmt.Errorf("lookup table %q has no event field", t.Name)
+	}
+	if t.Format == "" {
+		switch strings.ToLower(filepath.Ext(t.Path)) {
+		case ".csv":
+			t.Format = CSV
+		case ".json":
+			t.Format = JSON
+		default:
+			return t, fmt.Errorf("unable to infer the format of %q lookup table. Please specify the format", t.Name)
+		}
+	}
+	switch t.Match {
+	case "":
+		t.Match = Exact
+	case Exact, Glob, CIDR:
+	default:
+		return t, fmt.Errorf("unknown match type %q in %q lookup table", t.Match, t.Name)
+	}
+	switch t.Target {
+	case "":
+		t.Target = Metadata
+	case Metadata, Params:
+	default:
+		return t, fmt.Errorf("unknown target %q in %q lookup table", t.Target, t.Name)
+	}
+	return t, nil
+}
+
+func (e *enrich) Transform(kevt *kevent.Kevent) error {
+	for _, l := range e.lookups {
+		v, ok := outputs.FieldValue(kevt, l.config.Field)
+		if !ok {
+			continue
+		}
+		r, ok := l.table.Load().index.lookup(v)
+		if !ok {
+			continue
+		}
+		enrichMatches.Add(l.config.Name, 1)
+		if l.config.Target == Params && kevt.Kparams == nil {
+			kevt.Kparams = make(kevent.Kparams)
+		}
+		for _, a := range r {
+			if l.config.Target == Params {
+				kevt.Kparams.Append(a.name, kparams.UnicodeString, a.value)
+			} else {
+				kevt.AddMeta(kevent.MetadataKey(a.name), a.value)
+			}
+		}
+	}
+	return nil
+}
+
+// watch monitors directories of lookup table files.
+func (e *enrich) watch() error {
+	if len(e.lookups) == 0 {
+		return nil
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	dirs := make(map[string]bool)
+	for _, l := range e.lookups {
+		dir := filepath.Dir(l.config.Path)
+		if dirs[dir] {
+			continue
+		}
+		if err := w.Add(dir); err != nil {
+			_ = w.Close()
+			return fmt.Errorf("unable to watch %s directory: %v", dir, err)
+		}
+		dirs[dir] = true
+	}
+	e.watcher = w
+	go e.run(w)
+	return nil
+}
+
+// Close stops watching lookup table files.
+func (e *enrich) Close() error {
+	if e.watcher == nil {
+		return nil
+	}
+	return e.watcher.Close()
+}
+
+func (e *enrich) run(w *fsnotify.Watcher) {
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if ev.Op == fsnotify.Chmod {
+				continue
+			}
+			for _, l := range e.lookups {
+				if !strings.EqualFold(filepath.Clean(ev.Name), l.config.Path) {
+					continue
+				}
+				log.Debugf("lookup table file %s changed: %s", ev.Name, ev.Op)
+				// editors and exporters usually emit several
+				// events when the file is written, so the
+				// reload is deferred until the file settles
+				if l.timer == nil {
+					l.timer = time.AfterFunc(e.debounce, l.reload)
+				} else {
+					l.timer.Reset(e.debounce)
+				}
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("lookup tables watcher error: %v", err)
+		}
+	}
+}
+
+// reload loads the table file and replaces the active table. If
+// the file can't be loaded, the previous table remains active.
+func (l *lookup) reload() {
+	tbl, err := loadTable(l.config)
+	if err != nil {
+		enrichReloadErrors.Add(1)
+		log.Errorf("unable to reload %q lookup table. Previous table remains active: %v", l.config.Name, err)
+		return
+	}
+	l.table.Store(tbl)
+	enrichReloads.Add(1)
+	log.Infof("%q lookup table reloaded with %d row(s)", l.config.Name, tbl.rows)
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package enrich
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+const assetsCSV = "\ufeffexe,owner,business_unit,status\n" +
+	"C:\\Program Files\\Acme\\*,it-ops,finance,known-good\n" +
+	"C:\\Program Files\\Acme\\updater.exe,it-ops,finance,\n" +
+	"C:\\Windows\\System32\\svchost.exe,platform,core,known-good\n"
+
+const networksJSON = `{
+  "10.0.0.0/8": {"zone": "internal", "vlan": 10},
+  "10.20.0.0/16": {"zone": "servers", "vlan": 20},
+  "2001:db8::/32": {"zone": "lab"}
+}`
+
+func TestTransformCSVMetadata(t *testing.T) {
+	path := writeTable(t, "assets.csv", assetsCSV)
+	transf, err := transformers.Load(transformers.Config{
+		Type: transformers.Enrich,
+		Transformer: Config{Tables: []Table{
+			{Path: path, Field: "ps.exe", Match: Glob, Prefix: "asset_"},
+		}},
+	})
+	require.NoError(t, err)
+
+	var tests = []struct {
+		exe  string
+		meta kevent.Metadata
+	}{
+		{`C:\Windows\system32\svchost.exe`, kevent.Metadata{"asset_owner": "platform", "asset_business_unit": "core", "asset_status": "known-good"}},
+		{`C:\Program Files\Acme\bin\agent.exe`, kevent.Metadata{"asset_owner": "it-ops", "asset_business_unit": "finance", "asset_status": "known-good"}},
+		// the pattern with the longest literal prefix wins and empty values are skipped
+		{`C:\Program Files\Acme\updater.exe`, kevent.Metadata{"asset_owner": "it-ops", "asset_business_unit": "finance"}},
+		{`C:\Windows\notepad.exe`, kevent.Metadata{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.exe, func(t *testing.T) {
+			e := &kevent.Kevent{Type: ktypes.CreateFile, Metadata: make(kevent.Metadata), PS: &pstypes.PS{Exe: tt.exe}}
+			require.NoError(t, transf.Transform(e))
+			assert.Equal(t, tt.meta, e.Metadata)
+		})
+	}
+}
+
+func TestTransformJSONParams(t *testing.T) {
+	path := writeTable(t, "networks.json", networksJSON)
+	transf, err := transformers.Load(transformers.Config{
+		Type: transformers.Enrich,
+		Transformer: Config{Tables: []Table{
+			{Name: "networks", Path: path, Field: "net.dip", Match: CIDR, Columns: []string{"zone"}, Target: Params},
+		}},
+	})
+	require.NoError(t, err)
+
+	var tests = []struct {
+		ip   string
+		zone string
+	}{
+		{"10.1.2.3", "internal"},
+		{"10.20.1.5", "servers"},
+		{"2001:db8::1", "lab"},
+		{"8.8.8.8", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			e := &kevent.Kevent{
+				Type: ktypes.ConnectTCPv4,
+				Kparams: kevent.Kparams{
+					kparams.NetDIP: {Name: kparams.NetDIP, Type: kparams.IPv4, Value: net.ParseIP(tt.ip)},
+				},
+			}
+			require.NoError(t, transf.Transform(e))
+			if tt.zone == "" {
+				assert.False(t, e.Kparams.Contains("zone"))
+				return
+			}
+			assert.Equal(t, tt.zone, e.GetParamAsString("zone"))
+			assert.False(t, e.Kparams.Contains("vlan"))
+		})
+	}
+	assert.Equal(t, "3", enrichMatches.Get("networks").String())
+}
+
+func TestTransformExact(t *testing.T) {
+	path := writeTable(t, "hashes.json", `[
+  {"path": "C:\\Windows\\System32\\kernel32.dll", "verdict": "trusted", "score": 0},
+  {"path": "C:\\Users\\admin\\Downloads\\dropper.dll", "verdict": "malicious", "score": 97.5}
+]`)
+	transf, err := transformers.Load(transformers.Config{
+		Type: transformers.Enrich,
+		Transformer: Config{Tables: []Table{
+			{Path: path, Field: "file.path", Key: "path"},
+		}},
+	})
+	require.NoError(t, err)
+
+	e := &kevent.Kevent{
+		Type:     ktypes.CreateFile,
+		Metadata: make(kevent.Metadata),
+		Kparams: kevent.Kparams{
+			kparams.FilePath: {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: `C:\users\admin\downloads\dropper.dll`},
+		},
+	}
+	require.NoError(t, transf.Transform(e))
+	assert.Equal(t, kevent.Metadata{"verdict": "malicious", "score": "97.5"}, e.Metadata)
+}
+
+func TestReload(t *testing.T) {
+	path := writeTable(t, "assets.csv", "exe,owner\nC:\\Windows\\System32\\svchost.exe,platform\n")
+	transf, err := transformers.Load(transformers.Config{
+		Type: transformers.Enrich,
+		Transformer: Config{
+			Tables:         []Table{{Path: path, Field: "ps.exe"}},
+			ReloadDebounce: time.Millisecond * 50,
+		},
+	})
+	require.NoError(t, err)
+
+	owner := func() any {
+		e := &kevent.Kevent{Metadata: make(kevent.Metadata), PS: &pstypes.PS{Exe: `C:\Windows\System32\svchost.exe`}}
+		require.NoError(t, transf.Transform(e))
+		return e.Metadata["owner"]
+	}
+	assert.Equal(t, "platform", owner())
+
+	require.NoError(t, os.WriteFile(path, []byte("exe,owner\nC:\\Windows\\System32\\svchost.exe,secops\n"), 0644))
+	assert.Eventually(t, func() bool { return owner() == "secops" }, time.Second*5, time.Millisecond*20)
+
+	// the broken table doesn't replace the active table
+	require.NoError(t, os.WriteFile(path, []byte("exe,owner\n\"C:\\Windows,secops\n"), 0644))
+	assert.Eventually(t, func() bool { return enrichReloadErrors.Value() > 0 }, time.Second*5, time.Millisecond*20)
+	assert.Equal(t, "secops", owner())
+
+	// tables are no longer reloaded once the transformer is closed
+	require.NoError(t, transf.(io.Closer).Close())
+	require.NoError(t, os.WriteFile(path, []byte("exe,owner\nC:\\Windows\\System32\\svchost.exe,platform\n"), 0644))
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, "secops", owner())
+}
+
+func TestInvalidConfig(t *testing.T) {
+	path := writeTable(t, "assets.csv", assetsCSV)
+	networks := writeTable(t, "networks.csv", "network,zone\n10.0.0.0/33,internal\n")
+
+	var tests = []struct {
+		name  string
+		table Table
+	}{
+		{"no path", Table{Field: "ps.exe"}},
+		{"no field", Table{Path: path}},
+		{"unknown format", Table{Path: filepath.Join(t.TempDir(), "assets.txt"), Field: "ps.exe"}},
+		{"unknown match", Table{Path: path, Field: "ps.exe", Match: "regex"}},
+		{"unknown target", Table{Path: path, Field: "ps.exe", Target: "tags"}},
+		{"unknown column", Table{Path: path, Field: "ps.exe", Columns: []string{"location"}}},
+		{"missing file", Table{Path: filepath.Join(t.TempDir(), "missing.csv"), Field: "ps.exe"}},
+		{"invalid cidr", Table{Path: networks, Field: "net.dip", Match: CIDR}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := transformers.Load(transformers.Config{Type: transformers.Enrich, Transformer: Config{Tables: []Table{tt.table}}})
+			require.Error(t, err)
+		})
+	}
+}
+
+func writeTable(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
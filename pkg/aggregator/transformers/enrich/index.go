@@ -0,0 +1,282 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package enrich
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+
+	"github.com/rabbitstack/fibratus/pkg/util/wildcard"
+)
+
+// attr is the column appended to the event.
+type attr struct {
+	name  string
+	value string
+}
+
+// row contains the attributes of the table row.
+type row []attr
+
+// index finds the table row for the event field value.
+type index interface {
+	insert(key string, r row) error
+	lookup(value string) (row, bool)
+}
+
+func newIndex(match Match, caseSensitive bool) index {
+	switch match {
+	case Glob:
+		return &globIndex{prefixes: &globNode{}, suffixes: &globNode{}, caseSensitive: caseSensitive}
+	case CIDR:
+		return &cidrIndex{v4: &cidrNode{}, v6: &cidrNode{}}
+	default:
+		return &exactIndex{rows: make(map[string]row), caseSensitive: caseSensitive}
+	}
+}
+
+// exactIndex is the hash map of table keys.
+type exactIndex struct {
+	rows          map[string]row
+	caseSensitive bool
+}
+
+func (i *exactIndex) insert(key string, r row) error {
+	if !i.caseSensitive {
+		key = strings.ToLower(key)
+	}
+	if _, ok := i.rows[key]; !ok {
+		i.rows[key] = r
+	}
+	return nil
+}
+
+func (i *exactIndex) lookup(value string) (row, bool) {
+	if !i.caseSensitive {
+		value = strings.ToLower(value)
+	}
+	r, ok := i.rows[value]
+	return r, ok
+}
+
+// globIndex consists of two tries of literal pattern fragments. Every
+// pattern is indexed by the longer of its literal prefix preceding the
+// first star and its literal suffix following the last star. Suffixes
+// are stored reversed, so patterns with leading wildcards, such as
+// ?:\Windows\* or *\notepad.exe, don't end up in the root node. The '?'
+// wildcard is the trie edge that matches any character. Lookups walk both
+// tries along the field value and only evaluate patterns found on the way.
+// Patterns with longer literal fragments are more specific, so they are
+// evaluated first.
+type globIndex struct {
+	prefixes      *globNode
+	suffixes      *globNode
+	caseSensitive bool
+}
+
+type globNode struct {
+	children map[rune]*globNode
+	any      *globNode
+	depth    int
+	patterns []globPattern
+}
+
+type globPattern struct {
+	pattern string
+	row     row
+}
+
+func (i *globIndex) insert(key string, r row) error {
+	if !i.caseSensitive {
+		key = strings.ToLower(key)
+	}
+	chars := []rune(key)
+	first, last := len(chars), len(chars)
+	for j, c := range chars {
+		if c != '*' {
+			continue
+		}
+		if j < first {
+			first = j
+		}
+		last = j
+	}
+	node := i.prefixes
+	if last < len(chars) && len(chars)-last-1 > first {
+		// the suffix is the longer literal
+		node = i.suffixes
+		chars = chars[last+1:]
+		for a, b := 0, len(chars)-1; a < b; a, b = a+1, b-1 {
+			chars[a], chars[b] = chars[b], chars[a]
+		}
+	} else {
+		chars = chars[:first]
+	}
+	for _, c := range chars {
+		node = node.child(c)
+	}
+	node.patterns = append(node.patterns, globPattern{pattern: key, row: r})
+	return nil
+}
+
+func (i *globIndex) lookup(value string) (row, bool) {
+	if !i.caseSensitive {
+		value = strings.ToLower(value)
+	}
+	var stack [32]*globNode
+	chars := []rune(value)
+	nodes := i.prefixes.collect(chars, false, stack[:0])
+	nodes = i.suffixes.collect(chars, true, nodes)
+	sort.SliceStable(nodes, func(a, b int) bool { return nodes[a].depth > nodes[b].depth })
+	for _, node := range nodes {
+		for _, p := range node.patterns {
+			if wildcard.Match(p.pattern, value) {
+				return p.row, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// child returns the child node for the given pattern character.
+func (n *globNode) child(c rune) *globNode {
+	if c == '?' {
+		if n.any == nil {
+			n.any = &globNode{depth: n.depth + 1}
+		}
+		return n.any
+	}
+	if n.children == nil {
+		n.children = make(map[rune]*globNode)
+	}
+	child, ok := n.children[c]
+	if !ok {
+		child = &globNode{depth: n.depth + 1}
+		n.children[c] = child
+	}
+	return child
+}
+
+// collect appends nodes with patterns that are reachable by walking
+// the trie along the value. The value is walked backwards if reverse
+// is true.
+func (n *globNode) collect(value []rune, reverse bool, nodes []*globNode) []*globNode {
+	if len(n.patterns) > 0 {
+		nodes = append(nodes, n)
+	}
+	if len(value) == 0 {
+		return nodes
+	}
+	c, rest := value[0], value[1:]
+	if reverse {
+		c, rest = value[len(value)-1], value[:len(value)-1]
+	}
+	if child := n.children[c]; child != nil {
+		nodes = child.collect(rest, reverse, nodes)
+	}
+	if n.any != nil {
+		nodes = n.any.collect(rest, reverse, nodes)
+	}
+	return nodes
+}
+
+// cidrIndex is the binary trie of network prefixes. Lookups
+// return the row of the longest prefix containing the address.
+type cidrIndex struct {
+	v4 *cidrNode
+	v6 *cidrNode
+}
+
+type cidrNode struct {
+	children [2]*cidrNode
+	row      row
+}
+
+func (i *cidrIndex) insert(key string, r row) error {
+	var prefix netip.Prefix
+	if strings.Contains(key, "/") {
+		p, err := netip.ParsePrefix(key)
+		if err != nil {
+			return fmt.Errorf("invalid CIDR %q: %v", key, err)
+		}
+		prefix = p.Masked()
+	} else {
+		addr, err := netip.ParseAddr(key)
+		if err != nil {
+			return fmt.Errorf("invalid IP address %q: %v", key, err)
+		}
+		prefix = netip.PrefixFrom(addr, addr.BitLen())
+	}
+	addr := prefix.Addr().Unmap()
+	bits := prefix.Bits()
+	if prefix.Addr().Is4In6() {
+		bits -= 96
+		if bits < 0 {
+			return fmt.Errorf("invalid CIDR %q: IPv4-mapped prefix is shorter than 96 bits", key)
+		}
+	}
+	node := i.root(addr)
+	b := addr.AsSlice()
+	for j := 0; j < bits; j++ {
+		bit := (b[j/8] >> (7 - j%8)) & 1
+		if node.children[bit] == nil {
+			node.children[bit] = &cidrNode{}
+		}
+		node = node.children[bit]
+	}
+	if node.row == nil {
+		node.row = r
+	}
+	return nil
+}
+
+func (i *cidrIndex) lookup(value string) (row, bool) {
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		return nil, false
+	}
+	addr = addr.Unmap()
+	node := i.root(addr)
+	match := node.row
+	b := addr.As16()
+	offset := 0
+	if addr.Is4() {
+		offset = 12
+	}
+	for j := 0; j < addr.BitLen(); j++ {
+		bit := (b[offset+j/8] >> (7 - j%8)) & 1
+		node = node.children[bit]
+		if node == nil {
+			break
+		}
+		if node.row != nil {
+			match = node.row
+		}
+	}
+	return match, match != nil
+}
+
+func (i *cidrIndex) root(addr netip.Addr) *cidrNode {
+	if addr.Is4() {
+		return i.v4
+	}
+	return i.v6
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package enrich
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlobIndex(t *testing.T) {
+	idx := newIndex(Glob, false)
+	for _, key := range []string{`*\temp\*`, `C:\Windows\*`, `C:\Windows\System32\*.dll`, `C:\Windows\System32\ntdll.dll`, `C:\Windows\System3?\*.exe`} {
+		require.NoError(t, idx.insert(key, row{{name: "key", value: key}}))
+	}
+
+	var tests = []struct {
+		value string
+		key   string
+	}{
+		{`C:\Windows\System32\ntdll.dll`, `C:\Windows\System32\ntdll.dll`},
+		{`C:\WINDOWS\System32\kernel32.dll`, `C:\Windows\System32\*.dll`},
+		{`C:\Windows\System32\cmd.exe`, `C:\Windows\System3?\*.exe`},
+		{`C:\Windows\notepad.exe`, `C:\Windows\*`},
+		{`D:\build\temp\out.obj`, `*\temp\*`},
+		{`D:\build\out.obj`, ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			r, ok := idx.lookup(tt.value)
+			if tt.key == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.key, r[0].value)
+		})
+	}
+}
+
+func TestGlobIndexLeadingWildcards(t *testing.T) {
+	idx := newIndex(Glob, false)
+	for _, key := range []string{`?:\Windows\*`, `?:\Windows\System32\*`, `*\svchost.exe`, `*\System32\svchost.exe`, `C:\Users\?ohn\*`} {
+		require.NoError(t, idx.insert(key, row{{name: "key", value: key}}))
+	}
+	// none of the patterns is evaluated for every value
+	g := idx.(*globIndex)
+	assert.Empty(t, g.prefixes.patterns)
+	assert.Empty(t, g.suffixes.patterns)
+
+	var tests = []struct {
+		value string
+		key   string
+	}{
+		{`D:\Windows\notepad.exe`, `?:\Windows\*`},
+		{`C:\Windows\System32\cmd.exe`, `?:\Windows\System32\*`},
+		{`C:\Windows\System32\svchost.exe`, `*\System32\svchost.exe`},
+		{`C:\Windows\SysWOW64\svchost.exe`, `*\svchost.exe`},
+		{`C:\Windows\SysWOW64\cmd.exe`, `?:\Windows\*`},
+		{`C:\Temp\svchost.exe`, `*\svchost.exe`},
+		{`C:\Users\Žohn\ntuser.dat`, `C:\Users\?ohn\*`},
+		{`C:\Temp\cmd.exe`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			r, ok := idx.lookup(tt.value)
+			if tt.key == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.key, r[0].value)
+		})
+	}
+}
+
+func TestGlobIndexCaseSensitive(t *testing.T) {
+	idx := newIndex(Glob, true)
+	require.NoError(t, idx.insert(`C:\Windows\*`, row{}))
+	_, ok := idx.lookup(`C:\Windows\notepad.exe`)
+	assert.True(t, ok)
+	_, ok = idx.lookup(`c:\windows\notepad.exe`)
+	assert.False(t, ok)
+}
+
+func TestCIDRIndex(t *testing.T) {
+	idx := newIndex(CIDR, false)
+	for _, key := range []string{"0.0.0.0/0", "10.0.0.0/8", "10.20.0.0/16", "10.20.30.40", "fe80::/10", "2001:db8::/32", "::ffff:192.168.0.0/112"} {
+		require.NoError(t, idx.insert(key, row{{name: "key", value: key}}))
+	}
+
+	var tests = []struct {
+		value string
+		key   string
+	}{
+		{"10.20.30.40", "10.20.30.40"},
+		{"10.20.30.41", "10.20.0.0/16"},
+		{"10.1.1.1", "10.0.0.0/8"},
+		{"8.8.8.8", "0.0.0.0/0"},
+		{"::ffff:10.20.1.1", "10.20.0.0/16"},
+		{"192.168.4.4", "::ffff:192.168.0.0/112"},
+		{"fe80::1c2e:9a4b:7f00:1", "fe80::/10"},
+		{"2001:db8:1::1", "2001:db8::/32"},
+		{"2001:db9::1", ""},
+		{"not-an-ip", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			r, ok := idx.lookup(tt.value)
+			if tt.key == "" {
+				assert.False(t, ok)
+				return
+			}
+			require.True(t, ok)
+			assert.Equal(t, tt.key, r[0].value)
+		})
+	}
+
+	require.Error(t, idx.insert("10.0.0.0/40", row{}))
+	require.Error(t, idx.insert("10.0.0", row{}))
+}
+
+func TestExactIndex(t *testing.T) {
+	idx := newIndex(Exact, false)
+	require.NoError(t, idx.insert(`C:\Windows\System32\svchost.exe`, row{{name: "owner", value: "platform"}}))
+	// the first row wins for duplicate keys
+	require.NoError(t, idx.insert(`c:\windows\system32\svchost.exe`, row{{name: "owner", value: "secops"}}))
+
+	r, ok := idx.lookup(`C:\WINDOWS\system32\svchost.exe`)
+	require.True(t, ok)
+	assert.Equal(t, "platform", r[0].value)
+	_, ok = idx.lookup(`C:\Windows\System32\svchost`)
+	assert.False(t, ok)
+}
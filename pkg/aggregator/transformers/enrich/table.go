@@ -0,0 +1,213 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package enrich
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// defaultJSONKey is the name of the key member in JSON tables
+const defaultJSONKey = "key"
+
+// table is the loaded lookup table.
+type table struct {
+	index index
+	rows  int
+}
+
+// loadTable reads the lookup table file and builds the index of its rows.
+func loadTable(t Table) (*table, error) {
+	f, err := os.Open(t.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var (
+		header  []string
+		records []map[string]string
+		key     = t.Key
+	)
+	switch t.Format {
+	case CSV:
+		header, records, err = readCSV(f)
+		if err == nil && key == "" && len(header) > 0 {
+			key = header[0]
+		}
+	case JSON:
+		if key == "" {
+			key = defaultJSONKey
+		}
+		header, records, err = readJSON(f, key)
+	default:
+		return nil, fmt.Errorf("unknown table format %q", t.Format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %v", t.Path, err)
+	}
+
+	columns := t.Columns
+	if len(columns) == 0 {
+		columns = make([]string, 0, len(header))
+		for _, col := range header {
+			if col != key {
+				columns = append(columns, col)
+			}
+		}
+	} else if t.Format == CSV {
+		for _, col := range columns {
+			if !slices.Contains(header, col) {
+				return nil, fmt.Errorf("%s: unknown column %q", t.Path, col)
+			}
+		}
+	}
+
+	tbl := &table{index: newIndex(t.Match, t.CaseSensitive)}
+	for i, rec := range records {
+		k, ok := rec[key]
+		if !ok && t.Format == JSON {
+			return nil, fmt.Errorf("%s: row %d has no %q key", t.Path, i+1, key)
+		}
+		if k == "" {
+			continue
+		}
+		r := make(row, 0, len(columns))
+		for _, col := range columns {
+			if v := rec[col]; v != "" {
+				r = append(r, attr{name: t.Prefix + col, value: v})
+			}
+		}
+		if err := tbl.index.insert(k, r); err != nil {
+			return nil, fmt.Errorf("%s: row %d: %v", t.Path, i+1, err)
+		}
+		tbl.rows++
+	}
+	return tbl, nil
+}
+
+// readCSV reads CSV records. The first row is the header
+// with column names. The UTF-8 byte order mark produced
+// by spreadsheet exports is stripped from the header.
+func readCSV(r io.Reader) ([]string, []map[string]string, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+	records := make([]map[string]string, 0)
+	for {
+		fields, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rec := make(map[string]string, len(header))
+		for i, field := range fields {
+			if i < len(header) {
+				rec[header[i]] = field
+			}
+		}
+		records = append(records, rec)
+	}
+	return header, records, nil
+}
+
+// readJSON reads records from the array of objects. If the document
+// is an object of objects, the member names are used as table keys.
+// Columns are the sorted names of all object members.
+func readJSON(r io.Reader, key string) ([]string, []map[string]string, error) {
+	var doc any
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, nil, err
+	}
+	records := make([]map[string]string, 0)
+	switch v := doc.(type) {
+	case []any:
+		for i, o := range v {
+			obj, ok := o.(map[string]any)
+			if !ok {
+				return nil, nil, fmt.Errorf("element %d is not an object", i)
+			}
+			records = append(records, toRecord(obj))
+		}
+	case map[string]any:
+		for k, o := range v {
+			obj, ok := o.(map[string]any)
+			if !ok {
+				return nil, nil, fmt.Errorf("member %q is not an object", k)
+			}
+			rec := toRecord(obj)
+			rec[key] = k
+			records = append(records, rec)
+		}
+	default:
+		return nil, nil, errors.New("expected an array or object")
+	}
+	names := make(map[string]bool)
+	for _, rec := range records {
+		for name := range rec {
+			names[name] = true
+		}
+	}
+	header := make([]string, 0, len(names))
+	for name := range names {
+		header = append(header, name)
+	}
+	sort.Strings(header)
+	return header, records, nil
+}
+
+// toRecord converts JSON object members to strings.
+func toRecord(obj map[string]any) map[string]string {
+	rec := make(map[string]string, len(obj))
+	for k, v := range obj {
+		switch val := v.(type) {
+		case nil:
+		case string:
+			rec[k] = val
+		case float64:
+			rec[k] = strconv.FormatFloat(val, 'f', -1, 64)
+		case bool:
+			rec[k] = strconv.FormatBool(val)
+		default:
+			b, _ := json.Marshal(val)
+			rec[k] = string(b)
+		}
+	}
+	return rec
+}
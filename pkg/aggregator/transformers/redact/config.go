@@ -0,0 +1,66 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redact
+
+import "github.com/spf13/pflag"
+
+const (
+	enabled = "transformers.redact.enabled"
+)
+
+// Pattern describes the regular expression that locates secrets.
+type Pattern struct {
+	// Name identifies the pattern in metrics.
+	Name string `mapstructure:"name"`
+	// Expr is the regular expression. If the expression declares the
+	// secret capture group, only the group is masked, otherwise the
+	// entire match is replaced with the mask.
+	Expr string `mapstructure:"expr"`
+}
+
+// Pseudonymization describes the fields that are replaced with keyed hashes.
+type Pseudonymization struct {
+	// Key is the secret key of the HMAC-SHA256 function.
+	Key string `mapstructure:"key"`
+	// Fields is the list of event parameters and process fields that are pseudonymized.
+	Fields []string `mapstructure:"fields"`
+	// ProfilePaths indicates whether user names inside profile paths are pseudonymized.
+	ProfilePaths bool `mapstructure:"profile-paths"`
+}
+
+// Config stores the configuration for the redact transformer.
+type Config struct {
+	// Fields is the list of event parameters and process fields that are masked.
+	Fields []string `mapstructure:"fields"`
+	// Mask is the string that replaces the secret.
+	Mask string `mapstructure:"mask"`
+	// Patterns contains additional patterns for locating secrets.
+	Patterns []Pattern `mapstructure:"patterns"`
+	// DisableBuiltinPatterns turns off the built-in secret patterns.
+	DisableBuiltinPatterns bool `mapstructure:"disable-builtin-patterns"`
+	// Pseudonymization contains the pseudonymization settings.
+	Pseudonymization Pseudonymization `mapstructure:"pseudonymization"`
+	// Enabled indicates whether this transformer is enabled
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// AddFlags registers persistent flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Indicates if the redact transformer is enabled")
+}
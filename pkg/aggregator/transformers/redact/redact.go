@@ -0,0 +1,490 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/util/cmdline"
+)
+
+var (
+	maskedSecrets       = expvar.NewMap("transformers.redact.masked.secrets")
+	pseudonymizedValues = expvar.NewInt("transformers.redact.pseudonymized.values")
+	errMissingPseudoKey = errors.New("missing pseudonymization key")
+	// profilesDir matches the directory that contains user profiles
+	profilesDir = regexp.MustCompile(`(?i)[\\/]users[\\/]`)
+)
+
+const (
+	defaultMask = "[REDACTED]"
+	// pseudonymSize is the number of HMAC bytes retained in the pseudonym.
+	pseudonymSize = 16
+	// maxCachedProcs is the maximum number of redacted process states kept in the cache.
+	maxCachedProcs = 4096
+)
+
+// defaultFields are masked if no fields are given in the config.
+var defaultFields = []string{kparams.Cmdline, "ps.cmdline", "ps.parent.cmdline"}
+
+// profilePathFields are the process fields that may contain user profile paths.
+var profilePathFields = []string{"cmdline", "exe", "cwd"}
+
+// sharedProfiles are the profile directories that don't belong to any user.
+var sharedProfiles = map[string]bool{
+	"public":       true,
+	"default":      true,
+	"default user": true,
+	"all users":    true,
+}
+
+// field is the event parameter or process field subject to redaction.
+type field struct {
+	// param is the event parameter name
+	param string
+	// ps is the process field name
+	ps string
+	// parent indicates whether the field belongs to the parent process
+	parent bool
+}
+
+func parseField(name string) (field, error) {
+	var f field
+	switch {
+	case strings.HasPrefix(name, "ps.parent."):
+		f.ps, f.parent = strings.TrimPrefix(name, "ps.parent."), true
+	case strings.HasPrefix(name, "ps."):
+		f.ps = strings.TrimPrefix(name, "ps.")
+	default:
+		f.param = name
+		return f, nil
+	}
+	if psField(&pstypes.PS{}, f.ps) == nil {
+		return f, fmt.Errorf("unsupported %q process field", name)
+	}
+	return f, nil
+}
+
+// psFields are the names of all process fields that can be redacted.
+var psFields = [...]string{"cmdline", "exe", "cwd", "name", "sid", "username", "domain"}
+
+// psField returns the pointer to the process field value.
+func psField(ps *pstypes.PS, name string) *string {
+	switch name {
+	case "cmdline":
+		return &ps.Cmdline
+	case "exe":
+		return &ps.Exe
+	case "cwd":
+		return &ps.Cwd
+	case "name":
+		return &ps.Name
+	case "sid":
+		return &ps.SID
+	case "username":
+		return &ps.Username
+	case "domain":
+		return &ps.Domain
+	default:
+		return nil
+	}
+}
+
+// pattern is the compiled secret pattern.
+type pattern struct {
+	name string
+	re   *regexp.Regexp
+	// group is the index of the secret capture group or zero if the whole match is masked
+	group int
+}
+
+// redact masks secrets and replaces user identities with keyed hashes. Process
+// state is copied before alteration as it is shared with the snapshotter. The
+// redacted copies are cached, so events of the same process share the copy.
+type redact struct {
+	masked       []field
+	pseudonyms   []field
+	patterns     []pattern
+	mask         string
+	key          []byte
+	profilePaths bool
+
+	mu    sync.Mutex
+	procs map[*pstypes.PS]redactedProc
+}
+
+// procState captures the process attributes that determine the redacted copy.
+type procState struct {
+	fields       [len(psFields)]string
+	threads      int
+	parent       *pstypes.PS
+	parentFields [len(psFields)]string
+}
+
+// redactedProc is the redacted process state along with the state of the
+// original process at the time the copy was made.
+type redactedProc struct {
+	state procState
+	ps    *pstypes.PS
+}
+
+func stateOf(ps *pstypes.PS) procState {
+	var s procState
+	for i, name := range psFields {
+		s.fields[i] = *psField(ps, name)
+	}
+	ps.RLock()
+	s.threads = len(ps.Threads)
+	ps.RUnlock()
+	if ps.Parent != nil {
+		s.parent = ps.Parent
+		for i, name := range psFields {
+			s.parentFields[i] = *psField(ps.Parent, name)
+		}
+	}
+	return s
+}
+
+func init() {
+	transformers.Register(transformers.Redact, initRedactTransformer)
+}
+
+func initRedactTransformer(config transformers.Config) (transformers.Transformer, error) {
+	cfg, ok := config.Transformer.(Config)
+	if !ok {
+		return nil, transformers.ErrInvalidConfig(transformers.Redact)
+	}
+	r := &redact{
+		mask:         cfg.Mask,
+		key:          []byte(cfg.Pseudonymization.Key),
+		profilePaths: cfg.Pseudonymization.ProfilePaths,
+		procs:        make(map[*pstypes.PS]redactedProc),
+	}
+	if r.mask == "" {
+		r.mask = defaultMask
+	}
+
+	fields := cfg.Fields
+	if len(fields) == 0 {
+		fields = defaultFields
+	}
+	for _, name := range fields {
+		f, err := parseField(name)
+		if err != nil {
+			return nil, err
+		}
+		r.masked = append(r.masked, f)
+	}
+	for _, name := range cfg.Pseudonymization.Fields {
+		f, err := parseField(name)
+		if err != nil {
+			return nil, err
+		}
+		r.pseudonyms = append(r.pseudonyms, f)
+	}
+	if (len(r.pseudonyms) > 0 || r.profilePaths) && len(r.key) == 0 {
+		return nil, errMissingPseudoKey
+	}
+
+	patterns := make([]Pattern, 0, len(builtinPatterns)+len(cfg.Patterns))
+	if !cfg.DisableBuiltinPatterns {
+		patterns = append(patterns, builtinPatterns...)
+	}
+	patterns = append(patterns, cfg.Patterns...)
+	for _, p := range patterns {
+		re, err := regexp.Compile(p.Expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %q pattern: %v", p.Expr, err)
+		}
+		name := p.Name
+		if name == "" {
+			name = p.Expr
+		}
+		group := re.SubexpIndex(secretGroup)
+		if group < 0 {
+			group = 0
+		}
+		r.patterns = append(r.patterns, pattern{name: name, re: re, group: group})
+	}
+
+	return r, nil
+}
+
+func (r *redact) Transform(kevt *kevent.Kevent) error {
+	for _, f := range r.masked {
+		if f.param == "" {
+			continue
+		}
+		kpar, _ := kevt.Kparams.Get(f.param)
+		if kpar == nil || !isString(kpar.Type) {
+			continue
+		}
+		if s := kpar.String(); s != "" {
+			if v := r.maskSecrets(s); v != s {
+				setParam(kpar, v)
+			}
+		}
+	}
+
+	for _, f := range r.pseudonyms {
+		if f.param == "" {
+			continue
+		}
+		kpar, _ := kevt.Kparams.Get(f.param)
+		if kpar == nil {
+			continue
+		}
+		if s := kpar.String(); s != "" {
+			setParam(kpar, r.pseudonym(s))
+		}
+	}
+
+	if r.profilePaths {
+		for _, kpar := range kevt.Kparams {
+			if !isString(kpar.Type) {
+				continue
+			}
+			if s := kpar.String(); s != "" {
+				if v := r.pseudonymizeProfiles(s); v != s {
+					setParam(kpar, v)
+				}
+			}
+		}
+	}
+
+	if kevt.PS != nil {
+		kevt.PS = r.redactProcess(kevt.PS)
+	}
+
+	return nil
+}
+
+// redactProcess returns the redacted copy of the process state. The copy is
+// reused as long as the redacted attributes of the original process and its
+// parent, as well as the number of threads, remain the same. The original
+// process is returned if none of its fields require redaction.
+func (r *redact) redactProcess(ps *pstypes.PS) *pstypes.PS {
+	state := stateOf(ps)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if proc, ok := r.procs[ps]; ok && proc.state == state {
+		return proc.ps
+	}
+
+	kevt := &kevent.Kevent{PS: ps}
+	proc := &process{kevt: kevt}
+
+	for _, f := range r.masked {
+		if f.param != "" {
+			continue
+		}
+		if s := proc.get(f); s != "" {
+			if v := r.maskSecrets(s); v != s {
+				proc.set(f, v)
+			}
+		}
+	}
+
+	for _, f := range r.pseudonyms {
+		if f.param != "" {
+			continue
+		}
+		if s := proc.get(f); s != "" {
+			proc.set(f, r.pseudonym(s))
+		}
+	}
+
+	if r.profilePaths {
+		for _, parent := range []bool{false, true} {
+			for _, name := range profilePathFields {
+				f := field{ps: name, parent: parent}
+				if s := proc.get(f); s != "" {
+					if v := r.pseudonymizeProfiles(s); v != s {
+						proc.set(f, v)
+					}
+				}
+			}
+		}
+	}
+
+	// the cache is bounded and holds references to the original
+	// process states, so start afresh once the limit is reached
+	if len(r.procs) >= maxCachedProcs {
+		clear(r.procs)
+	}
+	r.procs[ps] = redactedProc{state: state, ps: kevt.PS}
+
+	return kevt.PS
+}
+
+// maskSecrets replaces all secrets in the string with the mask.
+func (r *redact) maskSecrets(s string) string {
+	for _, p := range r.patterns {
+		matches := p.re.FindAllStringSubmatchIndex(s, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		var (
+			b    strings.Builder
+			last int
+			n    int64
+		)
+		for _, m := range matches {
+			start, end := m[2*p.group], m[2*p.group+1]
+			if start < 0 || start == end {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(r.mask)
+			last = end
+			n++
+		}
+		if n == 0 {
+			continue
+		}
+		b.WriteString(s[last:])
+		s = b.String()
+		maskedSecrets.Add(p.name, n)
+	}
+	return s
+}
+
+// pseudonym derives the hex-encoded HMAC-SHA256 digest from the case-folded
+// value. The same value always maps to the same pseudonym for the given key.
+func (r *redact) pseudonym(s string) string {
+	mac := hmac.New(sha256.New, r.key)
+	mac.Write([]byte(strings.ToLower(s)))
+	pseudonymizedValues.Add(1)
+	return hex.EncodeToString(mac.Sum(nil)[:pseudonymSize])
+}
+
+// pseudonymizeProfiles replaces user names in profile paths such as
+// C:\Users\<username>\AppData with pseudonyms. The name ends at the next
+// path separator. When no separator follows, as in C:\Users\<username> /c
+// dir, the name also ends at the first space.
+func (r *redact) pseudonymizeProfiles(s string) string {
+	locs := profilesDir.FindAllStringIndex(s, -1)
+	if len(locs) == 0 {
+		return s
+	}
+	var (
+		b    strings.Builder
+		last int
+	)
+	for _, loc := range locs {
+		start := loc[1]
+		if start < last {
+			continue
+		}
+		end := len(s)
+		if i := strings.IndexAny(s[start:], "\\/\"'<>|:*?\r\n"); i >= 0 {
+			end = start + i
+		}
+		if end == len(s) || (s[end] != '\\' && s[end] != '/') {
+			if i := strings.IndexAny(s[start:end], " \t"); i >= 0 {
+				end = start + i
+			}
+		}
+		name := s[start:end]
+		if name == "" || sharedProfiles[strings.ToLower(name)] {
+			continue
+		}
+		b.WriteString(s[last:start])
+		b.WriteString(r.pseudonym(name))
+		last = end
+	}
+	if last == 0 {
+		return s
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// process provides access to the fields of the event process and its
+// parent. The process state is copied on the first alteration.
+type process struct {
+	kevt         *kevent.Kevent
+	copied       bool
+	parentCopied bool
+}
+
+func (p *process) ps(parent bool) *pstypes.PS {
+	ps := p.kevt.PS
+	if ps != nil && parent {
+		return ps.Parent
+	}
+	return ps
+}
+
+func (p *process) get(f field) string {
+	ps := p.ps(f.parent)
+	if ps == nil {
+		return ""
+	}
+	return *psField(ps, f.ps)
+}
+
+func (p *process) set(f field, value string) {
+	if !p.copied {
+		p.kevt.PS = p.kevt.PS.Copy()
+		p.copied = true
+	}
+	if f.parent && !p.parentCopied {
+		p.kevt.PS.Parent = p.kevt.PS.Parent.Copy()
+		p.parentCopied = true
+	}
+	ps := p.ps(f.parent)
+	*psField(ps, f.ps) = value
+	if f.ps == "cmdline" {
+		ps.Args = cmdline.Split(value)
+	}
+}
+
+func isString(typ kparams.Type) bool {
+	switch typ {
+	case kparams.UnicodeString, kparams.AnsiString, kparams.Path, kparams.DOSPath:
+		return true
+	default:
+		return false
+	}
+}
+
+// setParam replaces the parameter value. Parameters of other
+// types, such as SIDs, are converted to strings.
+func setParam(kpar *kevent.Kparam, value string) {
+	switch kpar.Type {
+	case kparams.UnicodeString, kparams.AnsiString, kparams.Path:
+	default:
+		kpar.Type = kparams.UnicodeString
+	}
+	kpar.Value = value
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sample
+
+import "github.com/spf13/pflag"
+
+const (
+	enabled = "transformers.sample.enabled"
+)
+
+// Rule describes the fraction of events that are kept.
+type Rule struct {
+	// Name identifies the rule in metrics. The filter expression is used if the name is empty.
+	Name string `mapstructure:"name"`
+	// Filter is the optional filter expression that matches sampled events. If
+	// empty, the rule applies to all events.
+	Filter string `mapstructure:"filter"`
+	// Rate determines the fraction of kept events. One in rate events is kept.
+	Rate uint64 `mapstructure:"rate"`
+	// Keys is the list of event fields whose values determine the sampling decision.
+	Keys []string `mapstructure:"keys"`
+}
+
+// Config stores the configuration for the sample transformer.
+type Config struct {
+	// Rules is the list of sampling rules. The first rule matching the event applies.
+	Rules []Rule `mapstructure:"rules"`
+	// Enabled indicates whether this transformer is enabled
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// AddFlags registers persistent flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Indicates if the sample transformer is enabled")
+}
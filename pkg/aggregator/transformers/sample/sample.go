@@ -0,0 +1,176 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sample
+
+import (
+	"container/list"
+	"expvar"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+// maxSampleKeys is the maximum number of key counters tracked per rule
+const maxSampleKeys = 10000
+
+// sampledOutEvents counts the number of events discarded by sampling per rule
+var sampledOutEvents = expvar.NewMap("transformers.sample.dropped.events")
+
+type rule struct {
+	name     string
+	filter   transformers.Predicate
+	rate     uint64
+	keys     []string
+	counters *counters
+}
+
+// sample transformer keeps one in rate events matching the rule. When keys are
+// given, the events are counted for every distinct combination of key values,
+// and the first of every rate events is kept for each combination. Otherwise,
+// all events matching the rule share a single counter.
+type sample struct {
+	rules []rule
+}
+
+// counters keeps the number of events seen for each key. The least
+// recently used keys are evicted once the capacity is reached, so the
+// counter of the evicted key starts afresh when the key reappears.
+type counters struct {
+	mu       sync.Mutex
+	capacity int
+	keys     map[string]*list.Element
+	lru      *list.List
+}
+
+type counter struct {
+	key string
+	n   uint64
+}
+
+func newCounters(capacity int) *counters {
+	return &counters{
+		capacity: capacity,
+		keys:     make(map[string]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// next increments the counter of the key and
+// returns the number of events seen before.
+func (c *counters) next(key string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.keys[key]; ok {
+		c.lru.MoveToFront(elem)
+		cnt := elem.Value.(*counter)
+		n := cnt.n
+		cnt.n++
+		return n
+	}
+	if c.lru.Len() >= c.capacity {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.keys, oldest.Value.(*counter).key)
+	}
+	c.keys[key] = c.lru.PushFront(&counter{key: key, n: 1})
+	return 0
+}
+
+func init() {
+	transformers.Register(transformers.Sample, initSampleTransformer)
+}
+
+func initSampleTransformer(config transformers.Config) (transformers.Transformer, error) {
+	cfg, ok := config.Transformer.(Config)
+	if !ok {
+		return nil, transformers.ErrInvalidConfig(transformers.Sample)
+	}
+
+	rules := make([]rule, 0, len(cfg.Rules))
+	for i, r := range cfg.Rules {
+		name := r.Name
+		if name == "" {
+			name = r.Filter
+		}
+		if name == "" {
+			name = fmt.Sprintf("rule-%d", i+1)
+		}
+		if r.Rate == 0 {
+			return nil, fmt.Errorf("%q sample rule requires a positive rate", name)
+		}
+		rl := rule{name: name, rate: r.Rate, keys: r.Keys, counters: newCounters(maxSampleKeys)}
+		if r.Filter != "" {
+			if config.Compiler == nil {
+				return nil, fmt.Errorf("%q sample rule declares the filter but no filter compiler is available", name)
+			}
+			var err error
+			rl.filter, err = config.Compiler(r.Filter)
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter in %q sample rule: %v", name, err)
+			}
+		}
+		rules = append(rules, rl)
+	}
+
+	return &sample{rules: rules}, nil
+}
+
+func (s sample) Transform(kevt *kevent.Kevent) error {
+	for _, r := range s.rules {
+		if r.filter != nil && !r.filter.Run(kevt) {
+			continue
+		}
+		if r.keep(kevt) {
+			return nil
+		}
+		sampledOutEvents.Add(r.name, 1)
+		return transformers.ErrDropped
+	}
+	return nil
+}
+
+// keep decides whether the event is retained.
+func (r rule) keep(kevt *kevent.Kevent) bool {
+	if r.rate == 1 {
+		return true
+	}
+	return r.counters.next(r.key(kevt))%r.rate == 0
+}
+
+// key joins the values of the key fields.
+func (r rule) key(kevt *kevent.Kevent) string {
+	if len(r.keys) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, key := range r.keys {
+		if i > 0 {
+			// separate values so different key
+			// combinations don't collide
+			b.WriteByte(0)
+		}
+		v, _ := outputs.FieldValue(kevt, key)
+		b.WriteString(v)
+	}
+	return b.String()
+}
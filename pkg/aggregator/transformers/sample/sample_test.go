@@ -0,0 +1,149 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sample
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+type predicate func(*kevent.Kevent) bool
+
+func (p predicate) Run(e *kevent.Kevent) bool { return p(e) }
+
+func compiler(expr string) (transformers.Predicate, error) {
+	if expr != "kevt.name = 'ReadFile'" {
+		return nil, errors.New("unknown field")
+	}
+	return predicate(func(e *kevent.Kevent) bool { return e.Type == ktypes.ReadFile }), nil
+}
+
+func TestTransformSequence(t *testing.T) {
+	transf, err := transformers.Load(transformers.Config{
+		Type:        transformers.Sample,
+		Transformer: Config{Rules: []Rule{{Name: "reads", Filter: "kevt.name = 'ReadFile'", Rate: 4}}},
+		Compiler:    compiler,
+	})
+	require.NoError(t, err)
+
+	// sequence numbers with a period that is a multiple
+	// of the rate don't bias the sampling decision
+	var kept int
+	for seq := uint64(0); seq < 100; seq++ {
+		err := transf.Transform(&kevent.Kevent{Type: ktypes.ReadFile, Seq: seq * 4})
+		if err == nil {
+			kept++
+			continue
+		}
+		require.ErrorIs(t, err, transformers.ErrDropped)
+	}
+	assert.Equal(t, 25, kept)
+	assert.Equal(t, "75", sampledOutEvents.Get("reads").String())
+
+	// events not matching the rule are always kept
+	for seq := uint64(1); seq < 4; seq++ {
+		require.NoError(t, transf.Transform(&kevent.Kevent{Type: ktypes.CreateFile, Seq: seq}))
+	}
+}
+
+func TestTransformKeys(t *testing.T) {
+	transf, err := transformers.Load(transformers.Config{
+		Type:        transformers.Sample,
+		Transformer: Config{Rules: []Rule{{Name: "reads-per-exe", Filter: "kevt.name = 'ReadFile'", Rate: 10, Keys: []string{"ps.exe"}}}},
+		Compiler:    compiler,
+	})
+	require.NoError(t, err)
+
+	// every key retains exactly one in rate events
+	// regardless of how events of keys are interleaved
+	seen, kept := make(map[string]int), make(map[string]int)
+	for seq := uint64(0); seq < 10000; seq++ {
+		exe := fmt.Sprintf(`C:\Program Files\app%d\app.exe`, seq%3)
+		if seq%7 == 0 {
+			exe = `C:\Windows\notepad.exe`
+		}
+		seen[exe]++
+		err := transf.Transform(&kevent.Kevent{Type: ktypes.ReadFile, Seq: seq, PS: &pstypes.PS{Exe: exe}})
+		if err == nil {
+			kept[exe]++
+			continue
+		}
+		require.ErrorIs(t, err, transformers.ErrDropped)
+	}
+	require.Len(t, kept, 4)
+	for exe, n := range seen {
+		assert.Equal(t, (n+9)/10, kept[exe], exe)
+	}
+
+	// the first event of a rare key is kept
+	require.NoError(t, transf.Transform(&kevent.Kevent{Type: ktypes.ReadFile, Seq: 42, PS: &pstypes.PS{Exe: `C:\Temp\rare.exe`}}))
+}
+
+func TestCounters(t *testing.T) {
+	c := newCounters(2)
+	assert.Equal(t, uint64(0), c.next("a"))
+	assert.Equal(t, uint64(1), c.next("a"))
+	assert.Equal(t, uint64(0), c.next("b"))
+	assert.Equal(t, uint64(2), c.next("a"))
+	// the least recently used key is evicted
+	assert.Equal(t, uint64(0), c.next("c"))
+	assert.Len(t, c.keys, 2)
+	assert.Equal(t, uint64(0), c.next("b"))
+	assert.Equal(t, uint64(1), c.next("c"))
+}
+
+func TestTransformRateOne(t *testing.T) {
+	transf, err := transformers.Load(transformers.Config{
+		Type:        transformers.Sample,
+		Transformer: Config{Rules: []Rule{{Rate: 1, Keys: []string{"ps.exe"}}}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, transf.Transform(&kevent.Kevent{Type: ktypes.ReadFile, Seq: 3}))
+}
+
+func TestInvalidConfig(t *testing.T) {
+	_, err := transformers.Load(transformers.Config{
+		Type:        transformers.Sample,
+		Transformer: Config{Rules: []Rule{{Name: "reads", Filter: "kevt.name = 'ReadFile'"}}},
+		Compiler:    compiler,
+	})
+	require.EqualError(t, err, `"reads" sample rule requires a positive rate`)
+
+	_, err = transformers.Load(transformers.Config{
+		Type:        transformers.Sample,
+		Transformer: Config{Rules: []Rule{{Name: "reads", Filter: "kevt.nam = 'ReadFile'", Rate: 10}}},
+		Compiler:    compiler,
+	})
+	require.EqualError(t, err, `invalid filter in "reads" sample rule: unknown field`)
+
+	_, err = transformers.Load(transformers.Config{
+		Type:        transformers.Sample,
+		Transformer: Config{Rules: []Rule{{Name: "reads", Filter: "kevt.name = 'ReadFile'", Rate: 10}}},
+	})
+	require.Error(t, err)
+}
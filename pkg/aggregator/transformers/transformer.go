@@ -19,12 +19,17 @@
 package transformers
 
 import (
+	"errors"
 	"fmt"
 	"github.com/rabbitstack/fibratus/pkg/kevent"
 )
 
 var transformers = map[Type]Factory{}
 
+// ErrDropped is returned by transformers that discard the event. The dropped
+// event is not forwarded to outputs and the remaining transformers are skipped.
+var ErrDropped = errors.New("event dropped")
+
 // Factory defines the function for transformer factories
 type Factory func(config Config) (Transformer, error)
 
@@ -32,8 +37,16 @@ type Factory func(config Config) (Transformer, error)
 type Type uint8
 
 const (
+	// Drop represents the drop transformer type. It discards events matching the filter expressions.
+	Drop Type = iota
+	// Sample represents the sample transformer type. It keeps a deterministic fraction of matching events.
+	Sample
+	// Enrich represents the enrich transformer type. It appends columns of lookup tables to the event.
+	Enrich
+	// Redact represents the redact transformer type. It masks secrets and pseudonymizes user identities.
+	Redact
 	// Remove represents the remove transformer type. This transformer deletes the given list of parameters from the event.
-	Remove Type = iota
+	Remove
 	// Rename represents the rename transformer type. It renames a sequence of kparam from old to new names.
 	Rename
 	// Replace represents the replace tranformer type. It applies string replacements on specific kparams.
@@ -47,6 +60,14 @@ const (
 // String returns the type human-readable name.
 func (typ Type) String() string {
 	switch typ {
+	case Drop:
+		return "drop"
+	case Sample:
+		return "sample"
+	case Enrich:
+		return "enrich"
+	case Redact:
+		return "redact"
 	case Remove:
 		return "remove"
 	case Rename:
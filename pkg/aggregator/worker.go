@@ -34,15 +34,17 @@ type worker struct {
 	qu      queue
 	client  outputs.Client
 	backoff time.Duration
+	done    chan struct{}
 }
 
 func initWorker(q queue, client outputs.Client) *worker {
-	w := &worker{qu: q, client: client, backoff: time.Second * 2}
+	w := &worker{qu: q, client: client, backoff: time.Second * 2, done: make(chan struct{})}
 	go w.run()
 	return w
 }
 
 func (w *worker) run() {
+	defer close(w.done)
 	for {
 		err := w.client.Connect()
 		if err != nil {
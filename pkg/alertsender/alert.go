@@ -59,18 +59,26 @@ func (s Severity) String() string {
 }
 
 // ParseSeverityFromString parses the severity from the string representation.
+// Unknown severities are parsed as the normal severity.
 func ParseSeverityFromString(sever string) Severity {
+	s, _ := ParseSeverity(sever)
+	return s
+}
+
+// ParseSeverity parses the severity from the string representation.
+// It returns an error if the severity is not recognized.
+func ParseSeverity(sever string) (Severity, error) {
 	switch sever {
 	case "normal", "Normal", "NORMAL", "low", "LOW":
-		return Normal
+		return Normal, nil
 	case "medium", "Medium", "MEDIUM":
-		return Medium
+		return Medium, nil
 	case "high", "High", "HIGH":
-		return High
+		return High, nil
 	case "critical", "Critical", "CRITICAL":
-		return Critical
+		return Critical, nil
 	default:
-		return Normal
+		return Normal, fmt.Errorf("unknown severity %q", sever)
 	}
 }
 
@@ -96,6 +104,12 @@ type Alert struct {
 	Severity Severity
 	// Events contains a list of events that trigger the alert.
 	Events []*kevent.Kevent
+	// Suppressed designates the number of alerts with the same
+	// deduplication key that were suppressed before this alert.
+	Suppressed int
+	// Capture is the path of the capture file containing the
+	// events recorded before the alert was triggered.
+	Capture string
 }
 
 // String returns the alert string representation. If verbose
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pagerduty
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	enabled      = "alertsenders.pagerduty.enabled"
+	routingKey   = "alertsenders.pagerduty.routing-key"
+	url          = "alertsenders.pagerduty.url"
+	dedupFields  = "alertsenders.pagerduty.dedup-fields"
+	resolveAfter = "alertsenders.pagerduty.resolve-after"
+	timeout      = "alertsenders.pagerduty.timeout"
+)
+
+// Config contains the configuration for the PagerDuty alert sender.
+type Config struct {
+	// Enabled indicates whether the PagerDuty alert sender is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string `mapstructure:"routing-key"`
+	// URL is the Events API v2 endpoint.
+	URL string `mapstructure:"url"`
+	// DedupFields contains the event fields that are combined with the
+	// rule identifier to build the deduplication key.
+	DedupFields []string `mapstructure:"dedup-fields"`
+	// Severities overrides the mapping of alert severities to PagerDuty severities.
+	Severities map[string]string `mapstructure:"severities"`
+	// ResolveAfter is the period after which the incident is resolved
+	// if no alerts with the same deduplication key are triggered.
+	ResolveAfter time.Duration `mapstructure:"resolve-after"`
+	// Timeout is the timeout for the Events API requests.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// AddFlags registers persistent flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Indicates whether the PagerDuty alert sender is enabled")
+	flags.String(routingKey, "", "Represents the integration key of the PagerDuty service")
+	flags.String(url, defaultURL, "Represents the PagerDuty Events API v2 endpoint")
+	flags.StringSlice(dedupFields, []string{}, "Specifies event fields that are combined with the rule identifier to build the deduplication key")
+	flags.Duration(resolveAfter, 0, "Specifies the period after which the incident is resolved if no alerts with the same deduplication key are triggered")
+	flags.Duration(timeout, time.Second*10, "Represents the timeout for the Events API requests")
+}
@@ -0,0 +1,354 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pagerduty
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/util/hostname"
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultURL is the PagerDuty Events API v2 endpoint
+const defaultURL = "https://events.pagerduty.com/v2/enqueue"
+
+const (
+	// maxSummaryLength is the maximum length of the incident summary
+	maxSummaryLength = 1024
+	// maxDedupKeyLength is the maximum length of the deduplication key
+	maxDedupKeyLength = 255
+)
+
+var errMissingRoutingKey = errors.New("missing PagerDuty routing key")
+
+// severities maps alert severities to PagerDuty severities.
+var severities = map[alertsender.Severity]string{
+	alertsender.Normal:   "info",
+	alertsender.Medium:   "warning",
+	alertsender.High:     "error",
+	alertsender.Critical: "critical",
+}
+
+// event is the PagerDuty Events API v2 event.
+type event struct {
+	RoutingKey  string   `json:"routing_key"`
+	EventAction string   `json:"event_action"`
+	DedupKey    string   `json:"dedup_key"`
+	Payload     *payload `json:"payload,omitempty"`
+	Client      string   `json:"client,omitempty"`
+	Links       []link   `json:"links,omitempty"`
+}
+
+type payload struct {
+	Summary       string         `json:"summary"`
+	Source        string         `json:"source"`
+	Severity      string         `json:"severity"`
+	Timestamp     string         `json:"timestamp,omitempty"`
+	Component     string         `json:"component,omitempty"`
+	Group         string         `json:"group,omitempty"`
+	Class         string         `json:"class,omitempty"`
+	CustomDetails map[string]any `json:"custom_details,omitempty"`
+}
+
+type link struct {
+	Href string `json:"href"`
+	Text string `json:"text,omitempty"`
+}
+
+// pagerduty triggers PagerDuty incidents through the Events API v2. Alerts
+// with the same deduplication key are grouped into the same incident. If
+// auto-resolution is enabled, incidents are resolved once no alerts with
+// the same deduplication key are triggered for the configured period.
+type pagerduty struct {
+	config     Config
+	client     *http.Client
+	severities map[alertsender.Severity]string
+
+	mu        sync.Mutex
+	triggered map[string]time.Time
+	quit      chan struct{}
+	wg        sync.WaitGroup
+}
+
+func init() {
+	alertsender.Register(alertsender.PagerDuty, makeSender)
+}
+
+// makeSender constructs a new instance of the PagerDuty alert sender.
+func makeSender(config alertsender.Config) (alertsender.Sender, error) {
+	c, ok := config.Sender.(Config)
+	if !ok {
+		return nil, alertsender.ErrInvalidConfig(alertsender.PagerDuty)
+	}
+	if c.RoutingKey == "" {
+		return nil, errMissingRoutingKey
+	}
+	if c.URL == "" {
+		c.URL = defaultURL
+	}
+	if c.Timeout == 0 {
+		c.Timeout = time.Second * 10
+	}
+
+	p := &pagerduty{
+		config:     c,
+		severities: make(map[alertsender.Severity]string),
+		triggered:  make(map[string]time.Time),
+		quit:       make(chan struct{}),
+		client: &http.Client{
+			Timeout: c.Timeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   10 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+			},
+		},
+	}
+	for sever, pdsever := range severities {
+		p.severities[sever] = pdsever
+	}
+	for sever, pdsever := range c.Severities {
+		switch strings.ToLower(sever) {
+		case "low", "normal", "medium", "high", "critical":
+		default:
+			return nil, fmt.Errorf("unknown %q alert severity", sever)
+		}
+		switch pdsever {
+		case "info", "warning", "error", "critical":
+		default:
+			return nil, fmt.Errorf("invalid %q PagerDuty severity for %q alert severity", pdsever, sever)
+		}
+		p.severities[alertsender.ParseSeverityFromString(strings.ToLower(sever))] = pdsever
+	}
+
+	if c.ResolveAfter > 0 {
+		p.wg.Add(1)
+		go p.resolveLoop()
+	}
+
+	return p, nil
+}
+
+func (p *pagerduty) Send(alert alertsender.Alert) error {
+	key := p.dedupKey(alert)
+	evt := event{
+		RoutingKey:  p.config.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    key,
+		Client:      "Fibratus",
+		Payload: &payload{
+			Summary:   truncate(alert.Title, maxSummaryLength),
+			Source:    hostname.Get(),
+			Severity:  p.severities[alert.Severity],
+			Timestamp: time.Now().Format(time.RFC3339Nano),
+			Group:     alert.Labels["tactic.name"],
+			Class:     alert.Labels["technique.name"],
+			CustomDetails: map[string]any{
+				"text":     alert.Text,
+				"severity": alert.Severity.String(),
+			},
+		},
+	}
+	if len(alert.Events) > 0 {
+		e := alert.Events[0]
+		if e.Host != "" {
+			evt.Payload.Source = e.Host
+		}
+		if e.PS != nil {
+			evt.Payload.Component = e.PS.Name
+		}
+		evt.Payload.CustomDetails["events"] = summarize(alert.Events)
+	}
+	if alert.Description != "" {
+		evt.Payload.CustomDetails["description"] = alert.Description
+	}
+	if len(alert.Labels) > 0 {
+		evt.Payload.CustomDetails["labels"] = alert.Labels
+	}
+	if len(alert.Tags) > 0 {
+		evt.Payload.CustomDetails["tags"] = alert.Tags
+	}
+	for _, prefix := range []string{"tactic", "technique", "subtechnique"} {
+		if ref := alert.Labels[prefix+".ref"]; ref != "" {
+			evt.Links = append(evt.Links, link{Href: ref, Text: alert.Labels[prefix+".name"]})
+		}
+	}
+
+	if err := p.post(evt); err != nil {
+		return err
+	}
+	if p.config.ResolveAfter > 0 {
+		p.mu.Lock()
+		p.triggered[key] = time.Now()
+		p.mu.Unlock()
+	}
+	return nil
+}
+
+func (p *pagerduty) Type() alertsender.Type { return alertsender.PagerDuty }
+func (p *pagerduty) SupportsMarkdown() bool { return false }
+
+func (p *pagerduty) Shutdown() error {
+	close(p.quit)
+	p.wg.Wait()
+	return nil
+}
+
+// dedupKey builds the deduplication key from the rule identifier and
+// the values of the configured event fields. Each field value is taken
+// from the first alert event where the field resolves. Keys exceeding
+// the maximum length are replaced with the digest of the field values.
+func (p *pagerduty) dedupKey(alert alertsender.Alert) string {
+	key := alert.ID
+	if key == "" {
+		key = alert.Title
+	}
+	if len(p.config.DedupFields) == 0 {
+		return truncate(key, maxDedupKeyLength)
+	}
+	values := make([]string, len(p.config.DedupFields))
+	for i, field := range p.config.DedupFields {
+		for _, e := range alert.Events {
+			if v, ok := outputs.FieldValue(e, field); ok {
+				values[i] = v
+				break
+			}
+		}
+	}
+	dedupKey := key + ":" + strings.Join(values, ":")
+	if len(dedupKey) <= maxDedupKeyLength {
+		return dedupKey
+	}
+	sum := sha256.Sum256([]byte(strings.Join(values, "\x00")))
+	return truncate(key, maxDedupKeyLength-len(sum)*2-1) + ":" + hex.EncodeToString(sum[:])
+}
+
+// resolveLoop periodically resolves incidents whose
+// alerts haven't been triggered for the resolve period.
+func (p *pagerduty) resolveLoop() {
+	defer p.wg.Done()
+	interval := p.config.ResolveAfter / 4
+	if interval < time.Millisecond*10 {
+		interval = time.Millisecond * 10
+	}
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			for _, key := range p.expired() {
+				err := p.post(event{RoutingKey: p.config.RoutingKey, EventAction: "resolve", DedupKey: key})
+				if err != nil {
+					log.Warnf("unable to resolve %q PagerDuty incident: %v", key, err)
+				}
+			}
+		case <-p.quit:
+			return
+		}
+	}
+}
+
+// expired returns and forgets the deduplication keys of stale incidents.
+func (p *pagerduty) expired() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	keys := make([]string, 0)
+	for key, ts := range p.triggered {
+		if time.Since(ts) >= p.config.ResolveAfter {
+			keys = append(keys, key)
+			delete(p.triggered, key)
+		}
+	}
+	return keys
+}
+
+func (p *pagerduty) post(evt event) error {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(evt); err != nil {
+		return err
+	}
+	//nolint:noctx
+	resp, err := p.client.Post(p.config.URL, "application/json", &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusAccepted || resp.StatusCode == http.StatusOK {
+		return nil
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	type response struct {
+		Message string   `json:"message"`
+		Errors  []string `json:"errors"`
+	}
+	var r response
+	if err := json.Unmarshal(b, &r); err == nil && r.Message != "" {
+		return fmt.Errorf("failed to send alert to PagerDuty. code: %d message: %s %s", resp.StatusCode, r.Message, strings.Join(r.Errors, ", "))
+	}
+	return fmt.Errorf("failed to send alert to PagerDuty. code: %d content: %s", resp.StatusCode, string(b))
+}
+
+// summarize produces the compact representation of alert events.
+func summarize(evts []*kevent.Kevent) []map[string]any {
+	summaries := make([]map[string]any, 0, len(evts))
+	for _, e := range evts {
+		params := make(map[string]string, len(e.Kparams))
+		for _, kpar := range e.Kparams {
+			params[kpar.Name] = kpar.String()
+		}
+		s := map[string]any{
+			"name":      e.Name,
+			"timestamp": e.Timestamp.Format(time.RFC3339Nano),
+			"pid":       e.PID,
+			"params":    params,
+		}
+		if e.PS != nil {
+			s["process"] = e.PS.Name
+			s["exe"] = e.PS.Exe
+			s["cmdline"] = e.PS.Cmdline
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return strings.ToValidUTF8(s[:n], "")
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pagerduty
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+// events records events received by the test server.
+type events struct {
+	sync.Mutex
+	evts []event
+}
+
+func (e *events) handler(w http.ResponseWriter, r *http.Request) {
+	var evt event
+	if err := json.NewDecoder(r.Body).Decode(&evt); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	e.Lock()
+	e.evts = append(e.evts, evt)
+	e.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (e *events) get() []event {
+	e.Lock()
+	defer e.Unlock()
+	return append([]event{}, e.evts...)
+}
+
+func testAlert(sever alertsender.Severity, exe string) alertsender.Alert {
+	alert := alertsender.NewAlertWithEvents(
+		"LSASS memory dumping",
+		"Detected the rundll32 process dumping the LSASS memory",
+		[]string{"credential-access"},
+		sever,
+		[]*kevent.Kevent{
+			{
+				Type:      ktypes.OpenProcess,
+				Name:      "OpenProcess",
+				PID:       1023,
+				Host:      "archrabbit",
+				Timestamp: time.Now(),
+				Kparams: kevent.Kparams{
+					kparams.Exe: {Name: kparams.Exe, Type: kparams.UnicodeString, Value: `C:\Windows\System32\lsass.exe`},
+				},
+				PS: &pstypes.PS{PID: 1023, Name: "rundll32.exe", Exe: exe},
+			},
+		},
+	)
+	alert.ID = "4ac2c4b1-c3a4-4d8b-bdb7-ecd6fd7f9ba1"
+	alert.Labels = map[string]string{
+		"tactic.id":      "TA0006",
+		"tactic.name":    "Credential Access",
+		"tactic.ref":     "https://attack.mitre.org/tactics/TA0006/",
+		"technique.id":   "T1003",
+		"technique.name": "OS Credential Dumping",
+		"technique.ref":  "https://attack.mitre.org/techniques/T1003/",
+	}
+	return alert
+}
+
+func TestSend(t *testing.T) {
+	var e events
+	srv := httptest.NewServer(http.HandlerFunc(e.handler))
+	defer srv.Close()
+
+	s, err := makeSender(alertsender.Config{
+		Type: alertsender.PagerDuty,
+		Sender: Config{
+			RoutingKey:  "R0UT1NGK3Y",
+			URL:         srv.URL,
+			DedupFields: []string{"ps.exe", "kevt.arg[exe]"},
+			Severities:  map[string]string{"high": "critical"},
+		},
+	})
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, s.Send(testAlert(alertsender.High, `C:\Windows\System32\rundll32.exe`)))
+	require.NoError(t, s.Send(testAlert(alertsender.Medium, `C:\Windows\System32\rundll32.exe`)))
+
+	evts := e.get()
+	require.Len(t, evts, 2)
+	evt := evts[0]
+	assert.Equal(t, "R0UT1NGK3Y", evt.RoutingKey)
+	assert.Equal(t, "trigger", evt.EventAction)
+	assert.Equal(t, `4ac2c4b1-c3a4-4d8b-bdb7-ecd6fd7f9ba1:C:\Windows\System32\rundll32.exe:C:\Windows\System32\lsass.exe`, evt.DedupKey)
+	require.NotNil(t, evt.Payload)
+	assert.Equal(t, "LSASS memory dumping", evt.Payload.Summary)
+	assert.Equal(t, "archrabbit", evt.Payload.Source)
+	assert.Equal(t, "critical", evt.Payload.Severity)
+	assert.Equal(t, "rundll32.exe", evt.Payload.Component)
+	assert.Equal(t, "Credential Access", evt.Payload.Group)
+	assert.Equal(t, "OS Credential Dumping", evt.Payload.Class)
+	assert.Equal(t, "high", evt.Payload.CustomDetails["severity"])
+	assert.Len(t, evt.Payload.CustomDetails["events"], 1)
+	assert.Equal(t, []link{
+		{Href: "https://attack.mitre.org/tactics/TA0006/", Text: "Credential Access"},
+		{Href: "https://attack.mitre.org/techniques/T1003/", Text: "OS Credential Dumping"},
+	}, evt.Links)
+
+	assert.Equal(t, "warning", evts[1].Payload.Severity)
+	assert.Equal(t, evt.DedupKey, evts[1].DedupKey)
+}
+
+func TestDedupKey(t *testing.T) {
+	p := &pagerduty{}
+	alert := testAlert(alertsender.Critical, `C:\Windows\System32\rundll32.exe`)
+	assert.Equal(t, "4ac2c4b1-c3a4-4d8b-bdb7-ecd6fd7f9ba1", p.dedupKey(alert))
+
+	p.config.DedupFields = []string{"ps.exe", "ps.sid"}
+	assert.Equal(t, `4ac2c4b1-c3a4-4d8b-bdb7-ecd6fd7f9ba1:C:\Windows\System32\rundll32.exe:`, p.dedupKey(alert))
+
+	alert = testAlert(alertsender.Critical, `C:\`+strings.Repeat("a", 300)+`.exe`)
+	key := p.dedupKey(alert)
+	assert.Len(t, key, 36+1+64)
+	assert.True(t, strings.HasPrefix(key, "4ac2c4b1-c3a4-4d8b-bdb7-ecd6fd7f9ba1:"))
+	assert.Equal(t, key, p.dedupKey(alert))
+}
+
+func TestResolve(t *testing.T) {
+	var e events
+	srv := httptest.NewServer(http.HandlerFunc(e.handler))
+	defer srv.Close()
+
+	s, err := makeSender(alertsender.Config{
+		Type:   alertsender.PagerDuty,
+		Sender: Config{RoutingKey: "R0UT1NGK3Y", URL: srv.URL, ResolveAfter: time.Millisecond * 100},
+	})
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, s.Send(testAlert(alertsender.Critical, `C:\Windows\System32\rundll32.exe`)))
+	require.Eventually(t, func() bool { return len(e.get()) == 2 }, time.Second*5, time.Millisecond*10)
+
+	evt := e.get()[1]
+	assert.Equal(t, "resolve", evt.EventAction)
+	assert.Equal(t, "4ac2c4b1-c3a4-4d8b-bdb7-ecd6fd7f9ba1", evt.DedupKey)
+	assert.Nil(t, evt.Payload)
+
+	// the incident is resolved only once
+	time.Sleep(time.Millisecond * 250)
+	assert.Len(t, e.get(), 2)
+}
+
+func TestSendError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"status":"invalid event","message":"Event object is invalid","errors":["Length of 'routing_key' is incorrect (should be 32 characters)"]}`))
+	}))
+	defer srv.Close()
+
+	s, err := makeSender(alertsender.Config{Type: alertsender.PagerDuty, Sender: Config{RoutingKey: "R0UT1NGK3Y", URL: srv.URL}})
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	err = s.Send(testAlert(alertsender.Critical, `C:\Windows\System32\rundll32.exe`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Event object is invalid")
+}
+
+func TestInvalidConfig(t *testing.T) {
+	var tests = []struct {
+		name   string
+		config Config
+	}{
+		{"missing routing key", Config{}},
+		{"unknown severity", Config{RoutingKey: "R0UT1NGK3Y", Severities: map[string]string{"severe": "critical"}}},
+		{"unknown PagerDuty severity", Config{RoutingKey: "R0UT1NGK3Y", Severities: map[string]string{"high": "fatal"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := makeSender(alertsender.Config{Type: alertsender.PagerDuty, Sender: tt.config})
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,267 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alertsender
+
+import (
+	"expvar"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rabbitstack/fibratus/pkg/util/wildcard"
+	log "github.com/sirupsen/logrus"
+)
+
+// unroutedAlerts counts alerts dropped because no route matched them
+var unroutedAlerts = expvar.NewInt("alertsender.unrouted.alerts")
+
+// Route dispatches alerts satisfying all match conditions to a subset
+// of alert senders. Conditions that are not specified match any alert.
+type Route struct {
+	// Name identifies the route.
+	Name string `mapstructure:"name"`
+	// Severity is the minimum severity of the alert.
+	Severity string `mapstructure:"severity"`
+	// Labels contains label conditions in the `key = value`
+	// or `key != value` form. All conditions must be satisfied.
+	Labels []string `mapstructure:"labels"`
+	// Tags matches alerts that contain any of the tags.
+	Tags []string `mapstructure:"tags"`
+	// Rules contains wildcard patterns matched against the
+	// rule name. For alerts not emitted by rules, patterns
+	// are matched against the alert title.
+	Rules []string `mapstructure:"rules"`
+	// Senders contains the names of alert senders that receive matching alerts.
+	Senders []string `mapstructure:"senders"`
+	// Continue indicates whether the routes following the matching route are evaluated.
+	Continue bool `mapstructure:"continue"`
+}
+
+// RouteConfig contains the alert routes.
+type RouteConfig struct {
+	// Routes is the list of routes evaluated in order.
+	Routes []Route
+	// Default is the route for alerts not matched by any route.
+	Default *Route
+}
+
+// labelCondition is the compiled label condition of the route.
+type labelCondition struct {
+	key    string
+	value  string
+	negate bool
+}
+
+func (c labelCondition) matches(labels map[string]string) bool {
+	v, ok := labels[c.key]
+	if c.negate {
+		return !ok || !strings.EqualFold(v, c.value)
+	}
+	return ok && strings.EqualFold(v, c.value)
+}
+
+// route is the compiled alert route.
+type route struct {
+	name     string
+	severity *Severity
+	labels   []labelCondition
+	tags     []string
+	rules    []string
+	senders  []Type
+	cont     bool
+}
+
+var routes []route
+var defaultRoute *route
+
+// loadRoutes compiles alert routes. Routes can only dispatch alerts
+// to loaded senders, so they are loaded after alert senders. Routes
+// without any enabled alert sender are skipped, so they never swallow
+// alerts.
+func loadRoutes(config RouteConfig) error {
+	routes = make([]route, 0, len(config.Routes))
+	defaultRoute = nil
+	for i, r := range config.Routes {
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("#%d", i+1)
+		}
+		rt, err := compileRoute(r)
+		if err != nil {
+			return fmt.Errorf("invalid %q alert route: %v", r.Name, err)
+		}
+		if len(rt.senders) == 0 {
+			log.Warnf("%q alert route has no enabled alert senders and is skipped", r.Name)
+			continue
+		}
+		routes = append(routes, rt)
+	}
+	if config.Default != nil {
+		r := *config.Default
+		if r.Severity != "" || len(r.Labels) > 0 || len(r.Tags) > 0 || len(r.Rules) > 0 {
+			return fmt.Errorf("invalid default alert route: only alert senders can be specified")
+		}
+		r.Name = "default"
+		rt, err := compileRoute(r)
+		if err != nil {
+			return fmt.Errorf("invalid default alert route: %v", err)
+		}
+		if len(rt.senders) == 0 {
+			log.Warn("default alert route has no enabled alert senders and is skipped")
+			return nil
+		}
+		defaultRoute = &rt
+	}
+	return nil
+}
+
+func compileRoute(r Route) (route, error) {
+	rt := route{
+		name:  r.Name,
+		tags:  make([]string, 0, len(r.Tags)),
+		rules: make([]string, 0, len(r.Rules)),
+		cont:  r.Continue,
+	}
+	if r.Severity != "" {
+		switch strings.ToLower(r.Severity) {
+		case "low", "normal", "medium", "high", "critical":
+		default:
+			return rt, fmt.Errorf("unknown %q severity", r.Severity)
+		}
+		sever := ParseSeverityFromString(strings.ToLower(r.Severity))
+		rt.severity = &sever
+	}
+	for _, label := range r.Labels {
+		var c labelCondition
+		key, value, ok := strings.Cut(label, "!=")
+		if ok {
+			c.negate = true
+		} else {
+			key, value, ok = strings.Cut(label, "=")
+		}
+		c.key, c.value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || c.key == "" {
+			return rt, fmt.Errorf("label condition %q is not in the key = value form", label)
+		}
+		rt.labels = append(rt.labels, c)
+	}
+	for _, tag := range r.Tags {
+		rt.tags = append(rt.tags, strings.ToLower(tag))
+	}
+	for _, rule := range r.Rules {
+		rt.rules = append(rt.rules, strings.ToLower(rule))
+	}
+	if len(r.Senders) == 0 {
+		return rt, fmt.Errorf("no alert senders")
+	}
+	for _, name := range r.Senders {
+		typ := ToType(strings.ToLower(name))
+		if typ == None {
+			return rt, fmt.Errorf("unknown %q alert sender", name)
+		}
+		if _, ok := alertsenders[typ]; !ok {
+			log.Warnf("%q alert sender is not enabled. Alerts won't be sent through %q alert route", name, r.Name)
+			continue
+		}
+		rt.senders = append(rt.senders, typ)
+	}
+	return rt, nil
+}
+
+// matches determines if the alert satisfies all route conditions.
+func (r route) matches(alert Alert) bool {
+	if r.severity != nil && alert.Severity < *r.severity {
+		return false
+	}
+	for _, c := range r.labels {
+		if !c.matches(alert.Labels) {
+			return false
+		}
+	}
+	if len(r.tags) > 0 && !r.matchesTags(alert.Tags) {
+		return false
+	}
+	if len(r.rules) > 0 && !r.matchesRules(strings.ToLower(alert.Title)) {
+		return false
+	}
+	return true
+}
+
+func (r route) matchesTags(tags []string) bool {
+	for _, tag := range tags {
+		if slices.Contains(r.tags, strings.ToLower(tag)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r route) matchesRules(name string) bool {
+	for _, pattern := range r.rules {
+		if wildcard.Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// FindRouted returns senders the alert is dispatched to. Routes are
+// evaluated in order until the first matching route that doesn't
+// continue the evaluation. Senders of all matching routes receive
+// the alert. If no route matches, the alert is dispatched through
+// the default route. Without the default route, the unmatched alert
+// is dropped. If no routes are configured, all registered senders
+// receive the alert.
+func FindRouted(alert Alert) []Sender {
+	if len(routes) == 0 && defaultRoute == nil {
+		return FindAll()
+	}
+
+	var matched bool
+	types := make([]Type, 0)
+	for _, r := range routes {
+		if !r.matches(alert) {
+			continue
+		}
+		matched = true
+		for _, typ := range r.senders {
+			if !slices.Contains(types, typ) {
+				types = append(types, typ)
+			}
+		}
+		if !r.cont {
+			break
+		}
+	}
+	if !matched {
+		if defaultRoute == nil {
+			log.Debugf("alert [%s] doesn't match any route and there is no default route. Dropping alert", alert.Title)
+			unroutedAlerts.Add(1)
+			return nil
+		}
+		types = defaultRoute.senders
+	}
+
+	senders := make([]Sender, 0, len(types))
+	for _, typ := range types {
+		if s, ok := alertsenders[typ]; ok {
+			senders = append(senders, s)
+		}
+	}
+	return senders
+}
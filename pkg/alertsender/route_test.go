@@ -0,0 +1,189 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package alertsender
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSender struct{ typ Type }
+
+func (s fakeSender) Send(Alert) error       { return nil }
+func (s fakeSender) Type() Type             { return s.typ }
+func (s fakeSender) Shutdown() error        { return nil }
+func (s fakeSender) SupportsMarkdown() bool { return true }
+
+func senderTypes(senders []Sender) []Type {
+	types := make([]Type, 0, len(senders))
+	for _, s := range senders {
+		types = append(types, s.Type())
+	}
+	return types
+}
+
+func TestFindRouted(t *testing.T) {
+	for _, typ := range []Type{Mail, Slack, PagerDuty, Teams} {
+		alertsenders[typ] = fakeSender{typ: typ}
+	}
+	defer func() {
+		for _, typ := range []Type{Mail, Slack, PagerDuty, Teams} {
+			delete(alertsenders, typ)
+		}
+		require.NoError(t, loadRoutes(RouteConfig{}))
+	}()
+
+	credAccess := Alert{
+		Title:    "LSASS memory dumping via legitimate or offensive tools",
+		Severity: Critical,
+		Labels:   map[string]string{"tactic.id": "TA0006"},
+		Tags:     []string{"credential-access"},
+	}
+	discovery := Alert{
+		Title:    "Suspicious domain discovery",
+		Severity: Normal,
+		Labels:   map[string]string{"tactic.id": "TA0007"},
+	}
+	exfil := Alert{
+		Title:    "Exfiltration over web service",
+		Severity: High,
+		Labels:   map[string]string{"tactic.id": "TA0010"},
+		Tags:     []string{"Exfiltration"},
+	}
+
+	require.NoError(t, loadRoutes(RouteConfig{}))
+	assert.Len(t, FindRouted(credAccess), 4)
+
+	var tests = []struct {
+		name   string
+		config RouteConfig
+		alert  Alert
+		want   []Type
+	}{
+		{
+			"severity and labels with continue",
+			RouteConfig{
+				Routes: []Route{
+					{Severity: "high", Labels: []string{"tactic.id = TA0006"}, Senders: []string{"pagerduty"}, Continue: true},
+					{Severity: "high", Senders: []string{"teams", "pagerduty"}},
+					{Senders: []string{"slack"}},
+				},
+				Default: &Route{Senders: []string{"mail"}},
+			},
+			credAccess,
+			[]Type{PagerDuty, Teams},
+		},
+		{
+			"first matching route stops evaluation",
+			RouteConfig{
+				Routes: []Route{
+					{Severity: "high", Senders: []string{"teams"}},
+					{Labels: []string{"tactic.id = TA0006"}, Senders: []string{"pagerduty"}},
+				},
+			},
+			credAccess,
+			[]Type{Teams},
+		},
+		{
+			"default route",
+			RouteConfig{
+				Routes:  []Route{{Severity: "high", Senders: []string{"pagerduty"}}},
+				Default: &Route{Senders: []string{"mail"}},
+			},
+			discovery,
+			[]Type{Mail},
+		},
+		{
+			"negated label condition",
+			RouteConfig{
+				Routes:  []Route{{Labels: []string{"tactic.id != TA0006"}, Senders: []string{"slack"}}},
+				Default: &Route{Senders: []string{"mail"}},
+			},
+			discovery,
+			[]Type{Slack},
+		},
+		{
+			"tags",
+			RouteConfig{
+				Routes:  []Route{{Tags: []string{"exfiltration", "command-and-control"}, Senders: []string{"teams"}}},
+				Default: &Route{Senders: []string{"mail"}},
+			},
+			exfil,
+			[]Type{Teams},
+		},
+		{
+			"rule name patterns",
+			RouteConfig{
+				Routes:  []Route{{Rules: []string{"*lsass*"}, Senders: []string{"pagerduty"}}},
+				Default: &Route{Senders: []string{"mail"}},
+			},
+			credAccess,
+			[]Type{PagerDuty},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, loadRoutes(tt.config))
+			assert.Equal(t, tt.want, senderTypes(FindRouted(tt.alert)))
+		})
+	}
+
+	// unmatched alerts are dropped without the default route
+	require.NoError(t, loadRoutes(RouteConfig{Routes: []Route{{Severity: "critical", Senders: []string{"pagerduty"}}}}))
+	unrouted := unroutedAlerts.Value()
+	assert.Empty(t, FindRouted(exfil))
+	assert.Equal(t, unrouted+1, unroutedAlerts.Value())
+	assert.Equal(t, []Type{PagerDuty}, senderTypes(FindRouted(credAccess)))
+
+	// routes without enabled senders are skipped and don't swallow alerts
+	require.NoError(t, loadRoutes(RouteConfig{Routes: []Route{
+		{Severity: "critical", Senders: []string{"eventlog"}},
+		{Labels: []string{"tactic.id = TA0006"}, Senders: []string{"slack"}},
+	}}))
+	assert.Equal(t, []Type{Slack}, senderTypes(FindRouted(credAccess)))
+	require.NoError(t, loadRoutes(RouteConfig{
+		Routes:  []Route{{Severity: "critical", Senders: []string{"eventlog"}}},
+		Default: &Route{Senders: []string{"systray"}},
+	}))
+	assert.Len(t, FindRouted(credAccess), 4)
+}
+
+func TestLoadRoutesInvalid(t *testing.T) {
+	var tests = []struct {
+		config RouteConfig
+		err    string
+	}{
+		{RouteConfig{Routes: []Route{{Name: "r", Severity: "severe", Senders: []string{"mail"}}}}, `invalid "r" alert route: unknown "severe" severity`},
+		{RouteConfig{Routes: []Route{{Labels: []string{"tactic.id"}, Senders: []string{"mail"}}}}, `invalid "#1" alert route: label condition "tactic.id" is not in the key = value form`},
+		{RouteConfig{Routes: []Route{{Senders: []string{"pager"}}}}, `invalid "#1" alert route: unknown "pager" alert sender`},
+		{RouteConfig{Default: &Route{}}, "invalid default alert route: no alert senders"},
+		{RouteConfig{Default: &Route{Severity: "high", Senders: []string{"mail"}}}, "invalid default alert route: only alert senders can be specified"},
+		{RouteConfig{Default: &Route{Labels: []string{"tactic.id = TA0006"}, Senders: []string{"mail"}}}, "invalid default alert route: only alert senders can be specified"},
+		{RouteConfig{Default: &Route{Tags: []string{"exfiltration"}, Senders: []string{"mail"}}}, "invalid default alert route: only alert senders can be specified"},
+		{RouteConfig{Default: &Route{Rules: []string{"*lsass*"}, Senders: []string{"mail"}}}, "invalid default alert route: only alert senders can be specified"},
+	}
+
+	for _, tt := range tests {
+		assert.EqualError(t, loadRoutes(tt.config), tt.err)
+	}
+	require.NoError(t, loadRoutes(RouteConfig{}))
+}
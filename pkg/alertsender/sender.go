@@ -46,6 +46,12 @@ const (
 	Systray
 	// Eventlog designate the eventlog alert sender
 	Eventlog
+	// Webhook designates the webhook alert sender
+	Webhook
+	// PagerDuty designates the PagerDuty Events API v2 alert sender
+	PagerDuty
+	// Teams designates the Microsoft Teams alert sender
+	Teams
 	// None is the type for unknown alert sender
 	None
 )
@@ -63,6 +69,12 @@ func (s Type) String() string {
 		return "systray"
 	case Eventlog:
 		return "eventlog"
+	case Webhook:
+		return "webhook"
+	case PagerDuty:
+		return "pagerduty"
+	case Teams:
+		return "teams"
 	default:
 		return "none"
 	}
@@ -93,6 +105,14 @@ func ToType(s string) Type {
 		return Noop
 	case "systray":
 		return Systray
+	case "eventlog":
+		return Eventlog
+	case "webhook":
+		return Webhook
+	case "pagerduty":
+		return PagerDuty
+	case "teams":
+		return Teams
 	default:
 		return None
 	}
@@ -142,8 +162,9 @@ func Load(config Config) (Sender, error) {
 	return factory(config)
 }
 
-// LoadAll loads all alert senders from the configuration inputs.
-func LoadAll(configs []Config) error {
+// LoadAll loads all alert senders from the configuration inputs
+// and compiles the alert routes that dispatch alerts to them.
+func LoadAll(configs []Config, routes RouteConfig) error {
 	for _, config := range configs {
 		alertsender, err := Load(config)
 		if err != nil {
@@ -151,5 +172,5 @@ func LoadAll(configs []Config) error {
 		}
 		alertsenders[config.Type] = alertsender
 	}
-	return nil
+	return loadRoutes(routes)
 }
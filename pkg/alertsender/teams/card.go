@@ -0,0 +1,214 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package teams
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/util/hostname"
+)
+
+const (
+	cardContentType = "application/vnd.microsoft.card.adaptive"
+	cardSchema      = "http://adaptivecards.io/schemas/adaptive-card.json"
+	cardVersion     = "1.4"
+	// eventsID identifies the collapsible event summary container
+	eventsID = "events"
+)
+
+// element is the Adaptive Card element.
+type element map[string]any
+
+// message is the Teams message that carries the Adaptive Card.
+type message struct {
+	Type        string       `json:"type"`
+	Attachments []attachment `json:"attachments"`
+}
+
+type attachment struct {
+	ContentType string  `json:"contentType"`
+	ContentURL  *string `json:"contentUrl"`
+	Content     element `json:"content"`
+}
+
+// mitre contains the label prefixes of MITRE ATT&CK tactics and techniques.
+var mitre = []struct {
+	prefix string
+	title  string
+}{
+	{"tactic", "Tactic"},
+	{"technique", "Technique"},
+	{"subtechnique", "Subtechnique"},
+}
+
+// newMessage builds the message with the Adaptive Card that renders the
+// alert title, the Markdown text, MITRE facts, and the event summary
+// that is collapsed until toggled.
+func newMessage(alert alertsender.Alert, maxEvents int) message {
+	host := hostname.Get()
+	if len(alert.Events) > 0 && alert.Events[0].Host != "" {
+		host = alert.Events[0].Host
+	}
+
+	body := []element{
+		{
+			"type":  "Container",
+			"style": style(alert.Severity),
+			"bleed": true,
+			"items": []element{
+				textBlock(alert.Title, element{"size": "Large", "weight": "Bolder"}),
+				textBlock(fmt.Sprintf("%s severity · %s", strings.ToUpper(alert.Severity.String()), host), element{"isSubtle": true, "spacing": "None"}),
+			},
+		},
+	}
+	if alert.Text != "" {
+		body = append(body, textBlock(alert.Text, nil))
+	}
+	if alert.Description != "" {
+		body = append(body, textBlock(alert.Description, element{"isSubtle": true}))
+	}
+
+	facts := mitreFacts(alert.Labels)
+	if len(alert.Tags) > 0 {
+		facts = append(facts, fact("Tags", strings.Join(alert.Tags, ", ")))
+	}
+	if len(facts) > 0 {
+		body = append(body, element{"type": "FactSet", "facts": facts})
+	}
+
+	if len(alert.Events) > 0 {
+		body = append(body,
+			element{
+				"type": "ActionSet",
+				"actions": []element{
+					{
+						"type":           "Action.ToggleVisibility",
+						"title":          fmt.Sprintf("Show events (%d)", len(alert.Events)),
+						"targetElements": []string{eventsID},
+					},
+				},
+			},
+			element{
+				"type":      "Container",
+				"id":        eventsID,
+				"isVisible": false,
+				"items":     eventSummary(alert.Events, maxEvents),
+			},
+		)
+	}
+
+	card := element{
+		"$schema": cardSchema,
+		"type":    "AdaptiveCard",
+		"version": cardVersion,
+		"body":    body,
+		"msteams": element{"width": "Full"},
+	}
+	return message{
+		Type:        "message",
+		Attachments: []attachment{{ContentType: cardContentType, Content: card}},
+	}
+}
+
+// mitreFacts renders MITRE ATT&CK labels as facts linking to the technique pages.
+func mitreFacts(labels map[string]string) []element {
+	facts := make([]element, 0)
+	for _, m := range mitre {
+		name, id, ref := labels[m.prefix+".name"], labels[m.prefix+".id"], labels[m.prefix+".ref"]
+		value := name
+		switch {
+		case name == "" && id == "":
+			continue
+		case name == "":
+			value = id
+		case id != "":
+			value = fmt.Sprintf("%s (%s)", name, id)
+		}
+		if ref != "" {
+			value = fmt.Sprintf("[%s](%s)", value, ref)
+		}
+		facts = append(facts, fact(m.title, value))
+	}
+	return facts
+}
+
+// eventSummary renders the process and parameters of each event.
+func eventSummary(evts []*kevent.Kevent, maxEvents int) []element {
+	items := make([]element, 0)
+	for i, e := range evts {
+		if maxEvents > 0 && i == maxEvents {
+			items = append(items, textBlock(fmt.Sprintf("%d more event(s) not shown", len(evts)-maxEvents), element{"isSubtle": true}))
+			break
+		}
+		items = append(items, textBlock(fmt.Sprintf("**#%d %s** · %s", i+1, e.Name, e.Timestamp.Format(time.RFC3339)), element{"separator": i > 0}))
+
+		facts := make([]element, 0)
+		if ps := e.PS; ps != nil {
+			facts = append(facts,
+				fact("Process", fmt.Sprintf("%s (%d)", ps.Name, ps.PID)),
+				fact("Executable", ps.Exe),
+				fact("Command line", ps.Cmdline),
+			)
+			if ps.Parent != nil {
+				facts = append(facts, fact("Parent", fmt.Sprintf("%s (%d)", ps.Parent.Name, ps.Parent.PID)))
+			}
+		} else {
+			facts = append(facts, fact("Process ID", fmt.Sprintf("%d", e.PID)))
+		}
+		names := make([]string, 0, len(e.Kparams))
+		for name := range e.Kparams {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			facts = append(facts, fact(name, e.Kparams[name].String()))
+		}
+		items = append(items, element{"type": "FactSet", "facts": facts})
+	}
+	return items
+}
+
+func textBlock(text string, props element) element {
+	block := element{"type": "TextBlock", "text": text, "wrap": true}
+	for k, v := range props {
+		block[k] = v
+	}
+	return block
+}
+
+func fact(title, value string) element {
+	return element{"title": title, "value": value}
+}
+
+// style returns the container style that reflects the alert severity.
+func style(sever alertsender.Severity) string {
+	switch sever {
+	case alertsender.Critical, alertsender.High:
+		return "attention"
+	case alertsender.Medium:
+		return "warning"
+	default:
+		return "accent"
+	}
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package teams
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	enabled   = "alertsenders.teams.enabled"
+	url       = "alertsenders.teams.url"
+	maxEvents = "alertsenders.teams.max-events"
+	timeout   = "alertsenders.teams.timeout"
+)
+
+// Config stores the settings that dictate the behaviour of the Microsoft Teams alert sender.
+type Config struct {
+	// Enabled determines if the Teams alert sender is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// URL represents the incoming webhook or the workflow URL of the channel where alerts are posted.
+	URL string `mapstructure:"url"`
+	// MaxEvents is the maximum number of events rendered in the event summary.
+	MaxEvents int `mapstructure:"max-events"`
+	// Timeout is the timeout for the webhook requests.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// AddFlags registers persistent flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Determines whether the Teams alert sender is enabled")
+	flags.String(url, "", "Represents the incoming webhook or the workflow URL of the channel where alerts are posted")
+	flags.Int(maxEvents, 5, "Specifies the maximum number of events rendered in the event summary")
+	flags.Duration(timeout, time.Second*10, "Represents the timeout for the webhook requests")
+}
@@ -0,0 +1,92 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package teams
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+)
+
+var errMissingURL = errors.New("missing Teams webhook URL")
+
+type teams struct {
+	client *http.Client
+	config Config
+}
+
+func init() {
+	alertsender.Register(alertsender.Teams, makeSender)
+}
+
+// makeSender constructs a new instance of the Microsoft Teams alert sender.
+func makeSender(config alertsender.Config) (alertsender.Sender, error) {
+	c, ok := config.Sender.(Config)
+	if !ok {
+		return nil, alertsender.ErrInvalidConfig(alertsender.Teams)
+	}
+	if c.URL == "" {
+		return nil, errMissingURL
+	}
+	if c.Timeout == 0 {
+		c.Timeout = time.Second * 10
+	}
+	client := &http.Client{
+		Timeout: c.Timeout,
+		Transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   10 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}
+	return &teams{config: c, client: client}, nil
+}
+
+func (s teams) Send(alert alertsender.Alert) error {
+	var body bytes.Buffer
+	if err := json.NewEncoder(&body).Encode(newMessage(alert, s.config.MaxEvents)); err != nil {
+		return err
+	}
+	//nolint:noctx
+	resp, err := s.client.Post(s.config.URL, "application/json", &body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("failed to send alert to Teams. code: %d content: %s", resp.StatusCode, string(b))
+	}
+	return nil
+}
+
+func (s teams) Type() alertsender.Type { return alertsender.Teams }
+func (s teams) Shutdown() error        { return nil }
+func (s teams) SupportsMarkdown() bool { return true }
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package teams
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+func testAlert(n int) alertsender.Alert {
+	evts := make([]*kevent.Kevent, n)
+	for i := range evts {
+		evts[i] = &kevent.Kevent{
+			Type:      ktypes.OpenProcess,
+			Name:      "OpenProcess",
+			PID:       1023,
+			Host:      "archrabbit",
+			Timestamp: time.Date(2024, 5, 16, 12, 4, 10, 0, time.UTC),
+			Kparams: kevent.Kparams{
+				kparams.Exe:       {Name: kparams.Exe, Type: kparams.UnicodeString, Value: `C:\Windows\System32\lsass.exe`},
+				kparams.ProcessID: {Name: kparams.ProcessID, Type: kparams.PID, Value: uint32(652)},
+			},
+			PS: &pstypes.PS{
+				PID:     1023,
+				Name:    "rundll32.exe",
+				Exe:     `C:\Windows\System32\rundll32.exe`,
+				Cmdline: `rundll32.exe C:\Windows\System32\comsvcs.dll, MiniDump 652 lsass.dmp full`,
+				Parent:  &pstypes.PS{PID: 4532, Name: "cmd.exe"},
+			},
+		}
+	}
+	alert := alertsender.NewAlertWithEvents(
+		"LSASS memory dumping",
+		"Detected **rundll32.exe** dumping the LSASS memory",
+		[]string{"credential-access"},
+		alertsender.Critical,
+		evts,
+	)
+	alert.Labels = map[string]string{
+		"tactic.id":      "TA0006",
+		"tactic.name":    "Credential Access",
+		"tactic.ref":     "https://attack.mitre.org/tactics/TA0006/",
+		"technique.id":   "T1003",
+		"technique.name": "OS Credential Dumping",
+	}
+	return alert
+}
+
+func TestSend(t *testing.T) {
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s, err := makeSender(alertsender.Config{Type: alertsender.Teams, Sender: Config{URL: srv.URL}})
+	require.NoError(t, err)
+	require.True(t, s.SupportsMarkdown())
+	require.NoError(t, s.Send(testAlert(1)))
+
+	var msg struct {
+		Type        string `json:"type"`
+		Attachments []struct {
+			ContentType string `json:"contentType"`
+			Content     struct {
+				Type    string           `json:"type"`
+				Version string           `json:"version"`
+				Body    []map[string]any `json:"body"`
+			} `json:"content"`
+		} `json:"attachments"`
+	}
+	require.NoError(t, json.Unmarshal(body, &msg))
+	assert.Equal(t, "message", msg.Type)
+	require.Len(t, msg.Attachments, 1)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", msg.Attachments[0].ContentType)
+	card := msg.Attachments[0].Content
+	assert.Equal(t, "AdaptiveCard", card.Type)
+
+	require.Len(t, card.Body, 5)
+	header := card.Body[0]
+	assert.Equal(t, "attention", header["style"])
+	assert.Equal(t, "LSASS memory dumping", header["items"].([]any)[0].(map[string]any)["text"])
+	assert.Equal(t, "CRITICAL severity · archrabbit", header["items"].([]any)[1].(map[string]any)["text"])
+	assert.Equal(t, "Detected **rundll32.exe** dumping the LSASS memory", card.Body[1]["text"])
+	assert.Equal(t, []any{
+		map[string]any{"title": "Tactic", "value": "[Credential Access (TA0006)](https://attack.mitre.org/tactics/TA0006/)"},
+		map[string]any{"title": "Technique", "value": "OS Credential Dumping (T1003)"},
+		map[string]any{"title": "Tags", "value": "credential-access"},
+	}, card.Body[2]["facts"])
+
+	toggle := card.Body[3]["actions"].([]any)[0].(map[string]any)
+	assert.Equal(t, "Action.ToggleVisibility", toggle["type"])
+	assert.Equal(t, []any{"events"}, toggle["targetElements"])
+	events := card.Body[4]
+	assert.Equal(t, "events", events["id"])
+	assert.Equal(t, false, events["isVisible"])
+	items := events["items"].([]any)
+	require.Len(t, items, 2)
+	assert.Equal(t, "**#1 OpenProcess** · 2024-05-16T12:04:10Z", items[0].(map[string]any)["text"])
+	facts := items[1].(map[string]any)["facts"].([]any)
+	assert.Contains(t, facts, map[string]any{"title": "Process", "value": "rundll32.exe (1023)"})
+	assert.Contains(t, facts, map[string]any{"title": "Parent", "value": "cmd.exe (4532)"})
+	assert.Contains(t, facts, map[string]any{"title": "exe", "value": `C:\Windows\System32\lsass.exe`})
+}
+
+func TestEventSummaryMaxEvents(t *testing.T) {
+	items := eventSummary(testAlert(4).Events, 2)
+	require.Len(t, items, 5)
+	assert.Equal(t, "2 more event(s) not shown", items[4]["text"])
+}
+
+func TestSendError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("Webhook message delivery failed"))
+	}))
+	defer srv.Close()
+
+	s, err := makeSender(alertsender.Config{Type: alertsender.Teams, Sender: Config{URL: srv.URL}})
+	require.NoError(t, err)
+	require.Error(t, s.Send(testAlert(1)))
+
+	_, err = makeSender(alertsender.Config{Type: alertsender.Teams, Sender: Config{}})
+	require.Error(t, err)
+}
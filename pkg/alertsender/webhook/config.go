@@ -0,0 +1,108 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	enabled               = "alertsenders.webhook.enabled"
+	contentType           = "alertsenders.webhook.content-type"
+	secret                = "alertsenders.webhook.secret"
+	signatureHeader       = "alertsenders.webhook.signature-header"
+	timeout               = "alertsenders.webhook.timeout"
+	maxRetries            = "alertsenders.webhook.max-retries"
+	retryBackoff          = "alertsenders.webhook.retry-backoff"
+	maxRetryBackoff       = "alertsenders.webhook.max-retry-backoff"
+	queueSize             = "alertsenders.webhook.queue-size"
+	tlsCA                 = "alertsenders.webhook.tls-ca"
+	tlsCert               = "alertsenders.webhook.tls-cert"
+	tlsKey                = "alertsenders.webhook.tls-key"
+	tlsInsecureSkipVerify = "alertsenders.webhook.tls-insecure-skip-verify"
+)
+
+// Endpoint describes the URL where alerts are delivered.
+type Endpoint struct {
+	// URL is the endpoint address.
+	URL string `mapstructure:"url"`
+	// Method is the HTTP verb of the request. Defaults to POST.
+	Method string `mapstructure:"method"`
+	// Headers contains additional request headers. They take precedence over global headers.
+	Headers map[string]string `mapstructure:"headers"`
+	// Template overrides the global body template for this endpoint.
+	Template string `mapstructure:"template"`
+	// Secret overrides the global signing secret for this endpoint.
+	Secret string `mapstructure:"secret"`
+}
+
+// Config contains the configuration for the webhook alert sender.
+type Config struct {
+	// Enabled indicates whether the webhook alert sender is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoints is the list of endpoints that receive every alert.
+	Endpoints []Endpoint `mapstructure:"endpoints"`
+	// Template is the Go template that renders the request body.
+	Template string `mapstructure:"template"`
+	// ContentType is the request body content type.
+	ContentType string `mapstructure:"content-type"`
+	// Headers contains additional headers sent to all endpoints.
+	Headers map[string]string `mapstructure:"headers"`
+	// Secret is the key for the HMAC-SHA256 request signature. Requests are not signed if empty.
+	Secret string `mapstructure:"secret"`
+	// SignatureHeader is the name of the header that carries the request signature.
+	SignatureHeader string `mapstructure:"signature-header"`
+	// Timeout is the timeout of a single delivery attempt.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxRetries is the maximum number of retries after the failed delivery attempt.
+	MaxRetries int `mapstructure:"max-retries"`
+	// RetryBackoff is the initial interval between delivery attempts.
+	RetryBackoff time.Duration `mapstructure:"retry-backoff"`
+	// MaxRetryBackoff is the maximum interval between delivery attempts.
+	MaxRetryBackoff time.Duration `mapstructure:"max-retry-backoff"`
+	// QueueSize is the maximum number of alerts waiting for delivery per endpoint.
+	QueueSize int `mapstructure:"queue-size"`
+	// TLSCA represents the path of the certificate file that is associated with the Certification Authority (CA).
+	TLSCA string `mapstructure:"tls-ca"`
+	// TLSCert is the path to the client certificate file.
+	TLSCert string `mapstructure:"tls-cert"`
+	// TLSKey represents the path to the client private key file.
+	TLSKey string `mapstructure:"tls-key"`
+	// TLSInsecureSkipVerify skips the chain and host verification.
+	TLSInsecureSkipVerify bool `mapstructure:"tls-insecure-skip-verify"`
+}
+
+// AddFlags registers persistent flags.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(enabled, false, "Indicates whether the webhook alert sender is enabled")
+	flags.String(contentType, "application/json", "Represents the request body content type")
+	flags.String(secret, "", "Specifies the key for the HMAC-SHA256 request signature")
+	flags.String(signatureHeader, "X-Fibratus-Signature", "Specifies the name of the header that carries the request signature")
+	flags.Duration(timeout, time.Second*10, "Represents the timeout of a single delivery attempt")
+	flags.Int(maxRetries, 3, "Specifies the maximum number of retries after the failed delivery attempt")
+	flags.Duration(retryBackoff, time.Second, "Represents the initial interval between delivery attempts")
+	flags.Duration(maxRetryBackoff, time.Second*30, "Represents the maximum interval between delivery attempts")
+	flags.Int(queueSize, 100, "Specifies the maximum number of alerts waiting for delivery per endpoint")
+	flags.String(tlsCA, "", "Represents the path of the certificate file that is associated with the Certification Authority (CA)")
+	flags.String(tlsCert, "", "Path to the client certificate file")
+	flags.String(tlsKey, "", "Path to the client private key file")
+	flags.Bool(tlsInsecureSkipVerify, false, "Indicates if the chain and host verification stage is skipped")
+}
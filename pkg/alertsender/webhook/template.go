@@ -0,0 +1,100 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"bytes"
+	"text/template"
+	"time"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/util/hostname"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+)
+
+// defaultTemplate renders the alert as the JSON object.
+const defaultTemplate = `{
+  "id": {{ .Alert.ID | toJson }},
+  "title": {{ .Alert.Title | toJson }},
+  "text": {{ .Alert.Text | toJson }},
+  "description": {{ .Alert.Description | toJson }},
+  "severity": {{ .Alert.Severity.String | toJson }},
+  "labels": {{ .Alert.Labels | default dict | toJson }},
+  "tags": {{ .Alert.Tags | default list | toJson }},
+  "hostname": {{ .Hostname | toJson }},
+  "timestamp": {{ .TriggeredAt.Format "2006-01-02T15:04:05.000Z07:00" | toJson }},
+  "events": {{ .Events }}
+}`
+
+// data is the root object passed to the body template.
+type data struct {
+	// Alert is the alert being sent.
+	Alert alertsender.Alert
+	// Events contains the JSON array of the events that triggered the alert.
+	Events string
+	// TriggeredAt is the time the alert was sent.
+	TriggeredAt time.Time
+	// Hostname is the name of the host where the alert was triggered.
+	Hostname string
+	// Version is the Fibratus version.
+	Version string
+}
+
+func parseTemplate(name, text string) (*template.Template, error) {
+	funcmap := sprig.TxtFuncMap()
+	// redefine hasKey to work on string map values
+	funcmap["hasKey"] = func(m map[string]string, key string) bool {
+		_, ok := m[key]
+		return ok
+	}
+	return template.New(name).Funcs(funcmap).Parse(text)
+}
+
+func newData(alert alertsender.Alert) data {
+	return data{
+		Alert:       alert,
+		Events:      marshalEvents(alert.Events),
+		TriggeredAt: time.Now(),
+		Hostname:    hostname.Get(),
+		Version:     version.Get(),
+	}
+}
+
+func render(tmpl *template.Template, d data) ([]byte, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, d); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+func marshalEvents(evts []*kevent.Kevent) string {
+	var b bytes.Buffer
+	b.WriteByte('[')
+	for i, evt := range evts {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.Write(evt.MarshalJSON())
+	}
+	b.WriteByte(']')
+	return b.String()
+}
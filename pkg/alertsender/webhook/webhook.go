@@ -0,0 +1,314 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/util/multierror"
+	"github.com/rabbitstack/fibratus/pkg/util/tls"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	deliveredAlerts = expvar.NewInt("alertsender.webhook.delivered.alerts")
+	deliveryErrors  = expvar.NewInt("alertsender.webhook.delivery.errors")
+	deliveryRetries = expvar.NewInt("alertsender.webhook.delivery.retries")
+	droppedAlerts   = expvar.NewInt("alertsender.webhook.dropped.alerts")
+
+	errNoEndpoints = errors.New("at least one endpoint is required")
+)
+
+const (
+	// timestampHeader carries the Unix time the request was signed at
+	timestampHeader = "X-Fibratus-Timestamp"
+	// shutdownTimeout is the time given to pending deliveries before the sender is shut down
+	shutdownTimeout = time.Second * 5
+)
+
+// endpoint maintains the queue of rendered alerts
+// and delivers them to the endpoint URL.
+type endpoint struct {
+	url     string
+	method  string
+	headers map[string]string
+	tmpl    *template.Template
+	secret  []byte
+	queue   chan []byte
+}
+
+// webhook posts alerts rendered by the template to HTTP endpoints. Alerts
+// are delivered asynchronously so the slow or unavailable endpoint doesn't
+// stall the rule engine.
+type webhook struct {
+	config    Config
+	client    *http.Client
+	endpoints []*endpoint
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func init() {
+	alertsender.Register(alertsender.Webhook, makeSender)
+}
+
+// makeSender constructs a new instance of the webhook alert sender.
+func makeSender(config alertsender.Config) (alertsender.Sender, error) {
+	c, ok := config.Sender.(Config)
+	if !ok {
+		return nil, alertsender.ErrInvalidConfig(alertsender.Webhook)
+	}
+	if len(c.Endpoints) == 0 {
+		return nil, errNoEndpoints
+	}
+	if c.Template == "" {
+		c.Template = defaultTemplate
+	}
+	if c.ContentType == "" {
+		c.ContentType = "application/json"
+	}
+	if c.SignatureHeader == "" {
+		c.SignatureHeader = "X-Fibratus-Signature"
+	}
+	if c.Timeout == 0 {
+		c.Timeout = time.Second * 10
+	}
+	if c.RetryBackoff == 0 {
+		c.RetryBackoff = time.Second
+	}
+	if c.MaxRetryBackoff == 0 {
+		c.MaxRetryBackoff = time.Second * 30
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = 100
+	}
+
+	tlsConfig, err := tls.MakeConfig(c.TLSCert, c.TLSKey, c.TLSCA, c.TLSInsecureSkipVerify)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TLS config: %v", err)
+	}
+	tmpl, err := parseTemplate("webhook", c.Template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template: %v", err)
+	}
+
+	w := &webhook{
+		config: c,
+		client: &http.Client{
+			Timeout: c.Timeout,
+			Transport: &http.Transport{
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   10 * time.Second,
+					KeepAlive: 30 * time.Second,
+				}).DialContext,
+				IdleConnTimeout:     90 * time.Second,
+				TLSHandshakeTimeout: 10 * time.Second,
+				TLSClientConfig:     tlsConfig,
+			},
+		},
+	}
+
+	for _, e := range c.Endpoints {
+		u, err := url.Parse(e.URL)
+		if err != nil || u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+			return nil, fmt.Errorf("invalid %q endpoint URL", e.URL)
+		}
+		ep := &endpoint{
+			url:     e.URL,
+			method:  e.Method,
+			headers: make(map[string]string),
+			tmpl:    tmpl,
+			secret:  []byte(c.Secret),
+			queue:   make(chan []byte, c.QueueSize),
+		}
+		if ep.method == "" {
+			ep.method = http.MethodPost
+		}
+		for k, v := range c.Headers {
+			ep.headers[k] = v
+		}
+		for k, v := range e.Headers {
+			ep.headers[k] = v
+		}
+		if e.Template != "" {
+			ep.tmpl, err = parseTemplate(e.URL, e.Template)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q endpoint template: %v", e.URL, err)
+			}
+		}
+		if e.Secret != "" {
+			ep.secret = []byte(e.Secret)
+		}
+		w.endpoints = append(w.endpoints, ep)
+	}
+
+	w.ctx, w.cancel = context.WithCancel(context.Background())
+	for _, ep := range w.endpoints {
+		w.wg.Add(1)
+		go w.deliver(ep)
+	}
+
+	return w, nil
+}
+
+// Send renders the alert for each endpoint and enqueues the request body
+// for delivery. Template errors and full queues are reported immediately,
+// while delivery errors are logged once all retries are exhausted.
+func (w *webhook) Send(alert alertsender.Alert) error {
+	d := newData(alert)
+	errs := make([]error, 0)
+	for _, ep := range w.endpoints {
+		body, err := render(ep.tmpl, d)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to render alert for %q endpoint: %v", ep.url, err))
+			continue
+		}
+		select {
+		case ep.queue <- body:
+		default:
+			droppedAlerts.Add(1)
+			errs = append(errs, fmt.Errorf("%q endpoint queue is full", ep.url))
+		}
+	}
+	return multierror.Wrap(errs...)
+}
+
+func (w *webhook) Type() alertsender.Type { return alertsender.Webhook }
+func (w *webhook) SupportsMarkdown() bool { return false }
+
+// Shutdown stops accepting alerts and waits for pending deliveries.
+// Deliveries that don't finish in time are abandoned.
+func (w *webhook) Shutdown() error {
+	for _, ep := range w.endpoints {
+		close(ep.queue)
+	}
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(shutdownTimeout):
+		w.cancel()
+		<-done
+	}
+	w.cancel()
+	return nil
+}
+
+func (w *webhook) deliver(ep *endpoint) {
+	defer w.wg.Done()
+	for body := range ep.queue {
+		if err := w.post(ep, body); err != nil {
+			deliveryErrors.Add(1)
+			log.Warnf("unable to deliver alert to %q webhook endpoint: %v", ep.url, err)
+			continue
+		}
+		deliveredAlerts.Add(1)
+	}
+}
+
+// post sends the request body to the endpoint. Network errors, 429 and
+// 5xx responses are retried with exponential backoff.
+func (w *webhook) post(ep *endpoint, body []byte) error {
+	b := &backoff.ExponentialBackOff{
+		InitialInterval:     w.config.RetryBackoff,
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		Multiplier:          backoff.DefaultMultiplier,
+		MaxInterval:         w.config.MaxRetryBackoff,
+		Stop:                backoff.Stop,
+		Clock:               backoff.SystemClock,
+	}
+	b.Reset()
+
+	var attempt int
+	op := func() error {
+		if attempt > 0 {
+			deliveryRetries.Add(1)
+		}
+		attempt++
+		return w.do(ep, body)
+	}
+	return backoff.Retry(op, backoff.WithContext(backoff.WithMaxRetries(b, uint64(w.config.MaxRetries)), w.ctx))
+}
+
+func (w *webhook) do(ep *endpoint, body []byte) error {
+	req, err := http.NewRequestWithContext(w.ctx, ep.method, ep.url, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", w.config.ContentType)
+	req.Header.Set("User-Agent", "fibratus")
+	for k, v := range ep.headers {
+		req.Header.Set(k, v)
+	}
+	if len(ep.secret) > 0 {
+		ts := strconv.FormatInt(time.Now().Unix(), 10)
+		req.Header.Set(timestampHeader, ts)
+		req.Header.Set(w.config.SignatureHeader, "sha256="+sign(ep.secret, ts, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("got %s response: %s", resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode >= 500 {
+		return err
+	}
+	return backoff.Permanent(err)
+}
+
+// sign computes the hex-encoded HMAC-SHA256 digest of the
+// timestamp and the request body joined by the dot.
+func sign(secret []byte, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(ts))
+	mac.Write([]byte{'.'})
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
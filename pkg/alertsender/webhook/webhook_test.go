@@ -0,0 +1,315 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+)
+
+// receiver records requests received by the test server.
+type receiver struct {
+	sync.Mutex
+	bodies  [][]byte
+	headers []http.Header
+}
+
+func (r *receiver) handler(status func(n int) int) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		r.Lock()
+		r.bodies = append(r.bodies, body)
+		r.headers = append(r.headers, req.Header.Clone())
+		n := len(r.bodies)
+		r.Unlock()
+		w.WriteHeader(status(n))
+	}
+}
+
+func (r *receiver) count() int {
+	r.Lock()
+	defer r.Unlock()
+	return len(r.bodies)
+}
+
+func ok(int) int { return http.StatusOK }
+
+func testAlert() alertsender.Alert {
+	alert := alertsender.NewAlertWithEvents(
+		"LSASS memory dumping",
+		"Detected the `rundll32` process dumping the LSASS memory",
+		[]string{"credential-access"},
+		alertsender.Critical,
+		[]*kevent.Kevent{
+			{
+				Type:     ktypes.OpenProcess,
+				Name:     "OpenProcess",
+				Category: ktypes.Process,
+				Seq:      2,
+				PID:      1023,
+				Tid:      2484,
+				Kparams: kevent.Kparams{
+					kparams.Exe: {Name: kparams.Exe, Type: kparams.UnicodeString, Value: `C:\Windows\System32\lsass.exe`},
+				},
+				Metadata: make(kevent.Metadata),
+			},
+		},
+	)
+	alert.ID = "4ac2c4b1-c3a4-4d8b-bdb7-ecd6fd7f9ba1"
+	alert.Labels = map[string]string{"tactic.id": "TA0006", "technique.id": "T1003"}
+	return alert
+}
+
+func TestSend(t *testing.T) {
+	var r receiver
+	srv := httptest.NewServer(r.handler(ok))
+	defer srv.Close()
+
+	s, err := makeSender(alertsender.Config{
+		Type: alertsender.Webhook,
+		Sender: Config{
+			Endpoints: []Endpoint{{URL: srv.URL + "/alerts", Headers: map[string]string{"Authorization": "Bearer 9f8a7b"}}},
+			Headers:   map[string]string{"X-Source": "fibratus", "Authorization": "Bearer global"},
+		},
+	})
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, s.Send(testAlert()))
+	require.Eventually(t, func() bool { return r.count() == 1 }, time.Second*5, time.Millisecond*10)
+
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(r.bodies[0], &doc))
+	assert.Equal(t, "4ac2c4b1-c3a4-4d8b-bdb7-ecd6fd7f9ba1", doc["id"])
+	assert.Equal(t, "LSASS memory dumping", doc["title"])
+	assert.Equal(t, "critical", doc["severity"])
+	assert.Equal(t, map[string]any{"tactic.id": "TA0006", "technique.id": "T1003"}, doc["labels"])
+	assert.Equal(t, []any{"credential-access"}, doc["tags"])
+	require.Len(t, doc["events"], 1)
+	assert.Equal(t, "OpenProcess", doc["events"].([]any)[0].(map[string]any)["name"])
+
+	h := r.headers[0]
+	assert.Equal(t, "application/json", h.Get("Content-Type"))
+	assert.Equal(t, "fibratus", h.Get("X-Source"))
+	assert.Equal(t, "Bearer 9f8a7b", h.Get("Authorization"))
+	assert.Empty(t, h.Get("X-Fibratus-Signature"))
+}
+
+func TestSendTemplateAndSignature(t *testing.T) {
+	var r1, r2 receiver
+	srv1 := httptest.NewServer(r1.handler(ok))
+	defer srv1.Close()
+	srv2 := httptest.NewServer(r2.handler(ok))
+	defer srv2.Close()
+
+	s, err := makeSender(alertsender.Config{
+		Type: alertsender.Webhook,
+		Sender: Config{
+			Template:    `{{ .Alert.Title }}|{{ .Alert.Severity }}|{{ index .Alert.Labels "tactic.id" }}|{{ .Alert.Tags | join "," }}`,
+			ContentType: "text/plain",
+			Secret:      "s3cr3t",
+			Endpoints: []Endpoint{
+				{URL: srv1.URL, Method: http.MethodPut},
+				{URL: srv2.URL, Template: `{{ .Alert.ID | upper }}`, Secret: "0th3r"},
+			},
+		},
+	})
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, s.Send(testAlert()))
+	require.Eventually(t, func() bool { return r1.count() == 1 && r2.count() == 1 }, time.Second*5, time.Millisecond*10)
+
+	assert.Equal(t, "LSASS memory dumping|critical|TA0006|credential-access", string(r1.bodies[0]))
+	assert.Equal(t, "4AC2C4B1-C3A4-4D8B-BDB7-ECD6FD7F9BA1", string(r2.bodies[0]))
+
+	h := r1.headers[0]
+	assert.Equal(t, "text/plain", h.Get("Content-Type"))
+	ts := h.Get("X-Fibratus-Timestamp")
+	require.NotEmpty(t, ts)
+	assert.Equal(t, "sha256="+sign([]byte("s3cr3t"), ts, r1.bodies[0]), h.Get("X-Fibratus-Signature"))
+	h = r2.headers[0]
+	assert.Equal(t, "sha256="+sign([]byte("0th3r"), h.Get("X-Fibratus-Timestamp"), r2.bodies[0]), h.Get("X-Fibratus-Signature"))
+}
+
+func TestSendRetries(t *testing.T) {
+	var tests = []struct {
+		name     string
+		status   func(n int) int
+		attempts int
+	}{
+		{"retry until success", func(n int) int {
+			if n < 3 {
+				return http.StatusServiceUnavailable
+			}
+			return http.StatusOK
+		}, 3},
+		{"exhaust retries", func(int) int { return http.StatusTooManyRequests }, 3},
+		{"no retries on client error", func(int) int { return http.StatusBadRequest }, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var r receiver
+			srv := httptest.NewServer(r.handler(tt.status))
+			defer srv.Close()
+
+			s, err := makeSender(alertsender.Config{
+				Type: alertsender.Webhook,
+				Sender: Config{
+					Endpoints:    []Endpoint{{URL: srv.URL}},
+					MaxRetries:   2,
+					RetryBackoff: time.Millisecond * 10,
+				},
+			})
+			require.NoError(t, err)
+
+			require.NoError(t, s.Send(testAlert()))
+			require.NoError(t, s.Shutdown())
+			assert.Equal(t, tt.attempts, r.count())
+		})
+	}
+}
+
+func TestSendQueueFull(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		<-release
+	}))
+	defer srv.Close()
+
+	s, err := makeSender(alertsender.Config{
+		Type:   alertsender.Webhook,
+		Sender: Config{Endpoints: []Endpoint{{URL: srv.URL}}, QueueSize: 1},
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Send(testAlert()))
+	require.Eventually(t, func() bool { return calls.Load() == 1 }, time.Second*5, time.Millisecond*10)
+	require.NoError(t, s.Send(testAlert()))
+	require.Error(t, s.Send(testAlert()))
+
+	close(release)
+	require.NoError(t, s.Shutdown())
+}
+
+func TestSendTLSClientCert(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, clientKey := writeCert(t, dir)
+
+	var r receiver
+	srv := httptest.NewUnstartedServer(r.handler(ok))
+	pool := x509.NewCertPool()
+	b, err := os.ReadFile(clientCert)
+	require.NoError(t, err)
+	require.True(t, pool.AppendCertsFromPEM(b))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: pool}
+	srv.StartTLS()
+	defer srv.Close()
+
+	ca := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(ca, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600))
+
+	s, err := makeSender(alertsender.Config{
+		Type: alertsender.Webhook,
+		Sender: Config{
+			Endpoints: []Endpoint{{URL: srv.URL}},
+			TLSCA:     ca,
+			TLSCert:   clientCert,
+			TLSKey:    clientKey,
+		},
+	})
+	require.NoError(t, err)
+	defer s.Shutdown()
+
+	require.NoError(t, s.Send(testAlert()))
+	require.Eventually(t, func() bool { return r.count() == 1 }, time.Second*5, time.Millisecond*10)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	var tests = []struct {
+		name   string
+		config Config
+	}{
+		{"no endpoints", Config{}},
+		{"invalid url", Config{Endpoints: []Endpoint{{URL: "soar.example.com/alerts"}}}},
+		{"invalid template", Config{Endpoints: []Endpoint{{URL: "https://soar.example.com"}}, Template: "{{ .Alert.Title "}},
+		{"invalid endpoint template", Config{Endpoints: []Endpoint{{URL: "https://soar.example.com", Template: "{{ end }}"}}}},
+		{"missing certificate", Config{Endpoints: []Endpoint{{URL: "https://soar.example.com"}}, TLSCert: "client.pem", TLSKey: "client.key"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := makeSender(alertsender.Config{Type: alertsender.Webhook, Sender: tt.config})
+			require.Error(t, err)
+		})
+	}
+}
+
+// writeCert generates the self-signed client certificate and its private key.
+func writeCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "fibratus"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	cert := filepath.Join(dir, "client.pem")
+	require.NoError(t, os.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	keyFile := filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return cert, keyFile
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"net/http"
+)
+
+// RulesReloader reloads the ruleset.
+type RulesReloader interface {
+	Reload() (*config.RulesCompileResult, error)
+}
+
+// ReloadRules is the handler that triggers the ruleset reload. If the
+// ruleset fails to compile, the previous ruleset remains active and
+// the error is returned in the response.
+func ReloadRules(reloader RulesReloader) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		rs, err := reloader.Reload()
+		if err != nil {
+			http.Error(w, fmt.Sprintf("unable to reload rules: %v", err), http.StatusUnprocessableEntity)
+			return
+		}
+		if rs == nil {
+			_, _ = w.Write([]byte("ruleset reloaded. No rules loaded"))
+			return
+		}
+		_, _ = w.Write([]byte(fmt.Sprintf("ruleset reloaded. %d rule(s) active\n%s", rs.NumberRules, rs)))
+	})
+}
@@ -30,9 +30,26 @@ import (
 	"strings"
 )
 
-func setupServer(lis net.Listener, c *config.Config) {
+// Option represents the option for the API server.
+type Option func(o *opts)
+
+type opts struct {
+	reloader handler.RulesReloader
+}
+
+// WithRulesReloader exposes the endpoint for reloading the ruleset.
+func WithRulesReloader(reloader handler.RulesReloader) Option {
+	return func(o *opts) {
+		o.reloader = reloader
+	}
+}
+
+func setupServer(lis net.Listener, c *config.Config, opts opts) {
 	mux := http.NewServeMux()
 	mux.Handle("/config", handler.Config(c))
+	if opts.reloader != nil {
+		mux.Handle("/rules/reload", handler.ReloadRules(opts.reloader))
+	}
 	mux.Handle("/debug/vars", expvar.Handler())
 
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
@@ -0,0 +1,62 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/config"
+	log "github.com/sirupsen/logrus"
+	"net"
+	"strings"
+)
+
+var listener net.Listener
+
+// StartServer starts the HTTP server with the specified configuration.
+// Named pipes are not available on this platform, so the server is only
+// started when the TCP transport is configured.
+func StartServer(c *config.Config, options ...Option) error {
+	var opts opts
+	for _, opt := range options {
+		opt(&opts)
+	}
+	apiConfig := c.API
+	if strings.HasPrefix(apiConfig.Transport, `npipe:///`) {
+		log.Infof("named pipe transport %s is not supported on this platform. API server is disabled", apiConfig.Transport)
+		return nil
+	}
+	var err error
+	listener, err = net.Listen("tcp", apiConfig.Transport)
+	if err != nil {
+		return err
+	}
+
+	setupServer(listener, c, opts)
+
+	return nil
+}
+
+// CloseServer shutdowns the server by stopping the listener.
+func CloseServer() error {
+	if listener != nil {
+		return listener.Close()
+	}
+	return nil
+}
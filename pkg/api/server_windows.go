@@ -29,7 +29,11 @@ import (
 var listener net.Listener
 
 // StartServer starts the HTTP server with the specified configuration.
-func StartServer(c *config.Config) error {
+func StartServer(c *config.Config, options ...Option) error {
+	var opts opts
+	for _, opt := range options {
+		opt(&opts)
+	}
 	var err error
 	apiConfig := c.API
 	if strings.HasPrefix(apiConfig.Transport, `npipe:///`) {
@@ -54,7 +58,7 @@ func StartServer(c *config.Config) error {
 		return err
 	}
 
-	setupServer(listener, c)
+	setupServer(listener, c, opts)
 
 	return nil
 }
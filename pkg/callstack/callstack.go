@@ -20,10 +20,7 @@ package callstack
 
 import (
 	"github.com/rabbitstack/fibratus/pkg/util/va"
-	"golang.org/x/arch/x86/x86asm"
-	"golang.org/x/sys/windows"
-	"os"
-	"path/filepath"
+	"github.com/rabbitstack/fibratus/pkg/util/winpath"
 	"strconv"
 	"strings"
 )
@@ -31,11 +28,6 @@ import (
 // unbacked represents the identifier for unbacked regions in stack frames
 const unbacked = "unbacked"
 
-var pageSize = uint64(os.Getpagesize())
-
-// buildNumber stores the Windows OS build number
-var _, _, buildNumber = windows.RtlGetNtVersionNumbers()
-
 // Frame describes a single stack frame.
 type Frame struct {
 	PID           uint32     // pid owning thread's stack
@@ -50,94 +42,6 @@ type Frame struct {
 // from unbacked memory section
 func (f Frame) IsUnbacked() bool { return f.Module == unbacked }
 
-// AllocationSize calculates the private region size
-// to which the frame return address pertains if the
-// memory pages within the region are private and
-// non-shareable pages.
-func (f *Frame) AllocationSize(proc windows.Handle) uint64 {
-	if f.Addr.InSystemRange() {
-		return 0
-	}
-
-	r := va.VirtualQuery(proc, f.Addr.Uint64())
-	if r == nil || (r.State != windows.MEM_COMMIT || r.Protect == windows.PAGE_NOACCESS || r.Type != va.MemImage) {
-		return 0
-	}
-
-	var size uint64
-
-	// traverse all pages in the region
-	for n := uint64(0); n < r.Size; n += pageSize {
-		addr := f.Addr.Inc(n)
-		ws := va.QueryWorkingSet(proc, addr.Uint64())
-		if ws == nil || !ws.Valid() {
-			continue
-		}
-
-		// use SharedOriginal after RS3/1709
-		if buildNumber >= 16299 {
-			if !ws.SharedOriginal() {
-				size += pageSize
-			}
-		} else {
-			if !ws.Shared() {
-				size += pageSize
-			}
-		}
-	}
-
-	return size
-}
-
-// Protection resolves the memory protection
-// of the pages within the region that contains the
-// frame return address.
-func (f *Frame) Protection(proc windows.Handle) string {
-	if f.Addr.InSystemRange() {
-		return ""
-	}
-	r := va.VirtualQuery(proc, f.Addr.Uint64())
-	if r == nil {
-		return "?"
-	}
-	return r.ProtectMask()
-}
-
-// CallsiteAssembly decodes the callsite trailing/leading
-// bytes depending on the value of the `leading` argument.
-// The resulting string contains the decoded x86 machine
-// opcodes in Intel assembler syntax.
-func (f *Frame) CallsiteAssembly(proc windows.Handle, leading bool) string {
-	if f.Addr.InSystemRange() {
-		return ""
-	}
-
-	size := uint(512)
-	base := f.Addr.Uintptr()
-	if leading {
-		base -= uintptr(size)
-	}
-
-	buf := va.ReadArea(proc, base, size, size, false)
-	if len(buf) == 0 || va.Zeroed(buf) {
-		return ""
-	}
-
-	var b strings.Builder
-
-	for i := 0; i < len(buf); {
-		ins, err := x86asm.Decode(buf[i:], 64)
-		if err != nil {
-			return b.String()
-		}
-		b.WriteString(x86asm.IntelSyntax(ins, f.Addr.Uint64(), nil))
-		b.WriteRune('|')
-		i += ins.Len
-	}
-
-	return b.String()
-}
-
 // Callstack is a sequence of stack frames
 // representing function executions.
 type Callstack []Frame
@@ -211,7 +115,7 @@ func (s Callstack) Summary() string {
 		if frame.IsUnbacked() {
 			n = unbacked
 		} else {
-			n = filepath.Base(frame.Module)
+			n = winpath.Base(frame.Module)
 		}
 
 		if n == prev {
@@ -307,52 +211,7 @@ func (s Callstack) Modules() []string {
 func (s Callstack) Symbols() []string {
 	syms := make([]string, len(s))
 	for i, f := range s {
-		syms[i] = filepath.Base(f.Module) + "!" + f.Symbol
+		syms[i] = winpath.Base(f.Module) + "!" + f.Symbol
 	}
 	return syms
 }
-
-// AllocationSizes returns allocation size of each stack frame
-// in terms of allocation/module private non-shareable pages.
-func (s Callstack) AllocationSizes(pid uint32) []uint64 {
-	proc, err := windows.OpenProcess(windows.PROCESS_QUERY_INFORMATION, false, pid)
-	if err != nil {
-		return nil
-	}
-	defer windows.Close(proc)
-	sizes := make([]uint64, len(s))
-	for i, f := range s {
-		sizes[i] = f.AllocationSize(proc)
-	}
-	return sizes
-}
-
-// Protections returns page protection mask for every
-// frame comprising the stack.
-func (s Callstack) Protections(pid uint32) []string {
-	proc, err := windows.OpenProcess(windows.PROCESS_QUERY_INFORMATION, false, pid)
-	if err != nil {
-		return nil
-	}
-	defer windows.Close(proc)
-	prots := make([]string, len(s))
-	for i, f := range s {
-		prots[i] = f.Protection(proc)
-	}
-	return prots
-}
-
-// CallsiteInsns returns callsite assembly opcodes
-// for leading/trailing bytes contained in each frame.
-func (s Callstack) CallsiteInsns(pid uint32, leading bool) []string {
-	proc, err := windows.OpenProcess(windows.PROCESS_QUERY_INFORMATION|windows.PROCESS_VM_READ, false, pid)
-	if err != nil {
-		return nil
-	}
-	defer windows.Close(proc)
-	opcodes := make([]string, len(s))
-	for i, f := range s {
-		opcodes[i] = f.CallsiteAssembly(proc, leading)
-	}
-	return opcodes
-}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package callstack
+
+// AllocationSizes returns nil as the allocation size of
+// the frame memory region can only be resolved by querying
+// the live process on Windows.
+func (s Callstack) AllocationSizes(pid uint32) []uint64 { return nil }
+
+// Protections returns nil as the frame memory region
+// protection can only be resolved by querying the live
+// process on Windows.
+func (s Callstack) Protections(pid uint32) []string { return nil }
+
+// CallsiteInsns returns nil as the callsite instructions
+// can only be read from the live process address space
+// on Windows.
+func (s Callstack) CallsiteInsns(pid uint32, leading bool) []string { return nil }
@@ -24,8 +24,11 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/alertsender"
 	"github.com/rabbitstack/fibratus/pkg/alertsender/eventlog"
 	"github.com/rabbitstack/fibratus/pkg/alertsender/mail"
+	"github.com/rabbitstack/fibratus/pkg/alertsender/pagerduty"
 	"github.com/rabbitstack/fibratus/pkg/alertsender/slack"
 	"github.com/rabbitstack/fibratus/pkg/alertsender/systray"
+	"github.com/rabbitstack/fibratus/pkg/alertsender/teams"
+	"github.com/rabbitstack/fibratus/pkg/alertsender/webhook"
 	"reflect"
 )
 
@@ -52,8 +55,22 @@ func (c *Config) tryLoadAlertSenders() error {
 		return fmt.Errorf("expected map[string]interface{} type for alertsenders but found %s", reflect.TypeOf(alertsenders))
 	}
 
+	var routes alertsender.RouteConfig
 	for typ, config := range mapping {
 		switch typ {
+		case "routes":
+			if err := decode(config, &routes.Routes); err != nil {
+				return fmt.Errorf("invalid alert routes: %v", err)
+			}
+		case "default-route":
+			if config == nil {
+				continue
+			}
+			var route alertsender.Route
+			if err := decode(config, &route); err != nil {
+				return fmt.Errorf("invalid default alert route: %v", err)
+			}
+			routes.Default = &route
 		case "mail":
 			var mailConfig mail.Config
 			if err := decode(config, &mailConfig); err != nil {
@@ -107,10 +124,51 @@ func (c *Config) tryLoadAlertSenders() error {
 				Sender: eventlogConfig,
 			}
 			configs = append(configs, config)
+
+		case "webhook":
+			var webhookConfig webhook.Config
+			if err := decode(config, &webhookConfig); err != nil {
+				return errAlertsenderConfig(typ, err)
+			}
+			if !webhookConfig.Enabled {
+				continue
+			}
+			config := alertsender.Config{
+				Type:   alertsender.Webhook,
+				Sender: webhookConfig,
+			}
+			configs = append(configs, config)
+		case "pagerduty":
+			var pagerdutyConfig pagerduty.Config
+			if err := decode(config, &pagerdutyConfig); err != nil {
+				return errAlertsenderConfig(typ, err)
+			}
+			if !pagerdutyConfig.Enabled {
+				continue
+			}
+			config := alertsender.Config{
+				Type:   alertsender.PagerDuty,
+				Sender: pagerdutyConfig,
+			}
+			configs = append(configs, config)
+		case "teams":
+			var teamsConfig teams.Config
+			if err := decode(config, &teamsConfig); err != nil {
+				return errAlertsenderConfig(typ, err)
+			}
+			if !teamsConfig.Enabled {
+				continue
+			}
+			config := alertsender.Config{
+				Type:   alertsender.Teams,
+				Sender: teamsConfig,
+			}
+			configs = append(configs, config)
 		}
 	}
 
 	c.Alertsenders = configs
+	c.AlertRoutes = routes
 
 	return nil
 }
@@ -77,6 +77,17 @@ func TestNewFromYamlFile(t *testing.T) {
 		}
 	}
 
+	require.Len(t, c.AlertRoutes.Routes, 2)
+	route := c.AlertRoutes.Routes[0]
+	assert.Equal(t, "credential-access", route.Name)
+	assert.Equal(t, "high", route.Severity)
+	assert.Equal(t, []string{"tactic.id = TA0006"}, route.Labels)
+	assert.Equal(t, []string{"slack"}, route.Senders)
+	assert.True(t, route.Continue)
+	assert.Equal(t, []string{"*LSASS*"}, c.AlertRoutes.Routes[1].Rules)
+	require.NotNil(t, c.AlertRoutes.Default)
+	assert.Equal(t, []string{"mail"}, c.AlertRoutes.Default.Senders)
+
 	assert.Equal(t, "npipe:///fibratus", c.API.Transport)
 	assert.Equal(t, time.Second*5, c.API.Timeout)
 	assert.True(t, c.DebugPrivilege)
@@ -84,6 +95,9 @@ func TestNewFromYamlFile(t *testing.T) {
 	assert.Equal(t, "top_netio", c.Filament.Name)
 
 	require.Len(t, c.Transformers, 2)
+	assert.Equal(t, transformers.Remove, c.Transformers[0].Type)
+	assert.Equal(t, "kevt.name = 'CreateFile'", c.Transformers[0].When)
+	assert.Empty(t, c.Transformers[1].When)
 
 	for _, tr := range c.Transformers {
 		switch tr.Type {
@@ -121,3 +135,26 @@ func TestNewFromJsonFile(t *testing.T) {
 	assert.Equal(t, time.Millisecond*230, c.Aggregator.FlushPeriod)
 	assert.Equal(t, time.Second*8, c.Aggregator.FlushTimeout)
 }
+
+func TestKcapRangeFlags(t *testing.T) {
+	c := NewWithOpts(WithReplay())
+
+	err := c.flags.Parse([]string{
+		"--config-file=../../configs/fibratus.yml",
+		"-k", "_fixtures/events.kcap",
+		"--kcap.from=2024-03-01T10:00:00Z",
+		"--kcap.to=2024-03-01T10:05:00Z",
+		"--kcap.seq=1000-2000",
+	})
+	require.NoError(t, err)
+	require.NoError(t, c.viper.BindPFlags(c.flags))
+	require.NoError(t, c.TryLoadFile(c.GetConfigFile()))
+
+	require.NoError(t, c.Init())
+	require.NoError(t, c.Validate())
+
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), c.KcapRange.From)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 5, 0, 0, time.UTC), c.KcapRange.To)
+	assert.Equal(t, uint64(1000), c.KcapRange.FromSeq)
+	assert.Equal(t, uint64(2000), c.KcapRange.ToSeq)
+}
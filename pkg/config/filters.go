@@ -38,6 +38,7 @@ import (
 	"slices"
 	"strings"
 	"text/template"
+	"time"
 )
 
 // FilterConfig is the descriptor of a single filter.
@@ -56,6 +57,67 @@ type FilterConfig struct {
 	Notes            string            `json:"notes" yaml:"notes"`
 	MinEngineVersion string            `json:"min-engine-version" yaml:"min-engine-version"`
 	Enabled          *bool             `json:"enabled" yaml:"enabled"`
+	Suppress         *SuppressConfig   `json:"suppress" yaml:"suppress"`
+	Tests            []FilterTest      `json:"tests" yaml:"tests"`
+}
+
+// SuppressConfig controls the alert suppression for the rule.
+// Alerts sharing the same deduplication key are throttled
+// within the time window. The key is composed of the field
+// values extracted from the events that triggered the alert.
+type SuppressConfig struct {
+	// Fields contains filter fields that compose the deduplication key.
+	// The field can be prefixed with the ordinal of the sequence event,
+	// e.g. 2.file.path. If no fields are given, all alerts of the rule
+	// share the same key.
+	Fields []string `json:"fields" yaml:"fields"`
+	// Window is the time window in which alerts with the same key are suppressed.
+	Window time.Duration `json:"window" yaml:"window"`
+	// MaxAlerts specifies how many alerts with the same key are emitted
+	// within the time window before the suppression kicks in.
+	MaxAlerts int `json:"max-alerts" yaml:"max-alerts"`
+}
+
+// FilterTest is the test case embedded in the rule definition.
+// It describes a series of synthetic events that are fed into
+// the rule engine, and the expected outcome of the rule evaluation.
+type FilterTest struct {
+	// Name is the short description of the test case.
+	Name string `json:"name" yaml:"name"`
+	// Match indicates whether the rule is expected to match the events.
+	Match bool `json:"match" yaml:"match"`
+	// Events contains synthetic events in the order they are produced.
+	Events []FilterTestEvent `json:"events" yaml:"events"`
+}
+
+// FilterTestEvent describes the synthetic event of the rule test case.
+type FilterTestEvent struct {
+	// Name is the event name, e.g. CreateProcess.
+	Name string `json:"name" yaml:"name"`
+	// At is the event timestamp relative to the start of the test case.
+	At time.Duration `json:"at" yaml:"at"`
+	// Params contains event parameters keyed by parameter name.
+	Params map[string]any `json:"params" yaml:"params"`
+	// PS is the state of the process that generated the event.
+	PS *FilterTestProcess `json:"ps" yaml:"ps"`
+}
+
+// FilterTestProcess describes the process state attached to the synthetic event.
+type FilterTestProcess struct {
+	PID       uint32             `json:"pid" yaml:"pid"`
+	Ppid      uint32             `json:"ppid" yaml:"ppid"`
+	Name      string             `json:"name" yaml:"name"`
+	Exe       string             `json:"exe" yaml:"exe"`
+	Cmdline   string             `json:"cmdline" yaml:"cmdline"`
+	Cwd       string             `json:"cwd" yaml:"cwd"`
+	Args      []string           `json:"args" yaml:"args"`
+	SID       string             `json:"sid" yaml:"sid"`
+	Username  string             `json:"username" yaml:"username"`
+	Domain    string             `json:"domain" yaml:"domain"`
+	SessionID uint32             `json:"session-id" yaml:"session-id"`
+	Envs      map[string]string  `json:"envs" yaml:"envs"`
+	Modules   []string           `json:"modules" yaml:"modules"`
+	Parent    *FilterTestProcess `json:"parent" yaml:"parent"`
 }
 
 // FilterAction wraps all possible filter actions.
@@ -111,6 +173,9 @@ func (f FilterConfig) DecodeActions() ([]any, error) {
 // IsDisabled determines if this filter is disabled.
 func (f FilterConfig) IsDisabled() bool { return f.Enabled != nil && !*f.Enabled }
 
+// HasTests determines if the filter has embedded test cases.
+func (f FilterConfig) HasTests() bool { return len(f.Tests) > 0 }
+
 // HasLabel determines if the filter has the given label.
 func (f FilterConfig) HasLabel(l string) bool { return f.Labels[l] != "" }
 
@@ -137,6 +202,9 @@ type Rules struct {
 	Enabled   bool     `json:"enabled" yaml:"enabled"`
 	FromPaths []string `json:"from-paths" yaml:"from-paths"`
 	FromURLs  []string `json:"from-urls" yaml:"from-urls"`
+	// Watch indicates if rule and macro files are watched for
+	// changes. When the files change, the ruleset is reloaded.
+	Watch bool `json:"watch" yaml:"watch"`
 }
 
 // Macros contains attributes that describe the location of
@@ -164,6 +232,12 @@ type ActionContext struct {
 	Events []*kevent.Kevent
 	// Filter represents the filter that matched the event
 	Filter *FilterConfig
+	// Suppressed is the number of alerts suppressed
+	// since the last alert emitted by the rule
+	Suppressed int
+	// Capture is the path of the flight recorder
+	// capture file dumped on behalf of the match
+	Capture string
 }
 
 // UniquePids returns a set of process identifiers
@@ -257,6 +331,7 @@ const (
 	rulesEnabled    = "filters.rules.enabled"
 	rulesFromPaths  = "filters.rules.from-paths"
 	rulesFromURLs   = "filters.rules.from-urls"
+	rulesWatch      = "filters.rules.watch"
 	macrosFromPaths = "filters.macros.from-paths"
 	matchAll        = "filters.match-all"
 )
@@ -265,6 +340,7 @@ func (f *Filters) initFromViper(v *viper.Viper) {
 	f.Rules.Enabled = v.GetBool(rulesEnabled)
 	f.Rules.FromPaths = v.GetStringSlice(rulesFromPaths)
 	f.Rules.FromURLs = v.GetStringSlice(rulesFromURLs)
+	f.Rules.Watch = v.GetBool(rulesWatch)
 	f.Macros.FromPaths = v.GetStringSlice(macrosFromPaths)
 	f.MatchAll = v.GetBool(matchAll)
 }
@@ -1,6 +1,3 @@
-//go:build windows
-// +build windows
-
 /*
  * Copyright 2019-2020 by Nedim Sabic Sabic
  * https://www.fibratus.io
@@ -22,7 +19,6 @@
 package config
 
 import (
-	"golang.org/x/sys/windows"
 	"runtime"
 	"time"
 
@@ -169,7 +165,7 @@ func (c *KstreamConfig) TestDropMask(ktype ktypes.Ktype) bool {
 
 // ExcludeKevent determines whether the supplied provider GUID
 // and the hook identifier are in the bitset of excluded events.
-func (c *KstreamConfig) ExcludeKevent(guid windows.GUID, hookID uint16) bool {
+func (c *KstreamConfig) ExcludeKevent(guid ktypes.GUID, hookID uint16) bool {
 	return c.dropMasks.Test(guid, hookID)
 }
 
@@ -22,7 +22,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"strconv"
+	"sort"
 
 	"github.com/rabbitstack/fibratus/pkg/outputs/eventlog"
 
@@ -30,17 +30,21 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/outputs/amqp"
 	"github.com/rabbitstack/fibratus/pkg/outputs/console"
 	"github.com/rabbitstack/fibratus/pkg/outputs/elasticsearch"
+	"github.com/rabbitstack/fibratus/pkg/outputs/file"
 	"github.com/rabbitstack/fibratus/pkg/outputs/http"
+	"github.com/rabbitstack/fibratus/pkg/outputs/kafka"
 	"github.com/rabbitstack/fibratus/pkg/outputs/null"
+	"github.com/rabbitstack/fibratus/pkg/outputs/otlp"
+	"github.com/rabbitstack/fibratus/pkg/outputs/splunk"
+	"github.com/rabbitstack/fibratus/pkg/outputs/syslog"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/sys/windows/svc"
 )
 
 var errNoOutputSection = errors.New("no output section in config")
 
 var errOutputConfig = func(output string, err error) error { return fmt.Errorf("%s output invalid config: %v", output, err) }
 
-func (c *Config) tryLoadOutput() error {
+func (c *Config) tryLoadOutputs() error {
 	output := c.viper.AllSettings()["output"]
 	if output == nil {
 		return errNoOutputSection
@@ -50,120 +54,121 @@ func (c *Config) tryLoadOutput() error {
 		return fmt.Errorf("expected map[string]interface{} type for output but found %s", reflect.TypeOf(output))
 	}
 
-	humNum := func(n int) string {
-		switch n {
-		case 2:
-			return "two"
-		case 3:
-			return "three"
-		case 4:
-			return "four"
-		case 5:
-			return "five"
-		case 6:
-			return "six"
-		case 7:
-			return "seven"
-		default:
-			return strconv.Itoa(n)
-		}
-	}
-	// don't permit if there are various outputs enabled at a time
-	activeOutputs := findActiveOutputs(mapping)
-	if len(activeOutputs) > 1 {
-		return fmt.Errorf("expected one but found %s active outputs: %s", humNum(len(activeOutputs)), activeOutputs)
-	}
+	configs := make([]outputs.Config, 0)
 
-	for typ, config := range mapping {
-		switch outputs.TypeFromString(typ) {
+	for name, config := range mapping {
+		var (
+			enabled bool
+			output  interface{}
+		)
+		typ := outputs.TypeFromString(name)
+		switch typ {
 		case outputs.Console:
 			var consoleConfig console.Config
 			if err := decode(config, &consoleConfig); err != nil {
-				return errOutputConfig(typ, err)
+				return errOutputConfig(name, err)
 			}
-			if !consoleConfig.Enabled {
-				continue
-			}
-			c.Output.Type, c.Output.Output = outputs.Console, consoleConfig
+			enabled, output = consoleConfig.Enabled, consoleConfig
 
 		case outputs.AMQP:
 			var amqpConfig amqp.Config
 			if err := decode(config, &amqpConfig); err != nil {
-				return errOutputConfig(typ, err)
-			}
-			if !amqpConfig.Enabled {
-				continue
+				return errOutputConfig(name, err)
 			}
-			c.Output.Type, c.Output.Output = outputs.AMQP, amqpConfig
+			enabled, output = amqpConfig.Enabled, amqpConfig
 
 		case outputs.Elasticsearch:
 			var esConfig elasticsearch.Config
 			if err := decode(config, &esConfig); err != nil {
-				return errOutputConfig(typ, err)
+				return errOutputConfig(name, err)
 			}
-			if !esConfig.Enabled {
-				continue
-			}
-			c.Output.Type, c.Output.Output = outputs.Elasticsearch, esConfig
+			enabled, output = esConfig.Enabled, esConfig
 
 		case outputs.HTTP:
 			var httpConfig http.Config
 			if err := decode(config, &httpConfig); err != nil {
-				return errOutputConfig(typ, err)
-			}
-			if !httpConfig.Enabled {
-				continue
+				return errOutputConfig(name, err)
 			}
-			c.Output.Type, c.Output.Output = outputs.HTTP, httpConfig
+			enabled, output = httpConfig.Enabled, httpConfig
 
 		case outputs.Eventlog:
 			var eventlogConfig eventlog.Config
 			if err := decode(config, &eventlogConfig); err != nil {
-				return errOutputConfig(typ, err)
+				return errOutputConfig(name, err)
 			}
-			if !eventlogConfig.Enabled {
-				continue
+			enabled, output = eventlogConfig.Enabled, eventlogConfig
+
+		case outputs.Syslog:
+			var syslogConfig syslog.Config
+			if err := decode(config, &syslogConfig); err != nil {
+				return errOutputConfig(name, err)
 			}
-			c.Output.Type, c.Output.Output = outputs.Eventlog, eventlogConfig
-		}
-	}
+			enabled, output = syslogConfig.Enabled, syslogConfig
+
+		case outputs.Kafka:
+			var kafkaConfig kafka.Config
+			if err := decode(config, &kafkaConfig); err != nil {
+				return errOutputConfig(name, err)
+			}
+			enabled, output = kafkaConfig.Enabled, kafkaConfig
+
+		case outputs.OTLP:
+			var otlpConfig otlp.Config
+			if err := decode(config, &otlpConfig); err != nil {
+				return errOutputConfig(name, err)
+			}
+			enabled, output = otlpConfig.Enabled, otlpConfig
 
-	// if it is not an interactive session but the console output is enabled
-	// we default to null output and warn about that
-	if isWindowsService() && c.Output.Output != nil {
-		if c.Output.Type == outputs.Console {
+		case outputs.File:
+			var fileConfig file.Config
+			if err := decode(config, &fileConfig); err != nil {
+				return errOutputConfig(name, err)
+			}
+			enabled, output = fileConfig.Enabled, fileConfig
+
+		case outputs.Splunk:
+			var splunkConfig splunk.Config
+			if err := decode(config, &splunkConfig); err != nil {
+				return errOutputConfig(name, err)
+			}
+			enabled, output = splunkConfig.Enabled, splunkConfig
+
+		default:
+			continue
+		}
+		if !enabled {
+			continue
+		}
+		// if it is not an interactive session but the console output
+		// is enabled we discard it and warn about that
+		if typ == outputs.Console && isWindowsService() {
 			log.Warn("running in non-interactive session with console output. " +
-				"Please configure a different output type. Defaulting to null output")
-			c.Output.Type, c.Output.Output = outputs.Null, &null.Config{}
-			return nil
+				"Please configure a different output type. Discarding console output")
+			continue
 		}
+		configs = append(configs, outputs.Config{Type: typ, Output: output, Filter: outputFilter(config)})
 	}
 
 	// default to null output
-	if c.Output.Output == nil {
+	if len(configs) == 0 {
 		log.Warn("all outputs disabled. Defaulting to null output")
-		c.Output.Type, c.Output.Output = outputs.Null, &null.Config{}
+		configs = append(configs, outputs.Config{Type: outputs.Null, Output: &null.Config{}})
 	}
 
-	return nil
-}
+	// sort outputs to guarantee stable ordering regardless of map iteration
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Type < configs[j].Type })
 
-func findActiveOutputs(outputs map[string]interface{}) []string {
-	outputTypes := make([]string, 0)
-	for typ, rawConfig := range outputs {
-		enabled, ok := rawConfig.(map[string]interface{})["enabled"].(bool)
-		if ok && enabled {
-			outputTypes = append(outputTypes, typ)
-		}
-	}
-	return outputTypes
+	c.Outputs = configs
+
+	return nil
 }
 
-// isWindowsService returns true if the process is running inside Windows Service.
-func isWindowsService() bool {
-	isWinService, err := svc.IsWindowsService()
-	if err != nil {
-		return false
+// outputFilter returns the filter expression shared by all output configurations.
+func outputFilter(config interface{}) string {
+	m, ok := config.(map[string]interface{})
+	if !ok {
+		return ""
 	}
-	return isWinService
+	expr, _ := m["filter"].(string)
+	return expr
 }
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	recorderEnabled  = "kcap.recorder.enabled"
+	recorderMaxAge   = "kcap.recorder.max-age"
+	recorderMaxSize  = "kcap.recorder.max-size"
+	recorderSeverity = "kcap.recorder.min-severity"
+	recorderPath     = "kcap.recorder.path"
+	recorderCooldown = "kcap.recorder.cooldown"
+	recorderMaxDumps = "kcap.recorder.max-dumps"
+)
+
+// RecorderConfig contains the settings of the flight recorder. The flight
+// recorder keeps the most recent events in memory, and when a rule fires,
+// dumps them to the capture file linked in the alert.
+type RecorderConfig struct {
+	// Enabled indicates if the flight recorder is enabled.
+	Enabled bool `json:"kcap.recorder.enabled" yaml:"kcap.recorder.enabled"`
+	// MaxAge is the maximum age of the events retained by the recorder.
+	MaxAge time.Duration `json:"kcap.recorder.max-age" yaml:"kcap.recorder.max-age"`
+	// MaxSize is the maximum size in megabytes of the events retained by the recorder.
+	MaxSize int `json:"kcap.recorder.max-size" yaml:"kcap.recorder.max-size"`
+	// MinSeverity is the minimum severity of the rule that triggers the capture dump.
+	MinSeverity string `json:"kcap.recorder.min-severity" yaml:"kcap.recorder.min-severity"`
+	// Path is the directory where capture dumps are stored.
+	Path string `json:"kcap.recorder.path" yaml:"kcap.recorder.path"`
+	// Cooldown is the minimum time between two consecutive capture dumps.
+	Cooldown time.Duration `json:"kcap.recorder.cooldown" yaml:"kcap.recorder.cooldown"`
+	// MaxDumps is the maximum number of capture dumps kept in the directory.
+	MaxDumps int `json:"kcap.recorder.max-dumps" yaml:"kcap.recorder.max-dumps"`
+}
+
+func (c *RecorderConfig) initFromViper(v *viper.Viper) {
+	c.Enabled = v.GetBool(recorderEnabled)
+	c.MaxAge = v.GetDuration(recorderMaxAge)
+	c.MaxSize = v.GetInt(recorderMaxSize)
+	c.MinSeverity = v.GetString(recorderSeverity)
+	c.Path = v.GetString(recorderPath)
+	c.Cooldown = v.GetDuration(recorderCooldown)
+	c.MaxDumps = v.GetInt(recorderMaxDumps)
+}
+
+// Validate checks the values that are not constrained
+// by the config schema, such as the ones given in flags.
+func (c RecorderConfig) Validate() error {
+	if _, err := alertsender.ParseSeverity(c.MinSeverity); err != nil {
+		return fmt.Errorf("invalid flight recorder min-severity: %v", err)
+	}
+	if c.MaxSize <= 0 {
+		return fmt.Errorf("flight recorder max-size must be positive")
+	}
+	if c.Cooldown < 0 || c.MaxDumps < 0 || c.MaxAge < 0 {
+		return fmt.Errorf("flight recorder max-age, cooldown, and max-dumps can't be negative")
+	}
+	return nil
+}
+
+func (c *RecorderConfig) addFlags(flags *pflag.FlagSet) {
+	flags.Bool(recorderEnabled, false, "Indicates if the flight recorder is enabled. The flight recorder keeps the most recent events in memory and dumps them to the capture file when a rule fires")
+	flags.Duration(recorderMaxAge, time.Minute*5, "Specifies the maximum age of the events retained by the flight recorder")
+	flags.Int(recorderMaxSize, 256, "Specifies the maximum size in megabytes of the events retained by the flight recorder")
+	flags.String(recorderSeverity, "high", "Specifies the minimum rule severity that triggers the flight recorder capture dump")
+	flags.String(recorderPath, filepath.Join(os.Getenv("PROGRAMFILES"), "fibratus", "captures"), "Specifies the directory where flight recorder captures are stored")
+	flags.Duration(recorderCooldown, time.Minute, "Specifies the minimum time between two consecutive flight recorder capture dumps. Alerts fired during the cooldown don't trigger dumps")
+	flags.Int(recorderMaxDumps, 50, "Specifies the maximum number of flight recorder captures kept in the directory. The oldest captures are removed first. Zero keeps all captures")
+}
@@ -34,7 +34,19 @@ var schema = `
 			"type": "object",
 			"properties": {
 				"flush-period":		{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s"},
-				"flush-timeout":	{"type": "string", "minLength": 2, "pattern": "[0-9]+s"}
+				"flush-timeout":	{"type": "string", "minLength": 2, "pattern": "[0-9]+s"},
+				"spool": {
+					"type": "object",
+					"properties": {
+						"enabled":			{"type": "boolean"},
+						"dir":				{"type": "string"},
+						"max-size":			{"type": "integer", "minimum": 1},
+						"segment-size":		{"type": "integer", "minimum": 1},
+						"policy":			{"type": "string", "enum": ["drop-oldest", "block"]},
+						"max-backoff":		{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m"}
+					},
+					"additionalProperties": false
+				}
 			},
 			"additionalProperties": false
 		},
@@ -100,6 +112,107 @@ var schema = `
 								"verbose": {"type": "boolean"}
 							},
 							"additionalProperties": false
+						},
+						"webhook": {
+							"type": "object",
+							"properties": {
+								"enabled": 						{"type": "boolean"},
+								"endpoints": 					{"type": "array", "items": [
+																	{
+																		"type": "object",
+																		"properties": {
+																			"url": 		{"type": "string", "format": "uri", "minLength": 1, "pattern": "^(https?|http?)://"},
+																			"method": 	{"type": "string", "enum": ["POST", "PUT", "PATCH"]},
+																			"headers": 	{"type": "object", "additionalProperties": {"type": "string"}},
+																			"template": {"type": "string"},
+																			"secret": 	{"type": "string"}
+																		},
+																		"required": ["url"],
+																		"additionalProperties": false
+																	}
+								]},
+								"template": 					{"type": "string"},
+								"content-type": 				{"type": "string", "minLength": 1},
+								"headers": 						{"type": "object", "additionalProperties": {"type": "string"}},
+								"secret": 						{"type": "string"},
+								"signature-header": 			{"type": "string", "minLength": 1},
+								"timeout": 						{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m"},
+								"max-retries": 					{"type": "integer", "minimum": 0},
+								"retry-backoff": 				{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m"},
+								"max-retry-backoff": 			{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m"},
+								"queue-size": 					{"type": "integer", "minimum": 1},
+								"tls-ca": 						{"type": "string"},
+								"tls-cert": 					{"type": "string"},
+								"tls-key": 						{"type": "string"},
+								"tls-insecure-skip-verify": 	{"type": "boolean"}
+							},
+							"if": {
+								"properties": {"enabled": { "const": true }}
+							},
+							"then": {
+								"properties": {"endpoints": {"minItems": 1}}
+							},
+							"additionalProperties": false
+						},
+						"pagerduty": {
+							"type": "object",
+							"properties": {
+								"enabled": 			{"type": "boolean"},
+								"routing-key": 		{"type": "string"},
+								"url": 				{"type": "string", "format": "uri", "minLength": 1, "pattern": "^(https?|http?)://"},
+								"dedup-fields": 	{"type": "array", "items": [{"type": "string", "minLength": 1}]},
+								"severities": 		{"type": "object", "additionalProperties": {"type": "string", "enum": ["info", "warning", "error", "critical"]}},
+								"resolve-after": 	{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m|h"},
+								"timeout": 			{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m"}
+							},
+							"if": {
+								"properties": {"enabled": { "const": true }}
+							},
+							"then": {
+								"properties": {"routing-key": {"minLength": 1}}
+							},
+							"additionalProperties": false
+						},
+						"teams": {
+							"type": "object",
+							"properties": {
+								"enabled": 		{"type": "boolean"},
+								"url": 			{"type": "string"},
+								"max-events": 	{"type": "integer", "minimum": 0},
+								"timeout": 		{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m"}
+							},
+							"if": {
+								"properties": {"enabled": { "const": true }}
+							},
+							"then": {
+								"properties": {"url": {"minLength": 1, "pattern": "^(https?|http?)://"}}
+							},
+							"additionalProperties": false
+						},
+						"routes": {
+							"type": "array",
+							"items": {
+								"type": "object",
+								"properties": {
+									"name": 		{"type": "string"},
+									"severity": 	{"type": "string", "enum": ["low", "normal", "medium", "high", "critical"]},
+									"labels": 		{"type": "array", "items": {"type": "string", "pattern": "^[^=!]+!?=.+$"}},
+									"tags": 		{"type": "array", "items": {"type": "string", "minLength": 1}},
+									"rules": 		{"type": "array", "items": {"type": "string", "minLength": 1}},
+									"senders": 		{"type": "array", "minItems": 1, "items": {"type": "string", "enum": ["mail", "slack", "systray", "eventlog", "webhook", "pagerduty", "teams"]}},
+									"continue": 	{"type": "boolean"}
+								},
+								"required": ["senders"],
+								"additionalProperties": false
+							}
+						},
+						"default-route": {
+							"type": "object",
+							"properties": {
+								"senders": 		{"type": "array", "minItems": 1, "items": {"type": "string", "enum": ["mail", "slack", "systray", "eventlog", "webhook", "pagerduty", "teams"]}}
+							},
+							"required": ["senders"],
+							"additionalProperties": false
 						}
 					},
 					"additionalProperties": false
@@ -130,7 +243,23 @@ var schema = `
 		"kcap": {
 			"type": "object",
 			"properties": {
-				"file":				{"type": "string"}
+				"file":				{"type": "string"},
+				"from":				{"type": "string"},
+				"to":				{"type": "string"},
+				"seq":				{"type": "string"},
+				"recorder": {
+					"type": "object",
+					"properties": {
+						"enabled":			{"type": "boolean"},
+						"max-age":			{"type": "string", "minLength": 2, "pattern": "[0-9]+(s|m|h)"},
+						"max-size":			{"type": "integer", "minimum": 1},
+						"min-severity":		{"type": "string", "enum": ["low", "medium", "high", "critical"]},
+						"path":				{"type": "string"},
+						"cooldown":			{"type": "string", "minLength": 2, "pattern": "[0-9]+(s|m|h)"},
+						"max-dumps":		{"type": "integer", "minimum": 0}
+					},
+					"additionalProperties": false
+				}
 			},
 			"additionalProperties": false
 		},
@@ -152,7 +281,8 @@ var schema = `
 					"properties": {
 						"enabled": 		{"type": "boolean"},
 						"from-paths": 	{"type": ["array", "null"], "items": [{"type": "string", "minLength": 4}]},
-						"from-urls":	{"type": ["array", "null"], "items": [{"type": "string", "minLength": 8}]}
+						"from-urls":	{"type": ["array", "null"], "items": [{"type": "string", "minLength": 8}]},
+						"watch":		{"type": "boolean"}
 					},
 					"additionalProperties": false
 				},
@@ -228,6 +358,7 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":		{"type": "boolean"},
+								"filter":		{"type": "string", "minLength": 1},
 								"format": 		{"type": "string", "enum": ["json", "pretty"]},
 								"template": 	{"type": "string"},
 								"kv-delimiter": {"type": "string"}
@@ -238,6 +369,7 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
 								"servers": 					{"type": "array", "items": [{"type": "string", "minItems": 1, "format": "uri", "minLength": 1, "maxLength": 255, "pattern": "^(https?|http?)://"}]},
 								"timeout": 					{"type": "string"},
 								"index-name":				{"type": "string", "minLength": 1},
@@ -248,6 +380,7 @@ var schema = `
 								"sniff": 					{"type": "boolean"},
 								"trace-log": 				{"type": "boolean"},
 								"gzip-compression": 		{"type": "boolean"},
+								"serializer": 				{"type": "string", "enum": ["json", "ecs", "ocsf"]},
 								"healthcheck-interval":		{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
 								"healthcheck-timeout":		{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
 								"flush-period":				{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
@@ -264,6 +397,7 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
 								"url": 						{"type": "string", "format": "uri", "minLength": 1, "maxLength": 255, "pattern": "^(amqps?|amqp?)://"},
 								"timeout": 					{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
 								"exchange": 				{"type": "string", "minLength": 1},
@@ -279,7 +413,8 @@ var schema = `
 								"tls-cert": 				{"type": "string"},
 								"tls-ca": 					{"type": "string"},
 								"tls-insecure-skip-verify": {"type": "boolean"},
-								"headers":					{"type": "object", "additionalProperties": true}
+								"headers":					{"type": "object", "additionalProperties": true},
+								"serializer": 				{"type": "string", "enum": ["json", "ecs", "ocsf"]}
 							},
 							"additionalProperties": false
 						},
@@ -287,10 +422,11 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
 								"endpoints": 				{"type": "array", "items": [{"type": "string", "minItems": 1, "format": "uri", "minLength": 1, "maxLength": 255, "pattern": "^(https?|http?)://"}]},
 								"timeout": 					{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
 								"method": 					{"type": "string", "enum": ["POST", "PUT"]},
-								"serializer": 				{"type": "string", "enum": ["json"]},
+								"serializer": 				{"type": "string", "enum": ["json", "ecs", "ocsf"]},
 								"enable-gzip": 				{"type": "boolean"},
 								"proxy-url": 				{"type": "string"},
 								"proxy-username": 			{"type": "string"},
@@ -309,11 +445,125 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
 								"level": 					{"type": "string", "enum": ["INFO", "info", "warn", "warning", "WARN", "WARNING", "error", "erro", "ERROR", "ERRO"]},
 								"remote-host": 				{"type": "string"},
 								"template": 				{"type": "string"}
 							},
 							"additionalProperties": false
+						},
+						"syslog": {
+							"type": "object",
+							"properties": {
+								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
+								"network": 					{"type": "string", "enum": ["udp", "tcp", "tls"]},
+								"address": 					{"type": "string", "minLength": 3},
+								"format": 					{"type": "string", "enum": ["rfc5424", "rfc3164"]},
+								"framing": 					{"type": "string", "enum": ["octet-counting", "non-transparent"]},
+								"facility": 				{"type": "string", "enum": ["kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp", "ntp", "security", "console", "local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7"]},
+								"severity": 				{"type": "string", "enum": ["emerg", "alert", "crit", "err", "warning", "notice", "info", "debug"]},
+								"app-name": 				{"type": "string"},
+								"hostname": 				{"type": "string"},
+								"timeout": 					{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
+								"max-backoff": 				{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
+								"serializer": 				{"type": "string", "enum": ["json", "cef", "leef"]},
+								"fields":					{"type": "object", "additionalProperties": {"type": "string", "minLength": 1}},
+								"tls-key": 					{"type": "string"},
+								"tls-cert": 				{"type": "string"},
+								"tls-ca": 					{"type": "string"},
+								"tls-insecure-skip-verify": {"type": "boolean"}
+							},
+							"additionalProperties": false
+						},
+						"kafka": {
+							"type": "object",
+							"properties": {
+								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
+								"brokers": 					{"type": "array", "items": [{"type": "string", "minItems": 1, "minLength": 3}]},
+								"topic": 					{"type": "string", "minLength": 1},
+								"topics":					{"type": "object", "additionalProperties": {"type": "string", "minLength": 1}},
+								"partition-key": 			{"type": "string"},
+								"client-id": 				{"type": "string"},
+								"version": 					{"type": "string", "pattern": "^[0-9]+\\.[0-9]+\\.[0-9]+"},
+								"compression": 				{"type": "string", "enum": ["none", "gzip", "snappy", "lz4", "zstd"]},
+								"required-acks": 			{"type": "string", "enum": ["none", "leader", "all"]},
+								"idempotent": 				{"type": "boolean"},
+								"timeout": 					{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
+								"retry-max": 				{"type": "integer", "minimum": 0},
+								"max-message-bytes": 		{"type": "integer", "minimum": 1},
+								"enable-tls": 				{"type": "boolean"},
+								"sasl-mechanism": 			{"type": "string", "enum": ["", "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512"]},
+								"sasl-username": 			{"type": "string"},
+								"sasl-password": 			{"type": "string"},
+								"tls-key": 					{"type": "string"},
+								"tls-cert": 				{"type": "string"},
+								"tls-ca": 					{"type": "string"},
+								"tls-insecure-skip-verify": {"type": "boolean"}
+							},
+							"additionalProperties": false
+						},
+						"otlp": {
+							"type": "object",
+							"properties": {
+								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
+								"endpoint": 				{"type": "string", "pattern": "^https?://"},
+								"protocol": 				{"type": "string", "enum": ["grpc", "http/protobuf", "http/json"]},
+								"headers":					{"type": "object", "additionalProperties": {"type": "string"}},
+								"timeout": 					{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
+								"enable-gzip": 				{"type": "boolean"},
+								"service-name": 			{"type": "string", "minLength": 1},
+								"resource-attributes":		{"type": "object", "additionalProperties": {"type": "string"}},
+								"tls-key": 					{"type": "string"},
+								"tls-cert": 				{"type": "string"},
+								"tls-ca": 					{"type": "string"},
+								"tls-insecure-skip-verify": {"type": "boolean"}
+							},
+							"additionalProperties": false
+						},
+						"file": {
+							"type": "object",
+							"properties": {
+								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
+								"path": 					{"type": "string", "minLength": 1},
+								"serializer": 				{"type": "string", "enum": ["json", "ecs", "ocsf"]},
+								"max-size": 				{"type": "integer", "minimum": 0},
+								"max-age": 					{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m|h}"},
+								"compression": 				{"type": "string", "enum": ["none", "gzip", "zstd"]},
+								"max-backups": 				{"type": "integer", "minimum": 0},
+								"max-total-size": 			{"type": "integer", "minimum": 0},
+								"fsync": 					{"type": "string", "enum": ["never", "batch", "interval"]},
+								"fsync-interval": 			{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m}"}
+							},
+							"additionalProperties": false
+						},
+						"splunk": {
+							"type": "object",
+							"properties": {
+								"enabled":					{"type": "boolean"},
+								"filter":					{"type": "string", "minLength": 1},
+								"endpoints": 				{"type": "array", "items": [{"type": "string", "minItems": 1, "format": "uri", "minLength": 1, "maxLength": 255, "pattern": "^(https?|http?)://"}]},
+								"token": 					{"type": "string"},
+								"index": 					{"type": "string"},
+								"source": 					{"type": "string"},
+								"sourcetype": 				{"type": "string"},
+								"host": 					{"type": "string"},
+								"serializer": 				{"type": "string", "enum": ["json", "ecs", "ocsf"]},
+								"timeout": 					{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
+								"enable-gzip": 				{"type": "boolean"},
+								"enable-ack": 				{"type": "boolean"},
+								"channel": 					{"type": "string"},
+								"ack-timeout": 				{"type": "string", "minLength": 2, "pattern": "[0-9]+s|m}"},
+								"ack-poll-interval": 		{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m}"},
+								"tls-key": 					{"type": "string"},
+								"tls-cert": 				{"type": "string"},
+								"tls-ca": 					{"type": "string"},
+								"tls-insecure-skip-verify": {"type": "boolean"}
+							},
+							"additionalProperties": false
 						}
 					},
 					"additionalProperties": false
@@ -335,10 +585,128 @@ var schema = `
 			"type": "object",
 			"anyOf": [{
 					"properties": {
+						"drop": {
+							"type": "object",
+							"properties": {
+								"enabled":  {"type": "boolean"},
+								"when":  	{"type": "string", "minLength": 1},
+								"rules": 	{"type": "array", "items": [
+														{
+															"type": "object",
+															"properties": {
+																"name": 	{"type": "string"},
+																"filter": 	{"type": "string", "minLength": 1}
+															},
+															"required": ["filter"],
+															"additionalProperties": false
+														}
+								]}
+							},
+							"if": {
+								"properties": {"enabled": { "const": true }}
+							},
+							"then": {
+								"properties": {"rules": {"minItems": 1}}
+							},
+							"additionalProperties": false
+						},
+						"sample": {
+							"type": "object",
+							"properties": {
+								"enabled":  {"type": "boolean"},
+								"when":  	{"type": "string", "minLength": 1},
+								"rules": 	{"type": "array", "items": [
+														{
+															"type": "object",
+															"properties": {
+																"name": 	{"type": "string"},
+																"filter": 	{"type": "string", "minLength": 1},
+																"rate": 	{"type": "integer", "minimum": 1},
+																"keys": 	{"type": "array", "items": [{"type": "string", "minLength": 1}]}
+															},
+															"required": ["rate"],
+															"additionalProperties": false
+														}
+								]}
+							},
+							"if": {
+								"properties": {"enabled": { "const": true }}
+							},
+							"then": {
+								"properties": {"rules": {"minItems": 1}}
+							},
+							"additionalProperties": false
+						},
+						"enrich": {
+							"type": "object",
+							"properties": {
+								"enabled":  		{"type": "boolean"},
+								"when":  			{"type": "string", "minLength": 1},
+								"reload-debounce": 	{"type": "string", "minLength": 2, "pattern": "[0-9]+ms|s|m}"},
+								"tables": 			{"type": "array", "items": [
+														{
+															"type": "object",
+															"properties": {
+																"name": 			{"type": "string"},
+																"path": 			{"type": "string", "minLength": 1},
+																"format": 			{"type": "string", "enum": ["csv", "json"]},
+																"field": 			{"type": "string", "minLength": 1},
+																"key": 				{"type": "string"},
+																"match": 			{"type": "string", "enum": ["exact", "glob", "cidr"]},
+																"columns": 			{"type": "array", "items": [{"type": "string", "minLength": 1}]},
+																"target": 			{"type": "string", "enum": ["metadata", "params"]},
+																"prefix": 			{"type": "string"},
+																"case-sensitive": 	{"type": "boolean"}
+															},
+															"required": ["path", "field"],
+															"additionalProperties": false
+														}
+								]}
+							},
+							"if": {
+								"properties": {"enabled": { "const": true }}
+							},
+							"then": {
+								"properties": {"tables": {"minItems": 1}}
+							},
+							"additionalProperties": false
+						},
+						"redact": {
+							"type": "object",
+							"properties": {
+								"enabled":  					{"type": "boolean"},
+								"when":  						{"type": "string", "minLength": 1},
+								"fields": 						{"type": "array", "items": [{"type": "string", "minLength": 1}]},
+								"mask": 						{"type": "string"},
+								"disable-builtin-patterns": 	{"type": "boolean"},
+								"patterns": 					{"type": "array", "items": [
+																	{
+																		"type": "object",
+																		"properties": {
+																			"name": {"type": "string"},
+																			"expr": {"type": "string", "minLength": 1}
+																		},
+																		"required": ["expr"],
+																		"additionalProperties": false
+																	}
+								]},
+								"pseudonymization": 			{
+																	"type": "object",
+																	"properties": {
+																		"key": 				{"type": "string", "minLength": 1},
+																		"fields": 			{"type": "array", "items": [{"type": "string", "minLength": 1}]},
+																		"profile-paths": 	{"type": "boolean"}
+																	},
+																	"additionalProperties": false
+								}
+							},
+							"additionalProperties": false
+						},
 						"remove": {
 							"type": "object",
 							"properties": {
 								"enabled":  {"type": "boolean"},
+								"when":  	{"type": "string", "minLength": 1},
 								"kparams": 	{"type": "array", "items": [{"type": "string"}]}
 							},
 							"if": {
@@ -353,6 +721,7 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":  {"type": "boolean"},
+								"when":  	{"type": "string", "minLength": 1},
 								"kparams": 	{"type": "array", "items": [
 														{
 															"type": "object",
@@ -376,6 +745,7 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":  		{"type": "boolean"},
+								"when":  	{"type": "string", "minLength": 1},
 								"replacements": 	{"type": "array", "items": [
 														{
 															"type": "object",
@@ -400,6 +770,7 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":  {"type": "boolean"},
+								"when":  	{"type": "string", "minLength": 1},
 								"tags": 	{"type": "array", "items": [
 														{
 															"type": "object",
@@ -423,6 +794,7 @@ var schema = `
 							"type": "object",
 							"properties": {
 								"enabled":  		{"type": "boolean"},
+								"when":  	{"type": "string", "minLength": 1},
 								"prefixes": 		{"type": "array", "items": [
 														{
 															"type": "object",
@@ -524,6 +896,43 @@ var rulesSchema = `
 		},
 		"tags":						{"type": "array", "items": [{"type": "string", "minLength": 1}]},
 		"references":			{"type": "array", "items": [{"type": "string", "minLength": 1}]},
+		"suppress": 			{
+			"type": "object",
+			"properties": {
+				"fields":				{"type": "array", "items": {"type": "string", "minLength": 1}},
+				"window":				{"type": "string", "minLength": 2, "pattern": "^([0-9]+(ms|s|m|h))+$"},
+				"max-alerts":		{"type": "integer", "minimum": 1}
+			},
+			"required": ["window"],
+			"additionalProperties": false
+		},
+		"tests":					{
+			"type": "array",
+			"items": {
+				"type": "object",
+				"properties": {
+					"name":					{"type": "string", "minLength": 1},
+					"match":				{"type": "boolean"},
+					"events":				{
+						"type": "array",
+						"minItems": 1,
+						"items": {
+							"type": "object",
+							"properties": {
+								"name":				{"type": "string", "minLength": 3},
+								"at":					{"type": "string", "pattern": "^([0-9]+(ms|s|m|h))+$"},
+								"params":			{"type": "object"},
+								"ps":					{"$ref": "#/definitions/testProcess"}
+							},
+							"required": ["name"],
+							"additionalProperties": false
+						}
+					}
+				},
+				"required": ["name", "match", "events"],
+				"additionalProperties": false
+			}
+		},
 		"action": 				{
 			"type": "array",
 			"items": {
@@ -552,7 +961,29 @@ var rulesSchema = `
 		}
 	},
 	"required": ["id", "version", "name", "condition", "min-engine-version"],
-	"additionalProperties": false
+	"additionalProperties": false,
+	"definitions": {
+		"testProcess": {
+			"type": "object",
+			"properties": {
+				"pid":					{"type": "integer", "minimum": 0},
+				"ppid":					{"type": "integer", "minimum": 0},
+				"name":					{"type": "string"},
+				"exe":					{"type": "string"},
+				"cmdline":			{"type": "string"},
+				"cwd":					{"type": "string"},
+				"args":					{"type": "array", "items": {"type": "string"}},
+				"sid":					{"type": "string"},
+				"username":			{"type": "string"},
+				"domain":				{"type": "string"},
+				"session-id":		{"type": "integer", "minimum": 0},
+				"envs":					{"type": "object", "additionalProperties": {"type": "string"}},
+				"modules":			{"type": "array", "items": {"type": "string"}},
+				"parent":				{"$ref": "#/definitions/testProcess"}
+			},
+			"additionalProperties": false
+		}
+	}
 }
 `
 
@@ -21,12 +21,17 @@ package config
 import (
 	"fmt"
 	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers"
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/drop"
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/enrich"
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/redact"
 	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/remove"
 	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/rename"
 	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/replace"
+	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/sample"
 	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/tags"
 	"github.com/rabbitstack/fibratus/pkg/aggregator/transformers/trim"
 	"reflect"
+	"sort"
 )
 
 var errTransformerConfig = func(t string, err error) error { return fmt.Errorf("%s transformer invalid config: %v", t, err) }
@@ -44,7 +49,68 @@ func (c *Config) tryLoadTransformers() error {
 	configs := make([]transformers.Config, 0)
 
 	for typ, config := range mapping {
+		when := transformerWhen(config)
 		switch typ {
+		case "drop":
+			var dropConfig drop.Config
+			if err := decode(config, &dropConfig); err != nil {
+				return errTransformerConfig(typ, err)
+			}
+			if !dropConfig.Enabled {
+				continue
+			}
+			config := transformers.Config{
+				Type:        transformers.Drop,
+				Transformer: dropConfig,
+				When:        when,
+			}
+			configs = append(configs, config)
+
+		case "sample":
+			var sampleConfig sample.Config
+			if err := decode(config, &sampleConfig); err != nil {
+				return errTransformerConfig(typ, err)
+			}
+			if !sampleConfig.Enabled {
+				continue
+			}
+			config := transformers.Config{
+				Type:        transformers.Sample,
+				Transformer: sampleConfig,
+				When:        when,
+			}
+			configs = append(configs, config)
+
+		case "enrich":
+			var enrichConfig enrich.Config
+			if err := decode(config, &enrichConfig); err != nil {
+				return errTransformerConfig(typ, err)
+			}
+			if !enrichConfig.Enabled {
+				continue
+			}
+			config := transformers.Config{
+				Type:        transformers.Enrich,
+				Transformer: enrichConfig,
+				When:        when,
+			}
+			configs = append(configs, config)
+
+		case "redact":
+			var redactConfig redact.Config
+			if err := decode(config, &redactConfig); err != nil {
+				return errTransformerConfig(typ, err)
+			}
+			if !redactConfig.Enabled {
+				continue
+			}
+			config := transformers.Config{
+				Type:        transformers.Redact,
+				Transformer: redactConfig,
+				When:        when,
+			}
+			configs = append(configs, config)
+
 		case "remove":
 			var removeConfig remove.Config
 			if err := decode(config, &removeConfig); err != nil {
@@ -56,6 +122,7 @@ func (c *Config) tryLoadTransformers() error {
 			config := transformers.Config{
 				Type:        transformers.Remove,
 				Transformer: removeConfig,
+				When:        when,
 			}
 			configs = append(configs, config)
 
@@ -70,6 +137,7 @@ func (c *Config) tryLoadTransformers() error {
 			config := transformers.Config{
 				Type:        transformers.Rename,
 				Transformer: renameConfig,
+				When:        when,
 			}
 			configs = append(configs, config)
 
@@ -84,6 +152,7 @@ func (c *Config) tryLoadTransformers() error {
 			config := transformers.Config{
 				Type:        transformers.Replace,
 				Transformer: replaceConfig,
+				When:        when,
 			}
 			configs = append(configs, config)
 
@@ -98,6 +167,7 @@ func (c *Config) tryLoadTransformers() error {
 			config := transformers.Config{
 				Type:        transformers.Trim,
 				Transformer: trimConfig,
+				When:        when,
 			}
 			configs = append(configs, config)
 
@@ -112,12 +182,26 @@ func (c *Config) tryLoadTransformers() error {
 			config := transformers.Config{
 				Type:        transformers.Tags,
 				Transformer: tagsConfig,
+				When:        when,
 			}
 			configs = append(configs, config)
 		}
 	}
 
+	// sort transformers to guarantee stable ordering regardless of map iteration
+	sort.Slice(configs, func(i, j int) bool { return configs[i].Type < configs[j].Type })
+
 	c.Transformers = configs
 
 	return nil
 }
+
+// transformerWhen returns the when filter expression shared by all transformer configurations.
+func transformerWhen(config interface{}) string {
+	m, ok := config.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	expr, _ := m["when"].(string)
+	return expr
+}
@@ -22,10 +22,12 @@ import (
 	"errors"
 	"expvar"
 	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/config"
 	kerrors "github.com/rabbitstack/fibratus/pkg/errors"
 	"github.com/rabbitstack/fibratus/pkg/filter/fields"
 	"github.com/rabbitstack/fibratus/pkg/filter/ql"
 	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/ps"
 	"github.com/rabbitstack/fibratus/pkg/util/bytes"
 	"github.com/rabbitstack/fibratus/pkg/util/hashers"
 	"net"
@@ -54,6 +56,11 @@ type Filter interface {
 	// on the state machine transitions and partial matches to decide whether the
 	// rule is fired.
 	RunSequence(evt *kevent.Kevent, seqID int, partials map[int][]*kevent.Kevent, rawMatch bool) bool
+	// RunThreshold runs a filter with the threshold expression. If the event matches
+	// the expression, the value of the field by which the matches are grouped is
+	// returned along with the match result. The engine is responsible for counting
+	// the matches within the sliding window.
+	RunThreshold(evt *kevent.Kevent) (bool, any)
 	// GetStringFields returns field names mapped to their string values.
 	GetStringFields() map[fields.Field][]string
 	// GetFields returns all fields used in the filter expression.
@@ -62,6 +69,10 @@ type Filter interface {
 	GetSequence() *ql.Sequence
 	// IsSequence determines if this filter is a sequence.
 	IsSequence() bool
+	// GetThreshold returns the threshold descriptor or nil if this filter is not a threshold.
+	GetThreshold() *ql.Threshold
+	// IsThreshold determines if this filter is a threshold.
+	IsThreshold() bool
 }
 
 // Field contains field meta attributes all accessors need to extract the value.
@@ -81,6 +92,7 @@ type BoundField struct {
 type filter struct {
 	expr        ql.Expr
 	seq         *ql.Sequence
+	thresh      *ql.Threshold
 	parser      *ql.Parser
 	accessors   []Accessor
 	fields      []Field
@@ -105,6 +117,8 @@ func (f *filter) Compile() error {
 	var err error
 	if f.parser.IsSequence() {
 		f.seq, err = f.parser.ParseSequence()
+	} else if f.parser.IsThreshold() {
+		f.thresh, err = f.parser.ParseThreshold()
 	} else {
 		f.expr, err = f.parser.ParseExpr()
 	}
@@ -161,6 +175,11 @@ func (f *filter) Compile() error {
 
 	if f.expr != nil {
 		ql.WalkFunc(f.expr, walk)
+	} else if f.thresh != nil {
+		ql.WalkFunc(f.thresh.Expr, walk)
+		if f.thresh.By != nil {
+			f.addField(f.thresh.By)
+		}
 	} else {
 		if f.seq.By != nil {
 			f.addField(f.seq.By)
@@ -309,6 +328,11 @@ func (f *filter) RunSequence(e *kevent.Kevent, seqID int, partials map[int][]*ke
 			joinID := valuer[by.Value]
 		outer:
 			for i := 0; i < seqID; i++ {
+				// negated expressions never store partials
+				if f.seq.Expressions[i].Negated {
+					joins[i] = true
+					continue
+				}
 				for _, p := range partials[i] {
 					if CompareSeqLink(joinID, p.SequenceLink()) {
 						joins[i] = true
@@ -330,6 +354,25 @@ func (f *filter) RunSequence(e *kevent.Kevent, seqID int, partials map[int][]*ke
 	return match
 }
 
+func (f *filter) RunThreshold(e *kevent.Kevent) (bool, any) {
+	if f.thresh == nil {
+		return false, nil
+	}
+	valuer := f.mapValuer(e)
+	if !ql.Eval(f.thresh.Expr, valuer, f.hasFunctions) {
+		return false, nil
+	}
+	if f.thresh.By == nil {
+		return true, nil
+	}
+	v := valuer[f.thresh.By.Value]
+	if v == nil {
+		// the event can't be grouped
+		return false, nil
+	}
+	return true, v
+}
+
 func joinsEqual(joins []bool) bool {
 	for _, j := range joins {
 		if !j {
@@ -342,8 +385,10 @@ func joinsEqual(joins []bool) bool {
 func (f *filter) GetStringFields() map[fields.Field][]string { return f.stringFields }
 func (f *filter) GetFields() []Field                         { return f.fields }
 
-func (f *filter) IsSequence() bool          { return f.seq != nil }
-func (f *filter) GetSequence() *ql.Sequence { return f.seq }
+func (f *filter) IsSequence() bool            { return f.seq != nil }
+func (f *filter) GetSequence() *ql.Sequence   { return f.seq }
+func (f *filter) IsThreshold() bool           { return f.thresh != nil }
+func (f *filter) GetThreshold() *ql.Threshold { return f.thresh }
 
 // InterpolateFields replaces all occurrences of field modifiers in the given string
 // with values extracted from the event. Field modifiers may contain a leading ordinal
@@ -568,3 +613,116 @@ func CompareSeqLink(s1, s2 any) bool {
 	}
 	return false
 }
+
+type opts struct {
+	psnap ps.Snapshotter
+}
+
+// Option defines the option supplied to the filter
+type Option func(o *opts)
+
+// WithPSnapshotter passes a process snapshotter reference to the filter.
+func WithPSnapshotter(psnap ps.Snapshotter) Option {
+	return func(o *opts) {
+		o.psnap = psnap
+	}
+}
+
+// New creates a new filter with the specified filter expression. The consumers must ensure
+// the expression is correctly parsed before executing the filter. This is achieved by calling the
+// `Compile` method after constructing the filter.
+func New(expr string, config *config.Config, options ...Option) Filter {
+	var opts opts
+	for _, opt := range options {
+		opt(&opts)
+	}
+	accessors := []Accessor{
+		// general event parameters
+		newKevtAccessor(),
+		// process state and parameters
+		newPSAccessor(opts.psnap),
+		// PE metadata
+		newPEAccessor(),
+	}
+	kconfig := config.Kstream
+	fconfig := config.Filters
+
+	if kconfig.EnableThreadKevents {
+		accessors = append(accessors, newThreadAccessor())
+	}
+	if kconfig.EnableImageKevents {
+		accessors = append(accessors, newImageAccessor())
+	}
+	if kconfig.EnableFileIOKevents {
+		accessors = append(accessors, newFileAccessor())
+	}
+	if kconfig.EnableRegistryKevents {
+		accessors = append(accessors, newRegistryAccessor())
+	}
+	if kconfig.EnableNetKevents {
+		accessors = append(accessors, newNetworkAccessor())
+	}
+	if kconfig.EnableHandleKevents {
+		accessors = append(accessors, newHandleAccessor())
+	}
+	if kconfig.EnableMemKevents {
+		accessors = append(accessors, newMemAccessor())
+	}
+	if kconfig.EnableDNSEvents {
+		accessors = append(accessors, newDNSAccessor())
+	}
+	if kconfig.EnableThreadpoolEvents {
+		accessors = append(accessors, newThreadpoolAccessor())
+	}
+
+	var parser *ql.Parser
+	if fconfig.HasMacros() {
+		parser = ql.NewParserWithConfig(expr, fconfig)
+	} else {
+		parser = ql.NewParser(expr)
+	}
+
+	return &filter{
+		parser:         parser,
+		accessors:      accessors,
+		fields:         make([]Field, 0),
+		segments:       make([]fields.Segment, 0),
+		stringFields:   make(map[fields.Field][]string),
+		boundFields:    make([]*ql.BoundFieldLiteral, 0),
+		seqBoundFields: make(map[int][]BoundField),
+	}
+}
+
+// NewFromCLI builds and compiles a filter by joining all the command line arguments into the filter expression.
+func NewFromCLI(args []string, config *config.Config) (Filter, error) {
+	expr := strings.Join(args, " ")
+	if expr == "" {
+		return nil, nil
+	}
+	filter := New(expr, config)
+	if err := filter.Compile(); err != nil {
+		return nil, fmt.Errorf("bad filter:\n%v", err)
+	}
+	return filter, nil
+}
+
+// NewFromCLIWithAllAccessors builds and compiles a filter with all field accessors enabled.
+func NewFromCLIWithAllAccessors(args []string) (Filter, error) {
+	expr := strings.Join(args, " ")
+	if expr == "" {
+		return nil, nil
+	}
+	filter := &filter{
+		parser:         ql.NewParser(expr),
+		accessors:      GetAccessors(),
+		fields:         make([]Field, 0),
+		segments:       make([]fields.Segment, 0),
+		stringFields:   make(map[fields.Field][]string),
+		boundFields:    make([]*ql.BoundFieldLiteral, 0),
+		seqBoundFields: make(map[int][]BoundField),
+	}
+	if err := filter.Compile(); err != nil {
+		return nil, fmt.Errorf("bad filter:\n %v", err)
+	}
+	return filter, nil
+}
@@ -24,10 +24,8 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/filter/fields"
 	"github.com/rabbitstack/fibratus/pkg/pe"
 	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
-	"github.com/rabbitstack/fibratus/pkg/util/signature"
-	"golang.org/x/sys/windows"
+	"github.com/rabbitstack/fibratus/pkg/util/winpath"
 	"maps"
-	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
@@ -244,40 +242,7 @@ func (f *Foreach) Call(args []interface{}) (interface{}, bool) {
 			}
 		}
 	case callstack.Callstack:
-		var pid uint32
-		var proc windows.Handle
-		var err error
-
-		if !elems.IsEmpty() {
-			pid = elems.FrameAt(0).PID
-		}
-
-		// open process handle with required access mask
-		var desiredAccess uint32
-	loop:
-		for _, seg := range segments {
-			switch seg.Segment {
-			case fields.CallsiteLeadingAssemblySegment, fields.CallsiteTrailingAssemblySegment:
-				// break on broader access rights
-				desiredAccess = windows.PROCESS_QUERY_INFORMATION | windows.PROCESS_VM_READ
-				break loop
-			case fields.AllocationSizeSegment, fields.ProtectionSegment:
-				desiredAccess = windows.PROCESS_QUERY_INFORMATION
-			}
-		}
-		if desiredAccess != 0 {
-			proc, err = windows.OpenProcess(desiredAccess, false, pid)
-			if err != nil {
-				return false, false
-			}
-			defer windows.Close(proc)
-		}
-
-		for _, frame := range elems {
-			if f.evalExpr(e, useCallValuer, f.callstackMapValuer(segments, frame, proc), valuer) {
-				return true, true
-			}
-		}
+		return f.evalCallstack(e, useCallValuer, elems, segments, valuer)
 	}
 
 	return false, false
@@ -449,7 +414,7 @@ func (f *Foreach) moduleMapValuer(segments []*BoundSegmentLiteral, mod pstypes.M
 		case fields.PathSegment:
 			valuer[key] = mod.Name
 		case fields.NameSegment:
-			valuer[key] = filepath.Base(mod.Name)
+			valuer[key] = winpath.Base(mod.Name)
 		case fields.AddressSegment:
 			valuer[key] = mod.BaseAddress.String()
 		case fields.SizeSegment:
@@ -536,8 +501,9 @@ func (f *Foreach) mmapMapValuer(segments []*BoundSegmentLiteral, mmap pstypes.Mm
 	return valuer
 }
 
-// callstackMapValuer returns map valuer with thread stack frame data.
-func (f *Foreach) callstackMapValuer(segments []*BoundSegmentLiteral, frame callstack.Frame, proc windows.Handle) MapValuer {
+// callstackMapValuer returns map valuer with thread stack frame data
+// that is resolved without accessing the process address space.
+func (f *Foreach) callstackMapValuer(segments []*BoundSegmentLiteral, frame callstack.Frame) MapValuer {
 	var valuer = MapValuer{}
 	for _, seg := range segments {
 		key := seg.Value
@@ -552,65 +518,6 @@ func (f *Foreach) callstackMapValuer(segments []*BoundSegmentLiteral, frame call
 			valuer[key] = frame.Module
 		case fields.SymbolSegment:
 			valuer[key] = frame.Module + "!" + frame.Symbol
-		case fields.AllocationSizeSegment:
-			valuer[key] = frame.AllocationSize(proc)
-		case fields.ProtectionSegment:
-			valuer[key] = frame.Protection(proc)
-		case fields.CallsiteTrailingAssemblySegment:
-			valuer[key] = frame.CallsiteAssembly(proc, false)
-		case fields.CallsiteLeadingAssemblySegment:
-			valuer[key] = frame.CallsiteAssembly(proc, true)
-		case fields.ModuleSignatureIsSignedSegment, fields.ModuleSignatureIsTrustedSegment,
-			fields.ModuleSignatureCertIssuerSegment, fields.ModuleSignatureCertSubjectSegment:
-
-			if frame.ModuleAddress.IsZero() {
-				continue
-			}
-
-			segment := seg.Segment
-			sign := signature.GetSignatures().GetSignature(frame.ModuleAddress.Uint64())
-			if sign == nil && frame.Module != "" {
-				// register signature if not present in the cache
-				var err error
-				sign = &signature.Signature{Filename: frame.Module}
-				sign.Type, sign.Level, err = sign.Check()
-				if err != nil {
-					continue
-				}
-
-				if sign.IsSigned() {
-					sign.Verify()
-				}
-
-				if segment == fields.ModuleSignatureCertIssuerSegment || segment == fields.ModuleSignatureCertSubjectSegment {
-					if err := sign.ParseCertificate(); err != nil {
-						continue
-					}
-				}
-
-				signature.GetSignatures().PutSignature(frame.ModuleAddress.Uint64(), sign)
-			}
-
-			switch segment {
-			case fields.ModuleSignatureIsSignedSegment:
-				valuer[key] = sign.IsSigned()
-			case fields.ModuleSignatureIsTrustedSegment:
-				valuer[key] = sign.IsTrusted()
-			case fields.ModuleSignatureCertIssuerSegment:
-				if err := sign.ParseCertificate(); err != nil {
-					continue
-				}
-				if sign.HasCertificate() {
-					valuer[key] = sign.Cert.Issuer
-				}
-			case fields.ModuleSignatureCertSubjectSegment:
-				if err := sign.ParseCertificate(); err != nil {
-					continue
-				}
-				if sign.HasCertificate() {
-					valuer[key] = sign.Cert.Subject
-				}
-			}
 		}
 	}
 	return valuer
@@ -0,0 +1,36 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import "github.com/rabbitstack/fibratus/pkg/callstack"
+
+// evalCallstack evaluates the predicate for each stack frame. Outside
+// Windows there is no live process to read from, so the segments such
+// as allocation size, protection, callsite assembly or module signature
+// are never resolved.
+func (f *Foreach) evalCallstack(e any, useCallValuer bool, elems callstack.Callstack, segments []*BoundSegmentLiteral, valuer MapValuer) (bool, bool) {
+	for _, frame := range elems {
+		if f.evalExpr(e, useCallValuer, f.callstackMapValuer(segments, frame), valuer) {
+			return true, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ql
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/callstack"
+	"github.com/rabbitstack/fibratus/pkg/filter/fields"
+	"github.com/rabbitstack/fibratus/pkg/util/signature"
+	"golang.org/x/sys/windows"
+)
+
+// evalCallstack evaluates the predicate for each stack frame. The process
+// handle is opened only if the predicate references the segments that
+// require reading the process address space.
+func (f *Foreach) evalCallstack(e any, useCallValuer bool, elems callstack.Callstack, segments []*BoundSegmentLiteral, valuer MapValuer) (bool, bool) {
+	var pid uint32
+	var proc windows.Handle
+	var err error
+
+	if !elems.IsEmpty() {
+		pid = elems.FrameAt(0).PID
+	}
+
+	// open process handle with required access mask
+	var desiredAccess uint32
+loop:
+	for _, seg := range segments {
+		switch seg.Segment {
+		case fields.CallsiteLeadingAssemblySegment, fields.CallsiteTrailingAssemblySegment:
+			// break on broader access rights
+			desiredAccess = windows.PROCESS_QUERY_INFORMATION | windows.PROCESS_VM_READ
+			break loop
+		case fields.AllocationSizeSegment, fields.ProtectionSegment:
+			desiredAccess = windows.PROCESS_QUERY_INFORMATION
+		}
+	}
+	if desiredAccess != 0 {
+		proc, err = windows.OpenProcess(desiredAccess, false, pid)
+		if err != nil {
+			return false, false
+		}
+		defer windows.Close(proc)
+	}
+
+	for _, frame := range elems {
+		frameValuer := f.callstackMapValuer(segments, frame)
+		f.callstackProcMapValuer(frameValuer, segments, frame, proc)
+		if f.evalExpr(e, useCallValuer, frameValuer, valuer) {
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// callstackProcMapValuer populates the map valuer with stack frame data
+// that is resolved from the process address space or the module file.
+func (f *Foreach) callstackProcMapValuer(valuer MapValuer, segments []*BoundSegmentLiteral, frame callstack.Frame, proc windows.Handle) {
+	for _, seg := range segments {
+		key := seg.Value
+		switch seg.Segment {
+		case fields.AllocationSizeSegment:
+			valuer[key] = frame.AllocationSize(proc)
+		case fields.ProtectionSegment:
+			valuer[key] = frame.Protection(proc)
+		case fields.CallsiteTrailingAssemblySegment:
+			valuer[key] = frame.CallsiteAssembly(proc, false)
+		case fields.CallsiteLeadingAssemblySegment:
+			valuer[key] = frame.CallsiteAssembly(proc, true)
+		case fields.ModuleSignatureIsSignedSegment, fields.ModuleSignatureIsTrustedSegment,
+			fields.ModuleSignatureCertIssuerSegment, fields.ModuleSignatureCertSubjectSegment:
+
+			if frame.ModuleAddress.IsZero() {
+				continue
+			}
+
+			segment := seg.Segment
+			sign := signature.GetSignatures().GetSignature(frame.ModuleAddress.Uint64())
+			if sign == nil && frame.Module != "" {
+				// register signature if not present in the cache
+				var err error
+				sign = &signature.Signature{Filename: frame.Module}
+				sign.Type, sign.Level, err = sign.Check()
+				if err != nil {
+					continue
+				}
+
+				if sign.IsSigned() {
+					sign.Verify()
+				}
+
+				if segment == fields.ModuleSignatureCertIssuerSegment || segment == fields.ModuleSignatureCertSubjectSegment {
+					if err := sign.ParseCertificate(); err != nil {
+						continue
+					}
+				}
+
+				signature.GetSignatures().PutSignature(frame.ModuleAddress.Uint64(), sign)
+			}
+
+			switch segment {
+			case fields.ModuleSignatureIsSignedSegment:
+				valuer[key] = sign.IsSigned()
+			case fields.ModuleSignatureIsTrustedSegment:
+				valuer[key] = sign.IsTrusted()
+			case fields.ModuleSignatureCertIssuerSegment:
+				if err := sign.ParseCertificate(); err != nil {
+					continue
+				}
+				if sign.HasCertificate() {
+					valuer[key] = sign.Cert.Issuer
+				}
+			case fields.ModuleSignatureCertSubjectSegment:
+				if err := sign.ParseCertificate(); err != nil {
+					continue
+				}
+				if sign.HasCertificate() {
+					valuer[key] = sign.Cert.Subject
+				}
+			}
+		}
+	}
+}
@@ -19,7 +19,7 @@
 package functions
 
 import (
-	"path/filepath"
+	"github.com/rabbitstack/fibratus/pkg/util/winpath"
 	"strings"
 )
 
@@ -32,11 +32,11 @@ func (f Base) Call(args []interface{}) (interface{}, bool) {
 	}
 	switch s := args[0].(type) {
 	case string:
-		return f.trimExt(filepath.Base(s), args), true
+		return f.trimExt(winpath.Base(s), args), true
 	case []string:
 		paths := make([]string, len(s))
 		for i, path := range s {
-			paths[i] = f.trimExt(filepath.Base(path), args)
+			paths[i] = f.trimExt(winpath.Base(path), args)
 		}
 		return paths, true
 	}
@@ -18,7 +18,7 @@
 
 package functions
 
-import "path/filepath"
+import "github.com/rabbitstack/fibratus/pkg/util/winpath"
 
 // Dir returns all but the last element of the path, typically the path's directory.
 type Dir struct{}
@@ -29,11 +29,11 @@ func (f Dir) Call(args []interface{}) (interface{}, bool) {
 	}
 	switch s := args[0].(type) {
 	case string:
-		return filepath.Dir(s), true
+		return winpath.Dir(s), true
 	case []string:
 		dirs := make([]string, len(s))
 		for i, path := range s {
-			dirs[i] = filepath.Dir(path)
+			dirs[i] = winpath.Dir(path)
 		}
 		return dirs, true
 	}
@@ -18,7 +18,7 @@
 
 package functions
 
-import "path/filepath"
+import "github.com/rabbitstack/fibratus/pkg/util/winpath"
 
 // Ext returns the file name extension used by the path.
 type Ext struct{}
@@ -28,7 +28,7 @@ func (f Ext) Call(args []interface{}) (interface{}, bool) {
 		return false, false
 	}
 	path := parseString(0, args)
-	ext := filepath.Ext(path)
+	ext := winpath.Ext(path)
 	if len(args) > 1 {
 		dot, ok := args[1].(bool)
 		if !ok {
@@ -18,7 +18,7 @@
 
 package functions
 
-import "path/filepath"
+import "github.com/rabbitstack/fibratus/pkg/util/winpath"
 
 // IsAbs reports whether the path is absolute.
 type IsAbs struct{}
@@ -28,7 +28,7 @@ func (f IsAbs) Call(args []interface{}) (interface{}, bool) {
 		return false, false
 	}
 	path := parseString(0, args)
-	return filepath.IsAbs(path), true
+	return winpath.IsAbs(path), true
 }
 
 func (f IsAbs) Desc() FunctionDesc {
@@ -18,7 +18,7 @@
 
 package functions
 
-import "path/filepath"
+import "github.com/rabbitstack/fibratus/pkg/util/winpath"
 
 // Volume returns leading volume name.
 type Volume struct{}
@@ -28,7 +28,7 @@ func (f Volume) Call(args []interface{}) (interface{}, bool) {
 		return false, false
 	}
 	path := parseString(0, args)
-	return filepath.VolumeName(path), true
+	return winpath.VolumeName(path), true
 }
 
 func (f Volume) Desc() FunctionDesc {
@@ -87,6 +87,7 @@ func (s *scanner) scan() (tok token, pos int, lit string) {
 			return Neq, pos, ""
 		}
 		s.r.unread()
+		return Bang, pos, ""
 	case '>':
 		if ch1, _ := s.r.read(); ch1 == '=' {
 			return Gte, pos, ""
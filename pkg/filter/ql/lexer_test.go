@@ -45,7 +45,7 @@ func TestScanner(t *testing.T) {
 		{s: `=`, tok: Eq},
 		{s: `~=`, tok: IEq},
 		{s: `<>`, tok: Neq},
-		{s: `! `, tok: Illegal, lit: "!"},
+		{s: `! `, tok: Bang},
 		{s: `<`, tok: Lt},
 		{s: `<=`, tok: Lte},
 		{s: `>`, tok: Gt},
@@ -23,7 +23,6 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/kevent"
 	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
 	"github.com/rabbitstack/fibratus/pkg/util/hashers"
-	"golang.org/x/sys/windows"
 	"net"
 	"reflect"
 	"strconv"
@@ -280,6 +279,9 @@ type SequenceExpr struct {
 	BoundFields []*BoundFieldLiteral
 	// Alias represents the sequence expression alias.
 	Alias string
+	// Negated indicates the expression must not match
+	// within the max span after upstream expressions match.
+	Negated bool
 
 	buckets map[uint32]bool
 	ktypes  []ktypes.Ktype
@@ -382,7 +384,7 @@ func (s *Sequence) init() {
 	// The edge case is for unordered events emitted
 	// by the same provider where the temporal order
 	// is guaranteed
-	guids := make(map[windows.GUID]bool)
+	guids := make(map[ktypes.GUID]bool)
 	for _, expr := range s.Expressions {
 		for _, k := range expr.ktypes {
 			if k.CanArriveOutOfOrder() {
@@ -419,3 +421,27 @@ func (s Sequence) incompatibleConstraints() bool {
 	}
 	return false
 }
+
+// HasNegated determines if any of the sequence expressions is negated.
+func (s Sequence) HasNegated() bool {
+	for _, expr := range s.Expressions {
+		if expr.Negated {
+			return true
+		}
+	}
+	return false
+}
+
+// Threshold represents the expression that is required to
+// match a number of times within the sliding time window.
+// Matches are optionally grouped by the field value.
+type Threshold struct {
+	// Count is the number of matches required to fire the rule.
+	Count int
+	// Within is the sliding time window in which the matches are counted.
+	Within time.Duration
+	// By contains the field literal if the matches are grouped by field value.
+	By *FieldLiteral
+	// Expr is the expression evaluated against each event.
+	Expr Expr
+}
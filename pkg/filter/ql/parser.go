@@ -105,6 +105,12 @@ func (p *Parser) ParseSequence() (*Sequence, error) {
 			if seq.incompatibleConstraints() {
 				return nil, fmt.Errorf("%s: sequence mixes global and per-expression 'by' statements", p.expr)
 			}
+			if seq.Expressions[0].Negated {
+				return nil, fmt.Errorf("%s: the first sequence expression can't be negated", p.expr)
+			}
+			if seq.HasNegated() && seq.MaxSpan == 0 {
+				return nil, fmt.Errorf("%s: negated expressions require the 'maxspan' statement", p.expr)
+			}
 
 			seq.init()
 
@@ -112,7 +118,13 @@ func (p *Parser) ParseSequence() (*Sequence, error) {
 		}
 		p.unscan()
 
+		// negated expressions are prefixed with the bang
+		var negated bool
 		tok, posStart, lit := p.scanIgnoreWhitespace()
+		if tok == Bang {
+			negated = true
+			tok, posStart, lit = p.scanIgnoreWhitespace()
+		}
 		if tok != Pipe {
 			return nil, newParseError(tokstr(tok, lit), []string{"|"}, posStart, p.expr)
 		}
@@ -145,18 +157,104 @@ func (p *Parser) ParseSequence() (*Sequence, error) {
 			if tok != Ident {
 				return nil, newParseError(tokstr(tok, lit), []string{"identifier"}, pos, p.expr)
 			}
+			if negated {
+				return nil, fmt.Errorf("%s: negated expressions can't be aliased", p.expr)
+			}
 			seqexpr = SequenceExpr{Expr: expr, Alias: lit}
 		default:
 			seqexpr = SequenceExpr{Expr: expr}
 			p.unscan()
 		}
 
+		seqexpr.Negated = negated
 		seqexpr.init()
 		seqexpr.walk()
 		exprs = append(exprs, seqexpr)
 	}
 }
 
+// ParseThreshold parses the threshold expression with the match count,
+// the sliding time window and the optional grouping field. This method
+// assumes the THRESHOLD token has already been consumed.
+func (p *Parser) ParseThreshold() (*Threshold, error) {
+	thresh := &Threshold{}
+
+	// parse match count
+	tok, pos, lit := p.scanIgnoreWhitespace()
+	if tok != Integer {
+		return nil, newParseError(tokstr(tok, lit), []string{"integer"}, pos, p.expr)
+	}
+	n, err := strconv.Atoi(lit)
+	if err != nil || n < 1 {
+		return nil, newParseError(tokstr(tok, lit), []string{"positive integer"}, pos, p.expr)
+	}
+	const maxCount = 10000
+	if n > maxCount {
+		return nil, fmt.Errorf("threshold count %d cannot be greater than %d", n, maxCount)
+	}
+	thresh.Count = n
+
+	// parse time window
+	tok, pos, lit = p.scanIgnoreWhitespace()
+	if tok != Within {
+		return nil, newParseError(tokstr(tok, lit), []string{"within"}, pos, p.expr)
+	}
+	thresh.Within, err = p.parseDuration()
+	if err != nil {
+		return nil, err
+	}
+	if thresh.Within <= 0 {
+		return nil, fmt.Errorf("threshold time window must be positive")
+	}
+	if thresh.Within > time.Hour*4 {
+		return nil, fmt.Errorf("threshold time window %v cannot be greater than 4h", thresh.Within)
+	}
+
+	// parse optional group field
+	tok, _, _ = p.scanIgnoreWhitespace()
+	if tok == By {
+		tok, pos, lit := p.scanIgnoreWhitespace()
+		if !fields.IsField(lit) {
+			return nil, newParseError(tokstr(tok, lit), []string{"field"}, pos, p.expr)
+		}
+		thresh.By, err = p.parseField(lit)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		p.unscan()
+	}
+
+	// parse the expression
+	tok, pos, lit = p.scanIgnoreWhitespace()
+	if tok != Pipe {
+		return nil, newParseError(tokstr(tok, lit), []string{"|"}, pos, p.expr)
+	}
+	thresh.Expr, err = p.ParseExpr()
+	if err != nil {
+		return nil, err
+	}
+	tok, pos, lit = p.scanIgnoreWhitespace()
+	if tok != Pipe {
+		return nil, newParseError(tokstr(tok, lit), []string{"|"}, pos, p.expr)
+	}
+	if tok, pos, lit := p.scanIgnoreWhitespace(); tok != EOF {
+		return nil, newParseError(tokstr(tok, lit), []string{"EOF"}, pos, p.expr)
+	}
+
+	return thresh, nil
+}
+
+// IsThreshold checks whether the expression given to the parser is a threshold.
+func (p *Parser) IsThreshold() bool {
+	tok, _, _ := p.scanIgnoreWhitespace()
+	if tok == Thresh {
+		return true
+	}
+	p.unscan()
+	return false
+}
+
 // IsSequence checks whether the expression given to the parser is a sequence.
 func (p *Parser) IsSequence() bool {
 	tok, _, _ := p.scanIgnoreWhitespace()
@@ -24,6 +24,7 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/filter/fields"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
 	"time"
 )
@@ -360,6 +361,45 @@ func TestParseSequence(t *testing.T) {
 			time.Minute * 2,
 			true,
 		},
+		{
+
+			`maxspan 1m
+			 |kevt.name = 'CreateFile'| by file.path
+			 !|kevt.name = 'LoadImage'| by image.path
+			`,
+			nil,
+			time.Minute,
+			true,
+		},
+		{
+
+			`|kevt.name = 'CreateFile'|
+			 !|kevt.name = 'LoadImage'|
+			`,
+			errors.New("negated expressions require the 'maxspan' statement"),
+			time.Duration(0),
+			false,
+		},
+		{
+
+			`maxspan 1m
+			 !|kevt.name = 'CreateFile'|
+			 |kevt.name = 'LoadImage'|
+			`,
+			errors.New("the first sequence expression can't be negated"),
+			time.Minute,
+			false,
+		},
+		{
+
+			`maxspan 1m
+			 |kevt.name = 'CreateFile'| as e1
+			 !|kevt.name = 'LoadImage'| as e2
+			`,
+			errors.New("negated expressions can't be aliased"),
+			time.Minute,
+			false,
+		},
 	}
 
 	for i, tt := range tests {
@@ -372,6 +412,9 @@ func TestParseSequence(t *testing.T) {
 		}
 
 		if seq != nil {
+			if strings.Contains(tt.expr, "!|") && !seq.HasNegated() {
+				t.Errorf("%d. exp=%s expected negated expression", i, tt.expr)
+			}
 			if seq.MaxSpan != tt.maxSpan {
 				t.Errorf("%d. exp=%s maxspan=%s got maxspan=%v", i, tt.expr, tt.maxSpan, seq.MaxSpan)
 			}
@@ -382,6 +425,88 @@ func TestParseSequence(t *testing.T) {
 	}
 }
 
+func TestParseThreshold(t *testing.T) {
+	var tests = []struct {
+		expr   string
+		err    error
+		count  int
+		within time.Duration
+		hasBy  bool
+	}{
+		{
+			`50 within 1m by ps.uuid |kevt.name = 'CreateFile'|`,
+			nil,
+			50,
+			time.Minute,
+			true,
+		},
+		{
+			`10 within 30s |kevt.name = 'CreateFile' and file.name icontains 'temp'|`,
+			nil,
+			10,
+			time.Second * 30,
+			false,
+		},
+		{
+			`within 1m |kevt.name = 'CreateFile'|`,
+			errors.New("expected integer"),
+			0,
+			0,
+			false,
+		},
+		{
+			`0 within 1m |kevt.name = 'CreateFile'|`,
+			errors.New("expected positive integer"),
+			0,
+			0,
+			false,
+		},
+		{
+			`50 by ps.uuid |kevt.name = 'CreateFile'|`,
+			errors.New("expected within"),
+			0,
+			0,
+			false,
+		},
+		{
+			`50 within 5h |kevt.name = 'CreateFile'|`,
+			errors.New("threshold time window 5h0m0s cannot be greater than 4h"),
+			0,
+			0,
+			false,
+		},
+		{
+			`50 within 1m |kevt.name = 'CreateFile'`,
+			errors.New("expected |"),
+			0,
+			0,
+			false,
+		},
+	}
+
+	for i, tt := range tests {
+		p := NewParser(tt.expr)
+		thresh, err := p.ParseThreshold()
+		if err == nil && tt.err != nil {
+			t.Errorf("%d. exp=%s expected error=\n%v", i, tt.expr, tt.err)
+		} else if err != nil && tt.err == nil {
+			t.Errorf("%d. exp=%s got error=\n%v", i, tt.expr, err)
+		}
+
+		if thresh != nil {
+			if thresh.Count != tt.count {
+				t.Errorf("%d. exp=%s count=%d got count=%d", i, tt.expr, tt.count, thresh.Count)
+			}
+			if thresh.Within != tt.within {
+				t.Errorf("%d. exp=%s within=%s got within=%v", i, tt.expr, tt.within, thresh.Within)
+			}
+			if (thresh.By != nil) != tt.hasBy {
+				t.Errorf("%d. exp=%s hasBy=%t got hasBy=%t", i, tt.expr, tt.hasBy, thresh.By != nil)
+			}
+		}
+	}
+}
+
 func TestIsSequenceUnordered(t *testing.T) {
 	var tests = []struct {
 		expr        string
@@ -79,11 +79,14 @@ const (
 	Pipe     // |
 	LBracket // [
 	RBracket // ]
+	Bang     // !
 
 	Seq     // SEQUENCE
 	MaxSpan // MAXSPAN
 	By      // BY
 	As      // AS
+	Thresh  // THRESHOLD
+	Within  // WITHIN
 )
 
 var keywords map[string]token
@@ -93,7 +96,7 @@ func init() {
 	for _, tok := range []token{And, Or, Contains, IContains, In,
 		IIn, Not, Startswith, IStartswith, Endswith, IEndswith,
 		Matches, IMatches, Fuzzy, IFuzzy, Fuzzynorm, IFuzzynorm,
-		Intersects, IIntersects, Seq, MaxSpan, By, As} {
+		Intersects, IIntersects, Seq, MaxSpan, By, As, Thresh, Within} {
 		keywords[strings.ToLower(tokens[tok])] = tok
 	}
 	keywords["true"] = True
@@ -153,11 +156,14 @@ var tokens = [...]string{
 	Pipe:     "|",
 	LBracket: "[",
 	RBracket: "]",
+	Bang:     "!",
 
 	Seq:     "SEQUENCE",
 	MaxSpan: "MAXSPAN",
 	By:      "BY",
 	As:      "AS",
+	Thresh:  "THRESHOLD",
+	Within:  "WITHIN",
 }
 
 // isOperator determines whether the current token is an operator.
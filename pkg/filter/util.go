@@ -26,7 +26,7 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/util/loldrivers"
 	"github.com/rabbitstack/fibratus/pkg/util/signature"
 	"github.com/rabbitstack/fibratus/pkg/util/va"
-	"path/filepath"
+	"github.com/rabbitstack/fibratus/pkg/util/winpath"
 )
 
 // isLOLDriver interacts with the loldrivers client to determine
@@ -40,7 +40,7 @@ func isLOLDriver(f fields.Field, e *kevent.Kevent) (kparams.Value, error) {
 		filename = e.GetParamAsString(kparams.ImagePath)
 	}
 
-	isDriver := filepath.Ext(filename) == ".sys" || e.Kparams.TryGetBool(kparams.FileIsDriver)
+	isDriver := winpath.Ext(filename) == ".sys" || e.Kparams.TryGetBool(kparams.FileIsDriver)
 	if !isDriver {
 		return nil, nil
 	}
@@ -1,6 +1,3 @@
-//go:build windows
-// +build windows
-
 /*
  * Copyright 2019-2020 by Nedim Sabic Sabic
  * https://www.fibratus.io
@@ -21,16 +18,29 @@
 
 package fs
 
-import "golang.org/x/sys/windows"
+const (
+	// FileSupersede replaces the file if it exists or creates a new file
+	FileSupersede uint32 = iota
+	// FileOpen opens the existing file
+	FileOpen
+	// FileCreate creates a new file. It fails if the file already exists
+	FileCreate
+	// FileOpenIf opens the file if it exists or creates a new file
+	FileOpenIf
+	// FileOverwrite opens and overwrites the existing file
+	FileOverwrite
+	// FileOverwriteIf overwrites the file if it exists or creates a new file
+	FileOverwriteIf
+)
 
 // FileCreateDispositions is the mapping between the file create disposition and its symbolical name.
 var FileCreateDispositions = map[uint32]string{
-	uint32(windows.FILE_SUPERSEDE):    "SUPERSEDE",
-	uint32(windows.FILE_OPEN):         "OPEN",
-	uint32(windows.FILE_CREATE):       "CREATE",
-	uint32(windows.FILE_OPEN_IF):      "OPEN_IF",
-	uint32(windows.FILE_OVERWRITE):    "OVERWRITE",
-	uint32(windows.FILE_OVERWRITE_IF): "OVERWRITE_IF",
+	FileSupersede:   "SUPERSEDE",
+	FileOpen:        "OPEN",
+	FileCreate:      "CREATE",
+	FileOpenIf:      "OPEN_IF",
+	FileOverwrite:   "OVERWRITE",
+	FileOverwriteIf: "OVERWRITE_IF",
 }
 
 // FileType is the type alias for the file type
@@ -1,6 +1,3 @@
-//go:build windows
-// +build windows
-
 /*
  * Copyright 2019-2020 by Nedim Sabic Sabic
  * https://www.fibratus.io
@@ -24,7 +21,6 @@ package types
 import (
 	"fmt"
 	"github.com/rabbitstack/fibratus/pkg/util/bytes"
-	"golang.org/x/sys/windows"
 	"unsafe"
 )
 
@@ -111,7 +107,7 @@ func (h *Handle) Unmarshal(b []byte) error {
 	}
 
 	// read handle identifier
-	h.Num = windows.Handle(bytes.ReadUint64(b[0:]))
+	h.Num = ID(bytes.ReadUint64(b[0:]))
 	// read object address
 	h.Object = bytes.ReadUint64(b[8:])
 	// read pid
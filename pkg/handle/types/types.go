@@ -1,6 +1,3 @@
-//go:build windows
-// +build windows
-
 /*
  * Copyright 2019-2020 by Nedim Sabic Sabic
  * https://www.fibratus.io
@@ -24,9 +21,6 @@ package types
 import (
 	"expvar"
 	"fmt"
-	"github.com/rabbitstack/fibratus/pkg/sys"
-	log "github.com/sirupsen/logrus"
-	"golang.org/x/sys/windows"
 	"strings"
 )
 
@@ -46,14 +40,10 @@ var (
 	typeMisses = expvar.NewInt("handle.types.name.misses")
 )
 
-func init() {
-	findObjectTypes()
-}
-
 // Handle stores various metadata specific to the handle allocated by a process.
 type Handle struct {
 	// Num represents the internal handle identifier.
-	Num windows.Handle `json:"id"`
+	Num ID `json:"id"`
 	// Object is the kernel address that this handle references.
 	Object uint64 `json:"-"`
 	// Pid represents the process's identifier that owns the handle.
@@ -134,17 +124,3 @@ func ConvertTypeIDToName(id uint16) string {
 	typeMisses.Add(1)
 	return ""
 }
-
-func findObjectTypes() {
-	objectTypes, err := sys.QueryObject[sys.ObjectTypesInformation](0, sys.ObjectTypesInformationClass)
-	if err != nil {
-		log.Warnf("unable to query object types: %v", err)
-		return
-	}
-	typesCount.Add(int64(objectTypes.NumberOfTypes))
-	objectTypeInfo := objectTypes.First()
-	for i := 0; i < int(objectTypes.NumberOfTypes); i++ {
-		objectTypeInfo = objectTypes.Next(objectTypeInfo)
-		typeNames[uint16(objectTypeInfo.TypeIndex)] = objectTypeInfo.TypeName.String()
-	}
-}
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/sys"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/sys/windows"
+)
+
+// ID represents the handle identifier.
+type ID = windows.Handle
+
+func init() {
+	findObjectTypes()
+}
+
+func findObjectTypes() {
+	objectTypes, err := sys.QueryObject[sys.ObjectTypesInformation](0, sys.ObjectTypesInformationClass)
+	if err != nil {
+		log.Warnf("unable to query object types: %v", err)
+		return
+	}
+	typesCount.Add(int64(objectTypes.NumberOfTypes))
+	objectTypeInfo := objectTypes.First()
+	for i := 0; i < int(objectTypes.NumberOfTypes); i++ {
+		objectTypeInfo = objectTypes.Next(objectTypeInfo)
+		typeNames[uint16(objectTypeInfo.TypeIndex)] = objectTypeInfo.TypeName.String()
+	}
+}
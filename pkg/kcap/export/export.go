@@ -0,0 +1,86 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package export converts events read from the capture file to formats
+// suitable for loading into analytics engines such as DuckDB or pandas.
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+)
+
+// Format designates the export format.
+type Format string
+
+const (
+	// JSONL exports events as JSON Lines. Each line contains a single event.
+	JSONL Format = "jsonl"
+	// Parquet exports events to the columnar Parquet format.
+	Parquet Format = "parquet"
+)
+
+// FormatFromPath infers the export format from the output file extension.
+// It falls back to JSON Lines if the extension is not recognized.
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".parquet", ".pq":
+		return Parquet
+	default:
+		return JSONL
+	}
+}
+
+// Exporter writes events to the underlying writer in the specific format.
+type Exporter interface {
+	// Export writes a single event.
+	Export(*kevent.Kevent) error
+	// Close flushes any buffered data. It doesn't close the underlying writer.
+	Close() error
+}
+
+// New creates a new exporter for the given format.
+func New(format Format, w io.Writer) (Exporter, error) {
+	switch format {
+	case JSONL:
+		return &jsonl{w: bufio.NewWriter(w)}, nil
+	case Parquet:
+		return newParquet(w), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// jsonl writes each event as a JSON document followed by the newline.
+type jsonl struct {
+	w *bufio.Writer
+}
+
+func (j *jsonl) Export(kevt *kevent.Kevent) error {
+	if _, err := j.w.Write(kevt.MarshalJSON()); err != nil {
+		return err
+	}
+	return j.w.WriteByte('\n')
+}
+
+func (j *jsonl) Close() error { return j.w.Flush() }
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newEvents(ts time.Time) []*kevent.Kevent {
+	ps := &pstypes.PS{
+		PID:       2436,
+		Ppid:      6304,
+		Name:      "firefox.exe",
+		Exe:       `C:\Program Files\Mozilla Firefox\firefox.exe`,
+		Cmdline:   `C:\Program Files\Mozilla Firefox\firefox.exe -contentproc`,
+		Args:      []string{"-contentproc"},
+		SID:       "S-1-1-18",
+		Username:  "admin",
+		Domain:    "archrabbit",
+		SessionID: 4,
+		Parent: &pstypes.PS{
+			PID:  6304,
+			Name: "explorer.exe",
+			Exe:  `C:\Windows\explorer.exe`,
+		},
+	}
+	return []*kevent.Kevent{
+		{
+			Type:        ktypes.CreateFile,
+			Tid:         2484,
+			PID:         2436,
+			CPU:         1,
+			Seq:         1,
+			Name:        "CreateFile",
+			Timestamp:   ts,
+			Category:    ktypes.File,
+			Host:        "archrabbit",
+			Description: "Creates or opens a new file, directory, I/O device, pipe, console",
+			Kparams: kevent.Kparams{
+				kparams.FileObject:    {Name: kparams.FileObject, Type: kparams.Uint64, Value: uint64(12456738026482168384)},
+				kparams.FilePath:      {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: `C:\Windows\system32\user32.dll`},
+				kparams.FileType:      {Name: kparams.FileType, Type: kparams.AnsiString, Value: "file"},
+				kparams.FileOperation: {Name: kparams.FileOperation, Type: kparams.AnsiString, Value: "open"},
+			},
+			PS: ps,
+		},
+		{
+			Type:      ktypes.ConnectTCPv4,
+			Tid:       2484,
+			PID:       2436,
+			Seq:       2,
+			Name:      "Connect",
+			Timestamp: ts.Add(time.Second),
+			Category:  ktypes.Net,
+			Host:      "archrabbit",
+			Kparams: kevent.Kparams{
+				kparams.NetSIP:     {Name: kparams.NetSIP, Type: kparams.IPv4, Value: net.ParseIP("10.0.2.15")},
+				kparams.NetDIP:     {Name: kparams.NetDIP, Type: kparams.IPv4, Value: net.ParseIP("216.58.201.174")},
+				kparams.NetSport:   {Name: kparams.NetSport, Type: kparams.Port, Value: uint16(49680)},
+				kparams.NetDport:   {Name: kparams.NetDport, Type: kparams.Port, Value: uint16(443)},
+				kparams.NetL4Proto: {Name: kparams.NetL4Proto, Type: kparams.AnsiString, Value: "TCP"},
+				kparams.NetSize:    {Name: kparams.NetSize, Type: kparams.Uint32, Value: uint32(512)},
+			},
+			PS: ps,
+		},
+		{
+			Type:      ktypes.RegSetValue,
+			Tid:       2484,
+			PID:       2436,
+			Seq:       3,
+			Name:      "RegSetValue",
+			Timestamp: ts.Add(time.Second * 2),
+			Category:  ktypes.Registry,
+			Host:      "archrabbit",
+			Kparams: kevent.Kparams{
+				kparams.RegPath:      {Name: kparams.RegPath, Type: kparams.UnicodeString, Value: `HKEY_CURRENT_USER\Software\Microsoft\Windows\CurrentVersion\Run\Updater`},
+				kparams.RegValue:     {Name: kparams.RegValue, Type: kparams.UnicodeString, Value: `C:\Temp\updater.exe`},
+				kparams.RegValueType: {Name: kparams.RegValueType, Type: kparams.AnsiString, Value: "REG_SZ"},
+				kparams.NTStatus:     {Name: kparams.NTStatus, Type: kparams.AnsiString, Value: "Success"},
+			},
+		},
+	}
+}
+
+func TestFormatFromPath(t *testing.T) {
+	assert.Equal(t, Parquet, FormatFromPath("events.parquet"))
+	assert.Equal(t, Parquet, FormatFromPath(`C:\exports\events.PQ`))
+	assert.Equal(t, JSONL, FormatFromPath("events.jsonl"))
+	assert.Equal(t, JSONL, FormatFromPath("-"))
+}
+
+func TestExportJSONL(t *testing.T) {
+	var b bytes.Buffer
+	exp, err := New(JSONL, &b)
+	require.NoError(t, err)
+	for _, kevt := range newEvents(time.Now()) {
+		require.NoError(t, exp.Export(kevt))
+	}
+	require.NoError(t, exp.Close())
+
+	var seqs []uint64
+	scanner := bufio.NewScanner(&b)
+	for scanner.Scan() {
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &doc))
+		seqs = append(seqs, uint64(doc["seq"].(float64)))
+	}
+	assert.Equal(t, []uint64{1, 2, 3}, seqs)
+}
+
+func TestExportParquet(t *testing.T) {
+	var b bytes.Buffer
+	exp, err := New(Parquet, &b)
+	require.NoError(t, err)
+	ts := time.Date(2024, 3, 11, 10, 23, 4, 12345, time.UTC)
+	for _, kevt := range newEvents(ts) {
+		require.NoError(t, exp.Export(kevt))
+	}
+	require.NoError(t, exp.Close())
+
+	r := parquet.NewGenericReader[Row](bytes.NewReader(b.Bytes()))
+	defer r.Close()
+	require.Equal(t, int64(3), r.NumRows())
+	rows := make([]Row, 3)
+	n, _ := r.Read(rows)
+	require.Equal(t, 3, n)
+
+	file := rows[0]
+	assert.Equal(t, uint64(1), file.Kevt.Seq)
+	assert.Equal(t, "CreateFile", file.Kevt.Name)
+	assert.Equal(t, "file", file.Kevt.Category)
+	assert.True(t, ts.Equal(file.Kevt.Time))
+	require.NotNil(t, file.PS)
+	assert.Equal(t, "firefox.exe", file.PS.Name)
+	assert.Equal(t, []string{"-contentproc"}, file.PS.Args)
+	require.NotNil(t, file.PS.Parent)
+	assert.Equal(t, "explorer.exe", file.PS.Parent.Name)
+	require.NotNil(t, file.File)
+	assert.Equal(t, "user32.dll", file.File.Name)
+	assert.Equal(t, ".dll", file.File.Extension)
+	assert.Equal(t, "open", file.File.Operation)
+	assert.Nil(t, file.Net)
+	assert.Nil(t, file.Registry)
+	assert.Equal(t, map[string]string{kparams.FileObject: "12456738026482168384"}, file.Params)
+
+	conn := rows[1]
+	require.NotNil(t, conn.Net)
+	assert.Equal(t, "216.58.201.174", conn.Net.DIP)
+	assert.Equal(t, uint32(443), conn.Net.Dport)
+	assert.Equal(t, uint32(49680), conn.Net.Sport)
+	assert.Equal(t, "TCP", conn.Net.L4Proto)
+	assert.Empty(t, conn.Params)
+
+	reg := rows[2]
+	assert.Nil(t, reg.PS)
+	require.NotNil(t, reg.Registry)
+	assert.Equal(t, "Run", reg.Registry.KeyName)
+	assert.Equal(t, `C:\Temp\updater.exe`, reg.Registry.Value)
+	assert.Equal(t, "REG_SZ", reg.Registry.ValueType)
+}
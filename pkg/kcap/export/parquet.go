@@ -0,0 +1,267 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package export
+
+import (
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/compress/zstd"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/util/winpath"
+)
+
+// batchSize determines how many rows are buffered before they are handed to the Parquet writer
+const batchSize = 1024
+
+// Row is the Parquet schema of the exported event. The column groups
+// mirror the filter field namespaces, so ps.name or net.dip can be
+// queried the same way they are referenced in filters. Category
+// specific groups are null for events of other categories. Parameters
+// not projected to typed columns end up in the params map column.
+// Integers narrower than 32 bits are widened, since the Parquet writer
+// doesn't handle uint8 and uint16 values.
+type Row struct {
+	Kevt     Kevt              `parquet:"kevt"`
+	PS       *Process          `parquet:"ps,optional"`
+	File     *File             `parquet:"file,optional"`
+	Net      *Net              `parquet:"net,optional"`
+	Registry *Registry         `parquet:"registry,optional"`
+	Params   map[string]string `parquet:"params"`
+}
+
+// Kevt contains the canonical event fields.
+type Kevt struct {
+	Seq         uint64    `parquet:"seq"`
+	PID         uint32    `parquet:"pid"`
+	TID         uint32    `parquet:"tid"`
+	CPU         uint32    `parquet:"cpu"`
+	Name        string    `parquet:"name,dict"`
+	Category    string    `parquet:"category,dict"`
+	Description string    `parquet:"desc,dict"`
+	Host        string    `parquet:"host,dict"`
+	Time        time.Time `parquet:"time,timestamp(nanosecond)"`
+}
+
+// Process contains the fields of the process that generated the event.
+type Process struct {
+	PID       uint32   `parquet:"pid"`
+	Ppid      uint32   `parquet:"ppid"`
+	Name      string   `parquet:"name,dict"`
+	Exe       string   `parquet:"exe"`
+	Cmdline   string   `parquet:"cmdline"`
+	Args      []string `parquet:"args,list"`
+	Cwd       string   `parquet:"cwd"`
+	SID       string   `parquet:"sid,dict"`
+	Username  string   `parquet:"username,dict"`
+	Domain    string   `parquet:"domain,dict"`
+	SessionID uint32   `parquet:"sessionid"`
+	Parent    *Parent  `parquet:"parent,optional"`
+}
+
+// Parent contains the fields of the parent process.
+type Parent struct {
+	PID     uint32 `parquet:"pid"`
+	Name    string `parquet:"name,dict"`
+	Exe     string `parquet:"exe"`
+	Cmdline string `parquet:"cmdline"`
+}
+
+// File contains the fields of file system events.
+type File struct {
+	Path      string `parquet:"path"`
+	Name      string `parquet:"name"`
+	Extension string `parquet:"extension,dict"`
+	Operation string `parquet:"operation,dict"`
+	Type      string `parquet:"type,dict"`
+}
+
+// Net contains the fields of network events.
+type Net struct {
+	SIP       string `parquet:"sip"`
+	DIP       string `parquet:"dip"`
+	Sport     uint32 `parquet:"sport"`
+	Dport     uint32 `parquet:"dport"`
+	SportName string `parquet:"sport_name,dict"`
+	DportName string `parquet:"dport_name,dict"`
+	L4Proto   string `parquet:"l4_proto,dict"`
+	Size      uint32 `parquet:"size"`
+}
+
+// Registry contains the fields of registry events.
+type Registry struct {
+	Path      string `parquet:"path"`
+	KeyName   string `parquet:"key_name"`
+	Value     string `parquet:"value"`
+	ValueType string `parquet:"value_type,dict"`
+	Status    string `parquet:"status,dict"`
+}
+
+// projected contains the parameters that are stored in typed columns for each category
+var projected = map[ktypes.Category][]string{
+	ktypes.File:     {kparams.FilePath, kparams.FileOperation, kparams.FileType},
+	ktypes.Net:      {kparams.NetSIP, kparams.NetDIP, kparams.NetSport, kparams.NetDport, kparams.NetSportName, kparams.NetDportName, kparams.NetL4Proto, kparams.NetSize},
+	ktypes.Registry: {kparams.RegPath, kparams.RegValue, kparams.RegValueType, kparams.NTStatus},
+}
+
+// NewRow builds the Parquet row from the event.
+func NewRow(kevt *kevent.Kevent) Row {
+	row := Row{
+		Kevt: Kevt{
+			Seq:         kevt.Seq,
+			PID:         kevt.PID,
+			TID:         kevt.Tid,
+			CPU:         uint32(kevt.CPU),
+			Name:        kevt.Name,
+			Category:    string(kevt.Category),
+			Description: kevt.Description,
+			Host:        kevt.Host,
+			Time:        kevt.Timestamp,
+		},
+		PS:     newProcess(kevt.PS),
+		Params: make(map[string]string),
+	}
+
+	switch kevt.Category {
+	case ktypes.File:
+		path := kevt.GetParamAsString(kparams.FilePath)
+		row.File = &File{
+			Path:      path,
+			Name:      winpath.Base(path),
+			Extension: winpath.Ext(path),
+			Operation: kevt.GetParamAsString(kparams.FileOperation),
+			Type:      kevt.GetParamAsString(kparams.FileType),
+		}
+	case ktypes.Net:
+		row.Net = &Net{
+			SIP:       kevt.GetParamAsString(kparams.NetSIP),
+			DIP:       kevt.GetParamAsString(kparams.NetDIP),
+			Sport:     uint32(kevt.Kparams.TryGetUint16(kparams.NetSport)),
+			Dport:     uint32(kevt.Kparams.TryGetUint16(kparams.NetDport)),
+			SportName: kevt.GetParamAsString(kparams.NetSportName),
+			DportName: kevt.GetParamAsString(kparams.NetDportName),
+			L4Proto:   kevt.GetParamAsString(kparams.NetL4Proto),
+			Size:      kevt.Kparams.TryGetUint32(kparams.NetSize),
+		}
+	case ktypes.Registry:
+		path := kevt.GetParamAsString(kparams.RegPath)
+		key := winpath.Base(path)
+		if kevt.IsRegSetValue() {
+			key = winpath.Base(winpath.Dir(path))
+		}
+		row.Registry = &Registry{
+			Path:      path,
+			KeyName:   key,
+			Value:     kevt.GetParamAsString(kparams.RegValue),
+			ValueType: kevt.GetParamAsString(kparams.RegValueType),
+			Status:    kevt.GetParamAsString(kparams.NTStatus),
+		}
+	}
+
+	for name, kpar := range kevt.Kparams {
+		if isProjected(kevt.Category, name) {
+			continue
+		}
+		row.Params[name] = kpar.String()
+	}
+
+	return row
+}
+
+func isProjected(category ktypes.Category, name string) bool {
+	for _, n := range projected[category] {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func newProcess(ps *pstypes.PS) *Process {
+	if ps == nil {
+		return nil
+	}
+	proc := &Process{
+		PID:       ps.PID,
+		Ppid:      ps.Ppid,
+		Name:      ps.Name,
+		Exe:       ps.Exe,
+		Cmdline:   ps.Cmdline,
+		Args:      ps.Args,
+		Cwd:       ps.Cwd,
+		SID:       ps.SID,
+		Username:  ps.Username,
+		Domain:    ps.Domain,
+		SessionID: ps.SessionID,
+	}
+	if ps.Parent != nil {
+		proc.Parent = &Parent{
+			PID:     ps.Parent.PID,
+			Name:    ps.Parent.Name,
+			Exe:     ps.Parent.Exe,
+			Cmdline: ps.Parent.Cmdline,
+		}
+	}
+	return proc
+}
+
+// pq buffers event rows and writes them as zstd compressed row groups.
+type pq struct {
+	w    *parquet.GenericWriter[Row]
+	rows []Row
+}
+
+func newParquet(w io.Writer) *pq {
+	return &pq{
+		w: parquet.NewGenericWriter[Row](
+			w,
+			parquet.Compression(&zstd.Codec{}),
+			parquet.CreatedBy("fibratus", "", ""),
+		),
+		rows: make([]Row, 0, batchSize),
+	}
+}
+
+func (p *pq) Export(kevt *kevent.Kevent) error {
+	p.rows = append(p.rows, NewRow(kevt))
+	if len(p.rows) < batchSize {
+		return nil
+	}
+	return p.flush()
+}
+
+func (p *pq) flush() error {
+	if len(p.rows) == 0 {
+		return nil
+	}
+	_, err := p.w.Write(p.rows)
+	p.rows = p.rows[:0]
+	return err
+}
+
+func (p *pq) Close() error {
+	if err := p.flush(); err != nil {
+		return err
+	}
+	return p.w.Close()
+}
@@ -0,0 +1,181 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package format implements the portable decoding of the kcap container
+// format. The decoder doesn't depend on any OS facilities, so the header,
+// handle and event sections can be pulled out of the capture file on any
+// platform. Turning raw section blocks into events and handles is left to
+// the kevent and handle marshallers.
+//
+// Starting with the 2.1 version, events are written in independently
+// compressed frames, and the trailing index maps sequence numbers and
+// timestamps to frame offsets, so readers can seek straight into the
+// requested portion of the capture.
+package format
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+	"github.com/rabbitstack/fibratus/pkg/util/bytes"
+)
+
+// Magic has two purposes. It is used to identify kcap files. The magic is stored within the
+// first 8 bytes of the file. The decoder ensures the magic number matches this constant.
+// Besides identifying the capture file, it serves as an input for initializing the byte
+// order on the machine where kcap file is read. This implies capture can be taken on a
+// machine with different endianness from the one capture is replayed.
+const Magic = 0x6669627261747573
+
+// Major represents the major digit of the kcap file format. Incrementing the major digit
+// makes older kcap readers not capable to replay the capture file.
+const Major = uint8(2)
+
+// Minor represents the minor digit of the kcap file format. Captures with the
+// minor digit 1 or greater are laid out in indexed frames.
+const Minor = uint8(1)
+
+var (
+	// ErrMagicMismatch signals invalid kcap binary format
+	ErrMagicMismatch = errors.New("invalid kcap file magic number")
+	// ErrMajorVer signals incompatible kcap version
+	ErrMajorVer = func(maj, min byte) error {
+		return fmt.Errorf("incompatible kcap version format. Required version %d.%d but %d.%d found", Major, Minor, maj, min)
+	}
+	// ErrReadVersion is thrown when version digit errors occur
+	ErrReadVersion = func(s string, err error) error { return fmt.Errorf("couldn't read %s version digit: %v", s, err) }
+	// ErrReadSection is thrown when section read errors occur
+	ErrReadSection = func(s section.Type, err error) error { return fmt.Errorf("couldn't read %s section: %v", s, err) }
+)
+
+// Header represents the leading block of the kcap file.
+type Header struct {
+	// Major is the major digit of the format the capture was written with.
+	Major uint8
+	// Minor is the minor digit of the format the capture was written with.
+	Minor uint8
+	// Flags is the bit vector reserved for the header description.
+	Flags uint64
+}
+
+// String returns the header version in major.minor notation.
+func (h Header) String() string { return fmt.Sprintf("%d.%d", h.Major, h.Minor) }
+
+// ReadHeader reads and validates the kcap header from the decompressed
+// byte stream. As a side effect, the native byte order is initialized
+// from the magic number, so all subsequent reads use the endianness of
+// the machine where the capture was taken.
+func ReadHeader(r io.Reader) (Header, error) {
+	var h Header
+	mag := make([]byte, 8)
+	if _, err := io.ReadFull(r, mag); err != nil {
+		return h, ErrMagicMismatch
+	}
+	if binary.LittleEndian.Uint64(mag) != Magic && binary.BigEndian.Uint64(mag) != Magic {
+		return h, ErrMagicMismatch
+	}
+	bytes.InitNativeEndian(mag)
+
+	ver := make([]byte, 1)
+	if _, err := io.ReadFull(r, ver); err != nil {
+		return h, ErrReadVersion("major", err)
+	}
+	h.Major = ver[0]
+	if _, err := io.ReadFull(r, ver); err != nil {
+		return h, ErrReadVersion("minor", err)
+	}
+	h.Minor = ver[0]
+	if h.Major < Major {
+		return h, ErrMajorVer(h.Major, h.Minor)
+	}
+
+	flags := make([]byte, 8)
+	if _, err := io.ReadFull(r, flags); err != nil {
+		return h, fmt.Errorf("fail to read kcap flags: %v", err)
+	}
+	h.Flags = bytes.ReadUint64(flags)
+
+	return h, nil
+}
+
+// Decoder pulls the header and the raw section blocks from the
+// decompressed kcap byte stream. The handle section must be
+// consumed before iterating over event sections.
+type Decoder struct {
+	r   io.Reader
+	hdr Header
+}
+
+// NewDecoder reads the kcap header from the given reader and returns
+// the decoder positioned at the start of the handle section.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	hdr, err := ReadHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Decoder{r: r, hdr: hdr}, nil
+}
+
+// Header returns the header of the capture file.
+func (d *Decoder) Header() Header { return d.hdr }
+
+// ReadHandles reads the handle section and returns the raw handle
+// blocks. Blocks that couldn't be read completely are left nil.
+func (d *Decoder) ReadHandles() ([][]byte, error) {
+	var sec section.Section
+	if _, err := io.ReadFull(d.r, sec[:]); err != nil {
+		return nil, ErrReadSection(section.Handle, err)
+	}
+	if sec.Type() != section.Handle {
+		return nil, ErrReadSection(section.Handle, fmt.Errorf("unexpected %s section", sec.Type()))
+	}
+	handles := make([][]byte, sec.Len())
+	for i := range handles {
+		b := make([]byte, 2)
+		if _, err := io.ReadFull(d.r, b); err != nil {
+			continue
+		}
+		b = make([]byte, bytes.ReadUint16(b))
+		if _, err := io.ReadFull(d.r, b); err != nil {
+			continue
+		}
+		handles[i] = b
+	}
+	return handles, nil
+}
+
+// Next returns the next event section along with its payload. It
+// returns io.EOF when there are no more sections in the stream, and
+// io.ErrUnexpectedEOF if the last section is truncated.
+func (d *Decoder) Next() (section.Section, []byte, error) {
+	var sec section.Section
+	if _, err := io.ReadFull(d.r, sec[:]); err != nil {
+		return sec, nil, err
+	}
+	buf := make([]byte, sec.Size())
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return sec, nil, err
+	}
+	return sec, buf, nil
+}
@@ -0,0 +1,131 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	gobytes "bytes"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
+	"github.com/rabbitstack/fibratus/pkg/util/bytes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder(t *testing.T) {
+	var b gobytes.Buffer
+	b.Write(bytes.WriteUint64(Magic))
+	b.Write([]byte{Major, Minor})
+	b.Write(bytes.WriteUint64(0))
+	hsec := section.New(section.Handle, kcapver.HandleSecV1, 2, 0)
+	b.Write(hsec[:])
+	for _, h := range []string{"handle1", "h2"} {
+		b.Write(bytes.WriteUint16(uint16(len(h))))
+		b.WriteString(h)
+	}
+	for _, e := range []string{"event1", "ev2"} {
+		sec := section.New(section.Kevt, kcapver.KevtSecV2, 0, uint32(len(e)))
+		b.Write(sec[:])
+		b.WriteString(e)
+	}
+	// truncated section
+	sec := section.New(section.Kevt, kcapver.KevtSecV2, 0, 10)
+	b.Write(sec[:])
+	b.WriteString("ev")
+
+	dec, err := NewDecoder(&b)
+	require.NoError(t, err)
+	assert.Equal(t, "2.1", dec.Header().String())
+
+	handles, err := dec.ReadHandles()
+	require.NoError(t, err)
+	require.Len(t, handles, 2)
+	assert.Equal(t, "handle1", string(handles[0]))
+	assert.Equal(t, "h2", string(handles[1]))
+
+	s, buf, err := dec.Next()
+	require.NoError(t, err)
+	assert.Equal(t, section.Kevt, s.Type())
+	assert.Equal(t, kcapver.KevtSecV2, s.Version())
+	assert.Equal(t, "event1", string(buf))
+	_, buf, err = dec.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "ev2", string(buf))
+	_, _, err = dec.Next()
+	require.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, _, err = dec.Next()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestReadHeaderErrors(t *testing.T) {
+	_, err := ReadHeader(gobytes.NewReader([]byte("notakcap")))
+	require.ErrorIs(t, err, ErrMagicMismatch)
+
+	var b gobytes.Buffer
+	b.Write(bytes.WriteUint64(Magic))
+	b.Write([]byte{1, 0})
+	_, err = ReadHeader(&b)
+	require.EqualError(t, err, "incompatible kcap version format. Required version 2.1 but 1.0 found")
+}
+
+func TestDecodeCapture(t *testing.T) {
+	f, err := os.Open("../_fixtures/cap2.kcap")
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	dec, err := NewDecoder(zr)
+	require.NoError(t, err)
+	assert.Equal(t, Major, dec.Header().Major)
+
+	handles, err := dec.ReadHandles()
+	require.NoError(t, err)
+	require.NotEmpty(t, handles)
+
+	n := 0
+	for {
+		sec, buf, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, section.Kevt, sec.Type())
+		require.Len(t, buf, int(sec.Size()))
+		n++
+	}
+	require.True(t, n > 90)
+}
+
+func TestDecodeIncompatibleCapture(t *testing.T) {
+	f, err := os.Open("../_fixtures/cap1.kcap")
+	require.NoError(t, err)
+	defer f.Close()
+	zr, err := zstd.NewReader(f)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	_, err = NewDecoder(zr)
+	require.Error(t, err)
+}
@@ -0,0 +1,311 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// checkpointFrameMagic identifies zstd skippable frames carrying process state checkpoints
+	checkpointFrameMagic = 0x184D2A5E
+	// indexFrameMagic identifies the zstd skippable frame carrying the frame index
+	indexFrameMagic = 0x184D2A5F
+	// skippableFrameHeaderSize is the size of the skippable frame magic and the payload length
+	skippableFrameHeaderSize = 8
+	// indexTrailer terminates the index frame payload and the whole capture file
+	indexTrailer = "KCAPIDX1"
+	// indexTrailerSize is the length of the index frame offset and the trailer magic
+	indexTrailerSize = 16
+	// indexEntrySize is the size of the serialized index entry
+	indexEntrySize = 53
+)
+
+// ErrNoIndex is returned when the capture file has no trailing index. This
+// is the case for captures prior to the 2.1 version or if the writer was
+// not closed gracefully.
+var ErrNoIndex = errors.New("kcap index not found")
+
+// EntryKind designates the type of the frame referenced by the index entry.
+type EntryKind uint8
+
+const (
+	// EventFrame is the zstd frame containing event sections
+	EventFrame EntryKind = iota + 1
+	// CheckpointFrame is the skippable frame containing the process state snapshot
+	CheckpointFrame
+)
+
+// IndexEntry describes a single frame in the capture file. For event
+// frames, sequence and timestamp bounds span all events in the frame.
+// For checkpoint frames, the bounds reflect the last event written
+// before the checkpoint, and the count is the number of processes.
+type IndexEntry struct {
+	Kind   EntryKind
+	Offset uint64
+	Size   uint64
+	Count  uint32
+	MinSeq uint64
+	MaxSeq uint64
+	MinTs  int64
+	MaxTs  int64
+}
+
+// Index maps sequence numbers and timestamps to frame offsets. Entries
+// are stored in the order frames appear in the capture file.
+type Index struct {
+	Entries []IndexEntry
+}
+
+// update accounts for the event in the entry bounds.
+func (e *IndexEntry) update(seq uint64, ts int64) {
+	if e.Count == 0 || seq < e.MinSeq {
+		e.MinSeq = seq
+	}
+	if e.Count == 0 || ts < e.MinTs {
+		e.MinTs = ts
+	}
+	if seq > e.MaxSeq {
+		e.MaxSeq = seq
+	}
+	if e.Count == 0 || ts > e.MaxTs {
+		e.MaxTs = ts
+	}
+	e.Count++
+}
+
+// marshal serializes index entries. Unlike sections, the index
+// is always encoded in little endian byte order, as the rest of
+// zstd skippable frame fields.
+func (idx *Index) marshal() []byte {
+	b := make([]byte, 0, len(idx.Entries)*indexEntrySize)
+	for _, e := range idx.Entries {
+		b = append(b, byte(e.Kind))
+		b = binary.LittleEndian.AppendUint64(b, e.Offset)
+		b = binary.LittleEndian.AppendUint64(b, e.Size)
+		b = binary.LittleEndian.AppendUint32(b, e.Count)
+		b = binary.LittleEndian.AppendUint64(b, e.MinSeq)
+		b = binary.LittleEndian.AppendUint64(b, e.MaxSeq)
+		b = binary.LittleEndian.AppendUint64(b, uint64(e.MinTs))
+		b = binary.LittleEndian.AppendUint64(b, uint64(e.MaxTs))
+	}
+	return b
+}
+
+func (idx *Index) unmarshal(b []byte) error {
+	if len(b)%indexEntrySize != 0 {
+		return fmt.Errorf("invalid kcap index size: %d", len(b))
+	}
+	idx.Entries = make([]IndexEntry, 0, len(b)/indexEntrySize)
+	for len(b) > 0 {
+		e := IndexEntry{
+			Kind:   EntryKind(b[0]),
+			Offset: binary.LittleEndian.Uint64(b[1:]),
+			Size:   binary.LittleEndian.Uint64(b[9:]),
+			Count:  binary.LittleEndian.Uint32(b[17:]),
+			MinSeq: binary.LittleEndian.Uint64(b[21:]),
+			MaxSeq: binary.LittleEndian.Uint64(b[29:]),
+			MinTs:  int64(binary.LittleEndian.Uint64(b[37:])),
+			MaxTs:  int64(binary.LittleEndian.Uint64(b[45:])),
+		}
+		idx.Entries = append(idx.Entries, e)
+		b = b[indexEntrySize:]
+	}
+	return nil
+}
+
+// ReadIndex reads the trailing index from the capture file of the given size.
+// It returns ErrNoIndex if the capture file doesn't end with the index frame.
+func ReadIndex(r io.ReaderAt, size int64) (*Index, error) {
+	if size < skippableFrameHeaderSize+indexTrailerSize {
+		return nil, ErrNoIndex
+	}
+	trailer := make([]byte, indexTrailerSize)
+	if _, err := r.ReadAt(trailer, size-indexTrailerSize); err != nil {
+		return nil, err
+	}
+	if string(trailer[8:]) != indexTrailer {
+		return nil, ErrNoIndex
+	}
+	off := int64(binary.LittleEndian.Uint64(trailer))
+	if off < 0 || off > size-skippableFrameHeaderSize-indexTrailerSize {
+		return nil, fmt.Errorf("invalid kcap index offset: %d", off)
+	}
+	hdr := make([]byte, skippableFrameHeaderSize)
+	if _, err := r.ReadAt(hdr, off); err != nil {
+		return nil, err
+	}
+	if binary.LittleEndian.Uint32(hdr) != indexFrameMagic {
+		return nil, ErrNoIndex
+	}
+	l := int64(binary.LittleEndian.Uint32(hdr[4:]))
+	if off+skippableFrameHeaderSize+l != size || l < indexTrailerSize {
+		return nil, fmt.Errorf("invalid kcap index frame size: %d", l)
+	}
+	b := make([]byte, l-indexTrailerSize)
+	if _, err := r.ReadAt(b, off+skippableFrameHeaderSize); err != nil {
+		return nil, err
+	}
+	idx := &Index{}
+	if err := idx.unmarshal(b); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// Range designates time and sequence bounds of the events to read from the
+// capture. Zero values denote unbounded sides of the range.
+type Range struct {
+	From    time.Time
+	To      time.Time
+	FromSeq uint64
+	ToSeq   uint64
+}
+
+// IsZero determines if the range doesn't impose any bounds.
+func (r Range) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero() && r.FromSeq == 0 && r.ToSeq == 0
+}
+
+// Contains determines if the event with the given sequence number and timestamp falls within the range.
+func (r Range) Contains(seq uint64, ts time.Time) bool {
+	if !r.From.IsZero() && ts.Before(r.From) {
+		return false
+	}
+	if !r.To.IsZero() && ts.After(r.To) {
+		return false
+	}
+	if seq < r.FromSeq {
+		return false
+	}
+	if r.ToSeq != 0 && seq > r.ToSeq {
+		return false
+	}
+	return true
+}
+
+// overlaps determines if any event in the frame may fall within the range.
+func (r Range) overlaps(e IndexEntry) bool {
+	if !r.From.IsZero() && e.MaxTs < r.From.UnixNano() {
+		return false
+	}
+	if !r.To.IsZero() && e.MinTs > r.To.UnixNano() {
+		return false
+	}
+	if e.MaxSeq < r.FromSeq {
+		return false
+	}
+	if r.ToSeq != 0 && e.MinSeq > r.ToSeq {
+		return false
+	}
+	return true
+}
+
+// ParseSeqRange parses the sequence range expressed as from-to. Either side
+// of the range can be omitted to leave it unbounded. A single number
+// selects the event with that sequence.
+func ParseSeqRange(s string) (uint64, uint64, error) {
+	if s == "" {
+		return 0, 0, nil
+	}
+	from, to, ok := strings.Cut(s, "-")
+	if !ok {
+		to = from
+	}
+	var (
+		f, t uint64
+		err  error
+	)
+	if from != "" {
+		f, err = strconv.ParseUint(strings.TrimSpace(from), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid sequence range %q: %v", s, err)
+		}
+	}
+	if to != "" {
+		t, err = strconv.ParseUint(strings.TrimSpace(to), 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid sequence range %q: %v", s, err)
+		}
+	}
+	if t != 0 && f > t {
+		return 0, 0, fmt.Errorf("invalid sequence range %q: lower bound exceeds upper bound", s)
+	}
+	return f, t, nil
+}
+
+// PlannedFrame is the event frame the reader has to decode to satisfy the range.
+type PlannedFrame struct {
+	IndexEntry
+	// StateOnly indicates the frame is decoded only to rebuild the
+	// process state, but its events fall out of the requested range.
+	StateOnly bool
+}
+
+// Plan describes the portion of the capture that is read for the range.
+type Plan struct {
+	// Checkpoint is the process state snapshot the state is restored
+	// from before decoding frames. It is nil if the state must be
+	// rebuilt from the start of the capture.
+	Checkpoint *IndexEntry
+	// Frames are event frames decoded in the file order.
+	Frames []PlannedFrame
+}
+
+// Plan resolves the frames that have to be decoded to read all events
+// within the range. Frames between the nearest preceding checkpoint
+// and the first frame in range are decoded to rebuild the process
+// state. Frames after the last frame in range are never read.
+func (idx *Index) Plan(r Range) Plan {
+	var plan Plan
+	first, last := -1, -1
+	for i, e := range idx.Entries {
+		if e.Kind != EventFrame || !r.overlaps(e) {
+			continue
+		}
+		if first == -1 {
+			first = i
+		}
+		last = i
+	}
+	if first == -1 {
+		return plan
+	}
+	start := 0
+	for i := first - 1; i >= 0; i-- {
+		if idx.Entries[i].Kind == CheckpointFrame {
+			plan.Checkpoint = &idx.Entries[i]
+			start = i + 1
+			break
+		}
+	}
+	for _, e := range idx.Entries[start : last+1] {
+		if e.Kind != EventFrame {
+			continue
+		}
+		plan.Frames = append(plan.Frames, PlannedFrame{IndexEntry: e, StateOnly: !r.overlaps(e)})
+	}
+	return plan
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	gobytes "bytes"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type codec struct{}
+
+var (
+	encoder, _ = zstd.NewWriter(nil)
+	decoder, _ = zstd.NewReader(nil)
+)
+
+func (codec) Compress(dst, src []byte) []byte            { return encoder.EncodeAll(src, dst) }
+func (codec) Decompress(dst, src []byte) ([]byte, error) { return decoder.DecodeAll(src, dst) }
+
+var base = time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+
+// writeCapture writes a capture with four event frames of
+// ten events each and a checkpoint after the second frame.
+func writeCapture(t *testing.T) []byte {
+	var b gobytes.Buffer
+	w := NewWriter(&b, codec{}, 0)
+	require.NoError(t, w.WriteHandles(kcapver.HandleSecV1, [][]byte{[]byte("handle1")}))
+	seq := uint64(1)
+	for frame := 0; frame < 4; frame++ {
+		for i := 0; i < 10; i++ {
+			ts := base.Add(time.Duration(seq) * time.Second).UnixNano()
+			require.NoError(t, w.WriteEvent(kcapver.KevtSecV2, seq, ts, []byte(fmt.Sprintf("event%d", seq))))
+			seq++
+		}
+		require.NoError(t, w.CloseFrame())
+		if frame == 1 {
+			require.NoError(t, w.WriteCheckpoint(kcapver.ProcessSecV4, [][]byte{[]byte("proc1"), []byte("proc2")}))
+		}
+	}
+	require.NoError(t, w.Close())
+	return b.Bytes()
+}
+
+func TestWriterSequentialDecode(t *testing.T) {
+	b := writeCapture(t)
+
+	// skippable frames are transparent to the streaming decoder
+	zr, err := zstd.NewReader(gobytes.NewReader(b))
+	require.NoError(t, err)
+	defer zr.Close()
+	dec, err := NewDecoder(zr)
+	require.NoError(t, err)
+	assert.Equal(t, "2.1", dec.Header().String())
+
+	handles, err := dec.ReadHandles()
+	require.NoError(t, err)
+	require.Len(t, handles, 1)
+	assert.Equal(t, "handle1", string(handles[0]))
+
+	n := 0
+	for {
+		sec, buf, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		n++
+		assert.Equal(t, section.Kevt, sec.Type())
+		assert.Equal(t, fmt.Sprintf("event%d", n), string(buf))
+	}
+	assert.Equal(t, 40, n)
+}
+
+func TestReadIndex(t *testing.T) {
+	b := writeCapture(t)
+
+	idx, err := ReadIndex(gobytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+	require.Len(t, idx.Entries, 5)
+
+	kinds := []EntryKind{EventFrame, EventFrame, CheckpointFrame, EventFrame, EventFrame}
+	for i, e := range idx.Entries {
+		assert.Equal(t, kinds[i], e.Kind)
+	}
+	assert.Equal(t, uint64(11), idx.Entries[1].MinSeq)
+	assert.Equal(t, uint64(20), idx.Entries[1].MaxSeq)
+	assert.Equal(t, uint32(10), idx.Entries[1].Count)
+	assert.Equal(t, base.Add(20*time.Second).UnixNano(), idx.Entries[2].MaxTs)
+
+	zr, err := zstd.NewReader(gobytes.NewReader(b))
+	require.NoError(t, err)
+	defer zr.Close()
+	hdr, err := ReadHeader(zr)
+	require.NoError(t, err)
+	fr := NewFrameReader(gobytes.NewReader(b), codec{}, hdr)
+
+	dec, err := fr.Events(idx.Entries[3])
+	require.NoError(t, err)
+	_, buf, err := dec.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "event21", string(buf))
+
+	secs, procs, err := fr.Checkpoint(idx.Entries[2])
+	require.NoError(t, err)
+	require.Len(t, procs, 2)
+	assert.Equal(t, kcapver.ProcessSecV4, secs[0].Version())
+	assert.Equal(t, "proc2", string(procs[1]))
+
+	_, err = fr.Events(idx.Entries[2])
+	require.Error(t, err)
+
+	// truncated captures have no index
+	_, err = ReadIndex(gobytes.NewReader(b[:len(b)-1]), int64(len(b)-1))
+	require.ErrorIs(t, err, ErrNoIndex)
+}
+
+func TestIndexPlan(t *testing.T) {
+	b := writeCapture(t)
+	idx, err := ReadIndex(gobytes.NewReader(b), int64(len(b)))
+	require.NoError(t, err)
+
+	var tests = []struct {
+		r          Range
+		checkpoint bool
+		frames     []uint64
+		stateOnly  []bool
+	}{
+		{Range{}, false, []uint64{1, 11, 21, 31}, []bool{false, false, false, false}},
+		{Range{FromSeq: 15, ToSeq: 25}, false, []uint64{1, 11, 21}, []bool{true, false, false}},
+		{Range{FromSeq: 32}, true, []uint64{21, 31}, []bool{true, false}},
+		{Range{From: base.Add(21 * time.Second), To: base.Add(25 * time.Second)}, true, []uint64{21}, []bool{false}},
+		{Range{To: base.Add(5 * time.Second)}, false, []uint64{1}, []bool{false}},
+		{Range{FromSeq: 100}, false, nil, nil},
+	}
+
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d", i), func(t *testing.T) {
+			plan := idx.Plan(tt.r)
+			assert.Equal(t, tt.checkpoint, plan.Checkpoint != nil)
+			var frames []uint64
+			var stateOnly []bool
+			for _, f := range plan.Frames {
+				frames = append(frames, f.MinSeq)
+				stateOnly = append(stateOnly, f.StateOnly)
+			}
+			assert.Equal(t, tt.frames, frames)
+			assert.Equal(t, tt.stateOnly, stateOnly)
+		})
+	}
+}
+
+func TestRangeContains(t *testing.T) {
+	r := Range{From: base, To: base.Add(time.Minute), FromSeq: 10, ToSeq: 20}
+	assert.True(t, r.Contains(15, base.Add(time.Second)))
+	assert.False(t, r.Contains(9, base.Add(time.Second)))
+	assert.False(t, r.Contains(21, base.Add(time.Second)))
+	assert.False(t, r.Contains(15, base.Add(-time.Second)))
+	assert.False(t, r.Contains(15, base.Add(2*time.Minute)))
+	assert.True(t, Range{}.Contains(1, time.Time{}))
+}
+
+func TestParseSeqRange(t *testing.T) {
+	var tests = []struct {
+		s        string
+		from, to uint64
+		err      bool
+	}{
+		{"100-200", 100, 200, false},
+		{"100-", 100, 0, false},
+		{"-200", 0, 200, false},
+		{"150", 150, 150, false},
+		{"", 0, 0, false},
+		{"200-100", 0, 0, true},
+		{"a-b", 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			from, to, err := ParseSeqRange(tt.s)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.from, from)
+			assert.Equal(t, tt.to, to)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+)
+
+// FrameReader decodes individual frames referenced by the index
+// without reading the capture from the start.
+type FrameReader struct {
+	r     io.ReaderAt
+	codec Codec
+	hdr   Header
+}
+
+// NewFrameReader creates the frame reader for the capture with the
+// given header. The header must have been read beforehand to set up
+// the byte order of the capture.
+func NewFrameReader(r io.ReaderAt, codec Codec, hdr Header) *FrameReader {
+	return &FrameReader{r: r, codec: codec, hdr: hdr}
+}
+
+// Events decompresses the event frame and returns the decoder for
+// iterating over its event sections.
+func (fr *FrameReader) Events(e IndexEntry) (*Decoder, error) {
+	if e.Kind != EventFrame {
+		return nil, fmt.Errorf("frame at offset %d is not an event frame", e.Offset)
+	}
+	b := make([]byte, e.Size)
+	if _, err := fr.r.ReadAt(b, int64(e.Offset)); err != nil {
+		return nil, ErrReadSection(section.Kevt, err)
+	}
+	buf, err := fr.codec.Decompress(nil, b)
+	if err != nil {
+		return nil, ErrReadSection(section.Kevt, err)
+	}
+	return &Decoder{r: bytes.NewReader(buf), hdr: fr.hdr}, nil
+}
+
+// Checkpoint reads the process state checkpoint and returns the
+// process sections along with their raw blocks.
+func (fr *FrameReader) Checkpoint(e IndexEntry) ([]section.Section, [][]byte, error) {
+	if e.Kind != CheckpointFrame || e.Size < skippableFrameHeaderSize {
+		return nil, nil, fmt.Errorf("frame at offset %d is not a checkpoint frame", e.Offset)
+	}
+	b := make([]byte, e.Size)
+	if _, err := fr.r.ReadAt(b, int64(e.Offset)); err != nil {
+		return nil, nil, ErrReadSection(section.Process, err)
+	}
+	if binary.LittleEndian.Uint32(b) != checkpointFrameMagic {
+		return nil, nil, ErrReadSection(section.Process, fmt.Errorf("invalid checkpoint frame magic"))
+	}
+	buf, err := fr.codec.Decompress(nil, b[skippableFrameHeaderSize:])
+	if err != nil {
+		return nil, nil, ErrReadSection(section.Process, err)
+	}
+	r := bytes.NewReader(buf)
+	var sec section.Section
+	if _, err := io.ReadFull(r, sec[:]); err != nil {
+		return nil, nil, ErrReadSection(section.Process, err)
+	}
+	secs := make([]section.Section, 0, sec.Len())
+	procs := make([][]byte, 0, sec.Len())
+	for i := uint32(0); i < sec.Len(); i++ {
+		var psec section.Section
+		if _, err := io.ReadFull(r, psec[:]); err != nil {
+			return nil, nil, ErrReadSection(section.Process, err)
+		}
+		b := make([]byte, psec.Size())
+		if _, err := io.ReadFull(r, b); err != nil {
+			return nil, nil, ErrReadSection(section.Process, err)
+		}
+		secs = append(secs, psec)
+		procs = append(procs, b)
+	}
+	return secs, procs, nil
+}
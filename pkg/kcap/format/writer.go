@@ -0,0 +1,213 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package format
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
+	"github.com/rabbitstack/fibratus/pkg/util/bytes"
+)
+
+// MaxFrameSize is the uncompressed size threshold after which the writer
+// closes the current event frame and starts a new one.
+const MaxFrameSize = 4 * 1024 * 1024
+
+var (
+	// ErrWriteMagic signals magic write errors
+	ErrWriteMagic = func(err error) error { return fmt.Errorf("couldn't write magic number: %v", err) }
+	// ErrWriteVersion signals version write errors
+	ErrWriteVersion = func(v string, err error) error { return fmt.Errorf("couldn't write %s kcap digit: %v", v, err) }
+	// ErrWriteSection signals section write errors
+	ErrWriteSection = func(s section.Type, err error) error { return fmt.Errorf("couldn't write %s kcap section: %v", s, err) }
+)
+
+// Codec compresses and decompresses self-contained frames. Any zstd
+// implementation satisfies the codec, as long as each Compress call
+// produces a complete zstd frame.
+type Codec interface {
+	// Compress appends the compressed frame of src to dst.
+	Compress(dst, src []byte) []byte
+	// Decompress appends the decompressed frame of src to dst.
+	Decompress(dst, src []byte) ([]byte, error)
+}
+
+// Writer lays out the capture as a sequence of independently compressed
+// frames. The first frame contains the header and the handle section.
+// Each subsequent frame contains a batch of event sections. Process state
+// checkpoints are stored in zstd skippable frames, and the capture is
+// terminated by the skippable frame holding the index. Since skippable
+// frames are ignored by zstd decoders, the whole capture remains a valid
+// zstd stream readable by any 2.x reader.
+type Writer struct {
+	w     io.Writer
+	codec Codec
+	// off is the number of bytes written to the underlying writer
+	off uint64
+	// buf accumulates uncompressed sections of the current frame
+	buf []byte
+	// cbuf is the scratch buffer for compressed frames
+	cbuf []byte
+	// frame describes the current frame
+	frame IndexEntry
+	idx   Index
+	// seq and ts are the sequence and the timestamp of the last written event
+	seq uint64
+	ts  int64
+}
+
+// NewWriter creates the frame writer and writes the kcap header that is
+// composed of magic number, major/minor digits and the flags bit vector.
+// The header is not flushed until the handle section is written.
+func NewWriter(w io.Writer, codec Codec, flags uint64) *Writer {
+	fw := &Writer{w: w, codec: codec, frame: IndexEntry{Kind: EventFrame}}
+	fw.buf = append(fw.buf, bytes.WriteUint64(Magic)...)
+	fw.buf = append(fw.buf, Major, Minor)
+	fw.buf = append(fw.buf, bytes.WriteUint64(flags)...)
+	return fw
+}
+
+// WriteHandles writes the handle section and closes the leading frame.
+// Each handle block is prefixed with its length.
+func (w *Writer) WriteHandles(ver kcapver.Version, handles [][]byte) error {
+	sec := section.New(section.Handle, ver, uint32(len(handles)), 0)
+	w.buf = append(w.buf, sec[:]...)
+	for _, b := range handles {
+		w.buf = append(w.buf, bytes.WriteUint16(uint16(len(b)))...)
+		w.buf = append(w.buf, b...)
+	}
+	// the leading frame is not indexed as it
+	// always resides at the start of the capture
+	if _, err := w.writeFrame(); err != nil {
+		return ErrWriteSection(section.Handle, err)
+	}
+	return nil
+}
+
+// WriteEvent appends the event section to the current frame. The
+// frame is closed once it exceeds the maximum frame size.
+func (w *Writer) WriteEvent(ver kcapver.Version, seq uint64, ts int64, b []byte) error {
+	sec := section.New(section.Kevt, ver, 0, uint32(len(b)))
+	w.buf = append(w.buf, sec[:]...)
+	w.buf = append(w.buf, b...)
+	w.frame.update(seq, ts)
+	w.seq, w.ts = seq, ts
+	if len(w.buf) >= MaxFrameSize {
+		return w.CloseFrame()
+	}
+	return nil
+}
+
+// CloseFrame compresses the current frame and writes it to the underlying
+// writer. It is a no-op if no events were written since the last call.
+func (w *Writer) CloseFrame() error {
+	if w.frame.Count == 0 {
+		return nil
+	}
+	e := w.frame
+	e.Offset = w.off
+	n, err := w.writeFrame()
+	if err != nil {
+		return ErrWriteSection(section.Kevt, err)
+	}
+	e.Size = uint64(n)
+	w.idx.Entries = append(w.idx.Entries, e)
+	w.frame = IndexEntry{Kind: EventFrame}
+	return nil
+}
+
+// WriteCheckpoint closes the current frame and writes the snapshot
+// of process states. Readers seeking into the capture restore the
+// process state from the nearest preceding checkpoint.
+func (w *Writer) WriteCheckpoint(ver kcapver.Version, procs [][]byte) error {
+	if err := w.CloseFrame(); err != nil {
+		return err
+	}
+	sec := section.New(section.Process, ver, uint32(len(procs)), 0)
+	b := append([]byte{}, sec[:]...)
+	for _, proc := range procs {
+		sec := section.New(section.Process, ver, 0, uint32(len(proc)))
+		b = append(b, sec[:]...)
+		b = append(b, proc...)
+	}
+	w.cbuf = w.codec.Compress(w.cbuf[:0], b)
+	e := IndexEntry{
+		Kind:   CheckpointFrame,
+		Offset: w.off,
+		Count:  uint32(len(procs)),
+		MinSeq: w.seq,
+		MaxSeq: w.seq,
+		MinTs:  w.ts,
+		MaxTs:  w.ts,
+	}
+	n, err := w.writeSkippableFrame(checkpointFrameMagic, w.cbuf)
+	if err != nil {
+		return ErrWriteSection(section.Process, err)
+	}
+	e.Size = uint64(n)
+	w.idx.Entries = append(w.idx.Entries, e)
+	return nil
+}
+
+// Close flushes the pending frame and writes the trailing index. The
+// underlying writer is not closed.
+func (w *Writer) Close() error {
+	if err := w.CloseFrame(); err != nil {
+		return err
+	}
+	off := w.off
+	b := w.idx.marshal()
+	b = binary.LittleEndian.AppendUint64(b, off)
+	b = append(b, indexTrailer...)
+	if _, err := w.writeSkippableFrame(indexFrameMagic, b); err != nil {
+		return fmt.Errorf("couldn't write kcap index: %v", err)
+	}
+	return nil
+}
+
+// Index returns the index of all frames written so far.
+func (w *Writer) Index() *Index { return &w.idx }
+
+// Size returns the number of bytes written to the underlying writer.
+func (w *Writer) Size() uint64 { return w.off }
+
+func (w *Writer) writeFrame() (int, error) {
+	w.cbuf = w.codec.Compress(w.cbuf[:0], w.buf)
+	w.buf = w.buf[:0]
+	n, err := w.w.Write(w.cbuf)
+	w.off += uint64(n)
+	return n, err
+}
+
+func (w *Writer) writeSkippableFrame(magic uint32, payload []byte) (int, error) {
+	var hdr [skippableFrameHeaderSize]byte
+	binary.LittleEndian.PutUint32(hdr[:], magic)
+	binary.LittleEndian.PutUint32(hdr[4:], uint32(len(payload)))
+	n, err := w.w.Write(hdr[:])
+	w.off += uint64(n)
+	if err != nil {
+		return n, err
+	}
+	m, err := w.w.Write(payload)
+	w.off += uint64(m)
+	return n + m, err
+}
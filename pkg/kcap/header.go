@@ -22,31 +22,32 @@
 package kcap
 
 import (
-	"github.com/rabbitstack/fibratus/pkg/kcap/section"
-	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
 )
 
-// magic has two purposes. It is used to identify kcap files. The magic is stored within the first 8 bytes of the file.
-// The reader ensures the magic number matches this constant. Besides identifying the capture file, it serves as an
-// input for initializing the byte order on the machine where kcap file is read. This implies capture can be taken on a
-// machine with different endianness from the one capture is replayed.
-const magic = 0x6669627261747573
+// magic identifies kcap files and determines the byte order of the capture.
+const magic = format.Magic
 
-// major represents the major digit of the kcap file format. Incrementing the major digit makes older kcap readers not
-// capable to replay the capture file
-const major = uint8(2)
+// major represents the major digit of the kcap file format
+const major = format.Major
 
 // minor represents the minor digit of the kcap file format
-const minor = uint8(0)
+const minor = format.Minor
 
 // flags denotes extra flags for the purpose of the header description
 const flags = uint64(0)
 
-// ws writes the section block with the specified parameters.
-func (w *writer) ws(typ section.Type, ver kcapver.Version, l, size uint32) error {
-	sec := section.New(typ, ver, l, size)
-	if _, err := w.zw.Write(sec[:]); err != nil {
-		return ErrWriteSection(typ, err)
-	}
-	return nil
-}
+var (
+	// encoder and decoder are safe for concurrent use when
+	// compressing/decompressing whole frames
+	encoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	decoder, _ = zstd.NewReader(nil)
+)
+
+// codec compresses each capture frame as a standalone zstd frame.
+type codec struct{}
+
+func (codec) Compress(dst, src []byte) []byte { return encoder.EncodeAll(src, dst) }
+
+func (codec) Decompress(dst, src []byte) ([]byte, error) { return decoder.DecodeAll(src, dst) }
@@ -22,27 +22,24 @@
 package kcap
 
 import (
-	"errors"
 	"expvar"
-	"fmt"
-	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
 )
 
 var (
 	// ErrKcapMagicMismatch signals invalid kcap binary format
-	ErrKcapMagicMismatch = errors.New("invalid kcap file magic number")
+	ErrKcapMagicMismatch = format.ErrMagicMismatch
 	// ErrMajorVer signals incompatible kcap version
-	ErrMajorVer = func(maj, min byte) error {
-		return fmt.Errorf("incompatible kcap version format. Required version %d.%d but %d.%d found", major, minor, maj, min)
-	}
+	ErrMajorVer = format.ErrMajorVer
 	// ErrReadVersion is thrown when version digit errors occur
-	ErrReadVersion = func(s string, err error) error { return fmt.Errorf("couldn't read %s version digit: %v", s, err) }
+	ErrReadVersion = format.ErrReadVersion
 	// ErrReadSection is thrown when section read errors occur
-	ErrReadSection = func(s section.Type, err error) error { return fmt.Errorf("couldn't read %s section: %v", s, err) }
+	ErrReadSection = format.ErrReadSection
 
-	kcapReadKevents           = expvar.NewInt("kcap.read.kevents")
-	kcapReadBytes             = expvar.NewInt("kcap.read.bytes")
-	kcapKeventUnmarshalErrors = expvar.NewInt("kcap.kevent.unmarshal.errors")
-	kcapHandleUnmarshalErrors = expvar.NewInt("kcap.reader.handle.unmarshal.errors")
-	kcapDroppedByFilter       = expvar.NewInt("kcap.reader.dropped.by.filter")
+	kcapReadKevents            = expvar.NewInt("kcap.read.kevents")
+	kcapReadBytes              = expvar.NewInt("kcap.read.bytes")
+	kcapKeventUnmarshalErrors  = expvar.NewInt("kcap.kevent.unmarshal.errors")
+	kcapHandleUnmarshalErrors  = expvar.NewInt("kcap.reader.handle.unmarshal.errors")
+	kcapProcessUnmarshalErrors = expvar.NewInt("kcap.reader.process.unmarshal.errors")
+	kcapDroppedByFilter        = expvar.NewInt("kcap.reader.dropped.by.filter")
 )
@@ -0,0 +1,305 @@
+//go:build kcap
+// +build kcap
+
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kcap
+
+import (
+	"context"
+	"fmt"
+	"github.com/klauspost/compress/zstd"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	log "github.com/sirupsen/logrus"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// NewReader builds a new instance of the kcap reader.
+func NewReader(filename string, config *config.Config) (Reader, error) {
+	if filepath.Ext(filename) == "" {
+		filename += ".kcap"
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%q capture file does not exist", filename)
+		}
+		return nil, err
+	}
+	zr, err := zstd.NewReader(f)
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	// from now on all byte reads will use the endianness of the magic number.
+	// This guarantees we'll be able to replay captures that were taken
+	// on a machine with a different endianness from the machine where
+	// actual capture is being read.
+	dec, err := format.NewDecoder(zr)
+	if err != nil {
+		zr.Close()
+		_ = f.Close()
+		return nil, err
+	}
+	r := &reader{f: f, zr: zr, dec: dec, config: config, done: make(chan struct{})}
+
+	// captures written in indexed frames can be
+	// replayed from an arbitrary point in time.
+	// If the capture wasn't closed gracefully, the
+	// index is missing and we fall back to reading
+	// the capture from the start
+	if dec.Header().Minor >= 1 {
+		fi, err := f.Stat()
+		if err != nil {
+			_ = r.Close()
+			return nil, err
+		}
+		r.idx, err = format.ReadIndex(f, fi.Size())
+		if err != nil && err != format.ErrNoIndex {
+			log.Warnf("unable to read kcap index: %v", err)
+		}
+	}
+
+	return r, nil
+}
+
+func (r *reader) SetFilter(f filter.Filter) { r.filter = f }
+
+func (r *reader) SetRange(rng format.Range) { r.rng = rng }
+
+func (r *reader) Read(ctx context.Context) (chan *kevent.Kevent, chan error) {
+	errsc := make(chan error, 100)
+	keventsc := make(chan *kevent.Kevent, 2000)
+	go func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		defer close(r.done)
+		// indexed captures are always read through the
+		// index to restore the process state checkpoint
+		// that may precede the first event frame, as in
+		// captures dumped by the flight recorder
+		if r.idx != nil {
+			r.seek(ctx, keventsc, errsc)
+			return
+		}
+		r.decode(ctx, r.dec, false, keventsc, errsc)
+	}()
+
+	return keventsc, errsc
+}
+
+// seek reads only the frames that overlap the range. Prior to
+// decoding the frames, the process state is restored from the
+// nearest checkpoint, and the frames between the checkpoint and
+// the range are decoded to update the snapshotters. The state of
+// the handle snapshotter is not checkpointed, so handles created
+// in the frames skipped over may be missing.
+func (r *reader) seek(ctx context.Context, keventsc chan *kevent.Kevent, errsc chan error) {
+	fr := format.NewFrameReader(r.f, codec{}, r.dec.Header())
+	plan := r.idx.Plan(r.rng)
+	if plan.Checkpoint != nil {
+		if err := r.restoreCheckpoint(fr, *plan.Checkpoint); err != nil {
+			errsc <- err
+		}
+	}
+	for _, frame := range plan.Frames {
+		dec, err := fr.Events(frame.IndexEntry)
+		if err != nil {
+			errsc <- err
+			continue
+		}
+		if !r.decode(ctx, dec, frame.StateOnly, keventsc, errsc) {
+			return
+		}
+	}
+}
+
+// decode pulls all event sections from the decoder. If stateOnly
+// is true, events are used to update the snapshotters but are
+// never pushed to the event channel. It returns false if the
+// context is canceled.
+func (r *reader) decode(ctx context.Context, dec *format.Decoder, stateOnly bool, keventsc chan *kevent.Kevent, errsc chan error) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		default:
+		}
+
+		sec, buf, err := dec.Next()
+		if err != nil {
+			if err != io.EOF {
+				errsc <- err
+				continue
+			}
+			return true
+		}
+		kevt, err := kevent.NewFromKcap(buf, sec.Version())
+		if err != nil {
+			errsc <- fmt.Errorf("fail to unmarshal kevent: %v", err)
+			kcapKeventUnmarshalErrors.Add(1)
+			continue
+		}
+		kcapReadBytes.Add(int64(len(buf)))
+		// update the state of the ps/handle snapshotters
+		if err := r.updateSnapshotters(kevt); err != nil {
+			log.Warn(err)
+		}
+		if stateOnly || !r.rng.Contains(kevt.Seq, kevt.Timestamp) {
+			continue
+		}
+		// push the event to the chanel
+		r.read(kevt, keventsc)
+	}
+}
+
+// restoreCheckpoint populates the process snapshotter
+// from the process state checkpoint.
+func (r *reader) restoreCheckpoint(fr *format.FrameReader, e format.IndexEntry) error {
+	secs, blocks, err := fr.Checkpoint(e)
+	if err != nil {
+		return err
+	}
+	procs := make(map[uint32]*pstypes.PS, len(blocks))
+	for i, b := range blocks {
+		proc, err := pstypes.NewFromKcap(b, secs[i])
+		if err != nil {
+			kcapProcessUnmarshalErrors.Add(1)
+			continue
+		}
+		procs[proc.PID] = proc
+	}
+	for _, proc := range procs {
+		if proc.PID != proc.Ppid {
+			proc.Parent = procs[proc.Ppid]
+		}
+		r.psnapshotter.Put(proc)
+	}
+	return nil
+}
+
+func (r *reader) Done() <-chan struct{} { return r.done }
+
+func (r *reader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.zr != nil {
+		r.zr.Close()
+	}
+	if r.f != nil {
+		return r.f.Close()
+	}
+	return nil
+}
+
+func (r *reader) read(kevt *kevent.Kevent, keventsc chan *kevent.Kevent) {
+	if kevt.Type.OnlyState() {
+		return
+	}
+	if r.filter != nil && !r.filter.Run(kevt) {
+		kcapDroppedByFilter.Add(1)
+		return
+	}
+	keventsc <- kevt
+	kcapReadKevents.Add(1)
+}
+
+type reader struct {
+	zr           *zstd.Decoder
+	dec          *format.Decoder
+	f            *os.File
+	psnapshotter ps.Snapshotter
+	filter       filter.Filter
+	config       *config.Config
+	mu           sync.Mutex // guards the underlying zstd byte buffer
+	done         chan struct{}
+	// idx is the frame index. It is nil for captures prior to 2.1 version
+	idx *format.Index
+	// rng narrows down the events pulled from the capture
+	rng format.Range
+	handleState
+}
+
+func (r *reader) updateSnapshotters(kevt *kevent.Kevent) error {
+	switch kevt.Type {
+	case ktypes.TerminateProcess:
+		if err := r.psnapshotter.Remove(kevt); err != nil {
+			return err
+		}
+	case ktypes.TerminateThread:
+		pid := kevt.Kparams.MustGetPid()
+		tid := kevt.Kparams.MustGetTid()
+		if err := r.psnapshotter.RemoveThread(pid, tid); err != nil {
+			return err
+		}
+	case ktypes.UnloadImage:
+		pid := kevt.Kparams.MustGetPid()
+		addr := kevt.Kparams.TryGetAddress(kparams.ImageBase)
+		if err := r.psnapshotter.RemoveModule(pid, addr); err != nil {
+			return err
+		}
+	case ktypes.CreateProcess,
+		ktypes.ProcessRundown,
+		ktypes.LoadImage,
+		ktypes.ImageRundown,
+		ktypes.CreateThread,
+		ktypes.ThreadRundown:
+		if err := r.psnapshotter.WriteFromKcap(kevt); err != nil {
+			return err
+		}
+	case ktypes.CreateHandle, ktypes.CloseHandle:
+		if err := r.updateHandles(kevt); err != nil {
+			return err
+		}
+	}
+	if kevt.PS == nil {
+		_, kevt.PS = r.psnapshotter.Find(kevt.PID)
+	}
+	return nil
+}
+
+// recoverHandles decodes the handles from the handle section.
+func (r *reader) recoverHandles() ([]htypes.Handle, error) {
+	blocks, err := r.dec.ReadHandles()
+	if err != nil {
+		return nil, err
+	}
+	handles := make([]htypes.Handle, len(blocks))
+	for i, b := range blocks {
+		if b == nil {
+			continue
+		}
+		handles[i], err = htypes.NewFromKcap(b)
+		if err != nil {
+			kcapHandleUnmarshalErrors.Add(1)
+		}
+	}
+	return handles, nil
+}
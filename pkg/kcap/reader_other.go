@@ -0,0 +1,44 @@
+//go:build kcap && !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kcap
+
+import (
+	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+)
+
+// handleState is empty as the handle snapshotter
+// can only be restored on Windows.
+type handleState struct{}
+
+// updateHandles is a no-op. Handles created or closed in the
+// capture are not tracked outside the handle snapshotter.
+func (r *reader) updateHandles(kevt *kevent.Kevent) error { return nil }
+
+func (r *reader) RecoverSnapshotters() ([]htypes.Handle, ps.Snapshotter, error) {
+	handles, err := r.recoverHandles()
+	if err != nil {
+		return nil, nil, err
+	}
+	r.psnapshotter = ps.NewSnapshotterFromKcap()
+	return handles, r.psnapshotter, nil
+}
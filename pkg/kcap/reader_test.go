@@ -25,6 +25,9 @@ import (
 	"context"
 	"fmt"
 	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -63,3 +66,51 @@ func TestRead(t *testing.T) {
 		}
 	}
 }
+
+func TestReadRange(t *testing.T) {
+	readSeqs := func(rng format.Range) []uint64 {
+		r, err := NewReader("_fixtures/cap2.kcap", &config.Config{})
+		require.NoError(t, err)
+		defer r.Close()
+		_, _, err = r.RecoverSnapshotters()
+		require.NoError(t, err)
+		r.SetRange(rng)
+
+		kevtsc, _ := r.Read(context.Background())
+		<-r.Done()
+		seqs := make([]uint64, 0)
+		for len(kevtsc) > 0 {
+			seqs = append(seqs, (<-kevtsc).Seq)
+		}
+		return seqs
+	}
+
+	seqs := readSeqs(format.Range{})
+	require.True(t, len(seqs) > 20)
+
+	// captures prior to 2.1 are scanned sequentially
+	from, to := seqs[5], seqs[15]
+	for _, seq := range readSeqs(format.Range{FromSeq: from, ToSeq: to}) {
+		require.True(t, seq >= from && seq <= to)
+	}
+	require.Empty(t, readSeqs(format.Range{FromSeq: seqs[len(seqs)-1] + 1}))
+}
+
+func TestReadFilter(t *testing.T) {
+	r, err := NewReader("_fixtures/cap2.kcap", &config.Config{})
+	require.NoError(t, err)
+	defer r.Close()
+	_, _, err = r.RecoverSnapshotters()
+	require.NoError(t, err)
+
+	f := filter.New("kevt.category = 'file'", &config.Config{Filters: &config.Filters{}})
+	require.NoError(t, f.Compile())
+	r.SetFilter(f)
+
+	kevtsc, _ := r.Read(context.Background())
+	<-r.Done()
+	require.True(t, len(kevtsc) > 0)
+	for len(kevtsc) > 0 {
+		require.Equal(t, ktypes.File, (<-kevtsc).Category)
+	}
+}
@@ -0,0 +1,287 @@
+//go:build kcap
+// +build kcap
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kcap
+
+import (
+	"expvar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/handle"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
+	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	recorderDumps      = expvar.NewInt("kcap.recorder.dumps")
+	recorderDumpErrors = expvar.NewInt("kcap.recorder.dump.errors")
+	recorderEvicted    = expvar.NewInt("kcap.recorder.evicted.kevents")
+	recorderSkipped    = expvar.NewInt("kcap.recorder.skipped.dumps")
+	recorderRemoved    = expvar.NewInt("kcap.recorder.removed.dumps")
+)
+
+// dumpTimeFormat is the timestamp layout of capture dump file names
+const dumpTimeFormat = "20060102-150405.000"
+
+// unsafeChars matches characters not allowed in capture file names
+var unsafeChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// dumpName matches the names of capture files dumped by the recorder
+var dumpName = regexp.MustCompile(`^[a-zA-Z0-9._-]+-(\d{8}-\d{6}\.\d{3})\.kcap$`)
+
+// recorded is the serialized event retained by the flight recorder
+type recorded struct {
+	seq uint64
+	ts  int64
+	buf []byte
+}
+
+// dumpRequest is the capture handed over to the dump worker
+type dumpRequest struct {
+	filename string
+	evts     []recorded
+}
+
+type recorder struct {
+	mu sync.Mutex
+	// ring holds the retained events in arrival order
+	ring []recorded
+	// size is the total size of retained events
+	size int64
+
+	maxAge   time.Duration
+	maxSize  int64
+	path     string
+	cooldown time.Duration
+	maxDumps int
+
+	// lastDump is the timestamp of the alert that triggered the last dump
+	lastDump time.Time
+	// dumps receives the captures written by the dump worker
+	dumps  chan dumpRequest
+	closed bool
+
+	psnap ps.Snapshotter
+	hsnap handle.Snapshotter
+
+	// wg tracks the dump worker
+	wg sync.WaitGroup
+}
+
+// NewRecorder creates the flight recorder. The recorder is the event
+// listener that retains serialized events until they exceed the
+// maximum age or the maximum size of the recording window.
+func NewRecorder(c config.RecorderConfig, psnap ps.Snapshotter, hsnap handle.Snapshotter) (Recorder, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(c.Path, os.ModePerm); err != nil {
+		return nil, fmt.Errorf("unable to create flight recorder directory: %v", err)
+	}
+	r := &recorder{
+		ring:     make([]recorded, 0),
+		maxAge:   c.MaxAge,
+		maxSize:  int64(c.MaxSize) * 1024 * 1024,
+		path:     c.Path,
+		cooldown: c.Cooldown,
+		maxDumps: c.MaxDumps,
+		dumps:    make(chan dumpRequest, 1),
+		psnap:    psnap,
+		hsnap:    hsnap,
+	}
+	r.wg.Add(1)
+	go r.run()
+	return r, nil
+}
+
+func (r *recorder) ProcessEvent(e *kevent.Kevent) (bool, error) {
+	b := e.MarshalRaw()
+	if len(b) == 0 || len(b) > maxKevtSize {
+		return true, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ts := e.Timestamp.UnixNano()
+	r.ring = append(r.ring, recorded{seq: e.Seq, ts: ts, buf: b})
+	r.size += int64(len(b))
+	// evict the events that fall
+	// out of the recording window
+	var n int
+	for n < len(r.ring)-1 {
+		if r.size <= r.maxSize && (r.maxAge == 0 || ts-r.ring[n].ts <= r.maxAge.Nanoseconds()) {
+			break
+		}
+		r.size -= int64(len(r.ring[n].buf))
+		n++
+	}
+	if n > 0 {
+		// release the references of evicted events
+		clear(r.ring[:n])
+		r.ring = r.ring[n:]
+		recorderEvicted.Add(int64(n))
+	}
+	return true, nil
+}
+
+func (*recorder) CanEnqueue() bool { return false }
+
+func (r *recorder) Dump(name string, ts time.Time) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return "", fmt.Errorf("flight recorder is closed")
+	}
+	if len(r.ring) == 0 {
+		return "", fmt.Errorf("no events recorded")
+	}
+	if !r.lastDump.IsZero() && ts.Sub(r.lastDump) < r.cooldown {
+		recorderSkipped.Add(1)
+		return "", nil
+	}
+	filename := filepath.Join(r.path, fmt.Sprintf("%s-%s.kcap", unsafeChars.ReplaceAllString(name, "_"), ts.Format(dumpTimeFormat)))
+	evts := make([]recorded, len(r.ring))
+	copy(evts, r.ring)
+	select {
+	case r.dumps <- dumpRequest{filename: filename, evts: evts}:
+	default:
+		// the worker is still writing the previous captures
+		recorderSkipped.Add(1)
+		return "", nil
+	}
+	r.lastDump = ts
+	return filename, nil
+}
+
+// run is the dump worker. Captures are written one at a time, and the
+// snapshots of handles and processes are taken by the worker, so they
+// never hold up the event processing.
+func (r *recorder) run() {
+	defer r.wg.Done()
+	for req := range r.dumps {
+		handles, procs := marshalHandles(r.hsnap), marshalProcs(r.psnap)
+		if err := r.dump(req.filename, req.evts, handles, procs); err != nil {
+			recorderDumpErrors.Add(1)
+			log.Errorf("unable to dump flight recorder capture to %s: %v", req.filename, err)
+			continue
+		}
+		recorderDumps.Add(1)
+		log.Infof("flight recorder dumped %d event(s) to %s", len(req.evts), req.filename)
+		r.retain()
+	}
+}
+
+// retain removes the oldest capture dumps that exceed the maximum
+// number of dumps. Only files named by the recorder are considered.
+func (r *recorder) retain() {
+	if r.maxDumps == 0 {
+		return
+	}
+	entries, err := os.ReadDir(r.path)
+	if err != nil {
+		log.Warnf("unable to read flight recorder directory: %v", err)
+		return
+	}
+	type dump struct {
+		name string
+		ts   time.Time
+	}
+	dumps := make([]dump, 0, len(entries))
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		m := dumpName.FindStringSubmatch(e.Name())
+		if m == nil {
+			continue
+		}
+		ts, err := time.ParseInLocation(dumpTimeFormat, m[1], time.Local)
+		if err != nil {
+			continue
+		}
+		dumps = append(dumps, dump{e.Name(), ts})
+	}
+	if len(dumps) <= r.maxDumps {
+		return
+	}
+	sort.Slice(dumps, func(i, j int) bool { return dumps[i].ts.After(dumps[j].ts) })
+	for _, d := range dumps[r.maxDumps:] {
+		if err := os.Remove(filepath.Join(r.path, d.name)); err != nil {
+			log.Warnf("unable to remove flight recorder capture %s: %v", d.name, err)
+			continue
+		}
+		recorderRemoved.Add(1)
+	}
+}
+
+// dump writes the self-contained capture. The process
+// state checkpoint is written ahead of the events, so
+// readers recover the state of processes that were
+// started before the recording window. Note that the
+// checkpoint reflects the state at the time of the dump,
+// not at the start of the recording window, so processes
+// that exited in the meantime are absent from it.
+func (r *recorder) dump(filename string, evts []recorded, handles, procs [][]byte) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	fw := format.NewWriter(f, codec{}, flags)
+	if err := fw.WriteHandles(kcapver.HandleSecV1, handles); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := fw.WriteCheckpoint(kcapver.ProcessSecV4, procs); err != nil {
+		_ = f.Close()
+		return err
+	}
+	for _, evt := range evts {
+		if err := fw.WriteEvent(kcapver.KevtSecV2, evt.seq, evt.ts, evt.buf); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	if err := fw.Close(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (r *recorder) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.dumps)
+	}
+	r.mu.Unlock()
+	r.wg.Wait()
+	return nil
+}
@@ -0,0 +1,184 @@
+//go:build kcap
+// +build kcap
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kcap
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/handle"
+	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRecordedEvent(seq uint64, ts time.Time) *kevent.Kevent {
+	return &kevent.Kevent{
+		Type:      ktypes.CreateFile,
+		Tid:       2484,
+		PID:       2436,
+		Seq:       seq,
+		Name:      "CreateFile",
+		Timestamp: ts,
+		Category:  ktypes.File,
+		Host:      "archrabbit",
+		Kparams: kevent.Kparams{
+			kparams.FileObject:    {Name: kparams.FileObject, Type: kparams.Uint64, Value: uint64(12456738026482168384)},
+			kparams.FilePath:      {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: "\\Device\\HarddiskVolume2\\Windows\\system32\\user32.dll"},
+			kparams.FileType:      {Name: kparams.FileType, Type: kparams.AnsiString, Value: "file"},
+			kparams.FileOperation: {Name: kparams.FileOperation, Type: kparams.AnsiString, Value: "open"},
+		},
+		Metadata: make(map[kevent.MetadataKey]any),
+	}
+}
+
+func TestRecorderEviction(t *testing.T) {
+	psnap := new(ps.SnapshotterMock)
+	hsnap := new(handle.SnapshotterMock)
+	rec, err := NewRecorder(config.RecorderConfig{MaxAge: time.Minute, MaxSize: 1, MinSeverity: "high", Path: t.TempDir()}, psnap, hsnap)
+	require.NoError(t, err)
+	r := rec.(*recorder)
+
+	now := time.Now()
+	for i := 0; i < 10; i++ {
+		_, err := r.ProcessEvent(newRecordedEvent(uint64(i+1), now.Add(time.Duration(i)*time.Second)))
+		require.NoError(t, err)
+	}
+	require.Len(t, r.ring, 10)
+
+	// events older than the maximum age are evicted
+	_, err = r.ProcessEvent(newRecordedEvent(11, now.Add(time.Minute+time.Second*5)))
+	require.NoError(t, err)
+	require.Len(t, r.ring, 6)
+	assert.Equal(t, uint64(6), r.ring[0].seq)
+
+	// events exceeding the maximum size are evicted
+	r.maxSize = r.size
+	_, err = r.ProcessEvent(newRecordedEvent(12, now.Add(time.Minute+time.Second*6)))
+	require.NoError(t, err)
+	require.Len(t, r.ring, 6)
+	assert.Equal(t, uint64(7), r.ring[0].seq)
+}
+
+func TestRecorderDump(t *testing.T) {
+	psnap := new(ps.SnapshotterMock)
+	hsnap := new(handle.SnapshotterMock)
+
+	procs := []*pstypes.PS{
+		{PID: 2436, Ppid: 6304, Name: "firefox.exe", Exe: `C:\Program Files\Mozilla Firefox\firefox.exe`, Cwd: `C:\Program Files\Mozilla Firefox\`, SID: "archrabbit\\SYSTEM"},
+		{PID: 6304, Ppid: 4, Name: "explorer.exe", Exe: `C:\Windows\explorer.exe`, Cwd: `C:\Windows\`, SID: "archrabbit\\SYSTEM"},
+	}
+	psnap.On("GetSnapshot").Return(procs)
+	hsnap.On("GetSnapshot").Return([]htypes.Handle{{Pid: 2436, Name: "C:\\Windows", Type: "File"}})
+
+	dir := t.TempDir()
+	rec, err := NewRecorder(config.RecorderConfig{MaxAge: time.Minute, MaxSize: 16, MinSeverity: "high", Path: dir}, psnap, hsnap)
+	require.NoError(t, err)
+
+	now := time.Now()
+	for i := 0; i < 50; i++ {
+		_, err := rec.ProcessEvent(newRecordedEvent(uint64(i+1), now.Add(time.Duration(i)*time.Millisecond)))
+		require.NoError(t, err)
+	}
+
+	ts := time.Date(2024, 3, 1, 10, 15, 30, 0, time.Local)
+	path, err := rec.Dump("Suspicious LSASS: access", ts)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "Suspicious_LSASS_access-20240301-101530.000.kcap"), path)
+	require.NoError(t, rec.Close())
+
+	r, err := NewReader(path, &config.Config{})
+	require.NoError(t, err)
+	defer r.Close()
+	_, rpsnap, err := r.RecoverSnapshotters()
+	require.NoError(t, err)
+
+	kevtsc, _ := r.Read(context.Background())
+	<-r.Done()
+	require.Len(t, kevtsc, 50)
+
+	// the process state is restored from the checkpoint
+	ok, proc := rpsnap.Find(2436)
+	require.True(t, ok)
+	assert.Equal(t, "firefox.exe", proc.Name)
+	require.NotNil(t, proc.Parent)
+	assert.Equal(t, "explorer.exe", proc.Parent.Name)
+}
+
+func TestRecorderDumpCooldownAndRetention(t *testing.T) {
+	psnap := new(ps.SnapshotterMock)
+	hsnap := new(handle.SnapshotterMock)
+	psnap.On("GetSnapshot").Return([]*pstypes.PS{})
+	hsnap.On("GetSnapshot").Return([]htypes.Handle{})
+
+	dir := t.TempDir()
+	// the file that only looks like the capture dump is never removed
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes-20240301.kcap"), []byte{}, 0644))
+
+	rec, err := NewRecorder(config.RecorderConfig{MaxAge: time.Minute, MaxSize: 16, MinSeverity: "high", Path: dir, Cooldown: time.Minute, MaxDumps: 2}, psnap, hsnap)
+	require.NoError(t, err)
+	r := rec.(*recorder)
+
+	_, err = rec.ProcessEvent(newRecordedEvent(1, time.Now()))
+	require.NoError(t, err)
+
+	ts := time.Date(2024, 3, 1, 10, 15, 30, 0, time.Local)
+	var paths []string
+	for i := 0; i < 6; i++ {
+		path, err := rec.Dump("Suspicious LSASS access", ts.Add(time.Duration(i)*time.Second*40))
+		require.NoError(t, err)
+		if path != "" {
+			paths = append(paths, path)
+		}
+		// wait for the worker to pick up the capture
+		require.Eventually(t, func() bool { return len(r.dumps) == 0 }, time.Second*5, time.Millisecond*10)
+	}
+	require.NoError(t, rec.Close())
+
+	// dumps within the cooldown are skipped
+	require.Len(t, paths, 3)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.kcap"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		paths[1],
+		paths[2],
+		filepath.Join(dir, "notes-20240301.kcap"),
+	}, files)
+
+	_, err = rec.Dump("Suspicious LSASS access", ts.Add(time.Hour))
+	require.Error(t, err)
+}
+
+func TestRecorderInvalidSeverity(t *testing.T) {
+	_, err := NewRecorder(config.RecorderConfig{MaxAge: time.Minute, MaxSize: 16, MinSeverity: "hihg", Path: t.TempDir()}, new(ps.SnapshotterMock), new(handle.SnapshotterMock))
+	require.Error(t, err)
+}
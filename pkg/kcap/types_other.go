@@ -1,3 +1,6 @@
+//go:build !windows
+// +build !windows
+
 /*
  * Copyright 2020-2021 by Nedim Sabic Sabic
  * https://www.fibratus.io
@@ -18,6 +21,15 @@
 
 package kcap
 
+import (
+	"context"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+)
+
 type Writer interface {
 	// Write accepts two channels. The event channel receives events pushed by the kstream consumer. When the event
 	// is peeked from the channel, it is serialized and written to the underlying byte buffer.
@@ -26,13 +38,23 @@ type Writer interface {
 	Close() error
 }
 
-// Reader offers the mechanism for recovering the state of the kcapture and replaying all captured events.
+// Reader offers the mechanism for replaying captures taken on Windows. The process state is
+// restored exclusively from the capture and is never queried from the operating system.
 type Reader interface {
 	// Read returns two channels. The event channel is poplated with event instances pulled from the kcap. If
 	// any error occurs during kcap processing, it is pushed to the error channel.
 	Read(ctx context.Context) (chan *kevent.Kevent, chan error)
 	// Close shutdowns the reader gracefully.
 	Close() error
+	// RecoverSnapshotters decodes the handle section and returns the captured handles along with
+	// the read-only process snapshotter. It must be called before reading events from the kcap.
+	RecoverSnapshotters() ([]htypes.Handle, ps.Snapshotter, error)
 	// SetFilter sets the filter that's is applied to each event coming out of the kcap.
 	SetFilter(f filter.Filter)
+	// SetRange restricts the events coming out of the kcap to the given time and
+	// sequence bounds.
+	SetRange(rng format.Range)
+	// Done returns a channel that is closed when the reader stops pulling events
+	// from the kcap, either because all events were read or the context was canceled.
+	Done() <-chan struct{}
 }
@@ -22,24 +22,30 @@ import (
 	"context"
 	"github.com/rabbitstack/fibratus/pkg/filter"
 	"github.com/rabbitstack/fibratus/pkg/handle"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
 	"github.com/rabbitstack/fibratus/pkg/kevent"
 	"github.com/rabbitstack/fibratus/pkg/ps"
+	"time"
 )
 
 // Writer is the minimal interface that all kcap writers need to satisfy. The Windows kcap
-// file format has the layout as depicted in the following diagram:
+// file format has the layout as depicted in the following diagram. Each frame is compressed
+// independently, and checkpoint and index frames are zstd skippable frames:
 //
-//	 +-+-+-+-+-+-+-+-++-+-+-+-+-+-+-+-++-+-+-+
-//	 | Magic Number  | Major | Minor | Flags |
-//		|----------------------------------------
-//	 | Handle Section |       Handles        |
-//	 -----------------------------------------
-//	 | Kevt Section | Kevt ..................|
-//		| ......................................|
-//		| ......................................|
-//		| ......................................|
-//	 | ........ Kevt Section n  Kevt n  EOF  |
-//	 +-+-+-+-+-+-+-+-++-+-+-+-+-+-+-+-++-+-+-+
+//	+-+-+-+-+-+-+-+-++-+-+-+-+-+-+-+-++-+-+-+
+//	| Magic Number  | Major | Minor | Flags |   frame 0
+//	|----------------------------------------
+//	| Handle Section |       Handles        |
+//	-----------------------------------------
+//	| Kevt Section | Kevt ..................|   frame 1
+//	| ........ Kevt Section n  Kevt n       |
+//	-----------------------------------------
+//	| Process Section | Processes ..........|   checkpoint
+//	-----------------------------------------
+//	| Kevt Section | Kevt ..................|   frame n
+//	-----------------------------------------
+//	| Frame index entries | Offset | Trailer|   index
+//	+-+-+-+-+-+-+-+-++-+-+-+-+-+-+-+-++-+-+-+
 type Writer interface {
 	// Write accepts two channels. The event channel receives events pushed by the event consumer.
 	// When the event is peeked from the channel, it is serialized and written to the underlying
@@ -60,4 +66,28 @@ type Reader interface {
 	RecoverSnapshotters() (handle.Snapshotter, ps.Snapshotter, error)
 	// SetFilter sets the filter applied to each event coming out of the kcap.
 	SetFilter(f filter.Filter)
+	// SetRange restricts the events coming out of the kcap to the given time and
+	// sequence bounds. Indexed captures are read starting from the frame nearest
+	// to the range, while older captures are scanned from the start.
+	SetRange(rng format.Range)
+	// Done returns a channel that is closed when the reader stops pulling events
+	// from the kcap, either because all events were read or the context was canceled.
+	// Events read until that point remain buffered in the event channel.
+	Done() <-chan struct{}
+}
+
+// Recorder continuously records the most recent events. It is registered as the event
+// listener and retains events within the recording window that is bounded by the age
+// and the size of recorded events.
+type Recorder interface {
+	kevent.Listener
+	// Dump persists recorded events along with the state of processes and handles in
+	// the self-contained capture file. The file is named after the given name and the
+	// timestamp. It returns the path of the capture file that is written asynchronously
+	// by the dump worker. The state of processes and handles is taken when the worker
+	// writes the capture. The path is empty if the dump is skipped because it falls
+	// within the cooldown, or the worker is still busy writing the previous capture.
+	Dump(name string, ts time.Time) (string, error)
+	// Close waits for pending dumps to complete.
+	Close() error
 }
@@ -23,22 +23,22 @@ package kcap
 
 import (
 	"expvar"
-	"fmt"
-	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
 	"math"
 )
 
 var (
 	// ErrWriteMagic signals magic write errors
-	ErrWriteMagic = func(err error) error { return fmt.Errorf("couldn't write magic number: %v", err) }
+	ErrWriteMagic = format.ErrWriteMagic
 	// ErrWriteVersion signals version write errors
-	ErrWriteVersion = func(v string, err error) error { return fmt.Errorf("couldn't write %s kcap digit: %v", v, err) }
+	ErrWriteVersion = format.ErrWriteVersion
 	// ErrWriteSection signals section write errors
-	ErrWriteSection = func(s section.Type, err error) error { return fmt.Errorf("couldn't write %s kcap section: %v", s, err) }
+	ErrWriteSection = format.ErrWriteSection
 
 	handleWriteErrors     = expvar.NewInt("kcap.handle.write.errors")
 	kevtWriteErrors       = expvar.NewInt("kcap.kevt.write.errors")
 	flusherErrors         = expvar.NewMap("kcap.flusher.errors")
+	checkpointErrors      = expvar.NewInt("kcap.checkpoint.errors")
 	overflowKevents       = expvar.NewInt("kcap.overflow.kevents")
 	kstreamConsumerErrors = expvar.NewInt("kcap.kstream.consumer.errors")
 )
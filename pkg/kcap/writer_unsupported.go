@@ -1,5 +1,5 @@
-//go:build !kcap
-// +build !kcap
+//go:build windows && !kcap
+// +build windows,!kcap
 
 /*
  * Copyright 2019-2020 by Nedim Sabic Sabic
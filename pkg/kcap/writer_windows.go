@@ -32,12 +32,10 @@ import (
 	"github.com/dustin/go-humanize"
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/rabbitstack/fibratus/pkg/handle"
-	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+	"github.com/rabbitstack/fibratus/pkg/kcap/format"
 	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
 	"github.com/rabbitstack/fibratus/pkg/kevent"
 	"github.com/rabbitstack/fibratus/pkg/ps"
-	"github.com/rabbitstack/fibratus/pkg/util/bytes"
-	zstd "github.com/valyala/gozstd"
 )
 
 type stats struct {
@@ -86,8 +84,11 @@ func (s *stats) printStats() {
 	t.Render()
 }
 
+// checkpointPeriod determines how often the process state checkpoint is written
+const checkpointPeriod = time.Minute
+
 type writer struct {
-	zw      *zstd.Writer
+	fw      *format.Writer
 	f       *os.File
 	flusher *time.Ticker
 	psnap   ps.Snapshotter
@@ -95,10 +96,12 @@ type writer struct {
 	stop    chan struct{}
 	// stats contains the capture statistics
 	stats *stats
-	// mu protects the underlying zstd buffer
+	// mu protects the frame writer
 	mu sync.Mutex
-	// released indicates if the zstd buffer is disposed
-	released atomic.Bool
+	// closed indicates if the frame writer is finalized
+	closed bool
+	// checkpoint is the time of the last process state checkpoint
+	checkpoint time.Time
 }
 
 // NewWriter constructs a new instance of the kcap writer.
@@ -110,7 +113,6 @@ func NewWriter(filename string, psnap ps.Snapshotter, hsnap handle.Snapshotter)
 	if err != nil {
 		return nil, err
 	}
-	zw := zstd.NewWriter(f)
 	// start by writing the kcap header that is composed
 	// of magic number, major/minor digits and the optional
 	// flags bit vector. The flags bit vector is reserved
@@ -122,30 +124,19 @@ func NewWriter(filename string, psnap ps.Snapshotter, hsnap handle.Snapshotter)
 	// that describes the version and the number of handles
 	// in the snapshot. This information is used by the reader to
 	// restore the state of the snapshotters.
-	if _, err := zw.Write(bytes.WriteUint64(magic)); err != nil {
-		return nil, ErrWriteMagic(err)
-	}
-	if _, err := zw.Write([]byte{major}); err != nil {
-		return nil, ErrWriteVersion("major", err)
-	}
-	if _, err := zw.Write([]byte{minor}); err != nil {
-		return nil, ErrWriteVersion("minor", err)
-	}
-	if _, err := zw.Write(bytes.WriteUint64(flags)); err != nil {
-		return nil, err
-	}
-
 	w := &writer{
-		zw:      zw,
-		f:       f,
-		flusher: time.NewTicker(time.Second),
-		psnap:   psnap,
-		hsnap:   hsnap,
-		stop:    make(chan struct{}),
-		stats:   &stats{kcapFile: filename},
+		fw:         format.NewWriter(f, codec{}, flags),
+		f:          f,
+		flusher:    time.NewTicker(time.Second),
+		psnap:      psnap,
+		hsnap:      hsnap,
+		stop:       make(chan struct{}),
+		stats:      &stats{kcapFile: filename},
+		checkpoint: time.Now(),
 	}
 
 	if err := w.writeSnapshots(); err != nil {
+		_ = f.Close()
 		return nil, err
 	}
 
@@ -155,32 +146,16 @@ func NewWriter(filename string, psnap ps.Snapshotter, hsnap handle.Snapshotter)
 }
 
 func (w *writer) writeSnapshots() error {
-	handles := w.hsnap.GetSnapshot()
+	blocks := marshalHandles(w.hsnap)
 	// write handle section and the data blocks
-	err := w.ws(section.Handle, kcapver.HandleSecV1, uint32(len(handles)), 0)
-	if err != nil {
+	if err := w.fw.WriteHandles(kcapver.HandleSecV1, blocks); err != nil {
+		handleWriteErrors.Add(int64(len(blocks)))
 		return err
 	}
-
-	writeHandle := func(buf []byte) error {
-		l := bytes.WriteUint16(uint16(len(buf)))
-		if _, err := w.zw.Write(l); err != nil {
-			return err
-		}
-		if _, err := w.zw.Write(buf); err != nil {
-			return err
-		}
-		return nil
-	}
-
-	for _, khandle := range handles {
-		if err := writeHandle(khandle.Marshal()); err != nil {
-			handleWriteErrors.Add(1)
-			continue
-		}
+	for range blocks {
 		w.stats.incHandles()
 	}
-	return w.zw.Flush()
+	return nil
 }
 
 func (w *writer) Write(kevtsc <-chan *kevent.Kevent, errs <-chan error) chan error {
@@ -195,7 +170,7 @@ func (w *writer) Write(kevtsc <-chan *kevent.Kevent, errs <-chan error) chan err
 					continue
 				}
 				// write event buffer
-				err := w.write(b)
+				err := w.write(kevt, b)
 				if err != nil {
 					errsc <- err
 					continue
@@ -215,7 +190,7 @@ func (w *writer) Write(kevtsc <-chan *kevent.Kevent, errs <-chan error) chan err
 	return errsc
 }
 
-func (w *writer) write(b []byte) error {
+func (w *writer) write(kevt *kevent.Kevent, b []byte) error {
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	l := len(b)
@@ -223,11 +198,10 @@ func (w *writer) write(b []byte) error {
 		overflowKevents.Add(1)
 		return fmt.Errorf("event size overflow by %d bytes", l-maxKevtSize)
 	}
-	if err := w.ws(section.Kevt, kcapver.KevtSecV2, 0, uint32(l)); err != nil {
-		kevtWriteErrors.Add(1)
-		return err
+	if w.closed {
+		return nil
 	}
-	if _, err := w.zw.Write(b); err != nil {
+	if err := w.fw.WriteEvent(kcapver.KevtSecV2, kevt.Seq, kevt.Timestamp.UnixNano(), b); err != nil {
 		kevtWriteErrors.Add(1)
 		return err
 	}
@@ -235,42 +209,77 @@ func (w *writer) write(b []byte) error {
 }
 
 func (w *writer) Close() error {
-	w.stats.printStats()
-
 	close(w.stop)
 
 	w.flusher.Stop()
 	w.mu.Lock()
 	defer w.mu.Unlock()
 
-	if w.zw != nil {
-		if err := w.zw.Close(); err != nil {
+	if !w.closed {
+		// flush the pending frame and the index
+		w.closed = true
+		if err := w.fw.Close(); err != nil {
+			_ = w.f.Close()
 			return err
 		}
-		w.zw.Release()
-		w.released.Store(true)
 	}
+	defer w.stats.printStats()
 	if w.f != nil {
 		return w.f.Close()
 	}
 	return nil
 }
 
+// flush periodically closes the current frame, so the events are
+// committed to the capture file. Every checkpoint period, the state
+// of all processes is written to allow readers to seek into the
+// capture without replaying all prior process events.
 func (w *writer) flush() {
 	for {
 		select {
 		case <-w.flusher.C:
-			if w.released.Load() {
+			w.mu.Lock()
+			if w.closed {
+				w.mu.Unlock()
 				return
 			}
-			w.mu.Lock()
-			err := w.zw.Flush()
-			w.mu.Unlock()
+			err := w.fw.CloseFrame()
 			if err != nil {
 				flusherErrors.Add(err.Error(), 1)
 			}
+			if time.Since(w.checkpoint) >= checkpointPeriod {
+				if err := w.writeCheckpoint(); err != nil {
+					checkpointErrors.Add(1)
+				}
+			}
+			w.mu.Unlock()
 		case <-w.stop:
 			return
 		}
 	}
 }
+
+func (w *writer) writeCheckpoint() error {
+	w.checkpoint = time.Now()
+	return w.fw.WriteCheckpoint(kcapver.ProcessSecV4, marshalProcs(w.psnap))
+}
+
+// marshalHandles serializes all handles from the handle snapshotter.
+func marshalHandles(hsnap handle.Snapshotter) [][]byte {
+	handles := hsnap.GetSnapshot()
+	blocks := make([][]byte, 0, len(handles))
+	for _, khandle := range handles {
+		blocks = append(blocks, khandle.Marshal())
+	}
+	return blocks
+}
+
+// marshalProcs serializes all processes from the process snapshotter.
+func marshalProcs(psnap ps.Snapshotter) [][]byte {
+	procs := psnap.GetSnapshot()
+	blocks := make([][]byte, 0, len(procs))
+	for _, proc := range procs {
+		blocks = append(blocks, proc.Marshal())
+	}
+	return blocks
+}
@@ -18,6 +18,14 @@
 
 package kevent
 
+import (
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/kcap/section"
+	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
+	ptypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/util/bytes"
+)
+
 // Batch contains a sequence of kernel events.
 type Batch struct {
 	Events []*Kevent
@@ -49,3 +57,93 @@ func (b *Batch) MarshalJSON() []byte {
 	buf = append(buf, ']')
 	return buf
 }
+
+// MarshalRaw produces a byte stream of the batch suitable for writing to disk.
+// Each event is followed by the state of the originating process and its parent,
+// so the events restored from the byte stream carry the full process context.
+func (b *Batch) MarshalRaw() []byte {
+	buf := make([]byte, 0)
+	buf = append(buf, bytes.WriteUint32(uint32(len(b.Events)))...)
+	for _, e := range b.Events {
+		raw := e.MarshalRaw()
+		sec := section.New(section.Kevt, kcapver.KevtSecV2, 0, uint32(len(raw)))
+		buf = append(buf, sec[:]...)
+		buf = append(buf, raw...)
+
+		var ps, parent *ptypes.PS
+		if e.PS != nil {
+			ps, parent = e.PS, e.PS.Parent
+		}
+		buf = appendProcess(buf, ps)
+		buf = appendProcess(buf, parent)
+	}
+	return buf
+}
+
+func appendProcess(buf []byte, ps *ptypes.PS) []byte {
+	if ps == nil {
+		sec := section.New(section.Process, kcapver.ProcessSecV4, 0, 0)
+		return append(buf, sec[:]...)
+	}
+	raw := ps.Marshal()
+	sec := section.New(section.Process, kcapver.ProcessSecV4, 0, uint32(len(raw)))
+	buf = append(buf, sec[:]...)
+	return append(buf, raw...)
+}
+
+// NewBatchFromRaw recovers the batch of events from the byte stream produced by MarshalRaw.
+func NewBatchFromRaw(buf []byte) (*Batch, error) {
+	if len(buf) < 4 {
+		return nil, fmt.Errorf("expected at least 4 bytes but got %d bytes", len(buf))
+	}
+	n := bytes.ReadUint32(buf)
+	off := 4
+	evts := make([]*Kevent, 0, n)
+
+	readSection := func() (section.Section, []byte, error) {
+		if len(buf)-off < len(section.Section{}) {
+			return section.Section{}, nil, fmt.Errorf("truncated section at offset %d", off)
+		}
+		sec := section.Read(buf[off:])
+		off += len(sec)
+		size := int(sec.Size())
+		if len(buf)-off < size {
+			return section.Section{}, nil, fmt.Errorf("truncated %s section at offset %d", sec, off)
+		}
+		b := buf[off : off+size]
+		off += size
+		return sec, b, nil
+	}
+
+	for i := uint32(0); i < n; i++ {
+		sec, b, err := readSection()
+		if err != nil {
+			return nil, err
+		}
+		e, err := NewFromKcap(b, sec.Version())
+		if err != nil {
+			return nil, err
+		}
+		var procs [2]*ptypes.PS
+		for j := range procs {
+			sec, b, err := readSection()
+			if err != nil {
+				return nil, err
+			}
+			if sec.Size() == 0 {
+				continue
+			}
+			procs[j], err = ptypes.NewFromKcap(b, sec)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ps := procs[0]; ps != nil {
+			ps.Parent = procs[1]
+			e.PS = ps
+		}
+		evts = append(evts, e)
+	}
+
+	return NewBatch(evts...), nil
+}
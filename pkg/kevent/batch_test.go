@@ -27,7 +27,6 @@ import (
 	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
 	"github.com/rabbitstack/fibratus/pkg/util/va"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/sys/windows"
 	"testing"
 	"time"
 )
@@ -69,14 +68,14 @@ func TestBatchMarshalJSON(t *testing.T) {
 				3455: {Tid: 3455, StartAddress: va.Address(140729524944768), IOPrio: 3, PagePrio: 5, KstackBase: va.Address(18446677035730165760), KstackLimit: va.Address(18446677035730137088), UstackLimit: va.Address(86376448), UstackBase: va.Address(86372352)},
 			},
 			Handles: []htypes.Handle{
-				{Num: windows.Handle(0xffffd105e9baaf70),
+				{Num: htypes.ID(0xffffd105e9baaf70),
 					Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 					Type:   "Key",
 					Object: 777488883434455544,
 					Pid:    uint32(1023),
 				},
 				{
-					Num:  windows.Handle(0xffffd105e9adaf70),
+					Num:  htypes.ID(0xffffd105e9adaf70),
 					Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 					Type: "ALPC Port",
 					Pid:  uint32(1023),
@@ -88,7 +87,7 @@ func TestBatchMarshalJSON(t *testing.T) {
 					Object: 457488883434455544,
 				},
 				{
-					Num:  windows.Handle(0xeaffd105e9adaf30),
+					Num:  htypes.ID(0xeaffd105e9adaf30),
 					Name: `C:\Users\bunny`,
 					Type: "File",
 					Pid:  uint32(1023),
@@ -137,14 +136,14 @@ func TestBatchMarshalJSON(t *testing.T) {
 				3455: {Tid: 3455, StartAddress: va.Address(140729524944768), IOPrio: 3, PagePrio: 5, KstackBase: va.Address(18446677035730165760), KstackLimit: va.Address(18446677035730137088), UstackLimit: va.Address(86376448), UstackBase: va.Address(86372352)},
 			},
 			Handles: []htypes.Handle{
-				{Num: windows.Handle(0xffffd105e9baaf70),
+				{Num: htypes.ID(0xffffd105e9baaf70),
 					Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 					Type:   "Key",
 					Object: 777488883434455544,
 					Pid:    uint32(1023),
 				},
 				{
-					Num:  windows.Handle(0xffffd105e9adaf70),
+					Num:  htypes.ID(0xffffd105e9adaf70),
 					Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 					Type: "ALPC Port",
 					Pid:  uint32(1023),
@@ -156,7 +155,7 @@ func TestBatchMarshalJSON(t *testing.T) {
 					Object: 457488883434455544,
 				},
 				{
-					Num:  windows.Handle(0xeaffd105e9adaf30),
+					Num:  htypes.ID(0xeaffd105e9adaf30),
 					Name: `C:\Users\bunny`,
 					Type: "File",
 					Pid:  uint32(1023),
@@ -205,14 +204,14 @@ func TestBatchMarshalJSON(t *testing.T) {
 				3455: {Tid: 3455, StartAddress: va.Address(140729524944768), IOPrio: 3, PagePrio: 5, KstackBase: va.Address(18446677035730165760), KstackLimit: va.Address(18446677035730137088), UstackLimit: va.Address(86376448), UstackBase: va.Address(86372352)},
 			},
 			Handles: []htypes.Handle{
-				{Num: windows.Handle(0xffffd105e9baaf70),
+				{Num: htypes.ID(0xffffd105e9baaf70),
 					Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 					Type:   "Key",
 					Object: 777488883434455544,
 					Pid:    uint32(1023),
 				},
 				{
-					Num:  windows.Handle(0xffffd105e9adaf70),
+					Num:  htypes.ID(0xffffd105e9adaf70),
 					Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 					Type: "ALPC Port",
 					Pid:  uint32(1023),
@@ -224,7 +223,7 @@ func TestBatchMarshalJSON(t *testing.T) {
 					Object: 457488883434455544,
 				},
 				{
-					Num:  windows.Handle(0xeaffd105e9adaf30),
+					Num:  htypes.ID(0xeaffd105e9adaf30),
 					Name: `C:\Users\bunny`,
 					Type: "File",
 					Pid:  uint32(1023),
@@ -251,3 +250,72 @@ func TestBatchMarshalJSON(t *testing.T) {
 	assert.Equal(t, uint32(459), kevts[1].PID)
 	assert.Equal(t, uint32(829), kevts[2].PID)
 }
+
+func TestBatchMarshalRaw(t *testing.T) {
+	kevt := &Kevent{
+		Type:        ktypes.CreateFile,
+		Tid:         2484,
+		PID:         859,
+		CPU:         1,
+		Seq:         2,
+		Name:        "CreateFile",
+		Timestamp:   time.Now(),
+		Category:    ktypes.File,
+		Host:        "archrabbit",
+		Description: "Creates or opens a new file, directory, I/O device, pipe, console",
+		Kparams: Kparams{
+			kparams.FilePath:      {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: "\\Device\\HarddiskVolume2\\Windows\\system32\\user32.dll"},
+			kparams.FileOperation: {Name: kparams.FileOperation, Type: kparams.AnsiString, Value: "open"},
+		},
+		Metadata: map[MetadataKey]any{"foo": "bar"},
+		PS: &pstypes.PS{
+			PID:     2436,
+			Ppid:    6304,
+			Name:    "firefox.exe",
+			Exe:     `C:\Program Files\Mozilla Firefox\firefox.exe`,
+			Cmdline: `C:\Program Files\Mozilla Firefox\firefox.exe -contentproc`,
+			SID:     "S-1-1-18",
+			Envs:    map[string]string{},
+			Parent: &pstypes.PS{
+				PID:  6304,
+				Ppid: 1022,
+				Name: "explorer.exe",
+				Exe:  `C:\Windows\explorer.exe`,
+				SID:  "S-1-1-18",
+				Envs: map[string]string{},
+			},
+		},
+	}
+	kevt1 := &Kevent{
+		Type:      ktypes.TerminateThread,
+		Tid:       2484,
+		PID:       859,
+		Seq:       3,
+		Name:      "TerminateThread",
+		Timestamp: time.Now(),
+		Category:  ktypes.Thread,
+		Host:      "archrabbit",
+		Kparams:   Kparams{},
+		Metadata:  map[MetadataKey]any{},
+	}
+
+	b, err := NewBatchFromRaw(NewBatch(kevt, kevt1).MarshalRaw())
+	require.NoError(t, err)
+	require.Len(t, b.Events, 2)
+
+	e := b.Events[0]
+	assert.Equal(t, uint64(2), e.Seq)
+	assert.Equal(t, "CreateFile", e.Name)
+	assert.Equal(t, "open", e.GetParamAsString(kparams.FileOperation))
+	require.NotNil(t, e.PS)
+	assert.Equal(t, "firefox.exe", e.PS.Name)
+	assert.Equal(t, `C:\Program Files\Mozilla Firefox\firefox.exe -contentproc`, e.PS.Cmdline)
+	require.NotNil(t, e.PS.Parent)
+	assert.Equal(t, "explorer.exe", e.PS.Parent.Name)
+
+	assert.Equal(t, uint64(3), b.Events[1].Seq)
+	assert.Equal(t, (*pstypes.PS)(nil), b.Events[1].PS)
+
+	_, err = NewBatchFromRaw([]byte{1, 0, 0, 0, 10})
+	require.Error(t, err)
+}
@@ -18,10 +18,7 @@
 
 package kevent
 
-import (
-	"github.com/rabbitstack/fibratus/pkg/util/va"
-	"golang.org/x/sys/windows"
-)
+import "github.com/rabbitstack/fibratus/pkg/util/va"
 
 // ViewSectionTypes describes possible values for process mapped sections.
 var ViewSectionTypes = ParamEnum{
@@ -34,83 +31,83 @@ var ViewSectionTypes = ParamEnum{
 
 // DNSRecordTypes describes DNS record type values.
 var DNSRecordTypes = ParamEnum{
-	windows.DNS_TYPE_A:       "A",
-	windows.DNS_TYPE_NS:      "NS",
-	windows.DNS_TYPE_MD:      "MD",
-	windows.DNS_TYPE_MF:      "MF",
-	windows.DNS_TYPE_CNAME:   "CNAME",
-	windows.DNS_TYPE_SOA:     "SOA",
-	windows.DNS_TYPE_MB:      "MB",
-	windows.DNS_TYPE_MG:      "MG",
-	windows.DNS_TYPE_MR:      "MR",
-	windows.DNS_TYPE_NULL:    "NULL",
-	windows.DNS_TYPE_WKS:     "WKS",
-	windows.DNS_TYPE_PTR:     "PTR",
-	windows.DNS_TYPE_HINFO:   "HINFO",
-	windows.DNS_TYPE_MINFO:   "MINFO",
-	windows.DNS_TYPE_MX:      "MX",
-	windows.DNS_TYPE_TEXT:    "TEXT",
-	windows.DNS_TYPE_RP:      "RP",
-	windows.DNS_TYPE_AFSDB:   "AFSDB",
-	windows.DNS_TYPE_X25:     "X25",
-	windows.DNS_TYPE_ISDN:    "ISDN",
-	windows.DNS_TYPE_NSAPPTR: "NSAPPTR",
-	windows.DNS_TYPE_SIG:     "SIG",
-	windows.DNS_TYPE_KEY:     "KEY",
-	windows.DNS_TYPE_PX:      "PX",
-	windows.DNS_TYPE_GPOS:    "GPOS",
-	windows.DNS_TYPE_AAAA:    "AAAA",
-	windows.DNS_TYPE_LOC:     "LOC",
-	windows.DNS_TYPE_NXT:     "NXT",
-	windows.DNS_TYPE_EID:     "EID",
-	windows.DNS_TYPE_NIMLOC:  "NIMLOC",
-	windows.DNS_TYPE_SRV:     "SRV",
-	windows.DNS_TYPE_ATMA:    "ATMA",
-	windows.DNS_TYPE_NAPTR:   "NAPTR",
-	windows.DNS_TYPE_KX:      "KX",
-	windows.DNS_TYPE_CERT:    "CERT",
-	windows.DNS_TYPE_A6:      "A6",
-	windows.DNS_TYPE_DNAME:   "DNAME",
-	windows.DNS_TYPE_SINK:    "SINK",
-	windows.DNS_TYPE_OPT:     "OPT",
-	windows.DNS_TYPE_DS:      "DS",
-	windows.DNS_TYPE_RRSIG:   "RRSIG",
-	windows.DNS_TYPE_NSEC:    "NSEC",
-	windows.DNS_TYPE_DNSKEY:  "DNSKEY",
-	windows.DNS_TYPE_DHCID:   "DHCID",
-	windows.DNS_TYPE_UINFO:   "UINFO",
-	windows.DNS_TYPE_UID:     "UID",
-	windows.DNS_TYPE_GID:     "GID",
-	windows.DNS_TYPE_UNSPEC:  "UNSPEC",
-	windows.DNS_TYPE_ADDRS:   "ADDRS",
-	windows.DNS_TYPE_TKEY:    "TKEY",
-	windows.DNS_TYPE_TSIG:    "TSIG",
-	windows.DNS_TYPE_IXFR:    "IXFR",
-	windows.DNS_TYPE_AXFR:    "AXFR",
-	windows.DNS_TYPE_MAILB:   "MAILB",
-	windows.DNS_TYPE_MAILA:   "MAILA",
-	windows.DNS_TYPE_ANY:     "ANY",
-	windows.DNS_TYPE_WINS:    "WINS",
-	windows.DNS_TYPE_WINSR:   "WINSR",
+	1:     "A",
+	2:     "NS",
+	3:     "MD",
+	4:     "MF",
+	5:     "CNAME",
+	6:     "SOA",
+	7:     "MB",
+	8:     "MG",
+	9:     "MR",
+	10:    "NULL",
+	11:    "WKS",
+	12:    "PTR",
+	13:    "HINFO",
+	14:    "MINFO",
+	15:    "MX",
+	16:    "TEXT",
+	17:    "RP",
+	18:    "AFSDB",
+	19:    "X25",
+	20:    "ISDN",
+	23:    "NSAPPTR",
+	24:    "SIG",
+	25:    "KEY",
+	26:    "PX",
+	27:    "GPOS",
+	28:    "AAAA",
+	29:    "LOC",
+	30:    "NXT",
+	31:    "EID",
+	32:    "NIMLOC",
+	33:    "SRV",
+	34:    "ATMA",
+	35:    "NAPTR",
+	36:    "KX",
+	37:    "CERT",
+	38:    "A6",
+	39:    "DNAME",
+	40:    "SINK",
+	41:    "OPT",
+	43:    "DS",
+	46:    "RRSIG",
+	47:    "NSEC",
+	48:    "DNSKEY",
+	49:    "DHCID",
+	100:   "UINFO",
+	101:   "UID",
+	102:   "GID",
+	103:   "UNSPEC",
+	248:   "ADDRS",
+	249:   "TKEY",
+	250:   "TSIG",
+	251:   "IXFR",
+	252:   "AXFR",
+	253:   "MAILB",
+	254:   "MAILA",
+	255:   "ANY",
+	65281: "WINS",
+	65282: "WINSR",
 }
 
 // DNSResponseCodes describes DNS response codes.
 var DNSResponseCodes = ParamEnum{
-	uint32(windows.DNS_ERROR_RCODE_NO_ERROR):        "NOERROR",
-	uint32(windows.DNS_ERROR_RCODE_FORMAT_ERROR):    "FORMERR",
-	uint32(windows.DNS_ERROR_RCODE_SERVER_FAILURE):  "SERVFAIL",
-	uint32(windows.DNS_ERROR_RCODE_NAME_ERROR):      "NXDOMAIN",
-	uint32(windows.DNS_ERROR_RCODE_NOT_IMPLEMENTED): "NOTIMP",
-	uint32(windows.DNS_ERROR_RCODE_REFUSED):         "REFUSED",
-	uint32(windows.DNS_ERROR_RCODE_YXDOMAIN):        "YXDOMAIN",
-	uint32(windows.DNS_ERROR_RCODE_YXRRSET):         "YXRRSET",
-	uint32(windows.DNS_ERROR_RCODE_NXRRSET):         "NXRRSET",
-	uint32(windows.DNS_ERROR_RCODE_NOTAUTH):         "NOTAUTH",
-	uint32(windows.DNS_ERROR_RCODE_NOTZONE):         "NOTZONE",
-	uint32(windows.DNS_ERROR_RCODE_BADSIG):          "BADSIG",
-	uint32(windows.DNS_ERROR_RCODE_BADKEY):          "BADKEY",
-	uint32(windows.DNS_ERROR_RCODE_BADTIME):         "BADTIME",
-	uint32(windows.DNS_ERROR_INVALID_NAME):          "BADNAME",
-	uint32(windows.ERROR_INVALID_PARAMETER):         "INVALID",
-	uint32(windows.DNS_INFO_NO_RECORDS):             "NXDOMAIN",
+	0:    "NOERROR",
+	9001: "FORMERR",
+	9002: "SERVFAIL",
+	9003: "NXDOMAIN",
+	9004: "NOTIMP",
+	9005: "REFUSED",
+	9006: "YXDOMAIN",
+	9007: "YXRRSET",
+	9008: "NXRRSET",
+	9009: "NOTAUTH",
+	9010: "NOTZONE",
+	9016: "BADSIG",
+	9017: "BADKEY",
+	9018: "BADTIME",
+	123:  "BADNAME",
+	87:   "INVALID",
+	9501: "NXDOMAIN",
 }
@@ -19,8 +19,6 @@
 package kevent
 
 import (
-	"github.com/rabbitstack/fibratus/pkg/sys"
-	"golang.org/x/sys/windows"
 	"strings"
 )
 
@@ -78,156 +76,156 @@ var PsCreationFlags = []ParamFlag{
 }
 
 // AllAccess represents the maximum process/thread access right
-const AllAccess = windows.STANDARD_RIGHTS_REQUIRED | windows.SYNCHRONIZE | 0xFFFF
+const AllAccess = 0x001FFFFF
 
 // PsAccessRightFlags describes flags for the process access rights.
 var PsAccessRightFlags = []ParamFlag{
 	{"ALL_ACCESS", AllAccess},
-	{"DELETE", windows.DELETE},
-	{"READ_CONTROL", windows.READ_CONTROL},
-	{"SYNCHRONIZE", windows.SYNCHRONIZE},
-	{"WRITE_DAC", windows.WRITE_DAC},
-	{"WRITE_OWNER", windows.WRITE_OWNER},
-	{"GENERIC_READ", windows.GENERIC_READ},
-	{"ACCESS_SYSTEM_SECURITY", windows.ACCESS_SYSTEM_SECURITY},
-	{"TERMINATE", windows.PROCESS_TERMINATE},
-	{"CREATE_THREAD", windows.PROCESS_CREATE_THREAD},
-	{"VM_OPERATION", windows.PROCESS_VM_OPERATION},
-	{"VM_READ", windows.PROCESS_VM_READ},
-	{"VM_WRITE", windows.PROCESS_VM_WRITE},
-	{"DUP_HANDLE", windows.PROCESS_DUP_HANDLE},
-	{"CREATE_PROCESS", windows.PROCESS_CREATE_PROCESS},
-	{"SET_QUOTA", windows.PROCESS_SET_QUOTA},
-	{"SET_INFORMATION", windows.PROCESS_SET_INFORMATION},
-	{"QUERY_INFORMATION", windows.PROCESS_QUERY_INFORMATION},
-	{"SUSPEND_RESUME", windows.PROCESS_SUSPEND_RESUME},
-	{"QUERY_LIMITED_INFORMATION", windows.PROCESS_QUERY_LIMITED_INFORMATION},
+	{"DELETE", 0x00010000},
+	{"READ_CONTROL", 0x00020000},
+	{"SYNCHRONIZE", 0x00100000},
+	{"WRITE_DAC", 0x00040000},
+	{"WRITE_OWNER", 0x00080000},
+	{"GENERIC_READ", 0x80000000},
+	{"ACCESS_SYSTEM_SECURITY", 0x01000000},
+	{"TERMINATE", 0x00000001},
+	{"CREATE_THREAD", 0x00000002},
+	{"VM_OPERATION", 0x00000008},
+	{"VM_READ", 0x00000010},
+	{"VM_WRITE", 0x00000020},
+	{"DUP_HANDLE", 0x00000040},
+	{"CREATE_PROCESS", 0x00000080},
+	{"SET_QUOTA", 0x00000100},
+	{"SET_INFORMATION", 0x00000200},
+	{"QUERY_INFORMATION", 0x00000400},
+	{"SUSPEND_RESUME", 0x00000800},
+	{"QUERY_LIMITED_INFORMATION", 0x00001000},
 }
 
 // ThreadAccessRightFlags describes flags for the thread access rights.
 var ThreadAccessRightFlags = []ParamFlag{
 	{"ALL_ACCESS", AllAccess},
-	{"DELETE", windows.DELETE},
-	{"READ_CONTROL", windows.READ_CONTROL},
-	{"SYNCHRONIZE", windows.SYNCHRONIZE},
-	{"WRITE_DAC", windows.WRITE_DAC},
-	{"WRITE_OWNER", windows.WRITE_OWNER},
-	{"TERMINATE", windows.THREAD_TERMINATE},
-	{"SUSPEND_THREAD", windows.THREAD_SUSPEND_RESUME},
-	{"GET_CONTEXT", windows.THREAD_GET_CONTEXT},
-	{"SET_CONTEXT", windows.THREAD_SET_CONTEXT},
-	{"SET_INFORMATION", windows.THREAD_SET_INFORMATION},
-	{"QUERY_INFORMATION", windows.THREAD_QUERY_INFORMATION},
-	{"SET_THREAD_TOKEN", windows.THREAD_SET_THREAD_TOKEN},
-	{"IMPERSONATE", windows.THREAD_IMPERSONATE},
-	{"DIRECT_IMPERSONATION", windows.THREAD_DIRECT_IMPERSONATION},
-	{"SET_LIMITED_INFORMATION", windows.THREAD_SET_LIMITED_INFORMATION},
-	{"QUERY_LIMITED_INFORMATION", windows.THREAD_QUERY_LIMITED_INFORMATION},
+	{"DELETE", 0x00010000},
+	{"READ_CONTROL", 0x00020000},
+	{"SYNCHRONIZE", 0x00100000},
+	{"WRITE_DAC", 0x00040000},
+	{"WRITE_OWNER", 0x00080000},
+	{"TERMINATE", 0x00000001},
+	{"SUSPEND_THREAD", 0x00000002},
+	{"GET_CONTEXT", 0x00000008},
+	{"SET_CONTEXT", 0x00000010},
+	{"SET_INFORMATION", 0x00000020},
+	{"QUERY_INFORMATION", 0x00000040},
+	{"SET_THREAD_TOKEN", 0x00000080},
+	{"IMPERSONATE", 0x00000100},
+	{"DIRECT_IMPERSONATION", 0x00000200},
+	{"SET_LIMITED_INFORMATION", 0x00000400},
+	{"QUERY_LIMITED_INFORMATION", 0x00000800},
 }
 
 // FileAttributeFlags describes file attribute flags.
 var FileAttributeFlags = []ParamFlag{
-	{"READ_ONLY", windows.FILE_ATTRIBUTE_READONLY},
-	{"HIDDEN", windows.FILE_ATTRIBUTE_HIDDEN},
-	{"SYSTEM", windows.FILE_ATTRIBUTE_SYSTEM},
-	{"DIRECTORY", windows.FILE_ATTRIBUTE_DIRECTORY},
-	{"ARCHIVE", windows.FILE_ATTRIBUTE_ARCHIVE},
-	{"DEVICE", windows.FILE_ATTRIBUTE_DEVICE},
-	{"NORMAL", windows.FILE_ATTRIBUTE_NORMAL},
-	{"TEMPORARY", windows.FILE_ATTRIBUTE_TEMPORARY},
-	{"SPARSE", windows.FILE_ATTRIBUTE_SPARSE_FILE},
-	{"JUNCTION", windows.FILE_ATTRIBUTE_REPARSE_POINT},
-	{"COMPRESSED", windows.FILE_ATTRIBUTE_COMPRESSED},
-	{"OFFLINE", windows.FILE_ATTRIBUTE_OFFLINE},
-	{"UNINDEXED", windows.FILE_ATTRIBUTE_NOT_CONTENT_INDEXED},
-	{"ENCRYPTED", windows.FILE_ATTRIBUTE_ENCRYPTED},
-	{"STREAM", windows.FILE_ATTRIBUTE_INTEGRITY_STREAM},
-	{"VIRTUAL", windows.FILE_ATTRIBUTE_VIRTUAL},
-	{"NO_SCRUB", windows.FILE_ATTRIBUTE_NO_SCRUB_DATA},
-	{"RECALL_OPEN", windows.FILE_ATTRIBUTE_RECALL_ON_OPEN},
-	{"RECALL_ACCESS", windows.FILE_ATTRIBUTE_RECALL_ON_DATA_ACCESS},
+	{"READ_ONLY", 0x00000001},
+	{"HIDDEN", 0x00000002},
+	{"SYSTEM", 0x00000004},
+	{"DIRECTORY", 0x00000010},
+	{"ARCHIVE", 0x00000020},
+	{"DEVICE", 0x00000040},
+	{"NORMAL", 0x00000080},
+	{"TEMPORARY", 0x00000100},
+	{"SPARSE", 0x00000200},
+	{"JUNCTION", 0x00000400},
+	{"COMPRESSED", 0x00000800},
+	{"OFFLINE", 0x00001000},
+	{"UNINDEXED", 0x00002000},
+	{"ENCRYPTED", 0x00004000},
+	{"STREAM", 0x00008000},
+	{"VIRTUAL", 0x00010000},
+	{"NO_SCRUB", 0x00020000},
+	{"RECALL_OPEN", 0x00040000},
+	{"RECALL_ACCESS", 0x00400000},
 	{"PINNED", 0x80000},
 	{"UNPINNED", 0x100000},
 }
 
 // FileCreateOptionsFlags describes file create options flags
 var FileCreateOptionsFlags = []ParamFlag{
-	{"DIRECTORY_FILE", windows.FILE_DIRECTORY_FILE},
-	{"WRITE_THROUGH", windows.FILE_WRITE_THROUGH},
-	{"SEQUENTIAL_ONLY", windows.FILE_SEQUENTIAL_ONLY},
-	{"NO_INTERMEDIATE_BUFFERING", windows.FILE_NO_INTERMEDIATE_BUFFERING},
-	{"SYNCHRONOUS_IO_ALERT", windows.FILE_SYNCHRONOUS_IO_ALERT},
-	{"SYNCHRONOUS_IO_NONALERT", windows.FILE_SYNCHRONOUS_IO_NONALERT},
-	{"NON_DIRECTORY_FILE", windows.FILE_NON_DIRECTORY_FILE},
-	{"CREATE_TREE_CONNECTION", windows.FILE_CREATE_TREE_CONNECTION},
-	{"COMPLETE_IF_OPLOCKED", windows.FILE_COMPLETE_IF_OPLOCKED},
-	{"NO_EA_KNOWLEDGE", windows.FILE_NO_EA_KNOWLEDGE},
-	{"OPEN_REMOTE_INSTANCE", windows.FILE_OPEN_REMOTE_INSTANCE},
-	{"RANDOM_ACCESS", windows.FILE_RANDOM_ACCESS},
-	{"DELETE_ON_CLOSE", windows.FILE_DELETE_ON_CLOSE},
-	{"OPEN_BY_FILE_ID", windows.FILE_OPEN_BY_FILE_ID},
-	{"FOR_BACKUP_INTENT", windows.FILE_OPEN_FOR_BACKUP_INTENT},
-	{"NO_COMPRESSION", windows.FILE_NO_COMPRESSION},
-	{"OPEN_REQUIRING_OPLOCK", windows.FILE_OPEN_REQUIRING_OPLOCK},
-	{"DISALLOW_EXCLUSIVE", windows.FILE_DISALLOW_EXCLUSIVE},
-	{"RESERVE_OPFILTER", windows.FILE_RESERVE_OPFILTER},
-	{"OPEN_REPARSE_POINT", windows.FILE_OPEN_REPARSE_POINT},
-	{"OPEN_NO_RECALL", windows.FILE_OPEN_NO_RECALL},
-	{"OPEN_FOR_FREE_SPACE_QUERY", windows.FILE_OPEN_FOR_FREE_SPACE_QUERY},
+	{"DIRECTORY_FILE", 0x00000001},
+	{"WRITE_THROUGH", 0x00000002},
+	{"SEQUENTIAL_ONLY", 0x00000004},
+	{"NO_INTERMEDIATE_BUFFERING", 0x00000008},
+	{"SYNCHRONOUS_IO_ALERT", 0x00000010},
+	{"SYNCHRONOUS_IO_NONALERT", 0x00000020},
+	{"NON_DIRECTORY_FILE", 0x00000040},
+	{"CREATE_TREE_CONNECTION", 0x00000080},
+	{"COMPLETE_IF_OPLOCKED", 0x00000100},
+	{"NO_EA_KNOWLEDGE", 0x00000200},
+	{"OPEN_REMOTE_INSTANCE", 0x00000400},
+	{"RANDOM_ACCESS", 0x00000800},
+	{"DELETE_ON_CLOSE", 0x00001000},
+	{"OPEN_BY_FILE_ID", 0x00002000},
+	{"FOR_BACKUP_INTENT", 0x00004000},
+	{"NO_COMPRESSION", 0x00008000},
+	{"OPEN_REQUIRING_OPLOCK", 0x00010000},
+	{"DISALLOW_EXCLUSIVE", 0x00020000},
+	{"RESERVE_OPFILTER", 0x00100000},
+	{"OPEN_REPARSE_POINT", 0x00200000},
+	{"OPEN_NO_RECALL", 0x00400000},
+	{"OPEN_FOR_FREE_SPACE_QUERY", 0x00800000},
 }
 
 // FileShareModeFlags describes file share mask flags
 var FileShareModeFlags = []ParamFlag{
-	{"READ", windows.FILE_SHARE_READ},
-	{"WRITE", windows.FILE_SHARE_WRITE},
-	{"DELETE", windows.FILE_SHARE_DELETE},
+	{"READ", 0x00000001},
+	{"WRITE", 0x00000002},
+	{"DELETE", 0x00000004},
 }
 
 // MemAllocationFlags describes virtual allocation/free type flags
 var MemAllocationFlags = []ParamFlag{
-	{"COMMIT", windows.MEM_COMMIT},
-	{"RESERVE", windows.MEM_RESERVE},
-	{"RESET", windows.MEM_RESET},
-	{"RESET_UNDO", windows.MEM_RESET_UNDO},
-	{"PHYSICAL", windows.MEM_PHYSICAL},
-	{"LARGE_PAGES", windows.MEM_LARGE_PAGES},
-	{"TOP_DOWN", windows.MEM_TOP_DOWN},
-	{"RELEASE", windows.MEM_RELEASE},
-	{"DECOMMIT", windows.MEM_DECOMMIT},
-	{"WRITE_WATCH", windows.MEM_WRITE_WATCH},
+	{"COMMIT", 0x00001000},
+	{"RESERVE", 0x00002000},
+	{"RESET", 0x00080000},
+	{"RESET_UNDO", 0x01000000},
+	{"PHYSICAL", 0x00400000},
+	{"LARGE_PAGES", 0x20000000},
+	{"TOP_DOWN", 0x00100000},
+	{"RELEASE", 0x00008000},
+	{"DECOMMIT", 0x00004000},
+	{"WRITE_WATCH", 0x00200000},
 }
 
 // MemProtectionFlags represents memory protection option flags.
 var MemProtectionFlags = []ParamFlag{
 	{"NONE", 0},
-	{"EXECUTE", windows.PAGE_EXECUTE},
-	{"EXECUTE_READ", windows.PAGE_EXECUTE_READ},
-	{"EXECUTE_READWRITE", windows.PAGE_EXECUTE_READWRITE},
-	{"EXECUTE_WRITECOPY", windows.PAGE_EXECUTE_WRITECOPY},
-	{"NOACCESS", windows.PAGE_NOACCESS},
-	{"READONLY", windows.PAGE_READONLY},
-	{"READWRITE", windows.PAGE_READWRITE},
-	{"WRITECOPY", windows.PAGE_WRITECOPY},
-	{"TARGETS_INVALID", windows.PAGE_TARGETS_INVALID},
-	{"TARGETS_NO_UPDATE", windows.PAGE_TARGETS_NO_UPDATE},
-	{"GUARD", windows.PAGE_GUARD},
-	{"NOCACHE", windows.PAGE_NOCACHE},
-	{"WRITECOMBINE", windows.PAGE_WRITECOMBINE},
+	{"EXECUTE", 0x00000010},
+	{"EXECUTE_READ", 0x00000020},
+	{"EXECUTE_READWRITE", 0x00000040},
+	{"EXECUTE_WRITECOPY", 0x00000080},
+	{"NOACCESS", 0x00000001},
+	{"READONLY", 0x00000002},
+	{"READWRITE", 0x00000004},
+	{"WRITECOPY", 0x00000008},
+	{"TARGETS_INVALID", 0x40000000},
+	{"TARGETS_NO_UPDATE", 0x40000000},
+	{"GUARD", 0x00000100},
+	{"NOCACHE", 0x00000200},
+	{"WRITECOMBINE", 0x00000400},
 }
 
 // ViewProtectionFlags describes section protection flags. These
 // have different values than the memory protection flags as they
 // are reported by the kernel.
 var ViewProtectionFlags = []ParamFlag{
-	{"EXECUTE_READWRITE", sys.SectionRWX},
-	{"EXECUTE_WRITECOPY", sys.SectionWXC},
-	{"NOCACHE", sys.SectionNC},
-	{"WRITECOMBINE", sys.SectionWCB},
-	{"READONLY", sys.SectionR},
-	{"EXECUTE", sys.SectionX},
-	{"EXECUTE_READ", sys.SectionRX},
-	{"READWRITE", sys.SectionRW},
-	{"WRITECOPY", sys.SectionWC},
+	{"EXECUTE_READWRITE", 0x00060000},
+	{"EXECUTE_WRITECOPY", 0x00070000},
+	{"NOCACHE", 0x00080000},
+	{"WRITECOMBINE", 0x00090000},
+	{"READONLY", 0x00010000},
+	{"EXECUTE", 0x00020000},
+	{"EXECUTE_READ", 0x00030000},
+	{"READWRITE", 0x00040000},
+	{"WRITECOPY", 0x00050000},
 }
 
 // DNSOptsFlags describes DNS query/response options.
@@ -256,17 +254,17 @@ var DNSOptsFlags = []ParamFlag{
 
 // AccessMaskFlags describes the generic and specific access rights
 var AccessMaskFlags = []ParamFlag{
-	{"DELETE", windows.DELETE},
-	{"READ_CONTROL", windows.READ_CONTROL},
-	{"WRITE_DAC", windows.WRITE_DAC},
-	{"WRITE_OWNER", windows.WRITE_OWNER},
-	{"SYNCHRONIZE", windows.SYNCHRONIZE},
-	{"STANDARD_RIGHTS_REQUIRED", windows.STANDARD_RIGHTS_REQUIRED},
-	{"STANDARD_RIGHTS_ALL", windows.STANDARD_RIGHTS_ALL},
-	{"ACCESS_SYSTEM_SECURITY", windows.ACCESS_SYSTEM_SECURITY},
-	{"MAXIMUM_ALLOWED", windows.MAXIMUM_ALLOWED},
-	{"GENERIC_READ", windows.GENERIC_READ},
-	{"GENERIC_WRITE", windows.GENERIC_WRITE},
-	{"GENERIC_EXECUTE", windows.GENERIC_EXECUTE},
-	{"GENERIC_ALL", windows.GENERIC_ALL},
+	{"DELETE", 0x00010000},
+	{"READ_CONTROL", 0x00020000},
+	{"WRITE_DAC", 0x00040000},
+	{"WRITE_OWNER", 0x00080000},
+	{"SYNCHRONIZE", 0x00100000},
+	{"STANDARD_RIGHTS_REQUIRED", 0x000F0000},
+	{"STANDARD_RIGHTS_ALL", 0x001F0000},
+	{"ACCESS_SYSTEM_SECURITY", 0x01000000},
+	{"MAXIMUM_ALLOWED", 0x02000000},
+	{"GENERIC_READ", 0x80000000},
+	{"GENERIC_WRITE", 0x40000000},
+	{"GENERIC_EXECUTE", 0x20000000},
+	{"GENERIC_ALL", 0x10000000},
 }
@@ -24,6 +24,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	kpars "github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -61,6 +62,13 @@ func TestFormat(t *testing.T) {
 	assert.Equal(t, "1999 4 -  (CreateProcess) -- pid: 876 (pid➜ 876) key1: value1", string(s))
 }
 
+func TestFormatCategory(t *testing.T) {
+	f, err := NewFormatter("{{ .Category }}:{{ .Type }}")
+	require.NoError(t, err)
+	s := f.Format(&Kevent{Name: "CreateFile", Category: ktypes.File})
+	assert.Equal(t, "file:CreateFile", string(s))
+}
+
 func TestFormatPS(t *testing.T) {
 	template := "{{ .Seq }} {{ .Process }} ({{ .Cwd }}) {{ .Ppid }} ({{ .Sid }})"
 	f, err := NewFormatter(template)
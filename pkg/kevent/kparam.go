@@ -20,21 +20,25 @@ package kevent
 
 import (
 	"fmt"
+	kerrors "github.com/rabbitstack/fibratus/pkg/errors"
 	"github.com/rabbitstack/fibratus/pkg/fs"
+	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
 	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
 	"github.com/rabbitstack/fibratus/pkg/network"
+	"github.com/rabbitstack/fibratus/pkg/util/ip"
 	"github.com/rabbitstack/fibratus/pkg/util/key"
+	"github.com/rabbitstack/fibratus/pkg/util/ntstatus"
 	"github.com/rabbitstack/fibratus/pkg/util/va"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"math/bits"
 	"net"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
-
-	kerrors "github.com/rabbitstack/fibratus/pkg/errors"
-	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
 )
 
 var caser = cases.Title(language.English)
@@ -735,3 +739,114 @@ func (kpars Kparams) findParam(name string) (*Kparam, error) {
 	}
 	return kpars[name], nil
 }
+
+// NewKparam creates a new event parameter. Since the parameter type is already categorized,
+// we can coerce the value to the appropriate representation (e.g. hex, IP address)
+func NewKparam(name string, typ kparams.Type, value kparams.Value, options ...ParamOption) *Kparam {
+	var opts paramOpts
+	for _, opt := range options {
+		opt(&opts)
+	}
+	var v kparams.Value
+	switch typ {
+	case kparams.IPv4:
+		v = ip.ToIPv4(value.(uint32))
+	case kparams.IPv6:
+		v = ip.ToIPv6(value.([]byte))
+	case kparams.Port:
+		v = bits.ReverseBytes16(value.(uint16))
+	default:
+		v = value
+	}
+	return &Kparam{Name: name, Type: typ, Value: v, Flags: opts.flags, Enum: opts.enum}
+}
+
+// String returns the string representation of the parameter value.
+func (k Kparam) String() string {
+	if k.Value == nil {
+		return ""
+	}
+	switch k.Type {
+	case kparams.UnicodeString, kparams.AnsiString, kparams.Path:
+		return k.Value.(string)
+	case kparams.SID, kparams.WbemSID:
+		return formatSID(&k)
+	case kparams.DOSPath:
+		return formatDOSPath(k.Value.(string))
+	case kparams.Key:
+		return formatKey(k.Value.(string))
+	case kparams.HandleType:
+		return htypes.ConvertTypeIDToName(k.Value.(uint16))
+	case kparams.Status:
+		v, ok := k.Value.(uint32)
+		if !ok {
+			return ""
+		}
+		return ntstatus.FormatMessage(v)
+	case kparams.Address:
+		v, ok := k.Value.(uint64)
+		if !ok {
+			return ""
+		}
+		return va.Address(v).String()
+	case kparams.Int8:
+		return strconv.Itoa(int(k.Value.(int8)))
+	case kparams.Uint8:
+		return strconv.Itoa(int(k.Value.(uint8)))
+	case kparams.Int16:
+		return strconv.Itoa(int(k.Value.(int16)))
+	case kparams.Uint16, kparams.Port:
+		return strconv.Itoa(int(k.Value.(uint16)))
+	case kparams.Uint32, kparams.PID, kparams.TID:
+		return strconv.Itoa(int(k.Value.(uint32)))
+	case kparams.Int32:
+		return strconv.Itoa(int(k.Value.(int32)))
+	case kparams.Uint64:
+		return strconv.FormatUint(k.Value.(uint64), 10)
+	case kparams.Int64:
+		return strconv.Itoa(int(k.Value.(int64)))
+	case kparams.IPv4, kparams.IPv6:
+		return k.Value.(net.IP).String()
+	case kparams.Bool:
+		return strconv.FormatBool(k.Value.(bool))
+	case kparams.Float:
+		return strconv.FormatFloat(float64(k.Value.(float32)), 'f', 6, 32)
+	case kparams.Double:
+		return strconv.FormatFloat(k.Value.(float64), 'f', 6, 64)
+	case kparams.Time:
+		return k.Value.(time.Time).String()
+	case kparams.Enum:
+		if k.Enum == nil {
+			return ""
+		}
+		e := k.Value
+		v, ok := e.(uint32)
+		if !ok {
+			return ""
+		}
+		return k.Enum[v]
+	case kparams.Flags, kparams.Flags64:
+		if k.Flags == nil {
+			return ""
+		}
+		f := k.Value
+		switch v := f.(type) {
+		case uint32:
+			return k.Flags.String(uint64(v))
+		case uint64:
+			return k.Flags.String(v)
+		default:
+			return ""
+		}
+	case kparams.Slice:
+		switch slice := k.Value.(type) {
+		case []string:
+			return strings.Join(slice, ",")
+		default:
+			return fmt.Sprintf("%v", slice)
+		}
+	case kparams.Binary:
+		return string(k.Value.([]byte))
+	}
+	return fmt.Sprintf("%v", k.Value)
+}
@@ -0,0 +1,80 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kevent
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"strconv"
+	"strings"
+)
+
+// formatDOSPath returns the path as stored in the capture. Device paths
+// are mapped to drive letters on the host where the capture is taken.
+func formatDOSPath(path string) string { return path }
+
+// formatKey returns the registry key path as stored in the capture. Root
+// keys are resolved on the host where the capture is taken.
+func formatKey(path string) string { return path }
+
+// formatSID decodes the binary SID parameter and produces its string
+// representation in the S-R-I-S-S... format without calling into the
+// Windows security API.
+func formatSID(kpar *Kparam) string {
+	b, ok := kpar.Value.([]byte)
+	if !ok {
+		return ""
+	}
+	if kpar.Type == kparams.WbemSID {
+		// a WBEM SID is actually a TOKEN_USER structure followed
+		// by the SID, so we have to skip two pointers
+		if len(b) < 16 {
+			return ""
+		}
+		b = b[16:]
+	}
+	if len(b) < 8 {
+		return ""
+	}
+	n := int(b[1])
+	if len(b) < 8+n*4 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("S-")
+	sb.WriteString(strconv.Itoa(int(b[0])))
+	sb.WriteByte('-')
+	var auth uint64
+	for _, v := range b[2:8] {
+		auth = auth<<8 | uint64(v)
+	}
+	if auth >= 1<<32 {
+		sb.WriteString(fmt.Sprintf("0x%012X", auth))
+	} else {
+		sb.WriteString(strconv.FormatUint(auth, 10))
+	}
+	for i := 0; i < n; i++ {
+		sb.WriteByte('-')
+		sb.WriteString(strconv.FormatUint(uint64(binary.LittleEndian.Uint32(b[8+i*4:])), 10))
+	}
+	return sb.String()
+}
@@ -21,7 +21,6 @@ package ktypes
 import (
 	"fmt"
 	"github.com/bits-and-blooms/bitset"
-	"golang.org/x/sys/windows"
 )
 
 // EventsetMasks allows efficient testing
@@ -45,7 +44,7 @@ func (e *EventsetMasks) Set(ktype Ktype) {
 
 // Test checks if the given provider GUID and
 // hook identifier are present in the bitset.
-func (e *EventsetMasks) Test(guid windows.GUID, hookID uint16) bool {
+func (e *EventsetMasks) Test(guid GUID, hookID uint16) bool {
 	i := e.bitsetIndex(guid)
 	if i < 0 {
 		return false
@@ -54,7 +53,7 @@ func (e *EventsetMasks) Test(guid windows.GUID, hookID uint16) bool {
 }
 
 // Clear clears the bitset for a given provider GUID.
-func (e *EventsetMasks) Clear(guid windows.GUID) {
+func (e *EventsetMasks) Clear(guid GUID) {
 	i := e.bitsetIndex(guid)
 	if i < 0 {
 		panic(fmt.Sprintf("invalid event bitset index: %s", guid.String()))
@@ -62,7 +61,7 @@ func (e *EventsetMasks) Clear(guid windows.GUID) {
 	e.masks[e.bitsetIndex(guid)].ClearAll()
 }
 
-func (e *EventsetMasks) bitsetIndex(guid windows.GUID) int {
+func (e *EventsetMasks) bitsetIndex(guid GUID) int {
 	switch guid {
 	case ProcessEventGUID:
 		return 0
@@ -22,8 +22,8 @@ import (
 	"encoding/json"
 	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
 	"github.com/rabbitstack/fibratus/pkg/util/va"
-	"golang.org/x/sys/windows"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -150,14 +150,14 @@ func TestKeventMarshalJSON(t *testing.T) {
 				3455: {Tid: 3455, StartAddress: va.Address(140729524944768), IOPrio: 3, PagePrio: 5, KstackBase: va.Address(18446677035730165760), KstackLimit: va.Address(18446677035730137088), UstackLimit: va.Address(86376448), UstackBase: va.Address(86372352)},
 			},
 			Handles: []htypes.Handle{
-				{Num: windows.Handle(0xffffd105e9baaf70),
+				{Num: htypes.ID(0xffffd105e9baaf70),
 					Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 					Type:   "Key",
 					Object: 777488883434455544,
 					Pid:    uint32(1023),
 				},
 				{
-					Num:  windows.Handle(0xffffd105e9adaf70),
+					Num:  htypes.ID(0xffffd105e9adaf70),
 					Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 					Type: "ALPC Port",
 					Pid:  uint32(1023),
@@ -169,7 +169,7 @@ func TestKeventMarshalJSON(t *testing.T) {
 					Object: 457488883434455544,
 				},
 				{
-					Num:  windows.Handle(0xeaffd105e9adaf30),
+					Num:  htypes.ID(0xeaffd105e9adaf30),
 					Name: `C:\Users\bunny`,
 					Type: "File",
 					Pid:  uint32(1023),
@@ -217,7 +217,7 @@ func TestKeventMarshalJSON(t *testing.T) {
 }
 
 func TestUnmarshalHugeHandles(t *testing.T) {
-	b, err := os.ReadFile("_fixtures\\handles.json")
+	b, err := os.ReadFile(filepath.Join("_fixtures", "handles.json"))
 	require.NoError(t, err)
 	handles := make([]htypes.Handle, 0)
 	err = json.Unmarshal(b, &handles)
@@ -321,14 +321,14 @@ func TestKeventMarshalJSONMultiple(t *testing.T) {
 					3455: {Tid: 3455, StartAddress: va.Address(140729524944768), IOPrio: 3, PagePrio: 5, KstackBase: va.Address(18446677035730165760), KstackLimit: va.Address(18446677035730137088), UstackLimit: va.Address(86376448), UstackBase: va.Address(86372352)},
 				},
 				Handles: []htypes.Handle{
-					{Num: windows.Handle(0xffffd105e9baaf70),
+					{Num: htypes.ID(0xffffd105e9baaf70),
 						Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 						Type:   "Key",
 						Object: 777488883434455544,
 						Pid:    uint32(1023),
 					},
 					{
-						Num:  windows.Handle(0xffffd105e9adaf70),
+						Num:  htypes.ID(0xffffd105e9adaf70),
 						Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 						Type: "ALPC Port",
 						Pid:  uint32(1023),
@@ -340,7 +340,7 @@ func TestKeventMarshalJSONMultiple(t *testing.T) {
 						Object: 457488883434455544,
 					},
 					{
-						Num:  windows.Handle(0xeaffd105e9adaf30),
+						Num:  htypes.ID(0xeaffd105e9adaf30),
 						Name: `C:\Users\bunny`,
 						Type: "File",
 						Pid:  uint32(1023),
@@ -397,14 +397,14 @@ func BenchmarkKeventMarshalJSON(b *testing.B) {
 			SessionID: 4,
 			Envs:      map[string]string{"ProgramData": "C:\\ProgramData", "COMPUTRENAME": "archrabbit"},
 			Handles: []htypes.Handle{
-				{Num: windows.Handle(0xffffd105e9baaf70),
+				{Num: htypes.ID(0xffffd105e9baaf70),
 					Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 					Type:   "Key",
 					Object: 777488883434455544,
 					Pid:    uint32(1023),
 				},
 				{
-					Num:  windows.Handle(0xffffd105e9adaf70),
+					Num:  htypes.ID(0xffffd105e9adaf70),
 					Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 					Type: "ALPC Port",
 					Pid:  uint32(1023),
@@ -416,7 +416,7 @@ func BenchmarkKeventMarshalJSON(b *testing.B) {
 					Object: 457488883434455544,
 				},
 				{
-					Num:  windows.Handle(0xeaffd105e9adaf30),
+					Num:  htypes.ID(0xeaffd105e9adaf30),
 					Name: `C:\Users\bunny`,
 					Type: "File",
 					Pid:  uint32(1023),
@@ -481,14 +481,14 @@ func BenchmarkKeventMarshalJSONStdlib(b *testing.B) {
 			SessionID: 4,
 			Envs:      map[string]string{"ProgramData": "C:\\ProgramData", "COMPUTRENAME": "archrabbit"},
 			Handles: []htypes.Handle{
-				{Num: windows.Handle(0xffffd105e9baaf70),
+				{Num: htypes.ID(0xffffd105e9baaf70),
 					Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 					Type:   "Key",
 					Object: 777488883434455544,
 					Pid:    uint32(1023),
 				},
 				{
-					Num:  windows.Handle(0xffffd105e9adaf70),
+					Num:  htypes.ID(0xffffd105e9adaf70),
 					Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 					Type: "ALPC Port",
 					Pid:  uint32(1023),
@@ -500,7 +500,7 @@ func BenchmarkKeventMarshalJSONStdlib(b *testing.B) {
 					Object: 457488883434455544,
 				},
 				{
-					Num:  windows.Handle(0xeaffd105e9adaf30),
+					Num:  htypes.ID(0xeaffd105e9adaf30),
 					Name: `C:\Users\bunny`,
 					Type: "File",
 					Pid:  uint32(1023),
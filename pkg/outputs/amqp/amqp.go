@@ -33,7 +33,8 @@ var (
 )
 
 type rabbitmq struct {
-	client *client
+	client     *client
+	serializer outputs.Serializer
 }
 
 func init() {
@@ -46,7 +47,7 @@ func initAMQP(config outputs.Config) (outputs.OutputGroup, error) {
 		return outputs.Fail(outputs.ErrInvalidConfig(outputs.AMQP, config.Output))
 	}
 
-	q := &rabbitmq{client: newClient(cfg)}
+	q := &rabbitmq{client: newClient(cfg), serializer: cfg.Serializer}
 
 	return outputs.Success(q), nil
 }
@@ -67,9 +68,12 @@ func (q *rabbitmq) Close() error {
 }
 
 func (q *rabbitmq) Publish(batch *kevent.Batch) error {
-	body := batch.MarshalJSON()
+	body, err := q.serializer.MarshalBatch(batch)
+	if err != nil {
+		return err
+	}
 
-	err := q.client.publish(body)
+	err = q.client.publish(body)
 	if err != nil {
 		amqpErrors.Add(1)
 		return err
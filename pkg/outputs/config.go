@@ -27,6 +27,9 @@ import (
 type Config struct {
 	Type   Type
 	Output interface{}
+	// Filter is the optional filter expression. If specified,
+	// only events matching the filter are routed to the output.
+	Filter string
 }
 
 // TLSConfig stores the client TLS parameters.
@@ -41,6 +41,7 @@ const (
 	esTemplateName        = "output.elasticsearch.template-name"
 	esTemplateConfig      = "output.elasticsearch.template-config"
 	esGzipCompression     = "output.elasticsearch.gzip-compression"
+	esSerializer          = "output.elasticsearch.serializer"
 )
 
 // Config contains the options for tweaking the output behaviour.
@@ -78,6 +79,8 @@ type Config struct {
 	TemplateConfig string `mapstructure:"template-config"`
 	// GzipCompression specifies if gzip compression is enabled.
 	GzipCompression bool `mapstructure:"gzip-compression"`
+	// Serializer indicates the serializer for the indexed documents.
+	Serializer outputs.Serializer `mapstructure:"serializer"`
 }
 
 // AddFlags registers persistent flags.
@@ -98,4 +101,5 @@ func AddFlags(flags *pflag.FlagSet) {
 	flags.String(esIndexName, "fibratus", "Represents the target index for kernel events. It allows time specifiers to create indices per time frame")
 	flags.String(esTemplateConfig, "", "Contains the full JSON body of the index template")
 	flags.Bool(esGzipCompression, false, "Specifies if gzip compression is enabled")
+	flags.String(esSerializer, string(outputs.JSON), "Indicates the event serializer type")
 }
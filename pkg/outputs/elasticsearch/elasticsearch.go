@@ -176,15 +176,22 @@ func (e *elasticsearch) Publish(batch *kevent.Batch) error {
 		// create the bulk index request for each event in the batch.
 		// We already have a valid JSON body, so just pass the raw
 		// JSON message as request document
-		e.bulkProcessor.Add(newBulkIndexRequest(indexName, kevt))
+		req, err := newBulkIndexRequest(indexName, kevt, e.config.Serializer)
+		if err != nil {
+			return err
+		}
+		e.bulkProcessor.Add(req)
 		totalBulkedDocs.Add(1)
 	}
 	return nil
 }
 
-func newBulkIndexRequest(indexName string, kevt *kevent.Kevent) *elastic.BulkIndexRequest {
-	kjson := kevt.MarshalJSON()
-	return elastic.NewBulkIndexRequest().Index(indexName).Doc(json.RawMessage(kjson))
+func newBulkIndexRequest(indexName string, kevt *kevent.Kevent, serializer outputs.Serializer) (*elastic.BulkIndexRequest, error) {
+	kjson, err := serializer.Marshal(kevt)
+	if err != nil {
+		return nil, err
+	}
+	return elastic.NewBulkIndexRequest().Index(indexName).Doc(json.RawMessage(kjson)), nil
 }
 
 func (e *elasticsearch) Close() error {
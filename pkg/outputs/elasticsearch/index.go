@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"github.com/olivere/elastic/v7"
 	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
 	"html/template"
 	"strings"
 	"time"
@@ -39,6 +40,11 @@ func (i index) putTemplate() error {
 	if i.config.TemplateName == "" {
 		return nil
 	}
+	// the built-in template describes the native event
+	// layout, so it is only applicable to the JSON serializer
+	if i.config.TemplateConfig == "" && i.config.Serializer != outputs.JSON && i.config.Serializer != "" {
+		return nil
+	}
 	// get the index pattern for the template
 	indexPattern := i.config.IndexName
 	if strings.Contains(indexPattern, "%") {
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package outputs
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+)
+
+// paramFields maps filter fields to the event parameters they are resolved from.
+var paramFields = map[string]string{
+	"net.dip":       kparams.NetDIP,
+	"net.sip":       kparams.NetSIP,
+	"net.dport":     kparams.NetDport,
+	"net.sport":     kparams.NetSport,
+	"file.path":     kparams.FilePath,
+	"image.path":    kparams.ImagePath,
+	"registry.path": kparams.RegPath,
+}
+
+// kevtFields contains event fields resolved by FieldValue.
+var kevtFields = map[string]bool{
+	"kevt.seq":      true,
+	"kevt.pid":      true,
+	"kevt.tid":      true,
+	"kevt.cpu":      true,
+	"kevt.name":     true,
+	"kevt.category": true,
+	"kevt.desc":     true,
+	"kevt.host":     true,
+	"kevt.time":     true,
+}
+
+// psFields contains process fields resolved by FieldValue. Parent
+// process fields are resolved with the ps.parent. prefix.
+var psFields = map[string]bool{
+	"ps.pid":      true,
+	"ps.ppid":     true,
+	"ps.uuid":     true,
+	"ps.name":     true,
+	"ps.exe":      true,
+	"ps.cmdline":  true,
+	"ps.cwd":      true,
+	"ps.sid":      true,
+	"ps.username": true,
+	"ps.domain":   true,
+}
+
+// IsKnownField determines if the field can be resolved by FieldValue.
+func IsKnownField(field string) bool {
+	if _, ok := paramFields[field]; ok || kevtFields[field] {
+		return true
+	}
+	if key, ok := accessorKey(field, "kevt.arg"); ok {
+		return key != ""
+	}
+	if key, ok := accessorKey(field, "kevt.meta"); ok {
+		return key != ""
+	}
+	if strings.HasPrefix(field, "ps.parent.") {
+		field = "ps." + strings.TrimPrefix(field, "ps.parent.")
+	}
+	return psFields[field]
+}
+
+// FieldValue resolves the string value of the event field. Field names follow the
+// filter field naming, e.g. ps.name or kevt.host. Event parameters and metadata
+// are resolved with the kevt.arg[name] and kevt.meta[key] accessors respectively.
+// The most common parameter fields, such as net.dip or file.path, are resolved
+// by their filter field names as well. The boolean return value is false if the
+// field is unknown or has no value.
+func FieldValue(e *kevent.Kevent, field string) (string, bool) {
+	if name, ok := paramFields[field]; ok {
+		return paramValue(e, name)
+	}
+	switch field {
+	case "kevt.seq":
+		return strconv.FormatUint(e.Seq, 10), true
+	case "kevt.pid":
+		return strconv.FormatUint(uint64(e.PID), 10), true
+	case "kevt.tid":
+		return strconv.FormatUint(uint64(e.Tid), 10), true
+	case "kevt.cpu":
+		return strconv.FormatUint(uint64(e.CPU), 10), true
+	case "kevt.name":
+		return e.Name, e.Name != ""
+	case "kevt.category":
+		return string(e.Category), e.Category != ""
+	case "kevt.desc":
+		return e.Description, e.Description != ""
+	case "kevt.host":
+		return e.Host, e.Host != ""
+	case "kevt.time":
+		return e.Timestamp.Format(time.RFC3339Nano), !e.Timestamp.IsZero()
+	}
+
+	if name, ok := accessorKey(field, "kevt.arg"); ok {
+		return paramValue(e, name)
+	}
+	if key, ok := accessorKey(field, "kevt.meta"); ok {
+		v, ok := e.Metadata[kevent.MetadataKey(key)]
+		if !ok {
+			return "", false
+		}
+		switch s := v.(type) {
+		case string:
+			return s, true
+		case fmt.Stringer:
+			return s.String(), true
+		default:
+			return fmt.Sprintf("%v", v), true
+		}
+	}
+
+	ps := e.PS
+	if ps == nil || !strings.HasPrefix(field, "ps.") {
+		return "", false
+	}
+	if strings.HasPrefix(field, "ps.parent.") {
+		if ps.Parent == nil {
+			return "", false
+		}
+		ps, field = ps.Parent, "ps."+strings.TrimPrefix(field, "ps.parent.")
+	}
+	var v string
+	switch field {
+	case "ps.pid":
+		return strconv.FormatUint(uint64(ps.PID), 10), true
+	case "ps.ppid":
+		return strconv.FormatUint(uint64(ps.Ppid), 10), true
+	case "ps.uuid":
+		return strconv.FormatUint(ps.UUID(), 10), true
+	case "ps.name":
+		v = ps.Name
+	case "ps.exe":
+		v = ps.Exe
+	case "ps.cmdline":
+		v = ps.Cmdline
+	case "ps.cwd":
+		v = ps.Cwd
+	case "ps.sid":
+		v = ps.SID
+	case "ps.username":
+		v = ps.Username
+	case "ps.domain":
+		v = ps.Domain
+	}
+	return v, v != ""
+}
+
+// paramValue returns the string value of the event parameter.
+func paramValue(e *kevent.Kevent, name string) (string, bool) {
+	kpar, err := e.Kparams.Get(name)
+	if err != nil {
+		return "", false
+	}
+	return kpar.String(), true
+}
+
+// accessorKey extracts the key from the field accessor
+// in the form of prefix[key].
+func accessorKey(field, prefix string) (string, bool) {
+	if !strings.HasPrefix(field, prefix+"[") || !strings.HasSuffix(field, "]") {
+		return "", false
+	}
+	return field[len(prefix)+1 : len(field)-1], true
+}
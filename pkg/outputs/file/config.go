@@ -0,0 +1,105 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+const (
+	fileEnabled       = "output.file.enabled"
+	filePath          = "output.file.path"
+	fileSerializer    = "output.file.serializer"
+	fileMaxSize       = "output.file.max-size"
+	fileMaxAge        = "output.file.max-age"
+	fileCompression   = "output.file.compression"
+	fileMaxBackups    = "output.file.max-backups"
+	fileMaxTotalSize  = "output.file.max-total-size"
+	fileFsync         = "output.file.fsync"
+	fileFsyncInterval = "output.file.fsync-interval"
+)
+
+// Compression designates the codec for compressing rotated files.
+type Compression string
+
+const (
+	// None leaves rotated files uncompressed.
+	None Compression = "none"
+	// Gzip compresses rotated files with gzip.
+	Gzip Compression = "gzip"
+	// Zstd compresses rotated files with Zstandard.
+	Zstd Compression = "zstd"
+)
+
+// FsyncPolicy determines when written events are flushed to stable storage.
+type FsyncPolicy string
+
+const (
+	// FsyncNever leaves flushing to the operating system.
+	FsyncNever FsyncPolicy = "never"
+	// FsyncBatch flushes the file after each written batch.
+	FsyncBatch FsyncPolicy = "batch"
+	// FsyncInterval flushes the file periodically.
+	FsyncInterval FsyncPolicy = "interval"
+)
+
+// Config contains the options for tweaking the file output behaviour.
+type Config struct {
+	// Enabled determines whether file output is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the path template of the active file. It accepts the %Y, %y, %m, %d, and %H
+	// time specifiers, and the %host specifier that is replaced with the host name.
+	Path string `mapstructure:"path"`
+	// Serializer indicates the serializer for the written events.
+	Serializer outputs.Serializer `mapstructure:"serializer"`
+	// MaxSize is the maximum size in megabytes of the active file before it gets rotated.
+	MaxSize int `mapstructure:"max-size"`
+	// MaxAge is the maximum time the file is written to before it gets rotated.
+	MaxAge time.Duration `mapstructure:"max-age"`
+	// Compression is the codec for compressing rotated files. It can be one of none, gzip, or zstd.
+	Compression Compression `mapstructure:"compression"`
+	// MaxBackups is the maximum number of rotated files to retain.
+	MaxBackups int `mapstructure:"max-backups"`
+	// MaxTotalSize is the maximum size in megabytes of all rotated files.
+	MaxTotalSize int `mapstructure:"max-total-size"`
+	// Fsync is the policy that determines when the file is flushed to stable storage.
+	Fsync FsyncPolicy `mapstructure:"fsync"`
+	// FsyncInterval is the flush interval for the interval fsync policy.
+	FsyncInterval time.Duration `mapstructure:"fsync-interval"`
+}
+
+// AddFlags registers persistent flags for the file output.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(fileEnabled, false, "Determines whether the file output is enabled")
+	flags.String(filePath, filepath.Join(os.Getenv("PROGRAMFILES"), "fibratus", "events", "fibratus-%Y-%m-%d.jsonl"), "The path template of the active file. It accepts the %Y, %y, %m, %d, %H time specifiers, and the %host specifier")
+	flags.String(fileSerializer, string(outputs.JSON), "Indicates the event serializer type")
+	flags.Int(fileMaxSize, 100, "The maximum size in megabytes of the active file before it gets rotated")
+	flags.Duration(fileMaxAge, time.Hour*24, "The maximum time the file is written to before it gets rotated")
+	flags.String(fileCompression, string(Gzip), "The codec for compressing rotated files. Possible values are none, gzip, and zstd")
+	flags.Int(fileMaxBackups, 10, "The maximum number of rotated files to retain")
+	flags.Int(fileMaxTotalSize, 1024, "The maximum size in megabytes of all rotated files")
+	flags.String(fileFsync, string(FsyncInterval), "The policy that determines when the file is flushed to stable storage. Possible values are never, batch, and interval")
+	flags.Duration(fileFsyncInterval, time.Second, "The flush interval for the interval fsync policy")
+}
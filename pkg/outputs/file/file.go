@@ -0,0 +1,396 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"expvar"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/util/hostname"
+)
+
+// megabyte is the number of bytes in one megabyte
+const megabyte = 1024 * 1024
+
+// backupTimeFormat is the timestamp layout of rotated file names
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// specifiers maps the path template specifiers to
+// the time layouts of the values they expand to
+var specifiers = map[string]string{
+	"%Y": "2006",
+	"%y": "06",
+	"%m": "01",
+	"%d": "02",
+	"%H": "15",
+}
+
+var (
+	// fileBytes counts the number of bytes written to files
+	fileBytes = expvar.NewInt("output.file.write.bytes")
+	// fileRotations counts the number of file rotations
+	fileRotations = expvar.NewInt("output.file.rotations")
+	// fileRemovals counts the number of rotated files removed by the retention policy
+	fileRemovals = expvar.NewInt("output.file.retention.removals")
+)
+
+type _file struct {
+	sync.Mutex
+	config Config
+	host   string
+	// maxSize and maxTotalSize are size limits in bytes
+	maxSize      int64
+	maxTotalSize int64
+
+	f    *os.File
+	path string
+	size int64
+	// openedAt is the time the active file was opened
+	openedAt time.Time
+	// dirty indicates the active file has writes that weren't flushed
+	dirty    bool
+	lastSync time.Time
+
+	// rotated receives the rotated files for compression and retention
+	rotated chan string
+	quit    chan struct{}
+	wg      sync.WaitGroup
+
+	// backupRe matches the names of rotated files. layouts are the time
+	// layouts of the template specifiers captured by the expression
+	backupRe *regexp.Regexp
+	layouts  []string
+
+	now func() time.Time
+}
+
+func init() {
+	outputs.Register(outputs.File, initFile)
+}
+
+func initFile(config outputs.Config) (outputs.OutputGroup, error) {
+	cfg, ok := config.Output.(Config)
+	if !ok {
+		return outputs.Fail(outputs.ErrInvalidConfig(outputs.File, config.Output))
+	}
+	if cfg.Path == "" {
+		return outputs.Fail(fmt.Errorf("file output path is required"))
+	}
+	switch cfg.Compression {
+	case "":
+		cfg.Compression = None
+	case None, Gzip, Zstd:
+	default:
+		return outputs.Fail(fmt.Errorf("unknown file compression %q", cfg.Compression))
+	}
+	switch cfg.Fsync {
+	case "":
+		cfg.Fsync = FsyncNever
+	case FsyncNever, FsyncBatch, FsyncInterval:
+	default:
+		return outputs.Fail(fmt.Errorf("unknown fsync policy %q", cfg.Fsync))
+	}
+	if cfg.Fsync == FsyncInterval && cfg.FsyncInterval <= 0 {
+		cfg.FsyncInterval = time.Second
+	}
+	return outputs.Success(newFile(cfg, hostname.Get())), nil
+}
+
+func newFile(config Config, host string) *_file {
+	f := &_file{
+		config:       config,
+		host:         host,
+		maxSize:      int64(config.MaxSize) * megabyte,
+		maxTotalSize: int64(config.MaxTotalSize) * megabyte,
+		rotated:      make(chan string, 16),
+		quit:         make(chan struct{}),
+		now:          time.Now,
+	}
+	f.backupRe, f.layouts = backupPattern(config.Path, host)
+	return f
+}
+
+// Connect starts the goroutines that compress rotated files, enforce
+// the retention policy, and periodically flush and rotate the active
+// file. Files left over from previous runs are compressed first.
+func (f *_file) Connect() error {
+	f.wg.Add(2)
+	go f.mill()
+	go f.tick()
+
+	for _, path := range f.backups() {
+		if f.config.Compression != None && !f.isCompressed(path) {
+			f.rotated <- path
+		}
+	}
+	f.rotated <- ""
+
+	return nil
+}
+
+// Close flushes and closes the active file and waits
+// until all rotated files are compressed.
+func (f *_file) Close() error {
+	f.Lock()
+	err := f.closeFile()
+	f.Unlock()
+	close(f.quit)
+	f.wg.Wait()
+	return err
+}
+
+// Publish writes the batch to the active file with each event on a separate line.
+func (f *_file) Publish(batch *kevent.Batch) error {
+	buf := make([]byte, 0)
+	for _, e := range batch.Events {
+		b, err := f.config.Serializer.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf = append(buf, b...)
+		buf = append(buf, '\n')
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	now := f.now()
+	path := f.expand(now)
+	if f.f != nil {
+		switch {
+		case path != f.path:
+			// the path template rolled over, so there is
+			// no need to rename the previous file
+			if err := f.rotate(false); err != nil {
+				return err
+			}
+		case f.exceedsSize(int64(len(buf))) || f.exceedsAge(now):
+			if err := f.rotate(true); err != nil {
+				return err
+			}
+		}
+	}
+	if f.f == nil {
+		if err := f.open(path, now); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.f.Write(buf)
+	f.size += int64(n)
+	fileBytes.Add(int64(n))
+	if err != nil {
+		return err
+	}
+	f.dirty = true
+	if f.config.Fsync == FsyncBatch {
+		return f.sync(now)
+	}
+	return nil
+}
+
+func (f *_file) open(path string, now time.Time) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	fd, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := fd.Stat()
+	if err != nil {
+		_ = fd.Close()
+		return err
+	}
+	f.f, f.path, f.size, f.openedAt = fd, path, fi.Size(), now
+	return nil
+}
+
+// rotate closes the active file and hands it over for compression. If the
+// rename flag is true, the file is renamed to include the rotation time, so
+// the same path can be reopened.
+func (f *_file) rotate(rename bool) error {
+	path := f.path
+	if err := f.closeFile(); err != nil {
+		return err
+	}
+	if rename {
+		backup := backupName(path, f.now())
+		if err := os.Rename(path, backup); err != nil {
+			return fmt.Errorf("unable to rotate %s: %v", path, err)
+		}
+		path = backup
+	}
+	fileRotations.Add(1)
+	select {
+	case f.rotated <- path:
+	default:
+		// the mill is lagging behind. The file is compressed on the next rotation
+		log.Warnf("unable to enqueue %s for compression", path)
+	}
+	return nil
+}
+
+func (f *_file) closeFile() error {
+	if f.f == nil {
+		return nil
+	}
+	var err error
+	if f.dirty && f.config.Fsync != FsyncNever {
+		err = f.f.Sync()
+	}
+	if cerr := f.f.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	f.f, f.dirty = nil, false
+	return err
+}
+
+func (f *_file) sync(now time.Time) error {
+	f.lastSync = now
+	if !f.dirty {
+		return nil
+	}
+	f.dirty = false
+	return f.f.Sync()
+}
+
+func (f *_file) exceedsSize(n int64) bool {
+	return f.maxSize > 0 && f.size > 0 && f.size+n > f.maxSize
+}
+
+func (f *_file) exceedsAge(now time.Time) bool {
+	return f.config.MaxAge > 0 && now.Sub(f.openedAt) >= f.config.MaxAge
+}
+
+// tick flushes the active file according to the interval fsync
+// policy and rotates the file when it reaches its maximum age,
+// even if no events are written.
+func (f *_file) tick() {
+	defer f.wg.Done()
+	interval := time.Second
+	if f.config.Fsync == FsyncInterval && f.config.FsyncInterval < interval {
+		interval = f.config.FsyncInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.Lock()
+			now := f.now()
+			if f.f != nil && f.exceedsAge(now) {
+				if err := f.rotate(true); err != nil {
+					log.Warnf("unable to rotate file: %v", err)
+				}
+			}
+			if f.f != nil && f.config.Fsync == FsyncInterval && now.Sub(f.lastSync) >= f.config.FsyncInterval {
+				if err := f.sync(now); err != nil {
+					log.Warnf("unable to sync %s: %v", f.path, err)
+				}
+			}
+			f.Unlock()
+		case <-f.quit:
+			return
+		}
+	}
+}
+
+// expand replaces the specifiers in the path template.
+func (f *_file) expand(t time.Time) string {
+	t = t.UTC()
+	return strings.NewReplacer(
+		"%host", f.host,
+		"%Y", t.Format("2006"),
+		"%y", t.Format("06"),
+		"%m", t.Format("01"),
+		"%d", t.Format("02"),
+		"%H", t.Format("15")).Replace(f.config.Path)
+}
+
+// glob returns the pattern that matches the active and rotated files.
+func (f *_file) glob() string {
+	path := strings.NewReplacer(
+		"%host", f.host,
+		"%Y", "*",
+		"%y", "*",
+		"%m", "*",
+		"%d", "*",
+		"%H", "*").Replace(f.config.Path)
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "*" + ext + "*"
+}
+
+// backupPattern returns the expression that matches the names of rotated
+// files. The name of the rotated file is either the expansion of the path
+// template, or the name produced by backupName, optionally followed by the
+// compressed file extension. The values of the template specifiers are
+// captured in the order they appear in the template and their time layouts
+// are returned along with the expression.
+func backupPattern(path, host string) (*regexp.Regexp, []string) {
+	var layouts []string
+	expr := func(s string) string {
+		var b strings.Builder
+		for len(s) > 0 {
+			i := strings.IndexByte(s, '%')
+			if i < 0 {
+				b.WriteString(regexp.QuoteMeta(s))
+				break
+			}
+			b.WriteString(regexp.QuoteMeta(s[:i]))
+			s = s[i:]
+			switch {
+			case strings.HasPrefix(s, "%host"):
+				b.WriteString(regexp.QuoteMeta(host))
+				s = s[5:]
+			case len(s) > 1 && specifiers[s[:2]] != "":
+				layout := specifiers[s[:2]]
+				layouts = append(layouts, layout)
+				fmt.Fprintf(&b, `(\d{%d})`, len(layout))
+				s = s[2:]
+			default:
+				b.WriteString("%")
+				s = s[1:]
+			}
+		}
+		return b.String()
+	}
+	ext := filepath.Ext(path)
+	re := "^" + expr(strings.TrimSuffix(path, ext)) +
+		`(?:-(?P<rotated>\d{4}-\d{2}-\d{2}T\d{2}-\d{2}-\d{2}\.\d{3}))?` +
+		expr(ext) + `(?:\.gz|\.zst)?$`
+	return regexp.MustCompile(re), layouts
+}
+
+// backupName returns the name of the rotated file. The rotation
+// time is inserted between the file name and the extension.
+func backupName(path string, t time.Time) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
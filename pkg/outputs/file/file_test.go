@@ -0,0 +1,377 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+// clock is the fake clock that is advanced manually
+type clock struct {
+	sync.Mutex
+	t time.Time
+}
+
+func (c *clock) now() time.Time {
+	c.Lock()
+	defer c.Unlock()
+	return c.t
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.Lock()
+	defer c.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func newTestFile(t *testing.T, config Config, opts ...func(f *_file)) (*_file, *clock) {
+	c := &clock{t: time.Date(2024, 3, 14, 10, 0, 0, 0, time.UTC)}
+	f := newFile(config, "archrabbit")
+	f.now = c.now
+	for _, opt := range opts {
+		opt(f)
+	}
+	require.NoError(t, f.Connect())
+	return f, c
+}
+
+func TestFilePublish(t *testing.T) {
+	dir := t.TempDir()
+	f, _ := newTestFile(t, Config{
+		Path:       filepath.Join(dir, "%host", "fibratus-%Y-%m-%d.jsonl"),
+		Serializer: outputs.JSON,
+		Fsync:      FsyncBatch,
+	})
+
+	require.NoError(t, f.Publish(getBatch(1, 2)))
+	require.NoError(t, f.Publish(getBatch(3)))
+	require.NoError(t, f.Close())
+
+	lines := readLines(t, filepath.Join(dir, "archrabbit", "fibratus-2024-03-14.jsonl"))
+	require.Len(t, lines, 3)
+	for i, line := range lines {
+		var e map[string]any
+		require.NoError(t, json.Unmarshal(line, &e))
+		assert.Equal(t, float64(i+1), e["seq"])
+	}
+}
+
+func TestFilePublishECS(t *testing.T) {
+	dir := t.TempDir()
+	f, _ := newTestFile(t, Config{Path: filepath.Join(dir, "fibratus.jsonl"), Serializer: outputs.ECS})
+
+	require.NoError(t, f.Publish(getBatch(1)))
+	require.NoError(t, f.Close())
+
+	lines := readLines(t, filepath.Join(dir, "fibratus.jsonl"))
+	require.Len(t, lines, 1)
+	var e map[string]any
+	require.NoError(t, json.Unmarshal(lines[0], &e))
+	assert.Contains(t, e, "@timestamp")
+}
+
+func TestFileRotateBySize(t *testing.T) {
+	var tests = []struct {
+		compression Compression
+		ext         string
+	}{
+		{Gzip, ".gz"},
+		{Zstd, ".zst"},
+		{None, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.compression), func(t *testing.T) {
+			dir := t.TempDir()
+			f, c := newTestFile(t, Config{
+				Path:        filepath.Join(dir, "fibratus.jsonl"),
+				Serializer:  outputs.JSON,
+				Compression: tt.compression,
+			}, func(f *_file) { f.maxSize = 1024 })
+
+			var seq uint64
+			for i := 0; i < 10; i++ {
+				seq++
+				require.NoError(t, f.Publish(getBatch(seq)))
+				c.advance(time.Second)
+			}
+			require.NoError(t, f.Close())
+
+			backups, err := filepath.Glob(filepath.Join(dir, "fibratus-*.jsonl"+tt.ext))
+			require.NoError(t, err)
+			require.True(t, len(backups) > 1)
+
+			// all events are retained across the rotated and active files
+			var lines int
+			for _, backup := range backups {
+				lines += len(readLines(t, backup))
+			}
+			lines += len(readLines(t, filepath.Join(dir, "fibratus.jsonl")))
+			assert.Equal(t, 10, lines)
+		})
+	}
+}
+
+func TestFileRotateByAge(t *testing.T) {
+	dir := t.TempDir()
+	f, c := newTestFile(t, Config{
+		Path:        filepath.Join(dir, "fibratus.jsonl"),
+		Serializer:  outputs.JSON,
+		Compression: Gzip,
+		MaxAge:      time.Hour,
+	})
+
+	require.NoError(t, f.Publish(getBatch(1)))
+	c.advance(time.Minute * 30)
+	require.NoError(t, f.Publish(getBatch(2)))
+	c.advance(time.Minute * 31)
+	require.NoError(t, f.Publish(getBatch(3)))
+	require.NoError(t, f.Close())
+
+	backup := filepath.Join(dir, "fibratus-2024-03-14T11-01-00.000.jsonl.gz")
+	assert.Len(t, readLines(t, backup), 2)
+	assert.Len(t, readLines(t, filepath.Join(dir, "fibratus.jsonl")), 1)
+}
+
+func TestFileRotateOnPathChange(t *testing.T) {
+	dir := t.TempDir()
+	f, c := newTestFile(t, Config{
+		Path:        filepath.Join(dir, "fibratus-%Y-%m-%d-%H.jsonl"),
+		Serializer:  outputs.JSON,
+		Compression: Gzip,
+	})
+
+	require.NoError(t, f.Publish(getBatch(1)))
+	c.advance(time.Hour)
+	require.NoError(t, f.Publish(getBatch(2)))
+	require.NoError(t, f.Close())
+
+	assert.Len(t, readLines(t, filepath.Join(dir, "fibratus-2024-03-14-10.jsonl.gz")), 1)
+	assert.Len(t, readLines(t, filepath.Join(dir, "fibratus-2024-03-14-11.jsonl")), 1)
+}
+
+func TestFileRetention(t *testing.T) {
+	dir := t.TempDir()
+	mtime := time.Now().Add(-time.Hour)
+	for i, name := range []string{
+		"fibratus-2024-03-10.jsonl.gz",
+		"fibratus-2024-03-11.jsonl.gz",
+		"fibratus-2024-03-12.jsonl.gz",
+		"fibratus-2024-03-13.jsonl.gz",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0644))
+		require.NoError(t, os.Chtimes(path, mtime.Add(time.Duration(i)*time.Minute), mtime.Add(time.Duration(i)*time.Minute)))
+	}
+	// the file left over from the previous run is compressed on startup
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fibratus-2024-03-13-2024-03-13T23-30-00.000.jsonl"), []byte("{}\n"), 0644))
+	// files that look like backups but weren't produced by rotation are left intact
+	for _, name := range []string{
+		"fibratus-2024-03-13-leftover.jsonl",
+		"fibratus-2024-03-01-archive.jsonl.gz",
+		"fibratus-2024-13-01.jsonl.gz",
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, make([]byte, 100), 0644))
+		require.NoError(t, os.Chtimes(path, mtime.Add(-time.Hour), mtime.Add(-time.Hour)))
+	}
+
+	f, _ := newTestFile(t, Config{
+		Path:        filepath.Join(dir, "fibratus-%Y-%m-%d.jsonl"),
+		Serializer:  outputs.JSON,
+		Compression: Gzip,
+		MaxBackups:  3,
+	})
+	require.NoError(t, f.Publish(getBatch(1)))
+	require.NoError(t, f.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "fibratus-2024-03-12.jsonl.gz"),
+		filepath.Join(dir, "fibratus-2024-03-13.jsonl.gz"),
+		filepath.Join(dir, "fibratus-2024-03-13-2024-03-13T23-30-00.000.jsonl.gz"),
+		filepath.Join(dir, "fibratus-2024-03-14.jsonl"),
+		filepath.Join(dir, "fibratus-2024-03-13-leftover.jsonl"),
+		filepath.Join(dir, "fibratus-2024-03-01-archive.jsonl.gz"),
+		filepath.Join(dir, "fibratus-2024-13-01.jsonl.gz"),
+	}, files)
+
+	// enforce the total size limit
+	f, _ = newTestFile(t, Config{
+		Path:        filepath.Join(dir, "fibratus-%Y-%m-%d.jsonl"),
+		Serializer:  outputs.JSON,
+		Compression: Gzip,
+	}, func(f *_file) { f.maxTotalSize = 150 })
+	require.NoError(t, f.Close())
+
+	files, err = filepath.Glob(filepath.Join(dir, "*.gz"))
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(dir, "fibratus-2024-03-13.jsonl.gz"),
+		filepath.Join(dir, "fibratus-2024-03-13-2024-03-13T23-30-00.000.jsonl.gz"),
+		filepath.Join(dir, "fibratus-2024-03-01-archive.jsonl.gz"),
+		filepath.Join(dir, "fibratus-2024-13-01.jsonl.gz"),
+	}, files)
+}
+
+func TestFileBackups(t *testing.T) {
+	var tests = []struct {
+		name    string
+		path    string
+		names   []string
+		backups []string
+	}{
+		{
+			"static",
+			"fibratus.jsonl",
+			[]string{
+				"fibratus.jsonl",
+				"fibratus-2024-03-14T09-00-00.000.jsonl.gz",
+				"fibratus-2024-03-14T09-30-00.000.jsonl",
+				"fibratus-2024-03-14T09-30-00.000.jsonl.gz.tmp",
+				"fibratus-2024-03-14T09-45-00.jsonl",
+				"fibratus-old.jsonl",
+				"fibratus.jsonl.bak",
+			},
+			[]string{
+				"fibratus-2024-03-14T09-30-00.000.jsonl",
+				"fibratus-2024-03-14T09-00-00.000.jsonl.gz",
+			},
+		},
+		{
+			"template",
+			"%host-%Y%m%d.jsonl",
+			[]string{
+				"archrabbit-20240313.jsonl.zst",
+				"archrabbit-20240313-2024-03-13T12-00-00.000.jsonl.zst",
+				"archrabbit-20240312.jsonl.zst",
+				"archrabbit-20240314.jsonl",
+				"archrabbit-20241312.jsonl.zst",
+				"otherhost-20240312.jsonl.zst",
+				"archrabbit-20240312-copy.jsonl",
+			},
+			[]string{
+				"archrabbit-20240313.jsonl.zst",
+				"archrabbit-20240313-2024-03-13T12-00-00.000.jsonl.zst",
+				"archrabbit-20240312.jsonl.zst",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for _, name := range tt.names {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}\n"), 0644))
+			}
+			f := newFile(Config{Path: filepath.Join(dir, tt.path)}, "archrabbit")
+			f.now = func() time.Time { return time.Date(2024, 3, 14, 10, 0, 0, 0, time.UTC) }
+
+			backups := make([]string, 0)
+			for _, path := range f.backups() {
+				backups = append(backups, filepath.Base(path))
+			}
+			assert.Equal(t, tt.backups, backups)
+		})
+	}
+}
+
+func TestInitFileInvalidConfig(t *testing.T) {
+	_, err := initFile(outputs.Config{Type: outputs.File, Output: Config{}})
+	require.Error(t, err)
+	_, err = initFile(outputs.Config{Type: outputs.File, Output: Config{Path: "fibratus.jsonl", Compression: "brotli"}})
+	require.Error(t, err)
+	_, err = initFile(outputs.Config{Type: outputs.File, Output: Config{Path: "fibratus.jsonl", Fsync: "sometimes"}})
+	require.Error(t, err)
+}
+
+func readLines(t *testing.T, path string) [][]byte {
+	fd, err := os.Open(path)
+	require.NoError(t, err)
+	defer fd.Close()
+
+	var r io.Reader = fd
+	switch filepath.Ext(path) {
+	case ".gz":
+		gz, err := gzip.NewReader(fd)
+		require.NoError(t, err)
+		r = gz
+	case ".zst":
+		zr, err := zstd.NewReader(fd)
+		require.NoError(t, err)
+		defer zr.Close()
+		r = zr
+	}
+
+	var lines [][]byte
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, append([]byte(nil), scanner.Bytes()...))
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func getBatch(seqs ...uint64) *kevent.Batch {
+	evts := make([]*kevent.Kevent, len(seqs))
+	for i, seq := range seqs {
+		evts[i] = &kevent.Kevent{
+			Type:        ktypes.CreateFile,
+			Tid:         2484,
+			PID:         859,
+			CPU:         1,
+			Seq:         seq,
+			Name:        "CreateFile",
+			Timestamp:   time.Now(),
+			Category:    ktypes.File,
+			Host:        "archrabbit",
+			Description: "Creates or opens a new file, directory, I/O device, pipe, console",
+			Kparams: kevent.Kparams{
+				kparams.FilePath: {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: `C:\Windows\system32\user32.dll`},
+			},
+			PS: &pstypes.PS{
+				PID:  859,
+				Ppid: 6304,
+				Name: "firefox.exe",
+				Exe:  `C:\Program Files\Mozilla Firefox\firefox.exe`,
+			},
+		}
+	}
+	return kevent.NewBatch(evts...)
+}
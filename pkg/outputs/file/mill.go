@@ -0,0 +1,247 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	log "github.com/sirupsen/logrus"
+)
+
+// mill compresses rotated files and enforces the retention policy
+// after each rotation. An empty path only triggers the retention.
+func (f *_file) mill() {
+	defer f.wg.Done()
+	for {
+		select {
+		case path := <-f.rotated:
+			f.process(path)
+		case <-f.quit:
+			// drain files rotated before shutdown
+			for {
+				select {
+				case path := <-f.rotated:
+					f.process(path)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (f *_file) process(path string) {
+	if path != "" && f.config.Compression != None {
+		if err := f.compress(path); err != nil {
+			log.Warnf("unable to compress %s: %v", path, err)
+		}
+	}
+	f.retain()
+}
+
+// compress writes the compressed copy of the file and removes the
+// original. The copy is written under a temporary name, so partially
+// compressed files are never mistaken for complete backups.
+func (f *_file) compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// already removed by the retention policy
+			return nil
+		}
+		return err
+	}
+	defer src.Close()
+
+	dst := path + f.compressedExt()
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+
+	var w io.WriteCloser
+	switch f.config.Compression {
+	case Zstd:
+		w, err = zstd.NewWriter(out)
+		if err != nil {
+			_ = out.Close()
+			return err
+		}
+	default:
+		w = gzip.NewWriter(out)
+	}
+	if _, err = io.Copy(w, src); err == nil {
+		err = w.Close()
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+func (f *_file) compressedExt() string {
+	if f.config.Compression == Zstd {
+		return ".zst"
+	}
+	return ".gz"
+}
+
+func (f *_file) isCompressed(path string) bool {
+	return strings.HasSuffix(path, ".gz") || strings.HasSuffix(path, ".zst")
+}
+
+// backup is the rotated file along with the times encoded in its name.
+type backup struct {
+	path string
+	// period is the time the path template was expanded for
+	period time.Time
+	// rotated is the rotation time. It is zero for files
+	// rotated when the path template expansion changed
+	rotated time.Time
+}
+
+// newer determines if the backup holds more recent events than the other
+// backup. Within the same period, files rotated by size or age precede the
+// file rotated when the period ended.
+func (b backup) newer(o backup) bool {
+	if !b.period.Equal(o.period) {
+		return b.period.After(o.period)
+	}
+	if b.rotated.IsZero() || o.rotated.IsZero() {
+		return b.rotated.IsZero() && !o.rotated.IsZero()
+	}
+	return b.rotated.After(o.rotated)
+}
+
+// parseBackup parses the times from the rotated file name. Files
+// with names that weren't produced by rotation are rejected.
+func (f *_file) parseBackup(path string) (backup, bool) {
+	m := f.backupRe.FindStringSubmatch(path)
+	if m == nil {
+		return backup{}, false
+	}
+	b := backup{path: path}
+	values := make([]string, 0, len(f.layouts))
+	for i, name := range f.backupRe.SubexpNames() {
+		if i == 0 {
+			continue
+		}
+		if name == "rotated" {
+			if m[i] == "" {
+				continue
+			}
+			t, err := time.Parse(backupTimeFormat, m[i])
+			if err != nil {
+				return backup{}, false
+			}
+			b.rotated = t
+			continue
+		}
+		values = append(values, m[i])
+	}
+	if len(values) > 0 {
+		t, err := time.Parse(strings.Join(f.layouts, " "), strings.Join(values, " "))
+		if err != nil {
+			return backup{}, false
+		}
+		b.period = t
+	}
+	return b, true
+}
+
+// backups returns the rotated files sorted from the newest to the oldest.
+// Neither the active file nor the file the template currently expands to
+// are considered rotated. Only files whose names match the names given to
+// rotated files are returned, so other files in the directory are never
+// compressed or removed by the retention policy.
+func (f *_file) backups() []string {
+	matches, err := filepath.Glob(f.glob())
+	if err != nil {
+		return nil
+	}
+	f.Lock()
+	active, current := f.path, f.expand(f.now())
+	if f.f == nil {
+		active = current
+	}
+	f.Unlock()
+
+	backups := make([]backup, 0, len(matches))
+	for _, path := range matches {
+		if path == active || path == current {
+			continue
+		}
+		b, ok := f.parseBackup(path)
+		if !ok {
+			continue
+		}
+		fi, err := os.Stat(path)
+		if err != nil || fi.IsDir() {
+			continue
+		}
+		backups = append(backups, b)
+	}
+	sort.SliceStable(backups, func(i, j int) bool { return backups[i].newer(backups[j]) })
+
+	paths := make([]string, len(backups))
+	for i, b := range backups {
+		paths[i] = b.path
+	}
+	return paths
+}
+
+// retain removes the oldest rotated files that exceed
+// the maximum count or the maximum total size.
+func (f *_file) retain() {
+	var total int64
+	for i, path := range f.backups() {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		total += fi.Size()
+		if (f.config.MaxBackups > 0 && i >= f.config.MaxBackups) ||
+			(f.maxTotalSize > 0 && total > f.maxTotalSize) {
+			if err := os.Remove(path); err != nil {
+				log.Warnf("unable to remove %s: %v", path, err)
+				continue
+			}
+			fileRemovals.Add(1)
+		}
+	}
+}
@@ -80,10 +80,9 @@ func (h *_http) Connect() error { return nil }
 func (h *_http) Close() error   { return nil }
 
 func (h *_http) Publish(batch *kevent.Batch) error {
-	var buf []byte
-	switch h.config.Serializer {
-	case outputs.JSON:
-		buf = batch.MarshalJSON()
+	buf, err := h.config.Serializer.MarshalBatch(batch)
+	if err != nil {
+		return err
 	}
 
 	if h.config.EnableGzip {
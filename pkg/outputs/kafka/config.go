@@ -0,0 +1,108 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+const (
+	kafkaEnabled         = "output.kafka.enabled"
+	kafkaBrokers         = "output.kafka.brokers"
+	kafkaTopic           = "output.kafka.topic"
+	kafkaPartitionKey    = "output.kafka.partition-key"
+	kafkaClientID        = "output.kafka.client-id"
+	kafkaVersion         = "output.kafka.version"
+	kafkaCompression     = "output.kafka.compression"
+	kafkaRequiredAcks    = "output.kafka.required-acks"
+	kafkaIdempotent      = "output.kafka.idempotent"
+	kafkaTimeout         = "output.kafka.timeout"
+	kafkaRetryMax        = "output.kafka.retry-max"
+	kafkaMaxMessageBytes = "output.kafka.max-message-bytes"
+	kafkaEnableTLS       = "output.kafka.enable-tls"
+	kafkaSASLMechanism   = "output.kafka.sasl-mechanism"
+	kafkaSASLUsername    = "output.kafka.sasl-username"
+	kafkaSASLPassword    = "output.kafka.sasl-password"
+)
+
+// Config contains the options for tweaking the Kafka output behaviour.
+type Config struct {
+	outputs.TLSConfig
+	// Enabled determines whether Kafka output is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// Brokers contains the list of bootstrap broker addresses.
+	Brokers []string `mapstructure:"brokers"`
+	// Topic is the default topic to which events are produced.
+	Topic string `mapstructure:"topic"`
+	// Topics maps event categories to topics. Events whose category
+	// is not present in the mapping are produced to the default topic.
+	Topics map[string]string `mapstructure:"topics"`
+	// PartitionKey is the event field from which the message key is derived, e.g. kevt.host,
+	// ps.uuid or kevt.category. Messages are assigned to random partitions if the key is empty.
+	PartitionKey string `mapstructure:"partition-key"`
+	// ClientID is the user-provided string sent with every request to brokers.
+	ClientID string `mapstructure:"client-id"`
+	// Version is the Kafka protocol version.
+	Version string `mapstructure:"version"`
+	// Compression is the message compression codec. It can be one of none, gzip, snappy, lz4, or zstd.
+	Compression string `mapstructure:"compression"`
+	// RequiredAcks is the delivery acknowledgement level. It can be one of none, leader, or all.
+	RequiredAcks string `mapstructure:"required-acks"`
+	// Idempotent enables the idempotent producer that guarantees exactly one copy of each message is written.
+	Idempotent bool `mapstructure:"idempotent"`
+	// Timeout represents the timeout for broker requests.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// RetryMax is the maximum number of times to retry sending a message.
+	RetryMax int `mapstructure:"retry-max"`
+	// MaxMessageBytes is the maximum permitted size of a message.
+	MaxMessageBytes int `mapstructure:"max-message-bytes"`
+	// EnableTLS determines whether the TLS transport is used to connect to brokers.
+	EnableTLS bool `mapstructure:"enable-tls"`
+	// SASLMechanism is the SASL authentication mechanism. It can be one of PLAIN, SCRAM-SHA-256, or SCRAM-SHA-512.
+	SASLMechanism string `mapstructure:"sasl-mechanism"`
+	// SASLUsername is the username for SASL authentication.
+	SASLUsername string `mapstructure:"sasl-username"`
+	// SASLPassword is the password for SASL authentication.
+	SASLPassword string `mapstructure:"sasl-password"`
+}
+
+// AddFlags registers persistent flags for the Kafka output.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(kafkaEnabled, false, "Determines whether the Kafka output is enabled")
+	flags.StringSlice(kafkaBrokers, []string{"localhost:9092"}, "A comma-separated list of bootstrap broker addresses")
+	flags.String(kafkaTopic, "fibratus", "The default topic to which events are produced")
+	flags.String(kafkaPartitionKey, "kevt.host", "The event field from which the message key is derived, e.g. kevt.host, ps.uuid, or kevt.category")
+	flags.String(kafkaClientID, "fibratus", "The user-provided string sent with every request to brokers")
+	flags.String(kafkaVersion, "2.1.0", "The Kafka protocol version")
+	flags.String(kafkaCompression, "none", "The message compression codec. Possible values are none, gzip, snappy, lz4, and zstd")
+	flags.String(kafkaRequiredAcks, "all", "The delivery acknowledgement level. Possible values are none, leader, and all")
+	flags.Bool(kafkaIdempotent, false, "Enables the idempotent producer that guarantees exactly one copy of each message is written")
+	flags.Duration(kafkaTimeout, time.Second*10, "Represents the timeout for broker requests")
+	flags.Int(kafkaRetryMax, 3, "The maximum number of times to retry sending a message")
+	flags.Int(kafkaMaxMessageBytes, 1000000, "The maximum permitted size of a message")
+	flags.Bool(kafkaEnableTLS, false, "Determines whether the TLS transport is used to connect to brokers")
+	flags.String(kafkaSASLMechanism, "", "The SASL authentication mechanism. Possible values are PLAIN, SCRAM-SHA-256, and SCRAM-SHA-512")
+	flags.String(kafkaSASLUsername, "", "The username for SASL authentication")
+	flags.String(kafkaSASLPassword, "", "The password for SASL authentication")
+	outputs.AddTLSFlags(flags, outputs.Kafka)
+}
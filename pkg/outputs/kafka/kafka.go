@@ -0,0 +1,219 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka
+
+import (
+	"expvar"
+	"fmt"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/util/tls"
+)
+
+// kafkaMessages counts the total number of produced messages
+var kafkaMessages = expvar.NewInt("output.kafka.publish.messages")
+
+type kafka struct {
+	config   Config
+	sconfig  *sarama.Config
+	producer sarama.SyncProducer
+}
+
+func init() {
+	outputs.Register(outputs.Kafka, initKafka)
+}
+
+func initKafka(config outputs.Config) (outputs.OutputGroup, error) {
+	cfg, ok := config.Output.(Config)
+	if !ok {
+		return outputs.Fail(outputs.ErrInvalidConfig(outputs.Kafka, config.Output))
+	}
+	if len(cfg.Brokers) == 0 {
+		return outputs.Fail(fmt.Errorf("at least one Kafka broker is required"))
+	}
+	if cfg.Topic == "" {
+		return outputs.Fail(fmt.Errorf("default Kafka topic is required"))
+	}
+	sconfig, err := newSaramaConfig(cfg)
+	if err != nil {
+		return outputs.Fail(err)
+	}
+	return outputs.Success(&kafka{config: cfg, sconfig: sconfig}), nil
+}
+
+func (k *kafka) Connect() error {
+	var err error
+	k.producer, err = sarama.NewSyncProducer(k.config.Brokers, k.sconfig)
+	return err
+}
+
+func (k *kafka) Close() error {
+	if k.producer == nil {
+		return nil
+	}
+	return k.producer.Close()
+}
+
+// Publish produces every event in the batch as a separate message. Brokers
+// acknowledge the messages according to the configured acks level. If any
+// of the messages isn't delivered after exhausting all retries, the returned
+// error contains the individual delivery errors.
+func (k *kafka) Publish(batch *kevent.Batch) error {
+	msgs := make([]*sarama.ProducerMessage, 0, len(batch.Events))
+	for _, e := range batch.Events {
+		msgs = append(msgs, k.message(e))
+	}
+	if err := k.producer.SendMessages(msgs); err != nil {
+		if errs, ok := err.(sarama.ProducerErrors); ok {
+			kafkaMessages.Add(int64(len(msgs) - len(errs)))
+		}
+		return err
+	}
+	kafkaMessages.Add(int64(len(msgs)))
+	return nil
+}
+
+// message builds the producer message from the event. The topic is
+// resolved from the event category, and the message key is derived
+// from the partition key field, so all events sharing the same field
+// value land in the same partition.
+func (k *kafka) message(e *kevent.Kevent) *sarama.ProducerMessage {
+	msg := &sarama.ProducerMessage{
+		Topic:     k.config.Topic,
+		Value:     sarama.ByteEncoder(e.MarshalJSON()),
+		Timestamp: e.Timestamp,
+	}
+	if topic, ok := k.config.Topics[string(e.Category)]; ok {
+		msg.Topic = topic
+	}
+	if k.config.PartitionKey != "" {
+		if key, ok := outputs.FieldValue(e, k.config.PartitionKey); ok {
+			msg.Key = sarama.StringEncoder(key)
+		}
+	}
+	return msg
+}
+
+func newSaramaConfig(config Config) (*sarama.Config, error) {
+	c := sarama.NewConfig()
+	if config.ClientID != "" {
+		c.ClientID = config.ClientID
+	}
+
+	if config.Version != "" {
+		version, err := sarama.ParseKafkaVersion(config.Version)
+		if err != nil {
+			return nil, err
+		}
+		c.Version = version
+	}
+
+	// required by the sync producer
+	c.Producer.Return.Successes = true
+	c.Producer.Return.Errors = true
+	c.Producer.Partitioner = sarama.NewHashPartitioner
+
+	switch strings.ToLower(config.RequiredAcks) {
+	case "none":
+		c.Producer.RequiredAcks = sarama.NoResponse
+	case "leader":
+		c.Producer.RequiredAcks = sarama.WaitForLocal
+	case "all", "":
+		c.Producer.RequiredAcks = sarama.WaitForAll
+	default:
+		return nil, fmt.Errorf("unknown Kafka required acks: %s", config.RequiredAcks)
+	}
+
+	switch strings.ToLower(config.Compression) {
+	case "none", "":
+		c.Producer.Compression = sarama.CompressionNone
+	case "gzip":
+		c.Producer.Compression = sarama.CompressionGZIP
+	case "snappy":
+		c.Producer.Compression = sarama.CompressionSnappy
+	case "lz4":
+		c.Producer.Compression = sarama.CompressionLZ4
+	case "zstd":
+		c.Producer.Compression = sarama.CompressionZSTD
+	default:
+		return nil, fmt.Errorf("unknown Kafka compression codec: %s", config.Compression)
+	}
+
+	if config.RetryMax > 0 {
+		c.Producer.Retry.Max = config.RetryMax
+	}
+	if config.MaxMessageBytes > 0 {
+		c.Producer.MaxMessageBytes = config.MaxMessageBytes
+	}
+	if config.Timeout > 0 {
+		c.Producer.Timeout = config.Timeout
+		c.Net.DialTimeout = config.Timeout
+		c.Net.ReadTimeout = config.Timeout
+		c.Net.WriteTimeout = config.Timeout
+	}
+
+	if config.Idempotent {
+		// the idempotent producer requires acks from all in-sync
+		// replicas and allows a single in-flight request so the
+		// sequence numbers are assigned in order
+		c.Producer.Idempotent = true
+		c.Producer.RequiredAcks = sarama.WaitForAll
+		c.Net.MaxOpenRequests = 1
+		if c.Producer.Retry.Max == 0 {
+			c.Producer.Retry.Max = 1
+		}
+	}
+
+	if config.EnableTLS {
+		tlsConfig, err := tls.MakeConfig(config.TLSCert, config.TLSKey, config.TLSCA, config.TLSInsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		c.Net.TLS.Enable = true
+		c.Net.TLS.Config = tlsConfig
+	}
+
+	if config.SASLMechanism != "" {
+		c.Net.SASL.Enable = true
+		c.Net.SASL.User = config.SASLUsername
+		c.Net.SASL.Password = config.SASLPassword
+		switch strings.ToUpper(config.SASLMechanism) {
+		case sarama.SASLTypePlaintext:
+			c.Net.SASL.Mechanism = sarama.SASLTypePlaintext
+		case sarama.SASLTypeSCRAMSHA256:
+			c.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
+			c.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hash: sha256Hash} }
+		case sarama.SASLTypeSCRAMSHA512:
+			c.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA512
+			c.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &scramClient{hash: sha512Hash} }
+		default:
+			return nil, fmt.Errorf("unsupported Kafka SASL mechanism: %s", config.SASLMechanism)
+		}
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Kafka producer config: %v", err)
+	}
+
+	return c, nil
+}
@@ -0,0 +1,234 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package kafka
+
+import (
+	"expvar"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+func TestKafkaPublish(t *testing.T) {
+	var tests = []struct {
+		name   string
+		config Config
+	}{
+		{
+			"default",
+			Config{PartitionKey: "kevt.host"},
+		},
+		{
+			"zstd compression",
+			Config{PartitionKey: "ps.uuid", Compression: "zstd"},
+		},
+		{
+			"idempotent producer",
+			Config{PartitionKey: "kevt.category", Compression: "snappy", Idempotent: true},
+		},
+		{
+			"leader acks",
+			Config{Compression: "lz4", RequiredAcks: "leader"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := sarama.NewMockBroker(t, 1)
+			defer broker.Close()
+			broker.SetHandlerByMap(map[string]sarama.MockResponse{
+				"MetadataRequest": sarama.NewMockMetadataResponse(t).
+					SetBroker(broker.Addr(), broker.BrokerID()).
+					SetLeader("fibratus", 0, broker.BrokerID()).
+					SetLeader("fibratus", 1, broker.BrokerID()).
+					SetLeader("fibratus-net", 0, broker.BrokerID()),
+				"ProduceRequest":        sarama.NewMockProduceResponse(t),
+				"InitProducerIDRequest": sarama.NewMockInitProducerIDResponse(t),
+			})
+
+			c := tt.config
+			c.Brokers = []string{broker.Addr()}
+			c.Topic = "fibratus"
+			c.Topics = map[string]string{"net": "fibratus-net"}
+			c.Version = "2.1.0"
+			c.Timeout = time.Second * 5
+
+			group, err := initKafka(outputs.Config{Type: outputs.Kafka, Output: c})
+			require.NoError(t, err)
+			require.Len(t, group.Clients, 1)
+			k := group.Clients[0]
+			require.NoError(t, k.Connect())
+			defer k.Close()
+
+			msgs := expvar.Get("output.kafka.publish.messages").(*expvar.Int).Value()
+			require.NoError(t, k.Publish(getBatch()))
+			assert.Equal(t, msgs+4, expvar.Get("output.kafka.publish.messages").(*expvar.Int).Value())
+
+			var produces int
+			for _, rr := range broker.History() {
+				if _, ok := rr.Request.(*sarama.ProduceRequest); ok {
+					produces++
+				}
+			}
+			assert.True(t, produces > 0)
+		})
+	}
+}
+
+func TestKafkaPublishError(t *testing.T) {
+	broker := sarama.NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("fibratus", 0, broker.BrokerID()),
+		"ProduceRequest": sarama.NewMockProduceResponse(t).
+			SetError("fibratus", 0, sarama.ErrMessageSizeTooLarge),
+	})
+
+	group, err := initKafka(outputs.Config{Type: outputs.Kafka, Output: Config{
+		Brokers: []string{broker.Addr()},
+		Topic:   "fibratus",
+		Timeout: time.Second * 5,
+	}})
+	require.NoError(t, err)
+	k := group.Clients[0]
+	require.NoError(t, k.Connect())
+	defer k.Close()
+
+	err = k.Publish(kevent.NewBatch(getEvent(1, "archrabbit", ktypes.File)))
+	require.Error(t, err)
+	errs, ok := err.(sarama.ProducerErrors)
+	require.True(t, ok)
+	require.Len(t, errs, 1)
+	assert.Equal(t, sarama.ErrMessageSizeTooLarge, errs[0].Err)
+}
+
+func TestKafkaMessage(t *testing.T) {
+	e := getEvent(1, "archrabbit", ktypes.Net)
+
+	var tests = []struct {
+		partitionKey string
+		topic        string
+		key          sarama.Encoder
+	}{
+		{"kevt.host", "fibratus-net", sarama.StringEncoder("archrabbit")},
+		{"kevt.category", "fibratus-net", sarama.StringEncoder("net")},
+		{"ps.uuid", "fibratus-net", sarama.StringEncoder(strconv.FormatUint(e.PS.UUID(), 10))},
+		{"kevt.arg[file_path]", "fibratus-net", sarama.StringEncoder(`C:\Windows\system32\user32.dll`)},
+		{"kevt.arg[dport]", "fibratus-net", nil},
+		{"", "fibratus-net", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.partitionKey, func(t *testing.T) {
+			k := &kafka{config: Config{
+				Topic:        "fibratus",
+				Topics:       map[string]string{"net": "fibratus-net"},
+				PartitionKey: tt.partitionKey,
+			}}
+			msg := k.message(e)
+			assert.Equal(t, tt.topic, msg.Topic)
+			assert.Equal(t, tt.key, msg.Key)
+			assert.Equal(t, e.Timestamp, msg.Timestamp)
+		})
+	}
+
+	k := &kafka{config: Config{Topic: "fibratus", PartitionKey: "kevt.host"}}
+	assert.Equal(t, "fibratus", k.message(getEvent(2, "archrabbit", ktypes.File)).Topic)
+
+	// events from the same host are always routed to the same partition
+	p := sarama.NewHashPartitioner("fibratus")
+	p1, err := p.Partition(k.message(getEvent(1, "archrabbit", ktypes.File)), 8)
+	require.NoError(t, err)
+	p2, err := p.Partition(k.message(getEvent(2, "archrabbit", ktypes.Registry)), 8)
+	require.NoError(t, err)
+	assert.Equal(t, p1, p2)
+}
+
+func TestNewSaramaConfig(t *testing.T) {
+	c, err := newSaramaConfig(Config{Idempotent: true, RequiredAcks: "none", Version: "2.1.0"})
+	require.NoError(t, err)
+	assert.True(t, c.Producer.Idempotent)
+	assert.Equal(t, sarama.WaitForAll, c.Producer.RequiredAcks)
+	assert.Equal(t, 1, c.Net.MaxOpenRequests)
+
+	c, err = newSaramaConfig(Config{SASLMechanism: "scram-sha-512", SASLUsername: "fibratus", SASLPassword: "secret", Compression: "zstd", Version: "2.1.0"})
+	require.NoError(t, err)
+	assert.True(t, c.Net.SASL.Enable)
+	assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA512), c.Net.SASL.Mechanism)
+	assert.NotNil(t, c.Net.SASL.SCRAMClientGeneratorFunc)
+	assert.Equal(t, sarama.CompressionZSTD, c.Producer.Compression)
+
+	_, err = newSaramaConfig(Config{Compression: "brotli"})
+	require.Error(t, err)
+	_, err = newSaramaConfig(Config{RequiredAcks: "some"})
+	require.Error(t, err)
+	_, err = newSaramaConfig(Config{SASLMechanism: "GSSAPI"})
+	require.Error(t, err)
+	// zstd requires at least Kafka 2.1.0
+	_, err = newSaramaConfig(Config{Compression: "zstd", Version: "1.0.0"})
+	require.Error(t, err)
+}
+
+func getBatch() *kevent.Batch {
+	return kevent.NewBatch(
+		getEvent(1, "archrabbit", ktypes.File),
+		getEvent(2, "archrabbit", ktypes.Net),
+		getEvent(3, "rabbitstack", ktypes.File),
+		getEvent(4, "rabbitstack", ktypes.Process),
+	)
+}
+
+func getEvent(seq uint64, host string, category ktypes.Category) *kevent.Kevent {
+	return &kevent.Kevent{
+		Type:        ktypes.CreateFile,
+		Tid:         2484,
+		PID:         859,
+		CPU:         1,
+		Seq:         seq,
+		Name:        "CreateFile",
+		Timestamp:   time.Now(),
+		Category:    category,
+		Host:        host,
+		Description: "Creates or opens a new file, directory, I/O device, pipe, console",
+		Kparams: kevent.Kparams{
+			kparams.FilePath:      {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: `C:\Windows\system32\user32.dll`},
+			kparams.FileOperation: {Name: kparams.FileOperation, Type: kparams.AnsiString, Value: "open"},
+		},
+		PS: &pstypes.PS{
+			PID:       859,
+			Ppid:      6304,
+			Name:      "firefox.exe",
+			Exe:       `C:\Program Files\Mozilla Firefox\firefox.exe`,
+			SID:       "S-1-5-18",
+			StartTime: time.Now(),
+		},
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+const (
+	otlpEnabled     = "output.otlp.enabled"
+	otlpEndpoint    = "output.otlp.endpoint"
+	otlpProtocol    = "output.otlp.protocol"
+	otlpTimeout     = "output.otlp.timeout"
+	otlpEnableGzip  = "output.otlp.enable-gzip"
+	otlpServiceName = "output.otlp.service-name"
+)
+
+// Protocol designates the OTLP transport and encoding.
+type Protocol string
+
+const (
+	// GRPC is the OTLP/gRPC protocol.
+	GRPC Protocol = "grpc"
+	// HTTPProtobuf is the OTLP/HTTP protocol with binary protobuf payloads.
+	HTTPProtobuf Protocol = "http/protobuf"
+	// HTTPJSON is the OTLP/HTTP protocol with JSON payloads.
+	HTTPJSON Protocol = "http/json"
+)
+
+// Config contains the options for tweaking the OTLP output behaviour.
+type Config struct {
+	outputs.TLSConfig
+	// Enabled determines whether OTLP output is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoint is the URL of the OTLP receiver. The https scheme enables the TLS transport.
+	Endpoint string `mapstructure:"endpoint"`
+	// Protocol is the OTLP transport protocol. It can be one of grpc, http/protobuf, or http/json.
+	Protocol Protocol `mapstructure:"protocol"`
+	// Headers contains a list of additional headers or gRPC metadata sent with every export request.
+	Headers map[string]string `mapstructure:"headers"`
+	// Timeout represents the timeout for export requests.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// EnableGzip specifies whether the gzip compression is enabled.
+	EnableGzip bool `mapstructure:"enable-gzip"`
+	// ServiceName is the value of the service.name resource attribute.
+	ServiceName string `mapstructure:"service-name"`
+	// ResourceAttributes contains additional resource attributes.
+	ResourceAttributes map[string]string `mapstructure:"resource-attributes"`
+}
+
+// AddFlags registers persistent flags for the OTLP output.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(otlpEnabled, false, "Determines whether the OTLP output is enabled")
+	flags.String(otlpEndpoint, "http://localhost:4318", "The URL of the OTLP receiver. The https scheme enables the TLS transport")
+	flags.String(otlpProtocol, string(HTTPProtobuf), "The OTLP transport protocol. Possible values are grpc, http/protobuf, and http/json")
+	flags.Duration(otlpTimeout, time.Second*10, "Represents the timeout for export requests")
+	flags.Bool(otlpEnableGzip, false, "Indicates whether the gzip compression is enabled")
+	flags.String(otlpServiceName, "fibratus", "The value of the service.name resource attribute")
+	outputs.AddTLSFlags(flags, outputs.OTLP)
+}
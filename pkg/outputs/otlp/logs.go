@@ -0,0 +1,246 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	logspb "go.opentelemetry.io/proto/otlp/logs/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+)
+
+// scopeName is the name of the instrumentation scope of exported log records
+const scopeName = "github.com/rabbitstack/fibratus"
+
+// newRequest converts the batch into the export request. Log records are
+// grouped by the host of the originating event, so each host is described
+// by its own resource.
+func (o *otlp) newRequest(batch *kevent.Batch) *collogspb.ExportLogsServiceRequest {
+	req := &collogspb.ExportLogsServiceRequest{}
+	scopes := make(map[string]*logspb.ScopeLogs)
+	now := uint64(time.Now().UnixNano())
+
+	for _, e := range batch.Events {
+		scope, ok := scopes[e.Host]
+		if !ok {
+			scope = &logspb.ScopeLogs{
+				Scope: &commonpb.InstrumentationScope{Name: scopeName, Version: version.Get()},
+			}
+			scopes[e.Host] = scope
+			req.ResourceLogs = append(req.ResourceLogs, &logspb.ResourceLogs{
+				Resource:  o.resource(e.Host),
+				ScopeLogs: []*logspb.ScopeLogs{scope},
+			})
+		}
+		rec := logRecord(e)
+		rec.ObservedTimeUnixNano = now
+		scope.LogRecords = append(scope.LogRecords, rec)
+	}
+
+	return req
+}
+
+// resource builds the resource describing the host.
+func (o *otlp) resource(host string) *resourcepb.Resource {
+	attrs := []*commonpb.KeyValue{
+		stringAttr("service.name", o.config.ServiceName),
+		stringAttr("service.version", version.Get()),
+		stringAttr("os.type", "windows"),
+	}
+	if host != "" {
+		attrs = append(attrs, stringAttr("host.name", host))
+	}
+	keys := make([]string, 0, len(o.config.ResourceAttributes))
+	for k := range o.config.ResourceAttributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, stringAttr(k, o.config.ResourceAttributes[k]))
+	}
+	return &resourcepb.Resource{Attributes: attrs}
+}
+
+// logRecord converts the event into the log record. The event description is
+// the record body, while the event fields, parameters, metadata and process
+// state are carried in attributes named after the corresponding filter fields.
+func logRecord(e *kevent.Kevent) *logspb.LogRecord {
+	body := e.Description
+	if body == "" {
+		body = e.Name
+	}
+	rec := &logspb.LogRecord{
+		TimeUnixNano:   uint64(e.Timestamp.UnixNano()),
+		SeverityNumber: logspb.SeverityNumber_SEVERITY_NUMBER_INFO,
+		SeverityText:   "INFO",
+		Body:           &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: body}},
+	}
+
+	attrs := []*commonpb.KeyValue{
+		intAttr("kevt.seq", uint64ToInt(e.Seq)),
+		intAttr("kevt.pid", int64(e.PID)),
+		intAttr("kevt.tid", int64(e.Tid)),
+		intAttr("kevt.cpu", int64(e.CPU)),
+		stringAttr("kevt.name", e.Name),
+		stringAttr("kevt.category", string(e.Category)),
+	}
+
+	names := make([]string, 0, len(e.Kparams))
+	for name := range e.Kparams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		attrs = append(attrs, &commonpb.KeyValue{Key: "kevt.arg." + name, Value: paramValue(e.Kparams[name])})
+	}
+
+	keys := make([]string, 0, len(e.Metadata))
+	for k := range e.Metadata {
+		keys = append(keys, string(k))
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		attrs = append(attrs, stringAttr("kevt.meta."+k, e.Metadata[kevent.MetadataKey(k)]))
+	}
+
+	if ps := e.PS; ps != nil {
+		attrs = append(attrs, processAttrs("ps.", ps)...)
+		if ps.Parent != nil {
+			attrs = append(attrs, processAttrs("ps.parent.", ps.Parent)...)
+		}
+	}
+
+	rec.Attributes = attrs
+
+	return rec
+}
+
+func processAttrs(prefix string, ps *pstypes.PS) []*commonpb.KeyValue {
+	attrs := []*commonpb.KeyValue{
+		intAttr(prefix+"pid", int64(ps.PID)),
+		intAttr(prefix+"ppid", int64(ps.Ppid)),
+		stringAttr(prefix+"name", ps.Name),
+		stringAttr(prefix+"exe", ps.Exe),
+		stringAttr(prefix+"cmdline", ps.Cmdline),
+		stringAttr(prefix+"cwd", ps.Cwd),
+		stringAttr(prefix+"sid", ps.SID),
+		stringAttr(prefix+"username", ps.Username),
+		stringAttr(prefix+"domain", ps.Domain),
+		intAttr(prefix+"sessionid", int64(ps.SessionID)),
+		stringAttr(prefix+"uuid", strconv.FormatUint(ps.UUID(), 10)),
+	}
+	// drop empty string attributes
+	n := 0
+	for _, attr := range attrs {
+		if v, ok := attr.Value.Value.(*commonpb.AnyValue_StringValue); ok && v.StringValue == "" {
+			continue
+		}
+		attrs[n] = attr
+		n++
+	}
+	return attrs[:n]
+}
+
+// paramValue maps the event parameter to the attribute value. Numeric
+// and boolean parameters retain their types, while the rest of the
+// parameters are rendered to strings.
+func paramValue(kpar *kevent.Kparam) *commonpb.AnyValue {
+	switch kpar.Type {
+	case kparams.Int8, kparams.Int16, kparams.Int32, kparams.Int64,
+		kparams.Uint8, kparams.Uint16, kparams.Uint32, kparams.Uint64,
+		kparams.PID, kparams.TID, kparams.Port:
+		if v, ok := toInt(kpar.Value); ok {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}
+		}
+	case kparams.Float, kparams.Double:
+		switch v := kpar.Value.(type) {
+		case float32:
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: float64(v)}}
+		case float64:
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_DoubleValue{DoubleValue: v}}
+		}
+	case kparams.Bool:
+		if v, ok := kpar.Value.(bool); ok {
+			return &commonpb.AnyValue{Value: &commonpb.AnyValue_BoolValue{BoolValue: v}}
+		}
+	}
+	return &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: kpar.String()}}
+}
+
+func toInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func uint64ToInt(n uint64) int64 {
+	if n > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return int64(n)
+}
+
+func intAttr(key string, v int64) *commonpb.KeyValue {
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_IntValue{IntValue: v}}}
+}
+
+func stringAttr(key string, v any) *commonpb.KeyValue {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case interface{ String() string }:
+		s = v.String()
+	default:
+		s = fmt.Sprintf("%v", v)
+	}
+	return &commonpb.KeyValue{Key: key, Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: s}}}
+}
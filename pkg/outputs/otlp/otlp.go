@@ -0,0 +1,260 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	grpcgzip "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/util/tls"
+)
+
+// logsPath is the default URL path of the OTLP/HTTP logs receiver
+const logsPath = "/v1/logs"
+
+var (
+	// otlpLogRecords counts the number of exported log records
+	otlpLogRecords = expvar.NewInt("output.otlp.log.records")
+	// otlpRejectedLogRecords counts the number of log records rejected by the receiver
+	otlpRejectedLogRecords = expvar.NewInt("output.otlp.rejected.log.records")
+)
+
+type otlp struct {
+	config Config
+	url    string
+
+	client *http.Client
+
+	conn *grpc.ClientConn
+	logs collogspb.LogsServiceClient
+}
+
+func init() {
+	outputs.Register(outputs.OTLP, initOTLP)
+}
+
+func initOTLP(config outputs.Config) (outputs.OutputGroup, error) {
+	cfg, ok := config.Output.(Config)
+	if !ok {
+		return outputs.Fail(outputs.ErrInvalidConfig(outputs.OTLP, config.Output))
+	}
+	if cfg.Protocol == "" {
+		cfg.Protocol = HTTPProtobuf
+	}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "fibratus"
+	}
+	switch cfg.Protocol {
+	case GRPC, HTTPProtobuf, HTTPJSON:
+	default:
+		return outputs.Fail(fmt.Errorf("unknown OTLP protocol %q", cfg.Protocol))
+	}
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Host == "" {
+		return outputs.Fail(fmt.Errorf("invalid OTLP endpoint %q", cfg.Endpoint))
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return outputs.Fail(fmt.Errorf("invalid OTLP endpoint %q: scheme must be http or https", cfg.Endpoint))
+	}
+	o := &otlp{config: cfg}
+	if cfg.Protocol == GRPC {
+		o.url = u.Host
+	} else {
+		if u.Path == "" || u.Path == "/" {
+			u.Path = logsPath
+		}
+		o.url = u.String()
+	}
+	return outputs.Success(o), nil
+}
+
+func (o *otlp) Connect() error {
+	tlsConfig, err := tls.MakeConfig(o.config.TLSCert, o.config.TLSKey, o.config.TLSCA, o.config.TLSInsecureSkipVerify)
+	if err != nil {
+		return fmt.Errorf("invalid TLS config: %v", err)
+	}
+	secure := strings.HasPrefix(o.config.Endpoint, "https")
+
+	if o.config.Protocol != GRPC {
+		o.client = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConfig,
+				Proxy:           http.ProxyFromEnvironment,
+			},
+			Timeout: o.config.Timeout,
+		}
+		return nil
+	}
+
+	creds := insecure.NewCredentials()
+	if secure {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	opts := []grpc.DialOption{grpc.WithTransportCredentials(creds)}
+	if o.config.EnableGzip {
+		opts = append(opts, grpc.WithDefaultCallOptions(grpc.UseCompressor(grpcgzip.Name)))
+	}
+	o.conn, err = grpc.NewClient(o.url, opts...)
+	if err != nil {
+		return fmt.Errorf("unable to create OTLP gRPC client for %s: %v", o.url, err)
+	}
+	o.logs = collogspb.NewLogsServiceClient(o.conn)
+	return nil
+}
+
+func (o *otlp) Close() error {
+	if o.conn != nil {
+		return o.conn.Close()
+	}
+	if o.client != nil {
+		o.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// Publish exports all events in the batch within a single request.
+func (o *otlp) Publish(batch *kevent.Batch) error {
+	req := o.newRequest(batch)
+	var err error
+	if o.config.Protocol == GRPC {
+		err = o.exportGRPC(req)
+	} else {
+		err = o.exportHTTP(req)
+	}
+	if err != nil {
+		return err
+	}
+	otlpLogRecords.Add(batch.Len())
+	return nil
+}
+
+func (o *otlp) exportGRPC(req *collogspb.ExportLogsServiceRequest) error {
+	ctx := context.Background()
+	if o.config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, o.config.Timeout)
+		defer cancel()
+	}
+	if len(o.config.Headers) > 0 {
+		ctx = metadata.NewOutgoingContext(ctx, metadata.New(o.config.Headers))
+	}
+	resp, err := o.logs.Export(ctx, req)
+	if err != nil {
+		return fmt.Errorf("unable to export OTLP logs: %v", err)
+	}
+	o.partialSuccess(resp)
+	return nil
+}
+
+func (o *otlp) exportHTTP(req *collogspb.ExportLogsServiceRequest) error {
+	var (
+		body        []byte
+		err         error
+		contentType string
+	)
+	if o.config.Protocol == HTTPJSON {
+		contentType = "application/json"
+		body, err = protojson.MarshalOptions{UseEnumNumbers: true}.Marshal(req)
+	} else {
+		contentType = "application/x-protobuf"
+		body, err = proto.Marshal(req)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to encode OTLP logs: %v", err)
+	}
+
+	var r io.Reader = bytes.NewReader(body)
+	if o.config.EnableGzip {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		if _, err := gz.Write(body); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		r = &buf
+	}
+
+	hreq, err := http.NewRequest(http.MethodPost, o.url, r)
+	if err != nil {
+		return err
+	}
+	for k, v := range o.config.Headers {
+		hreq.Header.Set(k, v)
+	}
+	hreq.Header.Set("Content-Type", contentType)
+	if o.config.EnableGzip {
+		hreq.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := o.client.Do(hreq)
+	if err != nil {
+		return fmt.Errorf("unable to export OTLP logs: %v", err)
+	}
+	defer resp.Body.Close()
+	rbody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("OTLP receiver %s returned %s", o.url, resp.Status)
+	}
+
+	if len(rbody) == 0 {
+		return nil
+	}
+	var exported collogspb.ExportLogsServiceResponse
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		err = protojson.Unmarshal(rbody, &exported)
+	} else {
+		err = proto.Unmarshal(rbody, &exported)
+	}
+	if err == nil {
+		o.partialSuccess(&exported)
+	}
+	return nil
+}
+
+// partialSuccess records log records the receiver refused to accept. The request
+// is not retried because the rejected records are deemed unprocessable.
+func (o *otlp) partialSuccess(resp *collogspb.ExportLogsServiceResponse) {
+	ps := resp.GetPartialSuccess()
+	if ps == nil || ps.GetRejectedLogRecords() == 0 {
+		return
+	}
+	otlpRejectedLogRecords.Add(ps.GetRejectedLogRecords())
+	log.Warnf("OTLP receiver rejected %d log records: %s", ps.GetRejectedLogRecords(), ps.GetErrorMessage())
+}
@@ -0,0 +1,245 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	collogspb "go.opentelemetry.io/proto/otlp/collector/logs/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/grpc"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+func TestOTLPHTTPPublish(t *testing.T) {
+	var tests = []struct {
+		name       string
+		protocol   Protocol
+		enableGzip bool
+	}{
+		{"protobuf", HTTPProtobuf, false},
+		{"json", HTTPJSON, false},
+		{"protobuf gzip", HTTPProtobuf, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req collogspb.ExportLogsServiceRequest
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, logsPath, r.URL.Path)
+				assert.Equal(t, "secret", r.Header.Get("X-Api-Key"))
+				var body io.Reader = r.Body
+				if tt.enableGzip {
+					assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+					gz, err := gzip.NewReader(r.Body)
+					require.NoError(t, err)
+					body = gz
+				}
+				b, err := io.ReadAll(body)
+				require.NoError(t, err)
+				if tt.protocol == HTTPJSON {
+					assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+					require.NoError(t, protojson.Unmarshal(b, &req))
+				} else {
+					assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+					require.NoError(t, proto.Unmarshal(b, &req))
+				}
+				w.Header().Set("Content-Type", "application/x-protobuf")
+				resp, _ := proto.Marshal(&collogspb.ExportLogsServiceResponse{})
+				_, _ = w.Write(resp)
+			}))
+			defer srv.Close()
+
+			group, err := initOTLP(outputs.Config{Type: outputs.OTLP, Output: Config{
+				Endpoint:   srv.URL,
+				Protocol:   tt.protocol,
+				Headers:    map[string]string{"X-Api-Key": "secret"},
+				Timeout:    time.Second * 5,
+				EnableGzip: tt.enableGzip,
+			}})
+			require.NoError(t, err)
+			o := group.Clients[0]
+			require.NoError(t, o.Connect())
+			defer o.Close()
+
+			require.NoError(t, o.Publish(getBatch()))
+			assertRequest(t, &req)
+		})
+	}
+}
+
+func TestOTLPHTTPPublishError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	group, err := initOTLP(outputs.Config{Type: outputs.OTLP, Output: Config{Endpoint: srv.URL + "/logs"}})
+	require.NoError(t, err)
+	o := group.Clients[0]
+	assert.Equal(t, srv.URL+"/logs", o.(*otlp).url)
+	require.NoError(t, o.Connect())
+	defer o.Close()
+
+	require.Error(t, o.Publish(getBatch()))
+}
+
+type logsServer struct {
+	collogspb.UnimplementedLogsServiceServer
+	reqs chan *collogspb.ExportLogsServiceRequest
+	md   chan metadata.MD
+}
+
+func (s *logsServer) Export(ctx context.Context, req *collogspb.ExportLogsServiceRequest) (*collogspb.ExportLogsServiceResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	s.md <- md
+	s.reqs <- req
+	return &collogspb.ExportLogsServiceResponse{}, nil
+}
+
+func TestOTLPGRPCPublish(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	ls := &logsServer{reqs: make(chan *collogspb.ExportLogsServiceRequest, 1), md: make(chan metadata.MD, 1)}
+	collogspb.RegisterLogsServiceServer(srv, ls)
+	go func() { _ = srv.Serve(l) }()
+	defer srv.Stop()
+
+	group, err := initOTLP(outputs.Config{Type: outputs.OTLP, Output: Config{
+		Endpoint:   "http://" + l.Addr().String(),
+		Protocol:   GRPC,
+		Headers:    map[string]string{"x-api-key": "secret"},
+		Timeout:    time.Second * 5,
+		EnableGzip: true,
+	}})
+	require.NoError(t, err)
+	o := group.Clients[0]
+	require.NoError(t, o.Connect())
+	defer o.Close()
+
+	require.NoError(t, o.Publish(getBatch()))
+	assert.Equal(t, []string{"secret"}, (<-ls.md).Get("x-api-key"))
+	assertRequest(t, <-ls.reqs)
+}
+
+func TestInitOTLPInvalidConfig(t *testing.T) {
+	_, err := initOTLP(outputs.Config{Type: outputs.OTLP, Output: Config{Endpoint: "localhost:4317"}})
+	require.Error(t, err)
+	_, err = initOTLP(outputs.Config{Type: outputs.OTLP, Output: Config{Endpoint: "http://localhost:4317", Protocol: "thrift"}})
+	require.Error(t, err)
+}
+
+func TestLogRecord(t *testing.T) {
+	e := getEvent(1, "archrabbit")
+	rec := logRecord(e)
+
+	assert.Equal(t, uint64(e.Timestamp.UnixNano()), rec.TimeUnixNano)
+	assert.Equal(t, e.Description, rec.Body.GetStringValue())
+
+	attrs := attributes(rec.Attributes)
+	assert.Equal(t, int64(859), attrs["kevt.pid"].GetIntValue())
+	assert.Equal(t, "CreateFile", attrs["kevt.name"].GetStringValue())
+	assert.Equal(t, "file", attrs["kevt.category"].GetStringValue())
+	assert.Equal(t, `C:\Windows\system32\user32.dll`, attrs["kevt.arg.file_path"].GetStringValue())
+	assert.Equal(t, int64(443), attrs["kevt.arg.dport"].GetIntValue())
+	assert.Equal(t, "yes", attrs["kevt.meta.signed"].GetStringValue())
+	assert.Equal(t, "firefox.exe", attrs["ps.name"].GetStringValue())
+	assert.Equal(t, int64(6304), attrs["ps.ppid"].GetIntValue())
+	assert.Equal(t, "explorer.exe", attrs["ps.parent.name"].GetStringValue())
+	// empty process fields are omitted
+	assert.NotContains(t, attrs, "ps.cwd")
+}
+
+func assertRequest(t *testing.T, req *collogspb.ExportLogsServiceRequest) {
+	require.Len(t, req.ResourceLogs, 2)
+	hosts := make(map[string]int)
+	for _, rl := range req.ResourceLogs {
+		res := attributes(rl.Resource.Attributes)
+		assert.Equal(t, "fibratus", res["service.name"].GetStringValue())
+		assert.Equal(t, "windows", res["os.type"].GetStringValue())
+		require.Len(t, rl.ScopeLogs, 1)
+		hosts[res["host.name"].GetStringValue()] = len(rl.ScopeLogs[0].LogRecords)
+	}
+	assert.Equal(t, map[string]int{"archrabbit": 2, "rabbitstack": 1}, hosts)
+}
+
+func attributes(kvs []*commonpb.KeyValue) map[string]*commonpb.AnyValue {
+	attrs := make(map[string]*commonpb.AnyValue)
+	for _, kv := range kvs {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func getBatch() *kevent.Batch {
+	return kevent.NewBatch(
+		getEvent(1, "archrabbit"),
+		getEvent(2, "archrabbit"),
+		getEvent(3, "rabbitstack"),
+	)
+}
+
+func getEvent(seq uint64, host string) *kevent.Kevent {
+	return &kevent.Kevent{
+		Type:        ktypes.CreateFile,
+		Tid:         2484,
+		PID:         859,
+		CPU:         1,
+		Seq:         seq,
+		Name:        "CreateFile",
+		Timestamp:   time.Now(),
+		Category:    ktypes.File,
+		Host:        host,
+		Description: "Creates or opens a new file, directory, I/O device, pipe, console",
+		Kparams: kevent.Kparams{
+			kparams.FilePath:      {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: `C:\Windows\system32\user32.dll`},
+			kparams.FileOperation: {Name: kparams.FileOperation, Type: kparams.AnsiString, Value: "open"},
+			kparams.NetDport:      {Name: kparams.NetDport, Type: kparams.Port, Value: uint16(443)},
+		},
+		Metadata: kevent.Metadata{"signed": "yes"},
+		PS: &pstypes.PS{
+			PID:       859,
+			Ppid:      6304,
+			Name:      "firefox.exe",
+			Exe:       `C:\Program Files\Mozilla Firefox\firefox.exe`,
+			SID:       "S-1-5-18",
+			StartTime: time.Now(),
+			Parent:    &pstypes.PS{PID: 6304, Name: "explorer.exe"},
+		},
+	}
+}
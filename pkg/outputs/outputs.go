@@ -49,6 +49,16 @@ const (
 	HTTP
 	// Eventlog denotes the eventlog output.
 	Eventlog
+	// Syslog denotes the syslog output.
+	Syslog
+	// Kafka denotes the Kafka output.
+	Kafka
+	// OTLP denotes the OpenTelemetry protocol output.
+	OTLP
+	// File denotes the file output.
+	File
+	// Splunk denotes the Splunk HTTP Event Collector output.
+	Splunk
 	// Null is the null output.
 	Null
 	// Unknown is an undefined output type.
@@ -68,6 +78,16 @@ func (t Type) String() string {
 		return "http"
 	case Eventlog:
 		return "eventlog"
+	case Syslog:
+		return "syslog"
+	case Kafka:
+		return "kafka"
+	case OTLP:
+		return "otlp"
+	case File:
+		return "file"
+	case Splunk:
+		return "splunk"
 	case Null:
 		return "null"
 	default:
@@ -88,6 +108,16 @@ func TypeFromString(s string) Type {
 		return HTTP
 	case "eventlog":
 		return Eventlog
+	case "syslog":
+		return Syslog
+	case "kafka":
+		return Kafka
+	case "otlp":
+		return OTLP
+	case "file":
+		return File
+	case "splunk":
+		return Splunk
 	case "null":
 		return Null
 	default:
@@ -0,0 +1,374 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ecs maps events to the Elastic Common Schema (ECS) documents.
+package ecs
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/fs"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs/schema"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+)
+
+// Version is the ECS version the documents conform to.
+const Version = "8.11.0"
+
+// categories maps event categories to ECS event categories
+var categories = map[ktypes.Category][]string{
+	ktypes.Process:  {"process"},
+	ktypes.Thread:   {"process"},
+	ktypes.File:     {"file"},
+	ktypes.Registry: {"registry"},
+	ktypes.Net:      {"network"},
+	ktypes.Image:    {"library"},
+	ktypes.Driver:   {"driver"},
+}
+
+// types maps event types to ECS event types
+var types = map[ktypes.Ktype][]string{
+	ktypes.CreateProcess:      {"start"},
+	ktypes.TerminateProcess:   {"end"},
+	ktypes.OpenProcess:        {"access"},
+	ktypes.CreateThread:       {"start"},
+	ktypes.TerminateThread:    {"end"},
+	ktypes.OpenThread:         {"access"},
+	ktypes.SetThreadContext:   {"change"},
+	ktypes.ReadFile:           {"access"},
+	ktypes.WriteFile:          {"change"},
+	ktypes.SetFileInformation: {"change"},
+	ktypes.DeleteFile:         {"deletion"},
+	ktypes.RenameFile:         {"change"},
+	ktypes.EnumDirectory:      {"access"},
+	ktypes.MapViewFile:        {"access"},
+	ktypes.RegCreateKey:       {"creation"},
+	ktypes.RegOpenKey:         {"access"},
+	ktypes.RegQueryKey:        {"access"},
+	ktypes.RegQueryValue:      {"access"},
+	ktypes.RegSetValue:        {"change"},
+	ktypes.RegDeleteKey:       {"deletion"},
+	ktypes.RegDeleteValue:     {"deletion"},
+	ktypes.LoadImage:          {"start"},
+	ktypes.UnloadImage:        {"info"},
+	ktypes.ConnectTCPv4:       {"connection", "start"},
+	ktypes.ConnectTCPv6:       {"connection", "start"},
+	ktypes.AcceptTCPv4:        {"connection", "start"},
+	ktypes.AcceptTCPv6:        {"connection", "start"},
+	ktypes.DisconnectTCPv4:    {"connection", "end"},
+	ktypes.DisconnectTCPv6:    {"connection", "end"},
+	ktypes.QueryDNS:           {"protocol"},
+	ktypes.ReplyDNS:           {"protocol"},
+}
+
+// egress contains network events initiated by the local host
+var egress = map[ktypes.Ktype]bool{
+	ktypes.ConnectTCPv4: true, ktypes.ConnectTCPv6: true,
+	ktypes.SendTCPv4: true, ktypes.SendTCPv6: true,
+	ktypes.SendUDPv4: true, ktypes.SendUDPv6: true,
+	ktypes.ReconnectTCPv4: true, ktypes.ReconnectTCPv6: true,
+	ktypes.RetransmitTCPv4: true, ktypes.RetransmitTCPv6: true,
+}
+
+// hives maps registry root keys to their abbreviations
+var hives = map[string]string{
+	"HKEY_LOCAL_MACHINE":  "HKLM",
+	"HKEY_CURRENT_USER":   "HKCU",
+	"HKEY_USERS":          "HKU",
+	"HKEY_CLASSES_ROOT":   "HKCR",
+	"HKEY_CURRENT_CONFIG": "HKCC",
+}
+
+// threat maps the MITRE ATT&CK rule labels to ECS threat fields
+var threat = map[string]string{
+	"tactic.id":         "threat.tactic.id",
+	"tactic.name":       "threat.tactic.name",
+	"tactic.ref":        "threat.tactic.reference",
+	"technique.id":      "threat.technique.id",
+	"technique.name":    "threat.technique.name",
+	"technique.ref":     "threat.technique.reference",
+	"subtechnique.id":   "threat.technique.subtechnique.id",
+	"subtechnique.name": "threat.technique.subtechnique.name",
+	"subtechnique.ref":  "threat.technique.subtechnique.reference",
+}
+
+// Marshal encodes the event as the ECS JSON document.
+func Marshal(e *kevent.Kevent) ([]byte, error) {
+	return json.Marshal(Document(e))
+}
+
+// Document maps the event to the ECS document. Event parameters that
+// have no ECS counterpart are retained in the fibratus.params object.
+func Document(e *kevent.Kevent) schema.Doc {
+	doc := make(schema.Doc)
+
+	doc.Set("@timestamp", e.Timestamp.Format(time.RFC3339Nano))
+	doc.Set("message", e.Description)
+	doc.Set("ecs.version", Version)
+	doc.Set("agent.type", "fibratus")
+	doc.Set("agent.version", version.Get())
+	doc.Set("host.name", e.Host)
+	doc.Set("host.hostname", e.Host)
+	doc.Set("host.os.type", "windows")
+	doc.Set("host.os.family", "windows")
+	doc.Set("host.os.platform", "windows")
+
+	doc.Set("event.kind", "event")
+	doc.Set("event.action", e.Name)
+	doc.Set("event.sequence", e.Seq)
+	doc.Set("event.module", "fibratus")
+	doc.Set("event.dataset", "fibratus."+string(e.Category))
+	if c, ok := categories[e.Category]; ok {
+		doc.Set("event.category", c)
+	}
+	if t, ok := types[e.Type]; ok {
+		doc.Set("event.type", t)
+	}
+	if e.IsCreateFile() {
+		if isCreateDisposition(e) {
+			doc.Set("event.type", []string{"creation"})
+		} else {
+			doc.Set("event.type", []string{"access"})
+		}
+	}
+	if e.Kparams.Contains(kparams.NTStatus) {
+		if e.IsSuccess() {
+			doc.Set("event.outcome", "success")
+		} else {
+			doc.Set("event.outcome", "failure")
+		}
+	}
+
+	// the process that generated the event is the parent of
+	// the spawned process in process creation events
+	if e.IsCreateProcess() {
+		setProcessFromParams(doc, e)
+		setProcess(doc, "process.parent.", e.PS)
+	} else {
+		doc.Set("process.pid", e.PID)
+		setProcess(doc, "process.", e.PS)
+		if e.PS != nil {
+			setProcess(doc, "process.parent.", e.PS.Parent)
+		}
+	}
+	doc.Set("process.thread.id", e.Tid)
+	if ps := e.PS; ps != nil {
+		doc.Set("user.id", ps.SID)
+		doc.Set("user.name", ps.Username)
+		doc.Set("user.domain", ps.Domain)
+	}
+
+	switch e.Category {
+	case ktypes.File:
+		setFile(doc, "file.", schema.String(e, kparams.FilePath))
+	case ktypes.Image:
+		setFile(doc, "dll.", schema.String(e, kparams.ImagePath))
+		doc.Set("dll.code_signature.subject_name", schema.String(e, kparams.ImageCertSubject))
+		if e.Kparams.Contains(kparams.ImageSignatureType) {
+			signed := schema.String(e, kparams.ImageSignatureType) != "NONE"
+			doc.Set("dll.code_signature.exists", signed)
+		}
+	case ktypes.Registry:
+		setRegistry(doc, e)
+	case ktypes.Net:
+		if e.IsDNS() {
+			setDNS(doc, e)
+		} else {
+			setNetwork(doc, e)
+		}
+	}
+
+	setRule(doc, e)
+
+	params := make(schema.Doc)
+	for name, kpar := range e.Kparams {
+		params[name] = schema.Value(kpar)
+	}
+	if len(params) > 0 {
+		doc.Set("fibratus.params", params)
+	}
+	doc.Set("fibratus.cpu", e.CPU)
+
+	return doc
+}
+
+func setProcess(doc schema.Doc, prefix string, ps *pstypes.PS) {
+	if ps == nil {
+		return
+	}
+	doc.Set(prefix+"pid", ps.PID)
+	doc.Set(prefix+"name", ps.Name)
+	doc.Set(prefix+"executable", ps.Exe)
+	doc.Set(prefix+"command_line", ps.Cmdline)
+	doc.Set(prefix+"working_directory", ps.Cwd)
+	doc.Set(prefix+"entity_id", strconv.FormatUint(ps.UUID(), 10))
+	if !ps.StartTime.IsZero() {
+		doc.Set(prefix+"start", ps.StartTime.Format(time.RFC3339Nano))
+	}
+	doc.Set(prefix+"user.id", ps.SID)
+	doc.Set(prefix+"user.name", ps.Username)
+	if prefix == "process." {
+		doc.Set("process.parent.pid", ps.Ppid)
+	}
+}
+
+func setProcessFromParams(doc schema.Doc, e *kevent.Kevent) {
+	if pid, ok := schema.Int(e, kparams.ProcessID); ok {
+		doc.Set("process.pid", pid)
+	}
+	doc.Set("process.name", schema.String(e, kparams.ProcessName))
+	doc.Set("process.executable", schema.String(e, kparams.Exe))
+	doc.Set("process.command_line", schema.String(e, kparams.Cmdline))
+	doc.Set("process.user.id", schema.String(e, kparams.UserSID))
+	doc.Set("process.user.name", schema.String(e, kparams.Username))
+	if start, err := e.Kparams.GetTime(kparams.StartTime); err == nil && !start.IsZero() {
+		doc.Set("process.start", start.Format(time.RFC3339Nano))
+	}
+}
+
+func setFile(doc schema.Doc, prefix string, path string) {
+	if path == "" {
+		return
+	}
+	doc.Set(prefix+"path", path)
+	doc.Set(prefix+"name", schema.Base(path))
+	if prefix == "file." {
+		doc.Set("file.directory", schema.Dir(path))
+		doc.Set("file.extension", schema.Ext(path))
+	}
+}
+
+func setRegistry(doc schema.Doc, e *kevent.Kevent) {
+	path := schema.String(e, kparams.RegPath)
+	if path == "" {
+		return
+	}
+	doc.Set("registry.path", path)
+	key := path
+	if e.Type == ktypes.RegSetValue || e.Type == ktypes.RegDeleteValue || e.Type == ktypes.RegQueryValue {
+		doc.Set("registry.value", schema.Base(path))
+		key = schema.Dir(path)
+	}
+	root, subkey, _ := strings.Cut(key, `\`)
+	if hive, ok := hives[root]; ok {
+		doc.Set("registry.hive", hive)
+		doc.Set("registry.key", subkey)
+	} else {
+		doc.Set("registry.key", key)
+	}
+	if e.Kparams.Contains(kparams.RegValue) {
+		doc.Set("registry.data.strings", []string{schema.String(e, kparams.RegValue)})
+	}
+	doc.Set("registry.data.type", schema.String(e, kparams.RegValueType))
+}
+
+func setNetwork(doc schema.Doc, e *kevent.Kevent) {
+	if ip := schema.IP(e, kparams.NetSIP); ip != nil {
+		doc.Set("source.ip", ip.String())
+	}
+	if ip := schema.IP(e, kparams.NetDIP); ip != nil {
+		doc.Set("destination.ip", ip.String())
+	}
+	if port, ok := schema.Int(e, kparams.NetSport); ok {
+		doc.Set("source.port", port)
+	}
+	if port, ok := schema.Int(e, kparams.NetDport); ok {
+		doc.Set("destination.port", port)
+	}
+	if names := schema.Strings(e, kparams.NetDIPNames); len(names) > 0 {
+		doc.Set("destination.domain", names[0])
+	}
+	if size, ok := schema.Int(e, kparams.NetSize); ok {
+		doc.Set("network.bytes", size)
+	}
+	if e.IsNetworkUDP() {
+		doc.Set("network.transport", "udp")
+	} else {
+		doc.Set("network.transport", "tcp")
+	}
+	if schema.IsIPv6(e) {
+		doc.Set("network.type", "ipv6")
+	} else {
+		doc.Set("network.type", "ipv4")
+	}
+	if egress[e.Type] {
+		doc.Set("network.direction", "egress")
+	} else {
+		doc.Set("network.direction", "ingress")
+	}
+}
+
+func setDNS(doc schema.Doc, e *kevent.Kevent) {
+	doc.Set("network.protocol", "dns")
+	doc.Set("dns.question.name", schema.String(e, kparams.DNSName))
+	doc.Set("dns.question.type", schema.String(e, kparams.DNSRR))
+	if e.Type == ktypes.QueryDNS {
+		doc.Set("dns.type", "query")
+		return
+	}
+	doc.Set("dns.type", "answer")
+	doc.Set("dns.response_code", schema.String(e, kparams.DNSRcode))
+	var answers []schema.Doc
+	for _, answer := range schema.Strings(e, kparams.DNSAnswers) {
+		if answer != "" {
+			answers = append(answers, schema.Doc{"data": answer})
+		}
+	}
+	if len(answers) > 0 {
+		doc.Set("dns.answers", answers)
+	}
+}
+
+// setRule populates the rule and threat fields for events that
+// triggered a rule. The remaining metadata is stored in labels.
+func setRule(doc schema.Doc, e *kevent.Kevent) {
+	if name := schema.Meta(e, kevent.RuleNameKey); name != "" {
+		doc.Set("event.kind", "alert")
+		doc.Set("rule.name", name)
+	}
+	labels := make(schema.Doc)
+	for k := range e.Metadata {
+		switch k {
+		case kevent.RuleNameKey, kevent.RuleSequenceLink, kevent.RuleSequenceOOOKey:
+			continue
+		}
+		v := schema.Meta(e, k)
+		if field, ok := threat[string(k)]; ok {
+			doc.Set("threat.framework", "MITRE ATT&CK")
+			doc.Set(field, v)
+			continue
+		}
+		// ECS labels can't contain dots in their keys
+		labels[strings.ReplaceAll(string(k), ".", "_")] = v
+	}
+	if len(labels) > 0 {
+		doc.Set("labels", labels)
+	}
+}
+
+func isCreateDisposition(e *kevent.Kevent) bool {
+	op, err := e.Kparams.GetUint32(kparams.FileOperation)
+	return err == nil && op == fs.FileCreate
+}
@@ -0,0 +1,211 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ecs
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/fs"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+func TestDocument(t *testing.T) {
+	ps := &pstypes.PS{
+		PID:      2436,
+		Ppid:     6304,
+		Name:     "powershell.exe",
+		Exe:      `C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`,
+		Cmdline:  `powershell.exe -nop`,
+		SID:      "S-1-5-18",
+		Username: "SYSTEM",
+		Domain:   "NT AUTHORITY",
+		Parent:   &pstypes.PS{PID: 6304, Name: "explorer.exe"},
+	}
+
+	var tests = []struct {
+		name   string
+		e      *kevent.Kevent
+		fields map[string]any
+	}{
+		{
+			"create file",
+			&kevent.Kevent{
+				Type:     ktypes.CreateFile,
+				Name:     "CreateFile",
+				Category: ktypes.File,
+				Kparams: kevent.Kparams{
+					kparams.FilePath:      {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: `C:\Windows\Temp\dropper.exe`},
+					kparams.FileOperation: {Name: kparams.FileOperation, Type: kparams.Enum, Value: uint32(fs.FileCreate)},
+				},
+			},
+			map[string]any{
+				"event.category":     []string{"file"},
+				"event.type":         []string{"creation"},
+				"event.dataset":      "fibratus.file",
+				"file.path":          `C:\Windows\Temp\dropper.exe`,
+				"file.name":          "dropper.exe",
+				"file.directory":     `C:\Windows\Temp`,
+				"file.extension":     "exe",
+				"process.name":       "powershell.exe",
+				"process.parent.pid": uint32(6304),
+				"user.name":          "SYSTEM",
+			},
+		},
+		{
+			"create process",
+			&kevent.Kevent{
+				Type:     ktypes.CreateProcess,
+				Name:     "CreateProcess",
+				Category: ktypes.Process,
+				Kparams: kevent.Kparams{
+					kparams.ProcessID:   {Name: kparams.ProcessID, Type: kparams.PID, Value: uint32(9876)},
+					kparams.ProcessName: {Name: kparams.ProcessName, Type: kparams.AnsiString, Value: "whoami.exe"},
+					kparams.Cmdline:     {Name: kparams.Cmdline, Type: kparams.UnicodeString, Value: "whoami /all"},
+				},
+			},
+			map[string]any{
+				"event.category":       []string{"process"},
+				"event.type":           []string{"start"},
+				"process.pid":          int64(9876),
+				"process.name":         "whoami.exe",
+				"process.command_line": "whoami /all",
+				"process.parent.name":  "powershell.exe",
+				"process.parent.pid":   uint32(2436),
+			},
+		},
+		{
+			"set registry value",
+			&kevent.Kevent{
+				Type:     ktypes.RegSetValue,
+				Name:     "RegSetValue",
+				Category: ktypes.Registry,
+				Kparams: kevent.Kparams{
+					kparams.RegPath:      {Name: kparams.RegPath, Type: kparams.UnicodeString, Value: `HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Run\updater`},
+					kparams.RegValue:     {Name: kparams.RegValue, Type: kparams.UnicodeString, Value: `C:\Windows\Temp\dropper.exe`},
+					kparams.RegValueType: {Name: kparams.RegValueType, Type: kparams.AnsiString, Value: "REG_SZ"},
+				},
+			},
+			map[string]any{
+				"event.type":            []string{"change"},
+				"registry.hive":         "HKLM",
+				"registry.key":          `SOFTWARE\Microsoft\Windows\CurrentVersion\Run`,
+				"registry.value":        "updater",
+				"registry.data.strings": []string{`C:\Windows\Temp\dropper.exe`},
+				"registry.data.type":    "REG_SZ",
+			},
+		},
+		{
+			"connect",
+			&kevent.Kevent{
+				Type:     ktypes.ConnectTCPv4,
+				Name:     "Connect",
+				Category: ktypes.Net,
+				Kparams: kevent.Kparams{
+					kparams.NetSIP:   {Name: kparams.NetSIP, Type: kparams.IPv4, Value: net.ParseIP("10.0.0.4")},
+					kparams.NetDIP:   {Name: kparams.NetDIP, Type: kparams.IPv4, Value: net.ParseIP("8.8.8.8")},
+					kparams.NetSport: {Name: kparams.NetSport, Type: kparams.Port, Value: uint16(51234)},
+					kparams.NetDport: {Name: kparams.NetDport, Type: kparams.Port, Value: uint16(443)},
+				},
+			},
+			map[string]any{
+				"event.category":    []string{"network"},
+				"event.type":        []string{"connection", "start"},
+				"source.ip":         "10.0.0.4",
+				"destination.ip":    "8.8.8.8",
+				"destination.port":  int64(443),
+				"network.transport": "tcp",
+				"network.type":      "ipv4",
+				"network.direction": "egress",
+			},
+		},
+		{
+			"dns reply",
+			&kevent.Kevent{
+				Type:     ktypes.ReplyDNS,
+				Name:     "ReplyDns",
+				Category: ktypes.Net,
+				Kparams: kevent.Kparams{
+					kparams.DNSName:    {Name: kparams.DNSName, Type: kparams.UnicodeString, Value: "example.org"},
+					kparams.DNSAnswers: {Name: kparams.DNSAnswers, Type: kparams.Slice, Value: []string{"93.184.216.34"}},
+				},
+			},
+			map[string]any{
+				"dns.type":          "answer",
+				"dns.question.name": "example.org",
+				"network.protocol":  "dns",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.e.PID = ps.PID
+			tt.e.Host = "archrabbit"
+			tt.e.Timestamp = time.Now()
+			tt.e.PS = ps
+			doc := Document(tt.e)
+			assert.Equal(t, Version, doc.Get("ecs.version"))
+			assert.Equal(t, "event", doc.Get("event.kind"))
+			assert.Equal(t, "archrabbit", doc.Get("host.name"))
+			for field, v := range tt.fields {
+				assert.Equal(t, v, doc.Get(field), field)
+			}
+			assert.NotNil(t, doc.Get("fibratus.params"))
+		})
+	}
+}
+
+func TestDocumentRuleMatch(t *testing.T) {
+	e := &kevent.Kevent{
+		Type:      ktypes.OpenProcess,
+		Name:      "OpenProcess",
+		Category:  ktypes.Process,
+		Timestamp: time.Now(),
+		Kparams:   kevent.Kparams{},
+		Metadata: kevent.Metadata{
+			kevent.RuleNameKey: "LSASS memory dumping",
+			"tactic.id":        "TA0006",
+			"technique.id":     "T1003",
+			"subtechnique.id":  "T1003.001",
+			"env.name":         "prod",
+		},
+	}
+	doc := Document(e)
+	assert.Equal(t, "alert", doc.Get("event.kind"))
+	assert.Equal(t, "LSASS memory dumping", doc.Get("rule.name"))
+	assert.Equal(t, "MITRE ATT&CK", doc.Get("threat.framework"))
+	assert.Equal(t, "TA0006", doc.Get("threat.tactic.id"))
+	assert.Equal(t, "T1003.001", doc.Get("threat.technique.subtechnique.id"))
+	assert.Equal(t, "prod", doc.Get("labels.env_name"))
+
+	b, err := Marshal(e)
+	require.NoError(t, err)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(b, &m))
+	assert.Contains(t, m, "@timestamp")
+}
@@ -0,0 +1,409 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ocsf maps events to the Open Cybersecurity Schema Framework (OCSF) event classes.
+package ocsf
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/rabbitstack/fibratus/pkg/fs"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs/schema"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+)
+
+// Version is the OCSF version the events conform to.
+const Version = "1.1.0"
+
+// class describes the OCSF event class
+type class struct {
+	uid          int
+	name         string
+	categoryUID  int
+	categoryName string
+}
+
+var (
+	baseEvent             = class{0, "Base Event", 0, "Uncategorized"}
+	fileSystemActivity    = class{1001, "File System Activity", 1, "System Activity"}
+	moduleActivity        = class{1005, "Module Activity", 1, "System Activity"}
+	processActivity       = class{1007, "Process Activity", 1, "System Activity"}
+	registryKeyActivity   = class{201001, "Registry Key Activity", 1, "System Activity"}
+	registryValueActivity = class{201002, "Registry Value Activity", 1, "System Activity"}
+	networkActivity       = class{4001, "Network Activity", 4, "Network Activity"}
+	dnsActivity           = class{4003, "DNS Activity", 4, "Network Activity"}
+)
+
+// activity identifies the event class and the activity within the class
+type activity struct {
+	class *class
+	id    int
+	name  string
+}
+
+// other is the activity of events that don't map to any specific class
+var other = activity{&baseEvent, 99, "Other"}
+
+// activities maps event types to OCSF classes and activities
+var activities = map[ktypes.Ktype]activity{
+	ktypes.CreateProcess:      {&processActivity, 1, "Launch"},
+	ktypes.TerminateProcess:   {&processActivity, 2, "Terminate"},
+	ktypes.OpenProcess:        {&processActivity, 3, "Open"},
+	ktypes.CreateFile:         {&fileSystemActivity, 14, "Open"},
+	ktypes.ReadFile:           {&fileSystemActivity, 2, "Read"},
+	ktypes.WriteFile:          {&fileSystemActivity, 3, "Update"},
+	ktypes.DeleteFile:         {&fileSystemActivity, 4, "Delete"},
+	ktypes.RenameFile:         {&fileSystemActivity, 5, "Rename"},
+	ktypes.SetFileInformation: {&fileSystemActivity, 6, "Set Attributes"},
+	ktypes.EnumDirectory:      {&fileSystemActivity, 2, "Read"},
+	ktypes.LoadImage:          {&moduleActivity, 1, "Load"},
+	ktypes.UnloadImage:        {&moduleActivity, 2, "Unload"},
+	ktypes.RegCreateKey:       {&registryKeyActivity, 1, "Create"},
+	ktypes.RegOpenKey:         {&registryKeyActivity, 2, "Read"},
+	ktypes.RegQueryKey:        {&registryKeyActivity, 2, "Read"},
+	ktypes.RegDeleteKey:       {&registryKeyActivity, 4, "Delete"},
+	ktypes.RegQueryValue:      {&registryValueActivity, 1, "Get"},
+	ktypes.RegSetValue:        {&registryValueActivity, 2, "Set"},
+	ktypes.RegDeleteValue:     {&registryValueActivity, 4, "Delete"},
+	ktypes.ConnectTCPv4:       {&networkActivity, 1, "Open"},
+	ktypes.ConnectTCPv6:       {&networkActivity, 1, "Open"},
+	ktypes.AcceptTCPv4:        {&networkActivity, 1, "Open"},
+	ktypes.AcceptTCPv6:        {&networkActivity, 1, "Open"},
+	ktypes.DisconnectTCPv4:    {&networkActivity, 2, "Close"},
+	ktypes.DisconnectTCPv6:    {&networkActivity, 2, "Close"},
+	ktypes.SendTCPv4:          {&networkActivity, 6, "Traffic"},
+	ktypes.SendTCPv6:          {&networkActivity, 6, "Traffic"},
+	ktypes.SendUDPv4:          {&networkActivity, 6, "Traffic"},
+	ktypes.SendUDPv6:          {&networkActivity, 6, "Traffic"},
+	ktypes.RecvTCPv4:          {&networkActivity, 6, "Traffic"},
+	ktypes.RecvTCPv6:          {&networkActivity, 6, "Traffic"},
+	ktypes.RecvUDPv4:          {&networkActivity, 6, "Traffic"},
+	ktypes.RecvUDPv6:          {&networkActivity, 6, "Traffic"},
+	ktypes.RetransmitTCPv4:    {&networkActivity, 6, "Traffic"},
+	ktypes.RetransmitTCPv6:    {&networkActivity, 6, "Traffic"},
+	ktypes.ReconnectTCPv4:     {&networkActivity, 99, "Other"},
+	ktypes.ReconnectTCPv6:     {&networkActivity, 99, "Other"},
+	ktypes.QueryDNS:           {&dnsActivity, 1, "Query"},
+	ktypes.ReplyDNS:           {&dnsActivity, 2, "Response"},
+}
+
+// outbound contains network events initiated by the local host
+var outbound = map[ktypes.Ktype]bool{
+	ktypes.ConnectTCPv4: true, ktypes.ConnectTCPv6: true,
+	ktypes.SendTCPv4: true, ktypes.SendTCPv6: true,
+	ktypes.SendUDPv4: true, ktypes.SendUDPv6: true,
+	ktypes.ReconnectTCPv4: true, ktypes.ReconnectTCPv6: true,
+	ktypes.RetransmitTCPv4: true, ktypes.RetransmitTCPv6: true,
+}
+
+// attacks maps the MITRE ATT&CK rule labels to the attack object fields
+var attacks = map[string]string{
+	"tactic.id":         "tactic.uid",
+	"tactic.name":       "tactic.name",
+	"technique.id":      "technique.uid",
+	"technique.name":    "technique.name",
+	"subtechnique.id":   "sub_technique.uid",
+	"subtechnique.name": "sub_technique.name",
+}
+
+// Marshal encodes the event as the OCSF JSON event.
+func Marshal(e *kevent.Kevent) ([]byte, error) {
+	return json.Marshal(Event(e))
+}
+
+// Event maps the event to the OCSF event class. Event parameters that
+// have no OCSF counterpart are retained in the unmapped object.
+func Event(e *kevent.Kevent) schema.Doc {
+	doc := make(schema.Doc)
+
+	act, ok := activities[e.Type]
+	if !ok {
+		act = other
+	}
+	if e.IsCreateFile() && isCreateDisposition(e) {
+		act = activity{&fileSystemActivity, 1, "Create"}
+	}
+	doc.Set("class_uid", act.class.uid)
+	doc.Set("class_name", act.class.name)
+	doc.Set("category_uid", act.class.categoryUID)
+	doc.Set("category_name", act.class.categoryName)
+	doc.Set("activity_id", act.id)
+	doc.Set("activity_name", act.name)
+	doc.Set("type_uid", act.class.uid*100+act.id)
+	doc.Set("type_name", act.class.name+": "+act.name)
+
+	doc.Set("time", e.Timestamp.UnixMilli())
+	doc.Set("message", e.Description)
+	doc.Set("severity_id", 1)
+	doc.Set("severity", "Informational")
+	if e.Kparams.Contains(kparams.NTStatus) {
+		if e.IsSuccess() {
+			doc.Set("status_id", 1)
+			doc.Set("status", "Success")
+		} else {
+			doc.Set("status_id", 2)
+			doc.Set("status", "Failure")
+			doc.Set("status_detail", schema.String(e, kparams.NTStatus))
+		}
+	}
+
+	doc.Set("metadata.version", Version)
+	doc.Set("metadata.product.name", "Fibratus")
+	doc.Set("metadata.product.vendor_name", "rabbitstack")
+	doc.Set("metadata.product.version", version.Get())
+	doc.Set("metadata.sequence", e.Seq)
+	doc.Set("metadata.profiles", []string{"host"})
+	doc.Set("metadata.event_code", e.Name)
+
+	doc.Set("device.hostname", e.Host)
+	doc.Set("device.os.name", "Windows")
+	doc.Set("device.os.type", "Windows")
+	doc.Set("device.os.type_id", 100)
+
+	if ps := e.PS; ps != nil {
+		actor := process(ps)
+		actor.Set("tid", e.Tid)
+		doc.Set("actor.process", actor)
+		doc.Set("actor.user", user(ps.Username, ps.SID, ps.Domain))
+	} else {
+		doc.Set("actor.process", schema.Doc{"pid": e.PID, "tid": e.Tid})
+	}
+
+	switch act.class {
+	case &processActivity:
+		setProcess(doc, e)
+	case &fileSystemActivity:
+		doc.Set("file", file(schema.String(e, kparams.FilePath)))
+	case &moduleActivity:
+		setModule(doc, e)
+	case &registryKeyActivity:
+		doc.Set("reg_key.path", schema.String(e, kparams.RegPath))
+	case &registryValueActivity:
+		setRegistryValue(doc, e)
+	case &networkActivity:
+		setNetwork(doc, e)
+	case &dnsActivity:
+		setDNS(doc, e)
+	}
+
+	setRule(doc, e)
+
+	params := make(schema.Doc)
+	for name, kpar := range e.Kparams {
+		params[name] = schema.Value(kpar)
+	}
+	if len(params) > 0 {
+		doc.Set("unmapped.params", params)
+	}
+	doc.Set("unmapped.category", string(e.Category))
+	doc.Set("unmapped.cpu", e.CPU)
+
+	return doc
+}
+
+func process(ps *pstypes.PS) schema.Doc {
+	doc := make(schema.Doc)
+	doc.Set("pid", ps.PID)
+	doc.Set("name", ps.Name)
+	doc.Set("cmd_line", ps.Cmdline)
+	doc.Set("uid", strconv.FormatUint(ps.UUID(), 10))
+	if !ps.StartTime.IsZero() {
+		doc.Set("created_time", ps.StartTime.UnixMilli())
+	}
+	if ps.Exe != "" {
+		doc.Set("file", file(ps.Exe))
+	}
+	if u := user(ps.Username, ps.SID, ps.Domain); len(u) > 0 {
+		doc.Set("user", u)
+	}
+	if ps.Parent != nil {
+		parent := schema.Doc{"pid": ps.Parent.PID}
+		parent.Set("name", ps.Parent.Name)
+		parent.Set("cmd_line", ps.Parent.Cmdline)
+		parent.Set("uid", strconv.FormatUint(ps.Parent.UUID(), 10))
+		doc.Set("parent_process", parent)
+	} else {
+		doc.Set("parent_process.pid", ps.Ppid)
+	}
+	return doc
+}
+
+func user(name, sid, domain string) schema.Doc {
+	doc := make(schema.Doc)
+	doc.Set("name", name)
+	doc.Set("uid", sid)
+	doc.Set("domain", domain)
+	return doc
+}
+
+func file(path string) schema.Doc {
+	if path == "" {
+		return nil
+	}
+	doc := schema.Doc{"path": path, "type_id": 1, "type": "Regular File"}
+	doc.Set("name", schema.Base(path))
+	doc.Set("parent_folder", schema.Dir(path))
+	doc.Set("ext", schema.Ext(path))
+	return doc
+}
+
+// setProcess populates the target process. The spawned process
+// is resolved from event parameters, while the actor process is
+// the parent of the spawned process.
+func setProcess(doc schema.Doc, e *kevent.Kevent) {
+	target := make(schema.Doc)
+	pidParam := kparams.ProcessID
+	if e.Type == ktypes.OpenProcess {
+		pidParam = kparams.TargetProcessID
+	}
+	if pid, ok := schema.Int(e, pidParam); ok {
+		target.Set("pid", pid)
+	}
+	target.Set("name", schema.String(e, kparams.ProcessName))
+	target.Set("cmd_line", schema.String(e, kparams.Cmdline))
+	if exe := schema.String(e, kparams.Exe); exe != "" {
+		target.Set("file", file(exe))
+	}
+	if u := user(schema.String(e, kparams.Username), schema.String(e, kparams.UserSID), schema.String(e, kparams.Domain)); len(u) > 0 {
+		target.Set("user", u)
+	}
+	if start, err := e.Kparams.GetTime(kparams.StartTime); err == nil && !start.IsZero() {
+		target.Set("created_time", start.UnixMilli())
+	}
+	if e.IsCreateProcess() && e.PS != nil {
+		target.Set("parent_process", process(e.PS))
+	}
+	if len(target) > 0 {
+		doc.Set("process", target)
+	}
+}
+
+func setModule(doc schema.Doc, e *kevent.Kevent) {
+	doc.Set("module.file", file(schema.String(e, kparams.ImagePath)))
+	doc.Set("module.file.signature.certificate.subject", schema.String(e, kparams.ImageCertSubject))
+	doc.Set("module.file.signature.certificate.issuer", schema.String(e, kparams.ImageCertIssuer))
+	doc.Set("module.base_address", schema.String(e, kparams.ImageBase))
+}
+
+func setRegistryValue(doc schema.Doc, e *kevent.Kevent) {
+	path := schema.String(e, kparams.RegPath)
+	if path == "" {
+		return
+	}
+	doc.Set("reg_value.path", schema.Dir(path))
+	doc.Set("reg_value.name", schema.Base(path))
+	doc.Set("reg_value.type", schema.String(e, kparams.RegValueType))
+	doc.Set("reg_value.data", schema.String(e, kparams.RegValue))
+}
+
+func setNetwork(doc schema.Doc, e *kevent.Kevent) {
+	if ip := schema.IP(e, kparams.NetSIP); ip != nil {
+		doc.Set("src_endpoint.ip", ip.String())
+	}
+	if port, ok := schema.Int(e, kparams.NetSport); ok {
+		doc.Set("src_endpoint.port", port)
+	}
+	if ip := schema.IP(e, kparams.NetDIP); ip != nil {
+		doc.Set("dst_endpoint.ip", ip.String())
+	}
+	if port, ok := schema.Int(e, kparams.NetDport); ok {
+		doc.Set("dst_endpoint.port", port)
+	}
+	if names := schema.Strings(e, kparams.NetDIPNames); len(names) > 0 {
+		doc.Set("dst_endpoint.hostname", names[0])
+	}
+	if size, ok := schema.Int(e, kparams.NetSize); ok {
+		doc.Set("traffic.bytes", size)
+	}
+	if e.IsNetworkUDP() {
+		doc.Set("connection_info.protocol_name", "udp")
+		doc.Set("connection_info.protocol_num", 17)
+	} else {
+		doc.Set("connection_info.protocol_name", "tcp")
+		doc.Set("connection_info.protocol_num", 6)
+	}
+	if schema.IsIPv6(e) {
+		doc.Set("connection_info.protocol_ver_id", 6)
+	} else {
+		doc.Set("connection_info.protocol_ver_id", 4)
+	}
+	if outbound[e.Type] {
+		doc.Set("connection_info.direction_id", 2)
+		doc.Set("connection_info.direction", "Outbound")
+	} else {
+		doc.Set("connection_info.direction_id", 1)
+		doc.Set("connection_info.direction", "Inbound")
+	}
+}
+
+func setDNS(doc schema.Doc, e *kevent.Kevent) {
+	doc.Set("query.hostname", schema.String(e, kparams.DNSName))
+	doc.Set("query.type", schema.String(e, kparams.DNSRR))
+	if e.Type == ktypes.QueryDNS {
+		return
+	}
+	doc.Set("rcode", schema.String(e, kparams.DNSRcode))
+	var answers []schema.Doc
+	for _, answer := range schema.Strings(e, kparams.DNSAnswers) {
+		if answer != "" {
+			answers = append(answers, schema.Doc{"rdata": answer})
+		}
+	}
+	if len(answers) > 0 {
+		doc.Set("answers", answers)
+	}
+}
+
+// setRule populates the attack object for events that triggered a rule
+// with the MITRE ATT&CK labels. The remaining metadata is stored in labels.
+func setRule(doc schema.Doc, e *kevent.Kevent) {
+	attack := make(schema.Doc)
+	var labels []string
+	for k := range e.Metadata {
+		switch k {
+		case kevent.RuleNameKey, kevent.RuleSequenceLink, kevent.RuleSequenceOOOKey:
+			continue
+		}
+		v := schema.Meta(e, k)
+		if field, ok := attacks[string(k)]; ok {
+			attack.Set(field, v)
+			continue
+		}
+		labels = append(labels, string(k)+"="+v)
+	}
+	if len(attack) > 0 {
+		doc.Set("attacks", []schema.Doc{attack})
+		doc.Set("metadata.profiles", []string{"host", "security_control"})
+	}
+	if len(labels) > 0 {
+		sort.Strings(labels)
+		doc.Set("metadata.labels", labels)
+	}
+	doc.Set("unmapped.rule.name", schema.Meta(e, kevent.RuleNameKey))
+}
+
+func isCreateDisposition(e *kevent.Kevent) bool {
+	op, err := e.Kparams.GetUint32(kparams.FileOperation)
+	return err == nil && op == fs.FileCreate
+}
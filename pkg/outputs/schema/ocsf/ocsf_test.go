@@ -0,0 +1,210 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ocsf
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+func TestEvent(t *testing.T) {
+	ps := &pstypes.PS{
+		PID:      2436,
+		Ppid:     6304,
+		Name:     "powershell.exe",
+		Exe:      `C:\Windows\System32\WindowsPowerShell\v1.0\powershell.exe`,
+		SID:      "S-1-5-18",
+		Username: "SYSTEM",
+		Domain:   "NT AUTHORITY",
+	}
+
+	var tests = []struct {
+		name     string
+		e        *kevent.Kevent
+		classUID int
+		typeUID  int
+		fields   map[string]any
+	}{
+		{
+			"delete file",
+			&kevent.Kevent{
+				Type:     ktypes.DeleteFile,
+				Category: ktypes.File,
+				Kparams: kevent.Kparams{
+					kparams.FilePath: {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: `C:\Windows\Temp\dropper.exe`},
+				},
+			},
+			1001,
+			100104,
+			map[string]any{
+				"file.path":          `C:\Windows\Temp\dropper.exe`,
+				"file.name":          "dropper.exe",
+				"file.parent_folder": `C:\Windows\Temp`,
+				"actor.process.name": "powershell.exe",
+				"actor.user.uid":     "S-1-5-18",
+			},
+		},
+		{
+			"create process",
+			&kevent.Kevent{
+				Type:     ktypes.CreateProcess,
+				Category: ktypes.Process,
+				Kparams: kevent.Kparams{
+					kparams.ProcessID:   {Name: kparams.ProcessID, Type: kparams.PID, Value: uint32(9876)},
+					kparams.ProcessName: {Name: kparams.ProcessName, Type: kparams.AnsiString, Value: "whoami.exe"},
+				},
+			},
+			1007,
+			100701,
+			map[string]any{
+				"process.pid":                 int64(9876),
+				"process.name":                "whoami.exe",
+				"process.parent_process.name": "powershell.exe",
+			},
+		},
+		{
+			"set registry value",
+			&kevent.Kevent{
+				Type:     ktypes.RegSetValue,
+				Category: ktypes.Registry,
+				Kparams: kevent.Kparams{
+					kparams.RegPath: {Name: kparams.RegPath, Type: kparams.UnicodeString, Value: `HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Run\updater`},
+				},
+			},
+			201002,
+			20100202,
+			map[string]any{
+				"reg_value.path": `HKEY_LOCAL_MACHINE\SOFTWARE\Microsoft\Windows\CurrentVersion\Run`,
+				"reg_value.name": "updater",
+			},
+		},
+		{
+			"recv",
+			&kevent.Kevent{
+				Type:     ktypes.RecvUDPv6,
+				Category: ktypes.Net,
+				Kparams: kevent.Kparams{
+					kparams.NetDIP:   {Name: kparams.NetDIP, Type: kparams.IPv6, Value: net.ParseIP("::1")},
+					kparams.NetDport: {Name: kparams.NetDport, Type: kparams.Port, Value: uint16(53)},
+				},
+			},
+			4001,
+			400106,
+			map[string]any{
+				"dst_endpoint.ip":                 "::1",
+				"dst_endpoint.port":               int64(53),
+				"connection_info.protocol_name":   "udp",
+				"connection_info.protocol_ver_id": 6,
+				"connection_info.direction_id":    1,
+			},
+		},
+		{
+			"thread pool",
+			&kevent.Kevent{
+				Type:     ktypes.SubmitThreadpoolWork,
+				Category: ktypes.Threadpool,
+				Kparams:  kevent.Kparams{},
+			},
+			0,
+			99,
+			map[string]any{
+				"unmapped.category": "threadpool",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.e.Host = "archrabbit"
+			tt.e.Timestamp = time.Now()
+			tt.e.PS = ps
+			ev := Event(tt.e)
+			assert.Equal(t, tt.classUID, ev.Get("class_uid"))
+			assert.Equal(t, tt.typeUID, ev.Get("type_uid"))
+			assert.Equal(t, Version, ev.Get("metadata.version"))
+			assert.Equal(t, "archrabbit", ev.Get("device.hostname"))
+			assert.Equal(t, tt.e.Timestamp.UnixMilli(), ev.Get("time"))
+			for field, v := range tt.fields {
+				assert.Equal(t, v, ev.Get(field), field)
+			}
+		})
+	}
+}
+
+func TestEventAttacks(t *testing.T) {
+	e := &kevent.Kevent{
+		Type:      ktypes.OpenProcess,
+		Category:  ktypes.Process,
+		Timestamp: time.Now(),
+		Kparams: kevent.Kparams{
+			kparams.TargetProcessID: {Name: kparams.TargetProcessID, Type: kparams.PID, Value: uint32(680)},
+		},
+		Metadata: kevent.Metadata{
+			kevent.RuleNameKey: "LSASS memory dumping",
+			"tactic.id":        "TA0006",
+			"technique.name":   "OS Credential Dumping",
+			"env":              "prod",
+		},
+	}
+	b, err := Marshal(e)
+	require.NoError(t, err)
+
+	var ev struct {
+		TypeUID int `json:"type_uid"`
+		Process struct {
+			PID int `json:"pid"`
+		} `json:"process"`
+		Attacks []struct {
+			Tactic struct {
+				UID string `json:"uid"`
+			} `json:"tactic"`
+			Technique struct {
+				Name string `json:"name"`
+			} `json:"technique"`
+		} `json:"attacks"`
+		Metadata struct {
+			Profiles []string `json:"profiles"`
+			Labels   []string `json:"labels"`
+		} `json:"metadata"`
+		Unmapped struct {
+			Rule struct {
+				Name string `json:"name"`
+			} `json:"rule"`
+		} `json:"unmapped"`
+	}
+	require.NoError(t, json.Unmarshal(b, &ev))
+	assert.Equal(t, 100703, ev.TypeUID)
+	assert.Equal(t, 680, ev.Process.PID)
+	require.Len(t, ev.Attacks, 1)
+	assert.Equal(t, "TA0006", ev.Attacks[0].Tactic.UID)
+	assert.Equal(t, "OS Credential Dumping", ev.Attacks[0].Technique.Name)
+	assert.Equal(t, []string{"host", "security_control"}, ev.Metadata.Profiles)
+	assert.Equal(t, []string{"env=prod"}, ev.Metadata.Labels)
+	assert.Equal(t, "LSASS memory dumping", ev.Unmapped.Rule.Name)
+}
@@ -0,0 +1,232 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package schema contains the building blocks shared by serializers that
+// normalize events into third-party schemas, such as ECS or OCSF.
+package schema
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+)
+
+// Doc represents the normalized document. Nested objects are
+// created on demand when the fields are set by their dotted paths.
+type Doc map[string]any
+
+// Set assigns the value to the field identified by the dotted path,
+// e.g. process.parent.pid. Empty strings, slices, objects and nil values are ignored.
+func (d Doc) Set(path string, v any) {
+	switch s := v.(type) {
+	case nil:
+		return
+	case string:
+		if s == "" {
+			return
+		}
+	case []string:
+		if len(s) == 0 {
+			return
+		}
+	case Doc:
+		if len(s) == 0 {
+			return
+		}
+	}
+	m := d
+	for {
+		n := strings.IndexByte(path, '.')
+		if n < 0 {
+			break
+		}
+		child, ok := m[path[:n]].(Doc)
+		if !ok {
+			child = make(Doc)
+			m[path[:n]] = child
+		}
+		m, path = child, path[n+1:]
+	}
+	m[path] = v
+}
+
+// Get returns the value of the field identified by the dotted path.
+func (d Doc) Get(path string) any {
+	m := d
+	for {
+		n := strings.IndexByte(path, '.')
+		if n < 0 {
+			return m[path]
+		}
+		child, ok := m[path[:n]].(Doc)
+		if !ok {
+			return nil
+		}
+		m, path = child, path[n+1:]
+	}
+}
+
+// String returns the string representation of the event parameter or
+// an empty string if the parameter is not present.
+func String(e *kevent.Kevent, name string) string {
+	kpar, err := e.Kparams.Get(name)
+	if err != nil {
+		return ""
+	}
+	return kpar.String()
+}
+
+// Int returns the integer value of the numeric event parameter.
+func Int(e *kevent.Kevent, name string) (int64, bool) {
+	kpar, err := e.Kparams.Get(name)
+	if err != nil {
+		return 0, false
+	}
+	return toInt(kpar.Value)
+}
+
+// IP returns the IP address parameter or nil if the parameter is not present.
+func IP(e *kevent.Kevent, name string) net.IP {
+	ip, err := e.Kparams.GetIP(name)
+	if err != nil {
+		return nil
+	}
+	return ip
+}
+
+// IsIPv6 determines if the network event carries IPv6 addresses.
+func IsIPv6(e *kevent.Kevent) bool {
+	for _, name := range []string{kparams.NetDIP, kparams.NetSIP} {
+		if kpar, err := e.Kparams.Get(name); err == nil {
+			return kpar.Type == kparams.IPv6
+		}
+	}
+	return false
+}
+
+// Strings returns the string slice parameter.
+func Strings(e *kevent.Kevent, name string) []string {
+	s, err := e.Kparams.GetStringSlice(name)
+	if err != nil {
+		return nil
+	}
+	return s
+}
+
+// Meta returns the string representation of the event metadata value.
+func Meta(e *kevent.Kevent, key kevent.MetadataKey) string {
+	v, ok := e.Metadata[key]
+	if !ok {
+		return ""
+	}
+	switch s := v.(type) {
+	case string:
+		return s
+	case fmt.Stringer:
+		return s.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// Value maps the event parameter to the value suitable for JSON encoding.
+// Numeric and boolean parameters retain their types, timestamps are formatted
+// in RFC 3339 format, and the rest of the parameters are rendered to strings.
+func Value(kpar *kevent.Kparam) any {
+	switch kpar.Type {
+	case kparams.Int8, kparams.Int16, kparams.Int32, kparams.Int64,
+		kparams.Uint8, kparams.Uint16, kparams.Uint32, kparams.Uint64,
+		kparams.PID, kparams.TID, kparams.Port:
+		if n, ok := toInt(kpar.Value); ok {
+			return n
+		}
+	case kparams.Float, kparams.Double:
+		switch n := kpar.Value.(type) {
+		case float32:
+			return float64(n)
+		case float64:
+			return n
+		}
+	case kparams.Bool:
+		if b, ok := kpar.Value.(bool); ok {
+			return b
+		}
+	case kparams.Time:
+		if t, ok := kpar.Value.(time.Time); ok {
+			return t.Format(time.RFC3339Nano)
+		}
+	}
+	return kpar.String()
+}
+
+// Base returns the last element of the Windows path.
+func Base(path string) string {
+	if n := strings.LastIndexAny(path, `\/`); n >= 0 {
+		return path[n+1:]
+	}
+	return path
+}
+
+// Dir returns all but the last element of the Windows path.
+func Dir(path string) string {
+	if n := strings.LastIndexAny(path, `\/`); n > 0 {
+		return path[:n]
+	}
+	return ""
+}
+
+// Ext returns the file name extension without the leading dot.
+func Ext(path string) string {
+	name := Base(path)
+	if n := strings.LastIndexByte(name, '.'); n >= 0 && n < len(name)-1 {
+		return name[n+1:]
+	}
+	return ""
+}
+
+func toInt(v any) (int64, bool) {
+	switch n := v.(type) {
+	case int8:
+		return int64(n), true
+	case int16:
+		return int64(n), true
+	case int32:
+		return int64(n), true
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case uint8:
+		return int64(n), true
+	case uint16:
+		return int64(n), true
+	case uint32:
+		return int64(n), true
+	case uint64:
+		if n > math.MaxInt64 {
+			return 0, false
+		}
+		return int64(n), true
+	}
+	return 0, false
+}
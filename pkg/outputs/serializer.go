@@ -18,10 +18,64 @@
 
 package outputs
 
+import (
+	"fmt"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs/schema/ecs"
+	"github.com/rabbitstack/fibratus/pkg/outputs/schema/ocsf"
+)
+
 // Serializer is the type definition for the output serializers.
 type Serializer string
 
 const (
 	// JSON represents the JSON serializer type.
 	JSON Serializer = "json"
+	// CEF represents the ArcSight Common Event Format serializer type.
+	CEF Serializer = "cef"
+	// LEEF represents the QRadar Log Event Extended Format serializer type.
+	LEEF Serializer = "leef"
+	// ECS represents the Elastic Common Schema serializer type.
+	ECS Serializer = "ecs"
+	// OCSF represents the Open Cybersecurity Schema Framework serializer type.
+	OCSF Serializer = "ocsf"
 )
+
+// Marshal encodes the event with the JSON based serializer. The JSON serializer
+// emits the native event layout, while ECS and OCSF serializers normalize the
+// event to their respective schemas.
+func (s Serializer) Marshal(e *kevent.Kevent) ([]byte, error) {
+	switch s {
+	case JSON, "":
+		return e.MarshalJSON(), nil
+	case ECS:
+		return ecs.Marshal(e)
+	case OCSF:
+		return ocsf.Marshal(e)
+	default:
+		return nil, fmt.Errorf("%s serializer is not supported", s)
+	}
+}
+
+// MarshalBatch encodes all events in the batch as a JSON array.
+func (s Serializer) MarshalBatch(batch *kevent.Batch) ([]byte, error) {
+	if s == JSON || s == "" {
+		return batch.MarshalJSON(), nil
+	}
+	buf := make([]byte, 0)
+	buf = append(buf, '[')
+	for i, e := range batch.Events {
+		b, err := s.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, b...)
+		buf = append(buf, '\n')
+	}
+	buf = append(buf, ']')
+	return buf, nil
+}
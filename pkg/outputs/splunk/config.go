@@ -0,0 +1,96 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package splunk
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+const (
+	splunkEnabled         = "output.splunk.enabled"
+	splunkEndpoints       = "output.splunk.endpoints"
+	splunkToken           = "output.splunk.token"
+	splunkIndex           = "output.splunk.index"
+	splunkSource          = "output.splunk.source"
+	splunkSourcetype      = "output.splunk.sourcetype"
+	splunkHost            = "output.splunk.host"
+	splunkSerializer      = "output.splunk.serializer"
+	splunkTimeout         = "output.splunk.timeout"
+	splunkEnableGzip      = "output.splunk.enable-gzip"
+	splunkEnableAck       = "output.splunk.enable-ack"
+	splunkChannel         = "output.splunk.channel"
+	splunkAckTimeout      = "output.splunk.ack-timeout"
+	splunkAckPollInterval = "output.splunk.ack-poll-interval"
+)
+
+// Config contains the options for tweaking the Splunk HEC output behaviour.
+type Config struct {
+	outputs.TLSConfig
+	// Enabled determines whether Splunk output is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// Endpoints contains a collection of HEC URLs. Batches are distributed across endpoints in round-robin fashion.
+	Endpoints []string `mapstructure:"endpoints"`
+	// Token is the HEC authentication token.
+	Token string `mapstructure:"token"`
+	// Index is the name of the destination index. It may contain event field templates.
+	Index string `mapstructure:"index"`
+	// Source is the value of the event source. It may contain event field templates.
+	Source string `mapstructure:"source"`
+	// Sourcetype is the value of the event sourcetype. It may contain event field templates.
+	Sourcetype string `mapstructure:"sourcetype"`
+	// Host overrides the event host name. It may contain event field templates.
+	Host string `mapstructure:"host"`
+	// Serializer indicates the serializer for the event payload.
+	Serializer outputs.Serializer `mapstructure:"serializer"`
+	// Timeout represents the timeout for the HEC requests.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// EnableGzip specifies whether the gzip compression is enabled.
+	EnableGzip bool `mapstructure:"enable-gzip"`
+	// EnableAck specifies whether the indexer acknowledgement is awaited for every batch.
+	EnableAck bool `mapstructure:"enable-ack"`
+	// Channel is the HEC channel identifier. It is generated if not provided.
+	Channel string `mapstructure:"channel"`
+	// AckTimeout is the maximum time to wait for the indexer acknowledgement.
+	AckTimeout time.Duration `mapstructure:"ack-timeout"`
+	// AckPollInterval determines how often the acknowledgement status is polled.
+	AckPollInterval time.Duration `mapstructure:"ack-poll-interval"`
+}
+
+// AddFlags registers persistent flags for the Splunk output.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(splunkEnabled, false, "Determines whether the Splunk output is enabled")
+	flags.StringSlice(splunkEndpoints, []string{}, "A comma-separated list of HEC endpoints. Batches are distributed across endpoints in round-robin fashion")
+	flags.String(splunkToken, "", "The HEC authentication token")
+	flags.String(splunkIndex, "", "The name of the destination index. If empty, the default index of the token is used")
+	flags.String(splunkSource, "fibratus", "The value of the event source")
+	flags.String(splunkSourcetype, "fibratus:event", "The value of the event sourcetype")
+	flags.String(splunkHost, "", "Overrides the event host name")
+	flags.String(splunkSerializer, string(outputs.JSON), "Indicates the event serializer type")
+	flags.Duration(splunkTimeout, time.Second*10, "Represents the timeout for the HEC requests")
+	flags.Bool(splunkEnableGzip, false, "Indicates whether the gzip compression is enabled")
+	flags.Bool(splunkEnableAck, false, "Indicates whether the indexer acknowledgement is awaited for every batch")
+	flags.String(splunkChannel, "", "The HEC channel identifier. It is generated if not provided")
+	flags.Duration(splunkAckTimeout, time.Minute, "The maximum time to wait for the indexer acknowledgement")
+	flags.Duration(splunkAckPollInterval, time.Second, "Determines how often the acknowledgement status is polled")
+	outputs.AddTLSFlags(flags, outputs.Splunk)
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package splunk
+
+import (
+	"strings"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+)
+
+// field is the envelope field value. It is either a static
+// string or a template rendered from event fields.
+type field struct {
+	value     string
+	formatter *kevent.Formatter
+}
+
+// newField creates the envelope field. Values containing
+// the {{ }} tags are parsed as event templates.
+func newField(s string) (field, error) {
+	if !strings.Contains(s, "{{") {
+		return field{value: s}, nil
+	}
+	formatter, err := kevent.NewFormatter(s)
+	if err != nil {
+		return field{}, err
+	}
+	return field{formatter: formatter}, nil
+}
+
+// render returns the field value for the given event.
+func (f field) render(e *kevent.Kevent) string {
+	if f.formatter == nil {
+		return f.value
+	}
+	return string(f.formatter.Format(e))
+}
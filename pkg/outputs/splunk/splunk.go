@@ -0,0 +1,598 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package splunk
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/util/tls"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+)
+
+const (
+	// collectorPath is the path prefix of the HEC REST API
+	collectorPath = "/services/collector"
+	// eventPath is the URL path of the HEC event endpoint
+	eventPath = collectorPath + "/event"
+	// ackPath is the URL path of the HEC indexer acknowledgement endpoint
+	ackPath = collectorPath + "/ack"
+	// maxPendingAcks is the maximum number of batches awaiting the indexer
+	// acknowledgement. Publishing is blocked while the limit is reached
+	maxPendingAcks = 128
+)
+
+var (
+	// splunkEvents counts the number of events accepted by HEC
+	splunkEvents = expvar.NewInt("output.splunk.events")
+	// splunkAckTimeouts counts the number of batches that weren't acknowledged in due time
+	splunkAckTimeouts = expvar.NewInt("output.splunk.ack.timeouts")
+	// splunkAckDrops counts the number of batches that weren't acknowledged by any endpoint
+	splunkAckDrops = expvar.NewInt("output.splunk.ack.dropped.batches")
+	// splunkEndpointErrors counts the failed requests per HEC endpoint
+	splunkEndpointErrors = expvar.NewMap("output.splunk.endpoint.errors")
+)
+
+// errClosed is returned when the output is closed while awaiting the indexer acknowledgement
+var errClosed = errors.New("splunk output closed")
+
+// userAgentHeader represents the value of the User-Agent header
+var userAgentHeader = version.ProductToken()
+
+// endpoint contains the URLs of a single HEC instance.
+type endpoint struct {
+	url    string
+	ackURL string
+}
+
+// pendingAck is the batch awaiting the indexer acknowledgement.
+type pendingAck struct {
+	id uint64
+	// ep is the index of the endpoint the batch was sent to
+	ep     int
+	body   []byte
+	events int64
+	// attempts is the number of endpoints the batch was sent to
+	attempts int
+	deadline time.Time
+}
+
+// ackKey identifies the pending acknowledgement. Acknowledgement
+// identifiers are only unique within the endpoint channel.
+type ackKey struct {
+	ep int
+	id uint64
+}
+
+type splunk struct {
+	client    *http.Client
+	config    Config
+	endpoints []endpoint
+	// next is the round-robin cursor of the endpoint receiving the next batch
+	next atomic.Uint32
+
+	index      field
+	source     field
+	sourcetype field
+	host       field
+
+	// acks contains the batches awaiting the indexer acknowledgement
+	mu   sync.Mutex
+	acks map[ackKey]*pendingAck
+	// slots bounds the number of pending acknowledgements
+	slots chan struct{}
+
+	quit      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// response is the HEC response body.
+type response struct {
+	Text  string  `json:"text"`
+	Code  int     `json:"code"`
+	AckID *uint64 `json:"ackId"`
+}
+
+func init() {
+	outputs.Register(outputs.Splunk, initSplunk)
+}
+
+func initSplunk(config outputs.Config) (outputs.OutputGroup, error) {
+	cfg, ok := config.Output.(Config)
+	if !ok {
+		return outputs.Fail(outputs.ErrInvalidConfig(outputs.Splunk, config.Output))
+	}
+	if len(cfg.Endpoints) == 0 {
+		return outputs.Fail(errors.New("at least one Splunk HEC endpoint is required"))
+	}
+	if cfg.Token == "" {
+		return outputs.Fail(errors.New("missing Splunk HEC token"))
+	}
+	switch cfg.Serializer {
+	case outputs.JSON, outputs.ECS, outputs.OCSF, "":
+	default:
+		return outputs.Fail(fmt.Errorf("%s serializer is not supported by Splunk output", cfg.Serializer))
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = time.Second * 10
+	}
+	if cfg.AckTimeout == 0 {
+		cfg.AckTimeout = time.Minute
+	}
+	if cfg.AckPollInterval == 0 {
+		cfg.AckPollInterval = time.Second
+	}
+	if cfg.EnableAck && cfg.Channel == "" {
+		cfg.Channel = uuid.NewString()
+	}
+
+	s := &splunk{
+		config:    cfg,
+		endpoints: make([]endpoint, len(cfg.Endpoints)),
+		acks:      make(map[ackKey]*pendingAck),
+		slots:     make(chan struct{}, maxPendingAcks),
+		quit:      make(chan struct{}),
+	}
+	for i, e := range cfg.Endpoints {
+		ep, err := parseEndpoint(e)
+		if err != nil {
+			return outputs.Fail(err)
+		}
+		s.endpoints[i] = ep
+	}
+
+	var err error
+	for _, f := range []struct {
+		name  string
+		value string
+		field *field
+	}{
+		{"index", cfg.Index, &s.index},
+		{"source", cfg.Source, &s.source},
+		{"sourcetype", cfg.Sourcetype, &s.sourcetype},
+		{"host", cfg.Host, &s.host},
+	} {
+		*f.field, err = newField(f.value)
+		if err != nil {
+			return outputs.Fail(fmt.Errorf("invalid Splunk %s template: %v", f.name, err))
+		}
+	}
+
+	return outputs.Success(s), nil
+}
+
+// parseEndpoint validates the HEC URL and derives the URLs of the event and
+// acknowledgement endpoints. If the URL has no path, the default event path is
+// appended. Any path prefix preceding the collector path, e.g. introduced by a
+// reverse proxy, is retained in the acknowledgement URL.
+func parseEndpoint(s string) (endpoint, error) {
+	u, err := url.Parse(s)
+	if err != nil || u.Host == "" {
+		return endpoint{}, fmt.Errorf("invalid Splunk HEC endpoint %q", s)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return endpoint{}, fmt.Errorf("invalid Splunk HEC endpoint %q: scheme must be http or https", s)
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = eventPath
+	}
+	ep := endpoint{url: u.String()}
+	prefix := u.Path
+	if i := strings.Index(prefix, collectorPath); i >= 0 {
+		prefix = prefix[:i]
+	} else {
+		prefix = strings.TrimSuffix(prefix, "/")
+	}
+	u.Path = prefix + ackPath
+	u.RawQuery = ""
+	ep.ackURL = u.String()
+	return ep, nil
+}
+
+func (s *splunk) Connect() error {
+	tlsConfig, err := tls.MakeConfig(s.config.TLSCert, s.config.TLSKey, s.config.TLSCA, s.config.TLSInsecureSkipVerify)
+	if err != nil {
+		return fmt.Errorf("invalid TLS config: %v", err)
+	}
+	s.client = &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: tlsConfig,
+			Proxy:           http.ProxyFromEnvironment,
+		},
+		Timeout: s.config.Timeout,
+	}
+	if s.config.EnableAck {
+		s.wg.Add(1)
+		go s.trackAcks()
+	}
+	return nil
+}
+
+// Close stops publishing and waits for the batches that
+// are still awaiting the indexer acknowledgement.
+func (s *splunk) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+		s.wg.Wait()
+		if s.client != nil {
+			s.client.CloseIdleConnections()
+		}
+	})
+	return nil
+}
+
+// Publish sends the batch to the next endpoint in the round-robin order. If
+// the endpoint fails to accept the batch, the remaining endpoints are tried in
+// turn before giving up. When the indexer acknowledgement is enabled, Publish
+// returns as soon as the batch is accepted, and the acknowledgement is awaited
+// in the background.
+func (s *splunk) Publish(batch *kevent.Batch) error {
+	body, err := s.marshal(batch)
+	if err != nil {
+		return err
+	}
+	if s.config.EnableGzip {
+		var bb bytes.Buffer
+		gz := gzip.NewWriter(&bb)
+		if _, err := gz.Write(body); err != nil {
+			return err
+		}
+		if err := gz.Close(); err != nil {
+			return err
+		}
+		body = bb.Bytes()
+	}
+
+	if s.config.EnableAck {
+		select {
+		case <-s.quit:
+			return errClosed
+		default:
+		}
+		select {
+		case s.slots <- struct{}{}:
+		case <-s.quit:
+			return errClosed
+		}
+	}
+
+	n := len(s.endpoints)
+	start := int(s.next.Add(1)-1) % n
+	for i := 0; i < n; i++ {
+		idx := (start + i) % n
+		ep := s.endpoints[idx]
+		var id *uint64
+		id, err = s.send(ep, body)
+		if err == nil {
+			if id == nil {
+				splunkEvents.Add(batch.Len())
+				return nil
+			}
+			s.track(&pendingAck{id: *id, ep: idx, body: body, events: batch.Len(), attempts: i + 1})
+			return nil
+		}
+		splunkEndpointErrors.Add(ep.url, 1)
+		if i < n-1 {
+			log.Warnf("unable to publish events to Splunk HEC endpoint %s. Trying the next endpoint: %v", ep.url, err)
+		}
+	}
+	if s.config.EnableAck {
+		<-s.slots
+	}
+	return err
+}
+
+// marshal encodes events in HEC envelopes. HEC accepts a stream of
+// concatenated JSON objects in a single request.
+func (s *splunk) marshal(batch *kevent.Batch) ([]byte, error) {
+	b := make([]byte, 0, len(batch.Events)*1024)
+	for _, e := range batch.Events {
+		evt, err := s.config.Serializer.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, `{"time":`...)
+		b = appendEpoch(b, e.Timestamp)
+		host := s.host.render(e)
+		if host == "" {
+			host = e.Host
+		}
+		b = appendString(b, "host", host)
+		b = appendString(b, "source", s.source.render(e))
+		b = appendString(b, "sourcetype", s.sourcetype.render(e))
+		b = appendString(b, "index", s.index.render(e))
+		b = append(b, `,"event":`...)
+		b = append(b, evt...)
+		b = append(b, '}', '\n')
+	}
+	return b, nil
+}
+
+// send posts the request body to the event endpoint. If the indexer
+// acknowledgement is enabled, it returns the acknowledgement identifier.
+func (s *splunk) send(ep endpoint, body []byte) (*uint64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	s.setHeaders(req)
+	if s.config.EnableGzip {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	if !s.config.EnableAck {
+		return nil, nil
+	}
+	if resp.AckID == nil {
+		return nil, fmt.Errorf("no acknowledgement identifier in HEC response. Make sure indexer acknowledgement is enabled for the token")
+	}
+	return resp.AckID, nil
+}
+
+// track registers the batch awaiting the indexer acknowledgement.
+func (s *splunk) track(a *pendingAck) {
+	a.deadline = time.Now().Add(s.config.AckTimeout)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.acks[ackKey{a.ep, a.id}] = a
+}
+
+// untrack removes the batch from pending acknowledgements.
+func (s *splunk) untrack(a *pendingAck) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.acks, ackKey{a.ep, a.id})
+}
+
+func (s *splunk) pendingAcks() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.acks)
+}
+
+// trackAcks periodically polls the acknowledgement status of pending
+// batches. When the output is closed, it keeps polling until all pending
+// batches are either acknowledged or time out.
+func (s *splunk) trackAcks() {
+	defer s.wg.Done()
+	tick := time.NewTicker(s.config.AckPollInterval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			s.pollAcks(true)
+		case <-s.quit:
+			for s.pendingAcks() > 0 {
+				<-tick.C
+				s.pollAcks(false)
+			}
+			return
+		}
+	}
+}
+
+// pollAcks queries the acknowledgement status of all pending batches with
+// a single request per endpoint. Acknowledged batches are released. Batches
+// that weren't acknowledged in due time are sent to the next endpoint if the
+// resend flag is true, or dropped otherwise.
+func (s *splunk) pollAcks(resend bool) {
+	s.mu.Lock()
+	pending := make(map[int][]*pendingAck)
+	for _, a := range s.acks {
+		pending[a.ep] = append(pending[a.ep], a)
+	}
+	s.mu.Unlock()
+
+	now := time.Now()
+	for idx, acks := range pending {
+		ep := s.endpoints[idx]
+		ids := make([]uint64, len(acks))
+		for i, a := range acks {
+			ids[i] = a.id
+		}
+		acked, err := s.queryAcks(ep, ids)
+		if err != nil {
+			splunkEndpointErrors.Add(ep.url, 1)
+			log.Warnf("unable to query indexer acknowledgements on Splunk HEC endpoint %s: %v", ep.url, err)
+		}
+		for _, a := range acks {
+			switch {
+			case acked[a.id]:
+				s.untrack(a)
+				splunkEvents.Add(a.events)
+				<-s.slots
+			case now.After(a.deadline):
+				splunkAckTimeouts.Add(1)
+				s.untrack(a)
+				if resend {
+					s.resend(a)
+					continue
+				}
+				s.drop(a)
+			}
+		}
+	}
+}
+
+// resend sends the batch that wasn't acknowledged in due time to
+// the next endpoint. The batch is dropped once all endpoints fail
+// to accept or acknowledge it.
+func (s *splunk) resend(a *pendingAck) {
+	n := len(s.endpoints)
+	for a.attempts < n {
+		a.ep = (a.ep + 1) % n
+		a.attempts++
+		ep := s.endpoints[a.ep]
+		id, err := s.send(ep, a.body)
+		if err == nil {
+			a.id = *id
+			s.track(a)
+			return
+		}
+		splunkEndpointErrors.Add(ep.url, 1)
+		log.Warnf("unable to resend unacknowledged events to Splunk HEC endpoint %s: %v", ep.url, err)
+	}
+	s.drop(a)
+}
+
+func (s *splunk) drop(a *pendingAck) {
+	splunkAckDrops.Add(1)
+	log.Errorf("dropping %d event(s) that weren't acknowledged by Splunk HEC indexers", a.events)
+	<-s.slots
+}
+
+// queryAcks checks which of the requests with the given
+// acknowledgement identifiers have been indexed.
+func (s *splunk) queryAcks(ep endpoint, ids []uint64) (map[uint64]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+	body, err := json.Marshal(struct {
+		Acks []uint64 `json:"acks"`
+	}{ids})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.ackURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	s.setHeaders(req)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, hecError(resp.StatusCode, b)
+	}
+	var acks struct {
+		Acks map[string]bool `json:"acks"`
+	}
+	if err := json.Unmarshal(b, &acks); err != nil {
+		return nil, fmt.Errorf("invalid HEC acknowledgement response: %v", err)
+	}
+	acked := make(map[uint64]bool, len(acks.Acks))
+	for k, ok := range acks.Acks {
+		id, err := strconv.ParseUint(k, 10, 64)
+		if err != nil {
+			continue
+		}
+		acked[id] = ok
+	}
+	return acked, nil
+}
+
+// do executes the HEC request and decodes the response.
+func (s *splunk) do(req *http.Request) (*response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, hecError(resp.StatusCode, b)
+	}
+	var r response
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &r); err != nil {
+			return nil, fmt.Errorf("invalid HEC response: %v", err)
+		}
+	}
+	return &r, nil
+}
+
+// setHeaders populates the authentication, channel, and content headers.
+func (s *splunk) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Splunk "+s.config.Token)
+	req.Header.Set("User-Agent", userAgentHeader)
+	req.Header.Set("Content-Type", "application/json")
+	if s.config.Channel != "" {
+		req.Header.Set("X-Splunk-Request-Channel", s.config.Channel)
+	}
+}
+
+// hecError builds the error from the HEC status code and response text.
+func hecError(status int, body []byte) error {
+	var r response
+	if err := json.Unmarshal(body, &r); err == nil && r.Text != "" {
+		return fmt.Errorf("HEC request failed with %d status code: %s (code %d)", status, r.Text, r.Code)
+	}
+	return fmt.Errorf("HEC request failed with %d status code: %s", status, string(body))
+}
+
+// appendEpoch appends the timestamp as epoch seconds with millisecond precision.
+func appendEpoch(b []byte, ts time.Time) []byte {
+	ms := ts.UnixMilli()
+	b = strconv.AppendInt(b, ms/1000, 10)
+	b = append(b, '.')
+	frac := ms % 1000
+	if frac < 100 {
+		b = append(b, '0')
+	}
+	if frac < 10 {
+		b = append(b, '0')
+	}
+	return strconv.AppendInt(b, frac, 10)
+}
+
+// appendString appends the JSON string attribute if the value is not empty.
+func appendString(b []byte, key, value string) []byte {
+	if value == "" {
+		return b
+	}
+	v, _ := json.Marshal(value)
+	b = append(b, ',', '"')
+	b = append(b, key...)
+	b = append(b, '"', ':')
+	return append(b, v...)
+}
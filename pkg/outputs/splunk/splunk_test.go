@@ -0,0 +1,362 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package splunk
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+type envelope struct {
+	Time       json.Number     `json:"time"`
+	Host       string          `json:"host"`
+	Source     string          `json:"source"`
+	Sourcetype string          `json:"sourcetype"`
+	Index      string          `json:"index"`
+	Event      json.RawMessage `json:"event"`
+}
+
+func TestSplunkPublish(t *testing.T) {
+	var tests = []struct {
+		name       string
+		enableGzip bool
+	}{
+		{"plain", false},
+		{"gzip", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var envelopes []envelope
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, eventPath, r.URL.Path)
+				assert.Equal(t, "Splunk 0b8e2e4c", r.Header.Get("Authorization"))
+				var body io.Reader = r.Body
+				if tt.enableGzip {
+					assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+					gz, err := gzip.NewReader(r.Body)
+					require.NoError(t, err)
+					body = gz
+				}
+				dec := json.NewDecoder(body)
+				for dec.More() {
+					var env envelope
+					require.NoError(t, dec.Decode(&env))
+					envelopes = append(envelopes, env)
+				}
+				_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+			}))
+			defer srv.Close()
+
+			s := newTestSplunk(t, Config{
+				Endpoints:  []string{srv.URL},
+				Token:      "0b8e2e4c",
+				Index:      "windows",
+				Source:     "fibratus",
+				Sourcetype: "fibratus:{{ .Category }}",
+				EnableGzip: tt.enableGzip,
+			})
+			require.NoError(t, s.Publish(getBatch()))
+
+			require.Len(t, envelopes, 3)
+			env := envelopes[0]
+			assert.Equal(t, "1700000000.042", env.Time.String())
+			assert.Equal(t, "archrabbit", env.Host)
+			assert.Equal(t, "fibratus", env.Source)
+			assert.Equal(t, "fibratus:file", env.Sourcetype)
+			assert.Equal(t, "windows", env.Index)
+			var evt map[string]any
+			require.NoError(t, json.Unmarshal(env.Event, &evt))
+			assert.Equal(t, "CreateFile", evt["name"])
+			assert.Equal(t, "rabbitstack", envelopes[2].Host)
+		})
+	}
+}
+
+func TestSplunkHostTemplate(t *testing.T) {
+	s := newTestSplunk(t, Config{Endpoints: []string{"http://localhost:8088"}, Token: "token", Host: "{{ .Host }}-{{ .Process }}"})
+	b, err := s.marshal(kevent.NewBatch(getEvent(1, "archrabbit")))
+	require.NoError(t, err)
+	var env envelope
+	require.NoError(t, json.Unmarshal(b, &env))
+	assert.Equal(t, "archrabbit-firefox.exe", env.Host)
+	assert.Empty(t, env.Index)
+}
+
+func TestSplunkIndexerAck(t *testing.T) {
+	var (
+		ackID atomic.Uint64
+		polls atomic.Int32
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "5f0f8e2c-ccd9-4d5f-a5ea-6e3d3d2e7f4b", r.Header.Get("X-Splunk-Request-Channel"))
+		switch r.URL.Path {
+		case "/hec" + eventPath:
+			_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":` + strconv.FormatUint(ackID.Add(1), 10) + `}`))
+		case "/hec" + ackPath:
+			b, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			// pending acknowledgements are queried in bulk
+			assert.JSONEq(t, `{"acks":[1,2,3]}`, sortAcks(t, b))
+			// the requests are indexed on the third poll
+			ok := strconv.FormatBool(polls.Add(1) == 3)
+			_, _ = w.Write([]byte(`{"acks":{"1":` + ok + `,"2":` + ok + `,"3":` + ok + `}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	s := newTestSplunk(t, Config{
+		Endpoints:       []string{srv.URL + "/hec" + eventPath},
+		Token:           "token",
+		EnableAck:       true,
+		Channel:         "5f0f8e2c-ccd9-4d5f-a5ea-6e3d3d2e7f4b",
+		AckPollInterval: time.Millisecond * 100,
+	})
+	// publishing doesn't wait for the acknowledgement
+	for i := 0; i < 3; i++ {
+		require.NoError(t, s.Publish(getBatch()))
+	}
+	assert.Equal(t, 3, s.pendingAcks())
+	require.Eventually(t, func() bool { return s.pendingAcks() == 0 }, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, int32(3), polls.Load())
+	assert.Len(t, s.slots, 0)
+}
+
+func TestSplunkIndexerAckTimeout(t *testing.T) {
+	var hits atomic.Int32
+	// the first endpoint never acknowledges the request
+	srv1 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ackPath {
+			_, _ = w.Write([]byte(`{"acks":{"1":false}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":1}`))
+	}))
+	defer srv1.Close()
+	srv2 := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ackPath {
+			_, _ = w.Write([]byte(`{"acks":{"5":true}}`))
+			return
+		}
+		hits.Add(1)
+		_, _ = w.Write([]byte(`{"text":"Success","code":0,"ackId":5}`))
+	}))
+	defer srv2.Close()
+
+	timeouts := splunkAckTimeouts.Value()
+	s := newTestSplunk(t, Config{
+		Endpoints:       []string{srv1.URL, srv2.URL},
+		Token:           "token",
+		EnableAck:       true,
+		AckTimeout:      time.Millisecond * 50,
+		AckPollInterval: time.Millisecond * 10,
+	})
+	assert.NotEmpty(t, s.config.Channel)
+
+	// the unacknowledged batch is sent to the next endpoint
+	require.NoError(t, s.Publish(getBatch()))
+	require.Eventually(t, func() bool { return s.pendingAcks() == 0 }, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, int32(1), hits.Load())
+	assert.Equal(t, timeouts+1, splunkAckTimeouts.Value())
+
+	// the batch is dropped when no endpoint acknowledges it
+	drops := splunkAckDrops.Value()
+	s = newTestSplunk(t, Config{
+		Endpoints:       []string{srv1.URL},
+		Token:           "token",
+		EnableAck:       true,
+		AckTimeout:      time.Millisecond * 50,
+		AckPollInterval: time.Millisecond * 10,
+	})
+	require.NoError(t, s.Publish(getBatch()))
+	require.Eventually(t, func() bool { return s.pendingAcks() == 0 }, time.Second*5, time.Millisecond*10)
+	assert.Equal(t, drops+1, splunkAckDrops.Value())
+	assert.Len(t, s.slots, 0)
+
+	// closing the output more than once doesn't panic
+	require.NoError(t, s.Close())
+	require.NoError(t, s.Close())
+	require.ErrorIs(t, s.Publish(getBatch()), errClosed)
+}
+
+func TestSplunkRoundRobin(t *testing.T) {
+	var mu sync.Mutex
+	hits := make(map[string]int)
+	handler := func(name string, status int) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			hits[name]++
+			mu.Unlock()
+			w.WriteHeader(status)
+			if status != http.StatusOK {
+				_, _ = w.Write([]byte(`{"text":"Server is busy","code":9}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"text":"Success","code":0}`))
+		}
+	}
+	srv1 := httptest.NewServer(handler("srv1", http.StatusOK))
+	defer srv1.Close()
+	srv2 := httptest.NewServer(handler("srv2", http.StatusOK))
+	defer srv2.Close()
+	srv3 := httptest.NewServer(handler("srv3", http.StatusServiceUnavailable))
+	defer srv3.Close()
+
+	s := newTestSplunk(t, Config{Endpoints: []string{srv1.URL, srv2.URL}, Token: "token"})
+	for i := 0; i < 4; i++ {
+		require.NoError(t, s.Publish(getBatch()))
+	}
+	assert.Equal(t, 2, hits["srv1"])
+	assert.Equal(t, 2, hits["srv2"])
+
+	// the failing endpoint is skipped in favor of the next endpoint
+	s = newTestSplunk(t, Config{Endpoints: []string{srv3.URL, srv1.URL}, Token: "token"})
+	require.NoError(t, s.Publish(getBatch()))
+	assert.Equal(t, 1, hits["srv3"])
+	assert.Equal(t, 3, hits["srv1"])
+
+	s = newTestSplunk(t, Config{Endpoints: []string{srv3.URL}, Token: "token"})
+	require.ErrorContains(t, s.Publish(getBatch()), "Server is busy")
+}
+
+func TestParseEndpoint(t *testing.T) {
+	var tests = []struct {
+		endpoint string
+		url      string
+		ackURL   string
+		err      bool
+	}{
+		{"https://splunk:8088", "https://splunk:8088/services/collector/event", "https://splunk:8088/services/collector/ack", false},
+		{"https://splunk:8088/", "https://splunk:8088/services/collector/event", "https://splunk:8088/services/collector/ack", false},
+		{"https://splunk/proxy/services/collector/event/1.0", "https://splunk/proxy/services/collector/event/1.0", "https://splunk/proxy/services/collector/ack", false},
+		{"https://splunk/ingest", "https://splunk/ingest", "https://splunk/ingest/services/collector/ack", false},
+		{"tcp://splunk:8088", "", "", true},
+		{"splunk", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			ep, err := parseEndpoint(tt.endpoint)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.url, ep.url)
+			assert.Equal(t, tt.ackURL, ep.ackURL)
+		})
+	}
+}
+
+func TestInitSplunkInvalidConfig(t *testing.T) {
+	var tests = []struct {
+		name   string
+		config Config
+	}{
+		{"no endpoints", Config{Token: "token"}},
+		{"no token", Config{Endpoints: []string{"http://localhost:8088"}}},
+		{"unsupported serializer", Config{Endpoints: []string{"http://localhost:8088"}, Token: "token", Serializer: outputs.CEF}},
+		{"invalid template", Config{Endpoints: []string{"http://localhost:8088"}, Token: "token", Sourcetype: "{{ .Unknown }}"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := initSplunk(outputs.Config{Type: outputs.Splunk, Output: tt.config})
+			require.Error(t, err)
+		})
+	}
+}
+
+// sortAcks sorts the acknowledgement identifiers in the request body.
+func sortAcks(t *testing.T, b []byte) string {
+	var req struct {
+		Acks []uint64 `json:"acks"`
+	}
+	require.NoError(t, json.Unmarshal(b, &req))
+	slices.Sort(req.Acks)
+	out, err := json.Marshal(req)
+	require.NoError(t, err)
+	return string(out)
+}
+
+func newTestSplunk(t *testing.T, config Config) *splunk {
+	group, err := initSplunk(outputs.Config{Type: outputs.Splunk, Output: config})
+	require.NoError(t, err)
+	s := group.Clients[0].(*splunk)
+	require.NoError(t, s.Connect())
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+func getBatch() *kevent.Batch {
+	return kevent.NewBatch(
+		getEvent(1, "archrabbit"),
+		getEvent(2, "archrabbit"),
+		getEvent(3, "rabbitstack"),
+	)
+}
+
+func getEvent(seq uint64, host string) *kevent.Kevent {
+	return &kevent.Kevent{
+		Type:        ktypes.CreateFile,
+		Tid:         2484,
+		PID:         859,
+		CPU:         1,
+		Seq:         seq,
+		Name:        "CreateFile",
+		Timestamp:   time.Unix(1700000000, 42*int64(time.Millisecond)),
+		Category:    ktypes.File,
+		Host:        host,
+		Description: "Creates or opens a new file, directory, I/O device, pipe, console",
+		Kparams: kevent.Kparams{
+			kparams.FilePath:      {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: `C:\Windows\system32\user32.dll`},
+			kparams.FileOperation: {Name: kparams.FileOperation, Type: kparams.AnsiString, Value: "open"},
+		},
+		Metadata: kevent.Metadata{"signed": "yes"},
+		PS: &pstypes.PS{
+			PID:       859,
+			Ppid:      6304,
+			Name:      "firefox.exe",
+			Exe:       `C:\Program Files\Mozilla Firefox\firefox.exe`,
+			SID:       "S-1-5-18",
+			StartTime: time.Now(),
+			Parent:    &pstypes.PS{PID: 6304, Name: "explorer.exe"},
+		},
+	}
+}
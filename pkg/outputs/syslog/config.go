@@ -0,0 +1,111 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package syslog
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+)
+
+const (
+	syslogEnabled    = "output.syslog.enabled"
+	syslogNetwork    = "output.syslog.network"
+	syslogAddress    = "output.syslog.address"
+	syslogFormat     = "output.syslog.format"
+	syslogFraming    = "output.syslog.framing"
+	syslogFacility   = "output.syslog.facility"
+	syslogSeverity   = "output.syslog.severity"
+	syslogAppName    = "output.syslog.app-name"
+	syslogHostname   = "output.syslog.hostname"
+	syslogTimeout    = "output.syslog.timeout"
+	syslogMaxBackoff = "output.syslog.max-backoff"
+	syslogSerializer = "output.syslog.serializer"
+)
+
+// Format designates the syslog message header format.
+type Format string
+
+const (
+	// RFC5424 is the IETF syslog protocol header format.
+	RFC5424 Format = "rfc5424"
+	// RFC3164 is the legacy BSD syslog header format.
+	RFC3164 Format = "rfc3164"
+)
+
+// Framing designates how messages are delimited on stream transports.
+type Framing string
+
+const (
+	// OctetCounting prefixes each message with its length as described in RFC 6587.
+	OctetCounting Framing = "octet-counting"
+	// NonTransparent terminates each message with the line feed character.
+	NonTransparent Framing = "non-transparent"
+)
+
+// Config contains the options for tweaking the syslog output behaviour.
+type Config struct {
+	outputs.TLSConfig
+	// Enabled determines whether syslog output is enabled.
+	Enabled bool `mapstructure:"enabled"`
+	// Network is the transport protocol. It can be one of udp, tcp, or tls.
+	Network string `mapstructure:"network"`
+	// Address is the host:port address of the syslog receiver.
+	Address string `mapstructure:"address"`
+	// Format determines the syslog header format.
+	Format Format `mapstructure:"format"`
+	// Framing determines the message framing on the tcp and tls transports.
+	Framing Framing `mapstructure:"framing"`
+	// Facility is the syslog facility name, e.g. local0 or security.
+	Facility string `mapstructure:"facility"`
+	// Severity is the syslog severity name assigned to event messages.
+	Severity string `mapstructure:"severity"`
+	// AppName identifies the application that originates the message.
+	AppName string `mapstructure:"app-name"`
+	// Hostname overrides the hostname in the syslog header. The event host is used if empty.
+	Hostname string `mapstructure:"hostname"`
+	// Timeout is the dial and write timeout.
+	Timeout time.Duration `mapstructure:"timeout"`
+	// MaxBackoff is the maximum interval between reconnection attempts.
+	MaxBackoff time.Duration `mapstructure:"max-backoff"`
+	// Serializer determines the message body format. It can be one of json, cef, or leef.
+	Serializer outputs.Serializer `mapstructure:"serializer"`
+	// Fields maps event fields to CEF/LEEF extension keys. The
+	// default mapping for the given serializer is used if empty.
+	Fields map[string]string `mapstructure:"fields"`
+}
+
+// AddFlags registers persistent flags for the syslog output.
+func AddFlags(flags *pflag.FlagSet) {
+	flags.Bool(syslogEnabled, false, "Determines whether the syslog output is enabled")
+	flags.String(syslogNetwork, "udp", "The transport protocol for sending messages. Possible values are udp, tcp, and tls")
+	flags.String(syslogAddress, "localhost:514", "The address of the syslog receiver in the host:port format")
+	flags.String(syslogFormat, string(RFC5424), "The syslog header format. Possible values are rfc5424 and rfc3164")
+	flags.String(syslogFraming, string(OctetCounting), "The message framing on stream transports. Possible values are octet-counting and non-transparent")
+	flags.String(syslogFacility, "local0", "The syslog facility of event messages")
+	flags.String(syslogSeverity, "info", "The syslog severity of event messages")
+	flags.String(syslogAppName, "fibratus", "Identifies the application that originates the message")
+	flags.String(syslogHostname, "", "Overrides the hostname in the syslog header. The event host is used if empty")
+	flags.Duration(syslogTimeout, time.Second*5, "The dial and write timeout")
+	flags.Duration(syslogMaxBackoff, time.Minute, "The maximum interval between reconnection attempts")
+	flags.String(syslogSerializer, string(outputs.JSON), "The message body format. Possible values are json, cef, and leef")
+	outputs.AddTLSFlags(flags, outputs.Syslog)
+}
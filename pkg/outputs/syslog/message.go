@@ -0,0 +1,318 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package syslog
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	"github.com/rabbitstack/fibratus/pkg/util/version"
+)
+
+const (
+	vendor  = "rabbitstack"
+	product = "fibratus"
+	// nilValue is the RFC 5424 placeholder for the absent header field
+	nilValue = "-"
+)
+
+var facilities = map[string]int{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"ntp":      12,
+	"security": 13,
+	"console":  14,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+var severities = map[string]int{
+	"emerg":   0,
+	"alert":   1,
+	"crit":    2,
+	"err":     3,
+	"warning": 4,
+	"notice":  5,
+	"info":    6,
+	"debug":   7,
+}
+
+// cefSeverities maps syslog severities to the CEF severity scale
+var cefSeverities = [...]int{10, 9, 8, 7, 5, 4, 3, 1}
+
+// defaultCEFFields is the default mapping of event fields to CEF extension keys
+var defaultCEFFields = map[string]string{
+	"kevt.seq":      "externalId",
+	"kevt.category": "cat",
+	"kevt.host":     "dvchost",
+	"kevt.pid":      "dvcpid",
+	"ps.pid":        "spid",
+	"ps.name":       "sproc",
+	"ps.username":   "suser",
+	"ps.domain":     "sntdom",
+}
+
+// defaultLEEFFields is the default mapping of event fields to LEEF attributes
+var defaultLEEFFields = map[string]string{
+	"kevt.seq":      "externalId",
+	"kevt.category": "cat",
+	"kevt.host":     "identHostName",
+	"kevt.pid":      "pid",
+	"ps.name":       "proc",
+	"ps.exe":        "procPath",
+	"ps.username":   "usrName",
+	"ps.domain":     "domain",
+}
+
+// mapping is the single event field to extension key association.
+type mapping struct {
+	field string
+	key   string
+}
+
+// formatter renders events to syslog messages.
+type formatter struct {
+	config   Config
+	pri      int
+	severity int
+	hostname string
+	local    string
+	version  string
+	fields   []mapping
+}
+
+func newFormatter(config Config) (*formatter, error) {
+	facility, ok := facilities[strings.ToLower(config.Facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility: %s", config.Facility)
+	}
+	severity, ok := severities[strings.ToLower(config.Severity)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog severity: %s", config.Severity)
+	}
+	switch config.Format {
+	case RFC5424, RFC3164:
+	default:
+		return nil, fmt.Errorf("unknown syslog format: %s", config.Format)
+	}
+
+	f := &formatter{
+		config:   config,
+		pri:      facility*8 + severity,
+		severity: severity,
+		hostname: config.Hostname,
+		version:  version.Get(),
+	}
+	f.local, _ = os.Hostname()
+
+	fields := config.Fields
+	switch config.Serializer {
+	case outputs.JSON:
+	case outputs.CEF:
+		if len(fields) == 0 {
+			fields = defaultCEFFields
+		}
+	case outputs.LEEF:
+		if len(fields) == 0 {
+			fields = defaultLEEFFields
+		}
+	default:
+		return nil, fmt.Errorf("unsupported syslog serializer: %s", config.Serializer)
+	}
+	for field, key := range fields {
+		if !outputs.IsKnownField(field) {
+			return nil, fmt.Errorf("unknown %q field in syslog fields", field)
+		}
+		f.fields = append(f.fields, mapping{field: field, key: key})
+	}
+	// keep extensions ordering stable across messages
+	sort.Slice(f.fields, func(i, j int) bool { return f.fields[i].key < f.fields[j].key })
+
+	return f, nil
+}
+
+// format renders the event to the syslog message, including the header.
+func (f *formatter) format(e *kevent.Kevent) []byte {
+	b := make([]byte, 0, 512)
+	b = f.appendHeader(b, e)
+	switch f.config.Serializer {
+	case outputs.CEF:
+		b = f.appendCEF(b, e)
+	case outputs.LEEF:
+		b = f.appendLEEF(b, e)
+	default:
+		b = append(b, e.MarshalJSON()...)
+	}
+	return b
+}
+
+// appendHeader appends the RFC 5424 or RFC 3164 header.
+func (f *formatter) appendHeader(b []byte, e *kevent.Kevent) []byte {
+	hostname := f.hostname
+	if hostname == "" {
+		hostname = e.Host
+	}
+	if hostname == "" {
+		hostname = f.local
+	}
+	if hostname == "" {
+		hostname = nilValue
+	}
+	appName := f.config.AppName
+	if appName == "" {
+		appName = nilValue
+	}
+	procID := nilValue
+	if e.PID != 0 {
+		procID = strconv.FormatUint(uint64(e.PID), 10)
+	}
+	ts := e.Timestamp
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	b = append(b, '<')
+	b = strconv.AppendInt(b, int64(f.pri), 10)
+	b = append(b, '>')
+
+	if f.config.Format == RFC3164 {
+		b = ts.AppendFormat(b, time.Stamp)
+		b = append(b, ' ')
+		b = append(b, hostname...)
+		b = append(b, ' ')
+		b = append(b, appName...)
+		b = append(b, '[')
+		b = append(b, procID...)
+		b = append(b, "]: "...)
+		return b
+	}
+
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	b = append(b, "1 "...)
+	b = ts.AppendFormat(b, "2006-01-02T15:04:05.000000Z07:00")
+	b = append(b, ' ')
+	b = append(b, hostname...)
+	b = append(b, ' ')
+	b = append(b, appName...)
+	b = append(b, ' ')
+	b = append(b, procID...)
+	b = append(b, ' ')
+	if e.Name != "" {
+		b = append(b, e.Name...)
+	} else {
+		b = append(b, nilValue...)
+	}
+	b = append(b, " - "...)
+	return b
+}
+
+// appendCEF appends the ArcSight Common Event Format body.
+//
+//	CEF:Version|Device Vendor|Device Product|Device Version|Device Event Class ID|Name|Severity|[Extension]
+func (f *formatter) appendCEF(b []byte, e *kevent.Kevent) []byte {
+	b = append(b, "CEF:0|"...)
+	b = append(b, escapeCEFHeader(vendor)...)
+	b = append(b, '|')
+	b = append(b, escapeCEFHeader(product)...)
+	b = append(b, '|')
+	b = append(b, escapeCEFHeader(f.version)...)
+	b = append(b, '|')
+	b = append(b, escapeCEFHeader(e.Name)...)
+	b = append(b, '|')
+	b = append(b, escapeCEFHeader(e.Description)...)
+	b = append(b, '|')
+	b = strconv.AppendInt(b, int64(cefSeverities[f.severity]), 10)
+	b = append(b, "|rt="...)
+	b = strconv.AppendInt(b, e.Timestamp.UnixMilli(), 10)
+	for _, m := range f.fields {
+		v, ok := outputs.FieldValue(e, m.field)
+		if !ok {
+			continue
+		}
+		b = append(b, ' ')
+		b = append(b, m.key...)
+		b = append(b, '=')
+		b = append(b, escapeCEFExtension(v)...)
+	}
+	return b
+}
+
+// appendLEEF appends the QRadar Log Event Extended Format body. Attributes
+// are separated by the tab character which is the default LEEF delimiter.
+//
+//	LEEF:Version|Vendor|Product|Version|EventID|Extension
+func (f *formatter) appendLEEF(b []byte, e *kevent.Kevent) []byte {
+	b = append(b, "LEEF:1.0|"...)
+	b = append(b, escapeLEEFHeader(vendor)...)
+	b = append(b, '|')
+	b = append(b, escapeLEEFHeader(product)...)
+	b = append(b, '|')
+	b = append(b, escapeLEEFHeader(f.version)...)
+	b = append(b, '|')
+	b = append(b, escapeLEEFHeader(e.Name)...)
+	b = append(b, "|devTime="...)
+	b = strconv.AppendInt(b, e.Timestamp.UnixMilli(), 10)
+	b = append(b, "\tdevTimeFormat=milliseconds\tsev="...)
+	b = strconv.AppendInt(b, int64(cefSeverities[f.severity]), 10)
+	for _, m := range f.fields {
+		v, ok := outputs.FieldValue(e, m.field)
+		if !ok {
+			continue
+		}
+		b = append(b, '\t')
+		b = append(b, m.key...)
+		b = append(b, '=')
+		b = append(b, escapeLEEFAttribute(v)...)
+	}
+	return b
+}
+
+var (
+	cefHeaderReplacer    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionReplacer = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\r", `\r`, "\n", `\n`)
+	leefHeaderReplacer   = strings.NewReplacer(`|`, `\|`, "\r", " ", "\n", " ")
+	leefValueReplacer    = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+)
+
+func escapeCEFHeader(s string) string     { return cefHeaderReplacer.Replace(s) }
+func escapeCEFExtension(s string) string  { return cefExtensionReplacer.Replace(s) }
+func escapeLEEFHeader(s string) string    { return leefHeaderReplacer.Replace(s) }
+func escapeLEEFAttribute(s string) string { return leefValueReplacer.Replace(s) }
@@ -0,0 +1,247 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package syslog
+
+import (
+	"crypto/tls"
+	"errors"
+	"expvar"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	tlsutil "github.com/rabbitstack/fibratus/pkg/util/tls"
+)
+
+var (
+	// syslogErrors counts syslog message delivery errors
+	syslogErrors = expvar.NewInt("output.syslog.publish.errors")
+	// syslogMessages counts the total number of sent syslog messages
+	syslogMessages = expvar.NewInt("output.syslog.publish.messages")
+	// syslogReconnects counts the number of reconnection attempts
+	syslogReconnects = expvar.NewInt("output.syslog.reconnects")
+
+	// errDisconnected is returned when the connection is down and the next reconnection attempt is not due yet
+	errDisconnected = errors.New("syslog connection is down")
+)
+
+type syslog struct {
+	sync.Mutex
+	config    Config
+	formatter *formatter
+	tlsConfig *tls.Config
+	conn      net.Conn
+	backoff   *backoff.ExponentialBackOff
+	// retryAt is the time of the next reconnection attempt
+	retryAt time.Time
+}
+
+func init() {
+	outputs.Register(outputs.Syslog, initSyslog)
+}
+
+func initSyslog(config outputs.Config) (outputs.OutputGroup, error) {
+	cfg, ok := config.Output.(Config)
+	if !ok {
+		return outputs.Fail(outputs.ErrInvalidConfig(outputs.Syslog, config.Output))
+	}
+	s, err := newSyslog(cfg)
+	if err != nil {
+		return outputs.Fail(err)
+	}
+	return outputs.Success(s), nil
+}
+
+func newSyslog(config Config) (*syslog, error) {
+	switch config.Network {
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("unsupported syslog network: %s", config.Network)
+	}
+	switch config.Framing {
+	case OctetCounting, NonTransparent:
+	default:
+		return nil, fmt.Errorf("unsupported syslog framing: %s", config.Framing)
+	}
+	if _, _, err := net.SplitHostPort(config.Address); err != nil {
+		return nil, fmt.Errorf("invalid syslog address: %v", err)
+	}
+	formatter, err := newFormatter(config)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &syslog{config: config, formatter: formatter}
+	if config.Network == "tls" {
+		s.tlsConfig, err = tlsutil.MakeConfig(config.TLSCert, config.TLSKey, config.TLSCA, config.TLSInsecureSkipVerify)
+		if err != nil {
+			return nil, err
+		}
+		if s.tlsConfig == nil {
+			// no client certificate or CA given, so the server
+			// certificate is verified against system roots
+			s.tlsConfig = &tls.Config{InsecureSkipVerify: config.TLSInsecureSkipVerify}
+		}
+		if s.tlsConfig.ServerName == "" && !config.TLSInsecureSkipVerify {
+			s.tlsConfig.ServerName, _, _ = net.SplitHostPort(config.Address)
+		}
+	}
+
+	maxBackoff := config.MaxBackoff
+	if maxBackoff == 0 {
+		maxBackoff = time.Minute
+	}
+	s.backoff = &backoff.ExponentialBackOff{
+		InitialInterval:     time.Millisecond * 500,
+		RandomizationFactor: backoff.DefaultRandomizationFactor,
+		Multiplier:          backoff.DefaultMultiplier,
+		MaxInterval:         maxBackoff,
+		Stop:                backoff.Stop,
+		Clock:               backoff.SystemClock,
+	}
+	s.backoff.Reset()
+
+	return s, nil
+}
+
+func (s *syslog) Connect() error {
+	s.Lock()
+	defer s.Unlock()
+	return s.dial()
+}
+
+func (s *syslog) Close() error {
+	s.Lock()
+	defer s.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// Publish writes each event in the batch as a separate syslog message. If the
+// connection is broken, the client tries to reestablish it, but reconnection
+// attempts are spaced out by the exponential backoff to avoid stalling the
+// aggregator when the receiver is down.
+func (s *syslog) Publish(batch *kevent.Batch) error {
+	s.Lock()
+	defer s.Unlock()
+
+	for _, e := range batch.Events {
+		msg := s.frame(s.formatter.format(e))
+		if err := s.write(msg); err != nil {
+			syslogErrors.Add(1)
+			return err
+		}
+		syslogMessages.Add(1)
+	}
+
+	return nil
+}
+
+// write sends the message and retries once on a fresh connection
+// if the write fails on the existing connection.
+func (s *syslog) write(msg []byte) error {
+	if s.conn == nil {
+		if err := s.reconnect(); err != nil {
+			return err
+		}
+	}
+	err := s.send(msg)
+	if err == nil {
+		return nil
+	}
+	log.Warnf("syslog write failed: %v. Trying to reconnect...", err)
+	_ = s.conn.Close()
+	s.conn = nil
+	if err := s.reconnect(); err != nil {
+		return err
+	}
+	return s.send(msg)
+}
+
+func (s *syslog) send(msg []byte) error {
+	if s.config.Timeout > 0 {
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.config.Timeout))
+	}
+	_, err := s.conn.Write(msg)
+	return err
+}
+
+// reconnect dials the receiver if the backoff interval
+// since the last failed attempt has elapsed.
+func (s *syslog) reconnect() error {
+	if time.Now().Before(s.retryAt) {
+		return errDisconnected
+	}
+	syslogReconnects.Add(1)
+	err := s.dial()
+	if err != nil {
+		next := s.backoff.NextBackOff()
+		s.retryAt = time.Now().Add(next)
+		log.Warnf("fail to connect to syslog receiver: %v. Reconnecting in %v...", err, next)
+		return err
+	}
+	return nil
+}
+
+func (s *syslog) dial() error {
+	var (
+		conn   net.Conn
+		err    error
+		dialer = &net.Dialer{Timeout: s.config.Timeout}
+	)
+	switch s.config.Network {
+	case "tls":
+		conn, err = tls.DialWithDialer(dialer, "tcp", s.config.Address, s.tlsConfig)
+	default:
+		conn, err = dialer.Dial(s.config.Network, s.config.Address)
+	}
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+	s.retryAt = time.Time{}
+	s.backoff.Reset()
+	return nil
+}
+
+// frame delimits the message according to the configured framing method.
+// Datagrams carry a single message each, so they are never framed.
+func (s *syslog) frame(msg []byte) []byte {
+	if s.config.Network == "udp" {
+		return msg
+	}
+	if s.config.Framing == NonTransparent {
+		return append(msg, '\n')
+	}
+	b := make([]byte, 0, len(msg)+8)
+	b = strconv.AppendInt(b, int64(len(msg)), 10)
+	b = append(b, ' ')
+	return append(b, msg...)
+}
@@ -0,0 +1,353 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package syslog
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/outputs"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+)
+
+func TestSyslogTCPOctetCounting(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			size, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(size))
+			if err != nil {
+				return
+			}
+			b := make([]byte, n)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return
+			}
+			msgs <- string(b)
+		}
+	}()
+
+	s, err := newSyslog(Config{
+		Network:    "tcp",
+		Address:    l.Addr().String(),
+		Format:     RFC5424,
+		Framing:    OctetCounting,
+		Facility:   "local0",
+		Severity:   "info",
+		AppName:    "fibratus",
+		Timeout:    time.Second,
+		Serializer: outputs.CEF,
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	require.NoError(t, s.Publish(getBatch()))
+
+	for i := 0; i < 2; i++ {
+		select {
+		case msg := <-msgs:
+			assert.True(t, strings.HasPrefix(msg, "<134>1 "), msg)
+			assert.Contains(t, msg, " archrabbit fibratus 859 CreateFile - CEF:0|rabbitstack|fibratus|")
+			assert.Contains(t, msg, "|CreateFile|Creates or opens a new file|3|rt=")
+			assert.Contains(t, msg, "sproc=firefox.exe")
+			assert.Contains(t, msg, `suser=SYSTEM`)
+			assert.Contains(t, msg, `sntdom=NT AUTHORITY`)
+		case <-time.After(time.Second * 5):
+			t.Fatal("timed out waiting for syslog messages")
+		}
+	}
+}
+
+func TestSyslogTLSWithoutClientCert(t *testing.T) {
+	l, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{serverCert(t)}})
+	require.NoError(t, err)
+	defer l.Close()
+
+	msgs := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				line, err := bufio.NewReader(conn).ReadString('\n')
+				if err != nil {
+					return
+				}
+				msgs <- line
+			}(conn)
+		}
+	}()
+
+	// neither client certificate nor CA is given
+	s, err := newSyslog(Config{
+		Network:    "tls",
+		Address:    l.Addr().String(),
+		Format:     RFC5424,
+		Framing:    NonTransparent,
+		Facility:   "local0",
+		Severity:   "info",
+		AppName:    "fibratus",
+		Timeout:    time.Second,
+		Serializer: outputs.JSON,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, s.tlsConfig)
+	assert.Equal(t, "127.0.0.1", s.tlsConfig.ServerName)
+	assert.False(t, s.tlsConfig.InsecureSkipVerify)
+	// the self-signed server certificate isn't trusted
+	require.Error(t, s.Connect())
+
+	s, err = newSyslog(Config{
+		Network:    "tls",
+		Address:    l.Addr().String(),
+		Format:     RFC5424,
+		Framing:    NonTransparent,
+		Facility:   "local0",
+		Severity:   "info",
+		AppName:    "fibratus",
+		Timeout:    time.Second,
+		Serializer: outputs.JSON,
+		TLSConfig:  outputs.TLSConfig{TLSInsecureSkipVerify: true},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	require.NoError(t, s.Publish(kevent.NewBatch(getEvent(1))))
+
+	select {
+	case msg := <-msgs:
+		assert.True(t, strings.HasPrefix(msg, "<134>1 "), msg)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for syslog message")
+	}
+}
+
+func TestUnknownFields(t *testing.T) {
+	_, err := newSyslog(Config{
+		Network:    "udp",
+		Address:    "127.0.0.1:514",
+		Format:     RFC5424,
+		Framing:    OctetCounting,
+		Facility:   "local0",
+		Severity:   "info",
+		Serializer: outputs.CEF,
+		Fields: map[string]string{
+			"ps.parent.name": "sproc",
+			"ps.nme":         "dproc",
+		},
+	})
+	require.EqualError(t, err, `unknown "ps.nme" field in syslog fields`)
+}
+
+// serverCert generates the self-signed server certificate.
+func serverCert(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestSyslogUDPLEEF(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	s, err := newSyslog(Config{
+		Network:    "udp",
+		Address:    conn.LocalAddr().String(),
+		Format:     RFC3164,
+		Framing:    OctetCounting,
+		Facility:   "security",
+		Severity:   "notice",
+		AppName:    "fibratus",
+		Timeout:    time.Second,
+		Serializer: outputs.LEEF,
+		Fields: map[string]string{
+			"kevt.arg[file_path]": "filePath",
+			"ps.cmdline":          "cmd",
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	require.NoError(t, s.Publish(kevent.NewBatch(getEvent(1))))
+
+	b := make([]byte, 4096)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second*5)))
+	n, _, err := conn.ReadFrom(b)
+	require.NoError(t, err)
+	msg := string(b[:n])
+
+	assert.True(t, strings.HasPrefix(msg, "<109>"), msg)
+	assert.Contains(t, msg, " archrabbit fibratus[859]: LEEF:1.0|rabbitstack|fibratus|")
+	assert.Contains(t, msg, "|CreateFile|devTime=")
+	assert.Contains(t, msg, "\tfilePath=C:\\Windows\\system32\\user32.dll")
+	assert.Contains(t, msg, "\tcmd=firefox.exe -contentproc")
+	assert.NotContains(t, msg, "usrName")
+}
+
+func TestSyslogReconnect(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+
+	s, err := newSyslog(Config{
+		Network:    "tcp",
+		Address:    addr,
+		Format:     RFC5424,
+		Framing:    NonTransparent,
+		Facility:   "local0",
+		Severity:   "info",
+		Timeout:    time.Second,
+		MaxBackoff: time.Second,
+		Serializer: outputs.JSON,
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Connect())
+	defer s.Close()
+
+	// bring down the receiver and wait for the write to fail
+	c, err := l.Accept()
+	require.NoError(t, err)
+	c.Close()
+	l.Close()
+
+	var perr error
+	for i := 0; i < 10 && perr == nil; i++ {
+		perr = s.Publish(getBatch())
+		time.Sleep(time.Millisecond * 50)
+	}
+	require.Error(t, perr)
+	// the next reconnection attempt is postponed by the backoff
+	assert.Equal(t, errDisconnected, s.Publish(getBatch()))
+
+	l, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer l.Close()
+
+	lines := make(chan string, 2)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		scanner := bufio.NewScanner(conn)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+
+	require.Eventually(t, func() bool { return s.Publish(getBatch()) == nil }, time.Second*5, time.Millisecond*100)
+
+	select {
+	case line := <-lines:
+		assert.True(t, strings.HasPrefix(line, "<134>1 "), line)
+		assert.Contains(t, line, `"name": "CreateFile"`)
+	case <-time.After(time.Second * 5):
+		t.Fatal("timed out waiting for syslog messages")
+	}
+}
+
+func TestCEFEscaping(t *testing.T) {
+	assert.Equal(t, `a\|b\\c`, escapeCEFHeader(`a|b\c`))
+	assert.Equal(t, `C:\\Windows a\=b\nc`, escapeCEFExtension("C:\\Windows a=b\nc"))
+	assert.Equal(t, "a b c", escapeLEEFAttribute("a\tb\nc"))
+}
+
+func getBatch() *kevent.Batch {
+	return kevent.NewBatch(getEvent(2), getEvent(3))
+}
+
+func getEvent(seq uint64) *kevent.Kevent {
+	return &kevent.Kevent{
+		Type:        ktypes.CreateFile,
+		Tid:         2484,
+		PID:         859,
+		CPU:         1,
+		Seq:         seq,
+		Name:        "CreateFile",
+		Timestamp:   time.Now(),
+		Category:    ktypes.File,
+		Host:        "archrabbit",
+		Description: "Creates or opens a new file",
+		Kparams: kevent.Kparams{
+			kparams.FilePath:      {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: "C:\\Windows\\system32\\user32.dll"},
+			kparams.FileOperation: {Name: kparams.FileOperation, Type: kparams.AnsiString, Value: "open"},
+		},
+		PS: &pstypes.PS{
+			PID:      859,
+			Ppid:     6304,
+			Name:     "firefox.exe",
+			Exe:      `C:\Program Files\Mozilla Firefox\firefox.exe`,
+			Cmdline:  `firefox.exe -contentproc --channel="6304.3.1055809391\1014207667"`,
+			SID:      "S-1-5-18",
+			Username: "SYSTEM",
+			Domain:   "NT AUTHORITY",
+		},
+	}
+}
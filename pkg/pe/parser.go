@@ -30,7 +30,6 @@ import (
 	peparser "github.com/saferwall/pe"
 	peparserlog "github.com/saferwall/pe/log"
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/sys/windows"
 	"os"
 	"path/filepath"
 	"strings"
@@ -185,24 +184,6 @@ func ParseBytes(data []byte, opts ...Option) (*PE, error) {
 	return parse("", data, opts...)
 }
 
-// ParseMem parses the in-memory layout of the PE header for the
-// specified process and base address. If change protection parameter
-// is set to true, this method will attempt to change region protection
-// if the region is marked as inaccessible.
-func ParseMem(pid uint32, base uintptr, changeProtection bool, opts ...Option) (*PE, error) {
-	access := windows.PROCESS_VM_READ | windows.PROCESS_QUERY_INFORMATION
-	if changeProtection {
-		access |= windows.PROCESS_VM_OPERATION
-	}
-	process, err := windows.OpenProcess(uint32(access), false, pid)
-	if err != nil {
-		return nil, err
-	}
-	defer windows.Close(process)
-	area := va.ReadArea(process, base, MaxHeaderSize, MinHeaderSize, changeProtection)
-	return ParseBytes(area, opts...)
-}
-
 func newParserOpts(opts opts) *peparser.Options {
 	return &peparser.Options{
 		DisableCertValidation:     true,
@@ -0,0 +1,42 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pe
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/util/va"
+	"golang.org/x/sys/windows"
+)
+
+// ParseMem parses the in-memory layout of the PE header for the
+// specified process and base address. If change protection parameter
+// is set to true, this method will attempt to change region protection
+// if the region is marked as inaccessible.
+func ParseMem(pid uint32, base uintptr, changeProtection bool, opts ...Option) (*PE, error) {
+	access := windows.PROCESS_VM_READ | windows.PROCESS_QUERY_INFORMATION
+	if changeProtection {
+		access |= windows.PROCESS_VM_OPERATION
+	}
+	process, err := windows.OpenProcess(uint32(access), false, pid)
+	if err != nil {
+		return nil, err
+	}
+	defer windows.Close(process)
+	area := va.ReadArea(process, base, MaxHeaderSize, MinHeaderSize, changeProtection)
+	return ParseBytes(area, opts...)
+}
@@ -0,0 +1,38 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pe
+
+import "errors"
+
+// errParseMemUnsupported is returned when the in-memory PE
+// is requested on an operating system other than Windows
+var errParseMemUnsupported = errors.New("parsing the PE from process memory is only supported on Windows")
+
+// ParseMem returns an error as the process address
+// space can only be read on Windows.
+func ParseMem(pid uint32, base uintptr, changeProtection bool, opts ...Option) (*PE, error) {
+	return nil, errParseMemUnsupported
+}
+
+// VerifySignature leaves the PE untrusted because the embedded
+// and catalog signatures can only be verified by the Windows
+// trust provider.
+func (pe *PE) VerifySignature() {}
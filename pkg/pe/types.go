@@ -1,6 +1,3 @@
-//go:build windows
-// +build windows
-
 /*
  * Copyright 2019-2020 by Nedim Sabic Sabic
  * https://www.fibratus.io
@@ -25,7 +22,6 @@ import (
 	"fmt"
 	"github.com/rabbitstack/fibratus/pkg/sys"
 	peparser "github.com/saferwall/pe"
-	"runtime"
 	"sync"
 	"time"
 )
@@ -113,35 +109,6 @@ type PE struct {
 	once     sync.Once
 }
 
-// VerifySignature checks if the embedded or catalog PE signature is trusted.
-func (pe *PE) VerifySignature() {
-	pe.once.Do(func() {
-		if sys.IsWintrustFound() {
-			runtime.LockOSThread()
-			trust := sys.NewWintrustData(sys.WtdChoiceFile)
-			defer trust.Close()
-			defer runtime.UnlockOSThread()
-			pe.IsTrusted = trust.VerifyFile(pe.filename)
-			// maybe the PE is catalog signed?
-			if !pe.IsSigned {
-				catalog := sys.NewCatalog()
-				err := catalog.Open(pe.filename)
-				defer catalog.Close()
-				if err != nil {
-					return
-				}
-				pe.IsSigned = catalog.IsCatalogSigned()
-				if pe.IsSigned {
-					pe.IsTrusted = catalog.Verify(pe.filename)
-				}
-				if pe.IsSigned && pe.IsTrusted {
-					pe.Cert, _ = catalog.ParseCertificate()
-				}
-			}
-		}
-	})
-}
-
 // String returns the string representation of the PE metadata.
 func (pe *PE) String() string {
 	return fmt.Sprintf(`
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pe
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/sys"
+	"runtime"
+)
+
+// VerifySignature checks if the embedded or catalog PE signature is trusted.
+func (pe *PE) VerifySignature() {
+	pe.once.Do(func() {
+		if sys.IsWintrustFound() {
+			runtime.LockOSThread()
+			trust := sys.NewWintrustData(sys.WtdChoiceFile)
+			defer trust.Close()
+			defer runtime.UnlockOSThread()
+			pe.IsTrusted = trust.VerifyFile(pe.filename)
+			// maybe the PE is catalog signed?
+			if !pe.IsSigned {
+				catalog := sys.NewCatalog()
+				err := catalog.Open(pe.filename)
+				defer catalog.Close()
+				if err != nil {
+					return
+				}
+				pe.IsSigned = catalog.IsCatalogSigned()
+				if pe.IsSigned {
+					pe.IsTrusted = catalog.Verify(pe.filename)
+				}
+				if pe.IsSigned && pe.IsTrusted {
+					pe.Cert, _ = catalog.ParseCertificate()
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,99 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ps
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"sync"
+)
+
+// snapshotter keeps the state of processes restored from the capture
+// file. The state is solely derived from captured events and is never
+// queried from the operating system, since the processes live on the
+// host where the capture was taken.
+type snapshotter struct {
+	mu    sync.RWMutex
+	procs map[uint32]*pstypes.PS
+}
+
+// NewSnapshotterFromKcap restores the snapshotter state from the kcap file.
+func NewSnapshotterFromKcap() Snapshotter {
+	return &snapshotter{procs: make(map[uint32]*pstypes.PS)}
+}
+
+// Write appends the process state from the captured event.
+func (s *snapshotter) Write(e *kevent.Kevent) error {
+	return s.WriteFromKcap(e)
+}
+
+func (s *snapshotter) Close() error { return nil }
+
+func (s *snapshotter) newProcState(pid, ppid uint32, e *kevent.Kevent) (*pstypes.PS, error) {
+	proc := pstypes.New(
+		pid,
+		ppid,
+		e.GetParamAsString(kparams.ProcessName),
+		e.GetParamAsString(kparams.Cmdline),
+		e.GetParamAsString(kparams.Exe),
+		e.GetParamAsString(kparams.UserSID),
+		e.Kparams.MustGetUint32(kparams.SessionID),
+	)
+
+	proc.Parent = s.procs[ppid]
+	proc.StartTime, _ = e.Kparams.GetTime(kparams.StartTime)
+	proc.IsWOW64 = (e.Kparams.MustGetUint32(kparams.ProcessFlags) & kevent.PsWOW64) != 0
+	proc.IsPackaged = (e.Kparams.MustGetUint32(kparams.ProcessFlags) & kevent.PsPackaged) != 0
+	proc.IsProtected = (e.Kparams.MustGetUint32(kparams.ProcessFlags) & kevent.PsProtected) != 0
+	proc.Domain = e.GetParamAsString(kparams.Domain)
+	proc.Username = e.GetParamAsString(kparams.Username)
+
+	return proc, nil
+}
+
+func (s *snapshotter) Remove(e *kevent.Kevent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	pid, err := e.Kparams.GetPid()
+	if err != nil {
+		return err
+	}
+	delete(s.procs, pid)
+	processCount.Add(-1)
+	// reset parent if it died after spawning a process
+	for procID, proc := range s.procs {
+		if proc.Ppid == pid {
+			s.procs[procID].Parent = nil
+		}
+	}
+	return nil
+}
+
+// Find retrieves the process state from the snapshot. Processes
+// missing in the snapshot are never looked up in the operating
+// system.
+func (s *snapshotter) Find(pid uint32) (bool, *pstypes.PS) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ps, ok := s.procs[pid]
+	return ok, ps
+}
@@ -21,7 +21,6 @@ package ps
 import (
 	"expvar"
 	"github.com/rabbitstack/fibratus/pkg/sys"
-	"github.com/rabbitstack/fibratus/pkg/util/va"
 	"golang.org/x/sys/windows"
 	"path/filepath"
 	"strconv"
@@ -33,25 +32,17 @@ import (
 	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
 	"github.com/rabbitstack/fibratus/pkg/kevent"
 	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
-	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
 	"github.com/rabbitstack/fibratus/pkg/pe"
 	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
 	log "github.com/sirupsen/logrus"
 )
 
-// SystemPID designates the pid of the system process that acts as the container for system threads
-const SystemPID uint32 = 4
-
 var (
 	// reapPeriod specifies the interval for triggering the housekeeping of dead processes
 	reapPeriod = time.Minute * 2
 
 	processLookupFailureCount = expvar.NewMap("process.lookup.failure.count")
 	reapedProcesses           = expvar.NewInt("process.reaped")
-	processCount              = expvar.NewInt("process.count")
-	threadCount               = expvar.NewInt("process.thread.count")
-	moduleCount               = expvar.NewInt("process.module.count")
-	mmapCount                 = expvar.NewInt("process.mmap.count")
 	pebReadErrors             = expvar.NewInt("process.peb.read.errors")
 )
 
@@ -104,44 +95,6 @@ func NewSnapshotterFromKcap(hsnap handle.Snapshotter, config *config.Config) Sna
 	return s
 }
 
-func (s *snapshotter) WriteFromKcap(e *kevent.Kevent) error {
-	switch e.Type {
-	case ktypes.CreateProcess, ktypes.ProcessRundown:
-		s.mu.Lock()
-		defer s.mu.Unlock()
-		proc := e.PS
-		if proc == nil {
-			return nil
-		}
-		pid, err := e.Kparams.GetPid()
-		if err != nil {
-			return err
-		}
-		ppid, err := e.Kparams.GetPpid()
-		if err != nil {
-			return err
-		}
-		if proc.PID == proc.Ppid ||
-			(e.IsProcessRundown() && pid == sys.InvalidProcessID) {
-			return nil
-		}
-		if e.IsProcessRundown() {
-			proc.Parent = s.procs[ppid]
-		} else {
-			proc, err = s.newProcState(pid, ppid, e)
-			if err != nil {
-				return err
-			}
-		}
-		s.procs[pid] = proc
-	case ktypes.CreateThread, ktypes.ThreadRundown:
-		return s.AddThread(e)
-	case ktypes.LoadImage, ktypes.ImageRundown:
-		return s.AddModule(e)
-	}
-	return nil
-}
-
 func (s *snapshotter) Write(e *kevent.Kevent) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -171,143 +124,6 @@ func (s *snapshotter) Write(e *kevent.Kevent) error {
 	return err
 }
 
-func (s *snapshotter) AddThread(e *kevent.Kevent) error {
-	pid, err := e.Kparams.GetPid()
-	if err != nil {
-		return err
-	}
-	threadCount.Add(1)
-
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	proc, ok := s.procs[pid]
-	if !ok {
-		return nil
-	}
-
-	thread := pstypes.Thread{}
-	thread.Tid, _ = e.Kparams.GetTid()
-	thread.UstackBase = e.Kparams.TryGetAddress(kparams.UstackBase)
-	thread.UstackLimit = e.Kparams.TryGetAddress(kparams.UstackLimit)
-	thread.KstackBase = e.Kparams.TryGetAddress(kparams.KstackBase)
-	thread.KstackLimit = e.Kparams.TryGetAddress(kparams.KstackLimit)
-	thread.IOPrio, _ = e.Kparams.GetUint8(kparams.IOPrio)
-	thread.BasePrio, _ = e.Kparams.GetUint8(kparams.BasePrio)
-	thread.PagePrio, _ = e.Kparams.GetUint8(kparams.PagePrio)
-	thread.StartAddress = e.Kparams.TryGetAddress(kparams.StartAddress)
-
-	proc.AddThread(thread)
-
-	return nil
-}
-
-func (s *snapshotter) AddModule(e *kevent.Kevent) error {
-	pid, err := e.Kparams.GetPid()
-	if err != nil {
-		return err
-	}
-	moduleCount.Add(1)
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	if pid == 0 && e.IsImageRundown() {
-		// assume system process if pid is zero
-		pid = SystemPID
-	}
-	proc, ok := s.procs[pid]
-	if !ok {
-		return nil
-	}
-
-	module := pstypes.Module{}
-	module.Size, _ = e.Kparams.GetUint64(kparams.ImageSize)
-	module.Checksum, _ = e.Kparams.GetUint32(kparams.ImageCheckSum)
-	module.Name = e.GetParamAsString(kparams.ImagePath)
-	module.BaseAddress = e.Kparams.TryGetAddress(kparams.ImageBase)
-	module.DefaultBaseAddress = e.Kparams.TryGetAddress(kparams.ImageDefaultBase)
-	module.SignatureLevel, _ = e.Kparams.GetUint32(kparams.ImageSignatureLevel)
-	module.SignatureType, _ = e.Kparams.GetUint32(kparams.ImageSignatureType)
-
-	if module.IsExecutable() && len(proc.Exe) < len(module.Name) {
-		proc.Exe = module.Name
-	}
-
-	proc.AddModule(module)
-
-	return nil
-}
-
-func (s *snapshotter) RemoveThread(pid uint32, tid uint32) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	proc, ok := s.procs[pid]
-	if !ok {
-		return nil
-	}
-	proc.RemoveThread(tid)
-	threadCount.Add(-1)
-	return nil
-}
-
-func (s *snapshotter) RemoveModule(pid uint32, addr va.Address) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	proc, ok := s.procs[pid]
-	if !ok {
-		return nil
-	}
-	proc.RemoveModule(addr)
-	moduleCount.Add(-1)
-	return nil
-}
-
-func (s *snapshotter) FindModule(addr va.Address) (bool, *pstypes.Module) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	for _, proc := range s.procs {
-		for _, mod := range proc.Modules {
-			if mod.BaseAddress == addr {
-				return true, &mod
-			}
-		}
-	}
-	return false, nil
-}
-
-func (s *snapshotter) AddMmap(e *kevent.Kevent) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	proc, ok := s.procs[e.PID]
-	if !ok {
-		return nil
-	}
-
-	mmapCount.Add(1)
-
-	mmap := pstypes.Mmap{}
-	mmap.File = e.GetParamAsString(kparams.FilePath)
-	mmap.BaseAddress = e.Kparams.TryGetAddress(kparams.FileViewBase)
-	mmap.Size, _ = e.Kparams.GetUint64(kparams.FileViewSize)
-	mmap.Protection, _ = e.Kparams.GetUint32(kparams.MemProtect)
-	mmap.Type = e.GetParamAsString(kparams.FileViewSectionType)
-
-	proc.AddMmap(mmap)
-
-	return nil
-}
-
-func (s *snapshotter) RemoveMmap(pid uint32, addr va.Address) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	proc, ok := s.procs[pid]
-	if !ok {
-		return nil
-	}
-	mmapCount.Add(-1)
-	proc.RemoveMmap(addr)
-	return nil
-}
-
 func (s *snapshotter) Close() error {
 	s.quit <- struct{}{}
 	return nil
@@ -423,22 +239,6 @@ func (s *snapshotter) Remove(e *kevent.Kevent) error {
 	return nil
 }
 
-func (s *snapshotter) Put(proc *pstypes.PS) {
-	if proc != nil {
-		s.mu.Lock()
-		defer s.mu.Unlock()
-		s.procs[proc.PID] = proc
-	}
-}
-
-func (s *snapshotter) FindAndPut(pid uint32) *pstypes.PS {
-	ok, proc := s.Find(pid)
-	if !ok {
-		s.Put(proc)
-	}
-	return proc
-}
-
 func (s *snapshotter) Find(pid uint32) (bool, *pstypes.PS) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
@@ -568,12 +368,6 @@ func (s *snapshotter) Find(pid uint32) (bool, *pstypes.PS) {
 	return false, proc
 }
 
-func (s *snapshotter) Size() uint32 {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-	return uint32(len(s.procs))
-}
-
 // gcDeadProcesses periodically scans the map of the snapshot's processes and removes
 // any terminated processes from it. This guarantees that any leftovers are cleaned-up
 // in case we miss process' terminate events.
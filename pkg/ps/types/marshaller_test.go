@@ -20,7 +20,6 @@ package types
 
 import (
 	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
-	"golang.org/x/sys/windows"
 
 	"github.com/rabbitstack/fibratus/pkg/kcap/section"
 	kcapver "github.com/rabbitstack/fibratus/pkg/kcap/version"
@@ -48,14 +47,14 @@ func TestPSMarshaler(t *testing.T) {
 		uuid:      123456789,
 		StartTime: n,
 		Handles: []htypes.Handle{
-			{Num: windows.Handle(0xffffd105e9baaf70),
+			{Num: htypes.ID(0xffffd105e9baaf70),
 				Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 				Type:   "Key",
 				Object: 777488883434455544,
 				Pid:    uint32(1023),
 			},
 			{
-				Num:  windows.Handle(0xffffd105e9adaf70),
+				Num:  htypes.ID(0xffffd105e9adaf70),
 				Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 				Type: "ALPC Port",
 				Pid:  uint32(1023),
@@ -67,7 +66,7 @@ func TestPSMarshaler(t *testing.T) {
 				Object: 457488883434455544,
 			},
 			{
-				Num:  windows.Handle(0xeaffd105e9adaf30),
+				Num:  htypes.ID(0xeaffd105e9adaf30),
 				Name: `C:\Users\bunny`,
 				Type: "File",
 				Pid:  uint32(1023),
@@ -145,14 +144,14 @@ func TestPSMarshalerWithPE(t *testing.T) {
 		StartTime: n,
 		PE:        p,
 		Handles: []htypes.Handle{
-			{Num: windows.Handle(0xffffd105e9baaf70),
+			{Num: htypes.ID(0xffffd105e9baaf70),
 				Name:   `\REGISTRY\MACHINE\SYSTEM\ControlSet001\Services\Tcpip\Parameters\Interfaces\{b677c565-6ca5-45d3-b618-736b4e09b036}`,
 				Type:   "Key",
 				Object: 777488883434455544,
 				Pid:    uint32(1023),
 			},
 			{
-				Num:  windows.Handle(0xffffd105e9adaf70),
+				Num:  htypes.ID(0xffffd105e9adaf70),
 				Name: `\RPC Control\OLEA61B27E13E028C4EA6C286932E80`,
 				Type: "ALPC Port",
 				Pid:  uint32(1023),
@@ -164,7 +163,7 @@ func TestPSMarshalerWithPE(t *testing.T) {
 				Object: 457488883434455544,
 			},
 			{
-				Num:  windows.Handle(0xeaffd105e9adaf30),
+				Num:  htypes.ID(0xeaffd105e9adaf30),
 				Name: `C:\Users\bunny`,
 				Type: "File",
 				Pid:  uint32(1023),
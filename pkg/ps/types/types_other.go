@@ -0,0 +1,58 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	htypes "github.com/rabbitstack/fibratus/pkg/handle/types"
+	"github.com/rabbitstack/fibratus/pkg/util/cmdline"
+)
+
+// New produces a new process state. Unlike on Windows, the
+// account name and the domain are not resolved from the SID,
+// since the SID belongs to the host where the event originated.
+func New(pid, ppid uint32, name, cmndline, exe, sid string, sessionID uint32) *PS {
+	return &PS{
+		PID:       pid,
+		Ppid:      ppid,
+		Name:      name,
+		Cmdline:   cmndline,
+		Exe:       exe,
+		Args:      cmdline.Split(cmndline),
+		SID:       sid,
+		SessionID: sessionID,
+		Threads:   make(map[uint32]Thread),
+		Modules:   make([]Module, 0),
+		Handles:   make([]htypes.Handle, 0),
+		Mmaps:     make([]Mmap, 0),
+	}
+}
+
+// UUID returns the unique process identifier. Processes restored
+// from the capture file carry the identifier assigned on the host
+// where the capture was taken. Otherwise, the identifier is derived
+// from the process id and the process start time.
+func (ps *PS) UUID() uint64 {
+	if ps.uuid != 0 {
+		return ps.uuid
+	}
+	ps.uuid = uint64(ps.PID) | uint64(ps.StartTime.UnixNano())
+	return ps.uuid
+}
@@ -76,6 +76,27 @@ func TestPSArgs(t *testing.T) {
 	require.Equal(t, "/prefetch:7", ps.Args[2])
 }
 
+func TestCopy(t *testing.T) {
+	ps := &PS{
+		PID:      4532,
+		Name:     "cmd.exe",
+		Cmdline:  "cmd.exe /c whoami",
+		Username: "admin",
+		Threads:  map[uint32]Thread{3453: {Tid: 3453}},
+		Parent:   &PS{PID: 1234, Name: "explorer.exe"},
+		uuid:     2345,
+	}
+	c := ps.Copy()
+	c.Username = "SYSTEM"
+	delete(c.Threads, 3453)
+
+	assert.Equal(t, "admin", ps.Username)
+	assert.Contains(t, ps.Threads, uint32(3453))
+	assert.Equal(t, "cmd.exe /c whoami", c.Cmdline)
+	assert.Equal(t, ps.Parent, c.Parent)
+	assert.Equal(t, uint64(2345), c.UUID())
+}
+
 func TestUUID(t *testing.T) {
 	now := time.Now()
 	// try to obtain the UUID on a system process
@@ -0,0 +1,38 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package action
+
+import (
+	"errors"
+	"net"
+)
+
+// errActionUnsupported is returned by response actions that can only
+// be carried out on the Windows host where the events originate.
+var errActionUnsupported = errors.New("action is only supported on Windows")
+
+// Kill is not supported outside Windows because the
+// processes exist on the host where events originate.
+func Kill(pids []uint32) error { return errActionUnsupported }
+
+// Isolate is not supported outside Windows because host
+// isolation relies on the Windows Filtering Platform.
+func Isolate(whitelist []net.IP) error { return errActionUnsupported }
@@ -27,7 +27,7 @@ import (
 	"strings"
 )
 
-// Alert sends the rule alert via all configured alert senders.
+// Alert sends the rule alert via alert senders the alert is routed to.
 func Alert(ctx *config.ActionContext, title string, text string, severity string, tags []string) error {
 	var b strings.Builder
 	for _, evt := range ctx.Events {
@@ -36,24 +36,38 @@ func Alert(ctx *config.ActionContext, title string, text string, severity string
 	}
 	log.Infof("sending alert: [%s]. Text: %s Event(s): %s", title, text, b.String())
 
-	senders := alertsender.FindAll()
-	if len(senders) == 0 {
+	if len(alertsender.FindAll()) == 0 {
 		return fmt.Errorf("no alertsenders registered. Alert won't be sent")
 	}
 
-	for _, sender := range senders {
-		alert := alertsender.NewAlert(
-			title,
-			text,
-			tags,
-			alertsender.ParseSeverityFromString(severity),
-		)
+	alert := alertsender.NewAlert(
+		title,
+		text,
+		tags,
+		alertsender.ParseSeverityFromString(severity),
+	)
+
+	alert.ID = ctx.Filter.ID
+	alert.Events = ctx.Events
+	alert.Labels = ctx.Filter.Labels
+	alert.Description = ctx.Filter.Description
+	alert.Suppressed = ctx.Suppressed
+	if ctx.Suppressed > 0 {
+		alert.Text += fmt.Sprintf("\n\n%d similar alert(s) suppressed since the last notification", ctx.Suppressed)
+	}
+	alert.Capture = ctx.Capture
+	if ctx.Capture != "" {
+		alert.Text += fmt.Sprintf("\n\nEvents leading up to the alert were captured in %s", ctx.Capture)
+	}
 
-		alert.ID = ctx.Filter.ID
-		alert.Events = ctx.Events
-		alert.Labels = ctx.Filter.Labels
-		alert.Description = ctx.Filter.Description
+	senders := alertsender.FindRouted(alert)
+	if len(senders) == 0 {
+		log.Debugf("alert [%s] is not routed to any alertsender", title)
+		return nil
+	}
 
+	for _, sender := range senders {
+		alert := alert
 		// strip markdown if not supported by the sender
 		if !sender.SupportsMarkdown() {
 			alert.Text = markdown.Strip(alert.Text)
@@ -0,0 +1,46 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import "time"
+
+// clock is the time source of the sequence state. The max span
+// deadlines are scheduled through the clock, so the rule test
+// runner can drive them by the virtual time of test events.
+type clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// AfterFunc calls the function in its own goroutine
+	// or synchronously once the duration elapses.
+	AfterFunc(d time.Duration, f func()) timer
+}
+
+// timer is the pending function call scheduled by the clock.
+type timer interface {
+	// Stop prevents the timer from firing. It returns
+	// false if the timer already fired or was stopped.
+	Stop() bool
+}
+
+// systemClock is the clock backed by the wall time.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) timer { return time.AfterFunc(d, f) }
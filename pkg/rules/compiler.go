@@ -55,26 +55,43 @@ func newCompiler(psnap ps.Snapshotter, config *config.Config) *compiler {
 	return &compiler{psnap: psnap, config: config}
 }
 
-func (c *compiler) compile() (map[*config.FilterConfig]filter.Filter, *config.RulesCompileResult, error) {
-	if err := c.config.Filters.LoadMacros(); err != nil {
-		return nil, nil, err
+// compile loads macros and rules into the new filters config and compiles
+// them. The active filters config is left intact, so it can be swapped with
+// the new config once the ruleset is built.
+func (c *compiler) compile() (*config.Filters, map[*config.FilterConfig]filter.Filter, *config.RulesCompileResult, error) {
+	fconfig := *c.config.Filters
+	if err := fconfig.LoadMacros(); err != nil {
+		return nil, nil, nil, err
 	}
-	if err := c.config.Filters.LoadFilters(); err != nil {
-		return nil, nil, err
+	if err := fconfig.LoadFilters(); err != nil {
+		return nil, nil, nil, err
 	}
+	cfg := *c.config
+	cfg.Filters = &fconfig
+	filters, rs, err := c.compileRules(&cfg, cfg.GetFilters())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return &fconfig, filters, rs, nil
+}
+
+// compileFilters compiles the conditions of the given rules
+// without reloading them from the rule and macro files.
+func (c *compiler) compileFilters(rules []*config.FilterConfig) (map[*config.FilterConfig]filter.Filter, *config.RulesCompileResult, error) {
+	return c.compileRules(c.config, rules)
+}
 
+func (c *compiler) compileRules(cfg *config.Config, rules []*config.FilterConfig) (map[*config.FilterConfig]filter.Filter, *config.RulesCompileResult, error) {
 	filters := make(map[*config.FilterConfig]filter.Filter)
 
-	for _, f := range c.config.GetFilters() {
+	for _, f := range rules {
 		if f.IsDisabled() {
 			log.Warnf("[%s] rule is disabled", f.Name)
 			continue
 		}
 
-		filtersCount.Add(1)
-
 		// compile the filter
-		fltr := filter.New(f.Condition, c.config, filter.WithPSnapshotter(c.psnap))
+		fltr := filter.New(f.Condition, cfg, filter.WithPSnapshotter(c.psnap))
 		err := fltr.Compile()
 		if err != nil {
 			return nil, nil, ErrInvalidFilter(f.Name, err)
@@ -104,6 +121,8 @@ func (c *compiler) compile() (map[*config.FilterConfig]filter.Filter, *config.Ru
 		filters[f] = fltr
 	}
 
+	filtersCount.Set(int64(len(filters)))
+
 	if len(filters) == 0 {
 		return filters, nil, nil
 	}
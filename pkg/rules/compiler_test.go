@@ -29,7 +29,7 @@ import (
 
 func TestCompile(t *testing.T) {
 	c := newCompiler(new(ps.SnapshotterMock), newConfig("_fixtures/default/*.yml"))
-	filters, rs, err := c.compile()
+	_, filters, rs, err := c.compile()
 	require.NoError(t, err)
 	require.NotNil(t, rs)
 	require.Len(t, filters, 6)
@@ -55,12 +55,14 @@ func TestCompileMinEngineVersion(t *testing.T) {
 		{"_fixtures/min_engine_version/fail/*.yml", "2.0.0", `rule "accept events where source port = 44123" needs engine version [2.2.0] but current version is [2.0.0]`},
 		{"_fixtures/min_engine_version/ok/*.yml", "2.0.0", ""},
 	}
+	// other tests run with the dev version
+	t.Cleanup(func() { version.Set("") })
 
 	for _, tt := range tests {
 		t.Run(tt.rules, func(t *testing.T) {
 			c := newCompiler(new(ps.SnapshotterMock), newConfig(tt.rules))
 			version.Set(tt.ver)
-			_, _, err := c.compile()
+			_, _, _, err := c.compile()
 			if err != nil && tt.e == "" {
 				require.Error(t, err)
 			}
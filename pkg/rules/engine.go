@@ -21,6 +21,7 @@ package rules
 import (
 	"expvar"
 	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
 	"github.com/rabbitstack/fibratus/pkg/config"
 	"github.com/rabbitstack/fibratus/pkg/filter"
 	"github.com/rabbitstack/fibratus/pkg/filter/fields"
@@ -31,16 +32,24 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/util/hashers"
 	log "github.com/sirupsen/logrus"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// Recorder persists the events leading up to the rule match
+// in the capture file and returns the path of the capture.
+// The path is empty if the capture dump was skipped.
+type Recorder interface {
+	Dump(name string, ts time.Time) (string, error)
+}
+
 // RuleMatchFunc is rule match function definition. It accepts
 // the filter (rule) config and the group of events that fired
 // the rule
 type RuleMatchFunc func(f *config.FilterConfig, evts ...*kevent.Kevent)
 
 var (
-	// sequenceGcInterval determines how often sequence GC kicks in
+	// sequenceGcInterval determines how often sequence and threshold GC kicks in
 	sequenceGcInterval = time.Minute
 
 	filterMatches = expvar.NewMap("filter.matches")
@@ -54,21 +63,42 @@ var (
 // the collection of compiled filters that are derived
 // from the loaded ruleset.
 type Engine struct {
-	filters compiledFilters
-	config  *config.Config
-	psnap   ps.Snapshotter
+	// rules is the active ruleset. The ruleset
+	// is atomically swapped when rules are reloaded
+	rules  atomic.Pointer[ruleset]
+	rmu    sync.Mutex // serializes ruleset compilation
+	config *config.Config
+	psnap  ps.Snapshotter
+
+	matches []*ruleMatch
+	mmu     sync.Mutex // guards the rule matches slice
 
-	matches   []*ruleMatch
-	mmu       sync.Mutex // guards the rule matches slice
-	sequences []*sequenceState
+	// usedEvents stores the event types required by the
+	// ruleset that was compiled when the engine started
+	usedEvents map[ktypes.Ktype]bool
 
 	scavenger *time.Ticker
+	quit      chan struct{}
+	closeOnce sync.Once
+
+	// clock is the time source for the
+	// max span deadlines of sequences
+	clock clock
 
 	compiler *compiler
 
 	hashCache *hashCache
 
 	matchFunc RuleMatchFunc
+
+	// recorder dumps the flight recorder capture
+	// for rules at or above the minimum severity
+	recorder    Recorder
+	minSeverity alertsender.Severity
+
+	// skipActions disables rule actions and alerts.
+	// Used when evaluating rule test cases
+	skipActions bool
 }
 
 type ruleMatch struct {
@@ -77,10 +107,9 @@ type ruleMatch struct {
 
 // hashCache caches the event type/category FNV hashes
 type hashCache struct {
-	mu             sync.RWMutex
-	types          map[ktypes.Ktype]uint32
-	cats           map[ktypes.Category]uint32
-	lookupCategory bool
+	mu    sync.RWMutex
+	types map[ktypes.Ktype]uint32
+	cats  map[ktypes.Category]uint32
 }
 
 func newHashCache() *hashCache {
@@ -119,33 +148,60 @@ type compiledFilter struct {
 	filter filter.Filter
 	config *config.FilterConfig
 	ss     *sequenceState
+	ts     *thresholdState
 }
 
 type compiledFilters map[uint32][]*compiledFilter
 
+// ruleset contains compiled filters indexed by event
+// type or category hashes along with the state of
+// sequence and threshold rules. The ruleset is never
+// mutated after it is built. Reloading rules produces
+// a new ruleset.
+type ruleset struct {
+	filters    compiledFilters
+	sequences  []*sequenceState
+	thresholds []*thresholdState
+	// suppressors maps the rule name to its alert suppressor
+	suppressors map[string]*suppressor
+	// lookupCategory indicates if any of the filters is indexed by category
+	lookupCategory bool
+	// matchAll indicates if a single event can trigger multiple rules
+	matchAll bool
+}
+
+func newRuleset() *ruleset {
+	return &ruleset{
+		filters:     make(compiledFilters),
+		sequences:   make([]*sequenceState, 0),
+		thresholds:  make([]*thresholdState, 0),
+		suppressors: make(map[string]*suppressor),
+	}
+}
+
 // collect collects all compiled filters for a
 // particular event type or category. If no filters
 // are found, the event is not asserted against the
 // ruleset.
-func (filters compiledFilters) collect(hashCache *hashCache, e *kevent.Kevent) []*compiledFilter {
+func (r *ruleset) collect(hashCache *hashCache, e *kevent.Kevent) []*compiledFilter {
 	h := hashCache.typeHash(e)
 	if h == 0 {
 		h = hashCache.addTypeHash(e)
 	}
 
-	if !hashCache.lookupCategory {
-		return filters[h]
+	if !r.lookupCategory {
+		return r.filters[h]
 	}
 
 	c := hashCache.categoryHash(e)
 	if c == 0 {
 		c = hashCache.addCategoryHash(e)
 	}
-	return append(filters[h], filters[c]...)
+	return append(r.filters[h], r.filters[c]...)
 }
 
-func newCompiledFilter(f filter.Filter, c *config.FilterConfig, ss *sequenceState) *compiledFilter {
-	return &compiledFilter{filter: f, config: c, ss: ss}
+func newCompiledFilter(f filter.Filter, c *config.FilterConfig, ss *sequenceState, ts *thresholdState) *compiledFilter {
+	return &compiledFilter{filter: f, config: c, ss: ss, ts: ts}
 }
 
 // isScoped determines if this filter is scoped, i.e. it has the event name or category
@@ -163,60 +219,162 @@ func (f *compiledFilter) isSequence() bool {
 	return f.ss != nil
 }
 
+func (f *compiledFilter) isThreshold() bool {
+	return f.ts != nil
+}
+
 func (f *compiledFilter) run(e *kevent.Kevent) bool {
 	if f.ss != nil {
 		return f.ss.runSequence(e)
 	}
+	if f.ts != nil {
+		return f.ts.runThreshold(e)
+	}
 	return f.filter.Run(e)
 }
 
 // NewEngine builds a fresh rules engine instance.
 func NewEngine(psnap ps.Snapshotter, config *config.Config) *Engine {
 	e := &Engine{
-		filters:   make(map[uint32][]*compiledFilter),
-		matches:   make([]*ruleMatch, 0),
-		sequences: make([]*sequenceState, 0),
-		psnap:     psnap,
-		config:    config,
-		scavenger: time.NewTicker(sequenceGcInterval),
-		compiler:  newCompiler(psnap, config),
-		hashCache: newHashCache(),
+		matches:    make([]*ruleMatch, 0),
+		psnap:      psnap,
+		config:     config,
+		usedEvents: make(map[ktypes.Ktype]bool),
+		scavenger:  time.NewTicker(sequenceGcInterval),
+		quit:       make(chan struct{}),
+		clock:      systemClock{},
+		compiler:   newCompiler(psnap, config),
+		hashCache:  newHashCache(),
 	}
+	e.rules.Store(newRuleset())
 
-	go e.gcSequences()
+	go e.gc()
 
 	return e
 }
 
-func (e *Engine) gcSequences() {
+func (e *Engine) gc() {
 	for {
-		<-e.scavenger.C
-		for _, seq := range e.sequences {
-			seq.gc()
+		select {
+		case <-e.scavenger.C:
+			rules := e.rules.Load()
+			for _, seq := range rules.sequences {
+				seq.gc()
+			}
+			for _, thresh := range rules.thresholds {
+				thresh.gc()
+			}
+			for _, s := range rules.suppressors {
+				s.gc()
+			}
+		case <-e.quit:
+			return
 		}
 	}
 }
 
+// Close stops the garbage collector of the rule
+// state and disposes the state of all sequences.
+func (e *Engine) Close() {
+	e.closeOnce.Do(func() {
+		e.scavenger.Stop()
+		close(e.quit)
+		for _, ss := range e.rules.Load().sequences {
+			ss.dispose()
+		}
+	})
+}
+
 // Compile loads macros/rules and builds an indexable filter set.
 // For every rule in the ruleset the condition is compiled and
 // converted into a filter. The filter is indexed by either the
 // event name or event category.
 func (e *Engine) Compile() (*config.RulesCompileResult, error) {
-	filters, rs, err := e.compiler.compile()
+	e.rmu.Lock()
+	defer e.rmu.Unlock()
+	fconfig, filters, rs, err := e.compiler.compile()
 	if err != nil {
 		return nil, err
 	}
+	rules, err := e.buildRuleset(filters, e.rules.Load())
+	if err != nil {
+		return nil, err
+	}
+	rules.matchAll = fconfig.MatchAll
+	if rs != nil {
+		for _, typ := range rs.UsedEvents {
+			e.usedEvents[typ] = true
+		}
+	}
+	e.rules.Store(rules)
+	e.config.Filters = fconfig
+
+	return rs, nil
+}
+
+// buildRuleset creates the ruleset from compiled filters. The state
+// of sequences that remained unchanged in respect to the previous
+// ruleset is transferred to the new ruleset.
+func (e *Engine) buildRuleset(filters map[*config.FilterConfig]filter.Filter, prev *ruleset) (*ruleset, error) {
+	rules := newRuleset()
+
+	// index the sequence states of the previous ruleset
+	// by rule and sequence identity
+	sequences := make(map[string]*sequenceState)
+	for _, ss := range prev.sequences {
+		sequences[ss.key] = ss
+	}
 
 	for c, f := range filters {
-		var ss *sequenceState
+		var (
+			ss *sequenceState
+			ts *thresholdState
+		)
 		if f.IsSequence() {
-			ss = newSequenceState(f, c, e.psnap)
+			key := sequenceKey(c, f.GetSequence())
+			if prevSeq, ok := sequences[key]; ok {
+				// keep the partials and the state
+				// machine of the unchanged sequence
+				ss = prevSeq
+				f = ss.filter
+				delete(sequences, key)
+			} else {
+				ss = newSequenceState(f, c, e.psnap)
+				ss.key = key
+				ss.clock = e.clock
+			}
+			if f.GetSequence().HasNegated() {
+				// sequences with negated expressions can match
+				// when the max span deadline is reached without
+				// any incoming event
+				ss.setDeadlineMatchFunc(func(evts ...*kevent.Kevent) {
+					e.onDeadlineMatch(c, evts...)
+				})
+			}
 		}
-		fltr := newCompiledFilter(f, c, ss)
+		if f.IsThreshold() {
+			ts = newThresholdState(f, c)
+		}
+		if c.Suppress != nil {
+			s, err := newSuppressor(c)
+			if err != nil {
+				return nil, err
+			}
+			// keep the suppression state if
+			// the settings remained unchanged
+			if prevSup, ok := prev.suppressors[c.Name]; ok && prevSup.equal(s) {
+				s = prevSup
+			}
+			rules.suppressors[c.Name] = s
+		}
+		fltr := newCompiledFilter(f, c, ss, ts)
 		if ss != nil {
-			// store the sequences in engine
+			// store the sequences in the ruleset
 			// for more convenient tracking
-			e.sequences = append(e.sequences, ss)
+			rules.sequences = append(rules.sequences, ss)
+		}
+		if ts != nil {
+			rules.thresholds = append(rules.thresholds, ts)
 		}
 		if !fltr.isScoped() {
 			log.Warnf("%q rule doesn't have "+
@@ -236,30 +394,40 @@ func (e *Engine) Compile() (*config.RulesCompileResult, error) {
 			for _, v := range values {
 				if name == fields.KevtName || name == fields.KevtCategory {
 					if name == fields.KevtCategory {
-						e.hashCache.lookupCategory = true
+						rules.lookupCategory = true
 					}
 					hash := hashers.FnvUint32([]byte(v))
-					e.filters[hash] = append(e.filters[hash], fltr)
+					rules.filters[hash] = append(rules.filters[hash], fltr)
 				}
 			}
 		}
 	}
 
-	return rs, nil
+	return rules, nil
 }
 
 func (e *Engine) RegisterMatchFunc(fn RuleMatchFunc) {
 	e.matchFunc = fn
 }
 
+// SetRecorder sets the flight recorder that dumps the capture when
+// the rule with the severity equal to or above the configured
+// minimum severity matches.
+func (e *Engine) SetRecorder(r Recorder) {
+	e.recorder = r
+	e.minSeverity = alertsender.ParseSeverityFromString(e.config.Recorder.MinSeverity)
+}
+
 func (*Engine) CanEnqueue() bool { return true }
 
 // ProcessEvent processes the system event against compiled filters.
 // Filter is the internal lingo that designates a rule condition.
-// Filters can be simple direct-event matchers or sequence states that
-// track an ordered series of events over a short period of time.
+// Filters can be simple direct-event matchers, sequence states that
+// track an ordered series of events over a short period of time, or
+// thresholds that count matching events within the sliding window.
 func (e *Engine) ProcessEvent(evt *kevent.Kevent) (bool, error) {
-	if len(e.filters) == 0 {
+	rules := e.rules.Load()
+	if len(rules.filters) == 0 {
 		return true, nil
 	}
 	var matches bool
@@ -267,20 +435,24 @@ func (e *Engine) ProcessEvent(evt *kevent.Kevent) (bool, error) {
 		// expire all sequences if the
 		// process referenced in any
 		// partials has terminated
-		for _, seq := range e.sequences {
+		for _, seq := range rules.sequences {
 			seq.expire(evt)
 		}
 	}
-	filters := e.filters.collect(e.hashCache, evt)
+	filters := rules.collect(e.hashCache, evt)
 	for _, f := range filters {
 		match := f.run(evt)
 		if !match {
 			continue
 		}
-		if f.isSequence() {
+		switch {
+		case f.isSequence():
 			e.appendMatch(f.config, f.ss.events()...)
 			f.ss.clearLocked()
-		} else {
+		case f.isThreshold():
+			e.appendMatch(f.config, f.ts.events()...)
+			f.ts.clearMatches()
+		default:
 			e.appendMatch(f.config, evt)
 		}
 		err := e.processActions()
@@ -288,7 +460,7 @@ func (e *Engine) ProcessEvent(evt *kevent.Kevent) (bool, error) {
 			log.Errorf("unable to execute rule action: %v", err)
 		}
 		switch {
-		case e.config.Filters.MatchAll:
+		case rules.matchAll:
 			matches = true
 		default:
 			return true, nil
@@ -297,6 +469,15 @@ func (e *Engine) ProcessEvent(evt *kevent.Kevent) (bool, error) {
 	return matches, nil
 }
 
+// onDeadlineMatch executes rule actions for the sequence that
+// matched when the deadline of the negated expression was reached.
+func (e *Engine) onDeadlineMatch(f *config.FilterConfig, evts ...*kevent.Kevent) {
+	e.appendMatch(f, evts...)
+	if err := e.processActions(); err != nil {
+		log.Errorf("unable to execute rule action: %v", err)
+	}
+}
+
 // processActions executes rule actions
 // on behalf of rule matches. Actions are
 // categorized into implicit and explicit
@@ -306,16 +487,35 @@ func (e *Engine) ProcessEvent(evt *kevent.Kevent) (bool, error) {
 // match. Other actions are executed if
 // declared in the rule definition.
 func (e *Engine) processActions() error {
-	defer e.clearMatches()
+	// matches can be appended concurrently by
+	// sequence deadlines, so we take ownership
+	// of pending matches before executing actions
 	e.mmu.Lock()
-	defer e.mmu.Unlock()
-	for _, m := range e.matches {
+	matches := e.matches
+	e.matches = make([]*ruleMatch, 0)
+	e.mmu.Unlock()
+	if e.skipActions {
+		return nil
+	}
+	suppressors := e.rules.Load().suppressors
+	for _, m := range matches {
 		f, evts := m.ctx.Filter, m.ctx.Events
 		filterMatches.Add(f.Name, 1)
 		log.Debugf("[%s] rule matched", f.Name)
-		err := action.Alert(m.ctx, f.Name, filter.InterpolateFields(f.Output, evts), f.Severity, f.Tags)
-		if err != nil {
-			return ErrRuleAction(f.Name, err)
+		// the alert is not sent if suppressed,
+		// but other actions are still executed
+		emit := true
+		if s, ok := suppressors[f.Name]; ok {
+			emit, m.ctx.Suppressed = s.next(evts)
+		}
+		if emit {
+			e.dumpCapture(m.ctx)
+			err := action.Alert(m.ctx, f.Name, filter.InterpolateFields(f.Output, evts), f.Severity, f.Tags)
+			if err != nil {
+				return ErrRuleAction(f.Name, err)
+			}
+		} else {
+			log.Debugf("[%s] rule alert suppressed", f.Name)
 		}
 
 		actions, err := f.DecodeActions()
@@ -342,6 +542,30 @@ func (e *Engine) processActions() error {
 	return nil
 }
 
+// dumpCapture persists the flight recorder capture and links
+// its path in the action context. Captures are only dumped
+// for rules with the severity at or above the minimum severity.
+func (e *Engine) dumpCapture(ctx *config.ActionContext) {
+	f := ctx.Filter
+	if e.recorder == nil || alertsender.ParseSeverityFromString(f.Severity) < e.minSeverity {
+		return
+	}
+	ts := time.Now()
+	if n := len(ctx.Events); n > 0 {
+		ts = ctx.Events[n-1].Timestamp
+	}
+	path, err := e.recorder.Dump(f.Name, ts)
+	if err != nil {
+		log.Warnf("[%s] unable to dump flight recorder capture: %v", f.Name, err)
+		return
+	}
+	if path == "" {
+		log.Debugf("[%s] flight recorder capture dump skipped", f.Name)
+		return
+	}
+	ctx.Capture = path
+}
+
 func (e *Engine) appendMatch(f *config.FilterConfig, evts ...*kevent.Kevent) {
 	for _, evt := range evts {
 		evt.AddMeta(kevent.RuleNameKey, f.Name)
@@ -360,9 +584,3 @@ func (e *Engine) appendMatch(f *config.FilterConfig, evts ...*kevent.Kevent) {
 		e.matchFunc(f, evts...)
 	}
 }
-
-func (e *Engine) clearMatches() {
-	e.mmu.Lock()
-	defer e.mmu.Unlock()
-	e.matches = make([]*ruleMatch, 0)
-}
@@ -27,11 +27,9 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
 	"github.com/rabbitstack/fibratus/pkg/ps"
 	"github.com/rabbitstack/fibratus/pkg/ps/types"
-	"github.com/rabbitstack/fibratus/pkg/sys"
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/sys/windows"
 	"net"
 	"os"
 	"testing"
@@ -143,7 +141,7 @@ func TestCompileIndexableFilters(t *testing.T) {
 
 	compileRules(t, e)
 
-	assert.Len(t, e.filters, 3)
+	assert.Len(t, e.rules.Load().filters, 3)
 
 	var tests = []struct {
 		evt   *kevent.Kevent
@@ -158,7 +156,7 @@ func TestCompileIndexableFilters(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.evt.Type.String(), func(t *testing.T) {
-			assert.Len(t, e.filters.collect(e.hashCache, tt.evt), tt.wants)
+			assert.Len(t, e.rules.Load().collect(e.hashCache, tt.evt), tt.wants)
 		})
 	}
 
@@ -253,7 +251,7 @@ func TestRunSequenceRule(t *testing.T) {
 	}
 
 	// register alert sender
-	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.None}}))
+	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.None}}, alertsender.RouteConfig{}))
 
 	require.False(t, wrapProcessEvent(e1, e.ProcessEvent))
 	require.False(t, wrapProcessEvent(e2, e.ProcessEvent))
@@ -427,7 +425,7 @@ func TestRunSimpleAndSequenceRules(t *testing.T) {
 }
 
 func TestAlertAction(t *testing.T) {
-	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.Noop}}))
+	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.Noop}}, alertsender.RouteConfig{}))
 	e := NewEngine(new(ps.SnapshotterMock), newConfig("_fixtures/simple_emit_alert.yml"))
 	compileRules(t, e)
 
@@ -459,59 +457,55 @@ func TestAlertAction(t *testing.T) {
 	emitAlert = nil
 }
 
-func TestKillAction(t *testing.T) {
-	log.SetLevel(log.DebugLevel)
-
-	e := NewEngine(new(ps.SnapshotterMock), newConfig("_fixtures/kill_action.yml"))
-	compileRules(t, e)
-
-	// register alert sender
-	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.None}}))
+type mockRecorder struct {
+	dumps []string
+}
 
-	var si windows.StartupInfo
-	var pi windows.ProcessInformation
-	argv, err := windows.UTF16PtrFromString("calc.exe")
-	require.NoError(t, err)
-	err = windows.CreateProcess(
-		nil,
-		argv,
-		nil,
-		nil,
-		true,
-		0,
-		nil,
-		nil,
-		&si,
-		&pi)
-	require.NoError(t, err)
+func (r *mockRecorder) Dump(name string, ts time.Time) (string, error) {
+	path := `C:\Captures\` + name + "-" + ts.UTC().Format("20060102") + ".kcap"
+	r.dumps = append(r.dumps, path)
+	return path, nil
+}
 
-	i := 0
-	for !sys.IsProcessRunning(pi.Process) && i < 10 {
-		i++
-		time.Sleep(time.Millisecond * 100 * time.Duration(i))
-	}
+func TestAlertActionFlightRecorder(t *testing.T) {
+	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.Noop}}, alertsender.RouteConfig{}))
+	c := newConfig("_fixtures/simple_emit_alert.yml")
+	c.Recorder.MinSeverity = "high"
+	e := NewEngine(new(ps.SnapshotterMock), c)
+	compileRules(t, e)
+	r := &mockRecorder{}
+	e.SetRecorder(r)
 
 	evt := &kevent.Kevent{
-		Type:      ktypes.CreateProcess,
-		Timestamp: time.Now(),
-		Name:      "CreateProcess",
+		Type:      ktypes.RecvTCPv4,
+		Name:      "Recv",
 		Tid:       2484,
 		PID:       859,
-		Category:  ktypes.Process,
+		Category:  ktypes.Net,
+		Timestamp: time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
 		PS: &types.PS{
 			Name: "cmd.exe",
-			Exe:  "C:\\Windows\\system32\\svchost-temp.exe",
 		},
 		Kparams: kevent.Kparams{
-			kparams.ProcessID:   {Name: kparams.ProcessID, Type: kparams.PID, Value: pi.ProcessId},
-			kparams.ProcessName: {Name: kparams.ProcessName, Type: kparams.UnicodeString, Value: "calc.exe"},
+			kparams.NetDport: {Name: kparams.NetDport, Type: kparams.Uint16, Value: uint16(443)},
+			kparams.NetSport: {Name: kparams.NetSport, Type: kparams.Uint16, Value: uint16(43123)},
+			kparams.NetSIP:   {Name: kparams.NetSIP, Type: kparams.IPv4, Value: net.ParseIP("127.0.0.1")},
+			kparams.NetDIP:   {Name: kparams.NetDIP, Type: kparams.IPv4, Value: net.ParseIP("216.58.201.174")},
 		},
-		Metadata: map[kevent.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+		Metadata: make(map[kevent.MetadataKey]any),
 	}
 
-	require.True(t, sys.IsProcessRunning(pi.Process))
 	require.True(t, wrapProcessEvent(evt, e.ProcessEvent))
-	require.False(t, sys.IsProcessRunning(pi.Process))
+	time.Sleep(time.Millisecond * 25)
+	require.NotNil(t, emitAlert)
+	require.Len(t, r.dumps, 1)
+	assert.Equal(t, `C:\Captures\match https connections-20240301.kcap`, emitAlert.Capture)
+	assert.Contains(t, emitAlert.Text, emitAlert.Capture)
+	emitAlert = nil
+
+	// rules below the minimum severity don't dump captures
+	e.dumpCapture(&config.ActionContext{Filter: &config.FilterConfig{Name: "low", Severity: "medium"}})
+	require.Len(t, r.dumps, 1)
 }
 
 func BenchmarkRunRules(b *testing.B) {
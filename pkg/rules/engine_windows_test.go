@@ -0,0 +1,89 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/alertsender"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	"github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/sys"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows"
+	"testing"
+	"time"
+)
+
+func TestKillAction(t *testing.T) {
+	log.SetLevel(log.DebugLevel)
+
+	e := NewEngine(new(ps.SnapshotterMock), newConfig("_fixtures/kill_action.yml"))
+	compileRules(t, e)
+
+	// register alert sender
+	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.None}}, alertsender.RouteConfig{}))
+
+	var si windows.StartupInfo
+	var pi windows.ProcessInformation
+	argv, err := windows.UTF16PtrFromString("calc.exe")
+	require.NoError(t, err)
+	err = windows.CreateProcess(
+		nil,
+		argv,
+		nil,
+		nil,
+		true,
+		0,
+		nil,
+		nil,
+		&si,
+		&pi)
+	require.NoError(t, err)
+
+	i := 0
+	for !sys.IsProcessRunning(pi.Process) && i < 10 {
+		i++
+		time.Sleep(time.Millisecond * 100 * time.Duration(i))
+	}
+
+	evt := &kevent.Kevent{
+		Type:      ktypes.CreateProcess,
+		Timestamp: time.Now(),
+		Name:      "CreateProcess",
+		Tid:       2484,
+		PID:       859,
+		Category:  ktypes.Process,
+		PS: &types.PS{
+			Name: "cmd.exe",
+			Exe:  "C:\\Windows\\system32\\svchost-temp.exe",
+		},
+		Kparams: kevent.Kparams{
+			kparams.ProcessID:   {Name: kparams.ProcessID, Type: kparams.PID, Value: pi.ProcessId},
+			kparams.ProcessName: {Name: kparams.ProcessName, Type: kparams.UnicodeString, Value: "calc.exe"},
+		},
+		Metadata: map[kevent.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
+	}
+
+	require.True(t, sys.IsProcessRunning(pi.Process))
+	require.True(t, wrapProcessEvent(evt, e.ProcessEvent))
+	require.False(t, sys.IsProcessRunning(pi.Process))
+}
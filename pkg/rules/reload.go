@@ -0,0 +1,228 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"expvar"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter/ql"
+	log "github.com/sirupsen/logrus"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// rulesReloads counts the number of successful ruleset reloads
+	rulesReloads = expvar.NewInt("filter.reloads")
+	// rulesReloadErrors counts the number of failed ruleset reloads
+	rulesReloadErrors = expvar.NewInt("filter.reload.errors")
+
+	// reloadDebounce is the quiet period after the last
+	// file change before the ruleset is reloaded. Editors
+	// usually emit several events when the file is saved
+	reloadDebounce = time.Second
+)
+
+// Reload loads macros and rules and compiles them into a new ruleset
+// that atomically replaces the active ruleset. The state of sequence
+// rules whose condition didn't change is transferred to the new ruleset.
+// If the ruleset fails to compile, the previous ruleset and the filters
+// config remain active and the error is returned.
+func (e *Engine) Reload() (*config.RulesCompileResult, error) {
+	e.rmu.Lock()
+	defer e.rmu.Unlock()
+
+	// macros and rules are loaded into the new filters
+	// config that replaces the active config only after
+	// the new ruleset is built
+	fconfig, filters, rs, err := e.compiler.compile()
+	if err != nil {
+		rulesReloadErrors.Add(1)
+		return nil, err
+	}
+	rules, err := e.buildRuleset(filters, e.rules.Load())
+	if err != nil {
+		rulesReloadErrors.Add(1)
+		return nil, err
+	}
+	rules.matchAll = fconfig.MatchAll
+
+	old := e.rules.Swap(rules)
+	e.config.Filters = fconfig
+
+	// discard the state of sequences
+	// removed from the ruleset
+	keep := make(map[*sequenceState]bool, len(rules.sequences))
+	for _, ss := range rules.sequences {
+		keep[ss] = true
+	}
+	for _, ss := range old.sequences {
+		if !keep[ss] {
+			ss.dispose()
+		}
+	}
+
+	// event types are enabled in the event source when
+	// the engine starts. If the new ruleset requires
+	// other events, they are not delivered to the engine
+	if rs != nil {
+		missing := make([]string, 0)
+		for _, typ := range rs.UsedEvents {
+			if !e.usedEvents[typ] {
+				missing = append(missing, typ.String())
+			}
+		}
+		if len(missing) > 0 {
+			log.Warnf("reloaded ruleset requires events that are not collected: %s. "+
+				"Restart Fibratus to enable them", strings.Join(missing, ", "))
+		}
+	}
+
+	rulesReloads.Add(1)
+	log.Infof("ruleset reloaded. %d rule(s) active", len(filters))
+
+	return rs, nil
+}
+
+// Watch starts monitoring the directories of rule and macro files. When
+// any of the files matching the rule or macro paths is created, modified,
+// or removed, the ruleset is reloaded.
+func (e *Engine) Watch() error {
+	patterns := make([]string, 0)
+	patterns = append(patterns, e.config.Filters.Rules.FromPaths...)
+	patterns = append(patterns, e.config.Filters.Macros.FromPaths...)
+
+	// glob expressions can also appear in the directory
+	dirs := make(map[string]bool)
+	for _, p := range patterns {
+		paths, err := filepath.Glob(filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		for _, path := range paths {
+			dirs[path] = true
+		}
+	}
+	if len(dirs) == 0 {
+		log.Warn("no rule or macro directories to watch")
+		return nil
+	}
+
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	for dir := range dirs {
+		if err := w.Add(dir); err != nil {
+			_ = w.Close()
+			return fmt.Errorf("unable to watch %s directory: %v", dir, err)
+		}
+		log.Infof("watching %s directory for rule changes", dir)
+	}
+
+	go e.watch(w, patterns)
+
+	return nil
+}
+
+func (e *Engine) watch(w *fsnotify.Watcher, patterns []string) {
+	var debounce *time.Timer
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if ev.Op == fsnotify.Chmod || !matchesAnyPath(patterns, ev.Name) {
+				continue
+			}
+			log.Debugf("rule file %s changed: %s", ev.Name, ev.Op)
+			if debounce == nil {
+				debounce = time.AfterFunc(reloadDebounce, e.reloadOnChange)
+			} else {
+				debounce.Reset(reloadDebounce)
+			}
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("rules watcher error: %v", err)
+		}
+	}
+}
+
+func (e *Engine) reloadOnChange() {
+	rs, err := e.Reload()
+	if err != nil {
+		log.Errorf("unable to reload rules. Previous ruleset remains active: %v", err)
+		return
+	}
+	if rs != nil {
+		log.Infof("rules compile summary: %s", rs)
+	}
+}
+
+// matchesAnyPath determines if the file path matches
+// any of the path patterns. Only YAML files are eligible.
+func matchesAnyPath(patterns []string, path string) bool {
+	ext := filepath.Ext(path)
+	if ext != ".yml" && ext != ".yaml" {
+		return false
+	}
+	path = strings.ToLower(filepath.Clean(path))
+	for _, p := range patterns {
+		if ok, _ := filepath.Match(strings.ToLower(filepath.Clean(p)), path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// sequenceKey builds the identity of the sequence rule. The key
+// is derived from the rule identifier and the sequence statements
+// and expressions with expanded macros. If the key remains equal
+// across reloads, the sequence state is preserved.
+func sequenceKey(c *config.FilterConfig, seq *ql.Sequence) string {
+	var b strings.Builder
+	b.WriteString(c.ID)
+	b.WriteByte(0)
+	b.WriteString(c.Name)
+	b.WriteByte(0)
+	b.WriteString(seq.MaxSpan.String())
+	if seq.By != nil {
+		b.WriteString(" by " + seq.By.Value)
+	}
+	for _, expr := range seq.Expressions {
+		b.WriteByte(0)
+		if expr.Negated {
+			b.WriteByte('!')
+		}
+		b.WriteString(expr.Expr.String())
+		if expr.By != nil {
+			b.WriteString(" by " + expr.By.Value)
+		}
+		if expr.Alias != "" {
+			b.WriteString(" as " + expr.Alias)
+		}
+	}
+	return b.String()
+}
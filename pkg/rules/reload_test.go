@@ -0,0 +1,137 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const sequenceRule = `
+name: Executable dropped and spawned
+id: 3e5e4a5c-7d1f-4f69-b1a3-6a0f8d0c2b11
+version: 1.0.0
+condition: >
+  sequence
+  maxspan %s
+  by ps.pid
+    |kevt.name = 'CreateFile' and file.extension = '.exe'|
+    |kevt.name = 'CreateProcess' and ps.child.name = 'cmd.exe'|
+min-engine-version: 2.0.0
+`
+
+const simpleRule = `
+name: Connection to the remote endpoint
+id: 9d1a1c4e-2f0b-4c5e-8f7a-1b2c3d4e5f60
+version: 1.0.0
+condition: kevt.name = 'Connect' and net.dport = 443
+min-engine-version: 2.0.0
+`
+
+const brokenRule = `
+name: Broken rule
+id: 0b6f0c1d-5d4e-4a3b-9c2d-7e8f9a0b1c2d
+version: 1.0.0
+condition: kevt.name = 'CreateFile' and file.nam = 'cmd.exe'
+min-engine-version: 2.0.0
+`
+
+func writeRule(t *testing.T, dir, name, rule string, args ...any) {
+	if len(args) > 0 {
+		rule = fmt.Sprintf(rule, args...)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(rule), 0644))
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	writeRule(t, dir, "sequence.yml", sequenceRule, "1h")
+
+	c := newConfig(filepath.Join(dir, "*.yml"))
+	e := NewEngine(new(ps.SnapshotterMock), c)
+	compileRules(t, e)
+
+	rules := e.rules.Load()
+	require.Len(t, rules.sequences, 1)
+	ss := rules.sequences[0]
+
+	evt := &kevent.Kevent{
+		Type:      ktypes.CreateFile,
+		Name:      "CreateFile",
+		Category:  ktypes.File,
+		Timestamp: time.Now(),
+		PID:       1023,
+		PS:        &pstypes.PS{PID: 1023, Name: "winword.exe"},
+		Kparams: kevent.Kparams{
+			kparams.FilePath: {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: "C:\\Temp\\dropper.exe"},
+		},
+		Metadata: make(map[kevent.MetadataKey]any),
+	}
+	match, err := e.ProcessEvent(evt)
+	require.NoError(t, err)
+	require.False(t, match)
+	require.Len(t, ss.partials[0], 1)
+
+	// add a new rule. The sequence state is preserved
+	// and the filters config is replaced with the new one
+	fconfig := c.Filters
+	writeRule(t, dir, "simple.yml", simpleRule)
+	rs, err := e.Reload()
+	require.NoError(t, err)
+	require.NotNil(t, rs)
+	assert.Equal(t, 2, rs.NumberRules)
+	assert.NotSame(t, fconfig, c.Filters)
+	assert.Len(t, fconfig.Rules.FromPaths, 1)
+
+	rules = e.rules.Load()
+	require.Len(t, rules.sequences, 1)
+	assert.Equal(t, ss, rules.sequences[0])
+	assert.Len(t, ss.partials[0], 1)
+
+	// the broken rule leaves the previous ruleset running
+	writeRule(t, dir, "broken.yml", brokenRule)
+	fconfig = c.Filters
+	_, err = e.Reload()
+	require.Error(t, err)
+	assert.Equal(t, rules, e.rules.Load())
+	assert.Same(t, fconfig, c.Filters)
+	assert.Len(t, c.GetFilters(), 2)
+	require.NoError(t, os.Remove(filepath.Join(dir, "broken.yml")))
+
+	// the sequence changed, so the state is discarded
+	writeRule(t, dir, "sequence.yml", sequenceRule, "30m")
+	_, err = e.Reload()
+	require.NoError(t, err)
+
+	rules = e.rules.Load()
+	require.Len(t, rules.sequences, 1)
+	assert.NotEqual(t, ss, rules.sequences[0])
+	assert.Len(t, ss.partials, 0)
+	assert.Len(t, rules.sequences[0].partials, 0)
+}
@@ -0,0 +1,34 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestMatchesAnyPath(t *testing.T) {
+	patterns := []string{"C:\\Program Files\\Fibratus\\Rules\\*", "C:\\Program Files\\Fibratus\\Rules\\Macros\\*.yml"}
+
+	assert.True(t, matchesAnyPath(patterns, "C:\\Program Files\\Fibratus\\Rules\\defense_evasion.yml"))
+	assert.True(t, matchesAnyPath(patterns, "c:\\program files\\fibratus\\rules\\macros\\macros.yml"))
+	assert.False(t, matchesAnyPath(patterns, "C:\\Program Files\\Fibratus\\Rules\\defense_evasion.yml~"))
+	assert.False(t, matchesAnyPath(patterns, "C:\\Program Files\\Fibratus\\Rules\\Macros\\macros.yaml"))
+	assert.False(t, matchesAnyPath(patterns, "C:\\Temp\\rule.yml"))
+}
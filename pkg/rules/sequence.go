@@ -31,6 +31,7 @@ import (
 	"github.com/rabbitstack/fibratus/pkg/ps"
 	"github.com/rabbitstack/fibratus/pkg/util/atomic"
 	log "github.com/sirupsen/logrus"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -84,6 +85,8 @@ type sequenceState struct {
 	seq     *ql.Sequence
 	name    string
 	maxSpan time.Duration
+	// key identifies the sequence across ruleset reloads
+	key string
 
 	// partials keeps the state of all matched events per expression
 	partials map[int][]*kevent.Kevent
@@ -102,12 +105,19 @@ type sequenceState struct {
 	// exprs stores the expression index to
 	// its respective string representation
 	exprs              map[int]string
-	spanDeadlines      map[fsm.State]*time.Timer
+	spanDeadlines      map[fsm.State]timer
 	inDeadline         atomic.Bool
 	inExpired          atomic.Bool
 	initialState       fsm.State
 	isPartialsBreached atomic.Bool
 
+	// absenceDeadlines keeps the max span deadline of
+	// every partial awaiting the absence of the negated
+	// expression
+	absenceDeadlines map[absenceKey]timer
+	// amu guards the absence deadlines map
+	amu sync.Mutex
+
 	// states keeps the mapping between expression
 	// index and its matching state. Whenever the expression
 	// evaluates to true the state is updated for the index
@@ -117,22 +127,40 @@ type sequenceState struct {
 	smu sync.RWMutex
 
 	psnap ps.Snapshotter
+
+	// clock schedules the max span deadlines
+	clock clock
+
+	// deadlineMatchFunc is called with the upstream matches
+	// when the sequence reaches the terminal state because
+	// the negated expression didn't match within the max span.
+	// The function is guarded by the partials mutex
+	deadlineMatchFunc func(evts ...*kevent.Kevent)
+}
+
+// absenceKey identifies the partial awaiting
+// the absence of the negated expression.
+type absenceKey struct {
+	seqID int
+	e     *kevent.Kevent
 }
 
 func newSequenceState(f filter.Filter, c *config.FilterConfig, psnap ps.Snapshotter) *sequenceState {
 	ss := &sequenceState{
-		filter:        f,
-		seq:           f.GetSequence(),
-		name:          c.Name,
-		maxSpan:       f.GetSequence().MaxSpan,
-		partials:      make(map[int][]*kevent.Kevent),
-		states:        make(map[fsm.State]bool),
-		matches:       make(map[int]*kevent.Kevent),
-		exprs:         make(map[int]string),
-		spanDeadlines: make(map[fsm.State]*time.Timer),
-		initialState:  sequenceInitialState,
-		inDeadline:    atomic.MakeBool(false),
-		psnap:         psnap,
+		filter:           f,
+		seq:              f.GetSequence(),
+		name:             c.Name,
+		maxSpan:          f.GetSequence().MaxSpan,
+		partials:         make(map[int][]*kevent.Kevent),
+		states:           make(map[fsm.State]bool),
+		matches:          make(map[int]*kevent.Kevent),
+		exprs:            make(map[int]string),
+		spanDeadlines:    make(map[fsm.State]timer),
+		absenceDeadlines: make(map[absenceKey]timer),
+		initialState:     sequenceInitialState,
+		inDeadline:       atomic.MakeBool(false),
+		psnap:            psnap,
+		clock:            systemClock{},
 	}
 
 	ss.initFSM()
@@ -153,6 +181,21 @@ func (s *sequenceState) events() []*kevent.Kevent {
 	return events
 }
 
+func (s *sequenceState) setDeadlineMatchFunc(fn func(evts ...*kevent.Kevent)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.deadlineMatchFunc = fn
+}
+
+// isNegated determines if the expression at the given state is negated.
+func (s *sequenceState) isNegated(state fsm.State) bool {
+	seqID, ok := state.(int)
+	if !ok || seqID >= len(s.seq.Expressions) {
+		return false
+	}
+	return s.seq.Expressions[seqID].Negated
+}
+
 func (s *sequenceState) isStateSchedulable(state fsm.State) bool {
 	return state != s.initialState && state != sequenceTerminalState && state != sequenceExpiredState && state != sequenceDeadlineState
 }
@@ -163,10 +206,15 @@ func (s *sequenceState) isStateSchedulable(state fsm.State) bool {
 func (s *sequenceState) initFSM() {
 	s.fsm = fsm.NewStateMachine(s.initialState)
 	s.fsm.OnTransitioned(func(ctx context.Context, transition fsm.Transition) {
-		// schedule span deadline for the current state unless initial/meta states
+		// schedule span deadline for the current state unless initial/meta states.
+		// The absence of the negated expression is awaited for each partial
 		if s.maxSpan != 0 && s.isStateSchedulable(s.currentState()) {
-			log.Debugf("scheduling max span deadline of %v for expression [%s] of sequence [%s]", s.maxSpan, s.expr(s.currentState()), s.name)
-			s.scheduleMaxSpanDeadline(s.currentState(), s.maxSpan)
+			if s.isNegated(s.currentState()) {
+				s.scheduleAbsenceDeadlines(s.currentState().(int))
+			} else {
+				log.Debugf("scheduling max span deadline of %v for expression [%s] of sequence [%s]", s.maxSpan, s.expr(s.currentState()), s.name)
+				s.scheduleMaxSpanDeadline(s.currentState(), s.maxSpan)
+			}
 		}
 		// if the sequence was deadlined/expired, we can disable the deadline
 		// status when the first expression in the sequence is reevaluated
@@ -224,6 +272,11 @@ func (s *sequenceState) initFSM() {
 // occurs when the process attributed to any of the pending partials in the
 // sequence terminates. In this case, the state machine transitions to the
 // expired state.
+//
+// Negated expressions invert the meaning of the max span deadline. If the
+// negated expression doesn't match until the deadline, the state transitions
+// to the next state via the match transition. Conversely, if an event matches
+// the negated expression, the state machine transitions to the deadline state.
 func (s *sequenceState) configureFSM() {
 	for seqID, expr := range s.seq.Expressions {
 		// sequence expression index is the state name
@@ -337,6 +390,11 @@ func (s *sequenceState) addPartial(seqID int, e *kevent.Kevent, isOOO bool) {
 	partialsPerSequence.Add(s.name, 1)
 	s.partials[seqID] = append(s.partials[seqID], e)
 	sort.Slice(s.partials[seqID], func(n, m int) bool { return s.partials[seqID][n].Timestamp.Before(s.partials[seqID][m].Timestamp) })
+	// the partial arrived while the absence of the
+	// negated expression is awaited for other partials
+	if state, ok := s.currentState().(int); ok && s.maxSpan != 0 && s.isNegated(state) && s.upstreamSlot(state) == seqID {
+		s.scheduleAbsenceDeadline(state, e)
+	}
 }
 
 // gc prunes the sequence partial if it remained
@@ -352,7 +410,7 @@ func (s *sequenceState) gc() {
 	}
 	for idx := range s.exprs {
 		for i := len(s.partials[idx]) - 1; i >= 0; i-- {
-			if len(s.partials[idx]) > 0 && time.Since(s.partials[idx][i].Timestamp) > dur {
+			if len(s.partials[idx]) > 0 && s.clock.Now().Sub(s.partials[idx][i].Timestamp) > dur && !s.awaitsAbsence(s.partials[idx][i]) {
 				log.Debugf("garbage collecting partial: [%s] of sequence [%s]", s.partials[idx][i], s.name)
 				// remove partial event from the corresponding slot
 				s.partials[idx] = append(
@@ -368,11 +426,34 @@ func (s *sequenceState) clear() {
 	s.partials = make(map[int][]*kevent.Kevent)
 	s.matches = make(map[int]*kevent.Kevent)
 	s.states = make(map[fsm.State]bool)
-	s.spanDeadlines = make(map[fsm.State]*time.Timer)
+	s.spanDeadlines = make(map[fsm.State]timer)
+	s.amu.Lock()
+	for _, t := range s.absenceDeadlines {
+		t.Stop()
+	}
+	s.absenceDeadlines = make(map[absenceKey]timer)
+	s.amu.Unlock()
 	s.isPartialsBreached.Store(false)
 	partialsPerSequence.Delete(s.name)
 }
 
+// dispose stops pending max span deadlines and discards
+// the state of the sequence that was removed from the
+// ruleset.
+func (s *sequenceState) dispose() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	s.mmu.Lock()
+	defer s.mmu.Unlock()
+	for _, span := range s.spanDeadlines {
+		span.Stop()
+	}
+	s.deadlineMatchFunc = nil
+	s.clear()
+}
+
 func (s *sequenceState) clearLocked() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -405,7 +486,7 @@ func (s *sequenceState) next(seqID int) bool {
 }
 
 func (s *sequenceState) scheduleMaxSpanDeadline(seqID fsm.State, maxSpan time.Duration) {
-	t := time.AfterFunc(maxSpan, func() {
+	t := s.clock.AfterFunc(maxSpan, func() {
 		inState, _ := s.fsm.IsInState(seqID)
 		if inState {
 			log.Debugf("max span of %v exceded for expression [%s] of sequence [%s]", maxSpan, s.expr(seqID), s.name)
@@ -430,12 +511,248 @@ func (s *sequenceState) scheduleMaxSpanDeadline(seqID fsm.State, maxSpan time.Du
 	s.spanDeadlines[seqID] = t
 }
 
+// upstreamSlot returns the index of the last non-negated
+// expression preceding the negated expression. Its partials
+// await the absence of the negated expression.
+func (s *sequenceState) upstreamSlot(seqID int) int {
+	for i := seqID - 1; i >= 0; i-- {
+		if !s.seq.Expressions[i].Negated {
+			return i
+		}
+	}
+	return 0
+}
+
+// scheduleAbsenceDeadlines schedules the max span deadline for
+// each partial of the slot upstream to the negated expression.
+func (s *sequenceState) scheduleAbsenceDeadlines(seqID int) {
+	for _, p := range s.partials[s.upstreamSlot(seqID)] {
+		s.scheduleAbsenceDeadline(seqID, p)
+	}
+}
+
+// scheduleAbsenceDeadline schedules the max span deadline for the
+// partial awaiting the absence of the negated expression. Every
+// partial gets its own deadline, so the partial that arrived later
+// is not reported before its max span elapses.
+func (s *sequenceState) scheduleAbsenceDeadline(seqID int, e *kevent.Kevent) {
+	s.amu.Lock()
+	defer s.amu.Unlock()
+	key := absenceKey{seqID: seqID, e: e}
+	if _, ok := s.absenceDeadlines[key]; ok {
+		return
+	}
+	log.Debugf("scheduling max span deadline of %v for negated expression [%s] of sequence [%s]: %s", s.maxSpan, s.expr(seqID), s.name, e)
+	s.absenceDeadlines[key] = s.clock.AfterFunc(s.maxSpan, func() {
+		s.absenceTransition(seqID, e)
+	})
+}
+
+// awaitsAbsence determines if the partial awaits the
+// absence of any of the negated expressions.
+func (s *sequenceState) awaitsAbsence(e *kevent.Kevent) bool {
+	s.amu.Lock()
+	defer s.amu.Unlock()
+	for key := range s.absenceDeadlines {
+		if key.e == e {
+			return true
+		}
+	}
+	return false
+}
+
+// stopAbsenceDeadlines stops the pending max span deadlines of the partial.
+func (s *sequenceState) stopAbsenceDeadlines(e *kevent.Kevent) {
+	s.amu.Lock()
+	defer s.amu.Unlock()
+	for key, t := range s.absenceDeadlines {
+		if key.e == e {
+			t.Stop()
+			delete(s.absenceDeadlines, key)
+		}
+	}
+}
+
+// absenceTransition is triggered when the max span deadline of the
+// partial is reached and no event matched the negated expression.
+// If the negated expression is the last in the sequence, the partial
+// and the upstream partials joined with it are handed to the deadline
+// match function, and the partial is discarded. The sequence is reset
+// once no more partials await the absence. Otherwise, the state machine
+// transitions to the state of the next expression.
+func (s *sequenceState) absenceTransition(seqID int, e *kevent.Kevent) {
+	var (
+		evts      []*kevent.Kevent
+		matchFunc func(evts ...*kevent.Kevent)
+	)
+	s.mu.Lock()
+	s.smu.Lock()
+	s.amu.Lock()
+	delete(s.absenceDeadlines, absenceKey{seqID: seqID, e: e})
+	s.amu.Unlock()
+	slot := s.upstreamSlot(seqID)
+	if inState, _ := s.fsm.IsInState(seqID); !inState || !slices.Contains(s.partials[slot], e) {
+		s.smu.Unlock()
+		s.mu.Unlock()
+		return
+	}
+	log.Debugf("no match for negated expression [%s] of sequence [%s] within max span of %v: %s", s.expr(seqID), s.name, s.maxSpan, e)
+
+	if seqID < len(s.seq.Expressions)-1 {
+		err := s.fsm.Fire(matchTransition, nil)
+		if err != nil {
+			matchTransitionErrors.Add(1)
+			log.Warnf("absence match transition failure: %v", err)
+		}
+		s.smu.Unlock()
+		s.mu.Unlock()
+		return
+	}
+
+	matchFunc = s.deadlineMatchFunc
+	evts = s.joined(slot, e)
+	s.partials[slot] = slices.DeleteFunc(s.partials[slot], func(p *kevent.Kevent) bool { return p == e })
+	partialsPerSequence.Add(s.name, -1)
+	s.stopAbsenceDeadlines(e)
+
+	if len(s.partials[slot]) == 0 {
+		err := s.fsm.Fire(matchTransition, nil)
+		if err != nil {
+			matchTransitionErrors.Add(1)
+			log.Warnf("absence match transition failure: %v", err)
+		}
+		err = s.fsm.Fire(resetTransition)
+		if err != nil {
+			log.Warnf("unable to transition to initial state: %v", err)
+		}
+		s.mmu.Lock()
+		s.clear()
+		s.mmu.Unlock()
+	}
+	s.smu.Unlock()
+	s.mu.Unlock()
+
+	if matchFunc != nil {
+		matchFunc(evts...)
+	}
+}
+
+// joined returns the partial along with the earliest
+// partials of the upstream slots sharing its join value.
+func (s *sequenceState) joined(slot int, e *kevent.Kevent) []*kevent.Kevent {
+	evts := []*kevent.Kevent{e}
+	link := e.SequenceLink()
+	for i := slot - 1; i >= 0; i-- {
+		if s.seq.Expressions[i].Negated {
+			continue
+		}
+		for _, p := range s.partials[i] {
+			if link == nil || filter.CompareSeqLink(link, p.SequenceLink()) {
+				evts = append(evts, p)
+				break
+			}
+		}
+	}
+	sort.Slice(evts, func(i, j int) bool { return evts[i].Timestamp.Before(evts[j].Timestamp) })
+	return evts
+}
+
+// cancelNegated is triggered when the event matches the negated
+// expression. Only the upstream partials joined with the event
+// are discarded. If partials with other join values remain, the
+// absence is still awaited for them. Otherwise, the pending deadline
+// is stopped and the state machine transitions to the deadline state.
+func (s *sequenceState) cancelNegated(seqID int, e *kevent.Kevent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.smu.Lock()
+	defer s.smu.Unlock()
+	if inState, _ := s.fsm.IsInState(seqID); !inState {
+		return
+	}
+	log.Debugf("negated expression [%s] of sequence [%s] matched", s.expr(seqID), s.name)
+	if link := e.SequenceLink(); link != nil && s.removeLinkedPartials(seqID, link) {
+		return
+	}
+	if span, ok := s.spanDeadlines[seqID]; ok {
+		span.Stop()
+		delete(s.spanDeadlines, seqID)
+	}
+	err := s.cancelTransition(seqID)
+	if err != nil {
+		log.Warnf("deadline transition failed: %v", err)
+	}
+	err = s.fsm.Fire(resetTransition)
+	if err != nil {
+		log.Warnf("unable to transition to initial state: %v", err)
+	}
+}
+
+// removeLinkedPartials removes the partials of the slots upstream
+// to the negated expression whose join value is equal to the given
+// link. Returns true if the partials with other join values are still
+// pending in the last matching slot before the negated expression.
+func (s *sequenceState) removeLinkedPartials(seqID int, link any) bool {
+	last := -1
+	for i := 0; i < seqID; i++ {
+		if s.seq.Expressions[i].Negated {
+			continue
+		}
+		last = i
+		partials := make([]*kevent.Kevent, 0, len(s.partials[i]))
+		for _, p := range s.partials[i] {
+			if filter.CompareSeqLink(link, p.SequenceLink()) {
+				log.Debugf("removing partial [%s] of sequence [%s] joined with the negated expression", p, s.name)
+				partialsPerSequence.Add(s.name, -1)
+				s.stopAbsenceDeadlines(p)
+				continue
+			}
+			partials = append(partials, p)
+		}
+		s.partials[i] = partials
+	}
+	return last >= 0 && len(s.partials[last]) > 0
+}
+
+// link joins the partials of adjacent sequence slots
+// and stores the events that produced the match. Negated
+// slots never contain partials, so they are skipped when
+// joining the upstream and downstream slots.
+func (s *sequenceState) link() {
+	setMatch := func(seqID int, e *kevent.Kevent) {
+		s.mmu.Lock()
+		defer s.mmu.Unlock()
+		if s.matches[seqID] == nil {
+			s.matches[seqID] = e
+		}
+	}
+
+	slots := make([]int, 0, len(s.seq.Expressions))
+	for seqID, expr := range s.seq.Expressions {
+		if !expr.Negated {
+			slots = append(slots, seqID)
+		}
+	}
+	for n := 0; n < len(slots)-1; n++ {
+		for _, outer := range s.partials[slots[n]] {
+			for _, inner := range s.partials[slots[n+1]] {
+				if filter.CompareSeqLink(outer.SequenceLink(), inner.SequenceLink()) {
+					setMatch(slots[n], outer)
+					setMatch(slots[n+1], inner)
+				}
+			}
+		}
+	}
+}
+
 func (s *sequenceState) runSequence(e *kevent.Kevent) bool {
 	for i, expr := range s.seq.Expressions {
 		// only try to evaluate the expression
 		// if upstream expressions have matched
 		if !s.next(i) {
-			if !s.seq.IsUnordered {
+			// negated expressions are never
+			// stored as out-of-order partials
+			if !s.seq.IsUnordered || expr.Negated {
 				continue
 			}
 			// it could be the event arrived out
@@ -465,6 +782,15 @@ func (s *sequenceState) runSequence(e *kevent.Kevent) bool {
 		matches := s.filter.RunSequence(e, i, s.partials, false)
 		s.mu.RUnlock()
 
+		// the event matching the negated expression
+		// refutes the absence and cancels the sequence
+		if expr.Negated {
+			if matches {
+				s.cancelNegated(i, e)
+			}
+			continue
+		}
+
 		// append the partial and transition state machine
 		if matches {
 			s.addPartial(i, e, false)
@@ -510,25 +836,8 @@ func (s *sequenceState) runSequence(e *kevent.Kevent) bool {
 		// collect all events involved in the rule match
 		isTerminal := s.isTerminalState()
 		if isTerminal {
-			setMatch := func(seqID int, e *kevent.Kevent) {
-				s.mmu.Lock()
-				defer s.mmu.Unlock()
-				if s.matches[seqID] == nil {
-					s.matches[seqID] = e
-				}
-			}
-
 			s.mu.RLock()
-			for seqID := 0; seqID < len(s.partials); seqID++ {
-				for _, outer := range s.partials[seqID] {
-					for _, inner := range s.partials[seqID+1] {
-						if filter.CompareSeqLink(outer.SequenceLink(), inner.SequenceLink()) {
-							setMatch(seqID, outer)
-							setMatch(seqID+1, inner)
-						}
-					}
-				}
-			}
+			s.link()
 			s.mu.RUnlock()
 
 			return true
@@ -578,6 +887,7 @@ func (s *sequenceState) expire(e *kevent.Kevent) bool {
 				s.name,
 				idx)
 			// remove partial event from the corresponding slot
+			s.stopAbsenceDeadlines(s.partials[idx][i])
 			s.partials[idx] = append(
 				s.partials[idx][:i],
 				s.partials[idx][i+1:]...)
@@ -30,7 +30,6 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/sys/windows/registry"
 	"net"
 	"strconv"
 	"testing"
@@ -441,6 +440,179 @@ func TestSimpleSequenceDeadline(t *testing.T) {
 	require.True(t, ss.runSequence(e2))
 }
 
+func TestSequenceAbsence(t *testing.T) {
+	log.SetLevel(log.DebugLevel)
+
+	c := &config.FilterConfig{Name: "Dropped executable never loaded"}
+	f := filter.New(`
+	sequence
+	maxspan 100ms
+  	|kevt.name = 'CreateFile' and file.extension = '.exe'| by file.path
+  	!|kevt.name = 'LoadImage'| by image.path
+	`, &config.Config{Kstream: config.KstreamConfig{EnableFileIOKevents: true, EnableImageKevents: true}, Filters: &config.Filters{}})
+	require.NoError(t, f.Compile())
+
+	ss := newSequenceState(f, c, new(ps.SnapshotterMock))
+	matches := make(chan []*kevent.Kevent, 1)
+	ss.deadlineMatchFunc = func(evts ...*kevent.Kevent) { matches <- evts }
+
+	e1 := &kevent.Kevent{
+		Type:      ktypes.CreateFile,
+		Timestamp: time.Now(),
+		Name:      "CreateFile",
+		Tid:       2484,
+		PID:       859,
+		Category:  ktypes.File,
+		PS: &pstypes.PS{
+			Name: "cmd.exe",
+			Exe:  "C:\\Windows\\system32\\cmd.exe",
+		},
+		Kparams: kevent.Kparams{
+			kparams.FilePath: {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: "C:\\Temp\\dropper.exe"},
+		},
+		Metadata: make(map[kevent.MetadataKey]any),
+	}
+	e2 := &kevent.Kevent{
+		Type:      ktypes.LoadImage,
+		Timestamp: time.Now(),
+		Name:      "LoadImage",
+		Tid:       2484,
+		PID:       859,
+		Category:  ktypes.Image,
+		Kparams: kevent.Kparams{
+			kparams.ImagePath: {Name: kparams.ImagePath, Type: kparams.UnicodeString, Value: "C:\\Temp\\dropper.exe"},
+		},
+		Metadata: make(map[kevent.MetadataKey]any),
+	}
+
+	// the negated expression doesn't match within max span
+	require.False(t, ss.runSequence(e1))
+	select {
+	case evts := <-matches:
+		require.Len(t, evts, 1)
+		assert.Equal(t, e1, evts[0])
+	case <-time.After(time.Second):
+		t.Fatal("expected sequence match on deadline")
+	}
+	require.Equal(t, sequenceInitialState, ss.currentState())
+	assert.Len(t, ss.partials, 0)
+
+	// the negated expression matches and the sequence is cancelled
+	require.False(t, ss.runSequence(e1))
+	require.False(t, ss.runSequence(e2))
+	require.Equal(t, sequenceInitialState, ss.currentState())
+	assert.Len(t, ss.partials, 0)
+	select {
+	case <-matches:
+		t.Fatal("unexpected sequence match")
+	case <-time.After(time.Millisecond * 200):
+	}
+}
+
+func TestSequenceAbsenceInterleavedJoins(t *testing.T) {
+	log.SetLevel(log.DebugLevel)
+
+	c := &config.FilterConfig{Name: "Dropped executable never loaded"}
+	f := filter.New(`
+	sequence
+	maxspan 100ms
+  	|kevt.name = 'CreateFile' and file.extension = '.exe'| by file.path
+  	!|kevt.name = 'LoadImage'| by image.path
+	`, &config.Config{Kstream: config.KstreamConfig{EnableFileIOKevents: true, EnableImageKevents: true}, Filters: &config.Filters{}})
+	require.NoError(t, f.Compile())
+
+	ss := newSequenceState(f, c, new(ps.SnapshotterMock))
+	clk := newVirtualClock(time.Now())
+	ss.clock = clk
+	matches := make(chan []*kevent.Kevent, 2)
+	ss.deadlineMatchFunc = func(evts ...*kevent.Kevent) { matches <- evts }
+
+	createFile := func(path string) *kevent.Kevent {
+		return &kevent.Kevent{
+			Type:      ktypes.CreateFile,
+			Timestamp: clk.Now(),
+			Name:      "CreateFile",
+			Tid:       2484,
+			PID:       859,
+			Category:  ktypes.File,
+			Kparams: kevent.Kparams{
+				kparams.FilePath: {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: path},
+			},
+			Metadata: make(map[kevent.MetadataKey]any),
+		}
+	}
+	loadImage := func(path string) *kevent.Kevent {
+		return &kevent.Kevent{
+			Type:      ktypes.LoadImage,
+			Timestamp: clk.Now(),
+			Name:      "LoadImage",
+			Tid:       2484,
+			PID:       859,
+			Category:  ktypes.Image,
+			Kparams: kevent.Kparams{
+				kparams.ImagePath: {Name: kparams.ImagePath, Type: kparams.UnicodeString, Value: path},
+			},
+			Metadata: make(map[kevent.MetadataKey]any),
+		}
+	}
+	advance := func(d time.Duration) { clk.advance(clk.Now().Add(d)) }
+	requireNoMatch := func() {
+		select {
+		case evts := <-matches:
+			t.Fatalf("unexpected sequence match: %v", evts)
+		default:
+		}
+	}
+	requireMatch := func(e *kevent.Kevent) {
+		select {
+		case evts := <-matches:
+			require.Len(t, evts, 1)
+			assert.Equal(t, e, evts[0])
+		default:
+			t.Fatal("expected sequence match on deadline")
+		}
+	}
+
+	e1 := createFile("C:\\Temp\\dropper.exe")
+	require.False(t, ss.runSequence(e1))
+	advance(time.Millisecond * 60)
+	e2 := createFile("C:\\Temp\\payload.exe")
+	require.False(t, ss.runSequence(e2))
+	// the loaded image only refutes the absence for the dropper
+	require.False(t, ss.runSequence(loadImage("C:\\Temp\\dropper.exe")))
+	require.Equal(t, 1, ss.currentState())
+	require.Len(t, ss.partials[0], 1)
+	assert.Equal(t, e2, ss.partials[0][0])
+
+	// the max span of the dropper elapsed, but
+	// the payload still awaits its own deadline
+	advance(time.Millisecond * 50)
+	requireNoMatch()
+	require.Equal(t, 1, ss.currentState())
+
+	// the deadline of the payload is reached
+	advance(time.Millisecond * 60)
+	requireMatch(e2)
+	require.Equal(t, sequenceInitialState, ss.currentState())
+
+	// every partial whose absence is confirmed yields a match
+	e3 := createFile("C:\\Temp\\stage1.exe")
+	require.False(t, ss.runSequence(e3))
+	advance(time.Millisecond * 30)
+	e4 := createFile("C:\\Temp\\stage2.exe")
+	require.False(t, ss.runSequence(e4))
+
+	advance(time.Millisecond * 80)
+	requireMatch(e3)
+	require.Equal(t, 1, ss.currentState())
+	require.Len(t, ss.partials[0], 1)
+
+	advance(time.Millisecond * 30)
+	requireMatch(e4)
+	require.Equal(t, sequenceInitialState, ss.currentState())
+	requireNoMatch()
+}
+
 func TestComplexSequence(t *testing.T) {
 	log.SetLevel(log.DebugLevel)
 
@@ -867,70 +1039,6 @@ func TestSequenceBoundFields(t *testing.T) {
 	require.True(t, ss.runSequence(e4))
 }
 
-func TestSequenceBoundFieldsWithFunctions(t *testing.T) {
-	log.SetLevel(log.DebugLevel)
-
-	maxSequencePartialLifetime = time.Millisecond * 500
-
-	c := &config.FilterConfig{Name: "Command shell created a temp file with network outbound"}
-	f := filter.New(`
- 	sequence
-  maxspan 5m
-    |kevt.name = 'CreateFile' and file.path imatches '?:\\Windows\\System32\\*.dll'| as e1
-    |kevt.name = 'RegSetValue' and registry.path ~= 'HKEY_CURRENT_USER\\Volatile Environment\\Notification Packages' 
-			and 
-		 get_reg_value(registry.path) iin (base($e1.file.path, false))|
-	`, &config.Config{Kstream: config.KstreamConfig{EnableFileIOKevents: true, EnableRegistryKevents: true}, Filters: &config.Filters{}})
-	require.NoError(t, f.Compile())
-
-	ss := newSequenceState(f, c, new(ps.SnapshotterMock))
-
-	e1 := &kevent.Kevent{
-		Type:     ktypes.CreateFile,
-		Name:     "CreateFile",
-		Category: ktypes.File,
-		Tid:      2484,
-		PID:      859,
-		PS: &pstypes.PS{
-			Name: "cmd.exe",
-			Exe:  "C:\\Windows\\system32\\cmd.exe",
-		},
-		Kparams: kevent.Kparams{
-			kparams.FilePath: {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: "C:\\Windows\\System32\\passwdflt.dll"},
-		},
-		Metadata: map[kevent.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
-	}
-
-	e2 := &kevent.Kevent{
-		Type:     ktypes.RegSetValue,
-		Name:     "RegSetValue",
-		Category: ktypes.Registry,
-		Tid:      2484,
-		PID:      859,
-		PS: &pstypes.PS{
-			Name: "cmd.exe",
-			Exe:  "C:\\Windows\\system32\\cmd.exe",
-		},
-		Kparams: kevent.Kparams{
-			kparams.RegPath: {Name: kparams.RegPath, Type: kparams.UnicodeString, Value: "HKEY_CURRENT_USER\\Volatile Environment\\Notification Packages"},
-		},
-		Metadata: map[kevent.MetadataKey]any{"foo": "bar", "fooz": "barzz"},
-	}
-
-	key, err := registry.OpenKey(registry.CURRENT_USER, "Volatile Environment", registry.SET_VALUE)
-	require.NoError(t, err)
-	defer key.Close()
-
-	defer func() {
-		_ = key.DeleteValue("Notification Packages")
-	}()
-
-	require.NoError(t, key.SetStringsValue("Notification Packages", []string{"secli", "passwdflt"}))
-
-	require.False(t, ss.runSequence(e1))
-	require.True(t, ss.runSequence(e2))
-}
-
 func TestIsExpressionEvaluable(t *testing.T) {
 	log.SetLevel(log.DebugLevel)
 
@@ -0,0 +1,637 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sigma
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"net"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// detection converts the Sigma detection section to the filter expression.
+type detection struct {
+	c   *Conversion
+	cat category
+	// names contains search identifiers in declaration order
+	names    []string
+	searches map[string]*yaml.Node
+	exprs    map[string]string
+}
+
+func newDetection(c *Conversion, cat category) *detection {
+	return &detection{
+		c:        c,
+		cat:      cat,
+		names:    make([]string, 0),
+		searches: make(map[string]*yaml.Node),
+		exprs:    make(map[string]string),
+	}
+}
+
+func (d *detection) convert(n *yaml.Node) string {
+	if n.Kind != yaml.MappingNode {
+		d.c.unsupported("malformed detection section")
+		return ""
+	}
+	var cond *yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i].Value, n.Content[i+1]
+		switch key {
+		case "condition":
+			cond = val
+		case "timeframe":
+			d.c.unsupported("timeframe")
+		default:
+			d.names = append(d.names, key)
+			d.searches[key] = val
+		}
+	}
+	if cond == nil {
+		d.c.unsupported("detection without condition")
+		return ""
+	}
+
+	// multiple conditions are joined by the OR operator
+	conds := make([]string, 0)
+	switch cond.Kind {
+	case yaml.ScalarNode:
+		conds = append(conds, cond.Value)
+	case yaml.SequenceNode:
+		for _, c := range cond.Content {
+			conds = append(conds, c.Value)
+		}
+	}
+	exprs := make([]string, 0, len(conds))
+	for _, c := range conds {
+		p := &conditionParser{d: d, toks: tokenize(c)}
+		expr := p.parse()
+		if p.err != nil {
+			// unsupported constructs in search
+			// identifiers are already reported
+			if p.err != errEmptySearch {
+				d.c.unsupported("%v in condition: %s", p.err, c)
+			}
+			continue
+		}
+		exprs = append(exprs, expr)
+	}
+	return join(exprs, "or")
+}
+
+// search returns the filter expression for the search identifier.
+func (d *detection) search(name string) string {
+	if expr, ok := d.exprs[name]; ok {
+		return expr
+	}
+	var expr string
+	n := d.searches[name]
+	switch n.Kind {
+	case yaml.MappingNode:
+		expr = d.convertMap(n)
+	case yaml.SequenceNode:
+		exprs := make([]string, 0, len(n.Content))
+		for _, item := range n.Content {
+			if item.Kind != yaml.MappingNode {
+				d.c.unsupported("keyword search in %s", name)
+				break
+			}
+			exprs = append(exprs, d.convertMap(item))
+		}
+		expr = join(exprs, "or")
+	default:
+		d.c.unsupported("keyword search in %s", name)
+	}
+	d.exprs[name] = expr
+	return expr
+}
+
+// convertMap converts field/value pairs joined by the AND operator.
+func (d *detection) convertMap(n *yaml.Node) string {
+	exprs := make([]string, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		expr := d.convertField(n.Content[i].Value, n.Content[i+1])
+		if expr != "" {
+			exprs = append(exprs, expr)
+		}
+	}
+	return join(exprs, "and")
+}
+
+// modifiers contains field modifiers applied to field values
+type modifiers struct {
+	op    string
+	all   bool
+	cased bool
+	flags string
+}
+
+func (d *detection) parseModifiers(name string, mods []string) (modifiers, bool) {
+	var m modifiers
+	for _, mod := range mods {
+		switch mod {
+		case "contains", "startswith", "endswith", "re", "cidr", "gt", "gte", "lt", "lte":
+			if m.op != "" {
+				d.c.unsupported("%s modifier combined with %s modifier in %s field", mod, m.op, name)
+				return m, false
+			}
+			m.op = mod
+		case "all":
+			m.all = true
+		case "cased":
+			m.cased = true
+		case "i", "m", "s":
+			if m.op != "re" {
+				d.c.unsupported("%s modifier in %s field", mod, name)
+				return m, false
+			}
+			m.flags += mod
+		default:
+			d.c.unsupported("%s modifier in %s field", mod, name)
+			return m, false
+		}
+	}
+	return m, true
+}
+
+func (d *detection) convertField(key string, n *yaml.Node) string {
+	parts := strings.Split(key, "|")
+	name := parts[0]
+	f, ok := d.cat.fields[name]
+	if !ok {
+		d.c.unsupported("%s field", name)
+		return ""
+	}
+	mods, ok := d.parseModifiers(name, parts[1:])
+	if !ok {
+		return ""
+	}
+
+	var nodes []*yaml.Node
+	switch n.Kind {
+	case yaml.ScalarNode:
+		nodes = []*yaml.Node{n}
+	case yaml.SequenceNode:
+		nodes = n.Content
+	default:
+		d.c.unsupported("malformed value in %s field", name)
+		return ""
+	}
+	values := make([]string, 0, len(nodes))
+	for _, v := range nodes {
+		if v.Kind != yaml.ScalarNode || v.Tag == "!!null" {
+			d.c.unsupported("null or nested value in %s field", name)
+			return ""
+		}
+		values = append(values, v.Value)
+	}
+
+	var exprs []string
+	switch f.kind {
+	case initiatedField:
+		exprs = d.initiated(name, mods, values)
+	case numberField:
+		exprs = d.numbers(name, f, mods, values)
+	case ipField:
+		exprs = d.ips(name, f, mods, values)
+	default:
+		exprs = d.strings(name, f, mods, values)
+	}
+	if exprs == nil {
+		return ""
+	}
+	if mods.all {
+		return join(exprs, "and")
+	}
+	return join(exprs, "or")
+}
+
+func (d *detection) initiated(name string, mods modifiers, values []string) []string {
+	if mods.op != "" {
+		d.c.unsupported("%s modifier in %s field", mods.op, name)
+		return nil
+	}
+	exprs := make([]string, 0, len(values))
+	for _, v := range values {
+		initiated, err := strconv.ParseBool(v)
+		if err != nil {
+			d.c.unsupported("%q value in %s field", v, name)
+			return nil
+		}
+		if initiated {
+			exprs = append(exprs, "kevt.name = 'Connect'")
+		} else {
+			exprs = append(exprs, "kevt.name = 'Accept'")
+		}
+	}
+	return exprs
+}
+
+var numOps = map[string]string{"": "=", "gt": ">", "gte": ">=", "lt": "<", "lte": "<="}
+
+func (d *detection) numbers(name string, f field, mods modifiers, values []string) []string {
+	op, ok := numOps[mods.op]
+	if !ok {
+		d.c.unsupported("%s modifier in %s field", mods.op, name)
+		return nil
+	}
+	exprs := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, err := strconv.ParseUint(v, 10, 64); err != nil {
+			d.c.unsupported("%q value in %s field", v, name)
+			return nil
+		}
+		exprs = append(exprs, fmt.Sprintf("%s %s %s", f.name, op, v))
+	}
+	return exprs
+}
+
+func (d *detection) ips(name string, f field, mods modifiers, values []string) []string {
+	switch mods.op {
+	case "":
+		exprs := make([]string, 0, len(values))
+		for _, v := range values {
+			if net.ParseIP(v) == nil {
+				d.c.unsupported("%q value in %s field", v, name)
+				return nil
+			}
+			exprs = append(exprs, fmt.Sprintf("%s = %s", f.name, v))
+		}
+		return exprs
+	case "cidr":
+		for _, v := range values {
+			if _, _, err := net.ParseCIDR(v); err != nil {
+				d.c.unsupported("%q value in %s field", v, name)
+				return nil
+			}
+		}
+		if mods.all {
+			exprs := make([]string, 0, len(values))
+			for _, v := range values {
+				exprs = append(exprs, fmt.Sprintf("cidr_contains(%s, %s)", f.name, quote(v)))
+			}
+			return exprs
+		}
+		return []string{fmt.Sprintf("cidr_contains(%s, %s)", f.name, quoteAll(values))}
+	default:
+		d.c.unsupported("%s modifier in %s field", mods.op, name)
+		return nil
+	}
+}
+
+func (d *detection) strings(name string, f field, mods modifiers, values []string) []string {
+	switch mods.op {
+	case "re":
+		for _, v := range values {
+			if _, err := regexp.Compile(v); err != nil {
+				d.c.unsupported("%q regular expression in %s field: %v", v, name, err)
+				return nil
+			}
+		}
+		patterns := values
+		if mods.flags != "" {
+			patterns = make([]string, len(values))
+			for i, v := range values {
+				patterns[i] = "(?" + mods.flags + ")" + v
+			}
+		}
+		if mods.all {
+			exprs := make([]string, 0, len(patterns))
+			for _, p := range patterns {
+				exprs = append(exprs, fmt.Sprintf("regex(%s, %s)", f.name, quote(p)))
+			}
+			return exprs
+		}
+		return []string{fmt.Sprintf("regex(%s, %s)", f.name, quoteAll(patterns))}
+	case "", "contains", "startswith", "endswith":
+	default:
+		d.c.unsupported("%s modifier in %s field", mods.op, name)
+		return nil
+	}
+
+	type operand struct{ op, val string }
+	operands := make([]operand, 0, len(values))
+	for _, v := range values {
+		if f.transform != nil {
+			v = f.transform(v)
+		}
+		if f.name == "registry.value" && registryDataRegexp.MatchString(v) {
+			d.c.unsupported("%q value in %s field", v, name)
+			return nil
+		}
+		op, val, ok := stringOp(v, mods, f.list)
+		if !ok {
+			d.c.unsupported("escaped wildcard in %q value of %s field", v, name)
+			return nil
+		}
+		operands = append(operands, operand{op, val})
+	}
+
+	if mods.all {
+		exprs := make([]string, 0, len(operands))
+		for _, o := range operands {
+			exprs = append(exprs, binaryExpr(f.name, o.op, []string{o.val}))
+		}
+		return exprs
+	}
+
+	// group values by operator to produce
+	// compact expressions with value lists
+	ops := make([]string, 0)
+	groups := make(map[string][]string)
+	for _, o := range operands {
+		if _, ok := groups[o.op]; !ok {
+			ops = append(ops, o.op)
+		}
+		groups[o.op] = append(groups[o.op], o.val)
+	}
+	exprs := make([]string, 0, len(ops))
+	for _, op := range ops {
+		vals := groups[op]
+		if len(vals) > 1 {
+			// equality operators don't accept lists
+			switch op {
+			case "~=":
+				op = "iin"
+			case "=":
+				op = "in"
+			}
+		}
+		exprs = append(exprs, binaryExpr(f.name, op, vals))
+	}
+	return exprs
+}
+
+func binaryExpr(name, op string, vals []string) string {
+	if len(vals) == 1 && op != "in" && op != "iin" {
+		return fmt.Sprintf("%s %s %s", name, op, quote(vals[0]))
+	}
+	return fmt.Sprintf("%s %s (%s)", name, op, quoteAll(vals))
+}
+
+// stringOp resolves the filter operator and the operand for the
+// Sigma string value. Values with wildcards are converted to the
+// glob pattern matched by the matches operator. Returns false if
+// the value contains both escaped and unescaped wildcards, as the
+// matches operator can't express literal wildcard characters.
+func stringOp(v string, mods modifiers, list bool) (string, string, bool) {
+	s, wildcard, escaped := unescape(v)
+	i := func(op string) string {
+		if mods.cased {
+			return op
+		}
+		return "i" + op
+	}
+	if wildcard {
+		if escaped {
+			return "", "", false
+		}
+		switch mods.op {
+		case "contains":
+			s = "*" + s + "*"
+		case "startswith":
+			s = s + "*"
+		case "endswith":
+			s = "*" + s
+		}
+		return i("matches"), s, true
+	}
+	switch mods.op {
+	case "contains", "startswith", "endswith":
+		return i(mods.op), s, true
+	}
+	if list {
+		return i("in"), s, true
+	}
+	if mods.cased {
+		return "=", s, true
+	}
+	return "~=", s, true
+}
+
+// unescape resolves Sigma escape sequences. The backslash escapes
+// wildcards and the backslash itself. Otherwise, the backslash is
+// interpreted literally. It reports whether the string contains
+// unescaped and escaped wildcards.
+func unescape(v string) (string, bool, bool) {
+	var (
+		b        strings.Builder
+		wildcard bool
+		escaped  bool
+	)
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case c == '\\' && i+1 < len(v) && (v[i+1] == '*' || v[i+1] == '?' || v[i+1] == '\\'):
+			if v[i+1] != '\\' {
+				escaped = true
+			}
+			b.WriteByte(v[i+1])
+			i++
+		case c == '*' || c == '?':
+			wildcard = true
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String(), wildcard, escaped
+}
+
+// quote produces the filter string literal.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = quote(v)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// join joins expressions with the logical operator. Compound
+// expressions are enclosed in parentheses.
+func join(exprs []string, op string) string {
+	switch len(exprs) {
+	case 0:
+		return ""
+	case 1:
+		return exprs[0]
+	default:
+		return "(" + strings.Join(exprs, " "+op+" ") + ")"
+	}
+}
+
+// conditionParser parses the Sigma condition and
+// translates search identifiers to filter expressions.
+// The NOT operator has the highest precedence, followed
+// by the AND operator, and the OR operator.
+type conditionParser struct {
+	d    *detection
+	toks []string
+	pos  int
+	err  error
+}
+
+// errEmptySearch signals the search identifier couldn't be converted
+var errEmptySearch = errors.New("empty search")
+
+var tokenRegexp = regexp.MustCompile(`\(|\)|\||[^\s()|]+`)
+
+func tokenize(s string) []string {
+	return tokenRegexp.FindAllString(s, -1)
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() string {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+func (p *conditionParser) fail(format string, args ...any) string {
+	if p.err == nil {
+		p.err = fmt.Errorf(format, args...)
+	}
+	return ""
+}
+
+func (p *conditionParser) parse() string {
+	for _, tok := range p.toks {
+		if tok == "|" {
+			return p.fail("aggregation expression")
+		}
+	}
+	expr := p.parseOr()
+	if p.err == nil && p.pos < len(p.toks) {
+		return p.fail("unexpected %q token", p.peek())
+	}
+	return expr
+}
+
+func (p *conditionParser) parseOr() string {
+	exprs := []string{p.parseAnd()}
+	for strings.ToLower(p.peek()) == "or" {
+		p.next()
+		exprs = append(exprs, p.parseAnd())
+	}
+	return join(exprs, "or")
+}
+
+func (p *conditionParser) parseAnd() string {
+	exprs := []string{p.parseNot()}
+	for strings.ToLower(p.peek()) == "and" {
+		p.next()
+		exprs = append(exprs, p.parseNot())
+	}
+	return join(exprs, "and")
+}
+
+func (p *conditionParser) parseNot() string {
+	if strings.ToLower(p.peek()) == "not" {
+		p.next()
+		expr := p.parseNot()
+		if expr == "" {
+			return ""
+		}
+		if !strings.HasPrefix(expr, "(") {
+			expr = "(" + expr + ")"
+		}
+		return "not " + expr
+	}
+	return p.parsePrimary()
+}
+
+func (p *conditionParser) parsePrimary() string {
+	tok := p.next()
+	switch tok {
+	case "":
+		return p.fail("unexpected end")
+	case "(":
+		expr := p.parseOr()
+		if p.next() != ")" {
+			return p.fail("unbalanced parentheses")
+		}
+		return expr
+	case ")":
+		return p.fail("unexpected %q token", tok)
+	}
+
+	if strings.ToLower(p.peek()) == "of" {
+		p.next()
+		return p.parseQuantifier(tok, p.next())
+	}
+
+	if _, ok := p.d.searches[tok]; !ok {
+		return p.fail("undefined %s search identifier", tok)
+	}
+	expr := p.d.search(tok)
+	if expr == "" && p.err == nil {
+		p.err = errEmptySearch
+	}
+	return expr
+}
+
+// parseQuantifier parses the `1 of` and `all of` expressions
+// applied to search identifiers matching the pattern.
+func (p *conditionParser) parseQuantifier(quantifier, pattern string) string {
+	var op string
+	switch strings.ToLower(quantifier) {
+	case "1", "any":
+		op = "or"
+	case "all":
+		op = "and"
+	default:
+		return p.fail("%s of quantifier", quantifier)
+	}
+	exprs := make([]string, 0)
+	for _, name := range p.d.names {
+		if pattern != "them" {
+			if ok, _ := path.Match(pattern, name); !ok {
+				continue
+			}
+		}
+		expr := p.d.search(name)
+		if expr == "" {
+			if p.err == nil {
+				p.err = errEmptySearch
+			}
+			return ""
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) == 0 {
+		return p.fail("no search identifiers matching %s", pattern)
+	}
+	return join(exprs, op)
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sigma
+
+import (
+	"regexp"
+	"strings"
+)
+
+// fieldKind determines how field values are compared
+type fieldKind uint8
+
+const (
+	stringField fieldKind = iota
+	numberField
+	ipField
+	// initiatedField is the network connection direction
+	// field that translates to the event name
+	initiatedField
+)
+
+// field describes the filter field mapped from the Sigma field.
+type field struct {
+	name string
+	kind fieldKind
+	// list indicates the field yields a list of strings
+	list bool
+	// transform converts the Sigma value to the value
+	// understood by the filter field
+	transform func(string) string
+}
+
+// category describes the Sigma log source category.
+type category struct {
+	// condition is the filter expression that
+	// scopes the rule to the category events
+	condition string
+	fields    map[string]field
+}
+
+var regKeyPrefixes = []struct{ short, long string }{
+	{`HKLM\`, `HKEY_LOCAL_MACHINE\`},
+	{`HKU\`, `HKEY_USERS\`},
+	{`HKCU\`, `HKEY_CURRENT_USER\`},
+	{`HKCR\`, `HKEY_CLASSES_ROOT\`},
+}
+
+// expandRegKey expands abbreviated root keys used in Sigma rules
+func expandRegKey(s string) string {
+	for _, p := range regKeyPrefixes {
+		if len(s) >= len(p.short) && strings.EqualFold(s[:len(p.short)], p.short) {
+			return p.long + s[len(p.short):]
+		}
+	}
+	return s
+}
+
+var categories = map[string]category{
+	"process_creation": {
+		condition: "kevt.name = 'CreateProcess'",
+		fields: map[string]field{
+			"Image":             {name: "ps.child.exe"},
+			"OriginalFileName":  {name: "ps.child.pe.file.name"},
+			"CommandLine":       {name: "ps.child.cmdline"},
+			"ProcessId":         {name: "ps.child.pid", kind: numberField},
+			"ParentImage":       {name: "ps.exe"},
+			"ParentCommandLine": {name: "ps.cmdline"},
+			"ParentProcessId":   {name: "ps.pid", kind: numberField},
+		},
+	},
+	"file_event": {
+		condition: "kevt.name = 'CreateFile' and file.operation != 'OPEN'",
+		fields: map[string]field{
+			"TargetFilename": {name: "file.path"},
+			"Image":          {name: "ps.exe"},
+			"CommandLine":    {name: "ps.cmdline"},
+			"ProcessId":      {name: "ps.pid", kind: numberField},
+		},
+	},
+	"registry_set": {
+		condition: "kevt.name = 'RegSetValue'",
+		fields: map[string]field{
+			"TargetObject": {name: "registry.path", transform: expandRegKey},
+			"Details":      {name: "registry.value"},
+			"Image":        {name: "ps.exe"},
+			"ProcessId":    {name: "ps.pid", kind: numberField},
+		},
+	},
+	"network_connection": {
+		condition: "kevt.name in ('Connect', 'Accept')",
+		fields: map[string]field{
+			"Initiated":           {name: "kevt.name", kind: initiatedField},
+			"DestinationIp":       {name: "net.dip", kind: ipField},
+			"DestinationPort":     {name: "net.dport", kind: numberField},
+			"DestinationHostname": {name: "net.dip.names", list: true},
+			"SourceIp":            {name: "net.sip", kind: ipField},
+			"SourcePort":          {name: "net.sport", kind: numberField},
+			"SourceHostname":      {name: "net.sip.names", list: true},
+			"Protocol":            {name: "net.l4.proto"},
+			"Image":               {name: "ps.exe"},
+			"ProcessId":           {name: "ps.pid", kind: numberField},
+		},
+	},
+	"image_load": {
+		condition: "kevt.name = 'LoadImage'",
+		fields: map[string]field{
+			"ImageLoaded": {name: "image.path"},
+			"Image":       {name: "ps.exe"},
+			"ProcessId":   {name: "ps.pid", kind: numberField},
+		},
+	},
+	"dns_query": {
+		condition: "kevt.name = 'QueryDns'",
+		fields: map[string]field{
+			"QueryName":    {name: "dns.name"},
+			"QueryResults": {name: "dns.answers", list: true},
+			"Image":        {name: "ps.exe"},
+			"ProcessId":    {name: "ps.pid", kind: numberField},
+		},
+	},
+}
+
+// registryDataRegexp matches Sysmon registry value data descriptors that
+// have no equivalent representation in the registry value filter field
+var registryDataRegexp = regexp.MustCompile(`(?i)^(DWORD|QWORD|Binary Data)\b`)
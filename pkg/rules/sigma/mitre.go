@@ -0,0 +1,294 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sigma
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// tactics maps Sigma tactic tags to MITRE tactic identifiers and names
+var tactics = map[string]struct{ id, name string }{
+	"reconnaissance":       {"TA0043", "Reconnaissance"},
+	"resource_development": {"TA0042", "Resource Development"},
+	"initial_access":       {"TA0001", "Initial Access"},
+	"execution":            {"TA0002", "Execution"},
+	"persistence":          {"TA0003", "Persistence"},
+	"privilege_escalation": {"TA0004", "Privilege Escalation"},
+	"defense_evasion":      {"TA0005", "Defense Evasion"},
+	"credential_access":    {"TA0006", "Credential Access"},
+	"discovery":            {"TA0007", "Discovery"},
+	"lateral_movement":     {"TA0008", "Lateral Movement"},
+	"collection":           {"TA0009", "Collection"},
+	"exfiltration":         {"TA0010", "Exfiltration"},
+	"command_and_control":  {"TA0011", "Command and Control"},
+	"impact":               {"TA0040", "Impact"},
+}
+
+// techniques maps MITRE technique and sub-technique identifiers to their names
+var techniques = map[string]string{
+	"T1003":     "OS Credential Dumping",
+	"T1003.001": "LSASS Memory",
+	"T1003.002": "Security Account Manager",
+	"T1003.003": "NTDS",
+	"T1003.004": "LSA Secrets",
+	"T1003.005": "Cached Domain Credentials",
+	"T1003.006": "DCSync",
+	"T1005":     "Data from Local System",
+	"T1007":     "System Service Discovery",
+	"T1012":     "Query Registry",
+	"T1016":     "System Network Configuration Discovery",
+	"T1018":     "Remote System Discovery",
+	"T1021":     "Remote Services",
+	"T1021.001": "Remote Desktop Protocol",
+	"T1021.002": "SMB/Windows Admin Shares",
+	"T1021.003": "Distributed Component Object Model",
+	"T1021.006": "Windows Remote Management",
+	"T1027":     "Obfuscated Files or Information",
+	"T1033":     "System Owner/User Discovery",
+	"T1036":     "Masquerading",
+	"T1036.003": "Rename System Utilities",
+	"T1036.005": "Match Legitimate Name or Location",
+	"T1037":     "Boot or Logon Initialization Scripts",
+	"T1040":     "Network Sniffing",
+	"T1046":     "Network Service Discovery",
+	"T1047":     "Windows Management Instrumentation",
+	"T1048":     "Exfiltration Over Alternative Protocol",
+	"T1049":     "System Network Connections Discovery",
+	"T1053":     "Scheduled Task/Job",
+	"T1053.005": "Scheduled Task",
+	"T1055":     "Process Injection",
+	"T1055.001": "Dynamic-link Library Injection",
+	"T1055.002": "Portable Executable Injection",
+	"T1055.003": "Thread Execution Hijacking",
+	"T1055.004": "Asynchronous Procedure Call",
+	"T1055.012": "Process Hollowing",
+	"T1055.013": "Process Doppelganging",
+	"T1057":     "Process Discovery",
+	"T1059":     "Command and Scripting Interpreter",
+	"T1059.001": "PowerShell",
+	"T1059.003": "Windows Command Shell",
+	"T1059.005": "Visual Basic",
+	"T1059.006": "Python",
+	"T1059.007": "JavaScript",
+	"T1068":     "Exploitation for Privilege Escalation",
+	"T1069":     "Permission Groups Discovery",
+	"T1070":     "Indicator Removal",
+	"T1070.001": "Clear Windows Event Logs",
+	"T1070.004": "File Deletion",
+	"T1070.006": "Timestomp",
+	"T1071":     "Application Layer Protocol",
+	"T1071.001": "Web Protocols",
+	"T1071.004": "DNS",
+	"T1072":     "Software Deployment Tools",
+	"T1078":     "Valid Accounts",
+	"T1082":     "System Information Discovery",
+	"T1083":     "File and Directory Discovery",
+	"T1087":     "Account Discovery",
+	"T1090":     "Proxy",
+	"T1095":     "Non-Application Layer Protocol",
+	"T1098":     "Account Manipulation",
+	"T1105":     "Ingress Tool Transfer",
+	"T1106":     "Native API",
+	"T1112":     "Modify Registry",
+	"T1113":     "Screen Capture",
+	"T1114":     "Email Collection",
+	"T1115":     "Clipboard Data",
+	"T1119":     "Automated Collection",
+	"T1120":     "Peripheral Device Discovery",
+	"T1123":     "Audio Capture",
+	"T1127":     "Trusted Developer Utilities Proxy Execution",
+	"T1127.001": "MSBuild",
+	"T1132":     "Data Encoding",
+	"T1133":     "External Remote Services",
+	"T1134":     "Access Token Manipulation",
+	"T1135":     "Network Share Discovery",
+	"T1136":     "Create Account",
+	"T1136.001": "Local Account",
+	"T1137":     "Office Application Startup",
+	"T1137.001": "Office Template Macros",
+	"T1140":     "Deobfuscate/Decode Files or Information",
+	"T1176":     "Browser Extensions",
+	"T1190":     "Exploit Public-Facing Application",
+	"T1195":     "Supply Chain Compromise",
+	"T1197":     "BITS Jobs",
+	"T1201":     "Password Policy Discovery",
+	"T1202":     "Indirect Command Execution",
+	"T1203":     "Exploitation for Client Execution",
+	"T1204":     "User Execution",
+	"T1204.002": "Malicious File",
+	"T1207":     "Rogue Domain Controller",
+	"T1210":     "Exploitation of Remote Services",
+	"T1211":     "Exploitation for Defense Evasion",
+	"T1212":     "Exploitation for Credential Access",
+	"T1216":     "System Script Proxy Execution",
+	"T1217":     "Browser Information Discovery",
+	"T1218":     "System Binary Proxy Execution",
+	"T1218.001": "Compiled HTML File",
+	"T1218.002": "Control Panel",
+	"T1218.003": "CMSTP",
+	"T1218.005": "Mshta",
+	"T1218.007": "Msiexec",
+	"T1218.009": "Regsvcs/Regasm",
+	"T1218.010": "Regsvr32",
+	"T1218.011": "Rundll32",
+	"T1219":     "Remote Access Software",
+	"T1220":     "XSL Script Processing",
+	"T1222":     "File and Directory Permissions Modification",
+	"T1482":     "Domain Trust Discovery",
+	"T1485":     "Data Destruction",
+	"T1486":     "Data Encrypted for Impact",
+	"T1489":     "Service Stop",
+	"T1490":     "Inhibit System Recovery",
+	"T1497":     "Virtualization/Sandbox Evasion",
+	"T1505":     "Server Software Component",
+	"T1505.003": "Web Shell",
+	"T1518":     "Software Discovery",
+	"T1529":     "System Shutdown/Reboot",
+	"T1543":     "Create or Modify System Process",
+	"T1543.003": "Windows Service",
+	"T1546":     "Event Triggered Execution",
+	"T1546.003": "Windows Management Instrumentation Event Subscription",
+	"T1546.008": "Accessibility Features",
+	"T1546.011": "Application Shimming",
+	"T1546.012": "Image File Execution Options Injection",
+	"T1546.015": "Component Object Model Hijacking",
+	"T1547":     "Boot or Logon Autostart Execution",
+	"T1547.001": "Registry Run Keys / Startup Folder",
+	"T1547.004": "Winlogon Helper DLL",
+	"T1547.005": "Security Support Provider",
+	"T1547.009": "Shortcut Modification",
+	"T1547.010": "Port Monitors",
+	"T1548":     "Abuse Elevation Control Mechanism",
+	"T1548.002": "Bypass User Account Control",
+	"T1550":     "Use Alternate Authentication Material",
+	"T1550.002": "Pass the Hash",
+	"T1550.003": "Pass the Ticket",
+	"T1552":     "Unsecured Credentials",
+	"T1552.001": "Credentials In Files",
+	"T1552.002": "Credentials in Registry",
+	"T1553":     "Subvert Trust Controls",
+	"T1553.004": "Install Root Certificate",
+	"T1553.005": "Mark-of-the-Web Bypass",
+	"T1555":     "Credentials from Password Stores",
+	"T1555.003": "Credentials from Web Browsers",
+	"T1555.004": "Windows Credential Manager",
+	"T1556":     "Modify Authentication Process",
+	"T1557":     "Adversary-in-the-Middle",
+	"T1558":     "Steal or Forge Kerberos Tickets",
+	"T1558.003": "Kerberoasting",
+	"T1559":     "Inter-Process Communication",
+	"T1559.001": "Component Object Model",
+	"T1560":     "Archive Collected Data",
+	"T1560.001": "Archive via Utility",
+	"T1562":     "Impair Defenses",
+	"T1562.001": "Disable or Modify Tools",
+	"T1562.002": "Disable Windows Event Logging",
+	"T1562.004": "Disable or Modify System Firewall",
+	"T1564":     "Hide Artifacts",
+	"T1564.001": "Hidden Files and Directories",
+	"T1564.004": "NTFS File Attributes",
+	"T1566":     "Phishing",
+	"T1566.001": "Spearphishing Attachment",
+	"T1566.002": "Spearphishing Link",
+	"T1567":     "Exfiltration Over Web Service",
+	"T1569":     "System Services",
+	"T1569.002": "Service Execution",
+	"T1570":     "Lateral Tool Transfer",
+	"T1571":     "Non-Standard Port",
+	"T1572":     "Protocol Tunneling",
+	"T1573":     "Encrypted Channel",
+	"T1574":     "Hijack Execution Flow",
+	"T1574.001": "DLL Search Order Hijacking",
+	"T1574.002": "DLL Side-Loading",
+	"T1574.011": "Services Registry Permissions Weakness",
+	"T1574.012": "COR_PROFILER",
+	"T1574.014": "AppDomainManager",
+	"T1614":     "System Location Discovery",
+	"T1620":     "Reflective Code Loading",
+}
+
+var techniqueRegexp = regexp.MustCompile(`^t(\d{4})(?:\.(\d{3}))?$`)
+
+// convertTags converts MITRE ATT&CK tags to tactic and technique labels.
+// Labels can only describe a single tactic and technique, so additional
+// MITRE tags are retained as rule tags, along with non-MITRE tags.
+func convertTags(c *Conversion, tags []string) (map[string]string, []string) {
+	labels := make(map[string]string)
+	rest := make([]string, 0)
+
+	for _, tag := range tags {
+		tag = strings.ToLower(tag)
+		name, ok := strings.CutPrefix(tag, "attack.")
+		if !ok {
+			rest = append(rest, tag)
+			continue
+		}
+		if tactic, ok := tactics[strings.ReplaceAll(name, "-", "_")]; ok {
+			if labels["tactic.id"] != "" {
+				c.warn("only the first tactic is converted to labels. %s is retained as tag", tag)
+				rest = append(rest, tag)
+				continue
+			}
+			labels["tactic.id"] = tactic.id
+			labels["tactic.name"] = tactic.name
+			labels["tactic.ref"] = fmt.Sprintf("https://attack.mitre.org/tactics/%s/", tactic.id)
+			continue
+		}
+		m := techniqueRegexp.FindStringSubmatch(name)
+		if m == nil {
+			// groups, software or other MITRE tags
+			rest = append(rest, tag)
+			continue
+		}
+		if labels["technique.id"] != "" {
+			c.warn("only the first technique is converted to labels. %s is retained as tag", tag)
+			rest = append(rest, tag)
+			continue
+		}
+		id := "T" + m[1]
+		labels["technique.id"] = id
+		labels["technique.ref"] = fmt.Sprintf("https://attack.mitre.org/techniques/%s/", id)
+		if n, ok := techniques[id]; ok {
+			labels["technique.name"] = n
+		} else {
+			c.warn("unknown name for the %s technique", id)
+		}
+		if m[2] != "" {
+			subid := id + "." + m[2]
+			labels["subtechnique.id"] = subid
+			labels["subtechnique.ref"] = fmt.Sprintf("https://attack.mitre.org/techniques/%s/%s/", id, m[2])
+			if n, ok := techniques[subid]; ok {
+				labels["subtechnique.name"] = n
+			} else {
+				c.warn("unknown name for the %s sub-technique", subid)
+			}
+		}
+	}
+
+	if labels["tactic.id"] == "" {
+		c.warn("no MITRE tactic tag")
+	}
+	if labels["technique.id"] == "" {
+		c.warn("no MITRE technique tag")
+	}
+
+	return labels, rest
+}
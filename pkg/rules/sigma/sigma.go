@@ -0,0 +1,300 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sigma
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// minEngineVersion is the engine version required by the converted rules
+const minEngineVersion = "2.0.0"
+
+// ruleVersion is the initial version of the converted rules
+const ruleVersion = "1.0.0"
+
+// severities maps Sigma levels to rule severities
+var severities = map[string]string{
+	"informational": "low",
+	"low":           "low",
+	"medium":        "medium",
+	"high":          "high",
+	"critical":      "critical",
+}
+
+var uuidRegexp = regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$")
+
+// sigmaRule represents the subset of the Sigma rule specification
+// relevant for the conversion.
+type sigmaRule struct {
+	Title          string    `yaml:"title"`
+	ID             string    `yaml:"id"`
+	Status         string    `yaml:"status"`
+	Description    string    `yaml:"description"`
+	References     []string  `yaml:"references"`
+	Author         string    `yaml:"author"`
+	Tags           []string  `yaml:"tags"`
+	Logsource      logsource `yaml:"logsource"`
+	Detection      yaml.Node `yaml:"detection"`
+	FalsePositives []string  `yaml:"falsepositives"`
+	Level          string    `yaml:"level"`
+	Action         string    `yaml:"action"`
+}
+
+type logsource struct {
+	Category string `yaml:"category"`
+	Product  string `yaml:"product"`
+	Service  string `yaml:"service"`
+}
+
+// Rule is the rule converted from the Sigma rule.
+type Rule struct {
+	Name             string
+	ID               string
+	Description      string
+	Labels           map[string]string
+	Tags             []string
+	References       []string
+	Condition        string
+	Severity         string
+	Notes            string
+	MinEngineVersion string
+}
+
+// Conversion is the outcome of converting a single Sigma rule.
+type Conversion struct {
+	// Title is the title of the Sigma rule.
+	Title string
+	// Rule is the converted rule. It is nil if the Sigma
+	// rule contains unsupported constructs.
+	Rule *Rule
+	// Unsupported contains the constructs that prevented the conversion.
+	Unsupported []string
+	// Warnings contains the issues that didn't prevent the conversion,
+	// but may require manual intervention.
+	Warnings []string
+}
+
+// IsConverted determines if the Sigma rule was successfully converted.
+func (c *Conversion) IsConverted() bool { return c.Rule != nil }
+
+func (c *Conversion) unsupported(format string, args ...any) {
+	c.Unsupported = append(c.Unsupported, fmt.Sprintf(format, args...))
+}
+
+func (c *Conversion) warn(format string, args ...any) {
+	c.Warnings = append(c.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Convert converts the Sigma rule to the native rule. An error is
+// returned if the rule is not a well-formed Sigma document. Sigma
+// constructs that can't be converted are reported in the resulting
+// conversion.
+func Convert(b []byte) (*Conversion, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	var r sigmaRule
+	if err := dec.Decode(&r); err != nil {
+		return nil, fmt.Errorf("invalid Sigma rule: %v", err)
+	}
+	if r.Title == "" {
+		return nil, fmt.Errorf("invalid Sigma rule: missing title")
+	}
+
+	c := &Conversion{Title: r.Title}
+
+	// rule collections are not supported
+	var next any
+	if r.Action != "" || dec.Decode(&next) != io.EOF {
+		c.unsupported("rule collections")
+	}
+	if r.Logsource.Product != "" && r.Logsource.Product != "windows" {
+		c.unsupported("%s log source product", r.Logsource.Product)
+	}
+	if r.Status == "deprecated" || r.Status == "unsupported" {
+		c.unsupported("%s rule status", r.Status)
+	}
+
+	cat, ok := categories[r.Logsource.Category]
+	if !ok {
+		switch {
+		case r.Logsource.Category != "":
+			c.unsupported("%s log source category", r.Logsource.Category)
+		case r.Logsource.Service != "":
+			c.unsupported("%s log source service", r.Logsource.Service)
+		default:
+			c.unsupported("log source without category")
+		}
+		return c, nil
+	}
+
+	expr := newDetection(c, cat).convert(&r.Detection)
+
+	rule := &Rule{
+		Name:             r.Title,
+		ID:               r.ID,
+		Description:      r.Description,
+		References:       r.References,
+		Severity:         severities[r.Level],
+		MinEngineVersion: minEngineVersion,
+	}
+	if !uuidRegexp.MatchString(rule.ID) {
+		rule.ID = uuid.New().String()
+	}
+	if r.Level != "" && rule.Severity == "" {
+		c.warn("unknown %s level", r.Level)
+	}
+	rule.Condition = cat.condition
+	if expr != "" {
+		rule.Condition += " and " + expr
+	}
+	rule.Labels, rule.Tags = convertTags(c, r.Tags)
+	rule.Notes = notes(r)
+
+	for _, s := range []string{rule.Name, rule.Description, rule.Condition, rule.Notes} {
+		// rule files are rendered as Go templates
+		if strings.Contains(s, "{{") || strings.Contains(s, "}}") {
+			c.unsupported("template delimiters in rule attributes")
+			break
+		}
+	}
+
+	if len(c.Unsupported) == 0 {
+		c.Rule = rule
+	}
+
+	return c, nil
+}
+
+func notes(r sigmaRule) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Converted from the Sigma rule")
+	if r.ID != "" {
+		fmt.Fprintf(&b, " %s", r.ID)
+	}
+	if r.Author != "" {
+		fmt.Fprintf(&b, " authored by %s", r.Author)
+	}
+	b.WriteString(".\n")
+	if len(r.FalsePositives) > 0 {
+		b.WriteString("\nFalse positives:\n")
+		for _, fp := range r.FalsePositives {
+			fmt.Fprintf(&b, "- %s\n", fp)
+		}
+	}
+	return b.String()
+}
+
+// Marshal renders the rule in the YAML format.
+func (r *Rule) Marshal() ([]byte, error) {
+	doc := &yaml.Node{Kind: yaml.MappingNode}
+
+	add := func(key string, val *yaml.Node) {
+		doc.Content = append(doc.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, val)
+	}
+	str := func(s string, style yaml.Style) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s, Style: style}
+	}
+	seq := func(items []string) *yaml.Node {
+		n := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range items {
+			n.Content = append(n.Content, str(item, 0))
+		}
+		return n
+	}
+
+	add("name", str(r.Name, 0))
+	add("id", str(r.ID, 0))
+	add("version", str(ruleVersion, 0))
+	if r.Description != "" {
+		add("description", str(strings.TrimSpace(r.Description)+"\n", yaml.LiteralStyle))
+	}
+	if len(r.Labels) > 0 {
+		labels := &yaml.Node{Kind: yaml.MappingNode}
+		for _, k := range labelKeys(r.Labels) {
+			labels.Content = append(labels.Content, str(k, 0), str(r.Labels[k], 0))
+		}
+		add("labels", labels)
+	}
+	if len(r.Tags) > 0 {
+		add("tags", seq(r.Tags))
+	}
+	if len(r.References) > 0 {
+		add("references", seq(r.References))
+	}
+	add("condition", str(r.Condition, yaml.FoldedStyle))
+	if r.Severity != "" {
+		add("severity", str(r.Severity, 0))
+	}
+	if r.Notes != "" {
+		add("notes", str(r.Notes, yaml.LiteralStyle))
+	}
+	add("min-engine-version", str(r.MinEngineVersion, 0))
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// labelKeys returns label keys with MITRE tactic
+// labels first, followed by technique labels.
+func labelKeys(labels map[string]string) []string {
+	rank := func(k string) int {
+		switch {
+		case strings.HasPrefix(k, "tactic."):
+			return 0
+		case strings.HasPrefix(k, "technique."):
+			return 1
+		case strings.HasPrefix(k, "subtechnique."):
+			return 2
+		default:
+			return 3
+		}
+	}
+	order := map[string]int{"id": 0, "name": 1, "ref": 2}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		ri, rj := rank(keys[i]), rank(keys[j])
+		if ri != rj {
+			return ri < rj
+		}
+		oi, iok := order[keys[i][strings.LastIndexByte(keys[i], '.')+1:]]
+		oj, jok := order[keys[j][strings.LastIndexByte(keys[j], '.')+1:]]
+		if iok && jok && oi != oj {
+			return oi < oj
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
@@ -0,0 +1,303 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sigma
+
+import (
+	"os"
+	"testing"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func convertFile(t *testing.T, file string) *Conversion {
+	b, err := os.ReadFile(file)
+	require.NoError(t, err)
+	c, err := Convert(b)
+	require.NoError(t, err)
+	if c.IsConverted() {
+		requireCompiles(t, c.Rule.Condition)
+	}
+	return c
+}
+
+// requireCompiles ensures the converted condition is a valid filter expression.
+func requireCompiles(t *testing.T, expr string) {
+	f := filter.New(expr, &config.Config{Kstream: config.KstreamConfig{}, Filters: &config.Filters{}})
+	require.NoError(t, f.Compile(), expr)
+}
+
+func TestConvert(t *testing.T) {
+	c := convertFile(t, "_fixtures/proc_creation_win_susp_whoami.yml")
+	require.True(t, c.IsConverted())
+	assert.Empty(t, c.Unsupported)
+	assert.Empty(t, c.Warnings)
+
+	r := c.Rule
+	assert.Equal(t, "Suspicious Whoami Execution From Office Application", r.Name)
+	assert.Equal(t, "8e5f2a1b-3c4d-4e6f-9a0b-1c2d3e4f5a6b", r.ID)
+	assert.Equal(t, "high", r.Severity)
+	assert.Equal(t, `kevt.name = 'CreateProcess' and (((ps.child.exe iendswith '\\whoami.exe' or ps.child.pe.file.name ~= 'whoami.exe') and ps.exe iendswith ('\\WINWORD.EXE', '\\EXCEL.EXE')) and not (ps.child.cmdline icontains '/groups' and ps.child.cmdline icontains '/fo csv'))`, r.Condition)
+	assert.Equal(t, map[string]string{
+		"tactic.id":      "TA0007",
+		"tactic.name":    "Discovery",
+		"tactic.ref":     "https://attack.mitre.org/tactics/TA0007/",
+		"technique.id":   "T1033",
+		"technique.name": "System Owner/User Discovery",
+		"technique.ref":  "https://attack.mitre.org/techniques/T1033/",
+	}, r.Labels)
+	assert.Equal(t, []string{"car.2016-03-001"}, r.Tags)
+	assert.Contains(t, r.Notes, "Administrative scripts")
+}
+
+func TestConvertNetworkConnection(t *testing.T) {
+	c := convertFile(t, "_fixtures/net_connection_win_rdp.yml")
+	require.True(t, c.IsConverted())
+	assert.Equal(t, []string{"only the first tactic is converted to labels. attack.command-and-control is retained as tag"}, c.Warnings)
+
+	r := c.Rule
+	assert.Equal(t, `kevt.name in ('Connect', 'Accept') and ((kevt.name = 'Connect' and net.dport = 3389) and not (cidr_contains(net.dip, '10.0.0.0/8', '192.168.0.0/16') or regex(ps.exe, '(?i).*\\\\(mstsc|rdcman)\\.exe$')))`, r.Condition)
+	assert.Equal(t, "T1021.001", r.Labels["subtechnique.id"])
+	assert.Equal(t, "Remote Desktop Protocol", r.Labels["subtechnique.name"])
+	assert.Equal(t, "https://attack.mitre.org/techniques/T1021/001/", r.Labels["subtechnique.ref"])
+	assert.Equal(t, "Lateral Movement", r.Labels["tactic.name"])
+}
+
+func TestConvertUnsupported(t *testing.T) {
+	c := convertFile(t, "_fixtures/registry_set_unsupported.yml")
+	require.False(t, c.IsConverted())
+	assert.Equal(t, []string{
+		"aggregation expression in condition: selection or keywords | count() > 5",
+	}, c.Unsupported)
+
+	var tests = []struct {
+		rule        string
+		unsupported []string
+	}{
+		{
+			`
+title: Unknown category
+logsource:
+  category: process_access
+detection:
+  selection:
+    GrantedAccess: '0x1010'
+  condition: selection
+`,
+			[]string{"process_access log source category"},
+		},
+		{
+			`
+title: Linux rule
+logsource:
+  category: process_creation
+  product: linux
+detection:
+  selection:
+    Image|endswith: '/bash'
+  condition: selection
+`,
+			[]string{"linux log source product"},
+		},
+		{
+			`
+title: Unsupported fields and modifiers
+logsource:
+  category: registry_set
+detection:
+  selection:
+    TargetObject|endswith: '\DisableAntiSpyware'
+    Details: 'DWORD (0x00000001)'
+    User|contains: 'AUTHORI'
+    Image|base64offset|contains: 'IEX'
+  condition: selection
+`,
+			[]string{
+				`"DWORD (0x00000001)" value in Details field`,
+				"User field",
+				"base64offset modifier in Image field",
+			},
+		},
+		{
+			`
+title: Keywords and timeframe
+logsource:
+  category: file_event
+detection:
+  keywords:
+    - 'mimikatz'
+  timeframe: 5m
+  condition: keywords
+`,
+			[]string{"timeframe", "keyword search in keywords"},
+		},
+		{
+			`
+title: Malformed condition
+logsource:
+  category: dns_query
+detection:
+  selection:
+    QueryName|endswith: '.onion'
+  condition: selection and (filter
+`,
+			[]string{"undefined filter search identifier in condition: selection and (filter"},
+		},
+		{
+			`
+title: Escaped wildcards
+logsource:
+  category: image_load
+detection:
+  selection:
+    ImageLoaded|contains: '\*\temp\\*.dll'
+  condition: selection
+`,
+			[]string{`escaped wildcard in "\\*\\temp\\\\*.dll" value of ImageLoaded field`},
+		},
+	}
+
+	for _, tt := range tests {
+		c, err := Convert([]byte(tt.rule))
+		require.NoError(t, err)
+		assert.False(t, c.IsConverted(), c.Title)
+		assert.Equal(t, tt.unsupported, c.Unsupported, c.Title)
+	}
+}
+
+func TestConvertConditions(t *testing.T) {
+	var tests = []struct {
+		detection string
+		expr      string
+	}{
+		{
+			`
+  selection:
+    TargetFilename|endswith: '.lnk'
+  condition: selection`,
+			`file.path iendswith '.lnk'`,
+		},
+		{
+			`
+  selection:
+    TargetFilename:
+      - 'C:\Users\\*\AppData\\*.exe'
+      - 'C:\Windows\Temp\evil.dll'
+      - 'C:\Windows\Temp\bad.dll'
+  condition: selection`,
+			`(file.path imatches 'C:\\Users\\*\\AppData\\*.exe' or file.path iin ('C:\\Windows\\Temp\\evil.dll', 'C:\\Windows\\Temp\\bad.dll'))`,
+		},
+		{
+			`
+  selection:
+    TargetFilename|contains: '\Temp\\*.ps1'
+    Image|startswith|cased: 'C:\Users\'
+  condition: selection`,
+			`(file.path imatches '*\\Temp\\*.ps1*' and ps.exe startswith 'C:\\Users\\')`,
+		},
+		{
+			`
+  sel1:
+    Image|endswith: '\cmd.exe'
+  sel2:
+    Image|endswith: '\powershell.exe'
+  other:
+    CommandLine|contains: "it's"
+  condition: 1 of sel* and not other`,
+			`((ps.exe iendswith '\\cmd.exe' or ps.exe iendswith '\\powershell.exe') and not (ps.cmdline icontains 'it\'s'))`,
+		},
+		{
+			`
+  sel1:
+    ProcessId|gt: 4
+  sel2:
+    ProcessId:
+      - 100
+      - 200
+  condition: all of them`,
+			`(ps.pid > 4 and (ps.pid = 100 or ps.pid = 200))`,
+		},
+		{
+			`
+  sel:
+    TargetFilename|re: '^C:\\Temp\\.+'
+  filter:
+    TargetFilename|endswith: '.tmp'
+  condition: sel and not (filter or filter)`,
+			`(regex(file.path, '^C:\\\\Temp\\\\.+') and not (file.path iendswith '.tmp' or file.path iendswith '.tmp'))`,
+		},
+	}
+
+	for _, tt := range tests {
+		rule := "title: Test\nlogsource:\n  category: file_event\ndetection:" + tt.detection
+		c, err := Convert([]byte(rule))
+		require.NoError(t, err)
+		require.True(t, c.IsConverted(), "%v", c.Unsupported)
+		assert.Equal(t, "kevt.name = 'CreateFile' and file.operation != 'OPEN' and "+tt.expr, c.Rule.Condition)
+		requireCompiles(t, c.Rule.Condition)
+	}
+}
+
+func TestConvertRegistryKeys(t *testing.T) {
+	rule := `
+title: Run key
+logsource:
+  category: registry_set
+detection:
+  selection:
+    TargetObject|startswith: 'HKLM\Software\Microsoft\Windows\CurrentVersion\Run\'
+  condition: selection
+`
+	c, err := Convert([]byte(rule))
+	require.NoError(t, err)
+	require.True(t, c.IsConverted())
+	requireCompiles(t, c.Rule.Condition)
+	assert.Equal(t, `kevt.name = 'RegSetValue' and registry.path istartswith 'HKEY_LOCAL_MACHINE\\Software\\Microsoft\\Windows\\CurrentVersion\\Run\\'`, c.Rule.Condition)
+	assert.Len(t, c.Rule.ID, 36)
+	assert.Equal(t, []string{"no MITRE tactic tag", "no MITRE technique tag"}, c.Warnings)
+}
+
+func TestMarshal(t *testing.T) {
+	c := convertFile(t, "_fixtures/net_connection_win_rdp.yml")
+	require.True(t, c.IsConverted())
+
+	b, err := c.Rule.Marshal()
+	require.NoError(t, err)
+
+	var rule map[string]any
+	require.NoError(t, yaml.Unmarshal(b, &rule))
+
+	assert.Equal(t, c.Rule.Name, rule["name"])
+	assert.Equal(t, c.Rule.ID, rule["id"])
+	assert.Equal(t, "1.0.0", rule["version"])
+	assert.Equal(t, c.Rule.Condition, rule["condition"])
+	assert.Equal(t, "2.0.0", rule["min-engine-version"])
+	assert.Equal(t, "medium", rule["severity"])
+	assert.Len(t, rule["labels"], 9)
+}
+
+func TestInvalidSigmaRule(t *testing.T) {
+	_, err := Convert([]byte("title: [broken"))
+	require.Error(t, err)
+	_, err = Convert([]byte("detection: {}"))
+	require.EqualError(t, err, "invalid Sigma rule: missing title")
+}
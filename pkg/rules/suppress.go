@@ -0,0 +1,197 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"expvar"
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	"github.com/rabbitstack/fibratus/pkg/filter/fields"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	log "github.com/sirupsen/logrus"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxSuppressionKeys determines the maximum number of deduplication keys per rule
+const maxSuppressionKeys = 10000
+
+var (
+	suppressedAlerts    = expvar.NewMap("filter.suppressed.alerts")
+	suppressionKeys     = expvar.NewMap("filter.suppression.keys")
+	suppressionBreaches = expvar.NewMap("filter.suppression.breaches")
+	// expiredSuppressions counts the suppressed alerts that were
+	// never reported because their deduplication key expired
+	expiredSuppressions = expvar.NewMap("filter.suppression.expired.alerts")
+)
+
+// suppression keeps the number of emitted and suppressed
+// alerts within the time window for a deduplication key.
+type suppression struct {
+	start      time.Time
+	alerts     int
+	suppressed int
+}
+
+// suppressor throttles alerts of the noisy rule. Alerts
+// are deduplicated by the key that is built from the field
+// values of the events that triggered the alert. Within the
+// time window, only the configured number of alerts is emitted
+// for the same key. The rest of the alerts are suppressed and
+// accounted in the next emitted alert.
+type suppressor struct {
+	name      string
+	tmpl      string
+	window    time.Duration
+	maxAlerts int
+
+	keys map[string]*suppression
+	mu   sync.Mutex
+
+	isKeysBreached bool
+}
+
+func newSuppressor(c *config.FilterConfig) (*suppressor, error) {
+	s := &suppressor{
+		name:      c.Name,
+		window:    c.Suppress.Window,
+		maxAlerts: c.Suppress.MaxAlerts,
+		keys:      make(map[string]*suppression),
+	}
+	if s.window <= 0 {
+		return nil, fmt.Errorf("%q rule has an invalid suppression window", c.Name)
+	}
+	if s.maxAlerts < 1 {
+		s.maxAlerts = 1
+	}
+	// the deduplication key is rendered from the
+	// template with field modifiers, akin to the
+	// rule output
+	tmpl := make([]string, 0, len(c.Suppress.Fields))
+	for _, field := range c.Suppress.Fields {
+		name := field
+		// strip the sequence event ordinal
+		if len(name) > 2 && name[1] == '.' && name[0] >= '1' && name[0] <= '9' {
+			name = name[2:]
+		}
+		if n := strings.Index(name, "["); n > 0 {
+			name = name[:n]
+		}
+		if !fields.IsField(name) {
+			return nil, fmt.Errorf("%q rule has an invalid suppression field %q", c.Name, field)
+		}
+		tmpl = append(tmpl, "%"+field)
+	}
+	s.tmpl = strings.Join(tmpl, "|")
+	return s, nil
+}
+
+// equal determines if both suppressors have the same settings.
+func (s *suppressor) equal(o *suppressor) bool {
+	return s.tmpl == o.tmpl && s.window == o.window && s.maxAlerts == o.maxAlerts
+}
+
+// key renders the deduplication key from the events.
+func (s *suppressor) key(evts []*kevent.Kevent) string {
+	if s.tmpl == "" {
+		return ""
+	}
+	return filter.InterpolateFields(s.tmpl, evts)
+}
+
+// next determines if the alert for the given events should
+// be emitted. If the alert is emitted, the second return
+// value contains the number of alerts suppressed for the
+// same key since the last emitted alert.
+func (s *suppressor) next(evts []*kevent.Kevent) (bool, int) {
+	key := s.key(evts)
+	now := time.Now()
+	if len(evts) > 0 {
+		now = evts[len(evts)-1].Timestamp
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w, ok := s.keys[key]
+	if !ok {
+		if len(s.keys) >= maxSuppressionKeys {
+			suppressionBreaches.Add(s.name, 1)
+			if !s.isKeysBreached {
+				log.Warnf("max suppression keys reached for rule %s. "+
+					"Alerts for new keys are not suppressed", s.name)
+			}
+			s.isKeysBreached = true
+			return true, 0
+		}
+		s.keys[key] = &suppression{start: now, alerts: 1}
+		suppressionKeys.Add(s.name, 1)
+		return true, 0
+	}
+
+	// the window expired. Start the new window
+	// and report the suppressed alerts count
+	if now.Sub(w.start) >= s.window {
+		suppressed := w.suppressed
+		w.start, w.alerts, w.suppressed = now, 1, 0
+		return true, suppressed
+	}
+
+	if w.alerts < s.maxAlerts {
+		suppressed := w.suppressed
+		w.alerts++
+		w.suppressed = 0
+		return true, suppressed
+	}
+
+	w.suppressed++
+	suppressedAlerts.Add(s.name, 1)
+	return false, 0
+}
+
+// gc removes the deduplication keys whose time window
+// expired. If the key has pending suppressed alerts, they
+// are reported before the key is evicted, since no alert
+// for the key was emitted to account for them.
+func (s *suppressor) gc() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var suppressed, keys int
+	for key, w := range s.keys {
+		if time.Since(w.start) <= s.window {
+			continue
+		}
+		if w.suppressed > 0 {
+			suppressed += w.suppressed
+			keys++
+		}
+		delete(s.keys, key)
+		suppressionKeys.Add(s.name, -1)
+	}
+	if suppressed > 0 {
+		log.Infof("%d alert(s) of rule %s were suppressed for %d expired "+
+			"deduplication key(s)", suppressed, s.name, keys)
+		expiredSuppressions.Add(s.name, int64(suppressed))
+	}
+	if len(s.keys) < maxSuppressionKeys {
+		s.isKeysBreached = false
+	}
+}
@@ -0,0 +1,186 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"expvar"
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newSuppressEvent(ts time.Time, exe string) *kevent.Kevent {
+	return &kevent.Kevent{
+		Type:      ktypes.CreateFile,
+		Name:      "CreateFile",
+		Category:  ktypes.File,
+		Timestamp: ts,
+		PID:       1234,
+		PS:        &pstypes.PS{PID: 1234, Exe: exe},
+		Kparams:   kevent.Kparams{},
+		Metadata:  make(map[kevent.MetadataKey]any),
+	}
+}
+
+func TestSuppressor(t *testing.T) {
+	c := &config.FilterConfig{
+		Name: "Unusual access to web browser credential stores",
+		Suppress: &config.SuppressConfig{
+			Fields:    []string{"ps.exe"},
+			Window:    time.Minute,
+			MaxAlerts: 2,
+		},
+	}
+	s, err := newSuppressor(c)
+	require.NoError(t, err)
+
+	now := time.Now()
+	chrome := "C:\\Program Files\\Google\\Chrome\\chrome.exe"
+	edge := "C:\\Program Files\\Microsoft\\Edge\\msedge.exe"
+
+	emit, suppressed := s.next([]*kevent.Kevent{newSuppressEvent(now, chrome)})
+	assert.True(t, emit)
+	assert.Equal(t, 0, suppressed)
+	emit, _ = s.next([]*kevent.Kevent{newSuppressEvent(now.Add(time.Second), chrome)})
+	assert.True(t, emit)
+
+	// max alerts reached for the key
+	for i := 0; i < 3; i++ {
+		emit, _ = s.next([]*kevent.Kevent{newSuppressEvent(now.Add(time.Second*2), chrome)})
+		assert.False(t, emit)
+	}
+
+	// different key is not suppressed
+	emit, _ = s.next([]*kevent.Kevent{newSuppressEvent(now.Add(time.Second*3), edge)})
+	assert.True(t, emit)
+	assert.Len(t, s.keys, 2)
+
+	// window expired. Suppressed alerts are reported
+	emit, suppressed = s.next([]*kevent.Kevent{newSuppressEvent(now.Add(time.Minute*2), chrome)})
+	assert.True(t, emit)
+	assert.Equal(t, 3, suppressed)
+
+	emit, suppressed = s.next([]*kevent.Kevent{newSuppressEvent(now.Add(time.Minute*2), chrome)})
+	assert.True(t, emit)
+	assert.Equal(t, 0, suppressed)
+}
+
+func TestSuppressorGC(t *testing.T) {
+	c := &config.FilterConfig{
+		Name:     "Suppressed rule",
+		Suppress: &config.SuppressConfig{Window: time.Minute},
+	}
+	s, err := newSuppressor(c)
+	require.NoError(t, err)
+
+	expired := expiredSuppressionCount(c.Name)
+
+	// the window started in the past, so it is already expired
+	start := time.Now().Add(-time.Minute * 2)
+	emit, _ := s.next([]*kevent.Kevent{newSuppressEvent(start, "C:\\Windows\\cmd.exe")})
+	require.True(t, emit)
+	emit, _ = s.next([]*kevent.Kevent{newSuppressEvent(start, "C:\\Windows\\notepad.exe")})
+	require.False(t, emit)
+	require.Len(t, s.keys, 1)
+
+	// the key with pending suppressed alerts is evicted
+	// and the suppressed alerts are reported
+	s.gc()
+	require.Len(t, s.keys, 0)
+	assert.Equal(t, expired+1, expiredSuppressionCount(c.Name))
+
+	// the active window is kept
+	emit, _ = s.next([]*kevent.Kevent{newSuppressEvent(time.Now(), "C:\\Windows\\cmd.exe")})
+	require.True(t, emit)
+	s.gc()
+	require.Len(t, s.keys, 1)
+}
+
+func TestSuppressorGCDrainsKeys(t *testing.T) {
+	c := &config.FilterConfig{
+		Name: "Suppressed rule with many keys",
+		Suppress: &config.SuppressConfig{
+			Fields: []string{"ps.exe"},
+			Window: time.Minute,
+		},
+	}
+	s, err := newSuppressor(c)
+	require.NoError(t, err)
+	expired := expiredSuppressionCount(c.Name)
+
+	start := time.Now().Add(-time.Minute * 2)
+	for i := 0; i < maxSuppressionKeys; i++ {
+		exe := fmt.Sprintf("C:\\Temp\\%d.exe", i)
+		emit, _ := s.next([]*kevent.Kevent{newSuppressEvent(start, exe)})
+		require.True(t, emit)
+		// every key has a pending suppressed alert
+		emit, _ = s.next([]*kevent.Kevent{newSuppressEvent(start, exe)})
+		require.False(t, emit)
+	}
+	require.Len(t, s.keys, maxSuppressionKeys)
+
+	// the table is full and alerts for new keys are not suppressed
+	emit, _ := s.next([]*kevent.Kevent{newSuppressEvent(time.Now(), "C:\\Windows\\cmd.exe")})
+	require.True(t, emit)
+	require.True(t, s.isKeysBreached)
+
+	s.gc()
+	require.Len(t, s.keys, 0)
+	require.False(t, s.isKeysBreached)
+	assert.Equal(t, expired+maxSuppressionKeys, expiredSuppressionCount(c.Name))
+
+	// new keys are suppressed again
+	emit, _ = s.next([]*kevent.Kevent{newSuppressEvent(time.Now(), "C:\\Windows\\cmd.exe")})
+	require.True(t, emit)
+	emit, _ = s.next([]*kevent.Kevent{newSuppressEvent(time.Now(), "C:\\Windows\\cmd.exe")})
+	require.False(t, emit)
+}
+
+func TestNewSuppressorErrors(t *testing.T) {
+	_, err := newSuppressor(&config.FilterConfig{
+		Name:     "Suppressed rule",
+		Suppress: &config.SuppressConfig{Fields: []string{"ps.exe", "2.file.path", "kevt.arg[exe]"}, Window: time.Minute},
+	})
+	require.NoError(t, err)
+
+	_, err = newSuppressor(&config.FilterConfig{
+		Name:     "Suppressed rule",
+		Suppress: &config.SuppressConfig{Fields: []string{"ps.exes"}, Window: time.Minute},
+	})
+	require.EqualError(t, err, `"Suppressed rule" rule has an invalid suppression field "ps.exes"`)
+
+	_, err = newSuppressor(&config.FilterConfig{
+		Name:     "Suppressed rule",
+		Suppress: &config.SuppressConfig{},
+	})
+	require.Error(t, err)
+}
+
+func expiredSuppressionCount(rule string) int64 {
+	if v, ok := expiredSuppressions.Get(rule).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
@@ -0,0 +1,490 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/rabbitstack/fibratus/pkg/util/va"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// paramTypes maps well-known numeric, boolean and address
+// parameters to their types. The remaining parameters are
+// inferred from the value given in the test case.
+var paramTypes = map[string]kparams.Type{
+	kparams.ProcessID:              kparams.PID,
+	kparams.ProcessParentID:        kparams.PID,
+	kparams.ProcessRealParentID:    kparams.PID,
+	kparams.TargetProcessID:        kparams.PID,
+	kparams.ThreadID:               kparams.TID,
+	kparams.SessionID:              kparams.Uint32,
+	kparams.ProcessFlags:           kparams.Uint32,
+	kparams.ExitStatus:             kparams.Uint32,
+	kparams.BasePrio:               kparams.Uint8,
+	kparams.IOPrio:                 kparams.Uint8,
+	kparams.PagePrio:               kparams.Uint8,
+	kparams.NetDport:               kparams.Port,
+	kparams.NetSport:               kparams.Port,
+	kparams.NetSize:                kparams.Uint32,
+	kparams.FileIoSize:             kparams.Uint32,
+	kparams.FileOffset:             kparams.Uint64,
+	kparams.FileObject:             kparams.Uint64,
+	kparams.FileKey:                kparams.Uint64,
+	kparams.FileExtraInfo:          kparams.Uint64,
+	kparams.FileViewSize:           kparams.Uint64,
+	kparams.FileViewBase:           kparams.Address,
+	kparams.FileIsDLL:              kparams.Bool,
+	kparams.FileIsDriver:           kparams.Bool,
+	kparams.FileIsExecutable:       kparams.Bool,
+	kparams.FileIsDotnet:           kparams.Bool,
+	kparams.ImageBase:              kparams.Address,
+	kparams.ImageDefaultBase:       kparams.Address,
+	kparams.ImageSize:              kparams.Uint64,
+	kparams.ImageCheckSum:          kparams.Uint32,
+	kparams.ImageSignatureType:     kparams.Uint32,
+	kparams.ImageSignatureLevel:    kparams.Uint32,
+	kparams.HandleID:               kparams.Uint32,
+	kparams.HandleObject:           kparams.Uint64,
+	kparams.MemRegionSize:          kparams.Uint64,
+	kparams.ThreadpoolTimerDuetime: kparams.Uint64,
+	kparams.ThreadpoolTimerPeriod:  kparams.Uint32,
+	kparams.ThreadpoolTimerWindow:  kparams.Uint32,
+}
+
+// TestResult represents the outcome of the test case embedded in the rule.
+type TestResult struct {
+	// Rule is the name of the rule under test.
+	Rule string
+	// Test is the name of the test case.
+	Test string
+	// Matched indicates if the rule matched the test case events.
+	Matched bool
+	// Passed is true when the rule outcome is equal to the expected outcome.
+	Passed bool
+	// Err is the error that prevented the test case from running.
+	Err error
+}
+
+// RunTests evaluates test cases embedded in the rules. Rules and
+// macros must already be loaded in the config. Each test case runs
+// on a fresh engine instance that only contains the rule under test.
+// Rule actions are not executed and alerts are not emitted.
+func RunTests(c *config.Config) []TestResult {
+	// test events don't originate from the event sources,
+	// so the accessors of all event categories are enabled
+	cfg := *c
+	cfg.Kstream.EnableThreadKevents = true
+	cfg.Kstream.EnableRegistryKevents = true
+	cfg.Kstream.EnableNetKevents = true
+	cfg.Kstream.EnableFileIOKevents = true
+	cfg.Kstream.EnableImageKevents = true
+	cfg.Kstream.EnableHandleKevents = true
+	cfg.Kstream.EnableMemKevents = true
+	cfg.Kstream.EnableDNSEvents = true
+	cfg.Kstream.EnableThreadpoolEvents = true
+
+	results := make([]TestResult, 0)
+	for _, f := range c.GetFilters() {
+		if f.IsDisabled() {
+			continue
+		}
+		for _, tc := range f.Tests {
+			res := TestResult{Rule: f.Name, Test: tc.Name}
+			res.Matched, res.Err = runTest(&cfg, f, tc)
+			res.Passed = res.Err == nil && res.Matched == tc.Match
+			results = append(results, res)
+		}
+	}
+	return results
+}
+
+// runTest feeds the test case events into the engine and reports whether
+// the rule matched. The engine is driven by the virtual clock, which
+// is advanced to the timestamp of each event before it is processed,
+// so max span deadlines fire in the order they would occur in real time.
+func runTest(c *config.Config, f *config.FilterConfig, tc config.FilterTest) (bool, error) {
+	start := time.Now()
+	evts, err := newTestEvents(tc, start)
+	if err != nil {
+		return false, err
+	}
+	psnap := newTestSnapshotter(evts)
+	clk := newVirtualClock(start)
+
+	e := NewEngine(psnap, c)
+	e.skipActions = true
+	e.clock = clk
+	defer e.Close()
+
+	filters, _, err := e.compiler.compileFilters([]*config.FilterConfig{f})
+	if err != nil {
+		return false, err
+	}
+	rules, err := e.buildRuleset(filters, e.rules.Load())
+	if err != nil {
+		return false, err
+	}
+	e.rules.Store(rules)
+
+	var matched atomic.Bool
+	e.RegisterMatchFunc(func(*config.FilterConfig, ...*kevent.Kevent) { matched.Store(true) })
+
+	var maxSpan time.Duration
+	for _, flt := range filters {
+		if flt.IsSequence() {
+			maxSpan = flt.GetSequence().MaxSpan
+		}
+	}
+
+	for _, evt := range evts {
+		clk.advance(evt.Timestamp)
+		if _, err := e.ProcessEvent(evt); err != nil {
+			return false, err
+		}
+	}
+	// fire pending max span deadlines, so
+	// negated expressions have a chance to match
+	clk.advance(clk.Now().Add(maxSpan))
+
+	return matched.Load(), nil
+}
+
+// virtualClock is the clock that only moves when advanced by
+// the test runner. Timers are fired synchronously in the order
+// of their deadlines as the clock is advanced past them.
+type virtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+}
+
+type virtualTimer struct {
+	c        *virtualClock
+	deadline time.Time
+	f        func()
+	active   bool
+}
+
+func newVirtualClock(now time.Time) *virtualClock {
+	return &virtualClock{now: now}
+}
+
+func (c *virtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *virtualClock) AfterFunc(d time.Duration, f func()) timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &virtualTimer{c: c, deadline: c.now.Add(d), f: f, active: true}
+	c.timers = append(c.timers, t)
+	return t
+}
+
+// advance moves the clock forward to the given time and fires all
+// timers due until then. The timer function is called without
+// holding the clock lock, so it can schedule new timers.
+func (c *virtualClock) advance(to time.Time) {
+	for {
+		c.mu.Lock()
+		var next *virtualTimer
+		timers := c.timers[:0]
+		for _, t := range c.timers {
+			if !t.active {
+				continue
+			}
+			timers = append(timers, t)
+			if !t.deadline.After(to) && (next == nil || t.deadline.Before(next.deadline)) {
+				next = t
+			}
+		}
+		c.timers = timers
+		if next == nil {
+			if to.After(c.now) {
+				c.now = to
+			}
+			c.mu.Unlock()
+			return
+		}
+		next.active = false
+		if next.deadline.After(c.now) {
+			c.now = next.deadline
+		}
+		c.mu.Unlock()
+		next.f()
+	}
+}
+
+func (t *virtualTimer) Stop() bool {
+	t.c.mu.Lock()
+	defer t.c.mu.Unlock()
+	active := t.active
+	t.active = false
+	return active
+}
+
+// newTestEvents builds events from the test case definition.
+// Event timestamps are relative to the start time and the events
+// are sorted by their timestamps.
+func newTestEvents(tc config.FilterTest, start time.Time) ([]*kevent.Kevent, error) {
+	evts := make([]*kevent.Kevent, len(tc.Events))
+	for i, ev := range tc.Events {
+		evt, err := newTestEvent(ev)
+		if err != nil {
+			return nil, fmt.Errorf("event #%d: %v", i+1, err)
+		}
+		evt.Timestamp = start.Add(ev.At)
+		evts[i] = evt
+	}
+	sort.SliceStable(evts, func(i, j int) bool { return evts[i].Timestamp.Before(evts[j].Timestamp) })
+	for i, evt := range evts {
+		evt.Seq = uint64(i + 1)
+	}
+	return evts, nil
+}
+
+func newTestEvent(ev config.FilterTestEvent) (*kevent.Kevent, error) {
+	kpars := make(kevent.Kparams)
+	ipv6 := false
+	for name, value := range ev.Params {
+		kpar, err := newTestParam(name, value)
+		if err != nil {
+			return nil, err
+		}
+		if kpar.Type == kparams.IPv6 {
+			ipv6 = true
+		}
+		kpars[name] = kpar
+	}
+
+	// network events with multiple types are resolved
+	// by the protocol and the IP address family. Types
+	// are ordered as TCPv4, TCPv6, UDPv4, and UDPv6
+	types := ktypes.KeventNameToKtypes(ev.Name)
+	var i int
+	if len(types) > 1 {
+		if ipv6 {
+			i++
+		}
+		if proto, ok := kpars[kparams.NetL4Proto]; ok && strings.EqualFold(fmt.Sprintf("%v", proto.Value), "UDP") {
+			i += 2
+		}
+		if i >= len(types) {
+			return nil, fmt.Errorf("%s event has no UDP variant", ev.Name)
+		}
+	}
+	typ := types[i]
+	if !typ.Exists() {
+		return nil, fmt.Errorf("unknown event name %s", ev.Name)
+	}
+
+	evt := &kevent.Kevent{
+		Type:     typ,
+		Name:     ev.Name,
+		Category: typ.Category(),
+		Kparams:  kpars,
+		Metadata: make(map[kevent.MetadataKey]any),
+	}
+	if ev.PS != nil {
+		evt.PS = newTestProcess(ev.PS)
+		evt.PID = evt.PS.PID
+	}
+
+	return evt, nil
+}
+
+// newTestParam infers the parameter type either from the
+// well-known parameter names or the YAML value type.
+func newTestParam(name string, value any) (*kevent.Kparam, error) {
+	typ, ok := paramTypes[name]
+	if !ok {
+		switch v := value.(type) {
+		case bool:
+			typ = kparams.Bool
+		case int:
+			typ = kparams.Uint32
+			if v > math.MaxUint32 {
+				typ = kparams.Uint64
+			}
+		case []any:
+			typ = kparams.Slice
+		case string:
+			typ = kparams.UnicodeString
+			if ip := net.ParseIP(v); ip != nil {
+				typ = kparams.IPv4
+				if ip.To4() == nil {
+					typ = kparams.IPv6
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported value %v for %s parameter", value, name)
+		}
+	}
+
+	kpar := &kevent.Kparam{Name: name, Type: typ}
+
+	switch typ {
+	case kparams.PID, kparams.TID, kparams.Uint32:
+		n, err := toUint(name, value, math.MaxUint32)
+		if err != nil {
+			return nil, err
+		}
+		kpar.Value = uint32(n)
+	case kparams.Port, kparams.Uint16:
+		n, err := toUint(name, value, math.MaxUint16)
+		if err != nil {
+			return nil, err
+		}
+		kpar.Value = uint16(n)
+	case kparams.Uint8:
+		n, err := toUint(name, value, math.MaxUint8)
+		if err != nil {
+			return nil, err
+		}
+		kpar.Value = uint8(n)
+	case kparams.Uint64, kparams.Address:
+		n, err := toUint(name, value, math.MaxUint64)
+		if err != nil {
+			return nil, err
+		}
+		kpar.Value = n
+	case kparams.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return nil, fmt.Errorf("%s parameter requires a boolean value", name)
+		}
+		kpar.Value = b
+	case kparams.IPv4, kparams.IPv6:
+		ip := net.ParseIP(fmt.Sprintf("%v", value))
+		if ip == nil {
+			return nil, fmt.Errorf("%s parameter requires an IP address", name)
+		}
+		kpar.Value = ip
+	case kparams.Slice:
+		items := value.([]any)
+		s := make([]string, len(items))
+		for i, item := range items {
+			s[i] = fmt.Sprintf("%v", item)
+		}
+		kpar.Value = s
+	default:
+		kpar.Value = fmt.Sprintf("%v", value)
+	}
+
+	return kpar, nil
+}
+
+func toUint(name string, value any, limit uint64) (uint64, error) {
+	n, ok := value.(int)
+	if !ok || n < 0 || uint64(n) > limit {
+		return 0, fmt.Errorf("%s parameter requires a numeric value in range [0, %d]", name, limit)
+	}
+	return uint64(n), nil
+}
+
+func newTestProcess(p *config.FilterTestProcess) *pstypes.PS {
+	proc := &pstypes.PS{
+		PID:       p.PID,
+		Ppid:      p.Ppid,
+		Name:      p.Name,
+		Exe:       p.Exe,
+		Cmdline:   p.Cmdline,
+		Cwd:       p.Cwd,
+		Args:      p.Args,
+		SID:       p.SID,
+		Username:  p.Username,
+		Domain:    p.Domain,
+		SessionID: p.SessionID,
+		Envs:      p.Envs,
+		Threads:   make(map[uint32]pstypes.Thread),
+		Modules:   make([]pstypes.Module, 0, len(p.Modules)),
+	}
+	for _, mod := range p.Modules {
+		proc.Modules = append(proc.Modules, pstypes.Module{Name: mod})
+	}
+	if p.Parent != nil {
+		proc.Parent = newTestProcess(p.Parent)
+		if proc.Ppid == 0 {
+			proc.Ppid = proc.Parent.PID
+		}
+	}
+	return proc
+}
+
+// testSnapshotter is the read-only process snapshotter
+// that serves the process state declared in test cases.
+type testSnapshotter struct {
+	procs map[uint32]*pstypes.PS
+}
+
+func newTestSnapshotter(evts []*kevent.Kevent) *testSnapshotter {
+	s := &testSnapshotter{procs: make(map[uint32]*pstypes.PS)}
+	for _, evt := range evts {
+		for proc := evt.PS; proc != nil; proc = proc.Parent {
+			if _, ok := s.procs[proc.PID]; !ok {
+				s.procs[proc.PID] = proc
+			}
+		}
+	}
+	return s
+}
+
+func (s *testSnapshotter) Write(*kevent.Kevent) error                    { return nil }
+func (s *testSnapshotter) AddThread(*kevent.Kevent) error                { return nil }
+func (s *testSnapshotter) AddModule(*kevent.Kevent) error                { return nil }
+func (s *testSnapshotter) RemoveThread(uint32, uint32) error             { return nil }
+func (s *testSnapshotter) RemoveModule(uint32, va.Address) error         { return nil }
+func (s *testSnapshotter) AddMmap(*kevent.Kevent) error                  { return nil }
+func (s *testSnapshotter) RemoveMmap(uint32, va.Address) error           { return nil }
+func (s *testSnapshotter) WriteFromKcap(*kevent.Kevent) error            { return nil }
+func (s *testSnapshotter) Remove(*kevent.Kevent) error                   { return nil }
+func (s *testSnapshotter) FindModule(va.Address) (bool, *pstypes.Module) { return false, nil }
+func (s *testSnapshotter) Put(proc *pstypes.PS)                          { s.procs[proc.PID] = proc }
+func (s *testSnapshotter) Size() uint32                                  { return uint32(len(s.procs)) }
+func (s *testSnapshotter) Close() error                                  { return nil }
+
+func (s *testSnapshotter) GetSnapshot() []*pstypes.PS {
+	procs := make([]*pstypes.PS, 0, len(s.procs))
+	for _, proc := range s.procs {
+		procs = append(procs, proc)
+	}
+	return procs
+}
+
+func (s *testSnapshotter) Find(pid uint32) (bool, *pstypes.PS) {
+	proc, ok := s.procs[pid]
+	return ok, proc
+}
+
+func (s *testSnapshotter) FindAndPut(pid uint32) *pstypes.PS {
+	return s.procs[pid]
+}
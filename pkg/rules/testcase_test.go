@@ -0,0 +1,174 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"testing"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net"
+	"time"
+)
+
+func loadRules(t *testing.T, c *config.Config) {
+	require.NoError(t, c.Filters.LoadMacros())
+	require.NoError(t, c.Filters.LoadFilters())
+}
+
+func TestRunTests(t *testing.T) {
+	c := newConfig("_fixtures/tests/*.yml")
+	// field accessors are enabled regardless of the event sources
+	c.Kstream = config.KstreamConfig{}
+	loadRules(t, c)
+
+	start := time.Now()
+	results := RunTests(c)
+	require.Len(t, results, 9)
+	// max span deadlines are driven by the virtual clock
+	assert.Less(t, time.Since(start), time.Second*10)
+
+	for _, res := range results {
+		assert.NoError(t, res.Err, res.Test)
+		assert.True(t, res.Passed, "%s: %s", res.Rule, res.Test)
+	}
+}
+
+func TestRunTestsFailures(t *testing.T) {
+	c := newConfig("_fixtures/tests_failing/*.yml")
+	loadRules(t, c)
+
+	results := RunTests(c)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "excel spawns cmd", results[0].Test)
+	assert.False(t, results[0].Passed)
+	assert.False(t, results[0].Matched)
+	assert.NoError(t, results[0].Err)
+
+	assert.Equal(t, "unknown event", results[1].Test)
+	assert.False(t, results[1].Passed)
+	assert.EqualError(t, results[1].Err, "event #1: unknown event name SpawnProcess")
+}
+
+func TestRunTestsShippedRules(t *testing.T) {
+	c := newConfig("../../rules/*.yml")
+	c.Kstream = config.KstreamConfig{}
+	c.Filters.Macros = config.Macros{FromPaths: []string{"../../rules/macros/*.yml"}}
+	loadRules(t, c)
+
+	results := RunTests(c)
+	require.NotEmpty(t, results)
+	for _, res := range results {
+		assert.NoError(t, res.Err, res.Test)
+		assert.True(t, res.Passed, "%s: %s", res.Rule, res.Test)
+	}
+}
+
+func TestNewTestParam(t *testing.T) {
+	var tests = []struct {
+		name  string
+		value any
+		typ   kparams.Type
+		want  kparams.Value
+		err   bool
+	}{
+		{kparams.ProcessID, 1234, kparams.PID, uint32(1234), false},
+		{kparams.NetDport, 443, kparams.Port, uint16(443), false},
+		{kparams.NetDport, 70000, kparams.Port, nil, true},
+		{kparams.NetDIP, "10.0.0.1", kparams.IPv4, net.ParseIP("10.0.0.1"), false},
+		{kparams.NetDIP, "fe80::1", kparams.IPv6, net.ParseIP("fe80::1"), false},
+		{kparams.FileIsDLL, true, kparams.Bool, true, false},
+		{kparams.FileIsDLL, "yes", kparams.Bool, nil, true},
+		{kparams.FilePath, `C:\Windows\notepad.exe`, kparams.UnicodeString, `C:\Windows\notepad.exe`, false},
+		{"flags", 5, kparams.Uint32, uint32(5), false},
+		{"names", []any{"a", "b"}, kparams.Slice, []string{"a", "b"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kpar, err := newTestParam(tt.name, tt.value)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.typ, kpar.Type)
+			assert.Equal(t, tt.want, kpar.Value)
+		})
+	}
+}
+
+func TestNewTestEvent(t *testing.T) {
+	var tests = []struct {
+		name   string
+		params map[string]any
+		typ    ktypes.Ktype
+		err    bool
+	}{
+		{"Send", map[string]any{kparams.NetDIP: "10.0.0.1"}, ktypes.SendTCPv4, false},
+		{"Send", map[string]any{kparams.NetDIP: "fe80::1"}, ktypes.SendTCPv6, false},
+		{"Send", map[string]any{kparams.NetDIP: "10.0.0.1", kparams.NetL4Proto: "UDP"}, ktypes.SendUDPv4, false},
+		{"Recv", map[string]any{kparams.NetDIP: "fe80::1", kparams.NetL4Proto: "udp"}, ktypes.RecvUDPv6, false},
+		{"Recv", map[string]any{kparams.NetDIP: "fe80::1", kparams.NetL4Proto: "TCP"}, ktypes.RecvTCPv6, false},
+		{"Connect", map[string]any{kparams.NetDIP: "fe80::1"}, ktypes.ConnectTCPv6, false},
+		{"Connect", map[string]any{kparams.NetDIP: "10.0.0.1", kparams.NetL4Proto: "UDP"}, ktypes.UnknownKtype, true},
+		{"CreateProcess", map[string]any{kparams.NetL4Proto: "UDP"}, ktypes.CreateProcess, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evt, err := newTestEvent(config.FilterTestEvent{Name: tt.name, Params: tt.params})
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.typ, evt.Type)
+		})
+	}
+}
+
+func TestVirtualClock(t *testing.T) {
+	start := time.Now()
+	c := newVirtualClock(start)
+
+	fired := make([]int, 0)
+	c.AfterFunc(time.Second*2, func() { fired = append(fired, 2) })
+	c.AfterFunc(time.Second, func() {
+		fired = append(fired, 1)
+		// timers scheduled by the timer function fire
+		// within the same advance if they are due
+		c.AfterFunc(time.Millisecond*500, func() { fired = append(fired, 3) })
+	})
+	stopped := c.AfterFunc(time.Millisecond, func() { fired = append(fired, 0) })
+	require.True(t, stopped.Stop())
+	require.False(t, stopped.Stop())
+
+	c.advance(start.Add(time.Millisecond * 1500))
+	assert.Equal(t, []int{1, 3}, fired)
+	assert.Equal(t, start.Add(time.Millisecond*1500), c.Now())
+
+	c.advance(start.Add(time.Second * 3))
+	assert.Equal(t, []int{1, 3, 2}, fired)
+	assert.Len(t, c.timers, 0)
+}
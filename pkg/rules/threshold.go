@@ -0,0 +1,199 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"expvar"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// maxThresholdGroups determines the maximum number of group counters per threshold rule
+	maxThresholdGroups = 10000
+	// thresholdContextEvents is the number of most recent events retained per group for the alert context
+	thresholdContextEvents = 2
+)
+
+var (
+	thresholdGroupsCount   = expvar.NewMap("threshold.groups.count")
+	thresholdEventsCount   = expvar.NewMap("threshold.events.count")
+	thresholdGroupBreaches = expvar.NewMap("threshold.group.breaches")
+)
+
+// thresholdState keeps the sliding window counters of
+// the threshold rule. Every distinct value of the field
+// by which the matches are grouped gets a dedicated counter.
+// The rule fires when the number of matching events within
+// the time window reaches the threshold count for any group.
+type thresholdState struct {
+	filter filter.Filter
+	name   string
+	count  int
+	within time.Duration
+
+	// groups maps the group key to the
+	// counter of the sliding time window
+	groups map[string]*thresholdGroup
+	// matches stores the most recent events
+	// of the group that reached the threshold
+	matches []*kevent.Kevent
+	// mu guards the groups map and matches
+	mu sync.Mutex
+
+	isGroupsBreached bool
+}
+
+// thresholdGroup is the sliding window counter of the group. Only
+// the timestamps of matching events are tracked in the window, while
+// the events themselves are kept solely for the alert context.
+type thresholdGroup struct {
+	// times are the sorted timestamps of the
+	// events residing in the sliding time window
+	times []time.Time
+	// evts are the most recent events in the window
+	evts []*kevent.Kevent
+}
+
+func newThresholdState(f filter.Filter, c *config.FilterConfig) *thresholdState {
+	return &thresholdState{
+		filter: f,
+		name:   c.Name,
+		count:  f.GetThreshold().Count,
+		within: f.GetThreshold().Within,
+		groups: make(map[string]*thresholdGroup),
+	}
+}
+
+// runThreshold evaluates the threshold expression against the event.
+// If the event matches, it is appended to the sliding window of its
+// group and events that fell out of the time window are evicted. Once
+// the group counter reaches the threshold count, the rule fires and the
+// group counter is reset.
+func (t *thresholdState) runThreshold(e *kevent.Kevent) bool {
+	match, v := t.filter.RunThreshold(e)
+	if !match {
+		return false
+	}
+	key := groupKey(v)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	g, ok := t.groups[key]
+	if !ok {
+		if len(t.groups) >= maxThresholdGroups {
+			thresholdGroupBreaches.Add(t.name, 1)
+			if !t.isGroupsBreached {
+				log.Warnf("max group counters reached in threshold [%s]. "+
+					"Dropping event: %s", t.name, e)
+			}
+			t.isGroupsBreached = true
+			return false
+		}
+		g = &thresholdGroup{}
+		t.groups[key] = g
+		thresholdGroupsCount.Add(t.name, 1)
+	}
+
+	// keep the timestamps and events sorted
+	// in case they arrived out of order
+	i := sort.Search(len(g.times), func(i int) bool { return g.times[i].After(e.Timestamp) })
+	g.times = slices.Insert(g.times, i, e.Timestamp)
+	thresholdEventsCount.Add(t.name, 1)
+
+	i = sort.Search(len(g.evts), func(i int) bool { return g.evts[i].Timestamp.After(e.Timestamp) })
+	g.evts = slices.Insert(g.evts, i, e)
+	if n := len(g.evts) - thresholdContextEvents; n > 0 {
+		g.evts = slices.Delete(g.evts, 0, n)
+	}
+
+	// slide the window
+	cutoff := g.times[len(g.times)-1].Add(-t.within)
+	n := sort.Search(len(g.times), func(i int) bool { return !g.times[i].Before(cutoff) })
+	if n > 0 {
+		g.times = g.times[n:]
+		thresholdEventsCount.Add(t.name, -int64(n))
+	}
+	n = sort.Search(len(g.evts), func(i int) bool { return !g.evts[i].Timestamp.Before(cutoff) })
+	g.evts = slices.Delete(g.evts, 0, n)
+
+	if len(g.times) < t.count {
+		return false
+	}
+
+	log.Debugf("threshold of %d events within %v reached for group [%s] of rule [%s]", t.count, t.within, key, t.name)
+	t.matches = g.evts
+	t.removeGroup(key, len(g.times))
+
+	return true
+}
+
+// events returns the most recent events in
+// the sliding window of the group that fired
+// the rule.
+func (t *thresholdState) events() []*kevent.Kevent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.matches
+}
+
+func (t *thresholdState) clearMatches() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.matches = nil
+}
+
+// gc removes the group counters whose most
+// recent event is older than the time window.
+func (t *thresholdState) gc() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key, g := range t.groups {
+		if len(g.times) > 0 && time.Since(g.times[len(g.times)-1]) > t.within {
+			log.Debugf("garbage collecting group [%s] of threshold [%s]", key, t.name)
+			t.removeGroup(key, len(g.times))
+		}
+	}
+	if len(t.groups) < maxThresholdGroups {
+		t.isGroupsBreached = false
+	}
+}
+
+func (t *thresholdState) removeGroup(key string, n int) {
+	delete(t.groups, key)
+	thresholdGroupsCount.Add(t.name, -1)
+	thresholdEventsCount.Add(t.name, -int64(n))
+}
+
+// groupKey converts the group field value to the counter key.
+func groupKey(v any) string {
+	if v == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", v)
+}
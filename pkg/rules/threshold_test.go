@@ -0,0 +1,146 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/rabbitstack/fibratus/pkg/config"
+	"github.com/rabbitstack/fibratus/pkg/filter"
+	"github.com/rabbitstack/fibratus/pkg/kevent"
+	"github.com/rabbitstack/fibratus/pkg/kevent/kparams"
+	"github.com/rabbitstack/fibratus/pkg/kevent/ktypes"
+	"github.com/rabbitstack/fibratus/pkg/ps"
+	pstypes "github.com/rabbitstack/fibratus/pkg/ps/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRenameEvent(ts time.Time, pid uint32, exe string) *kevent.Kevent {
+	return &kevent.Kevent{
+		Type:      ktypes.RenameFile,
+		Timestamp: ts,
+		Name:      "RenameFile",
+		Tid:       2484,
+		PID:       pid,
+		Category:  ktypes.File,
+		PS: &pstypes.PS{
+			PID:  pid,
+			Name: "ransom.exe",
+			Exe:  exe,
+		},
+		Kparams: kevent.Kparams{
+			kparams.FilePath: {Name: kparams.FilePath, Type: kparams.UnicodeString, Value: "C:\\Users\\admin\\Documents\\report.docx"},
+		},
+		Metadata: make(map[kevent.MetadataKey]any),
+	}
+}
+
+func newThresholdFilter(t *testing.T, expr string) filter.Filter {
+	f := filter.New(expr, &config.Config{Kstream: config.KstreamConfig{EnableFileIOKevents: true}, Filters: &config.Filters{}})
+	require.NoError(t, f.Compile())
+	require.True(t, f.IsThreshold())
+	return f
+}
+
+func TestThresholdState(t *testing.T) {
+	f := newThresholdFilter(t, `threshold 3 within 1m by ps.pid |kevt.name = 'RenameFile'|`)
+	ts := newThresholdState(f, &config.FilterConfig{Name: "Mass file renames"})
+
+	now := time.Now()
+
+	require.False(t, ts.runThreshold(newRenameEvent(now, 1, "C:\\ransom.exe")))
+	require.False(t, ts.runThreshold(newRenameEvent(now.Add(time.Second), 1, "C:\\ransom.exe")))
+	// different group
+	require.False(t, ts.runThreshold(newRenameEvent(now.Add(time.Second*2), 2, "C:\\ransom.exe")))
+	assert.Len(t, ts.groups, 2)
+	// only the most recent events are retained
+	assert.Len(t, ts.groups["1"].times, 2)
+	assert.Len(t, ts.groups["1"].evts, 2)
+
+	require.True(t, ts.runThreshold(newRenameEvent(now.Add(time.Second*3), 1, "C:\\ransom.exe")))
+	evts := ts.events()
+	require.Len(t, evts, 2)
+	assert.Equal(t, now.Add(time.Second), evts[0].Timestamp)
+	assert.Equal(t, now.Add(time.Second*3), evts[1].Timestamp)
+	ts.clearMatches()
+	assert.Len(t, ts.events(), 0)
+
+	// group counter is reset after the rule fires
+	assert.Len(t, ts.groups, 1)
+	require.False(t, ts.runThreshold(newRenameEvent(now.Add(time.Second*4), 1, "C:\\ransom.exe")))
+}
+
+func TestThresholdSlidingWindow(t *testing.T) {
+	f := newThresholdFilter(t, `threshold 3 within 10s by ps.pid |kevt.name = 'RenameFile'|`)
+	ts := newThresholdState(f, &config.FilterConfig{Name: "Mass file renames"})
+
+	now := time.Now()
+
+	require.False(t, ts.runThreshold(newRenameEvent(now, 1, "C:\\ransom.exe")))
+	require.False(t, ts.runThreshold(newRenameEvent(now.Add(time.Second*5), 1, "C:\\ransom.exe")))
+	// the first event falls out of the window
+	require.False(t, ts.runThreshold(newRenameEvent(now.Add(time.Second*12), 1, "C:\\ransom.exe")))
+	assert.Len(t, ts.groups["1"].times, 2)
+	// out-of-order event within the window
+	require.True(t, ts.runThreshold(newRenameEvent(now.Add(time.Second*8), 1, "C:\\ransom.exe")))
+	evts := ts.events()
+	require.Len(t, evts, 2)
+	assert.Equal(t, now.Add(time.Second*8), evts[0].Timestamp)
+	assert.Equal(t, now.Add(time.Second*12), evts[1].Timestamp)
+}
+
+func TestThresholdWithoutGroup(t *testing.T) {
+	f := newThresholdFilter(t, `threshold 2 within 1m |kevt.name = 'RenameFile' and ps.exe = 'C:\\ransom.exe'|`)
+	ts := newThresholdState(f, &config.FilterConfig{Name: "Mass file renames"})
+
+	now := time.Now()
+
+	require.False(t, ts.runThreshold(newRenameEvent(now, 1, "C:\\ransom.exe")))
+	require.False(t, ts.runThreshold(newRenameEvent(now, 2, "C:\\Windows\\explorer.exe")))
+	require.True(t, ts.runThreshold(newRenameEvent(now.Add(time.Second), 2, "C:\\ransom.exe")))
+}
+
+func TestThresholdGC(t *testing.T) {
+	f := newThresholdFilter(t, `threshold 3 within 500ms by ps.pid |kevt.name = 'RenameFile'|`)
+	ts := newThresholdState(f, &config.FilterConfig{Name: "Mass file renames"})
+
+	require.False(t, ts.runThreshold(newRenameEvent(time.Now(), 1, "C:\\ransom.exe")))
+	assert.Len(t, ts.groups, 1)
+
+	time.Sleep(time.Second)
+
+	ts.gc()
+
+	assert.Len(t, ts.groups, 0)
+}
+
+func TestRunThresholdRule(t *testing.T) {
+	e := NewEngine(new(ps.SnapshotterMock), newConfig("_fixtures/threshold_rule.yml"))
+	compileRules(t, e)
+
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		require.False(t, wrapProcessEvent(newRenameEvent(now.Add(time.Duration(i)*time.Second), 1, "C:\\ransom.exe"), e.ProcessEvent))
+	}
+	evt := newRenameEvent(now.Add(time.Second*5), 1, "C:\\ransom.exe")
+	require.True(t, wrapProcessEvent(evt, e.ProcessEvent))
+	assert.Equal(t, "Mass file renames", evt.GetMetaAsString(kevent.RuleNameKey))
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2021-2022 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sys
+
+import (
+	"fmt"
+	"github.com/rabbitstack/fibratus/pkg/util/bytes"
+	"time"
+	"unsafe"
+)
+
+// Cert represents certificate information embedded in the PE or catalog.
+type Cert struct {
+	// NotBefore specifies the certificate won't be valid before this timestamp.
+	NotBefore time.Time `json:"not_before"`
+
+	// NotAfter specifies the certificate won't be valid after this timestamp.
+	NotAfter time.Time `json:"not_after"`
+
+	// Issuer represents the certificate authority (CA) that charges customers to issue
+	// certificates for them.
+	Issuer string `json:"issuer"`
+
+	// Subject indicates the subject of the certificate is the entity its public key is associated
+	// with (i.e. the "owner" of the certificate).
+	Subject string `json:"subject"`
+
+	// SerialNumber represents the serial number MUST be a positive integer assigned
+	// by the CA to each certificate. It MUST be unique for each certificate issued by
+	// a given CA (i.e., the issuer name and serial number identify a unique certificate).
+	// CAs MUST force the serialNumber to be a non-negative integer.
+	// For convenience, we convert the big int to string.
+	SerialNumber string `json:"serial_number"`
+}
+
+// Marshal writes certificate info into a raw buffer.
+func (c *Cert) Marshal() []byte {
+	b := make([]byte, 0)
+
+	before := make([]byte, 0)
+	before = c.NotBefore.AppendFormat(before, time.RFC3339Nano)
+	b = append(b, bytes.WriteUint16(uint16(len(before)))...)
+	b = append(b, before...)
+
+	after := make([]byte, 0)
+	after = c.NotAfter.AppendFormat(after, time.RFC3339Nano)
+	b = append(b, bytes.WriteUint16(uint16(len(after)))...)
+	b = append(b, after...)
+
+	b = append(b, bytes.WriteUint16(uint16(len(c.SerialNumber)))...)
+	b = append(b, c.SerialNumber...)
+	b = append(b, bytes.WriteUint16(uint16(len(c.Subject)))...)
+	b = append(b, c.Subject...)
+	b = append(b, bytes.WriteUint16(uint16(len(c.Issuer)))...)
+	b = append(b, c.Issuer...)
+
+	return b
+}
+
+// Unmarshal decodes cert info from the raw buffer. This method
+// assumes the certificate structure size was already read.
+func (c *Cert) Unmarshal(b []byte, offset, certSize uint32) error {
+	if certSize > uint32(len(b)) {
+		return fmt.Errorf("invalid PE cert size. Got %d but max buffer size is %d", certSize, len(b))
+	}
+
+	// read not before
+	l := bytes.ReadUint16(b[26+offset:])
+	buf := b[28+offset:]
+	offset += uint32(l)
+	if len(buf) > 0 {
+		c.NotBefore, _ = time.Parse(time.RFC3339Nano, string((*[1<<30 - 1]byte)(unsafe.Pointer(&buf[0]))[:l:l]))
+	}
+
+	// read not after
+	l = bytes.ReadUint16(b[28+offset:])
+	buf = b[30+offset:]
+	offset += uint32(l)
+	if len(buf) > 0 {
+		c.NotAfter, _ = time.Parse(time.RFC3339Nano, string((*[1<<30 - 1]byte)(unsafe.Pointer(&buf[0]))[:l:l]))
+	}
+
+	// read serial
+	l = bytes.ReadUint16(b[30+offset:])
+	buf = b[32+offset:]
+	offset += uint32(l)
+	c.SerialNumber = string((*[1<<30 - 1]byte)(unsafe.Pointer(&buf[0]))[:l:l])
+
+	// read subject
+	l = bytes.ReadUint16(b[32+offset:])
+	buf = b[34+offset:]
+	offset += uint32(l)
+	c.Subject = string((*[1<<30 - 1]byte)(unsafe.Pointer(&buf[0]))[:l:l])
+
+	// read issuer
+	l = bytes.ReadUint16(b[34+offset:])
+	buf = b[36+offset:]
+	c.Issuer = string((*[1<<30 - 1]byte)(unsafe.Pointer(&buf[0]))[:l:l])
+
+	return nil
+}
@@ -0,0 +1,43 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hostname
+
+import "os"
+
+// Get returns the host name of the machine. If the host
+// name can't be resolved, the local IP address is used.
+func Get() string {
+	if hostname != "" {
+		return hostname
+	}
+	var err error
+	hostname, err = os.Hostname()
+	if err != nil {
+		hostnameErrors.Add(err.Error(), 1)
+	}
+	if hostname == "" {
+		hostname = localIP()
+	}
+	if hostname == "" {
+		hostname = "unknown"
+	}
+	return hostname
+}
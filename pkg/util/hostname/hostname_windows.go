@@ -1,5 +1,5 @@
 /*
- * Copyright 2019-2020 by Nedim Sabic Sabic
+ * Copyright 2021-present by Nedim Sabic Sabic
  * https://www.fibratus.io
  * All Rights Reserved.
  *
@@ -19,19 +19,11 @@
 package hostname
 
 import (
-	"expvar"
-	"net"
 	"os"
 	"syscall"
 	"unsafe"
 )
 
-// hostname is the current host name or FQDN
-var hostname string
-
-// hostnameErrors exposes host/fqdn resolution errors
-var hostnameErrors = expvar.NewMap("hostname.errors")
-
 const computerNamePhysicalDNSFullyQualified = 7
 
 var (
@@ -79,30 +71,3 @@ func Get() string {
 
 	return hostname
 }
-
-// localIP returns the first non-loopback interface IP address.
-func localIP() string {
-	ifaces, err := net.Interfaces()
-	if err != nil {
-		return ""
-	}
-	for _, i := range ifaces {
-		addrs, err := i.Addrs()
-		if err != nil {
-			continue
-		}
-		for _, addr := range addrs {
-			var ip net.IP
-			switch v := addr.(type) {
-			case *net.IPNet:
-				ip = v.IP
-			case *net.IPAddr:
-				ip = v.IP
-			}
-			if !ip.IsLoopback() {
-				return ip.String()
-			}
-		}
-	}
-	return ""
-}
@@ -1,6 +1,3 @@
-//go:build windows
-// +build windows
-
 /*
  * Copyright 2019-2020 by Nedim Sabic Sabic
  * https://www.fibratus.io
@@ -21,18 +18,7 @@
 
 package ip
 
-import (
-	"net"
-	"syscall"
-	"unsafe"
-)
-
-var (
-	nt = syscall.NewLazyDLL("ntdll.dll")
-
-	// rtlIpv6AddressToString is the procedure for `RtlIpv6AddressToStringW` API call.
-	rtlIpv6AddressToString = nt.NewProc("RtlIpv6AddressToStringW")
-)
+import "net"
 
 // ToIPv4 accepts an integer IP address in network byte order and returns an IP-typed address.
 func ToIPv4(ip uint32) net.IP {
@@ -41,9 +27,7 @@ func ToIPv4(ip uint32) net.IP {
 
 // ToIPv6 converts the buffer with IPv6 address in network byte order to an IP-typed address.
 func ToIPv6(buffer []byte) net.IP {
-	ipv6 := make([]uint16, 46)
-	if rtlIpv6AddressToString != nil {
-		_, _, _ = rtlIpv6AddressToString.Call(uintptr(unsafe.Pointer(&buffer[0])), uintptr(unsafe.Pointer(&ipv6[0])))
-	}
-	return net.ParseIP(syscall.UTF16ToString(ipv6))
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, buffer)
+	return ip
 }
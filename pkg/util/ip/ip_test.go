@@ -1,6 +1,3 @@
-//go:build windows
-// +build windows
-
 /*
  * Copyright 2019-2020 by Nedim Sabic Sabic
  * https://www.fibratus.io
@@ -34,4 +31,6 @@ func TestToIPv4(t *testing.T) {
 func TestToIPv6(t *testing.T) {
 	ip := ToIPv6([]byte{0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x1})
 	assert.Equal(t, "::1", ip.String())
+	ip = ToIPv6([]byte{0xfe, 0x80, 0x0, 0x0, 0x0, 0x0, 0x0, 0x0, 0x2, 0x15, 0x5d, 0xff, 0xfe, 0x0, 0x1, 0x2})
+	assert.Equal(t, "fe80::215:5dff:fe00:102", ip.String())
 }
@@ -64,16 +64,6 @@ var (
 	Invalid = Key(registry.Key(0))
 )
 
-// RegistryValueTypes enumerate all possible registry value types.
-var RegistryValueTypes = map[uint32]string{
-	registry.DWORD:     "REG_DWORD",
-	registry.QWORD:     "REG_QWORD",
-	registry.SZ:        "REG_SZ",
-	registry.EXPAND_SZ: "REG_EXPAND_SZ",
-	registry.MULTI_SZ:  "REG_MULTI_SZ",
-	registry.BINARY:    "REG_BINARY",
-}
-
 // String converts registry root key identifier to string.
 func (key Key) String() string {
 	switch key {
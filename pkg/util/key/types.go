@@ -0,0 +1,29 @@
+/*
+ * Copyright 2019-2020 by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package key
+
+// RegistryValueTypes enumerate all possible registry value types.
+var RegistryValueTypes = map[uint32]string{
+	4:  "REG_DWORD",
+	11: "REG_QWORD",
+	1:  "REG_SZ",
+	2:  "REG_EXPAND_SZ",
+	7:  "REG_MULTI_SZ",
+	3:  "REG_BINARY",
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -29,10 +30,10 @@ func TestInitFromConfig(t *testing.T) {
 	require.Error(t, InitFromConfig(Config{}, "fibratus.log"))
 	require.NoError(t, InitFromConfig(Config{Path: "_fixtures", Level: "info", Formatter: "text"}, "fibratus.log"))
 
-	os.Remove("_fixtures\\fibratus.log")
+	os.Remove(filepath.Join("_fixtures", "fibratus.log"))
 
 	logrus.Info("fibratus initialized")
 
-	_, err := os.Stat("_fixtures\\fibratus.log")
+	_, err := os.Stat(filepath.Join("_fixtures", "fibratus.log"))
 	require.NoError(t, err)
 }
@@ -0,0 +1,53 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package log
+
+import (
+	"fmt"
+	"golang.org/x/sys/windows"
+	"os"
+)
+
+// redirectStderrToFile redirects the standard output stream to a log file.
+// Helpful to capture panics and send them to the file.
+func redirectStderrToFile(file string) error {
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_SYNC|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to open %s for stderr redirection: %v", file, err)
+	}
+	defer f.Close()
+
+	fd, err := dupFD(f.Fd())
+	if err != nil {
+		return fmt.Errorf("failed to duplicate file handle: %v", err)
+	}
+
+	err = windows.SetStdHandle(windows.STD_ERROR_HANDLE, fd)
+	if err != nil {
+		return fmt.Errorf("failed to redirect stderr to file: %v", err)
+	}
+
+	return nil
+}
+
+func dupFD(fd uintptr) (windows.Handle, error) {
+	proc := windows.CurrentProcess()
+	var h windows.Handle
+	return h, windows.DuplicateHandle(proc, windows.Handle(fd), proc, &h, 0, true, windows.DUPLICATE_SAME_ACCESS)
+}
@@ -29,11 +29,11 @@ import (
 	"fmt"
 	libntfs "github.com/rabbitstack/fibratus/pkg/fs/ntfs"
 	"github.com/rabbitstack/fibratus/pkg/util/cmdline"
+	"github.com/rabbitstack/fibratus/pkg/util/winpath"
 	log "github.com/sirupsen/logrus"
 	"hash"
 	"io"
 	"net/http"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
@@ -183,7 +183,7 @@ func (c *Client) matchPath(path string) (bool, Driver) {
 		if d.Filename == "" {
 			continue
 		}
-		if strings.EqualFold(filepath.Base(path), d.Filename) {
+		if strings.EqualFold(winpath.Base(path), d.Filename) {
 			return true, d
 		}
 	}
@@ -20,9 +20,9 @@ package signals
 
 import (
 	log "github.com/sirupsen/logrus"
-	"golang.org/x/sys/windows"
 	"os"
 	"os/signal"
+	"syscall"
 )
 
 // Install setups the signal handler. Returns a blocking
@@ -30,7 +30,7 @@ import (
 // signals are triggered.
 func Install() chan struct{} {
 	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, os.Interrupt, windows.SIGTERM)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
 
 	stopCh := make(chan struct{})
 
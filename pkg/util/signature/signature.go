@@ -19,8 +19,6 @@
 package signature
 
 import (
-	"github.com/rabbitstack/fibratus/pkg/pe"
-	"github.com/rabbitstack/fibratus/pkg/sys"
 	log "github.com/sirupsen/logrus"
 	"sync"
 	"time"
@@ -90,85 +88,3 @@ func (s *Signatures) gcSignatures() {
 		s.mux.Unlock()
 	}
 }
-
-// ParseCertificate parses the certificate data for catalog-based
-// signatures.
-func (s *Signature) ParseCertificate() error {
-	// the certificate exists in the PE security directory
-	if s.Cert != nil {
-		return nil
-	}
-	if !sys.IsWintrustFound() {
-		return ErrWintrustUnavailable
-	}
-	// parse catalog certificate
-	catalog := sys.NewCatalog()
-	if err := catalog.Open(s.Filename); err != nil {
-		return err
-	}
-	defer catalog.Close()
-	var err error
-	s.Cert, err = catalog.ParseCertificate()
-	if err != nil {
-		return err
-	}
-	return nil
-}
-
-// Check determines if the provided executable image or DLL is signed.
-// It first parses the PE security directory to look for the signature
-// information. If the certificate is not embedded inside the PE object
-// then this method will try to locate the hash in the catalog file. If
-// the certificate parsing is successful, this function returns the
-// signature structure containing the signature type and certificate info.
-// If the signature is not present, this function returns ErrNotSigned error.
-// To verify the signature, call the Verify method of the Signature structure.
-// On success, this method returns the signature type and the signature level.
-// The signature level is either unchecked or unsigned. It is necessary to
-// call the Verify method to determine the signature chain trust.
-func (s *Signature) Check() (uint32, uint32, error) {
-	// check if the signature is embedded in PE
-	f, err := pe.ParseFile(s.Filename, pe.WithSecurity())
-	if err != nil {
-		return None, UncheckedLevel, err
-	}
-	if f.IsSigned {
-		s.Cert = f.Cert
-		return Embedded, UncheckedLevel, nil
-	}
-
-	if !sys.IsWintrustFound() {
-		return None, UncheckedLevel, ErrWintrustUnavailable
-	}
-
-	// maybe the signature is in the catalog?
-	catalog := sys.NewCatalog()
-	if err := catalog.Open(s.Filename); err != nil {
-		return None, UncheckedLevel, err
-	}
-	defer catalog.Close()
-	if catalog.IsCatalogSigned() {
-		return Catalog, UncheckedLevel, nil
-	}
-	return None, UnsignedLevel, ErrNotSigned // image not signed
-}
-
-// Verify verifies the DLL or executable image signature.
-// The signature is verified via Authenticode policy provider.
-// Windows must verify the trust chain by following the certificates
-// to a trusted root certificate.
-// If the verification fails on the PE object, then the attempt to
-// verify the signature in the catalog file is made. This method
-// returns a bool value indicating if the signature is trusted.
-func (s *Signature) Verify() bool {
-	if !sys.IsWintrustFound() {
-		return false
-	}
-	s.Level = UnsignedLevel
-	isTrusted := s.VerifyEmbedded() || s.VerifyCatalog()
-	if isTrusted {
-		s.Level = AuthenticodeLevel
-		return isTrusted
-	}
-	return false
-}
@@ -0,0 +1,61 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signature
+
+import "github.com/rabbitstack/fibratus/pkg/pe"
+
+// ParseCertificate parses the certificate data for catalog-based
+// signatures. Catalogs are only available on Windows, so only the
+// certificates embedded in the PE security directory are returned.
+func (s *Signature) ParseCertificate() error {
+	if s.Cert != nil {
+		return nil
+	}
+	return ErrWintrustUnavailable
+}
+
+// Check determines if the provided executable image or DLL is signed
+// by parsing the PE security directory. The catalog lookup requires
+// wintrust, so images lacking the embedded signature yield the
+// ErrWintrustUnavailable error.
+func (s *Signature) Check() (uint32, uint32, error) {
+	f, err := pe.ParseFile(s.Filename, pe.WithSecurity())
+	if err != nil {
+		return None, UncheckedLevel, err
+	}
+	if f.IsSigned {
+		s.Cert = f.Cert
+		return Embedded, UncheckedLevel, nil
+	}
+	return None, UncheckedLevel, ErrWintrustUnavailable
+}
+
+// Verify always returns false as the trust chain
+// verification requires the Authenticode policy provider.
+func (s *Signature) Verify() bool { return false }
+
+// VerifyEmbedded always returns false as the trust
+// verification requires the wintrust provider.
+func (s *Signature) VerifyEmbedded() bool { return false }
+
+// VerifyCatalog always returns false as catalogs
+// are only available on Windows.
+func (s *Signature) VerifyCatalog() bool { return false }
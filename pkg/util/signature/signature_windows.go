@@ -0,0 +1,129 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package signature
+
+import (
+	"github.com/rabbitstack/fibratus/pkg/pe"
+	"github.com/rabbitstack/fibratus/pkg/sys"
+	"runtime"
+)
+
+// ParseCertificate parses the certificate data for catalog-based
+// signatures.
+func (s *Signature) ParseCertificate() error {
+	// the certificate exists in the PE security directory
+	if s.Cert != nil {
+		return nil
+	}
+	if !sys.IsWintrustFound() {
+		return ErrWintrustUnavailable
+	}
+	// parse catalog certificate
+	catalog := sys.NewCatalog()
+	if err := catalog.Open(s.Filename); err != nil {
+		return err
+	}
+	defer catalog.Close()
+	var err error
+	s.Cert, err = catalog.ParseCertificate()
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Check determines if the provided executable image or DLL is signed.
+// It first parses the PE security directory to look for the signature
+// information. If the certificate is not embedded inside the PE object
+// then this method will try to locate the hash in the catalog file. If
+// the certificate parsing is successful, this function returns the
+// signature structure containing the signature type and certificate info.
+// If the signature is not present, this function returns ErrNotSigned error.
+// To verify the signature, call the Verify method of the Signature structure.
+// On success, this method returns the signature type and the signature level.
+// The signature level is either unchecked or unsigned. It is necessary to
+// call the Verify method to determine the signature chain trust.
+func (s *Signature) Check() (uint32, uint32, error) {
+	// check if the signature is embedded in PE
+	f, err := pe.ParseFile(s.Filename, pe.WithSecurity())
+	if err != nil {
+		return None, UncheckedLevel, err
+	}
+	if f.IsSigned {
+		s.Cert = f.Cert
+		return Embedded, UncheckedLevel, nil
+	}
+
+	if !sys.IsWintrustFound() {
+		return None, UncheckedLevel, ErrWintrustUnavailable
+	}
+
+	// maybe the signature is in the catalog?
+	catalog := sys.NewCatalog()
+	if err := catalog.Open(s.Filename); err != nil {
+		return None, UncheckedLevel, err
+	}
+	defer catalog.Close()
+	if catalog.IsCatalogSigned() {
+		return Catalog, UncheckedLevel, nil
+	}
+	return None, UnsignedLevel, ErrNotSigned // image not signed
+}
+
+// Verify verifies the DLL or executable image signature.
+// The signature is verified via Authenticode policy provider.
+// Windows must verify the trust chain by following the certificates
+// to a trusted root certificate.
+// If the verification fails on the PE object, then the attempt to
+// verify the signature in the catalog file is made. This method
+// returns a bool value indicating if the signature is trusted.
+func (s *Signature) Verify() bool {
+	if !sys.IsWintrustFound() {
+		return false
+	}
+	s.Level = UnsignedLevel
+	isTrusted := s.VerifyEmbedded() || s.VerifyCatalog()
+	if isTrusted {
+		s.Level = AuthenticodeLevel
+		return isTrusted
+	}
+	return false
+}
+
+// VerifyEmbedded performs a trust verification action on the PE file
+// by passing the inquiry to a trust provider that supports the action
+// identifier.
+func (s *Signature) VerifyEmbedded() bool {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	trust := sys.NewWintrustData(sys.WtdChoiceFile)
+	defer trust.Close()
+	return trust.VerifyFile(s.Filename)
+}
+
+// VerifyCatalog verifies the catalog-based file signature.
+func (s *Signature) VerifyCatalog() bool {
+	catalog := sys.NewCatalog()
+	err := catalog.Open(s.Filename)
+	if err != nil {
+		return false
+	}
+	defer catalog.Close()
+	return catalog.Verify(s.Filename)
+}
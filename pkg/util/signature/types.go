@@ -21,7 +21,6 @@ package signature
 import (
 	"errors"
 	"github.com/rabbitstack/fibratus/pkg/sys"
-	"runtime"
 	"time"
 )
 
@@ -101,25 +100,3 @@ func (s *Signature) keepalive() {
 func (s *Signature) IsSigned() bool       { return s.Type != None }
 func (s *Signature) IsTrusted() bool      { return s.Level != UncheckedLevel && s.Level != UnsignedLevel }
 func (s *Signature) HasCertificate() bool { return s.Cert != nil }
-
-// VerifyEmbedded performs a trust verification action on the PE file
-// by passing the inquiry to a trust provider that supports the action
-// identifier.
-func (s *Signature) VerifyEmbedded() bool {
-	runtime.LockOSThread()
-	defer runtime.UnlockOSThread()
-	trust := sys.NewWintrustData(sys.WtdChoiceFile)
-	defer trust.Close()
-	return trust.VerifyFile(s.Filename)
-}
-
-// VerifyCatalog verifies the catalog-based file signature.
-func (s *Signature) VerifyCatalog() bool {
-	catalog := sys.NewCatalog()
-	err := catalog.Open(s.Filename)
-	if err != nil {
-		return false
-	}
-	defer catalog.Close()
-	return catalog.Verify(s.Filename)
-}
@@ -28,31 +28,6 @@ import (
 	"unsafe"
 )
 
-const (
-	// MemImage indicates that the memory pages within the region are mapped
-	// into the view of an image section.
-	MemImage uint32 = 0x1000000
-	// MemMapped indicates that the memory pages within the region are mapped
-	// into the view of a section.
-	MemMapped uint32 = 0x40000
-	// MemPrivate Indicates that the memory pages within the region are private
-	// that is, not shared by other processes.
-	MemPrivate uint32 = 0x20000
-)
-
-const (
-	// SectionData indicates a mapped view of a data file.
-	SectionData = 0x0
-	// SectionImage indicates a mapped view of an executable image.
-	SectionImage = 0x4
-	// SectionImageNoExecute indicates a mapped view an executable image file that will not be executed.
-	SectionImageNoExecute = 0x8
-	// SectionPagefile indicates a mapped view of pagefile-backed section.
-	SectionPagefile = 0xC
-	// SectionPhysical indicates that the allocation is a view of the \Device\PhysicalMemory section.
-	SectionPhysical = 0xD
-)
-
 // RegionInfo  describes the allocated region page properties.
 type RegionInfo struct {
 	Type     uint32
@@ -272,16 +247,6 @@ func ReadArea(process windows.Handle, base uintptr, bufSize, minSize uint, force
 	return buf
 }
 
-// Zeroed determines if all bytes in the area are zeroed.
-func Zeroed(area []byte) bool {
-	for _, b := range area {
-		if b != 0 {
-			return false
-		}
-	}
-	return true
-}
-
 // NewRegion creates a new region for the specified process and base address.
 func NewRegion(process windows.Handle, base uintptr) (*Region, error) {
 	var m windows.MemoryBasicInformation
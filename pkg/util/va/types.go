@@ -0,0 +1,44 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package va
+
+const (
+	// MemImage indicates that the memory pages within the region are mapped
+	// into the view of an image section.
+	MemImage uint32 = 0x1000000
+	// MemMapped indicates that the memory pages within the region are mapped
+	// into the view of a section.
+	MemMapped uint32 = 0x40000
+	// MemPrivate Indicates that the memory pages within the region are private
+	// that is, not shared by other processes.
+	MemPrivate uint32 = 0x20000
+)
+
+const (
+	// SectionData indicates a mapped view of a data file.
+	SectionData = 0x0
+	// SectionImage indicates a mapped view of an executable image.
+	SectionImage = 0x4
+	// SectionImageNoExecute indicates a mapped view an executable image file that will not be executed.
+	SectionImageNoExecute = 0x8
+	// SectionPagefile indicates a mapped view of pagefile-backed section.
+	SectionPagefile = 0xC
+	// SectionPhysical indicates that the allocation is a view of the \Device\PhysicalMemory section.
+	SectionPhysical = 0xD
+)
@@ -0,0 +1,150 @@
+//go:build !windows
+
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package winpath
+
+import (
+	"path"
+	"strings"
+)
+
+// separator is the Windows path separator
+const separator = '\\'
+
+func isSlash(c uint8) bool { return c == '\\' || c == '/' }
+
+// Base returns the last element of the path. Trailing
+// separators are removed before extracting the last element.
+func Base(path string) string {
+	if path == "" {
+		return "."
+	}
+	for len(path) > 0 && isSlash(path[len(path)-1]) {
+		path = path[0 : len(path)-1]
+	}
+	path = path[volumeNameLen(path):]
+	i := len(path) - 1
+	for i >= 0 && !isSlash(path[i]) {
+		i--
+	}
+	if i >= 0 {
+		path = path[i+1:]
+	}
+	if path == "" {
+		return string(separator)
+	}
+	return path
+}
+
+// Dir returns all but the last element of the path.
+// The returned path is cleaned.
+func Dir(path string) string {
+	vol := path[:volumeNameLen(path)]
+	i := len(path) - 1
+	for i >= len(vol) && !isSlash(path[i]) {
+		i--
+	}
+	dir := clean(path[len(vol) : i+1])
+	if dir == "." && len(vol) > 2 {
+		// must be UNC
+		return vol
+	}
+	return vol + dir
+}
+
+// Ext returns the file name extension used by the path.
+func Ext(path string) string {
+	for i := len(path) - 1; i >= 0 && !isSlash(path[i]); i-- {
+		if path[i] == '.' {
+			return path[i:]
+		}
+	}
+	return ""
+}
+
+// IsAbs reports whether the path is absolute.
+func IsAbs(path string) bool {
+	l := volumeNameLen(path)
+	if l == 0 {
+		return false
+	}
+	// UNC and device paths are always absolute
+	if isSlash(path[0]) && isSlash(path[1]) {
+		return true
+	}
+	path = path[l:]
+	if path == "" {
+		return false
+	}
+	return isSlash(path[0])
+}
+
+// VolumeName returns the leading volume name. Given "C:\foo\bar"
+// it returns "C:". Given "\\host\share\foo" it returns "\\host\share".
+func VolumeName(path string) string { return path[:volumeNameLen(path)] }
+
+// volumeNameLen returns the length of the leading volume name.
+func volumeNameLen(path string) int {
+	switch {
+	case len(path) >= 2 && path[1] == ':' && isLetter(path[0]):
+		return 2
+	case len(path) >= 4 && isSlash(path[0]) && isSlash(path[1]) && (path[2] == '.' || path[2] == '?') && isSlash(path[3]):
+		// device path, e.g. \\.\C:\foo or \\?\UNC\host\share\foo
+		n := segment(path, 4)
+		if strings.EqualFold(path[4:n], "UNC") {
+			return uncLen(path, n)
+		}
+		return n
+	case len(path) >= 2 && isSlash(path[0]) && isSlash(path[1]):
+		return uncLen(path, 1)
+	}
+	return 0
+}
+
+// uncLen returns the length of the \\host\share
+// prefix whose separator preceding the host is at i.
+func uncLen(path string, i int) int {
+	if i+1 >= len(path) || isSlash(path[i+1]) {
+		return i
+	}
+	n := segment(path, i+1)
+	if n+1 >= len(path) || isSlash(path[n+1]) {
+		return n
+	}
+	return segment(path, n+1)
+}
+
+// segment returns the index of the separator
+// terminating the path segment starting at i.
+func segment(path string, i int) int {
+	for i < len(path) && !isSlash(path[i]) {
+		i++
+	}
+	return i
+}
+
+func isLetter(c uint8) bool { return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' }
+
+// clean returns the shortest path name equivalent
+// to the path without the volume name.
+func clean(p string) string {
+	p = path.Clean(strings.ReplaceAll(p, string(separator), "/"))
+	return strings.ReplaceAll(p, "/", string(separator))
+}
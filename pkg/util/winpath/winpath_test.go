@@ -0,0 +1,99 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package winpath
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase(t *testing.T) {
+	var tests = []struct {
+		path string
+		base string
+	}{
+		{`C:\Windows\System32\cmd.exe`, "cmd.exe"},
+		{`C:\Windows\System32\`, "System32"},
+		{`C:\`, `\`},
+		{`\\host\share\docs\report.docx`, "report.docx"},
+		{`\Device\HarddiskVolume2\Windows\notepad.exe`, "notepad.exe"},
+		{"", "."},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.base, Base(tt.path), tt.path)
+	}
+}
+
+func TestDir(t *testing.T) {
+	var tests = []struct {
+		path string
+		dir  string
+	}{
+		{`C:\Windows\System32\cmd.exe`, `C:\Windows\System32`},
+		{`C:\Windows\..\cmd.exe`, `C:\`},
+		{`C:\cmd.exe`, `C:\`},
+		{`\\host\share\report.docx`, `\\host\share\`},
+		{`\\host\share`, `\\host\share`},
+		{`HKEY_LOCAL_MACHINE\SYSTEM\Setup`, `HKEY_LOCAL_MACHINE\SYSTEM`},
+		{"cmd.exe", "."},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.dir, Dir(tt.path), tt.path)
+	}
+}
+
+func TestExt(t *testing.T) {
+	assert.Equal(t, ".exe", Ext(`C:\Windows\cmd.exe`))
+	assert.Equal(t, "", Ext(`C:\Windows.old\cmd`))
+}
+
+func TestIsAbs(t *testing.T) {
+	var tests = []struct {
+		path  string
+		isAbs bool
+	}{
+		{`C:\Windows\cmd.exe`, true},
+		{`C:Windows\cmd.exe`, false},
+		{`Windows\cmd.exe`, false},
+		{`\\host\share\docs`, true},
+		{`\\?\C:\Windows\cmd.exe`, true},
+		{"/usr/bin", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.isAbs, IsAbs(tt.path), tt.path)
+	}
+}
+
+func TestVolumeName(t *testing.T) {
+	var tests = []struct {
+		path string
+		vol  string
+	}{
+		{`C:\Windows\cmd.exe`, "C:"},
+		{`\\host\share\docs`, `\\host\share`},
+		{`\\.\C:\Windows`, `\\.\C:`},
+		{`\\?\UNC\host\share\docs`, `\\?\UNC\host\share`},
+		{`\Windows\cmd.exe`, ""},
+		{`Windows\cmd.exe`, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.vol, VolumeName(tt.path), tt.path)
+	}
+}
@@ -0,0 +1,36 @@
+/*
+ * Copyright 2021-present by Nedim Sabic Sabic
+ * https://www.fibratus.io
+ * All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *  http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package winpath
+
+import "path/filepath"
+
+// Base returns the last element of the path.
+func Base(path string) string { return filepath.Base(path) }
+
+// Dir returns all but the last element of the path.
+func Dir(path string) string { return filepath.Dir(path) }
+
+// Ext returns the file name extension used by the path.
+func Ext(path string) string { return filepath.Ext(path) }
+
+// IsAbs reports whether the path is absolute.
+func IsAbs(path string) bool { return filepath.IsAbs(path) }
+
+// VolumeName returns the leading volume name.
+func VolumeName(path string) string { return filepath.VolumeName(path) }
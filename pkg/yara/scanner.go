@@ -390,8 +390,7 @@ func (s scanner) scan(target any) (yara.MatchRules, error) {
 }
 
 func (s scanner) emit(matches yara.MatchRules, e *kevent.Kevent) error {
-	senders := alertsender.FindAll()
-	if len(senders) == 0 {
+	if len(alertsender.FindAll()) == 0 {
 		return fmt.Errorf("no alertsenders registered. Alert won't be sent")
 	}
 
@@ -427,27 +426,27 @@ func (s scanner) emit(matches yara.MatchRules, e *kevent.Kevent) error {
 			return err
 		}
 
-		// send alert via all registered alert senders
-		for _, sender := range senders {
-			log.Infof("sending alert: [%s]. Text: %s Event: %s", title, text, e.String())
+		log.Infof("sending alert: [%s]. Text: %s Event: %s", title, text, e.String())
 
-			alert := alertsender.NewAlert(
-				title,
-				text,
-				m.Tags,
-				m.SeverityFromScore(),
-			)
+		alert := alertsender.NewAlert(
+			title,
+			text,
+			m.Tags,
+			m.SeverityFromScore(),
+		)
 
-			id := m.ID()
-			// generate id if it doesn't exist in meta fields
-			if id == "" {
-				id = uuid.New().String()
-			}
-			alert.ID = id
-			alert.Events = []*kevent.Kevent{e}
-			alert.Labels = m.Labels()
-			alert.Description = m.Description()
+		id := m.ID()
+		// generate id if it doesn't exist in meta fields
+		if id == "" {
+			id = uuid.New().String()
+		}
+		alert.ID = id
+		alert.Events = []*kevent.Kevent{e}
+		alert.Labels = m.Labels()
+		alert.Description = m.Description()
 
+		// send alert via alert senders the alert is routed to
+		for _, sender := range alertsender.FindRouted(alert) {
 			err := sender.Send(alert)
 			if err != nil {
 				return fmt.Errorf("unable to emit YARA alert via [%s] sender: %v", sender.Type(), err)
@@ -70,7 +70,7 @@ func init() {
 func TestScan(t *testing.T) {
 	log.SetLevel(log.DebugLevel)
 	psnap := new(ps.SnapshotterMock)
-	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.Noop}}))
+	require.NoError(t, alertsender.LoadAll([]alertsender.Config{{Type: alertsender.Noop}}, alertsender.RouteConfig{}))
 
 	var tests = []struct {
 		name          string